@@ -0,0 +1,25 @@
+#!/bin/sh
+
+# Generates certificates for tests. They should not be used for anything else.
+
+set -ex
+
+cd "$(dirname "$0")"
+
+openssl req -x509 -newkey rsa:2048 -nodes -days 36500 -subj "/CN=FerretDB test CA" \
+    -keyout rootCA-key.pem -out rootCA-cert.pem
+
+openssl req -newkey rsa:2048 -nodes -subj "/CN=localhost" -keyout server-key.pem -out server.csr
+printf "subjectAltName=DNS:localhost,IP:127.0.0.1\nextendedKeyUsage=serverAuth\n" > server.ext
+openssl x509 -req -in server.csr -CA rootCA-cert.pem -CAkey rootCA-key.pem -CAcreateserial -days 36500 \
+    -extfile server.ext -out server-cert.pem
+
+openssl req -newkey rsa:2048 -nodes -subj "/CN=client" -keyout client-key.pem -out client.csr
+printf "extendedKeyUsage=clientAuth\n" > client.ext
+openssl x509 -req -in client.csr -CA rootCA-cert.pem -CAkey rootCA-key.pem -CAcreateserial -days 36500 \
+    -extfile client.ext -out client-cert.pem
+
+# the Go driver's tlsCertificateKeyFile option expects both certificate and key in the same file
+cat client-cert.pem client-key.pem > client.pem
+
+rm -f server.csr server.ext client.csr client.ext client-key.pem client-cert.pem rootCA-cert.srl
@@ -254,7 +254,7 @@ func setupTigris(ctx context.Context, logger *zap.SugaredLogger) error {
 
 // run runs all setup commands.
 func run(ctx context.Context, logger *zap.SugaredLogger) error {
-	go debug.RunHandler(ctx, "127.0.0.1:8089", logger.Named("debug").Desugar())
+	go debug.RunHandler(ctx, "127.0.0.1:8089", nil, nil, logger.Named("debug").Desugar())
 
 	if err := setupPostgres(ctx, logger); err != nil {
 		return err
@@ -15,39 +15,92 @@
 package main
 
 import (
+	"compress/zlib"
 	"context"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/expfmt"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
 	"github.com/FerretDB/FerretDB/internal/clientconn"
+	"github.com/FerretDB/FerretDB/internal/handlers/pg/pgdb"
 	"github.com/FerretDB/FerretDB/internal/handlers/registry"
 	"github.com/FerretDB/FerretDB/internal/util/debug"
 	"github.com/FerretDB/FerretDB/internal/util/logging"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/util/version"
+	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
 var (
 	versionF = flag.Bool("version", false, "print version to stdout (full version, commit, branch, dirty flag) and exit")
 
-	listenAddrF = flag.String("listen-addr", "127.0.0.1:27017", "listen address")
+	listenAddrF = flag.String("listen-addr", "127.0.0.1:27017", "listen address; empty value disables plaintext connections")
 	proxyAddrF  = flag.String("proxy-addr", "127.0.0.1:37017", "proxy address")
 	debugAddrF  = flag.String("debug-addr", "127.0.0.1:8088", "debug address")
 	modeF       = flag.String("mode", string(clientconn.AllModes[0]), fmt.Sprintf("operation mode: %v", clientconn.AllModes))
 
+	shutdownTimeoutF = flag.Duration("shutdown-timeout", 15*time.Second, "grace period for in-flight commands on shutdown")
+	slowMSF          = flag.Int64("slowms", 100, "slow operations logging threshold in milliseconds; negative value disables it")
+	slowSampleRateF  = flag.Float64("slow-sample-rate", 0, "fraction of faster operations that are also logged")
+	maxConnectionsF  = flag.Int("max-connections", 0, "maximum number of concurrent client connections; 0 means no limit")
+	requireAuthF     = flag.Bool("require-auth", false, "reject most commands on unauthenticated connections")
+	readOnlyF        = flag.Bool("read-only", false, "reject write commands; can be changed at runtime with setParameter")
+	idleTimeoutF     = flag.Duration("idle-timeout", 0, "close client connections idle for that long; 0 means no timeout")
+
+	otlpEndpointF = flag.String("otlp-endpoint", "", "OTLP/HTTP traces endpoint (host:port); empty value disables tracing")
+
+	accessLogF = flag.String("access-log", "", `JSON lines access log: file path or "stdout"; empty value disables it`)
+
+	diffIgnoreF = flag.String("diff-ignore", "", "additional comma-separated [command:]path list of fields ignored in diff modes")
+
+	listenTLSF         = flag.String("listen-tls", "", "listen TLS address; empty value disables TLS connections")
+	listenTLSCertFileF = flag.String("listen-tls-cert", "", "TLS certificate file")
+	listenTLSKeyFileF  = flag.String("listen-tls-key", "", "TLS private key file")
+	listenTLSCAFileF   = flag.String("listen-tls-ca", "", "TLS CA file; if set, client certificates are required")
+
+	listenUnixF     = flag.String("listen-unix", "", "listen Unix domain socket path; empty value disables it")
+	listenUnixPermF = flag.String("listen-unix-perm", "0700", "Unix domain socket file permissions (octal)")
+
+	recordDirF     = flag.String("record-dir", "", "directory for wire traffic recordings; empty value disables recording")
+	recordMaxSizeF = flag.Int64("record-max-size", 100<<20, "maximum total size of wire traffic recordings in bytes")
+
+	compressionZlibLevelF = flag.Int("compression-zlib-level", zlib.DefaultCompression, "zlib compression level for OP_COMPRESSED")
+
 	handlerF = flag.String("handler", "<set in initFlags()>", "<set in initFlags()>")
 
-	postgreSQLURLF = flag.String("postgresql-url", "postgres://postgres@127.0.0.1:5432/ferretdb", "PostgreSQL URL")
+	postgreSQLURLF        = flag.String("postgresql-url", "postgres://postgres@127.0.0.1:5432/ferretdb", "PostgreSQL URL or DSN")
+	postgreSQLJSONBIndexF = flag.Bool("postgresql-jsonb-index", true, "create GIN index on _jsonb column for new collections")
+	postgreSQLRepairF     = flag.Bool("postgresql-repair-databases", false, "restore missing settings tables on startup")
 
-	logLevelF = flag.String("log-level", "<set in initFlags()>", "<set in initFlags()>")
+	postgreSQLMaxConnsF       = flag.Int("postgresql-max-conns", 0, "PostgreSQL pool: maximum connections")
+	postgreSQLMinConnsF       = flag.Int("postgresql-min-conns", 0, "PostgreSQL pool: minimum connections")
+	postgreSQLMaxConnLifeF    = flag.Duration("postgresql-max-conn-life", 0, "PostgreSQL pool: maximum connection lifetime")
+	postgreSQLMaxConnIdleF    = flag.Duration("postgresql-max-conn-idle", 0, "PostgreSQL pool: maximum connection idle time")
+	postgreSQLAcquireTimeoutF = flag.Duration("postgresql-acquire-timeout", 30*time.Second, "PostgreSQL pool: acquire timeout")
+	postgreSQLSimpleProtocolF = flag.Bool("postgresql-simple-protocol", false, "use PostgreSQL simple protocol (for PgBouncer)")
+	postgreSQLMinVersionF     = flag.Int("postgresql-min-version", pgdb.DefaultMinServerVersionNum, "minimal PostgreSQL version")
+	postgreSQLHealthCheckF    = flag.Duration("postgresql-health-check", pgdb.DefaultHealthCheckInterval, "health check interval")
+
+	postgreSQLChangeStreamsF    = flag.Bool("postgresql-change-streams", false, "record changes for $changeStream")
+	postgreSQLChangesRetentionF = flag.Duration("postgresql-changes-retention", pgdb.DefaultChangesRetention, "changes retention")
+
+	logLevelF    = flag.String("log-level", "<set in initFlags()>", "<set in initFlags()>")
+	logPayloadsF = flag.Bool("log-payloads", false, "log full request and response bodies at debug level; for development only")
 
 	testConnTimeoutF = flag.Duration("test-conn-timeout", 0, "test: set connection timeout")
 )
@@ -94,14 +147,17 @@ func main() {
 		fmt.Fprintln(os.Stdout, "commit:", info.Commit)
 		fmt.Fprintln(os.Stdout, "branch:", info.Branch)
 		fmt.Fprintln(os.Stdout, "dirty:", info.Dirty)
+		fmt.Fprintln(os.Stdout, "package:", info.Package)
 		return
 	}
 
 	startFields := []zap.Field{
 		zap.String("version", info.Version),
 		zap.String("commit", info.Commit),
+		zap.Time("commitTime", info.CommitTime),
 		zap.String("branch", info.Branch),
 		zap.Bool("dirty", info.Dirty),
+		zap.String("package", info.Package),
 	}
 	for _, k := range info.BuildEnvironment.Keys() {
 		v := must.NotFail(info.BuildEnvironment.Get(k))
@@ -109,6 +165,10 @@ func main() {
 	}
 	logger.Info("Starting FerretDB "+info.Version+"...", startFields...)
 
+	if _, _, _, err := version.ParseSemVer(info.Version); err != nil {
+		logger.Warn("Version information is incomplete; please build FerretDB from a Git checkout or a release", zap.Error(err))
+	}
+
 	var found bool
 	for _, m := range clientconn.AllModes {
 		if *modeF == string(m) {
@@ -120,6 +180,33 @@ func main() {
 		logger.Sugar().Fatalf("Unknown mode %q.", *modeF)
 	}
 
+	zlibCompressor, err := wire.NewZlibCompressor(*compressionZlibLevelF)
+	if err != nil {
+		logger.Sugar().Fatalf("Invalid zlib compression level %d: %s.", *compressionZlibLevelF, err)
+	}
+	wire.RegisterCompressor(zlibCompressor)
+
+	unixPerm, err := strconv.ParseUint(*listenUnixPermF, 8, 32)
+	if err != nil {
+		logger.Sugar().Fatalf("Invalid Unix domain socket permissions %q: %s.", *listenUnixPermF, err)
+	}
+
+	var accessLog io.Writer
+	switch *accessLogF {
+	case "":
+		// disabled
+	case "stdout":
+		accessLog = os.Stdout
+	default:
+		f, err := os.OpenFile(*accessLogF, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+		if err != nil {
+			logger.Sugar().Fatalf("Failed to open access log: %s.", err)
+		}
+		defer f.Close()
+
+		accessLog = f
+	}
+
 	ctx, stop := notifyAppTermination(context.Background())
 	go func() {
 		<-ctx.Done()
@@ -127,13 +214,55 @@ func main() {
 		stop()
 	}()
 
-	go debug.RunHandler(ctx, *debugAddrF, logger.Named("debug"))
+	var tracerProvider trace.TracerProvider
+	if *otlpEndpointF != "" {
+		exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpoint(*otlpEndpointF), otlptracehttp.WithInsecure())
+		if err != nil {
+			logger.Sugar().Fatalf("Failed to create OTLP exporter: %s.", err)
+		}
+
+		tp := sdktrace.NewTracerProvider(
+			sdktrace.WithBatcher(exporter),
+			sdktrace.WithResource(resource.NewSchemaless(
+				semconv.ServiceNameKey.String("ferretdb"),
+				semconv.ServiceVersionKey.String(info.Version),
+			)),
+		)
+
+		defer func() {
+			// use new context, as ctx is already done
+			stopCtx, stopCancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer stopCancel()
+
+			if err := tp.Shutdown(stopCtx); err != nil {
+				logger.Warn("Failed to flush traces", zap.Error(err))
+			}
+		}()
+
+		tracerProvider = tp
+	}
 
 	h, err := registry.NewHandler(*handlerF, &registry.NewHandlerOpts{
-		Ctx:           ctx,
-		Logger:        logger,
-		PostgreSQLURL: *postgreSQLURLF,
-		TigrisURL:     tigrisURL,
+		Ctx:                         ctx,
+		Logger:                      logger,
+		Metrics:                     prometheus.DefaultRegisterer,
+		PostgreSQLURL:               *postgreSQLURLF,
+		PostgreSQLDisableJSONBIndex: !*postgreSQLJSONBIndexF,
+		PostgreSQLRepairDatabases:   *postgreSQLRepairF,
+		PostgreSQLPool: pgdb.NewPoolOpts{
+			MaxConns:        int32(*postgreSQLMaxConnsF),
+			MinConns:        int32(*postgreSQLMinConnsF),
+			MaxConnLifetime: *postgreSQLMaxConnLifeF,
+			MaxConnIdleTime: *postgreSQLMaxConnIdleF,
+			AcquireTimeout:  *postgreSQLAcquireTimeoutF,
+			SimpleProtocol:  *postgreSQLSimpleProtocolF,
+
+			MinServerVersionNum: *postgreSQLMinVersionF,
+			HealthCheckInterval: *postgreSQLHealthCheckF,
+		},
+		PostgreSQLChangeStreams:          *postgreSQLChangeStreamsF,
+		PostgreSQLChangeStreamsRetention: *postgreSQLChangesRetentionF,
+		TigrisURL:                        tigrisURL,
 	})
 	if err != nil {
 		logger.Fatal(err.Error())
@@ -141,15 +270,40 @@ func main() {
 	defer h.Close()
 
 	l := clientconn.NewListener(&clientconn.NewListenerOpts{
-		ListenAddr:      *listenAddrF,
-		ProxyAddr:       *proxyAddrF,
-		Mode:            clientconn.Mode(*modeF),
-		Handler:         h,
-		Logger:          logger,
-		TestConnTimeout: *testConnTimeoutF,
+		ListenAddr:       *listenAddrF,
+		TLS:              *listenTLSF,
+		TLSCertFile:      *listenTLSCertFileF,
+		TLSKeyFile:       *listenTLSKeyFileF,
+		TLSCAFile:        *listenTLSCAFileF,
+		Unix:             *listenUnixF,
+		UnixPerm:         os.FileMode(unixPerm),
+		MaxConnections:   int32(*maxConnectionsF),
+		RequireAuth:      *requireAuthF,
+		ReadOnly:         *readOnlyF,
+		IdleTimeout:      *idleTimeoutF,
+		AccessLog:        accessLog,
+		SlowMS:           *slowMSF,
+		SlowOpSampleRate: *slowSampleRateF,
+		ProxyAddr:        *proxyAddrF,
+		DiffIgnoredPaths: clientconn.ParseDiffIgnoredPaths(*diffIgnoreF),
+		Mode:             clientconn.Mode(*modeF),
+		Handler:          h,
+		Logger:           logger,
+		Metrics:          prometheus.DefaultRegisterer,
+		TracerProvider:   tracerProvider,
+		LogPayloads:      *logPayloadsF,
+		RecordDir:        *recordDirF,
+		RecordMaxSize:    *recordMaxSizeF,
+		ShutdownTimeout:  *shutdownTimeoutF,
+		TestConnTimeout:  *testConnTimeoutF,
 	})
 
-	prometheus.DefaultRegisterer.MustRegister(l)
+	state := map[string]debug.StateProvider{"listener": l}
+	if p, ok := h.(debug.StateProvider); ok {
+		state["handler"] = p
+	}
+
+	go debug.RunHandler(ctx, *debugAddrF, nil, state, logger.Named("debug"))
 
 	err = l.Run(ctx)
 	if err == nil || err == context.Canceled {
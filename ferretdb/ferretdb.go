@@ -21,17 +21,27 @@ import (
 	"fmt"
 	"net/url"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
-	"go.uber.org/zap/zapcore"
 
 	"github.com/FerretDB/FerretDB/internal/clientconn"
+	"github.com/FerretDB/FerretDB/internal/handlers"
 	"github.com/FerretDB/FerretDB/internal/handlers/registry"
-	"github.com/FerretDB/FerretDB/internal/util/logging"
 )
 
+// defaultListenAddr is used when Config.ListenAddr is empty.
+const defaultListenAddr = "127.0.0.1:27017"
+
 // Config represents FerretDB configuration.
 type Config struct {
-	// Handler to use; one of `pg` or `tigris` (if enabled at compile-time).
+	// Listen address for plaintext connections; empty value means "127.0.0.1:27017".
+	// Zero port (for example, "127.0.0.1:0") means that a random free port is used;
+	// use MongoDBURI to get it.
+	ListenAddr string
+
+	// Handler to use; one of `pg`, `tigris` (if enabled at compile-time),
+	// or any other handler registered in the handler registry.
 	Handler string
 
 	// PostgreSQL connection string for `pg` handler.
@@ -39,73 +49,110 @@ type Config struct {
 
 	// Tigris connection string for `tigris` handler.
 	TigrisURL string
+
+	// Logger to use; nil value disables logging.
+	Logger *zap.Logger
+
+	// Registry for FerretDB metrics; nil value means that metrics are not exported.
+	// Registries should not be shared between multiple instances.
+	Metrics prometheus.Registerer
+
+	// OpenTelemetry tracer provider for command and query spans; nil value disables tracing.
+	TracerProvider trace.TracerProvider
 }
 
 // FerretDB represents an instance of embeddable FerretDB implementation.
+//
+// Multiple instances could be used in the same process.
 type FerretDB struct {
-	config     *Config
-	listenAddr string
+	config *Config
+	h      handlers.Interface
+	l      *clientconn.Listener
 }
 
 // New creates a new instance of embeddable FerretDB implementation.
+//
+// It connects to the backend, so Run should be called to release resources,
+// even if the caller changes its mind.
 func New(config *Config) (*FerretDB, error) {
-	f := &FerretDB{
-		config:     config,
-		listenAddr: "127.0.0.1:27017",
+	if config == nil {
+		return nil, errors.New("config is nil")
 	}
 
-	return f, nil
-}
+	logger := config.Logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
 
-// Run runs FerretDB until ctx is done.
-//
-// When this method returns, listener and all connections are closed.
-func (f *FerretDB) Run(ctx context.Context) error {
-	newOpts := registry.NewHandlerOpts{
+	metrics := config.Metrics
+	if metrics == nil {
+		metrics = prometheus.NewRegistry()
+	}
+
+	listenAddr := config.ListenAddr
+	if listenAddr == "" {
+		listenAddr = defaultListenAddr
+	}
+
+	h, err := registry.NewHandler(config.Handler, &registry.NewHandlerOpts{
 		Ctx:           context.Background(),
 		Logger:        logger,
-		PostgreSQLURL: f.config.PostgreSQLURL,
-		TigrisURL:     f.config.TigrisURL,
-	}
-	h, err := registry.NewHandler(f.config.Handler, &newOpts)
+		Metrics:       metrics,
+		PostgreSQLURL: config.PostgreSQLURL,
+		TigrisURL:     config.TigrisURL,
+	})
 	if err != nil {
-		return fmt.Errorf("failed to construct handler: %s", err)
+		return nil, fmt.Errorf("failed to construct handler: %s", err)
 	}
-	defer h.Close()
 
 	l := clientconn.NewListener(&clientconn.NewListenerOpts{
-		ListenAddr: f.listenAddr,
-		Mode:       clientconn.NormalMode,
-		Handler:    h,
-		Logger:     logger,
+		ListenAddr:     listenAddr,
+		Mode:           clientconn.NormalMode,
+		Handler:        h,
+		Logger:         logger,
+		Metrics:        metrics,
+		TracerProvider: config.TracerProvider,
 	})
 
-	if err = l.Run(ctx); err != nil {
-		// Do not expose internal error details.
-		// If you need stable error values and/or types for some cases, please create an issue.
-		err = errors.New(err.Error())
+	return &FerretDB{
+		config: config,
+		h:      h,
+		l:      l,
+	}, nil
+}
+
+// Run runs FerretDB until ctx is done.
+//
+// It should be called only once.
+// If ctx is done, ctx.Err() is returned.
+// When this method returns, listener, all connections, and backend connections are closed.
+func (f *FerretDB) Run(ctx context.Context) error {
+	defer f.h.Close()
+
+	err := f.l.Run(ctx)
+	if err == nil || err == ctx.Err() {
+		return err
 	}
-	return err
+
+	// Do not expose internal error details.
+	// If you need stable error values and/or types for some cases, please create an issue.
+	return errors.New(err.Error())
 }
 
 // MongoDBURI returns MongoDB URI for this FerretDB instance.
+//
+// It contains the actual listen address, even if the port in the configuration was zero.
+// It blocks until Run starts listening (or fails to do so).
 func (f *FerretDB) MongoDBURI() string {
+	var host string
+	if addr := f.l.Addr(); addr != nil {
+		host = addr.String()
+	}
+
 	u := url.URL{
 		Scheme: "mongodb",
-		Host:   f.listenAddr,
+		Host:   host,
 		Path:   "/",
 	}
 	return u.String()
 }
-
-// logger is a global logger used by FerretDB.
-//
-// If it is a problem for you, please create an issue.
-var logger *zap.Logger
-
-// Initialize the global logger there to avoid creating too many issues for zap users that initialize it in their
-// `main()` functions. It is still not a full solution; eventually, we should remove the usage of the global logger.
-func init() {
-	logging.Setup(zapcore.FatalLevel)
-	logger = zap.L()
-}
@@ -16,12 +16,14 @@ package ferretdb
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 )
 
 func Example() {
 	f, err := New(&Config{
+		ListenAddr:    "127.0.0.1:0",
 		Handler:       "pg",
 		PostgreSQLURL: "postgres://postgres@127.0.0.1:5432/ferretdb",
 	})
@@ -29,8 +31,19 @@ func Example() {
 		log.Fatal(err)
 	}
 
-	go f.Run(context.Background())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		if err := f.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			log.Print(err)
+		}
+	}()
+
+	// MongoDB URI contains the actual random port, like mongodb://127.0.0.1:54321/
 	uri := f.MongoDBURI()
 	fmt.Println(uri)
 
@@ -40,7 +53,11 @@ func Example() {
 	//
 	// [...]
 	//
-	// mongo.Connect(ctx, options.Client().ApplyURI(uri)
+	// mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	//
+	// See also integration tests for a complete example.
 
-	// Output: mongodb://127.0.0.1:27017/
+	// stop FerretDB and wait for all connections to be closed
+	cancel()
+	<-done
 }
@@ -0,0 +1,77 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+)
+
+func TestAuthPlain(t *testing.T) {
+	setup.SkipForTigrisWithReason(t, "PLAIN authentication is checked by PostgreSQL")
+
+	t.Parallel()
+
+	s := setup.SetupWithOpts(t, nil)
+	if s.UnixSocket == "" {
+		t.Skip("PLAIN authentication is tested only with in-process FerretDB")
+	}
+
+	connect := func(t *testing.T, username string) *mongo.Client {
+		t.Helper()
+
+		opts := options.Client().ApplyURI(fmt.Sprintf("mongodb://127.0.0.1:%d/", s.Port)).SetAuth(options.Credential{
+			AuthMechanism: "PLAIN",
+			Username:      username,
+			Password:      "password",
+		})
+
+		client, err := mongo.Connect(s.Ctx, opts)
+		require.NoError(t, err)
+		t.Cleanup(func() { client.Disconnect(s.Ctx) })
+
+		return client
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		t.Parallel()
+
+		db := connect(t, "postgres").Database("admin")
+
+		var actual bson.D
+		err := db.RunCommand(s.Ctx, bson.D{{"connectionStatus", int32(1)}}).Decode(&actual)
+		require.NoError(t, err)
+
+		authInfo := actual.Map()["authInfo"].(bson.D)
+		expected := bson.A{bson.D{{"user", "postgres"}, {"db", "$external"}}}
+		assert.Equal(t, expected, authInfo.Map()["authenticatedUsers"])
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		t.Parallel()
+
+		err := connect(t, "nonexistent").Ping(s.Ctx, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Authentication failed.")
+	})
+}
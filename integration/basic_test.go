@@ -18,12 +18,14 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
 	"go.mongodb.org/mongo-driver/x/mongo/driver"
 
 	"github.com/FerretDB/FerretDB/integration/setup"
@@ -107,6 +109,113 @@ func TestInsertFind(t *testing.T) {
 }
 
 //nolint:paralleltest // we test a global list of databases
+func TestInsertUnacknowledged(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	// unacknowledged writes are sent with moreToCome flag, and the server does not reply to them
+	opts := options.Collection().SetWriteConcern(writeconcern.New(writeconcern.W(0)))
+	unacknowledged, err := collection.Clone(opts)
+	require.NoError(t, err)
+
+	_, err = unacknowledged.InsertOne(ctx, bson.D{{"_id", "unacknowledged"}, {"v", int32(42)}})
+	require.ErrorIs(t, err, mongo.ErrUnacknowledgedWrite)
+
+	// the next request on the same connection should get its own reply, not the insert's one
+	var actual bson.D
+	assert.Eventually(t, func() bool {
+		err = collection.FindOne(ctx, bson.D{{"_id", "unacknowledged"}}).Decode(&actual)
+		return err == nil
+	}, 5*time.Second, 100*time.Millisecond)
+
+	assert.Equal(t, bson.D{{"_id", "unacknowledged"}, {"v", int32(42)}}, actual)
+}
+
+func TestInsertMixedTypes(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	expected := []bson.D{
+		{{"_id", "document"}, {"v", bson.D{{"foo", int32(1)}}}},
+		{{"_id", "int32"}, {"v", int32(42)}},
+		{{"_id", "int64"}, {"v", int64(42)}},
+		{{"_id", "null"}, {"v", nil}},
+		{{"_id", "string"}, {"v", "foo"}},
+	}
+
+	// insert int32 first, so other types conflict with it
+	docs := []any{expected[1], expected[4], expected[3], expected[0], expected[2]}
+	_, err := collection.InsertMany(ctx, docs)
+	require.NoError(t, err)
+
+	AssertEqualDocumentsSlice(t, expected, FindAll(t, ctx, collection))
+
+	var actual bson.D
+	err = collection.FindOne(ctx, bson.D{{"v", "foo"}}).Decode(&actual)
+	require.NoError(t, err)
+	AssertEqualDocuments(t, expected[4], actual)
+
+	_, err = collection.UpdateOne(ctx, bson.D{{"_id", "int32"}}, bson.D{{"$set", bson.D{{"v", 42.13}}}})
+	require.NoError(t, err)
+
+	err = collection.FindOne(ctx, bson.D{{"_id", "int32"}}).Decode(&actual)
+	require.NoError(t, err)
+	AssertEqualDocuments(t, bson.D{{"_id", "int32"}, {"v", 42.13}}, actual)
+}
+
+func TestInsertWriteErrorsIndex(t *testing.T) {
+	setup.SkipForTigrisWithReason(t, "Tigris handler does not report write errors for individual documents")
+
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		ordered  bool
+		inserted int
+		indexes  []int
+	}{
+		"Ordered": {
+			ordered:  true,
+			inserted: 2,
+			indexes:  []int{2},
+		},
+		"Unordered": {
+			ordered:  false,
+			inserted: 3,
+			indexes:  []int{2, 4},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx, collection := setup.Setup(t)
+
+			docs := []any{
+				bson.D{{"_id", "a"}},
+				bson.D{{"_id", "b"}},
+				bson.D{{"_id", "a"}},
+				bson.D{{"_id", "c"}},
+				bson.D{{"_id", "b"}},
+			}
+
+			_, err := collection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(tc.ordered))
+
+			var we mongo.BulkWriteException
+			require.ErrorAs(t, err, &we)
+
+			indexes := make([]int, len(we.WriteErrors))
+			for i, e := range we.WriteErrors {
+				assert.Equal(t, 11000, e.Code)
+				indexes[i] = e.Index
+			}
+			assert.Equal(t, tc.indexes, indexes)
+
+			n, err := collection.CountDocuments(ctx, bson.D{})
+			require.NoError(t, err)
+			assert.EqualValues(t, tc.inserted, n)
+		})
+	}
+}
+
 func TestFindCommentMethod(t *testing.T) {
 	setup.SkipForTigris(t)
 
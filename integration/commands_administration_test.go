@@ -19,6 +19,7 @@ import (
 	"math"
 	"net"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 
@@ -93,6 +94,66 @@ func TestCommandsAdministrationCreateDropList(t *testing.T) {
 	AssertEqualError(t, expectedErr, err)
 }
 
+func TestCommandsAdministrationListCollections(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t) // no providers there
+	db := collection.Database()
+
+	for _, name := range []string{"foo_1", "foo_2", "bar"} {
+		_, err := db.Collection(name).InsertOne(ctx, bson.D{{"_id", "v"}})
+		require.NoError(t, err)
+	}
+
+	t.Run("RegexFilter", func(t *testing.T) {
+		t.Parallel()
+
+		names, err := db.ListCollectionNames(ctx, bson.D{{"name", primitive.Regex{Pattern: "^foo_"}}})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"foo_1", "foo_2"}, names)
+	})
+
+	t.Run("RegexFilterNameOnly", func(t *testing.T) {
+		t.Parallel()
+
+		var res bson.D
+		err := db.RunCommand(ctx, bson.D{
+			{"listCollections", int32(1)},
+			{"filter", bson.D{{"name", bson.D{{"$regex", "^BA"}, {"$options", "i"}}}}},
+			{"nameOnly", true},
+		}).Decode(&res)
+		require.NoError(t, err)
+
+		doc := ConvertDocument(t, res)
+		firstBatch := must.NotFail(doc.GetByPath(types.NewPath([]string{"cursor", "firstBatch"}))).(*types.Array)
+		require.Equal(t, 1, firstBatch.Len())
+
+		info := must.NotFail(firstBatch.Get(0)).(*types.Document)
+		assert.Equal(t, []string{"name", "type"}, info.Keys())
+		assert.Equal(t, "bar", must.NotFail(info.Get("name")))
+		assert.Equal(t, "collection", must.NotFail(info.Get("type")))
+	})
+
+	t.Run("Info", func(t *testing.T) {
+		t.Parallel()
+
+		cursor, err := db.ListCollections(ctx, bson.D{{"name", "foo_1"}})
+		require.NoError(t, err)
+
+		var res []bson.D
+		require.NoError(t, cursor.All(ctx, &res))
+		require.Len(t, res, 1)
+
+		info := ConvertDocument(t, res[0])
+		assert.Equal(t, "foo_1", must.NotFail(info.Get("name")))
+		assert.Equal(t, "collection", must.NotFail(info.Get("type")))
+		assert.Equal(t, 0, must.NotFail(info.Get("options")).(*types.Document).Len())
+
+		uuid := must.NotFail(info.GetByPath(types.NewPath([]string{"info", "uuid"}))).(types.Binary)
+		assert.Equal(t, types.BinaryUUID, uuid.Subtype)
+		assert.Len(t, uuid.B, 16)
+	})
+}
+
 func TestCommandsAdministrationCreateDropListDatabases(t *testing.T) {
 	setup.SkipForTigris(t)
 
@@ -138,6 +199,84 @@ func TestCommandsAdministrationCreateDropListDatabases(t *testing.T) {
 	assert.Equal(t, bson.D{{"ok", 1.0}}, res)
 }
 
+func TestCommandsAdministrationDropDatabase(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t, shareddata.Int32s)
+	db := collection.Database()
+
+	var res bson.D
+	err := db.RunCommand(ctx, bson.D{{"dropDatabase", 1}}).Decode(&res)
+	require.NoError(t, err)
+	assert.Equal(t, bson.D{{"dropped", db.Name()}, {"ok", 1.0}}, res)
+
+	// "dropped" field is absent for non-existing database
+	err = db.RunCommand(ctx, bson.D{{"dropDatabase", 1}}).Decode(&res)
+	require.NoError(t, err)
+	assert.Equal(t, bson.D{{"ok", 1.0}}, res)
+
+	names, err := db.Client().ListDatabaseNames(ctx, bson.D{{"name", db.Name()}})
+	require.NoError(t, err)
+	assert.Empty(t, names)
+}
+
+func TestCommandsAdministrationDropDatabaseReserved(t *testing.T) {
+	t.Parallel()
+
+	if !setup.IsPg() {
+		t.Skip("Only `pg` handler reserves database names for server settings")
+	}
+
+	ctx, collection := setup.Setup(t)
+
+	// server-wide settings such as free monitoring state are stored there
+	db := collection.Database().Client().Database("_ferretdb_server")
+
+	err := db.RunCommand(ctx, bson.D{{"dropDatabase", 1}}).Err()
+	expected := mongo.CommandError{
+		Code:    73,
+		Name:    "InvalidNamespace",
+		Message: `Invalid database name: '_ferretdb_server'`,
+	}
+	AssertEqualError(t, expected, err)
+}
+
+func TestCommandsAdministrationDropDatabaseConcurrently(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t, shareddata.Int32s)
+	db := collection.Database()
+
+	const n = 10
+	results := make([]bson.D, n)
+	errs := make([]error, n)
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = db.RunCommand(ctx, bson.D{{"dropDatabase", 1}}).Decode(&results[i])
+		}(i)
+	}
+	wg.Wait()
+
+	var dropped int
+	for i := 0; i < n; i++ {
+		require.NoError(t, errs[i])
+
+		m := results[i].Map()
+		assert.Equal(t, 1.0, m["ok"])
+		if _, ok := m["dropped"]; ok {
+			dropped++
+		}
+	}
+
+	assert.LessOrEqual(t, dropped, 1)
+
+	names, err := db.Client().ListDatabaseNames(ctx, bson.D{{"name", db.Name()}})
+	require.NoError(t, err)
+	assert.Empty(t, names)
+}
+
 func TestCommandsAdministrationGetParameter(t *testing.T) {
 	setup.SkipForTigris(t)
 
@@ -598,8 +737,6 @@ func TestCommandsAdministrationBuildInfo(t *testing.T) {
 }
 
 func TestCommandsAdministrationCollStatsEmpty(t *testing.T) {
-	setup.SkipForTigris(t)
-
 	t.Parallel()
 	ctx, collection := setup.Setup(t)
 
@@ -642,6 +779,25 @@ func TestCommandsAdministrationCollStats(t *testing.T) {
 	assert.InDelta(t, float64(4096), must.NotFail(doc.Get("totalSize")), 16_024)
 }
 
+func TestCommandsAdministrationCollStatsWithScale(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t, shareddata.Int32s)
+
+	var actual bson.D
+	command := bson.D{{"collStats", collection.Name()}, {"scale", float64(1_000)}}
+	err := collection.Database().RunCommand(ctx, command).Decode(&actual)
+	require.NoError(t, err)
+
+	doc := ConvertDocument(t, actual)
+	assert.Equal(t, float64(1), must.NotFail(doc.Get("ok")))
+	assert.Equal(t, collection.Database().Name()+"."+collection.Name(), must.NotFail(doc.Get("ns")))
+	assert.Equal(t, int32(len(shareddata.Int32s.Docs())), must.NotFail(doc.Get("count")))
+	assert.Equal(t, int32(1000), must.NotFail(doc.Get("scaleFactor")))
+
+	assert.InDelta(t, float64(16), must.NotFail(doc.Get("size")), 16)
+	assert.InDelta(t, float64(16), must.NotFail(doc.Get("totalSize")), 16)
+}
+
 func TestCommandsAdministrationDataSize(t *testing.T) {
 	setup.SkipForTigris(t)
 
@@ -771,6 +927,72 @@ func TestCommandsAdministrationDBStatsEmptyWithScale(t *testing.T) {
 	// https://github.com/FerretDB/FerretDB/issues/727
 }
 
+func TestCommandsAdministrationDBStatsScale(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t, shareddata.DocumentsStrings)
+
+	for name, tc := range map[string]struct { //nolint:paralleltest // false positive
+		scale       any
+		expected    float64
+		expectedErr *mongo.CommandError
+	}{
+		"Int32": {
+			scale:    int32(1_000),
+			expected: 1_000,
+		},
+		"Int64": {
+			scale:    int64(1_000),
+			expected: 1_000,
+		},
+		"Fractional": {
+			scale:    float64(1_000.5),
+			expected: 1_000,
+		},
+		"Zero": {
+			scale: int32(0),
+			expectedErr: &mongo.CommandError{
+				Code:    51024,
+				Name:    "Location51024",
+				Message: "scale has to be > 0",
+			},
+		},
+		"Negative": {
+			scale: float64(-1),
+			expectedErr: &mongo.CommandError{
+				Code:    51024,
+				Name:    "Location51024",
+				Message: "scale has to be > 0",
+			},
+		},
+		"String": {
+			scale: "1",
+			expectedErr: &mongo.CommandError{
+				Code:    14,
+				Name:    "TypeMismatch",
+				Message: "BSON field 'dbStats.scale' is the wrong type 'string', expected types '[long, int, decimal, double]'",
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var actual bson.D
+			command := bson.D{{"dbStats", int32(1)}, {"scale", tc.scale}}
+			err := collection.Database().RunCommand(ctx, command).Decode(&actual)
+			if tc.expectedErr != nil {
+				AssertEqualError(t, *tc.expectedErr, err)
+				return
+			}
+			require.NoError(t, err)
+
+			doc := ConvertDocument(t, actual)
+			assert.Equal(t, float64(1), must.NotFail(doc.Get("ok")))
+			assert.EqualValues(t, tc.expected, must.NotFail(doc.Get("scaleFactor")))
+		})
+	}
+}
+
 //nolint:paralleltest // we test a global server status
 func TestCommandsAdministrationServerStatus(t *testing.T) {
 	setup.SkipForTigris(t)
@@ -812,6 +1034,33 @@ func TestCommandsAdministrationServerStatus(t *testing.T) {
 	assert.Equal(t, int32(0), must.NotFail(catalogStats.Get("timeseries")))
 	assert.Equal(t, int32(0), must.NotFail(catalogStats.Get("views")))
 	assert.Equal(t, int32(0), must.NotFail(catalogStats.Get("internalViews")))
+
+	connections, ok := must.NotFail(doc.Get("connections")).(*types.Document)
+	require.True(t, ok)
+	assert.GreaterOrEqual(t, must.NotFail(connections.Get("current")), int32(1))
+	assert.GreaterOrEqual(t, must.NotFail(connections.Get("available")), int32(0))
+
+	opcounters, ok := must.NotFail(doc.Get("opcounters")).(*types.Document)
+	require.True(t, ok)
+
+	for _, k := range []string{"insert", "query", "update", "delete", "getmore"} {
+		assert.GreaterOrEqual(t, must.NotFail(opcounters.Get(k)), int64(0), k)
+	}
+
+	// setup itself runs commands
+	assert.Greater(t, must.NotFail(opcounters.Get("command")), int64(0))
+
+	// the same build details are reported by buildInfo
+	var buildInfo bson.D
+	err = collection.Database().RunCommand(ctx, bson.D{{"buildInfo", int32(1)}}).Decode(&buildInfo)
+	require.NoError(t, err)
+
+	buildInfoDoc := ConvertDocument(t, buildInfo)
+
+	build, err := doc.GetByPath(types.NewPathFromString("ferretdb.build"))
+	require.NoError(t, err)
+	assert.Equal(t, must.NotFail(buildInfoDoc.Get("ferretdbVersion")), must.NotFail(build.(*types.Document).Get("version")))
+	assert.Equal(t, must.NotFail(buildInfoDoc.Get("gitVersion")), must.NotFail(build.(*types.Document).Get("gitVersion")))
 }
 
 // TestCommandsAdministrationWhatsMyURI tests the `whatsmyuri` command.
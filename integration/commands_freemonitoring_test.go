@@ -27,11 +27,18 @@ import (
 )
 
 func TestCommandsFreeMonitoringGetFreeMonitoringStatus(t *testing.T) {
-	t.Parallel()
+	// not parallel because free monitoring state is server-wide
 	s := setup.SetupWithOpts(t, &setup.SetupOpts{
 		DatabaseName: "admin",
 	})
 
+	// MongoDB and Tigris handler have free monitoring disabled,
+	// `pg` handler stores the state, so it is set first
+	if setup.IsPg() {
+		err := s.Collection.Database().RunCommand(s.Ctx, bson.D{{"setFreeMonitoring", 1}, {"action", "disable"}}).Err()
+		require.NoError(t, err)
+	}
+
 	expected := map[string]any{
 		"state": "disabled",
 		"ok":    float64(1),
@@ -64,8 +71,10 @@ func TestCommandsFreeMonitoringSetFreeMonitoring(t *testing.T) {
 	})
 
 	for name, tc := range map[string]struct {
-		command bson.D
-		err     *mongo.CommandError
+		command    bson.D
+		err        *mongo.CommandError
+		altMessage string
+		pgOK       bool // `pg` handler stores the state instead of returning err
 	}{
 		"Enable": {
 			command: bson.D{{"setFreeMonitoring", 1}, {"action", "enable"}},
@@ -74,6 +83,7 @@ func TestCommandsFreeMonitoringSetFreeMonitoring(t *testing.T) {
 				Name:    "Location50840",
 				Message: `Free Monitoring has been disabled via the command-line and/or config file`,
 			},
+			pgOK: true,
 		},
 		"Disable": {
 			command: bson.D{{"setFreeMonitoring", 1}, {"action", "disable"}},
@@ -82,6 +92,7 @@ func TestCommandsFreeMonitoringSetFreeMonitoring(t *testing.T) {
 				Name:    "Location50840",
 				Message: `Free Monitoring has been disabled via the command-line and/or config file`,
 			},
+			pgOK: true,
 		},
 		"Other": {
 			command: bson.D{{"setFreeMonitoring", 1}, {"action", "foobar"}},
@@ -90,6 +101,8 @@ func TestCommandsFreeMonitoringSetFreeMonitoring(t *testing.T) {
 				Name:    "BadValue",
 				Message: `Enumeration value 'foobar' for field 'setFreeMonitoring.action' is not a valid value.`,
 			},
+			altMessage: `Enumeration value 'foobar' for field 'setFreeMonitoring.action' is not a valid value. ` +
+				`Expected one of: 'enable', 'disable'.`,
 		},
 		"Empty": {
 			command: bson.D{{"setFreeMonitoring", 1}, {"action", ""}},
@@ -98,6 +111,8 @@ func TestCommandsFreeMonitoringSetFreeMonitoring(t *testing.T) {
 				Name:    "BadValue",
 				Message: `Enumeration value '' for field 'setFreeMonitoring.action' is not a valid value.`,
 			},
+			altMessage: `Enumeration value '' for field 'setFreeMonitoring.action' is not a valid value. ` +
+				`Expected one of: 'enable', 'disable'.`,
 		},
 	} {
 		name, tc := name, tc
@@ -108,8 +123,14 @@ func TestCommandsFreeMonitoringSetFreeMonitoring(t *testing.T) {
 			var actual bson.D
 			err := s.Collection.Database().RunCommand(s.Ctx, tc.command).Decode(&actual)
 
+			if tc.pgOK && setup.IsPg() {
+				require.NoError(t, err)
+				assert.Equal(t, bson.D{{"ok", float64(1)}}, actual)
+				return
+			}
+
 			if tc.err != nil {
-				AssertEqualError(t, *tc.err, err)
+				AssertEqualAltError(t, *tc.err, tc.altMessage, err)
 				return
 			}
 
@@ -117,3 +138,49 @@ func TestCommandsFreeMonitoringSetFreeMonitoring(t *testing.T) {
 		})
 	}
 }
+
+func TestCommandsFreeMonitoringCycle(t *testing.T) {
+	// not parallel because free monitoring state is server-wide
+	setup.SkipForTigrisWithReason(t, "Tigris handler does not store free monitoring state")
+
+	if !setup.IsPg() {
+		t.Skip("Free monitoring is disabled for the target system")
+	}
+
+	s := setup.SetupWithOpts(t, &setup.SetupOpts{
+		DatabaseName: "admin",
+	})
+	db := s.Collection.Database()
+
+	checkState := func(t *testing.T, expected string) {
+		t.Helper()
+
+		var status bson.D
+		err := db.RunCommand(s.Ctx, bson.D{{"getFreeMonitoringStatus", 1}}).Decode(&status)
+		require.NoError(t, err)
+		assert.Equal(t, expected, status.Map()["state"])
+
+		var serverStatus bson.D
+		err = db.RunCommand(s.Ctx, bson.D{{"serverStatus", 1}}).Decode(&serverStatus)
+		require.NoError(t, err)
+
+		freeMonitoring, ok := serverStatus.Map()["freeMonitoring"].(bson.D)
+		require.True(t, ok)
+		assert.Equal(t, expected, freeMonitoring.Map()["state"])
+	}
+
+	for _, step := range []struct {
+		action string
+		state  string
+	}{
+		{action: "enable", state: "enabled"},
+		{action: "disable", state: "disabled"},
+	} {
+		var res bson.D
+		err := db.RunCommand(s.Ctx, bson.D{{"setFreeMonitoring", 1}, {"action", step.action}}).Decode(&res)
+		require.NoError(t, err)
+		assert.Equal(t, float64(1), res.Map()["ok"])
+
+		checkState(t, step.state)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+	"github.com/FerretDB/FerretDB/integration/shareddata"
+)
+
+// TestCommandsGenericFields checks that commands do not fail
+// when they contain fields that drivers and shells add to any command.
+//
+// lsid is not set there because the driver adds it itself.
+func TestCommandsGenericFields(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t, shareddata.Int32s)
+
+	db := collection.Database()
+	c := collection.Name()
+
+	generic := bson.D{
+		{"$readPreference", bson.D{{"mode", "primary"}}},
+		{"apiVersion", "1"},
+		{"$clusterTime", bson.D{
+			{"clusterTime", primitive.Timestamp{T: uint32(time.Now().Unix()), I: 1}},
+			{"signature", bson.D{
+				{"hash", primitive.Binary{Data: make([]byte, 20)}},
+				{"keyId", int64(0)},
+			}},
+		}},
+	}
+
+	for name, tc := range map[string]struct { //nolint:paralleltest // false positive
+		command bson.D
+	}{
+		"Ping":             {command: bson.D{{"ping", int32(1)}}},
+		"BuildInfo":        {command: bson.D{{"buildInfo", int32(1)}}},
+		"Hello":            {command: bson.D{{"hello", int32(1)}}},
+		"IsMaster":         {command: bson.D{{"isMaster", int32(1)}}},
+		"ConnectionStatus": {command: bson.D{{"connectionStatus", int32(1)}}},
+		"HostInfo":         {command: bson.D{{"hostInfo", int32(1)}}},
+		"ServerStatus":     {command: bson.D{{"serverStatus", int32(1)}}},
+		"WhatsMyURI":       {command: bson.D{{"whatsmyuri", int32(1)}}},
+		"DBStats":          {command: bson.D{{"dbStats", int32(1)}}},
+		"CollStats":        {command: bson.D{{"collStats", c}}},
+		"ListCollections":  {command: bson.D{{"listCollections", int32(1)}}},
+		"DataSize":         {command: bson.D{{"dataSize", db.Name() + "." + c}}},
+		"Find": {
+			command: bson.D{{"find", c}, {"filter", bson.D{}}, {"tailable", false}, {"collation", bson.D{}}},
+		},
+		"Count":    {command: bson.D{{"count", c}, {"query", bson.D{}}}},
+		"Distinct": {command: bson.D{{"distinct", c}, {"key", "v"}}},
+		"Insert": {
+			command: bson.D{{"insert", c}, {"documents", bson.A{bson.D{{"_id", "generic"}, {"v", int32(42)}}}}},
+		},
+		"Update": {
+			command: bson.D{
+				{"update", c},
+				{"updates", bson.A{bson.D{{"q", bson.D{{"_id", "int32"}}}, {"u", bson.D{{"$set", bson.D{{"v", int32(43)}}}}}}}},
+			},
+		},
+		"FindAndModify": {
+			command: bson.D{{"findAndModify", c}, {"query", bson.D{{"_id", "int32-zero"}}}, {"update", bson.D{{"v", int32(0)}}}},
+		},
+		"Delete": {
+			command: bson.D{{"delete", c}, {"deletes", bson.A{bson.D{{"q", bson.D{{"_id", "generic"}}}, {"limit", int32(1)}}}}},
+		},
+		"Create": {command: bson.D{{"create", c + "_generic"}, {"capped", false}}},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			command := append(append(bson.D{}, tc.command...), generic...)
+
+			var res bson.D
+			err := db.RunCommand(ctx, command).Decode(&res)
+			require.NoError(t, err)
+
+			require.Equal(t, float64(1), res.Map()["ok"])
+		})
+	}
+}
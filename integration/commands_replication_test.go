@@ -108,3 +108,29 @@ func TestCommandsReplicationHello(t *testing.T) {
 
 	assert.Equal(t, expected, m)
 }
+
+func TestCommandsReplicationHelloAwaitable(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+	db := collection.Database()
+
+	var actual bson.D
+	err := db.RunCommand(ctx, bson.D{{"hello", 1}}).Decode(&actual)
+	require.NoError(t, err)
+
+	topologyVersion, ok := actual.Map()["topologyVersion"].(bson.D)
+	require.True(t, ok, "%#v", actual)
+
+	// the topology does not change, so the server waits for maxAwaitTimeMS
+	// and replies with the same topologyVersion
+	start := time.Now()
+	err = db.RunCommand(ctx, bson.D{
+		{"hello", 1},
+		{"topologyVersion", topologyVersion},
+		{"maxAwaitTimeMS", int64(500)},
+	}).Decode(&actual)
+	require.NoError(t, err)
+
+	assert.GreaterOrEqual(t, time.Since(start), 500*time.Millisecond)
+	assert.Equal(t, topologyVersion, actual.Map()["topologyVersion"])
+}
@@ -0,0 +1,182 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+)
+
+// countCompatTestCase describes count compatibility test case.
+type countCompatTestCase struct {
+	filter     bson.D                   // required
+	optSkip    any                      // optional, skip option for the count command
+	limit      any                      // optional, limit option for the count command
+	resultType compatTestCaseResultType // defaults to nonEmptyResult
+	skip       string                   // skips test if non-empty
+}
+
+// testCountCompat tests count compatibility test cases.
+func testCountCompat(t *testing.T, testCases map[string]countCompatTestCase) {
+	t.Helper()
+
+	// Use shared setup because count queries can't modify data.
+	ctx, targetCollections, compatCollections := setup.SetupCompat(t)
+
+	for name, tc := range testCases {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+
+			if tc.skip != "" {
+				t.Skip(tc.skip)
+			}
+
+			t.Parallel()
+
+			filter := tc.filter
+			require.NotNil(t, filter)
+
+			var nonEmptyResults bool
+			for i := range targetCollections {
+				targetCollection := targetCollections[i]
+				compatCollection := compatCollections[i]
+				t.Run(targetCollection.Name(), func(t *testing.T) {
+					t.Helper()
+
+					command := bson.D{{"count", targetCollection.Name()}, {"query", filter}}
+					if tc.optSkip != nil {
+						command = append(command, bson.E{"skip", tc.optSkip})
+					}
+					if tc.limit != nil {
+						command = append(command, bson.E{"limit", tc.limit})
+					}
+
+					var targetRes, compatRes bson.D
+					targetErr := targetCollection.Database().RunCommand(ctx, command).Decode(&targetRes)
+					compatErr := compatCollection.Database().RunCommand(ctx, command).Decode(&compatRes)
+
+					if targetErr != nil {
+						t.Logf("Target error: %v", targetErr)
+						targetErr = UnsetRaw(t, targetErr)
+						compatErr = UnsetRaw(t, compatErr)
+						assert.Equal(t, compatErr, targetErr)
+						return
+					}
+					require.NoError(t, compatErr, "compat error")
+
+					t.Logf("Compat (expected) result: %v", compatRes)
+					t.Logf("Target (actual)   result: %v", targetRes)
+					assert.Equal(t, compatRes, targetRes)
+
+					if n, ok := targetRes.Map()["n"]; ok && n != int32(0) {
+						nonEmptyResults = true
+					}
+				})
+			}
+
+			switch tc.resultType {
+			case nonEmptyResult:
+				assert.True(t, nonEmptyResults, "expected non-empty results")
+			case emptyResult:
+				assert.False(t, nonEmptyResults, "expected empty results")
+			default:
+				t.Fatalf("unknown result type %v", tc.resultType)
+			}
+		})
+	}
+}
+
+func TestCountCompat(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]countCompatTestCase{
+		"Empty": {
+			filter: bson.D{},
+		},
+		"IDString": {
+			filter: bson.D{{"_id", "string"}},
+		},
+		"IDNotExists": {
+			filter:     bson.D{{"_id", "count-id-not-exists"}},
+			resultType: emptyResult,
+		},
+		"Exists": {
+			filter: bson.D{{"v", bson.D{{"$exists", true}}}},
+		},
+		"Limit": {
+			filter: bson.D{},
+			limit:  int32(1),
+		},
+		"LimitDouble": {
+			filter: bson.D{{"v", bson.D{{"$exists", true}}}},
+			limit:  float64(2),
+		},
+		"Skip": {
+			filter:  bson.D{},
+			optSkip: int64(1),
+		},
+		"SkipLimit": {
+			filter:  bson.D{{"v", bson.D{{"$exists", true}}}},
+			optSkip: int32(1),
+			limit:   int32(1),
+		},
+		"SkipAll": {
+			filter:     bson.D{},
+			optSkip:    int32(1000),
+			resultType: emptyResult,
+		},
+		"SkipNegative": {
+			filter:     bson.D{},
+			optSkip:    int32(-1),
+			resultType: emptyResult,
+		},
+		"SkipString": {
+			filter:     bson.D{},
+			optSkip:    "1",
+			resultType: emptyResult,
+		},
+		"SkipDouble": {
+			filter:  bson.D{},
+			optSkip: float64(1),
+		},
+		"SkipFractional": {
+			filter:     bson.D{},
+			optSkip:    1.5,
+			resultType: emptyResult,
+		},
+		"LimitNegative": {
+			filter: bson.D{},
+			limit:  int32(-1),
+		},
+		"LimitFractional": {
+			filter:     bson.D{},
+			limit:      1.5,
+			resultType: emptyResult,
+		},
+		"LimitString": {
+			filter:     bson.D{},
+			limit:      "1",
+			resultType: emptyResult,
+		},
+	}
+
+	testCountCompat(t, testCases)
+}
@@ -0,0 +1,137 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+	"github.com/FerretDB/FerretDB/internal/clientconn"
+	"github.com/FerretDB/FerretDB/internal/handlers/registry"
+	"github.com/FerretDB/FerretDB/internal/util/debug"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestDebugState(t *testing.T) {
+	setup.SkipForTigris(t)
+
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(testutil.Ctx(t))
+	defer cancel()
+
+	logger := zaptest.NewLogger(t)
+	metrics := prometheus.NewRegistry()
+
+	h, err := registry.NewHandler("pg", &registry.NewHandlerOpts{
+		Ctx:           ctx,
+		Logger:        logger,
+		Metrics:       metrics,
+		PostgreSQLURL: testutil.PostgreSQLURL(t, nil),
+	})
+	require.NoError(t, err)
+	t.Cleanup(h.Close)
+
+	l := clientconn.NewListener(&clientconn.NewListenerOpts{
+		ListenAddr: "127.0.0.1:0",
+		Mode:       clientconn.NormalMode,
+		Handler:    h,
+		Logger:     logger,
+		Metrics:    metrics,
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.ErrorIs(t, l.Run(ctx), context.Canceled)
+	}()
+	t.Cleanup(func() { <-done })
+
+	srv := httptest.NewServer(debug.StateHandler(map[string]debug.StateProvider{
+		"listener": l,
+		"handler":  h.(debug.StateProvider),
+	}, logger))
+	t.Cleanup(srv.Close)
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(fmt.Sprintf("mongodb://%s/", l.Addr())))
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, client.Disconnect(ctx)) })
+
+	db := client.Database(testutil.DatabaseName(t))
+	collection := db.Collection(testutil.CollectionName(t))
+	t.Cleanup(func() { assert.NoError(t, db.Drop(ctx)) })
+
+	_, err = collection.InsertMany(ctx, []any{
+		bson.D{{"_id", int32(1)}},
+		bson.D{{"_id", int32(2)}},
+		bson.D{{"_id", int32(3)}},
+	})
+	require.NoError(t, err)
+
+	type state struct {
+		Listener struct {
+			Connections []struct {
+				Client string `json:"client"`
+			} `json:"connections"`
+		} `json:"listener"`
+		Handler struct {
+			Cursors []struct {
+				ID int64  `json:"id"`
+				NS string `json:"ns"`
+			} `json:"cursors"`
+		} `json:"handler"`
+	}
+
+	getState := func() *state {
+		resp, err := http.Get(srv.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var s state
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&s))
+
+		return &s
+	}
+
+	cursor, err := collection.Find(ctx, bson.D{}, options.Find().SetBatchSize(1))
+	require.NoError(t, err)
+
+	s := getState()
+	assert.NotEmpty(t, s.Listener.Connections)
+	require.Len(t, s.Handler.Cursors, 1)
+	assert.Equal(t, cursor.ID(), s.Handler.Cursors[0].ID)
+	assert.Equal(t, db.Name()+"."+collection.Name(), s.Handler.Cursors[0].NS)
+
+	// driver sends killCursors for cursors that are not exhausted
+	require.NoError(t, cursor.Close(ctx))
+
+	s = getState()
+	assert.Empty(t, s.Handler.Cursors)
+}
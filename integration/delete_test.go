@@ -20,6 +20,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/FerretDB/FerretDB/integration/setup"
 	"github.com/FerretDB/FerretDB/integration/shareddata"
@@ -55,3 +56,130 @@ func TestDeleteSimple(t *testing.T) {
 		})
 	}
 }
+
+func TestDeleteFilter(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct { //nolint:paralleltest // false positive
+		provider    shareddata.Provider
+		filter      bson.D
+		limit       int32
+		expectedN   int32
+		expectedIDs []any // remaining documents, if deterministic
+	}{
+		"GreaterThan": {
+			provider:    shareddata.Int32s,
+			filter:      bson.D{{"v", bson.D{{"$gt", int32(0)}}}},
+			expectedN:   2,
+			expectedIDs: []any{"int32-min", "int32-zero"},
+		},
+		"In": {
+			provider:    shareddata.Int32s,
+			filter:      bson.D{{"v", bson.D{{"$in", bson.A{int32(0), int32(42)}}}}},
+			expectedN:   2,
+			expectedIDs: []any{"int32-max", "int32-min"},
+		},
+		"StringEquality": {
+			provider:    shareddata.Strings,
+			filter:      bson.D{{"v", "foo"}},
+			expectedN:   1,
+			expectedIDs: []any{"string-double", "string-empty", "string-whole"},
+		},
+		"IDEquality": {
+			provider:    shareddata.Strings,
+			filter:      bson.D{{"_id", "string-empty"}},
+			expectedN:   1,
+			expectedIDs: []any{"string", "string-double", "string-whole"},
+		},
+		"Limit": {
+			provider:  shareddata.Strings,
+			filter:    bson.D{{"v", bson.D{{"$regex", "^42"}}}},
+			limit:     1,
+			expectedN: 1,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Use per-test setup because deletes modify data set.
+			ctx, collection := setup.Setup(t, tc.provider)
+
+			command := bson.D{
+				{"delete", collection.Name()},
+				{"deletes", bson.A{bson.D{{"q", tc.filter}, {"limit", tc.limit}}}},
+			}
+
+			var res bson.D
+			err := collection.Database().RunCommand(ctx, command).Decode(&res)
+			require.NoError(t, err)
+
+			AssertEqualDocuments(t, bson.D{{"n", tc.expectedN}, {"ok", float64(1)}}, res)
+
+			cursor, err := collection.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{"_id", 1}}))
+			require.NoError(t, err)
+
+			var docs []bson.D
+			require.NoError(t, cursor.All(ctx, &docs))
+			assert.Len(t, docs, len(tc.provider.Docs())-int(tc.expectedN))
+
+			if tc.expectedIDs != nil {
+				assert.Equal(t, tc.expectedIDs, CollectIDs(t, docs))
+			}
+		})
+	}
+}
+
+func TestDeleteWriteErrors(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct { //nolint:paralleltest // false positive
+		ordered  bool
+		expected bson.D
+	}{
+		"Ordered": {
+			ordered: true,
+			expected: bson.D{
+				{"n", int32(1)},
+				{"writeErrors", bson.A{
+					bson.D{{"index", int32(1)}, {"code", int32(2)}, {"errmsg", "unknown operator: $bad"}},
+				}},
+				{"ok", float64(1)},
+			},
+		},
+		"Unordered": {
+			ordered: false,
+			expected: bson.D{
+				{"n", int32(2)},
+				{"writeErrors", bson.A{
+					bson.D{{"index", int32(1)}, {"code", int32(2)}, {"errmsg", "unknown operator: $bad"}},
+				}},
+				{"ok", float64(1)},
+			},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Use per-test setup because deletes modify data set.
+			ctx, collection := setup.Setup(t, shareddata.Int32s)
+
+			command := bson.D{
+				{"delete", collection.Name()},
+				{"deletes", bson.A{
+					bson.D{{"q", bson.D{{"v", int32(42)}}}, {"limit", int32(0)}},
+					bson.D{{"q", bson.D{{"v", bson.D{{"$bad", int32(1)}}}}}, {"limit", int32(0)}},
+					bson.D{{"q", bson.D{{"v", int32(0)}}}, {"limit", int32(0)}},
+				}},
+				{"ordered", tc.ordered},
+			}
+
+			var res bson.D
+			err := collection.Database().RunCommand(ctx, command).Decode(&res)
+			require.NoError(t, err)
+
+			AssertEqualDocuments(t, tc.expected, res)
+		})
+	}
+}
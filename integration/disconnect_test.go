@@ -0,0 +1,95 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+	"github.com/FerretDB/FerretDB/integration/shareddata"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+// TestDisconnectCancel checks that PostgreSQL query is canceled when the client disconnects.
+func TestDisconnectCancel(t *testing.T) {
+	setup.SkipForTigris(t)
+
+	t.Parallel()
+
+	ctx, collection := setup.Setup(t, shareddata.Scalars)
+	schema := collection.Database().Name()
+
+	conn, err := pgx.Connect(ctx, testutil.PostgreSQLURL(t, nil))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close(ctx) })
+
+	tx, err := conn.Begin(ctx)
+	require.NoError(t, err)
+	t.Cleanup(func() { tx.Rollback(ctx) })
+
+	// block all queries to the schema's tables until the transaction ends
+	rows, err := tx.Query(ctx, `SELECT tablename FROM pg_tables WHERE schemaname = $1`, schema)
+	require.NoError(t, err)
+
+	var tables []string
+	for rows.Next() {
+		var table string
+		require.NoError(t, rows.Scan(&table))
+		tables = append(tables, table)
+	}
+	require.NoError(t, rows.Err())
+	require.NotEmpty(t, tables)
+
+	for _, table := range tables {
+		_, err = tx.Exec(ctx, `LOCK TABLE `+pgx.Identifier{schema, table}.Sanitize()+` IN ACCESS EXCLUSIVE MODE`)
+		require.NoError(t, err)
+	}
+
+	waiting := func() int {
+		var count int
+		err := conn.QueryRow(
+			ctx,
+			`SELECT count(*) FROM pg_stat_activity `+
+				`WHERE wait_event_type = 'Lock' AND pid <> pg_backend_pid() AND query LIKE '%' || $1 || '%'`,
+			schema,
+		).Scan(&count)
+		require.NoError(t, err)
+		return count
+	}
+
+	// the driver closes the connection when the context is done
+	findCtx, findCancel := context.WithCancel(ctx)
+	defer findCancel()
+
+	findDone := make(chan error, 1)
+	go func() {
+		_, err := collection.Find(findCtx, bson.D{})
+		findDone <- err
+	}()
+
+	assert.Eventually(t, func() bool { return waiting() > 0 }, 10*time.Second, 50*time.Millisecond)
+
+	findCancel()
+	require.Error(t, <-findDone)
+
+	assert.Eventually(t, func() bool { return waiting() == 0 }, 5*time.Second, 50*time.Millisecond)
+}
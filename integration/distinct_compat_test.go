@@ -0,0 +1,130 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+)
+
+// distinctCompatTestCase describes distinct compatibility test case.
+type distinctCompatTestCase struct {
+	key        string                   // required
+	filter     bson.D                   // defaults to empty filter
+	resultType compatTestCaseResultType // defaults to nonEmptyResult
+	skip       string                   // skips test if non-empty
+}
+
+// testDistinctCompat tests distinct compatibility test cases.
+func testDistinctCompat(t *testing.T, testCases map[string]distinctCompatTestCase) {
+	t.Helper()
+
+	// Use shared setup because distinct queries can't modify data.
+	ctx, targetCollections, compatCollections := setup.SetupCompat(t)
+
+	for name, tc := range testCases {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+
+			if tc.skip != "" {
+				t.Skip(tc.skip)
+			}
+
+			t.Parallel()
+
+			require.NotEmpty(t, tc.key)
+
+			filter := tc.filter
+			if filter == nil {
+				filter = bson.D{}
+			}
+
+			var nonEmptyResults bool
+			for i := range targetCollections {
+				targetCollection := targetCollections[i]
+				compatCollection := compatCollections[i]
+				t.Run(targetCollection.Name(), func(t *testing.T) {
+					t.Helper()
+
+					targetRes, targetErr := targetCollection.Distinct(ctx, tc.key, filter)
+					compatRes, compatErr := compatCollection.Distinct(ctx, tc.key, filter)
+
+					if targetErr != nil {
+						t.Logf("Target error: %v", targetErr)
+						targetErr = UnsetRaw(t, targetErr)
+						compatErr = UnsetRaw(t, compatErr)
+						assert.Equal(t, compatErr, targetErr)
+						return
+					}
+					require.NoError(t, compatErr, "compat error")
+
+					t.Logf("Compat (expected) values: %v", compatRes)
+					t.Logf("Target (actual)   values: %v", targetRes)
+					assert.Equal(t, compatRes, targetRes)
+
+					if len(targetRes) > 0 || len(compatRes) > 0 {
+						nonEmptyResults = true
+					}
+				})
+			}
+
+			switch tc.resultType {
+			case nonEmptyResult:
+				assert.True(t, nonEmptyResults, "expected non-empty results")
+			case emptyResult:
+				assert.False(t, nonEmptyResults, "expected empty results")
+			default:
+				t.Fatalf("unknown result type %v", tc.resultType)
+			}
+		})
+	}
+}
+
+func TestDistinctCompat(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]distinctCompatTestCase{
+		"ID": {
+			key: "_id",
+		},
+		"Value": {
+			key: "v",
+		},
+		"ValueFilter": {
+			key:    "v",
+			filter: bson.D{{"v", bson.D{{"$exists", true}}}},
+		},
+		"Dotted": {
+			key: "v.foo",
+		},
+		"Missing": {
+			key:        "no-such-field",
+			resultType: emptyResult,
+		},
+		"FilterEmptyResult": {
+			key:        "v",
+			filter:     bson.D{{"_id", "distinct-no-such-id"}},
+			resultType: emptyResult,
+		},
+	}
+
+	testDistinctCompat(t, testCases)
+}
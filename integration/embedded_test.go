@@ -16,6 +16,10 @@ package integration
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -24,6 +28,9 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
 
 	"github.com/FerretDB/FerretDB/ferretdb"
 	"github.com/FerretDB/FerretDB/integration/setup"
@@ -35,43 +42,183 @@ func TestEmbedded(t *testing.T) {
 
 	t.Parallel()
 
+	ctx, cancel := context.WithCancel(testutil.Ctx(t))
+	defer cancel()
+
+	// multiple instances in the same process should not conflict
+	var uris []string
+	var dones []chan struct{}
+	for i := 0; i < 2; i++ {
+		f, err := ferretdb.New(&ferretdb.Config{
+			ListenAddr:    "127.0.0.1:0",
+			Handler:       "pg",
+			PostgreSQLURL: testutil.PostgreSQLURL(t, nil),
+		})
+		require.NoError(t, err)
+
+		// check that Run exits on context cancel
+		done := make(chan struct{})
+		go func() {
+			err := f.Run(ctx)
+			assert.ErrorIs(t, err, context.Canceled)
+			close(done)
+		}()
+
+		uris = append(uris, f.MongoDBURI())
+		dones = append(dones, done)
+	}
+
+	assert.NotEqual(t, uris[0], uris[1])
+
+	filter := bson.D{{
+		"name",
+		bson.D{{
+			"$not",
+			bson.D{{
+				"$regex",
+				primitive.Regex{Pattern: "test.*"},
+			}},
+		}},
+	}}
+
+	for _, uri := range uris {
+		client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+		require.NoError(t, err)
+
+		names, err := client.ListDatabaseNames(ctx, filter)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"admin", "public"}, names)
+
+		require.NoError(t, client.Disconnect(ctx))
+	}
+
+	cancel()
+
+	for _, done := range dones {
+		<-done
+	}
+}
+
+func Example_embedded() {
 	f, err := ferretdb.New(&ferretdb.Config{
+		ListenAddr:    "127.0.0.1:0",
 		Handler:       "pg",
-		PostgreSQLURL: testutil.PostgreSQLURL(t, nil),
+		PostgreSQLURL: "postgres://postgres@127.0.0.1:5432/ferretdb",
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		if err := f.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			log.Print(err)
+		}
+	}()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(f.MongoDBURI()))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	res, err := client.Database("test").Collection("example").InsertOne(ctx, bson.D{{"answer", int32(42)}})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(res.InsertedID != nil)
+
+	if err = client.Disconnect(ctx); err != nil {
+		log.Fatal(err)
+	}
+
+	cancel()
+	<-done
+}
+
+func TestEmbeddedTracing(t *testing.T) {
+	setup.SkipForTigris(t)
+
+	t.Parallel()
+
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	f, err := ferretdb.New(&ferretdb.Config{
+		ListenAddr:     "127.0.0.1:0",
+		Handler:        "pg",
+		PostgreSQLURL:  testutil.PostgreSQLURL(t, nil),
+		TracerProvider: tp,
 	})
 	require.NoError(t, err)
 
 	ctx, cancel := context.WithCancel(testutil.Ctx(t))
 	defer cancel()
 
-	// check that Run exits on context cancel
 	done := make(chan struct{})
 	go func() {
-		err := f.Run(ctx)
-		t.Logf("Run exited with %v.", err) // result is undefined for now
-		cancel()
+		assert.ErrorIs(t, f.Run(ctx), context.Canceled)
 		close(done)
 	}()
 
 	client, err := mongo.Connect(ctx, options.Client().ApplyURI(f.MongoDBURI()))
 	require.NoError(t, err)
 
-	filter := bson.D{{
-		"name",
-		bson.D{{
-			"$not",
-			bson.D{{
-				"$regex",
-				primitive.Regex{Pattern: "test.*"},
-			}},
-		}},
-	}}
-	names, err := client.ListDatabaseNames(ctx, filter)
+	db := client.Database(testutil.DatabaseName(t))
+	collection := db.Collection(testutil.CollectionName(t))
+
+	t.Cleanup(func() {
+		require.NoError(t, db.Drop(ctx))
+	})
+
+	_, err = collection.InsertOne(ctx, bson.D{{"_id", "secret"}, {"v", "secret value"}})
 	require.NoError(t, err)
-	assert.Equal(t, []string{"admin", "public"}, names)
+
+	exporter.Reset()
+
+	// a find in a transaction performs several PostgreSQL queries (at least BEGIN, SELECT, and COMMIT)
+	cursor, err := collection.Find(ctx, bson.D{{"v", "secret value"}})
+	require.NoError(t, err)
+	require.NoError(t, cursor.All(ctx, new([]bson.D)))
 
 	require.NoError(t, client.Disconnect(ctx))
 
 	cancel()
 	<-done
+
+	var findSpan *tracetest.SpanStub
+	spans := exporter.GetSpans()
+	for i, span := range spans {
+		if span.Name == "find" {
+			findSpan = &spans[i]
+			break
+		}
+	}
+	require.NotNil(t, findSpan)
+
+	assert.Contains(t, findSpan.Attributes, semconv.DBNameKey.String(testutil.DatabaseName(t)))
+	assert.Contains(t, findSpan.Attributes, semconv.DBMongoDBCollectionKey.String(testutil.CollectionName(t)))
+
+	var queries int
+	for _, span := range spans {
+		if span.Parent.SpanID() != findSpan.SpanContext.SpanID() {
+			continue
+		}
+
+		queries++
+
+		assert.True(t, strings.HasPrefix(span.Name, "pgdb."), "%s", span.Name)
+		assert.Equal(t, findSpan.SpanContext.TraceID(), span.SpanContext.TraceID())
+
+		// document contents are never recorded
+		for _, attr := range span.Attributes {
+			assert.NotContains(t, attr.Value.Emit(), "secret")
+		}
+	}
+
+	assert.GreaterOrEqual(t, queries, 2)
 }
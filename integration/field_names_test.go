@@ -0,0 +1,123 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+)
+
+func TestFieldNamesInsert(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	t.Run("TopLevelDollar", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := collection.InsertOne(ctx, bson.D{{"_id", "dollar"}, {"$foo", int32(1)}})
+		AssertEqualWriteError(t, mongo.WriteError{
+			Code:    2,
+			Message: "Document can't have $ prefixed field names: $foo",
+		}, err)
+	})
+
+	t.Run("Allowed", func(t *testing.T) {
+		setup.SkipForTigris(t)
+
+		t.Parallel()
+
+		for name, doc := range map[string]bson.D{
+			"Dotted":         {{"_id", "dotted"}, {"a.b", int32(1)}},
+			"EmbeddedDollar": {{"_id", "embedded-dollar"}, {"a", bson.D{{"$bar", int32(1)}}}},
+			"EmbeddedDotted": {{"_id", "embedded-dotted"}, {"a", bson.D{{"b.c", int32(1)}}}},
+		} {
+			name, doc := name, doc
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+
+				_, err := collection.InsertOne(ctx, doc)
+				require.NoError(t, err)
+
+				var actual bson.D
+				err = collection.FindOne(ctx, bson.D{{"_id", doc[0].Value}}).Decode(&actual)
+				require.NoError(t, err)
+				AssertEqualDocuments(t, doc, actual)
+			})
+		}
+	})
+}
+
+func TestFieldNamesDottedFilter(t *testing.T) {
+	setup.SkipForTigris(t)
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "literal"}, {"a.b", int32(1)}},
+		bson.D{{"_id", "path"}, {"a", bson.D{{"b", int32(1)}}}},
+	})
+	require.NoError(t, err)
+
+	// dots in filters are always interpreted as paths
+	cursor, err := collection.Find(ctx, bson.D{{"a.b", int32(1)}})
+	require.NoError(t, err)
+
+	var actual []bson.D
+	require.NoError(t, cursor.All(ctx, &actual))
+	assert.Equal(t, []any{"path"}, CollectIDs(t, actual))
+}
+
+func TestFieldNamesUpdate(t *testing.T) {
+	setup.SkipForTigris(t)
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	_, err := collection.InsertOne(ctx, bson.D{{"_id", int32(1)}})
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		update bson.D
+		err    string
+	}{
+		"Set": {
+			update: bson.D{{"$set", bson.D{{"$foo", int32(1)}}}},
+			err:    "The dollar ($) prefixed field '$foo' in '$foo' is not valid for storage.",
+		},
+		"SetDotted": {
+			update: bson.D{{"$set", bson.D{{"$foo.bar", int32(1)}}}},
+			err:    "The dollar ($) prefixed field '$foo' in '$foo.bar' is not valid for storage.",
+		},
+		"Inc": {
+			update: bson.D{{"$inc", bson.D{{"$foo", int32(1)}}}},
+			err:    "The dollar ($) prefixed field '$foo' in '$foo' is not valid for storage.",
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := collection.UpdateOne(ctx, bson.D{{"_id", int32(1)}}, tc.update)
+			AssertEqualWriteError(t, mongo.WriteError{Code: 52, Message: tc.err}, err)
+		})
+	}
+}
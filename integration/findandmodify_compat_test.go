@@ -0,0 +1,198 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// findAndModifyCompatTestCase describes findAndModify compatibility test case.
+type findAndModifyCompatTestCase struct {
+	command    bson.D                   // required, findAndModify command parameters except collection name
+	resultType compatTestCaseResultType // defaults to nonEmptyResult
+	skip       string                   // skips test if non-empty
+}
+
+func TestFindAndModifyCompat(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]findAndModifyCompatTestCase{
+		"Remove": {
+			command: bson.D{
+				{"query", bson.D{}},
+				{"sort", bson.D{{"_id", 1}}},
+				{"remove", true},
+			},
+		},
+		"RemoveSortDesc": {
+			command: bson.D{
+				{"query", bson.D{}},
+				{"sort", bson.D{{"_id", -1}}},
+				{"remove", true},
+			},
+		},
+		"RemoveNotFound": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "no-such-doc"}}},
+				{"remove", true},
+			},
+			resultType: emptyResult,
+		},
+		"UpdateSet": {
+			command: bson.D{
+				{"query", bson.D{}},
+				{"sort", bson.D{{"_id", 1}}},
+				{"update", bson.D{{"$set", bson.D{{"foo", "bar"}}}}},
+			},
+		},
+		"UpdateSetNew": {
+			command: bson.D{
+				{"query", bson.D{}},
+				{"sort", bson.D{{"_id", -1}}},
+				{"update", bson.D{{"$set", bson.D{{"foo", "bar"}}}}},
+				{"new", true},
+			},
+		},
+		"UpdateFields": {
+			command: bson.D{
+				{"query", bson.D{}},
+				{"sort", bson.D{{"_id", 1}}},
+				{"update", bson.D{{"$set", bson.D{{"foo", "bar"}}}}},
+				{"new", true},
+				{"fields", bson.D{{"foo", 1}}},
+			},
+		},
+		"Replace": {
+			command: bson.D{
+				{"query", bson.D{}},
+				{"sort", bson.D{{"_id", 1}}},
+				{"update", bson.D{{"foo", "bar"}}},
+				{"new", true},
+			},
+		},
+		"UpdateNotFound": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "no-such-doc"}}},
+				{"update", bson.D{{"$set", bson.D{{"foo", "bar"}}}}},
+			},
+			resultType: emptyResult,
+		},
+		"Upsert": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "no-such-doc"}}},
+				{"update", bson.D{{"$set", bson.D{{"foo", "bar"}}}}},
+				{"upsert", true},
+				{"new", true},
+			},
+		},
+		"UpsertReplace": {
+			command: bson.D{
+				{"query", bson.D{{"_id", "no-such-doc"}}},
+				{"update", bson.D{{"foo", "bar"}}},
+				{"upsert", true},
+				{"new", true},
+			},
+		},
+	}
+
+	testFindAndModifyCompat(t, testCases)
+}
+
+// testFindAndModifyCompat tests findAndModify compatibility test cases.
+func testFindAndModifyCompat(t *testing.T, testCases map[string]findAndModifyCompatTestCase) {
+	t.Helper()
+
+	for name, tc := range testCases {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Helper()
+
+			if tc.skip != "" {
+				t.Skip(tc.skip)
+			}
+
+			t.Parallel()
+
+			// Use per-test setup because findAndModify modifies data set.
+			ctx, targetCollections, compatCollections := setup.SetupCompat(t)
+
+			command := tc.command
+			require.NotNil(t, command, "command should be set")
+
+			var nonEmptyResults bool
+			for i := range targetCollections {
+				targetCollection := targetCollections[i]
+				compatCollection := compatCollections[i]
+				t.Run(targetCollection.Name(), func(t *testing.T) {
+					t.Helper()
+
+					targetCommand := append(bson.D{{"findAndModify", targetCollection.Name()}}, command...)
+					compatCommand := append(bson.D{{"findAndModify", compatCollection.Name()}}, command...)
+
+					var targetRes, compatRes bson.D
+					targetErr := targetCollection.Database().RunCommand(ctx, targetCommand).Decode(&targetRes)
+					compatErr := compatCollection.Database().RunCommand(ctx, compatCommand).Decode(&compatRes)
+
+					if targetErr != nil {
+						t.Logf("Target error: %v", targetErr)
+						targetErr = UnsetRaw(t, targetErr)
+						compatErr = UnsetRaw(t, compatErr)
+
+						// Skip modifications that could not be performed due to Tigris schema validation.
+						if e, ok := targetErr.(mongo.CommandError); ok && e.Name == "DocumentValidationFailure" {
+							if e.HasErrorCodeWithMessage(121, "json schema validation failed for field") {
+								setup.SkipForTigrisWithReason(t, targetErr.Error())
+							}
+						}
+
+						assert.Equal(t, compatErr, targetErr)
+						return
+					}
+					require.NoError(t, compatErr, "compat error")
+
+					AssertEqualDocuments(t, compatRes, targetRes)
+
+					if n, _ := ConvertDocument(t, targetRes).GetByPath(types.NewPath([]string{"lastErrorObject", "n"})); n != int32(0) {
+						nonEmptyResults = true
+					}
+
+					targetDocs := FindAll(t, ctx, targetCollection)
+					compatDocs := FindAll(t, ctx, compatCollection)
+
+					t.Logf("Compat (expected) IDs: %v", CollectIDs(t, compatDocs))
+					t.Logf("Target (actual)   IDs: %v", CollectIDs(t, targetDocs))
+					AssertEqualDocumentsSlice(t, compatDocs, targetDocs)
+				})
+			}
+
+			switch tc.resultType {
+			case nonEmptyResult:
+				assert.True(t, nonEmptyResults, "expected non-empty results (some documents should be modified)")
+			case emptyResult:
+				assert.False(t, nonEmptyResults, "expected empty results (no documents should be modified)")
+			default:
+				t.Fatalf("unknown result type %v", tc.resultType)
+			}
+		})
+	}
+}
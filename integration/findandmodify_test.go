@@ -312,6 +312,76 @@ func TestFindAndModifyErrors(t *testing.T) {
 	}
 }
 
+// TestFindAndModifyErrorsInt32s checks parameters validation with data supported by all handlers.
+func TestFindAndModifyErrorsInt32s(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		command    bson.D
+		err        *mongo.CommandError
+		altMessage string
+	}{
+		"EmptyCollectionName": {
+			command: bson.D{{"findAndModify", ""}},
+			err: &mongo.CommandError{
+				Code:    73,
+				Message: "Invalid namespace specified 'testfindandmodifyerrorsint32s_emptycollectionname.'",
+				Name:    "InvalidNamespace",
+			},
+		},
+		"NotEnoughParameters": {
+			err: &mongo.CommandError{
+				Code:    9,
+				Message: "Either an update or remove=true must be specified",
+				Name:    "FailedToParse",
+			},
+		},
+		"UpdateAndRemove": {
+			command: bson.D{
+				{"update", bson.D{}},
+				{"remove", true},
+			},
+			err: &mongo.CommandError{
+				Code:    9,
+				Name:    "FailedToParse",
+				Message: "Cannot specify both an update and remove=true",
+			},
+		},
+		"BadSortType": {
+			command: bson.D{
+				{"update", bson.D{}},
+				{"sort", "123"},
+			},
+			err: &mongo.CommandError{
+				Code:    14,
+				Name:    "TypeMismatch",
+				Message: "BSON field 'findAndModify.sort' is the wrong type 'string', expected type 'object'",
+			},
+			altMessage: "BSON field 'sort' is the wrong type 'string', expected type 'object'",
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx, collection := setup.Setup(t, shareddata.Int32s)
+
+			command := tc.command
+			if command.Map()["findAndModify"] == nil {
+				command = append(bson.D{{"findAndModify", collection.Name()}}, command...)
+
+				if command.Map()["sort"] == nil {
+					command = append(command, bson.D{{"sort", bson.D{{"_id", 1}}}}...)
+				}
+			}
+
+			var actual bson.D
+			err := collection.Database().RunCommand(ctx, command).Decode(&actual)
+
+			AssertEqualAltError(t, *tc.err, tc.altMessage, err)
+		})
+	}
+}
+
 func TestFindAndModifyUpdate(t *testing.T) {
 	setup.SkipForTigris(t)
 
@@ -690,8 +760,6 @@ func TestFindAndModifyRemove(t *testing.T) {
 }
 
 func TestFindAndModifyBadMaxTimeMSType(t *testing.T) {
-	setup.SkipForTigris(t) // FindAndModify is not implemented for Tigris yet
-
 	t.Parallel()
 	ctx, collection := setup.Setup(t)
 
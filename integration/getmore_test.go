@@ -0,0 +1,282 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// insertGetMoreDocs inserts n documents with int32 _id from 0 to n-1 and returns them.
+func insertGetMoreDocs(t *testing.T, ctx context.Context, collection *mongo.Collection, n int) []bson.D {
+	t.Helper()
+
+	docs := make([]bson.D, n)
+	insert := make([]any, n)
+	for i := range docs {
+		docs[i] = bson.D{{"_id", int32(i)}, {"v", int32(i % 3)}}
+		insert[i] = docs[i]
+	}
+
+	_, err := collection.InsertMany(ctx, insert)
+	require.NoError(t, err)
+
+	return docs
+}
+
+func TestGetMore(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	docs := insertGetMoreDocs(t, ctx, collection, 10)
+
+	for name, tc := range map[string]struct {
+		filter    bson.D
+		opts      *options.FindOptions
+		expected  []bson.D
+		unordered bool // Tigris returns documents without sort in its own order
+	}{
+		"Sort": {
+			filter:   bson.D{},
+			opts:     options.Find().SetSort(bson.D{{"_id", 1}}).SetBatchSize(3),
+			expected: docs,
+		},
+		"NoSort": {
+			filter:    bson.D{},
+			opts:      options.Find().SetBatchSize(3),
+			expected:  docs,
+			unordered: true,
+		},
+		"FilterLimit": {
+			filter:   bson.D{{"v", int32(1)}},
+			opts:     options.Find().SetSort(bson.D{{"_id", -1}}).SetBatchSize(1).SetLimit(2),
+			expected: []bson.D{docs[7], docs[4]},
+		},
+		"FilterProjection": {
+			filter:    bson.D{{"v", int32(2)}},
+			opts:      options.Find().SetProjection(bson.D{{"v", 0}}).SetBatchSize(2),
+			expected:  []bson.D{{{"_id", int32(2)}}, {{"_id", int32(5)}}, {{"_id", int32(8)}}},
+			unordered: true,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cursor, err := collection.Find(ctx, tc.filter, tc.opts)
+			require.NoError(t, err)
+			defer cursor.Close(ctx)
+
+			// the first batch does not contain all documents, so the cursor is kept open
+			assert.NotZero(t, cursor.ID())
+
+			var actual []bson.D
+			require.NoError(t, cursor.All(ctx, &actual))
+
+			if tc.unordered {
+				assert.ElementsMatch(t, CollectIDs(t, tc.expected), CollectIDs(t, actual))
+				return
+			}
+
+			AssertEqualDocumentsSlice(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestGetMoreSingleBatch(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	insertGetMoreDocs(t, ctx, collection, 5)
+
+	var res bson.D
+	err := collection.Database().RunCommand(ctx, bson.D{
+		{"find", collection.Name()},
+		{"batchSize", int32(2)},
+		{"singleBatch", true},
+	}).Decode(&res)
+	require.NoError(t, err)
+
+	c, ok := res.Map()["cursor"].(bson.D)
+	require.True(t, ok)
+	assert.Equal(t, int64(0), c.Map()["id"])
+	assert.Len(t, c.Map()["firstBatch"], 2)
+}
+
+func TestGetMoreNegativeLimit(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	expected := insertGetMoreDocs(t, ctx, collection, 5)
+
+	// the driver sends negative limit as a positive limit with singleBatch
+	cursor, err := collection.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{"_id", 1}}).SetLimit(-3))
+	require.NoError(t, err)
+	defer cursor.Close(ctx)
+
+	assert.Equal(t, int64(0), cursor.ID())
+
+	var actual []bson.D
+	require.NoError(t, cursor.All(ctx, &actual))
+	AssertEqualDocumentsSlice(t, expected[:3], actual)
+}
+
+func TestGetMoreKillCursors(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	insertGetMoreDocs(t, ctx, collection, 5)
+
+	db := collection.Database()
+
+	var res bson.D
+	err := db.RunCommand(ctx, bson.D{{"find", collection.Name()}, {"batchSize", int32(2)}}).Decode(&res)
+	require.NoError(t, err)
+
+	id, ok := res.Map()["cursor"].(bson.D).Map()["id"].(int64)
+	require.True(t, ok)
+	require.NotZero(t, id)
+
+	err = db.RunCommand(ctx, bson.D{{"getMore", id}, {"collection", "other"}}).Err()
+	AssertEqualError(t, mongo.CommandError{
+		Code: 2,
+		Name: "BadValue",
+		Message: "Requested getMore on namespace '" + db.Name() + ".other', " +
+			"but cursor belongs to a different namespace " + db.Name() + "." + collection.Name(),
+	}, err)
+
+	err = db.RunCommand(ctx, bson.D{
+		{"getMore", id},
+		{"collection", collection.Name()},
+		{"batchSize", int32(1)},
+	}).Decode(&res)
+	require.NoError(t, err)
+
+	c := res.Map()["cursor"].(bson.D)
+	assert.Equal(t, id, c.Map()["id"])
+	assert.Len(t, c.Map()["nextBatch"], 1)
+
+	err = db.RunCommand(ctx, bson.D{{"killCursors", collection.Name()}, {"cursors", bson.A{id, int64(42)}}}).Decode(&res)
+	require.NoError(t, err)
+
+	expected := bson.D{
+		{"cursorsKilled", bson.A{id}},
+		{"cursorsNotFound", bson.A{int64(42)}},
+		{"cursorsAlive", bson.A{}},
+		{"cursorsUnknown", bson.A{}},
+		{"ok", float64(1)},
+	}
+	AssertEqualDocuments(t, expected, res)
+
+	err = db.RunCommand(ctx, bson.D{{"getMore", id}, {"collection", collection.Name()}}).Err()
+	AssertEqualError(t, mongo.CommandError{
+		Code:    43,
+		Name:    "CursorNotFound",
+		Message: "cursor id " + fmt.Sprint(id) + " not found",
+	}, err)
+}
+
+func TestGetMoreExhausted(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	insertGetMoreDocs(t, ctx, collection, 3)
+
+	db := collection.Database()
+
+	var res bson.D
+	err := db.RunCommand(ctx, bson.D{{"find", collection.Name()}, {"batchSize", int32(2)}}).Decode(&res)
+	require.NoError(t, err)
+
+	id := res.Map()["cursor"].(bson.D).Map()["id"].(int64)
+	require.NotZero(t, id)
+
+	err = db.RunCommand(ctx, bson.D{{"getMore", id}, {"collection", collection.Name()}}).Decode(&res)
+	require.NoError(t, err)
+
+	c := res.Map()["cursor"].(bson.D)
+	assert.Equal(t, int64(0), c.Map()["id"])
+	assert.Len(t, c.Map()["nextBatch"], 1)
+
+	// exhausted cursor is removed
+	err = db.RunCommand(ctx, bson.D{{"killCursors", collection.Name()}, {"cursors", bson.A{id}}}).Decode(&res)
+	require.NoError(t, err)
+	assert.Equal(t, bson.A{id}, res.Map()["cursorsNotFound"])
+}
+
+func TestGetMoreCursorTimeout(t *testing.T) {
+	// not parallel because cursorTimeoutMillis is a server-wide parameter
+	ctx, collection := setup.Setup(t)
+
+	insertGetMoreDocs(t, ctx, collection, 5)
+
+	admin := collection.Database().Client().Database("admin")
+
+	var res bson.D
+	err := admin.RunCommand(ctx, bson.D{{"setParameter", int32(1)}, {"cursorTimeoutMillis", int32(500)}}).Decode(&res)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		err := admin.RunCommand(ctx, bson.D{{"setParameter", int32(1)}, {"cursorTimeoutMillis", int32(600000)}}).Err()
+		require.NoError(t, err)
+	})
+
+	timedOut := func() int64 {
+		var actual bson.D
+		err := admin.RunCommand(ctx, bson.D{{"serverStatus", int32(1)}}).Decode(&actual)
+		require.NoError(t, err)
+
+		v, err := ConvertDocument(t, actual).GetByPath(types.NewPathFromString("metrics.cursor.timedOut"))
+		require.NoError(t, err)
+
+		return v.(int64)
+	}
+
+	before := timedOut()
+
+	expiring, err := collection.Find(ctx, bson.D{}, options.Find().SetBatchSize(1))
+	require.NoError(t, err)
+	defer expiring.Close(ctx)
+
+	exempt, err := collection.Find(ctx, bson.D{}, options.Find().SetBatchSize(1).SetNoCursorTimeout(true))
+	require.NoError(t, err)
+	defer exempt.Close(ctx)
+
+	assert.Eventually(t, func() bool { return timedOut() > before }, 30*time.Second, 100*time.Millisecond)
+
+	// the first document is in the first batch, the second one requires getMore
+	require.True(t, expiring.Next(ctx))
+	require.False(t, expiring.Next(ctx))
+
+	var cmdErr mongo.CommandError
+	require.ErrorAs(t, expiring.Err(), &cmdErr)
+	assert.Equal(t, int32(43), cmdErr.Code)
+	assert.Equal(t, "CursorNotFound", cmdErr.Name)
+
+	var actual []bson.D
+	require.NoError(t, exempt.All(ctx, &actual))
+	assert.Len(t, actual, 5)
+}
@@ -0,0 +1,95 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+)
+
+func TestQueryBinaryUUID(t *testing.T) {
+	setup.SkipForTigris(t)
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	uuid := []byte{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0, 0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0}
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "uuid"}, {"v", primitive.Binary{Subtype: 0x04, Data: uuid}}},
+		bson.D{{"_id", "uuid-old"}, {"v", primitive.Binary{Subtype: 0x03, Data: uuid}}},
+		bson.D{{"_id", "empty"}, {"v", primitive.Binary{Subtype: 0x04, Data: []byte{}}}},
+		bson.D{{"_id", "string"}, {"v", "foo"}},
+	})
+	require.NoError(t, err)
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		t.Parallel()
+
+		var actual bson.D
+		err := collection.FindOne(ctx, bson.D{{"_id", "empty"}}).Decode(&actual)
+		require.NoError(t, err)
+		AssertEqualDocuments(t, bson.D{{"_id", "empty"}, {"v", primitive.Binary{Subtype: 0x04, Data: []byte{}}}}, actual)
+	})
+
+	for name, tc := range map[string]struct {
+		filter      bson.D
+		expectedIDs []any
+	}{
+		"UUID": {
+			filter:      bson.D{{"v", primitive.Binary{Subtype: 0x04, Data: uuid}}},
+			expectedIDs: []any{"uuid"},
+		},
+		"UUIDOld": {
+			filter:      bson.D{{"v", primitive.Binary{Subtype: 0x03, Data: uuid}}},
+			expectedIDs: []any{"uuid-old"},
+		},
+		"Empty": {
+			filter:      bson.D{{"v", primitive.Binary{Subtype: 0x04, Data: []byte{}}}},
+			expectedIDs: []any{"empty"},
+		},
+		"In": {
+			filter: bson.D{{"v", bson.D{{"$in", bson.A{
+				primitive.Binary{Subtype: 0x04, Data: uuid},
+				primitive.Binary{Subtype: 0x04, Data: []byte{}},
+			}}}}},
+			expectedIDs: []any{"empty", "uuid"},
+		},
+		"Type": {
+			filter:      bson.D{{"v", bson.D{{"$type", "binData"}}}},
+			expectedIDs: []any{"empty", "uuid", "uuid-old"},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cursor, err := collection.Find(ctx, tc.filter, options.Find().SetSort(bson.D{{"_id", 1}}))
+			require.NoError(t, err)
+
+			var actual []bson.D
+			err = cursor.All(ctx, &actual)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedIDs, CollectIDs(t, actual))
+		})
+	}
+}
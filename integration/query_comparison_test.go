@@ -524,7 +524,10 @@ func TestQueryComparisonGt(t *testing.T) {
 		expectedIDs []any
 		err         *mongo.CommandError
 	}{
-		// TODO document
+		"Document": {
+			value:       bson.D{{"foo", int32(42)}},
+			expectedIDs: []any{"document-composite", "document-composite-reverse"},
+		},
 
 		"ArrayEmpty": {
 			value: bson.A{},
@@ -749,7 +752,10 @@ func TestQueryComparisonGte(t *testing.T) {
 		expectedIDs []any
 		err         *mongo.CommandError
 	}{
-		// TODO document
+		"Document": {
+			value:       bson.D{{"foo", int32(42)}},
+			expectedIDs: []any{"document", "document-composite", "document-composite-reverse"},
+		},
 
 		"ArrayEmpty": {
 			value: bson.A{},
@@ -967,7 +973,10 @@ func TestQueryComparisonLt(t *testing.T) {
 		expectedIDs []any
 		err         *mongo.CommandError
 	}{
-		// TODO document
+		"Document": {
+			value:       bson.D{{"foo", int32(42)}},
+			expectedIDs: []any{"document-empty", "document-null"},
+		},
 
 		"ArrayEmpty": {
 			value:       bson.A{},
@@ -1194,7 +1203,10 @@ func TestQueryComparisonLte(t *testing.T) {
 		expectedIDs []any
 		err         *mongo.CommandError
 	}{
-		// TODO document
+		"Document": {
+			value:       bson.D{{"foo", int32(42)}},
+			expectedIDs: []any{"document", "document-empty", "document-null"},
+		},
 
 		"ArrayEmpty": {
 			value:       bson.A{},
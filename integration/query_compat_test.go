@@ -23,12 +23,15 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/FerretDB/FerretDB/integration/setup"
+	"github.com/FerretDB/FerretDB/integration/shareddata"
 )
 
 // queryCompatTestCase describes query compatibility test case.
 type queryCompatTestCase struct {
 	filter     bson.D                   // required
 	sort       bson.D                   // defaults to `bson.D{{"_id", 1}}`
+	limit      *int64                   // defaults to nil to leave limit unset
+	projection bson.D                   // defaults to nil to leave projection unset
 	resultType compatTestCaseResultType // defaults to nonEmptyResult
 	skip       string                   // skips test if non-empty
 }
@@ -37,9 +40,19 @@ type queryCompatTestCase struct {
 func testQueryCompat(t *testing.T, testCases map[string]queryCompatTestCase) {
 	t.Helper()
 
+	testQueryCompatWithProviders(t, shareddata.AllProviders(), testCases)
+}
+
+// testQueryCompatWithProviders tests query compatibility test cases with the given data providers.
+func testQueryCompatWithProviders(t *testing.T, providers []shareddata.Provider, testCases map[string]queryCompatTestCase) {
+	t.Helper()
+
 	// Use shared setup because find queries can't modify data.
 	// TODO Use read-only user. https://github.com/FerretDB/FerretDB/issues/1025
-	ctx, targetCollections, compatCollections := setup.SetupCompat(t)
+	s := setup.SetupCompatWithOpts(t, &setup.SetupCompatOpts{
+		Providers: providers,
+	})
+	ctx, targetCollections, compatCollections := s.Ctx, s.TargetCollections, s.CompatCollections
 
 	for name, tc := range testCases {
 		name, tc := name, tc
@@ -60,6 +73,12 @@ func testQueryCompat(t *testing.T, testCases map[string]queryCompatTestCase) {
 				sort = bson.D{{"_id", 1}}
 			}
 			opts := options.Find().SetSort(sort)
+			if tc.limit != nil {
+				opts.SetLimit(*tc.limit)
+			}
+			if tc.projection != nil {
+				opts.SetProjection(tc.projection)
+			}
 
 			var nonEmptyResults bool
 			for i := range targetCollections {
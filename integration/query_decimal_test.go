@@ -0,0 +1,105 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+)
+
+func TestQueryDecimal128(t *testing.T) {
+	setup.SkipForTigris(t)
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	mustDecimal := func(s string) primitive.Decimal128 {
+		d, err := primitive.ParseDecimal128(s)
+		require.NoError(t, err)
+		return d
+	}
+
+	_, err := collection.InsertMany(ctx, []any{
+		bson.D{{"_id", "decimal"}, {"v", mustDecimal("1.5")}},
+		bson.D{{"_id", "decimal-whole"}, {"v", mustDecimal("42.0")}},
+		bson.D{{"_id", "decimal-nan"}, {"v", mustDecimal("NaN")}},
+		bson.D{{"_id", "decimal-big"}, {"v", mustDecimal("1.234567890123456789012345678901234E+6000")}},
+		bson.D{{"_id", "double"}, {"v", 1.5}},
+		bson.D{{"_id", "int32"}, {"v", int32(42)}},
+	})
+	require.NoError(t, err)
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		t.Parallel()
+
+		var actual bson.D
+		err := collection.FindOne(ctx, bson.D{{"_id", "decimal-big"}}).Decode(&actual)
+		require.NoError(t, err)
+		AssertEqualDocuments(t, bson.D{
+			{"_id", "decimal-big"},
+			{"v", mustDecimal("1.234567890123456789012345678901234E+6000")},
+		}, actual)
+	})
+
+	for name, tc := range map[string]struct {
+		filter      bson.D
+		expectedIDs []any
+	}{
+		"EqDouble": {
+			filter:      bson.D{{"v", 1.5}},
+			expectedIDs: []any{"decimal", "double"},
+		},
+		"EqDecimal": {
+			filter:      bson.D{{"v", mustDecimal("42")}},
+			expectedIDs: []any{"decimal-whole", "int32"},
+		},
+		"EqNaN": {
+			filter:      bson.D{{"v", mustDecimal("NaN")}},
+			expectedIDs: []any{"decimal-nan"},
+		},
+		"Gt": {
+			filter:      bson.D{{"v", bson.D{{"$gt", mustDecimal("2")}}}},
+			expectedIDs: []any{"decimal-big", "decimal-whole", "int32"},
+		},
+		"TypeDecimal": {
+			filter:      bson.D{{"v", bson.D{{"$type", "decimal"}}}},
+			expectedIDs: []any{"decimal", "decimal-big", "decimal-nan", "decimal-whole"},
+		},
+		"TypeCode": {
+			filter:      bson.D{{"v", bson.D{{"$type", 19}}}},
+			expectedIDs: []any{"decimal", "decimal-big", "decimal-nan", "decimal-whole"},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cursor, err := collection.Find(ctx, tc.filter, options.Find().SetSort(bson.D{{"_id", 1}}))
+			require.NoError(t, err)
+
+			var actual []bson.D
+			err = cursor.All(ctx, &actual)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedIDs, CollectIDs(t, actual))
+		})
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"math"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestQueryDoubleCompatSpecialValues tests filtering and sorting of -0, NaN and ±Infinity doubles.
+//
+// Inserting (and reading back) of those values is tested by compat setup and comparison of all returned documents.
+func TestQueryDoubleCompatSpecialValues(t *testing.T) {
+	t.Parallel()
+
+	doubles := primitive.Regex{Pattern: "^double"}
+
+	testCases := map[string]queryCompatTestCase{
+		"NegativeZero": {
+			filter: bson.D{{"v", math.Copysign(0, -1)}},
+		},
+		"Zero": {
+			filter: bson.D{{"v", 0.0}},
+		},
+		"NegativeZeroInt32": {
+			filter: bson.D{{"v", int32(0)}, {"_id", "double-negative-zero"}},
+		},
+		"NaN": {
+			filter: bson.D{{"v", math.NaN()}},
+		},
+		"PositiveInfinity": {
+			filter: bson.D{{"v", math.Inf(+1)}},
+		},
+		"NegativeInfinity": {
+			filter: bson.D{{"v", math.Inf(-1)}},
+		},
+		"In": {
+			filter: bson.D{{"v", bson.D{{"$in", bson.A{math.NaN(), math.Inf(+1), math.Copysign(0, -1)}}}}},
+		},
+		"GtNaN": {
+			filter:     bson.D{{"v", bson.D{{"$gt", math.NaN()}}}},
+			resultType: emptyResult,
+		},
+		"GteNaN": {
+			filter: bson.D{{"v", bson.D{{"$gte", math.NaN()}}}},
+		},
+		"LtNegativeInfinity": {
+			filter:     bson.D{{"v", bson.D{{"$lt", math.Inf(-1)}}}},
+			resultType: emptyResult,
+		},
+		"LtePositiveInfinity": {
+			filter: bson.D{{"_id", doubles}, {"v", bson.D{{"$lte", math.Inf(+1)}}}},
+		},
+		"SortAsc": {
+			filter: bson.D{{"_id", doubles}},
+			sort:   bson.D{{"v", 1}, {"_id", 1}},
+		},
+		"SortDesc": {
+			filter: bson.D{{"_id", doubles}},
+			sort:   bson.D{{"v", -1}, {"_id", 1}},
+		},
+	}
+
+	testQueryCompat(t, testCases)
+}
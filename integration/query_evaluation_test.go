@@ -467,6 +467,14 @@ func TestQueryEvaluationRegex(t *testing.T) {
 			filter:      bson.D{{"v", bson.D{{"$regex", primitive.Regex{Pattern: "foo", Options: "123"}}}}},
 			expectedIDs: []any{"multiline-string", "string"},
 		},
+		"RegexExtended": {
+			filter:      bson.D{{"v", bson.D{{"$regex", "f o o # comment"}, {"$options", "x"}}}},
+			expectedIDs: []any{"multiline-string", "string"},
+		},
+		"RegexInArray": {
+			filter:      bson.D{{"v", bson.D{{"$in", bson.A{primitive.Regex{Pattern: "^fo{2}$"}, "bar"}}}}},
+			expectedIDs: []any{"string"},
+		},
 	} {
 		name, tc := name, tc
 		t.Run(name, func(t *testing.T) {
@@ -506,6 +514,22 @@ func TestQueryEvaluationRegexErrors(t *testing.T) {
 				Message: "Regular expression is invalid: missing )",
 			},
 		},
+		"Lookbehind": {
+			filter: bson.D{{"v", bson.D{{"$regex", primitive.Regex{Pattern: "(?<=bar)foo"}}}}},
+			err: &mongo.CommandError{
+				Code:    51091,
+				Name:    "Location51091",
+				Message: "Regular expression is invalid: lookbehind assertion (?<= is not supported",
+			},
+		},
+		"Backreference": {
+			filter: bson.D{{"v", bson.D{{"$in", bson.A{primitive.Regex{Pattern: `(o)\1`}}}}}},
+			err: &mongo.CommandError{
+				Code:    51091,
+				Name:    "Location51091",
+				Message: "Regular expression is invalid: backreference \\1 is not supported",
+			},
+		},
 		"MissingClosingBracket": {
 			filter: bson.D{{"v", bson.D{{"$regex", primitive.Regex{Pattern: "g[-z+ng  wrong regex"}}}}},
 			err: &mongo.CommandError{
@@ -0,0 +1,158 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/AlekSi/pointer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+	"github.com/FerretDB/FerretDB/integration/shareddata"
+)
+
+// TestQueryCompatLimit tests limit semantics: zero means no limit,
+// and negative values mean a single batch of at most that many documents.
+func TestQueryCompatLimit(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]queryCompatTestCase{
+		"Zero": {
+			filter: bson.D{},
+			limit:  pointer.ToInt64(0),
+		},
+		"One": {
+			filter: bson.D{},
+			limit:  pointer.ToInt64(1),
+		},
+		"Five": {
+			filter: bson.D{},
+			limit:  pointer.ToInt64(5),
+		},
+		"MinusOne": {
+			filter: bson.D{},
+			limit:  pointer.ToInt64(-1),
+		},
+		"MinusFive": {
+			filter: bson.D{},
+			limit:  pointer.ToInt64(-5),
+		},
+		"MaxInt32": {
+			filter: bson.D{},
+			limit:  pointer.ToInt64(math.MaxInt32),
+		},
+		"MinInt32": {
+			filter: bson.D{},
+			limit:  pointer.ToInt64(math.MinInt32),
+		},
+		"MaxInt64": {
+			filter: bson.D{},
+			limit:  pointer.ToInt64(math.MaxInt64),
+		},
+		"WithFilter": {
+			filter: bson.D{{"v", bson.D{{"$gt", int32(0)}}}},
+			limit:  pointer.ToInt64(-2),
+		},
+	}
+
+	testQueryCompat(t, testCases)
+}
+
+// TestQueryCompatCommandParams tests validation of find command's skip, limit, and batchSize parameters.
+//
+// Raw commands are used because the driver validates and converts some values itself.
+// Error codes are compared as is; messages are compared up to the first quoted field name
+// that differs between MongoDB versions.
+func TestQueryCompatCommandParams(t *testing.T) {
+	t.Parallel()
+
+	s := setup.SetupCompatWithOpts(t, &setup.SetupCompatOpts{
+		Providers: []shareddata.Provider{shareddata.Int32s},
+	})
+	ctx, targetCollection, compatCollection := s.Ctx, s.TargetCollections[0], s.CompatCollections[0]
+
+	for name, tc := range map[string]struct { //nolint:paralleltest // false positive
+		params bson.D
+	}{
+		"SkipDouble":           {params: bson.D{{"skip", float64(1)}}},
+		"SkipFractional":       {params: bson.D{{"skip", 1.5}}},
+		"SkipNegative":         {params: bson.D{{"skip", int32(-1)}}},
+		"SkipString":           {params: bson.D{{"skip", "1"}}},
+		"LimitDouble":          {params: bson.D{{"limit", float64(2)}}},
+		"LimitFractional":      {params: bson.D{{"limit", 2.5}}},
+		"LimitString":          {params: bson.D{{"limit", "2"}}},
+		"BatchSizeDouble":      {params: bson.D{{"batchSize", float64(2)}}},
+		"BatchSizeFractional":  {params: bson.D{{"batchSize", 2.5}}},
+		"BatchSizeNegative":    {params: bson.D{{"batchSize", int64(-1)}}},
+		"BatchSizeString":      {params: bson.D{{"batchSize", "2"}}},
+		"SkipLimitBatchSize":   {params: bson.D{{"skip", int32(1)}, {"limit", int64(3)}, {"batchSize", float64(2)}}},
+		"NegativeLimitAndSkip": {params: bson.D{{"skip", int32(-1)}, {"limit", int32(2)}}},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			command := append(bson.D{{"find", targetCollection.Name()}, {"sort", bson.D{{"_id", 1}}}}, tc.params...)
+
+			var targetRes, compatRes bson.D
+			targetErr := targetCollection.Database().RunCommand(ctx, command).Decode(&targetRes)
+			compatErr := compatCollection.Database().RunCommand(ctx, command).Decode(&compatRes)
+
+			if compatErr != nil {
+				var compatCmdErr, targetCmdErr mongo.CommandError
+				require.True(t, errors.As(compatErr, &compatCmdErr), "%v", compatErr)
+				require.True(t, errors.As(targetErr, &targetCmdErr), "%v", targetErr)
+
+				assert.Equal(t, compatCmdErr.Code, targetCmdErr.Code)
+
+				prefix := compatCmdErr.Message
+				if i := strings.IndexByte(prefix, '\''); i > 0 {
+					prefix = prefix[:i]
+				}
+				assert.True(t, strings.HasPrefix(targetCmdErr.Message, prefix), "%q", targetCmdErr.Message)
+
+				return
+			}
+			require.NoError(t, targetErr)
+
+			targetCursor := targetRes.Map()["cursor"].(bson.D).Map()
+			compatCursor := compatRes.Map()["cursor"].(bson.D).Map()
+			AssertEqualDocumentsSlice(t, toDocs(t, compatCursor["firstBatch"]), toDocs(t, targetCursor["firstBatch"]))
+		})
+	}
+}
+
+// toDocs converts batch to a slice of documents.
+func toDocs(t testing.TB, batch any) []bson.D {
+	t.Helper()
+
+	arr, ok := batch.(bson.A)
+	require.True(t, ok, "%T", batch)
+
+	res := make([]bson.D, len(arr))
+	for i, v := range arr {
+		res[i], ok = v.(bson.D)
+		require.True(t, ok, "%T", v)
+	}
+
+	return res
+}
@@ -29,7 +29,7 @@ func TestQueryLogicalCompatAnd(t *testing.T) {
 			filter: bson.D{{
 				"$and", bson.A{},
 			}},
-			skip: "https://github.com/FerretDB/FerretDB/issues/962",
+			resultType: emptyResult,
 		},
 		"One": {
 			filter: bson.D{{
@@ -80,7 +80,6 @@ func TestQueryLogicalCompatAnd(t *testing.T) {
 				},
 			}},
 			resultType: emptyResult,
-			skip:       "https://github.com/FerretDB/FerretDB/issues/962",
 		},
 	}
 
@@ -95,7 +94,7 @@ func TestQueryLogicalCompatOr(t *testing.T) {
 			filter: bson.D{{
 				"$or", bson.A{},
 			}},
-			skip: "https://github.com/FerretDB/FerretDB/issues/962",
+			resultType: emptyResult,
 		},
 		"One": {
 			filter: bson.D{{
@@ -135,7 +134,6 @@ func TestQueryLogicalCompatOr(t *testing.T) {
 				},
 			}},
 			resultType: emptyResult,
-			skip:       "https://github.com/FerretDB/FerretDB/issues/962",
 		},
 	}
 
@@ -150,7 +148,7 @@ func TestQueryLogicalCompatNor(t *testing.T) {
 			filter: bson.D{{
 				"$nor", bson.A{},
 			}},
-			skip: "https://github.com/FerretDB/FerretDB/issues/962",
+			resultType: emptyResult,
 		},
 		"One": {
 			filter: bson.D{{
@@ -185,6 +183,126 @@ func TestQueryLogicalCompatNor(t *testing.T) {
 	testQueryCompat(t, testCases)
 }
 
+func TestQueryLogicalCompatNested(t *testing.T) {
+	t.Parallel()
+
+	positive := bson.D{{"v", bson.D{{"$gt", int32(0)}}}}
+	small := bson.D{{"v", bson.D{{"$lt", int64(42)}}}}
+	ints := bson.D{{"v", bson.D{{"$type", "int"}}}}
+	strs := bson.D{{"v", bson.D{{"$type", "string"}}}}
+	zero := bson.D{{"v", int32(0)}}
+
+	testCases := map[string]queryCompatTestCase{
+		"FieldAndOr": {
+			filter: bson.D{
+				{"v", bson.D{{"$gt", int32(0)}}},
+				{"$or", bson.A{small, strs}},
+			},
+		},
+		"OrAndField": {
+			filter: bson.D{
+				{"$or", bson.A{small, strs}},
+				{"v", bson.D{{"$type", "int"}}},
+			},
+		},
+		"FieldAndNor": {
+			filter: bson.D{
+				{"v", bson.D{{"$type", "int"}}},
+				{"$nor", bson.A{positive}},
+			},
+		},
+		"FieldAndOrAndNor": {
+			filter: bson.D{
+				{"v", bson.D{{"$exists", true}}},
+				{"$or", bson.A{ints, strs}},
+				{"$nor", bson.A{zero}},
+			},
+		},
+		"AndOrAnd": {
+			filter: bson.D{{"$and", bson.A{
+				bson.D{{"$or", bson.A{
+					bson.D{{"$and", bson.A{positive, small}}},
+					strs,
+				}}},
+				bson.D{{"v", bson.D{{"$exists", true}}}},
+			}}},
+		},
+		"OrAndOr": {
+			filter: bson.D{{"$or", bson.A{
+				bson.D{{"$and", bson.A{
+					bson.D{{"$or", bson.A{zero, strs}}},
+					bson.D{{"v", bson.D{{"$exists", true}}}},
+				}}},
+				bson.D{{"$and", bson.A{positive, small, ints}}},
+			}}},
+		},
+		"NorOrAnd": {
+			filter: bson.D{{"$nor", bson.A{
+				bson.D{{"$or", bson.A{
+					bson.D{{"$and", bson.A{positive, small}}},
+					strs,
+				}}},
+			}}},
+		},
+		"OrNor": {
+			filter: bson.D{{"$or", bson.A{
+				bson.D{{"$nor", bson.A{positive, strs}}},
+				zero,
+			}}},
+		},
+		"AndNorAnd": {
+			filter: bson.D{{"$and", bson.A{
+				ints,
+				bson.D{{"$nor", bson.A{
+					bson.D{{"$and", bson.A{positive, small}}},
+				}}},
+			}}},
+		},
+		"ImplicitAndInsideOr": {
+			filter: bson.D{{"$or", bson.A{
+				bson.D{{"v", bson.D{{"$type", "int"}}}, {"$and", bson.A{positive, small}}},
+				strs,
+			}}},
+		},
+		"Comment": {
+			filter: bson.D{
+				{"$comment", "nested"},
+				{"$or", bson.A{zero, strs}},
+			},
+		},
+		"FieldAndOrNoMatch": {
+			filter: bson.D{
+				{"v", "no-such-value"},
+				{"$or", bson.A{positive, strs}},
+			},
+			resultType: emptyResult,
+		},
+		"NestedZero": {
+			filter: bson.D{{"$or", bson.A{
+				positive,
+				bson.D{{"$and", bson.A{}}},
+			}}},
+			resultType: emptyResult,
+		},
+		"NestedBadValue": {
+			filter: bson.D{{"$and", bson.A{
+				positive,
+				bson.D{{"$or", bson.A{small, int32(42)}}},
+			}}},
+			resultType: emptyResult,
+		},
+		"NestedUnknownOperator": {
+			filter: bson.D{{"$or", bson.A{
+				positive,
+				bson.D{{"$foo", int32(42)}},
+			}}},
+			resultType: emptyResult,
+		},
+	}
+
+	testQueryCompat(t, testCases)
+}
+
 func TestQueryLogicalCompatNot(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,81 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"github.com/FerretDB/FerretDB/integration/shareddata"
+)
+
+// TestQueryProjectionCompatDotted tests dotted projections over embedded documents,
+// arrays of documents, arrays of scalars, and mixed arrays.
+func TestQueryProjectionCompatDotted(t *testing.T) {
+	t.Parallel()
+
+	providers := []shareddata.Provider{
+		shareddata.ArrayDocuments,
+		shareddata.Composites,
+		shareddata.Int32s,
+	}
+
+	testCases := map[string]queryCompatTestCase{
+		"IncludeField": {
+			filter:     bson.D{},
+			projection: bson.D{{"v.foo", 1}},
+		},
+		"ExcludeField": {
+			filter:     bson.D{},
+			projection: bson.D{{"v.foo", 0}},
+		},
+		"IncludeSiblings": {
+			filter:     bson.D{},
+			projection: bson.D{{"v.foo", 1}, {"v.bar", true}},
+		},
+		"ExcludeSiblings": {
+			filter:     bson.D{},
+			projection: bson.D{{"v.foo", false}, {"v.bar", 0}},
+		},
+		"IncludeDeep": {
+			filter:     bson.D{},
+			projection: bson.D{{"v.foo.bar", 1}},
+		},
+		"ExcludeDeep": {
+			filter:     bson.D{},
+			projection: bson.D{{"v.foo.baz", 0}},
+		},
+		"IncludeWithoutID": {
+			filter:     bson.D{},
+			projection: bson.D{{"_id", 0}, {"v.bar", 1}},
+		},
+		"ExcludeWithID": {
+			filter:     bson.D{},
+			projection: bson.D{{"_id", 1}, {"v.bar", 0}},
+		},
+		"IncludeNonExistent": {
+			filter:     bson.D{},
+			projection: bson.D{{"v.non-existent", 1}},
+		},
+		"PathCollision": {
+			filter:     bson.D{},
+			projection: bson.D{{"v", 1}, {"v.foo", 1}},
+			resultType: emptyResult,
+		},
+	}
+
+	testQueryCompatWithProviders(t, providers, testCases)
+}
@@ -0,0 +1,115 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// TestQuerySortCompat tests sorting by arrays, nulls and missing fields.
+//
+// Filters select documents with distinct sort keys because the order of documents
+// with equal keys is not defined.
+func TestQuerySortCompat(t *testing.T) {
+	t.Parallel()
+
+	arrays := bson.D{{"_id", bson.D{{"$in", bson.A{
+		"array-empty", "array-null", "array-two", "array", "array-embedded", "string",
+	}}}}}
+	missing := bson.D{{"_id", bson.D{{"$in", bson.A{"document", "document-empty"}}}}}
+	index := bson.D{{"_id", bson.D{{"$in", bson.A{"array-three", "array-three-reverse", "array-embedded"}}}}}
+
+	testCases := map[string]queryCompatTestCase{
+		"ArraysAsc": {
+			filter: arrays,
+			sort:   bson.D{{"v", 1}},
+		},
+		"ArraysDesc": {
+			filter: arrays,
+			sort:   bson.D{{"v", -1}},
+		},
+		"MissingAsc": {
+			filter: missing,
+			sort:   bson.D{{"v.foo", 1}},
+		},
+		"MissingDesc": {
+			filter: missing,
+			sort:   bson.D{{"v.foo", -1}},
+		},
+		"ArrayIndexAsc": {
+			filter: index,
+			sort:   bson.D{{"v.0", 1}},
+		},
+		"ArrayIndexDesc": {
+			filter: index,
+			sort:   bson.D{{"v.0", -1}},
+		},
+	}
+
+	testQueryCompat(t, testCases)
+}
+
+// TestQuerySortCompoundCompat tests sorting by multiple keys over values of mixed types.
+//
+// _id is used as the last key to make the order of documents defined.
+func TestQuerySortCompoundCompat(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]queryCompatTestCase{
+		"AscAsc": {
+			filter: bson.D{},
+			sort:   bson.D{{"v", 1}, {"_id", 1}},
+		},
+		"AscDesc": {
+			filter: bson.D{},
+			sort:   bson.D{{"v", 1}, {"_id", -1}},
+		},
+		"DescAsc": {
+			filter: bson.D{},
+			sort:   bson.D{{"v", -1}, {"_id", 1}},
+		},
+		"DescDesc": {
+			filter: bson.D{},
+			sort:   bson.D{{"v", -1}, {"_id", -1}},
+		},
+		"DottedAsc": {
+			filter: bson.D{},
+			sort:   bson.D{{"v.foo", 1}, {"v", -1}, {"_id", 1}},
+		},
+		"Int64Double": {
+			filter: bson.D{},
+			sort:   bson.D{{"v", int64(-1)}, {"_id", float64(1)}},
+		},
+		"Fractional": {
+			filter:     bson.D{},
+			sort:       bson.D{{"v", 1}, {"_id", 1.5}},
+			resultType: emptyResult,
+		},
+		"Zero": {
+			filter:     bson.D{},
+			sort:       bson.D{{"v", 1}, {"_id", 0}},
+			resultType: emptyResult,
+		},
+		"EmptyPathElement": {
+			filter:     bson.D{},
+			sort:       bson.D{{"v..foo", 1}, {"_id", 1}},
+			resultType: emptyResult,
+		},
+	}
+
+	testQueryCompat(t, testCases)
+}
@@ -342,6 +342,15 @@ func TestQueryCount(t *testing.T) {
 			},
 			response: 11,
 		},
+		"CountSkipLimit": {
+			command: bson.D{
+				{"count", collection.Name()},
+				{"query", bson.D{{"v", bson.D{{"$type", "array"}}}}},
+				{"skip", int32(10)},
+				{"limit", int32(5)},
+			},
+			response: 1,
+		},
 		"CountNonExistingCollection": {
 			command: bson.D{
 				{"count", "doesnotexist"},
@@ -923,3 +932,74 @@ func TestQueryNonExistingCollection(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, actual, 0)
 }
+
+func TestQuerySkipLimit(t *testing.T) {
+	setup.SkipForTigris(t)
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t, shareddata.Scalars, shareddata.Composites)
+
+	all := FindAll(t, ctx, collection)
+	require.Greater(t, len(all), 5)
+
+	for name, tc := range map[string]struct {
+		filter   bson.D
+		skip     int64
+		limit    int64
+		expected []bson.D
+	}{
+		"Skip": {
+			skip:     2,
+			expected: all[2:],
+		},
+		"Limit": {
+			limit:    3,
+			expected: all[:3],
+		},
+		"SkipLimit": {
+			skip:     2,
+			limit:    3,
+			expected: all[2:5],
+		},
+		"SkipAll": {
+			skip:     int64(len(all)),
+			expected: []bson.D{},
+		},
+		"FilterSkipLimit": {
+			filter:   bson.D{{"_id", bson.D{{"$in", bson.A{all[1][0].Value, all[3][0].Value, all[4][0].Value}}}}},
+			skip:     1,
+			limit:    1,
+			expected: all[3:4],
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			filter := tc.filter
+			if filter == nil {
+				filter = bson.D{}
+			}
+
+			opts := options.Find().SetSort(bson.D{{"_id", 1}}).SetSkip(tc.skip).SetLimit(tc.limit)
+			cursor, err := collection.Find(ctx, filter, opts)
+			require.NoError(t, err)
+
+			actual := []bson.D{}
+			require.NoError(t, cursor.All(ctx, &actual))
+			AssertEqualDocumentsSlice(t, tc.expected, actual)
+		})
+	}
+
+	t.Run("NegativeSkip", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := collection.Find(ctx, bson.D{}, options.Find().SetSkip(-1))
+		expected := mongo.CommandError{
+			Code:    51024,
+			Name:    "Location51024",
+			Message: "BSON field 'skip' value must be >= 0, actual value '-1'",
+		}
+		AssertEqualError(t, expected, err)
+	})
+}
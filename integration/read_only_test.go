@@ -0,0 +1,94 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+)
+
+func TestReadOnly(t *testing.T) {
+	// not parallel because readOnly is a server-wide parameter
+	ctx, collection := setup.Setup(t)
+
+	insertGetMoreDocs(t, ctx, collection, 5)
+
+	// the first document is in the first batch, others require getMore
+	cursor, err := collection.Find(ctx, bson.D{}, options.Find().SetBatchSize(1))
+	require.NoError(t, err)
+	defer cursor.Close(ctx)
+
+	db := collection.Database()
+	admin := db.Client().Database("admin")
+
+	var res bson.D
+	err = admin.RunCommand(ctx, bson.D{{"setParameter", int32(1)}, {"readOnly", true}}).Decode(&res)
+	require.NoError(t, err)
+	assert.Equal(t, false, res.Map()["was"])
+
+	t.Cleanup(func() {
+		err := admin.RunCommand(ctx, bson.D{{"setParameter", int32(1)}, {"readOnly", false}}).Err()
+		require.NoError(t, err)
+	})
+
+	err = admin.RunCommand(ctx, bson.D{{"hello", int32(1)}}).Decode(&res)
+	require.NoError(t, err)
+	assert.Equal(t, true, res.Map()["readOnly"])
+
+	expected := mongo.CommandError{
+		Code:    10107,
+		Name:    "NotWritablePrimary",
+		Message: "not primary",
+	}
+
+	for name, command := range map[string]bson.D{
+		"Insert":        {{"insert", collection.Name()}, {"documents", bson.A{bson.D{{"_id", "new"}}}}},
+		"Update":        {{"update", collection.Name()}, {"updates", bson.A{bson.D{{"q", bson.D{}}, {"u", bson.D{}}}}}},
+		"Delete":        {{"delete", collection.Name()}, {"deletes", bson.A{bson.D{{"q", bson.D{}}, {"limit", 0}}}}},
+		"FindAndModify": {{"findAndModify", collection.Name()}, {"remove", true}},
+		"Create":        {{"create", collection.Name() + "_new"}},
+		"Drop":          {{"drop", collection.Name()}},
+		"DropDatabase":  {{"dropDatabase", int32(1)}},
+		"CreateIndexes": {{"createIndexes", collection.Name()}, {"indexes", bson.A{}}},
+	} {
+		command := command
+		t.Run(name, func(t *testing.T) {
+			err := db.RunCommand(ctx, command).Err()
+			AssertEqualError(t, expected, err)
+		})
+	}
+
+	n, err := collection.CountDocuments(ctx, bson.D{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), n)
+
+	var docs []bson.D
+	require.NoError(t, cursor.All(ctx, &docs))
+	assert.Len(t, docs, 5)
+
+	err = admin.RunCommand(ctx, bson.D{{"setParameter", int32(1)}, {"readOnly", false}}).Decode(&res)
+	require.NoError(t, err)
+	assert.Equal(t, true, res.Map()["was"])
+
+	_, err = collection.InsertOne(ctx, bson.D{{"_id", "new"}})
+	require.NoError(t, err)
+}
@@ -0,0 +1,147 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+	"github.com/FerretDB/FerretDB/internal/clientconn"
+	"github.com/FerretDB/FerretDB/internal/handlers/pg/pgdb"
+	"github.com/FerretDB/FerretDB/internal/handlers/registry"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+// TestPostgreSQLConnectionLoss checks that FerretDB recovers after PostgreSQL connections are terminated,
+// like after PostgreSQL restart.
+func TestPostgreSQLConnectionLoss(t *testing.T) {
+	setup.SkipForTigris(t)
+
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(testutil.Ctx(t))
+	defer cancel()
+
+	// own handler with a unique application name, so other tests are not affected
+	applicationName := testutil.DatabaseName(t)
+
+	logger := zaptest.NewLogger(t)
+	metrics := prometheus.NewRegistry()
+
+	h, err := registry.NewHandler("pg", &registry.NewHandlerOpts{
+		Ctx:     ctx,
+		Logger:  logger,
+		Metrics: metrics,
+		PostgreSQLURL: testutil.PostgreSQLURL(t, &testutil.PostgreSQLURLOpts{
+			Params: map[string]string{"application_name": applicationName},
+		}),
+		PostgreSQLPool: pgdb.NewPoolOpts{
+			// background checks are disabled to make the test deterministic
+			HealthCheckInterval: -1,
+		},
+	})
+	require.NoError(t, err)
+	t.Cleanup(h.Close)
+
+	l := clientconn.NewListener(&clientconn.NewListenerOpts{
+		ListenAddr: "127.0.0.1:0",
+		Mode:       clientconn.NormalMode,
+		Handler:    h,
+		Logger:     logger,
+		Metrics:    metrics,
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.ErrorIs(t, l.Run(ctx), context.Canceled)
+	}()
+	t.Cleanup(func() { <-done })
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(fmt.Sprintf("mongodb://%s/", l.Addr())))
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, client.Disconnect(ctx)) })
+
+	db := client.Database(testutil.DatabaseName(t))
+	collection := db.Collection(testutil.CollectionName(t))
+	t.Cleanup(func() { assert.NoError(t, db.Drop(ctx)) })
+
+	_, err = collection.InsertOne(ctx, bson.D{{"_id", int32(1)}})
+	require.NoError(t, err)
+
+	conn, err := pgx.Connect(ctx, testutil.PostgreSQLURL(t, nil))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close(ctx) })
+
+	count := func(sql string) int {
+		var n int
+		require.NoError(t, conn.QueryRow(ctx, sql, applicationName).Scan(&n))
+		return n
+	}
+
+	terminateBackends := func() {
+		n := count(`SELECT count(pg_terminate_backend(pid)) FROM pg_stat_activity WHERE application_name = $1`)
+		require.Positive(t, n)
+
+		// pg_terminate_backend does not wait for backends to exit
+		assert.Eventually(t, func() bool {
+			return count(`SELECT count(*) FROM pg_stat_activity WHERE application_name = $1`) == 0
+		}, 10*time.Second, 10*time.Millisecond)
+	}
+
+	// reads are retried transparently
+	terminateBackends()
+
+	var docs []bson.D
+	cursor, err := collection.Find(ctx, bson.D{})
+	require.NoError(t, err)
+	require.NoError(t, cursor.All(ctx, &docs))
+	assert.Len(t, docs, 1)
+
+	// writes return HostUnreachable error
+	terminateBackends()
+
+	_, err = collection.InsertOne(ctx, bson.D{{"_id", int32(2)}})
+	AssertEqualError(t, mongo.CommandError{
+		Code:    6,
+		Name:    "HostUnreachable",
+		Message: "connection to PostgreSQL was lost",
+	}, err)
+
+	// and then work again
+	_, err = collection.InsertOne(ctx, bson.D{{"_id", int32(3)}})
+	require.NoError(t, err)
+
+	var res bson.D
+	err = client.Database("admin").RunCommand(ctx, bson.D{{"serverStatus", int32(1)}}).Decode(&res)
+	require.NoError(t, err)
+
+	v, err := ConvertDocument(t, res).GetByPath(types.NewPathFromString("ferretdb.health.connectionLosses"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), v)
+}
@@ -21,10 +21,13 @@ import (
 	"fmt"
 	"net"
 	"net/url"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -42,6 +45,7 @@ var (
 	proxyAddrF  = flag.String("proxy-addr", "", "proxy to use for in-process FerretDB")
 	handlerF    = flag.String("handler", "pg", "handler to use for in-process FerretDB")
 	compatPortF = flag.Int("compat-port", 37017, "second system's port for compatibility tests; if 0, they are skipped")
+	recordDirF  = flag.String("record-dir", "", "directory for wire traffic recordings of in-process FerretDB")
 
 	// Disable noisy setup logs by default.
 	debugSetupF = flag.Bool("debug-setup", false, "enable debug logs for tests setup")
@@ -50,6 +54,14 @@ var (
 	startupOnce sync.Once
 )
 
+// IsPg returns true if tests run against in-process FerretDB with `pg` handler.
+//
+// This function should not be used lightly; it exists only for the (rare) differences in behavior
+// between handlers that can't be expressed by SkipForTigrisWithReason.
+func IsPg() bool {
+	return *targetPortF == 0 && *handlerF == "pg"
+}
+
 // SkipForTigris skips the current test for Tigris handler.
 //
 // This function should not be used lightly in new tests and should eventually be removed.
@@ -74,14 +86,32 @@ func SkipForTigrisWithReason(tb testing.TB, reason string) {
 	}
 }
 
+// CertPath returns the path to the test certificate file with the given name.
+//
+// See build/certs/generate.sh.
+func CertPath(name string) string {
+	return filepath.Join("..", "build", "certs", name)
+}
+
 // setupListener starts in-process FerretDB server that runs until ctx is done,
-// and returns listening port number.
-func setupListener(tb testing.TB, ctx context.Context, logger *zap.Logger) int {
+// and returns listening port numbers for plaintext and TLS connections, and Unix domain socket path.
+func setupListener(tb testing.TB, ctx context.Context, logger *zap.Logger) (int, int, string) {
 	tb.Helper()
 
+	// keep the path short, as socket paths are limited to ~100 bytes
+	unixDir, err := os.MkdirTemp("", "ferretdb")
+	require.NoError(tb, err)
+	tb.Cleanup(func() { os.RemoveAll(unixDir) })
+
+	unixSocket := filepath.Join(unixDir, "mongodb.sock")
+
+	// each in-process FerretDB server has its own metrics registry, so they do not conflict
+	metrics := prometheus.NewRegistry()
+
 	h, err := registry.NewHandler(*handlerF, &registry.NewHandlerOpts{
 		Ctx:           ctx,
 		Logger:        logger,
+		Metrics:       metrics,
 		PostgreSQLURL: testutil.PostgreSQLURL(tb, nil),
 		TigrisURL:     testutil.TigrisURL(tb),
 	})
@@ -95,10 +125,20 @@ func setupListener(tb testing.TB, ctx context.Context, logger *zap.Logger) int {
 
 	l := clientconn.NewListener(&clientconn.NewListenerOpts{
 		ListenAddr:         "127.0.0.1:0",
+		TLS:                "127.0.0.1:0",
+		TLSCertFile:        CertPath("server-cert.pem"),
+		TLSKeyFile:         CertPath("server-key.pem"),
+		TLSCAFile:          CertPath("rootCA-cert.pem"),
+		Unix:               unixSocket,
+		UnixPerm:           0o700,
 		ProxyAddr:          proxyAddr,
 		Mode:               mode,
 		Handler:            h,
 		Logger:             logger,
+		Metrics:            metrics,
+		LogPayloads:        true, // test data is not sensitive, and full bodies make failures easier to debug
+		RecordDir:          *recordDirF,
+		RecordMaxSize:      1 << 30,
 		TestRunCancelDelay: time.Hour, // make it easier to notice missing client's disconnects
 	})
 
@@ -121,9 +161,10 @@ func setupListener(tb testing.TB, ctx context.Context, logger *zap.Logger) int {
 	})
 
 	port := l.Addr().(*net.TCPAddr).Port
-	logger.Info("Listener started", zap.String("handler", *handlerF), zap.Int("port", port))
+	tlsPort := l.TLSAddr().(*net.TCPAddr).Port
+	logger.Info("Listener started", zap.String("handler", *handlerF), zap.Int("port", port), zap.Int("tls_port", tlsPort))
 
-	return port
+	return port, tlsPort, unixSocket
 }
 
 // setupClient returns MongoDB client for database on 127.0.0.1:port.
@@ -177,7 +218,7 @@ func startup() {
 	startupOnce.Do(func() {
 		logging.Setup(zap.DebugLevel)
 
-		go debug.RunHandler(context.Background(), "127.0.0.1:0", zap.L().Named("debug"))
+		go debug.RunHandler(context.Background(), "127.0.0.1:0", nil, nil, zap.L().Named("debug"))
 
 		if p := *targetPortF; p == 0 {
 			zap.S().Infof("Target system: in-process FerretDB with %q handler.", *handlerF)
@@ -47,6 +47,8 @@ type SetupResult struct {
 	Ctx        context.Context
 	Collection *mongo.Collection
 	Port       uint16
+	TLSPort    uint16 // zero if target system is not in-process FerretDB
+	UnixSocket string // empty if target system is not in-process FerretDB
 }
 
 // SetupWithOpts setups the test according to given options.
@@ -68,8 +70,10 @@ func SetupWithOpts(tb testing.TB, opts *SetupOpts) *SetupResult {
 	logger := zaptest.NewLogger(tb, zaptest.Level(level), zaptest.WrapOptions(zap.AddCaller()))
 
 	port := *targetPortF
+	var tlsPort int
+	var unixSocket string
 	if port == 0 {
-		port = setupListener(tb, ctx, logger)
+		port, tlsPort, unixSocket = setupListener(tb, ctx, logger)
 	}
 
 	// register cleanup function after setupListener registers its own to preserve full logs
@@ -83,6 +87,8 @@ func SetupWithOpts(tb testing.TB, opts *SetupOpts) *SetupResult {
 		Ctx:        ctx,
 		Collection: collection,
 		Port:       uint16(port),
+		TLSPort:    uint16(tlsPort),
+		UnixSocket: unixSocket,
 	}
 }
 
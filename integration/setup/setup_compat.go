@@ -83,7 +83,7 @@ func SetupCompatWithOpts(tb testing.TB, opts *SetupCompatOpts) *SetupCompatResul
 
 	targetPort := *targetPortF
 	if targetPort == 0 {
-		targetPort = setupListener(tb, ctx, logger)
+		targetPort, _, _ = setupListener(tb, ctx, logger)
 	}
 
 	// register cleanup function after setupListener registers its own to preserve full logs
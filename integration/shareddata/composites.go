@@ -48,6 +48,36 @@ var Composites = &Values[string]{
 	},
 }
 
+// ArrayDocuments contains arrays of documents, possibly mixed with other values, for tests.
+//
+// It is not a part of AllProviders because filters by dotted paths do not go through arrays yet.
+var ArrayDocuments = &Values[string]{
+	name:     "ArrayDocuments",
+	handlers: []string{"pg"},
+	data: map[string]any{
+		"array-documents": bson.A{
+			bson.D{{"foo", int32(42)}, {"bar", "a"}},
+			bson.D{{"bar", "b"}, {"foo", int32(43)}},
+		},
+		"array-documents-mixed": bson.A{
+			bson.D{{"foo", int32(44)}, {"bar", "c"}},
+			int32(42),
+			"foo",
+			nil,
+			bson.D{{"bar", "d"}},
+		},
+		"array-documents-nested": bson.A{
+			bson.A{bson.D{{"foo", int32(45)}, {"bar", "e"}}, int32(42)},
+			bson.D{{"foo", bson.D{{"bar", int32(1)}, {"baz", int32(2)}}}},
+		},
+		"array-documents-empty": bson.A{bson.D{}},
+		"document-array-documents": bson.D{
+			{"foo", bson.A{bson.D{{"bar", int32(1)}, {"baz", int32(2)}}, bson.D{{"baz", int32(3)}}}},
+			{"bar", "f"},
+		},
+	},
+}
+
 // DocumentsDoubles contains documents with double values for tests.
 var DocumentsDoubles = &Values[string]{
 	name:     "DocumentsDoubles",
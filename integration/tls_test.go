@@ -0,0 +1,93 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+)
+
+func TestTLS(t *testing.T) {
+	t.Parallel()
+
+	s := setup.SetupWithOpts(t, nil)
+	if s.TLSPort == 0 {
+		t.Skip("TLS is tested only with in-process FerretDB")
+	}
+
+	v := url.Values{
+		"tls":                   []string{"true"},
+		"tlsCAFile":             []string{setup.CertPath("rootCA-cert.pem")},
+		"tlsCertificateKeyFile": []string{setup.CertPath("client.pem")},
+	}
+	uri := fmt.Sprintf("mongodb://127.0.0.1:%d/?%s", s.TLSPort, v.Encode())
+
+	client, err := mongo.Connect(s.Ctx, options.Client().ApplyURI(uri))
+	require.NoError(t, err)
+	defer client.Disconnect(s.Ctx)
+
+	collection := client.Database(s.Collection.Database().Name()).Collection(s.Collection.Name())
+
+	_, err = collection.InsertOne(s.Ctx, bson.D{{"_id", "tls"}})
+	require.NoError(t, err)
+
+	var actual bson.D
+	err = s.Collection.FindOne(s.Ctx, bson.D{{"_id", "tls"}}).Decode(&actual)
+	require.NoError(t, err)
+	assert.Equal(t, bson.D{{"_id", "tls"}}, actual)
+
+	t.Run("NoClientCertificate", func(t *testing.T) {
+		t.Parallel()
+
+		v := url.Values{
+			"tls":                      []string{"true"},
+			"tlsCAFile":                []string{setup.CertPath("rootCA-cert.pem")},
+			"serverSelectionTimeoutMS": []string{"1000"},
+		}
+		uri := fmt.Sprintf("mongodb://127.0.0.1:%d/?%s", s.TLSPort, v.Encode())
+
+		client, err := mongo.Connect(s.Ctx, options.Client().ApplyURI(uri))
+		require.NoError(t, err)
+		defer client.Disconnect(s.Ctx)
+
+		err = client.Ping(s.Ctx, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("Plaintext", func(t *testing.T) {
+		t.Parallel()
+
+		uri := fmt.Sprintf("mongodb://127.0.0.1:%d/?serverSelectionTimeoutMS=1000", s.TLSPort)
+
+		client, err := mongo.Connect(s.Ctx, options.Client().ApplyURI(uri))
+		require.NoError(t, err)
+		defer client.Disconnect(s.Ctx)
+
+		start := time.Now()
+		err = client.Ping(s.Ctx, nil)
+		assert.Error(t, err)
+		assert.Less(t, time.Since(start), 10*time.Second)
+	})
+}
@@ -0,0 +1,63 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/FerretDB/FerretDB/integration/setup"
+	"github.com/FerretDB/FerretDB/integration/shareddata"
+)
+
+func TestUnixSocket(t *testing.T) {
+	t.Parallel()
+
+	s := setup.SetupWithOpts(t, &setup.SetupOpts{
+		Providers: []shareddata.Provider{shareddata.Scalars},
+	})
+	if s.UnixSocket == "" {
+		t.Skip("Unix domain socket is tested only with in-process FerretDB")
+	}
+
+	// the socket path should be percent-encoded
+	uri := "mongodb://" + url.PathEscape(s.UnixSocket)
+
+	client, err := mongo.Connect(s.Ctx, options.Client().ApplyURI(uri))
+	require.NoError(t, err)
+	defer client.Disconnect(s.Ctx)
+
+	collection := client.Database(s.Collection.Database().Name()).Collection(s.Collection.Name())
+
+	var expected bson.D
+	err = s.Collection.FindOne(s.Ctx, bson.D{{"_id", "string"}}).Decode(&expected)
+	require.NoError(t, err)
+
+	var actual bson.D
+	err = collection.FindOne(s.Ctx, bson.D{{"_id", "string"}}).Decode(&actual)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+
+	// whatsmyuri should not break on the empty peer address
+	var res bson.D
+	err = collection.Database().RunCommand(s.Ctx, bson.D{{"whatsmyuri", int32(1)}}).Decode(&res)
+	require.NoError(t, err)
+}
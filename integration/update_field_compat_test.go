@@ -82,3 +82,24 @@ func TestUpdateFieldCompatUnset(t *testing.T) {
 
 	testUpdateCompat(t, testCases)
 }
+
+func TestUpdateFieldCompatSet(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]updateCompatTestCase{
+		"Simple": {
+			update: bson.D{{"$set", bson.D{{"v", int32(42)}}}},
+		},
+		"NewField": {
+			update: bson.D{{"$set", bson.D{{"foo", int32(42)}}}},
+		},
+		"NewFields": {
+			update: bson.D{{"$set", bson.D{{"foo", int32(42)}, {"bar", "baz"}}}},
+		},
+		"DotNotationNewField": {
+			update: bson.D{{"$set", bson.D{{"foo.bar", int32(42)}}}},
+		},
+	}
+
+	testUpdateCompat(t, testCases)
+}
@@ -278,6 +278,45 @@ func TestUpdateFieldCurrentDate(t *testing.T) {
 	})
 }
 
+func TestUpdateFieldCurrentDateTimestampsUnique(t *testing.T) {
+	setup.SkipForTigris(t)
+
+	t.Parallel()
+	ctx, collection := setup.Setup(t)
+
+	docs := make([]any, 10)
+	for i := range docs {
+		docs[i] = bson.D{{"_id", int32(i)}}
+	}
+
+	_, err := collection.InsertMany(ctx, docs)
+	require.NoError(t, err)
+
+	// all documents are updated within the same second
+	update := bson.D{{"$currentDate", bson.D{{"ts", bson.D{{"$type", "timestamp"}}}}}}
+	res, err := collection.UpdateMany(ctx, bson.D{}, update)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(docs)), res.ModifiedCount)
+
+	cursor, err := collection.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{"_id", 1}}))
+	require.NoError(t, err)
+
+	var actual []struct {
+		TS primitive.Timestamp `bson:"ts"`
+	}
+	require.NoError(t, cursor.All(ctx, &actual))
+	require.Len(t, actual, len(docs))
+
+	seen := make(map[primitive.Timestamp]struct{}, len(actual))
+	for _, doc := range actual {
+		assert.NotZero(t, doc.TS.I)
+
+		_, ok := seen[doc.TS]
+		assert.False(t, ok, "duplicate timestamp %v", doc.TS)
+		seen[doc.TS] = struct{}{}
+	}
+}
+
 func TestUpdateFieldInc(t *testing.T) {
 	setup.SkipForTigris(t)
 
@@ -512,6 +551,31 @@ func TestUpdateFieldInc(t *testing.T) {
 				update:   bson.D{{"$inc", bson.D{{"v", int64(1)}}}},
 				expected: bson.D{{"_id", "int32"}, {"v", int64(43)}},
 			},
+			"IntMaxIncrement": {
+				id:       "int32-max",
+				update:   bson.D{{"$inc", bson.D{{"v", int32(1)}}}},
+				expected: bson.D{{"_id", "int32-max"}, {"v", int64(math.MaxInt32 + 1)}},
+			},
+			"IntMinDecrement": {
+				id:       "int32-min",
+				update:   bson.D{{"$inc", bson.D{{"v", int32(-1)}}}},
+				expected: bson.D{{"_id", "int32-min"}, {"v", int64(math.MinInt32 - 1)}},
+			},
+			"IntZeroIncrement": {
+				id:       "int32",
+				update:   bson.D{{"$inc", bson.D{{"v", int32(0)}}}},
+				expected: bson.D{{"_id", "int32"}, {"v", int32(42)}},
+				stat: &mongo.UpdateResult{
+					MatchedCount:  1,
+					ModifiedCount: 0,
+					UpsertedCount: 0,
+				},
+			},
+			"LongMaxDoubleIncrement": {
+				id:       "int64-max",
+				update:   bson.D{{"$inc", bson.D{{"v", float64(1)}}}},
+				expected: bson.D{{"_id", "int64-max"}, {"v", float64(math.MaxInt64)}},
+			},
 
 			"FieldNotExist": {
 				id:       "int32",
@@ -657,6 +721,32 @@ func TestUpdateFieldInc(t *testing.T) {
 					Message: `Cannot create field 'foo' in element {array: [ 42, "foo", null ]}`,
 				},
 			},
+			"LongMaxOverflow": {
+				id:     "int64-max",
+				update: bson.D{{"$inc", bson.D{{"v", int32(1)}}}},
+				err: &mongo.WriteError{
+					Code: 2,
+					Message: `Failed to apply $inc operations to current value ` +
+						`((NumberLong)9223372036854775807) for document {_id: "int64-max"}`,
+				},
+			},
+			"LongMinOverflow": {
+				id:     "int64-min",
+				update: bson.D{{"$inc", bson.D{{"v", int64(-1)}}}},
+				err: &mongo.WriteError{
+					Code: 2,
+					Message: `Failed to apply $inc operations to current value ` +
+						`((NumberLong)-9223372036854775808) for document {_id: "int64-min"}`,
+				},
+			},
+			"FieldNotExistWithStringValue": {
+				id:     "int32",
+				update: bson.D{{"$inc", bson.D{{"foo", "bad value"}}}},
+				err: &mongo.WriteError{
+					Code:    14,
+					Message: `Cannot increment with non-numeric argument: {foo: "bad value"}`,
+				},
+			},
 		} {
 			name, tc := name, tc
 			t.Run(name, func(t *testing.T) {
@@ -671,6 +761,172 @@ func TestUpdateFieldInc(t *testing.T) {
 	})
 }
 
+func TestUpdateFieldMul(t *testing.T) {
+	setup.SkipForTigris(t)
+
+	t.Parallel()
+
+	t.Run("Ok", func(t *testing.T) {
+		t.Parallel()
+
+		for name, tc := range map[string]struct {
+			id       string
+			update   bson.D
+			expected bson.D
+			stat     *mongo.UpdateResult
+		}{
+			"Int": {
+				id:       "int32",
+				update:   bson.D{{"$mul", bson.D{{"v", int32(2)}}}},
+				expected: bson.D{{"_id", "int32"}, {"v", int32(84)}},
+			},
+			"IntOne": {
+				id:       "int32",
+				update:   bson.D{{"$mul", bson.D{{"v", int32(1)}}}},
+				expected: bson.D{{"_id", "int32"}, {"v", int32(42)}},
+				stat: &mongo.UpdateResult{
+					MatchedCount:  1,
+					ModifiedCount: 0,
+					UpsertedCount: 0,
+				},
+			},
+			"IntMaxOverflow": {
+				id:       "int32-max",
+				update:   bson.D{{"$mul", bson.D{{"v", int32(2)}}}},
+				expected: bson.D{{"_id", "int32-max"}, {"v", int64(2 * math.MaxInt32)}},
+			},
+			"IntLong": {
+				id:       "int32",
+				update:   bson.D{{"$mul", bson.D{{"v", int64(2)}}}},
+				expected: bson.D{{"_id", "int32"}, {"v", int64(84)}},
+			},
+			"IntDouble": {
+				id:       "int32",
+				update:   bson.D{{"$mul", bson.D{{"v", 0.5}}}},
+				expected: bson.D{{"_id", "int32"}, {"v", 21.0}},
+			},
+			"Long": {
+				id:       "int64",
+				update:   bson.D{{"$mul", bson.D{{"v", int32(-1)}}}},
+				expected: bson.D{{"_id", "int64"}, {"v", int64(-42)}},
+			},
+			"LongMaxDouble": {
+				id:       "int64-max",
+				update:   bson.D{{"$mul", bson.D{{"v", 2.0}}}},
+				expected: bson.D{{"_id", "int64-max"}, {"v", 2 * float64(math.MaxInt64)}},
+			},
+			"Double": {
+				id:       "double",
+				update:   bson.D{{"$mul", bson.D{{"v", int32(2)}}}},
+				expected: bson.D{{"_id", "double"}, {"v", 84.26}},
+			},
+			"DoubleNaN": {
+				id:       "double-nan",
+				update:   bson.D{{"$mul", bson.D{{"v", int32(2)}}}},
+				expected: bson.D{{"_id", "double-nan"}, {"v", math.NaN()}},
+				stat: &mongo.UpdateResult{
+					MatchedCount:  1,
+					ModifiedCount: 1,
+					UpsertedCount: 0,
+				},
+			},
+			"FieldNotExist": {
+				id:       "int32",
+				update:   bson.D{{"$mul", bson.D{{"foo", int64(42)}}}},
+				expected: bson.D{{"_id", "int32"}, {"v", int32(42)}, {"foo", int64(0)}},
+			},
+			"FieldNotExistNegativeDouble": {
+				id:       "int32",
+				update:   bson.D{{"$mul", bson.D{{"foo", -42.13}}}},
+				expected: bson.D{{"_id", "int32"}, {"v", int32(42)}, {"foo", math.Copysign(0, -1)}},
+			},
+			"DotNotationDocumentFieldExist": {
+				id:     "document-composite",
+				update: bson.D{{"$mul", bson.D{{"v.foo", int32(2)}}}},
+				expected: bson.D{
+					{"_id", "document-composite"},
+					{"v", bson.D{{"foo", int32(84)}, {"42", "foo"}, {"array", bson.A{int32(42), "foo", nil}}}},
+				},
+			},
+		} {
+			name, tc := name, tc
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+				ctx, collection := setup.Setup(t, shareddata.Scalars, shareddata.Composites)
+
+				result, err := collection.UpdateOne(ctx, bson.D{{"_id", tc.id}}, tc.update)
+				require.NoError(t, err)
+
+				if tc.stat != nil {
+					require.Equal(t, tc.stat, result)
+				}
+
+				var actual bson.D
+				err = collection.FindOne(ctx, bson.D{{"_id", tc.id}}).Decode(&actual)
+				require.NoError(t, err)
+
+				AssertEqualDocuments(t, tc.expected, actual)
+			})
+		}
+	})
+
+	t.Run("Err", func(t *testing.T) {
+		t.Parallel()
+
+		for name, tc := range map[string]struct {
+			id     string
+			update bson.D
+			err    *mongo.WriteError
+		}{
+			"MulOnString": {
+				id:     "string",
+				update: bson.D{{"$mul", bson.D{{"v", int32(2)}}}},
+				err: &mongo.WriteError{
+					Code: 14,
+					Message: `Cannot apply $mul to a value of non-numeric type. ` +
+						`{_id: "string"} has the field 'v' of non-numeric type string`,
+				},
+			},
+			"MulWithStringValue": {
+				id:     "int32",
+				update: bson.D{{"$mul", bson.D{{"v", "bad value"}}}},
+				err: &mongo.WriteError{
+					Code:    14,
+					Message: `Cannot multiply with non-numeric argument: {v: "bad value"}`,
+				},
+			},
+			"LongMaxOverflow": {
+				id:     "int64-max",
+				update: bson.D{{"$mul", bson.D{{"v", int32(2)}}}},
+				err: &mongo.WriteError{
+					Code: 2,
+					Message: `Failed to apply $mul operations to current value ` +
+						`((NumberLong)9223372036854775807) for document {_id: "int64-max"}`,
+				},
+			},
+			"IntLongOverflow": {
+				id:     "int32-max",
+				update: bson.D{{"$mul", bson.D{{"v", int64(math.MaxInt64)}}}},
+				err: &mongo.WriteError{
+					Code: 2,
+					Message: `Failed to apply $mul operations to current value ` +
+						`((NumberInt)2147483647) for document {_id: "int32-max"}`,
+				},
+			},
+		} {
+			name, tc := name, tc
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+				ctx, collection := setup.Setup(t, shareddata.Scalars, shareddata.Composites)
+
+				_, err := collection.UpdateOne(ctx, bson.D{{"_id", tc.id}}, tc.update)
+				require.NotNil(t, tc.err)
+				AssertEqualWriteError(t, *tc.err, err)
+			})
+		}
+	})
+}
+
 func TestUpdateFieldSet(t *testing.T) {
 	setup.SkipForTigris(t)
 
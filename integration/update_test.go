@@ -78,6 +78,85 @@ func TestUpdateUpsert(t *testing.T) {
 	AssertEqualDocuments(t, bson.D{{"_id", id}, {"foo", "qux"}}, doc)
 }
 
+func TestUpdateUpsertIndex(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t, shareddata.Int32s)
+
+	command := bson.D{
+		{"update", collection.Name()},
+		{"updates", bson.A{
+			bson.D{{"q", bson.D{{"_id", "upsert-0"}}}, {"u", bson.D{{"$set", bson.D{{"v", int32(1)}}}}}, {"upsert", true}},
+			bson.D{{"q", bson.D{{"_id", "int32"}}}, {"u", bson.D{{"$set", bson.D{{"v", int32(2)}}}}}, {"upsert", true}},
+			bson.D{{"q", bson.D{{"_id", "upsert-2"}}}, {"u", bson.D{{"$set", bson.D{{"foo", "bar"}}}}}, {"upsert", true}},
+		}},
+	}
+
+	var res bson.D
+	err := collection.Database().RunCommand(ctx, command).Decode(&res)
+	require.NoError(t, err)
+
+	expected := bson.D{
+		{"n", int32(3)},
+		{"upserted", bson.A{
+			bson.D{{"index", int32(0)}, {"_id", "upsert-0"}},
+			bson.D{{"index", int32(2)}, {"_id", "upsert-2"}},
+		}},
+		{"nModified", int32(1)},
+		{"ok", float64(1)},
+	}
+	AssertEqualDocuments(t, expected, res)
+
+	var doc bson.D
+	err = collection.FindOne(ctx, bson.D{{"_id", "upsert-2"}}).Decode(&doc)
+	require.NoError(t, err)
+	AssertEqualDocuments(t, bson.D{{"_id", "upsert-2"}, {"foo", "bar"}}, doc)
+}
+
+func TestUpdateNumericBoolParams(t *testing.T) {
+	t.Parallel()
+	ctx, collection := setup.Setup(t, shareddata.Int32s)
+
+	command := bson.D{
+		{"update", collection.Name()},
+		{"updates", bson.A{
+			bson.D{{"q", bson.D{{"_id", "upsert"}}}, {"u", bson.D{{"$set", bson.D{{"v", int32(1)}}}}}, {"upsert", int32(1)}},
+			bson.D{{"q", bson.D{{"_id", "no-upsert"}}}, {"u", bson.D{{"$set", bson.D{{"v", int32(1)}}}}}, {"upsert", 0.0}},
+			bson.D{{"q", bson.D{}}, {"u", bson.D{{"$set", bson.D{{"foo", "bar"}}}}}, {"multi", 1.0}},
+		}},
+		{"ordered", int64(1)},
+	}
+
+	var res bson.D
+	err := collection.Database().RunCommand(ctx, command).Decode(&res)
+	require.NoError(t, err)
+
+	n, err := collection.CountDocuments(ctx, bson.D{{"foo", "bar"}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(shareddata.Int32s.Docs())+1), n)
+
+	err = collection.FindOne(ctx, bson.D{{"_id", "no-upsert"}}).Err()
+	assert.Equal(t, mongo.ErrNoDocuments, err)
+
+	command = bson.D{
+		{"update", collection.Name()},
+		{"updates", bson.A{
+			bson.D{{"q", bson.D{}}, {"u", bson.D{{"$set", bson.D{{"foo", "baz"}}}}}, {"multi", "true"}},
+		}},
+	}
+	err = collection.Database().RunCommand(ctx, command).Err()
+	AssertEqualAltError(
+		t,
+		mongo.CommandError{
+			Code: 14,
+			Name: "TypeMismatch",
+			Message: "BSON field 'update.updates.multi' is the wrong type 'string', " +
+				"expected types '[bool, long, int, decimal, double']",
+		},
+		"BSON field 'multi' is the wrong type 'string', expected types '[bool, long, int, decimal, double]'",
+		err,
+	)
+}
+
 func TestMultiFlag(t *testing.T) {
 	setup.SkipForTigris(t)
 
@@ -35,7 +35,7 @@ func (bin *binaryType) ReadFrom(r *bufio.Reader) error {
 	if err := binary.Read(r, binary.LittleEndian, &l); err != nil {
 		return lazyerrors.Errorf("bson.Binary.ReadFrom (binary.Read): %w", err)
 	}
-	if l < 0 {
+	if l < 0 || l > types.MaxDocumentLen {
 		return lazyerrors.Errorf("bson.Binary.ReadFrom: invalid length: %d", l)
 	}
 
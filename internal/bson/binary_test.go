@@ -15,8 +15,13 @@
 package bson
 
 import (
+	"bufio"
+	"bytes"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
 	"github.com/FerretDB/FerretDB/internal/types"
 )
 
@@ -34,6 +39,16 @@ var binaryTestCases = []testCase{{
 		B:       []byte{},
 	},
 	b: []byte{0x00, 0x00, 0x00, 0x00, 0x00},
+}, {
+	name: "uuid",
+	v: &binaryType{
+		Subtype: types.BinaryUUID,
+		B:       []byte{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0, 0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0},
+	},
+	b: []byte{
+		0x10, 0x00, 0x00, 0x00, 0x04,
+		0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0, 0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0,
+	},
 }, {
 	name: "invalid subtype",
 	v: &binaryType{
@@ -59,6 +74,33 @@ func TestBinary(t *testing.T) {
 	testBinary(t, binaryTestCases, func() bsontype { return new(binaryType) })
 }
 
+func TestBinarySubtypes(t *testing.T) {
+	t.Parallel()
+
+	subtypes := []types.BinarySubtype{
+		types.BinaryGeneric, types.BinaryFunction, types.BinaryGenericOld, types.BinaryUUIDOld,
+		types.BinaryUUID, types.BinaryMD5, types.BinaryEncrypted, types.BinaryColumn, types.BinaryUser,
+	}
+	payloads := [][]byte{
+		{},
+		{0x00},
+		{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0, 0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0},
+	}
+
+	for _, subtype := range subtypes {
+		for _, payload := range payloads {
+			expected := binaryType{Subtype: subtype, B: payload}
+
+			b, err := expected.MarshalBinary()
+			require.NoError(t, err)
+
+			var actual binaryType
+			require.NoError(t, actual.ReadFrom(bufio.NewReader(bytes.NewReader(b))))
+			assert.Equal(t, expected, actual, "%s: %x", subtype, payload)
+		}
+	}
+}
+
 func FuzzBinary(f *testing.F) {
 	fuzzBinary(f, binaryTestCases, func() bsontype { return new(binaryType) })
 }
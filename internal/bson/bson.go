@@ -15,6 +15,15 @@
 // Package bson provides converters from/to BSON for built-in and `types` types.
 //
 // See contributing guidelines and documentation for package `types` for details.
+//
+// Deprecated BSON types are decoded to their modern replacements, the same way MongoDB does:
+//
+//	Symbol    -> String
+//	Undefined -> Null
+//	DBPointer -> DBRef document {"$ref": <namespace>, "$id": <ObjectID>}
+//
+// There are no `types` types for them, so they are never encoded;
+// round-trip of a document containing them produces the replacement types instead.
 package bson
 
 import (
@@ -70,6 +79,10 @@ func fromBSON(v bsontype) any {
 		return types.Timestamp(*v)
 	case *int64Type:
 		return int64(*v)
+	case *decimal128Type:
+		return types.Decimal128(*v)
+	case *dbPointerType:
+		return v.dbRef()
 	case *CString:
 		panic("not reached")
 	}
@@ -108,6 +121,8 @@ func toBSON(v any) bsontype {
 		return pointer.To(timestampType(v))
 	case int64:
 		return pointer.To(int64Type(v))
+	case types.Decimal128:
+		return pointer.To(decimal128Type(v))
 	}
 
 	panic(fmt.Sprintf("not reached: %T", v)) // for go-sumtype to work
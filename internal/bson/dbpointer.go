@@ -0,0 +1,99 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bson
+
+import (
+	"bufio"
+	"bytes"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// dbPointerType represents deprecated BSON DBPointer type.
+//
+// It is only decoded: like modern MongoDB, Document.ReadFrom converts it to
+// DBRef document {"$ref": namespace, "$id": id} that is encoded as a regular document.
+type dbPointerType struct {
+	namespace string
+	id        types.ObjectID
+}
+
+func (dbp *dbPointerType) bsontype() {}
+
+// ReadFrom implements bsontype interface.
+func (dbp *dbPointerType) ReadFrom(r *bufio.Reader) error {
+	var ns stringType
+	if err := ns.ReadFrom(r); err != nil {
+		return lazyerrors.Errorf("bson.DBPointer.ReadFrom (namespace): %w", err)
+	}
+
+	var id objectIDType
+	if err := id.ReadFrom(r); err != nil {
+		return lazyerrors.Errorf("bson.DBPointer.ReadFrom (id): %w", err)
+	}
+
+	*dbp = dbPointerType{
+		namespace: string(ns),
+		id:        types.ObjectID(id),
+	}
+	return nil
+}
+
+// WriteTo implements bsontype interface.
+func (dbp dbPointerType) WriteTo(w *bufio.Writer) error {
+	v, err := dbp.MarshalBinary()
+	if err != nil {
+		return lazyerrors.Errorf("bson.DBPointer.WriteTo: %w", err)
+	}
+
+	_, err = w.Write(v)
+	if err != nil {
+		return lazyerrors.Errorf("bson.DBPointer.WriteTo: %w", err)
+	}
+
+	return nil
+}
+
+// MarshalBinary implements bsontype interface.
+func (dbp dbPointerType) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	bufw := bufio.NewWriter(&buf)
+
+	if err := stringType(dbp.namespace).WriteTo(bufw); err != nil {
+		return nil, err
+	}
+	if err := objectIDType(dbp.id).WriteTo(bufw); err != nil {
+		return nil, err
+	}
+
+	bufw.Flush()
+
+	return buf.Bytes(), nil
+}
+
+// dbRef returns DBRef document that replaces DBPointer value.
+func (dbp dbPointerType) dbRef() *types.Document {
+	return must.NotFail(types.NewDocument(
+		"$ref", dbp.namespace,
+		"$id", dbp.id,
+	))
+}
+
+// check interfaces
+var (
+	_ bsontype = (*dbPointerType)(nil)
+)
@@ -0,0 +1,50 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bson
+
+import (
+	"testing"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+var dbPointerTestCases = []testCase{{
+	name: "normal",
+	v: &dbPointerType{
+		namespace: "db.c",
+		id:        types.ObjectID{0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01},
+	},
+	b: []byte{
+		0x05, 0x00, 0x00, 0x00, 0x64, 0x62, 0x2e, 0x63, 0x00,
+		0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01,
+	},
+}, {
+	name: "EOF",
+	b:    []byte{0x05, 0x00, 0x00, 0x00, 0x64, 0x62, 0x2e, 0x63, 0x00, 0x01},
+	bErr: `unexpected EOF`,
+}}
+
+func TestDBPointer(t *testing.T) {
+	t.Parallel()
+	testBinary(t, dbPointerTestCases, func() bsontype { return new(dbPointerType) })
+}
+
+func FuzzDBPointer(f *testing.F) {
+	fuzzBinary(f, dbPointerTestCases, func() bsontype { return new(dbPointerType) })
+}
+
+func BenchmarkDBPointer(b *testing.B) {
+	benchmark(b, dbPointerTestCases, func() bsontype { return new(dbPointerType) })
+}
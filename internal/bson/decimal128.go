@@ -0,0 +1,72 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bson
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// decimal128Type represents BSON 128-bit decimal floating point type.
+type decimal128Type types.Decimal128
+
+func (d *decimal128Type) bsontype() {}
+
+// ReadFrom implements bsontype interface.
+func (d *decimal128Type) ReadFrom(r *bufio.Reader) error {
+	var b [16]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return lazyerrors.Errorf("bson.Decimal128.ReadFrom (io.ReadFull): %w", err)
+	}
+
+	d.L = binary.LittleEndian.Uint64(b[:8])
+	d.H = binary.LittleEndian.Uint64(b[8:])
+
+	return nil
+}
+
+// WriteTo implements bsontype interface.
+func (d decimal128Type) WriteTo(w *bufio.Writer) error {
+	v, err := d.MarshalBinary()
+	if err != nil {
+		return lazyerrors.Errorf("bson.Decimal128.WriteTo: %w", err)
+	}
+
+	_, err = w.Write(v)
+	if err != nil {
+		return lazyerrors.Errorf("bson.Decimal128.WriteTo: %w", err)
+	}
+
+	return nil
+}
+
+// MarshalBinary implements bsontype interface.
+func (d decimal128Type) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 16)
+
+	binary.LittleEndian.PutUint64(b[:8], d.L)
+	binary.LittleEndian.PutUint64(b[8:], d.H)
+
+	return b, nil
+}
+
+// check interfaces
+var (
+	_ bsontype = (*decimal128Type)(nil)
+)
@@ -0,0 +1,70 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bson
+
+import (
+	"testing"
+
+	"github.com/AlekSi/pointer"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+var decimal128TestCases = []testCase{{
+	name: "1",
+	v:    pointer.To(decimal128Type(types.Decimal128{H: 0x3040000000000000, L: 1})),
+	b: []byte{
+		0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x40, 0x30,
+	},
+}, {
+	name: "-1.23E+5",
+	v:    pointer.To(decimal128Type(types.Decimal128{H: 0xb046000000000000, L: 123})),
+	b: []byte{
+		0x7b, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x46, 0xb0,
+	},
+}, {
+	name: "NaN",
+	v:    pointer.To(decimal128Type(types.Decimal128{H: 0x7c00000000000000})),
+	b: []byte{
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x7c,
+	},
+}, {
+	name: "-Infinity",
+	v:    pointer.To(decimal128Type(types.Decimal128{H: 0xf800000000000000})),
+	b: []byte{
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xf8,
+	},
+}, {
+	name: "EOF",
+	b:    []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+	bErr: `unexpected EOF`,
+}}
+
+func TestDecimal128(t *testing.T) {
+	t.Parallel()
+	testBinary(t, decimal128TestCases, func() bsontype { return new(decimal128Type) })
+}
+
+func FuzzDecimal128(f *testing.F) {
+	fuzzBinary(f, decimal128TestCases, func() bsontype { return new(decimal128Type) })
+}
+
+func BenchmarkDecimal128(b *testing.B) {
+	benchmark(b, decimal128TestCases, func() bsontype { return new(decimal128Type) })
+}
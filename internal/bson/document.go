@@ -176,7 +176,8 @@ func (doc *Document) ReadFrom(r *bufio.Reader) error {
 			doc.m[string(ename)] = types.Binary(v)
 
 		case tagUndefined:
-			return lazyerrors.Errorf("bson.Document.ReadFrom: unhandled element type `Undefined (value) — Deprecated`")
+			// deprecated type without value, replaced by Null
+			doc.m[string(ename)] = types.Null
 
 		case tagObjectID:
 			var v objectIDType
@@ -231,7 +232,30 @@ func (doc *Document) ReadFrom(r *bufio.Reader) error {
 			}
 			doc.m[string(ename)] = int64(v)
 
-		case tagDBPointer, tagDecimal, tagJavaScript, tagJavaScriptScope, tagMaxKey, tagMinKey, tagSymbol:
+		case tagDecimal:
+			var v decimal128Type
+			if err := v.ReadFrom(bufr); err != nil {
+				return lazyerrors.Errorf("bson.Document.ReadFrom (Decimal128): %w", err)
+			}
+			doc.m[string(ename)] = types.Decimal128(v)
+
+		case tagSymbol:
+			// deprecated type with the same encoding as String, replaced by it
+			var v stringType
+			if err := v.ReadFrom(bufr); err != nil {
+				return lazyerrors.Errorf("bson.Document.ReadFrom (Symbol): %w", err)
+			}
+			doc.m[string(ename)] = string(v)
+
+		case tagDBPointer:
+			// deprecated type, replaced by DBRef document
+			var v dbPointerType
+			if err := v.ReadFrom(bufr); err != nil {
+				return lazyerrors.Errorf("bson.Document.ReadFrom (DBPointer): %w", err)
+			}
+			doc.m[string(ename)] = v.dbRef()
+
+		case tagJavaScript, tagJavaScriptScope, tagMaxKey, tagMinKey:
 			return lazyerrors.Errorf("bson.Document.ReadFrom: unhandled element type %#02x (%s)", t, tag(t))
 		default:
 			return lazyerrors.Errorf("bson.Document.ReadFrom: unhandled element type %#02x (%s)", t, tag(t))
@@ -392,6 +416,15 @@ func (doc Document) MarshalBinary() ([]byte, error) {
 				return nil, lazyerrors.Error(err)
 			}
 
+		case types.Decimal128:
+			bufw.WriteByte(byte(tagDecimal))
+			if err := ename.WriteTo(bufw); err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+			if err := decimal128Type(elV).WriteTo(bufw); err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+
 		default:
 			return nil, lazyerrors.Errorf("bson.Document.MarshalBinary: unhandled element type %T", elV)
 		}
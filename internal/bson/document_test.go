@@ -15,9 +15,14 @@
 package bson
 
 import (
+	"bufio"
+	"bytes"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/util/testutil"
@@ -171,12 +176,53 @@ var (
 	documentTestCases = []testCase{handshake1, handshake2, handshake3, handshake4, all, eof}
 )
 
+// deprecatedTypes is a document with deprecated BSON types:
+// {"s": Symbol("foo"), "u": undefined, "p": DBPointer("db.c", ObjectID(0101...)), "a": [undefined]}.
+var deprecatedTypes = []byte{
+	0x36, 0x00, 0x00, 0x00, // document length
+	0x0e, 0x73, 0x00, 0x04, 0x00, 0x00, 0x00, 0x66, 0x6f, 0x6f, 0x00, // "s": Symbol
+	0x06, 0x75, 0x00, // "u": Undefined
+	0x0c, 0x70, 0x00, 0x05, 0x00, 0x00, 0x00, 0x64, 0x62, 0x2e, 0x63, 0x00, // "p": DBPointer namespace
+	0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, // DBPointer id
+	0x04, 0x61, 0x00, 0x08, 0x00, 0x00, 0x00, 0x06, 0x30, 0x00, 0x00, // "a": array with Undefined
+	0x00, // end of document
+}
+
+func TestDocumentDeprecatedTypes(t *testing.T) {
+	t.Parallel()
+
+	expected := must.NotFail(types.NewDocument(
+		"s", "foo",
+		"u", types.Null,
+		"p", must.NotFail(types.NewDocument(
+			"$ref", "db.c",
+			"$id", types.ObjectID{0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01},
+		)),
+		"a", must.NotFail(types.NewArray(types.Null)),
+	))
+
+	var doc Document
+	bufr := bufio.NewReader(bytes.NewReader(deprecatedTypes))
+	require.NoError(t, doc.ReadFrom(bufr))
+	assert.Zero(t, bufr.Buffered(), "not all bufr bytes were consumed")
+	assert.Equal(t, MustConvertDocument(expected), &doc)
+
+	// replacement types are encoded
+	expectedB, err := MustConvertDocument(expected).MarshalBinary()
+	require.NoError(t, err)
+	actualB, err := doc.MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, expectedB, actualB)
+	assert.NotEqual(t, deprecatedTypes, actualB)
+}
+
 func TestDocument(t *testing.T) {
 	t.Parallel()
 	testBinary(t, documentTestCases, func() bsontype { return new(Document) })
 }
 
 func FuzzDocument(f *testing.F) {
+	f.Add(deprecatedTypes)
 	fuzzBinary(f, documentTestCases, func() bsontype { return new(Document) })
 }
 
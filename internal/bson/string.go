@@ -20,6 +20,7 @@ import (
 	"encoding/binary"
 	"io"
 
+	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 )
 
@@ -34,7 +35,7 @@ func (str *stringType) ReadFrom(r *bufio.Reader) error {
 	if err := binary.Read(r, binary.LittleEndian, &l); err != nil {
 		return lazyerrors.Error(err)
 	}
-	if l <= 0 {
+	if l <= 0 || l > types.MaxDocumentLen {
 		return lazyerrors.Errorf("invalid length %d", l)
 	}
 
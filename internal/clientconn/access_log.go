@@ -0,0 +1,153 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// accessLogBufferSize is the number of access log entries buffered before new ones are dropped.
+const accessLogBufferSize = 4096
+
+// accessLogEntry represents a single line of the access log.
+//
+// Request and response contents are never included.
+type accessLogEntry struct {
+	Time           time.Time `json:"ts"`
+	Conn           string    `json:"conn"`
+	Remote         string    `json:"remote"`
+	User           string    `json:"user,omitempty"`
+	NS             string    `json:"ns,omitempty"`
+	Command        string    `json:"command"`
+	DurationMillis float64   `json:"durationMillis"`
+	OK             bool      `json:"ok"`
+	Code           int32     `json:"code,omitempty"`
+	BytesIn        int32     `json:"bytesIn"`  // uncompressed request size
+	BytesOut       int32     `json:"bytesOut"` // uncompressed response size
+}
+
+// accessLog writes access log entries as JSON lines in a separate goroutine.
+//
+// Writing never blocks request processing: if the buffer is full, entries are dropped and counted.
+type accessLog struct {
+	w       io.Writer
+	l       *zap.Logger
+	entries chan *accessLogEntry
+	done    chan struct{}
+
+	// drops is the total number of dropped entries, accessed atomically.
+	drops int64
+}
+
+// newAccessLog creates a new access log that writes to w.
+//
+// The caller should call run in a separate goroutine and close when all connections are done.
+func newAccessLog(w io.Writer, size int, l *zap.Logger) *accessLog {
+	return &accessLog{
+		w:       w,
+		l:       l,
+		entries: make(chan *accessLogEntry, size),
+		done:    make(chan struct{}),
+	}
+}
+
+// run writes entries until the log is closed.
+func (al *accessLog) run() {
+	defer close(al.done)
+
+	bufw := bufio.NewWriter(al.w)
+	enc := json.NewEncoder(bufw)
+
+	var failed bool
+
+	for entry := range al.entries {
+		err := enc.Encode(entry)
+
+		// flush when there are no more entries to write right now
+		if err == nil && len(al.entries) == 0 {
+			err = bufw.Flush()
+		}
+
+		if err != nil && !failed {
+			failed = true
+			al.l.Warn("Failed to write access log", zap.Error(err))
+		}
+	}
+}
+
+// log adds the entry to the log or drops it if the buffer is full.
+func (al *accessLog) log(entry *accessLogEntry) {
+	select {
+	case al.entries <- entry:
+	default:
+		atomic.AddInt64(&al.drops, 1)
+	}
+}
+
+// dropped returns the total number of dropped entries.
+func (al *accessLog) dropped() int64 {
+	if al == nil {
+		return 0
+	}
+
+	return atomic.LoadInt64(&al.drops)
+}
+
+// close writes all buffered entries and stops the writing goroutine.
+func (al *accessLog) close() {
+	close(al.entries)
+	<-al.done
+}
+
+// logAccess adds the access log entry for the handled request if the access log is enabled.
+func (c *conn) logAccess(start time.Time, reqHeader, resHeader *wire.MsgHeader, command string, document *types.Document, code common.ErrorCode, result string) { //nolint:lll // argument list is too long
+	if c.accessLog == nil {
+		return
+	}
+
+	var remote string
+	if addr := c.connInfo.PeerAddr; addr != nil {
+		remote = addr.String()
+	}
+
+	var user string
+	if auth := c.connInfo.Auth; auth.Authenticated {
+		user = auth.Username + "@" + auth.Database
+	}
+
+	c.accessLog.log(&accessLogEntry{
+		Time:           start.UTC(),
+		Conn:           c.connID,
+		Remote:         remote,
+		User:           user,
+		NS:             requestNS(command, document),
+		Command:        command,
+		DurationMillis: float64(time.Since(start).Microseconds()) / 1000,
+		OK:             result == "ok",
+		Code:           int32(code),
+		BytesIn:        reqHeader.MessageLength,
+		BytesOut:       resHeader.MessageLength,
+	})
+}
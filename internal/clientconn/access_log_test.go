@@ -0,0 +1,160 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/dummy"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// syncBuffer is a bytes.Buffer protected by a mutex.
+type syncBuffer struct {
+	m sync.Mutex
+	b bytes.Buffer
+}
+
+// Write implements io.Writer.
+func (sb *syncBuffer) Write(p []byte) (int, error) {
+	sb.m.Lock()
+	defer sb.m.Unlock()
+
+	return sb.b.Write(p)
+}
+
+// String returns the buffer content.
+func (sb *syncBuffer) String() string {
+	sb.m.Lock()
+	defer sb.m.Unlock()
+
+	return sb.b.String()
+}
+
+func TestAccessLog(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	h, err := dummy.New()
+	require.NoError(t, err)
+
+	var buf syncBuffer
+
+	l := NewListener(&NewListenerOpts{
+		ListenAddr: "127.0.0.1:0",
+		AccessLog:  &buf,
+		Mode:       NormalMode,
+		Handler:    h,
+		Logger:     zaptest.NewLogger(t),
+		Metrics:    prometheus.NewRegistry(),
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.ErrorIs(t, l.Run(ctx), context.Canceled)
+	}()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	require.NoError(t, err)
+
+	roundTrip(t, conn, 1, must.NotFail(types.NewDocument("buildInfo", int32(1), "$db", "admin")))
+	roundTrip(t, conn, 2, must.NotFail(types.NewDocument("find", "values", "filter", types.MakeDocument(0), "$db", "test")))
+	require.NoError(t, conn.Close())
+
+	cancel()
+	<-done
+
+	var lines []map[string]any
+
+	s := bufio.NewScanner(bytes.NewBufferString(buf.String()))
+	for s.Scan() {
+		var line map[string]any
+		require.NoError(t, json.Unmarshal(s.Bytes(), &line), "%s", s.Text())
+		lines = append(lines, line)
+	}
+	require.NoError(t, s.Err())
+	require.Len(t, lines, 2)
+
+	for _, line := range lines {
+		// ts, durationMillis, and bytes vary
+		ts, err := time.Parse(time.RFC3339Nano, line["ts"].(string))
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now(), ts, time.Minute)
+		delete(line, "ts")
+
+		assert.GreaterOrEqual(t, line["durationMillis"].(float64), float64(0))
+		delete(line, "durationMillis")
+
+		assert.Greater(t, line["bytesIn"].(float64), float64(0))
+		delete(line, "bytesIn")
+
+		assert.Greater(t, line["bytesOut"].(float64), float64(0))
+		delete(line, "bytesOut")
+
+		assert.Contains(t, line["conn"], conn.LocalAddr().String()+" -> ")
+		delete(line, "conn")
+
+		assert.Equal(t, conn.LocalAddr().String(), line["remote"])
+		delete(line, "remote")
+	}
+
+	expected := []map[string]any{{
+		"ns":      "admin",
+		"command": "buildInfo",
+		"ok":      true,
+	}, {
+		"ns":      "test.values",
+		"command": "find",
+		"ok":      false,
+		"code":    float64(common.ErrNotImplemented),
+	}}
+	assert.Equal(t, expected, lines)
+}
+
+func TestAccessLogDrop(t *testing.T) {
+	t.Parallel()
+
+	var buf syncBuffer
+	al := newAccessLog(&buf, 1, zaptest.NewLogger(t))
+
+	// the buffer is not drained until run is called
+	for i := 0; i < 3; i++ {
+		al.log(&accessLogEntry{Command: "ping", OK: true})
+	}
+
+	assert.Equal(t, int64(2), al.dropped())
+
+	go al.run()
+	al.close()
+
+	assert.Equal(t, 1, bytes.Count([]byte(buf.String()), []byte("\n")))
+}
@@ -0,0 +1,48 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+)
+
+// authNotRequiredCommands contains commands that are allowed on unauthenticated connections
+// when authentication is required.
+var authNotRequiredCommands = map[string]struct{}{
+	"hello":        {},
+	"isMaster":     {},
+	"ismaster":     {},
+	"saslStart":    {},
+	"saslContinue": {},
+	"buildInfo":    {},
+	"buildinfo":    {},
+	"ping":         {},
+}
+
+// checkAuth returns Unauthorized error if authentication is required,
+// the connection is not authenticated, and the given command requires it.
+func (c *conn) checkAuth(command string) error {
+	if !c.requireAuth || c.connInfo.Auth.Authenticated {
+		return nil
+	}
+
+	if _, ok := authNotRequiredCommands[command]; ok {
+		return nil
+	}
+
+	return common.NewErrorMsg(common.ErrUnauthorized, fmt.Sprintf("command %s requires authentication", command))
+}
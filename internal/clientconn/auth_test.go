@@ -0,0 +1,164 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/handlers"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/dummy"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// authHandler is a dummy handler with authentication commands that accept a single user.
+type authHandler struct {
+	handlers.Interface
+}
+
+// MsgSaslStart implements handlers.Interface.
+func (h *authHandler) MsgSaslStart(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	return common.MsgSaslStart(ctx, msg, func(ctx context.Context, username, password string) (bool, error) {
+		return username == "user" && password == "pass", nil
+	})
+}
+
+// MsgSaslContinue implements handlers.Interface.
+func (h *authHandler) MsgSaslContinue(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	return common.MsgSaslContinue(ctx, msg)
+}
+
+// MsgLogout implements handlers.Interface.
+func (h *authHandler) MsgLogout(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	return common.MsgLogout(ctx, msg)
+}
+
+// MsgConnectionStatus implements handlers.Interface.
+func (h *authHandler) MsgConnectionStatus(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	return common.MsgConnectionStatus(ctx, msg)
+}
+
+func TestRequireAuth(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dh, err := dummy.New()
+	require.NoError(t, err)
+
+	l := NewListener(&NewListenerOpts{
+		ListenAddr:  "127.0.0.1:0",
+		RequireAuth: true,
+		Mode:        NormalMode,
+		Handler:     &authHandler{Interface: dh},
+		Logger:      zaptest.NewLogger(t),
+		Metrics:     prometheus.NewRegistry(),
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.ErrorIs(t, l.Run(ctx), context.Canceled)
+	}()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	var requestID int32
+	run := func(t *testing.T, doc *types.Document) *types.Document {
+		t.Helper()
+
+		requestID++
+		return roundTrip(t, conn, requestID, doc)
+	}
+
+	assertError := func(t *testing.T, res *types.Document, code common.ErrorCode, errmsg string) {
+		t.Helper()
+
+		assert.Equal(t, float64(0), must.NotFail(res.Get("ok")))
+		assert.Equal(t, int32(code), must.NotFail(res.Get("code")))
+		assert.Equal(t, errmsg, must.NotFail(res.Get("errmsg")))
+	}
+
+	saslStart := func(username, password string) *types.Document {
+		return must.NotFail(types.NewDocument(
+			"saslStart", int32(1),
+			"mechanism", "PLAIN",
+			"payload", types.Binary{B: []byte("\x00" + username + "\x00" + password)},
+			"$db", "$external",
+		))
+	}
+
+	connectionStatus := must.NotFail(types.NewDocument("connectionStatus", int32(1), "$db", "admin"))
+
+	res := run(t, must.NotFail(types.NewDocument("find", "values", "$db", "test")))
+	assertError(t, res, common.ErrUnauthorized, "command find requires authentication")
+
+	res = run(t, connectionStatus)
+	assertError(t, res, common.ErrUnauthorized, "command connectionStatus requires authentication")
+
+	res = run(t, must.NotFail(types.NewDocument("buildInfo", int32(1), "$db", "admin")))
+	assert.Equal(t, float64(1), must.NotFail(res.Get("ok")))
+
+	res = run(t, must.NotFail(types.NewDocument(
+		"saslStart", int32(1), "mechanism", "SCRAM-SHA-256", "payload", types.Binary{}, "$db", "admin",
+	)))
+	assertError(t, res, common.ErrMechanismUnavailable, "Received authentication for mechanism SCRAM-SHA-256 which is not enabled")
+
+	res = run(t, saslStart("user", "wrong"))
+	assertError(t, res, common.ErrAuthenticationFailed, "Authentication failed.")
+
+	res = run(t, saslStart("user", "pass"))
+	assert.Equal(t, float64(1), must.NotFail(res.Get("ok")))
+	assert.Equal(t, true, must.NotFail(res.Get("done")))
+	conversationID := must.NotFail(res.Get("conversationId")).(int32)
+
+	res = run(t, must.NotFail(types.NewDocument(
+		"saslContinue", int32(1), "conversationId", conversationID, "payload", types.Binary{}, "$db", "$external",
+	)))
+	assert.Equal(t, float64(1), must.NotFail(res.Get("ok")))
+	assert.Equal(t, true, must.NotFail(res.Get("done")))
+
+	res = run(t, connectionStatus)
+	assert.Equal(t, float64(1), must.NotFail(res.Get("ok")))
+	users := must.NotFail(res.GetByPath(types.NewPathFromString("authInfo.authenticatedUsers"))).(*types.Array)
+	expected := must.NotFail(types.NewDocument("user", "user", "db", "$external"))
+	assert.Equal(t, expected, must.NotFail(users.Get(0)))
+
+	res = run(t, must.NotFail(types.NewDocument("logout", int32(1), "$db", "$external")))
+	assert.Equal(t, float64(1), must.NotFail(res.Get("ok")))
+
+	res = run(t, connectionStatus)
+	assertError(t, res, common.ErrUnauthorized, "command connectionStatus requires authentication")
+
+	res = run(t, must.NotFail(types.NewDocument(
+		"saslContinue", int32(1), "conversationId", conversationID, "payload", types.Binary{}, "$db", "$external",
+	)))
+	assertError(t, res, common.ErrProtocolError, "No SASL session state found")
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,84 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/sanitize"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// maxLoggedDocuments is the number of documents in OP_REPLY after which the rest are only counted.
+const maxLoggedDocuments = 10
+
+// bodyShape returns a representation of the message body for logging
+// that includes field names and value types, but not values.
+func bodyShape(body wire.MsgBody) string {
+	var sb strings.Builder
+
+	switch body := body.(type) {
+	case *wire.OpMsg:
+		fmt.Fprintf(&sb, "OP_MSG flags: %s", body.FlagBits)
+
+		for _, s := range body.Sections() {
+			switch s.Kind {
+			case 0:
+				fmt.Fprintf(&sb, "\nsection 0: %s", shapes(s.Documents))
+			case 1:
+				fmt.Fprintf(&sb, "\nsection 1 %q: [%d x object]", s.Identifier, len(s.Documents))
+			}
+		}
+
+	case *wire.OpQuery:
+		fmt.Fprintf(
+			&sb, "OP_QUERY flags: %s, collection: %s, skip: %d, return: %d\nquery: %s",
+			body.Flags, body.FullCollectionName, body.NumberToSkip, body.NumberToReturn, sanitize.Document(body.Query),
+		)
+
+		if body.ReturnFieldsSelector != nil {
+			fmt.Fprintf(&sb, "\nfields: %s", sanitize.Document(body.ReturnFieldsSelector))
+		}
+
+	case *wire.OpReply:
+		fmt.Fprintf(
+			&sb, "OP_REPLY flags: %s, cursor ID: %d, starting from: %d\ndocuments: %s",
+			body.ResponseFlags, body.CursorID, body.StartingFrom, shapes(body.Documents),
+		)
+
+	default:
+		fmt.Fprintf(&sb, "%T", body)
+	}
+
+	return sb.String()
+}
+
+// shapes returns shapes of the first maxLoggedDocuments documents.
+func shapes(docs []*types.Document) string {
+	res := make([]string, 0, len(docs))
+
+	for i, doc := range docs {
+		if i == maxLoggedDocuments {
+			res = append(res, fmt.Sprintf("... %d more", len(docs)-i))
+			break
+		}
+
+		res = append(res, sanitize.Document(doc))
+	}
+
+	return strings.Join(res, ", ")
+}
@@ -0,0 +1,66 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+func TestBodyShape(t *testing.T) {
+	t.Parallel()
+
+	var msg wire.OpMsg
+	err := msg.SetSections(
+		wire.OpMsgSection{
+			Documents: []*types.Document{must.NotFail(types.NewDocument(
+				"insert", "secretCollection",
+				"$db", "secretDatabase",
+			))},
+		},
+		wire.OpMsgSection{
+			Kind:       1,
+			Identifier: "documents",
+			Documents: []*types.Document{
+				must.NotFail(types.NewDocument("_id", "secret1")),
+				must.NotFail(types.NewDocument("_id", "secret2")),
+			},
+		},
+	)
+	require.NoError(t, err)
+
+	actual := bodyShape(&msg)
+	expected := "OP_MSG flags: []\n" +
+		`section 0: {"insert": string, "$db": string}` + "\n" +
+		`section 1 "documents": [2 x object]`
+	assert.Equal(t, expected, actual)
+	assert.NotContains(t, actual, "secret")
+
+	reply := &wire.OpReply{
+		CursorID:       42,
+		NumberReturned: 1,
+		Documents:      []*types.Document{must.NotFail(types.NewDocument("ismaster", true, "me", "secret"))},
+	}
+
+	expected = "OP_REPLY flags: [], cursor ID: 42, starting from: 0\n" +
+		`documents: {"ismaster": bool, "me": string}`
+	assert.Equal(t, expected, bodyShape(reply))
+}
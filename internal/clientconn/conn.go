@@ -18,17 +18,22 @@ package clientconn
 import (
 	"bufio"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net"
+	"strings"
 	"sync/atomic"
 	"time"
 
 	"github.com/AlekSi/pointer"
 	"github.com/pmezard/go-difflib/difflib"
 	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"golang.org/x/exp/slices"
 
 	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
 	"github.com/FerretDB/FerretDB/internal/handlers"
@@ -37,6 +42,7 @@ import (
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/sanitize"
 	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
@@ -59,6 +65,9 @@ const (
 // AllModes includes all operation modes, with the first one being the default.
 var AllModes = []Mode{NormalMode, ProxyMode, DiffNormalMode, DiffProxyMode}
 
+// compressionThreshold is the minimal response size (including header) that is compressed.
+const compressionThreshold = 1024
+
 // conn represents client connection.
 type conn struct {
 	netConn       net.Conn
@@ -67,7 +76,20 @@ type conn struct {
 	h             handlers.Interface
 	m             *ConnMetrics
 	proxy         *proxy.Router
+	diffIgnored   map[string][]string
+	recorder      *connRecorder
+	inFlight      *inFlight
+	requireAuth   bool
+	idleTimeout   time.Duration
+	connID        string
+	accessLog     *accessLog
+	connInfo      *conninfo.ConnInfo
+	tracer        trace.Tracer // nil if tracing is disabled
+	logPayloads   bool
 	lastRequestID int32
+
+	// clientMetadataLogged is true if client metadata was logged and added to the logger.
+	clientMetadataLogged bool
 }
 
 // newConnOpts represents newConn options.
@@ -78,6 +100,18 @@ type newConnOpts struct {
 	handler     handlers.Interface
 	connMetrics *ConnMetrics
 	proxyAddr   string
+	diffIgnored map[string][]string
+	recorder    *recorder
+	inFlight    *inFlight
+	connections *conninfo.Connections
+	slowOps     *conninfo.SlowOps
+	readOnly    *conninfo.ReadOnly
+	requireAuth bool
+	idleTimeout time.Duration
+	connID      string       // used in the access log
+	accessLog   *accessLog   // nil if disabled
+	tracer      trace.Tracer // nil if disabled
+	logPayloads bool         // log full request and response bodies instead of their shapes
 }
 
 // newConn creates a new client connection for given net.Conn.
@@ -94,13 +128,41 @@ func newConn(opts *newConnOpts) (*conn, error) {
 		}
 	}
 
+	var r *connRecorder
+	if opts.recorder != nil {
+		var err error
+		if r, err = opts.recorder.open(opts.netConn.RemoteAddr().String()); err != nil {
+			if p != nil {
+				p.Close()
+			}
+			return nil, err
+		}
+	}
+
 	return &conn{
-		netConn: opts.netConn,
-		mode:    opts.mode,
-		l:       opts.l.Sugar(),
-		h:       opts.handler,
-		m:       opts.connMetrics,
-		proxy:   p,
+		netConn:     opts.netConn,
+		mode:        opts.mode,
+		l:           opts.l.Sugar(),
+		h:           opts.handler,
+		m:           opts.connMetrics,
+		proxy:       p,
+		diffIgnored: opts.diffIgnored,
+		recorder:    r,
+		inFlight:    opts.inFlight,
+		requireAuth: opts.requireAuth,
+		idleTimeout: opts.idleTimeout,
+		connID:      opts.connID,
+		accessLog:   opts.accessLog,
+		tracer:      opts.tracer,
+		logPayloads: opts.logPayloads,
+		connInfo: &conninfo.ConnInfo{
+			PeerAddr:          opts.netConn.RemoteAddr(),
+			AggregationStages: opts.connMetrics.aggregationStages,
+			Commands:          opts.connMetrics.commands,
+			Connections:       opts.connections,
+			SlowOps:           opts.slowOps,
+			ReadOnly:          opts.readOnly,
+		},
 	}, nil
 }
 
@@ -139,6 +201,11 @@ func (c *conn) run(ctx context.Context) (err error) {
 		close(done)
 	}()
 
+	if conns := c.connInfo.Connections; conns != nil {
+		conns.AddConn(c.connID, c.netConn.RemoteAddr().String(), time.Now())
+		defer conns.RemoveConn(c.connID)
+	}
+
 	bufr := bufio.NewReader(c.netConn)
 	bufw := bufio.NewWriter(c.netConn)
 	defer func() {
@@ -150,19 +217,69 @@ func (c *conn) run(ctx context.Context) (err error) {
 			c.proxy.Close()
 		}
 
+		if c.recorder != nil {
+			if e := c.recorder.close(); e != nil {
+				c.l.Warnf("Failed to close recording: %s", e)
+			}
+		}
+
+		if md := c.connInfo.ClientMetadata; md != nil && c.connInfo.Connections != nil {
+			c.connInfo.Connections.RemoveDriver(md.Driver)
+		}
+
+		c.connInfo.Close()
+
 		// c.netConn is closed by the caller
 	}()
 
 	for {
+		if err = c.setIdleDeadline(ctx); err != nil {
+			return
+		}
+
 		var reqHeader *wire.MsgHeader
 		var reqBody wire.MsgBody
 		reqHeader, reqBody, err = wire.ReadMessage(bufr)
 		if err != nil {
+			if errors.Is(err, wire.ErrMessageTooLarge) {
+				c.l.Errorf("Closing connection: %s", err)
+			}
+
+			err = c.checkIdle(ctx, err)
 			return
 		}
 
-		c.l.Debugf("Request header: %s", reqHeader)
-		c.l.Debugf("Request message:\n%s\n\n\n", reqBody)
+		if conns := c.connInfo.Connections; conns != nil {
+			conns.ConnRequest(c.connID, time.Now())
+		}
+
+		// unwrap compressed request; the response is compressed with the same compressor
+		compressor := wire.CompressorNoop
+		if compressed, ok := reqBody.(*wire.OpCompressed); ok {
+			compressor = compressed.CompressorID
+			if reqHeader, reqBody, err = wire.Decompress(reqHeader, compressed); err != nil {
+				return
+			}
+		}
+
+		c.logRequest(reqHeader, reqBody)
+
+		if c.recorder != nil {
+			c.record(c.recorder.request(reqHeader, reqBody))
+		}
+
+		// moreToCome requests are handled, but not replied to;
+		// exhaust replies are streamed only in normal mode, proxy never gets exhaustAllowed flag
+		var moreToCome, exhaust bool
+		if msg, ok := reqBody.(*wire.OpMsg); ok {
+			moreToCome = msg.FlagBits.FlagSet(wire.OpMsgMoreToCome)
+
+			if c.mode != NormalMode {
+				msg.FlagBits &^= wire.OpMsgFlags(wire.OpMsgExhaustAllowed)
+			}
+
+			exhaust = msg.FlagBits.FlagSet(wire.OpMsgExhaustAllowed) && !moreToCome
+		}
 
 		// diffLogLevel provides the level of logging for the diff between the "normal" and "proxy" responses.
 		// It is set to the highest level of logging used to log response.
@@ -173,7 +290,7 @@ func (c *conn) run(ctx context.Context) (err error) {
 		var resBody wire.MsgBody
 		var resCloseConn bool
 		if c.mode != ProxyMode {
-			resHeader, resBody, resCloseConn = c.route(ctx, reqHeader, reqBody)
+			resHeader, resBody, resCloseConn = c.routeUntilDisconnect(ctx, bufr, reqHeader, reqBody)
 			diffLogLevel = c.logResponse("Response", resHeader, resBody, resCloseConn)
 		}
 
@@ -186,21 +303,36 @@ func (c *conn) run(ctx context.Context) (err error) {
 			}
 
 			proxyHeader, proxyBody, _ = c.proxy.Route(ctx, reqHeader, reqBody)
-			if level := c.logResponse("Proxy response", proxyHeader, proxyBody, resCloseConn); level != diffLogLevel {
-				// In principle, normal and proxy responses should be logged with the same level
-				// as they behave the same way. If it's not true, there is a bug somewhere, so
-				// we should log the diff as an error.
-				diffLogLevel = zap.ErrorLevel
+			if !moreToCome {
+				if level := c.logResponse("Proxy response", proxyHeader, proxyBody, resCloseConn); level != diffLogLevel {
+					// In principle, normal and proxy responses should be logged with the same level
+					// as they behave the same way. If it's not true, there is a bug somewhere, so
+					// we should log the diff as an error.
+					diffLogLevel = zap.ErrorLevel
+				}
+			}
+		}
+
+		// the client does not expect a reply
+		if moreToCome {
+			if resCloseConn {
+				err = errors.New("fatal error")
+				return
 			}
+
+			continue
 		}
 
 		// diff in diff mode
 		if c.mode == DiffNormalMode || c.mode == DiffProxyMode {
-			var diffHeader string
-			diffHeader, err = difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
-				A:        difflib.SplitLines(resHeader.String()),
+			// volatile fields are normalized in both responses to make the diff readable
+			command := requestCommand(reqBody)
+
+			var headerDiff string
+			headerDiff, err = difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+				A:        difflib.SplitLines(diffHeader(resHeader)),
 				FromFile: "res header",
-				B:        difflib.SplitLines(proxyHeader.String()),
+				B:        difflib.SplitLines(diffHeader(proxyHeader)),
 				ToFile:   "proxy header",
 				Context:  1,
 			})
@@ -208,11 +340,11 @@ func (c *conn) run(ctx context.Context) (err error) {
 				return
 			}
 
-			var diffBody string
-			diffBody, err = difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
-				A:        difflib.SplitLines(resBody.String()),
+			var bodyDiff string
+			bodyDiff, err = difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+				A:        difflib.SplitLines(diffBody(command, resBody, c.diffIgnored)),
 				FromFile: "res body",
-				B:        difflib.SplitLines(proxyBody.String()),
+				B:        difflib.SplitLines(diffBody(command, proxyBody, c.diffIgnored)),
 				ToFile:   "proxy body",
 				Context:  1,
 			})
@@ -220,7 +352,7 @@ func (c *conn) run(ctx context.Context) (err error) {
 				return
 			}
 
-			c.l.Desugar().Check(diffLogLevel, fmt.Sprintf("Header diff:\n%s\nBody diff:\n%s\n\n", diffHeader, diffBody)).Write()
+			c.l.Desugar().Check(diffLogLevel, fmt.Sprintf("Header diff:\n%s\nBody diff:\n%s\n\n", headerDiff, bodyDiff)).Write()
 		}
 
 		// replace response with one from proxy in proxy and diff-proxy modes
@@ -233,19 +365,124 @@ func (c *conn) run(ctx context.Context) (err error) {
 			panic("no response to send to client")
 		}
 
-		if err = wire.WriteMessage(bufw, resHeader, resBody); err != nil {
-			return
-		}
+		more := exhaust && !resCloseConn && exhaustMore(reqBody, resBody)
 
-		if err = bufw.Flush(); err != nil {
-			return
+		for replies := 1; ; replies++ {
+			// let the client send the next request itself from time to time
+			if replies >= maxExhaustReplies {
+				more = false
+			}
+
+			if more {
+				resBody.(*wire.OpMsg).FlagBits |= wire.OpMsgFlags(wire.OpMsgMoreToCome)
+			}
+
+			if err = c.writeResponse(bufw, compressor, resHeader, resBody); err != nil {
+				return
+			}
+
+			if resCloseConn {
+				err = errors.New("fatal error")
+				return
+			}
+
+			if !more {
+				break
+			}
+
+			// handle the same request again for the next exhaust reply;
+			// it is a response to the previous reply, not to the original request
+			nextHeader := *reqHeader
+			nextHeader.RequestID = resHeader.RequestID
+
+			resHeader, resBody, resCloseConn = c.routeUntilDisconnect(ctx, bufr, &nextHeader, reqBody)
+			c.logResponse("Response", resHeader, resBody, resCloseConn)
+
+			more = !resCloseConn && exhaustMore(reqBody, resBody)
 		}
+	}
+}
 
-		if resCloseConn {
-			err = errors.New("fatal error")
-			return
+// writeResponse records and compresses response if needed, writes it to the client and flushes the buffer.
+func (c *conn) writeResponse(bufw *bufio.Writer, compressor wire.CompressorID, resHeader *wire.MsgHeader, resBody wire.MsgBody) error { //nolint:lll // argument list is too long
+	if c.recorder != nil {
+		c.record(c.recorder.response(resHeader, resBody))
+	}
+
+	var err error
+	if c.compressResponse(compressor, resHeader) {
+		if resHeader, resBody, err = wire.Compress(resHeader, resBody, compressor); err != nil {
+			return err
 		}
 	}
+
+	if err = wire.WriteMessage(bufw, resHeader, resBody); err != nil {
+		return err
+	}
+
+	return bufw.Flush()
+}
+
+// record handles recording error by logging it and stopping recording for the connection.
+func (c *conn) record(err error) {
+	if err == nil {
+		return
+	}
+
+	c.l.Warnf("Recording stopped: %s", err)
+
+	if e := c.recorder.close(); e != nil {
+		c.l.Warnf("Failed to close recording: %s", e)
+	}
+
+	c.recorder = nil
+}
+
+// maxExhaustReplies is the maximum number of replies streamed for a single exhaust request.
+const maxExhaustReplies = 1000
+
+// exhaustMore returns true if the server should send more replies to the exhaust request
+// without waiting for the client's next request.
+//
+// That's the case for getMore with a live cursor and for awaitable hello/isMaster
+// that actually waited for the topology change.
+// Otherwise, the same request would be handled again immediately, making a busy loop.
+func exhaustMore(reqBody, resBody wire.MsgBody) bool {
+	reqMsg, ok := reqBody.(*wire.OpMsg)
+	if !ok {
+		return false
+	}
+
+	resMsg, ok := resBody.(*wire.OpMsg)
+	if !ok {
+		return false
+	}
+
+	req, err := reqMsg.Document()
+	if err != nil {
+		return false
+	}
+
+	res, err := resMsg.Document()
+	if err != nil {
+		return false
+	}
+
+	if ok, _ := res.Get("ok"); ok != float64(1) {
+		return false
+	}
+
+	switch req.Command() {
+	case "getMore":
+		id, err := res.GetByPath(types.NewPathFromString("cursor.id"))
+		return err == nil && id != int64(0)
+
+	case "hello", "isMaster", "ismaster":
+		return common.TopologyChangeAwaited(req)
+
+	default:
+		return false
+	}
 }
 
 // route sends request to a handler's command based on the op code provided in the request header.
@@ -259,40 +496,85 @@ func (c *conn) run(ctx context.Context) (err error) {
 // They also should not use recover(). That allows us to use fuzzing.
 func (c *conn) route(ctx context.Context, reqHeader *wire.MsgHeader, reqBody wire.MsgBody) (resHeader *wire.MsgHeader, resBody wire.MsgBody, closeConn bool) { //nolint:lll // argument list is too long
 	requests := c.m.requests.MustCurryWith(prometheus.Labels{"opcode": reqHeader.OpCode.String()})
+	start := time.Now()
 	var command string
+	var document *types.Document
 	var result *string
+	var code common.ErrorCode
+	var span trace.Span
+	c.connInfo.OpStats = conninfo.OpStats{}
 	defer func() {
 		if result == nil {
 			result = pointer.ToString("panic")
 		}
-		c.m.responses.WithLabelValues(resHeader.OpCode.String(), command, *result).Inc()
+
+		if span != nil {
+			endCommandSpan(span, code, *result)
+		}
+
+		label := commandLabel(command)
+		c.m.responses.WithLabelValues(resHeader.OpCode.String(), label, *result).Inc()
+		c.m.commands.WithLabelValues(label, *result).Inc()
+		d := time.Since(start)
+		c.m.commandDuration.WithLabelValues(label, *result).Observe(d.Seconds())
+
+		c.logSlowOp(command, document, *result, d)
+		c.logAccess(start, reqHeader, resHeader, command, document, code, *result)
 	}()
 
-	connInfo := &conninfo.ConnInfo{
-		PeerAddr:          c.netConn.RemoteAddr(),
-		AggregationStages: c.m.aggregationStages,
-	}
-	ctx, cancel := context.WithCancel(conninfo.WithConnInfo(ctx, connInfo))
+	ctx, cancel := context.WithCancel(conninfo.WithConnInfo(ctx, c.connInfo))
 	defer cancel()
 
+	// new requests are rejected during graceful shutdown
+	started := c.inFlight.start()
+	if started {
+		defer c.inFlight.done()
+	}
+
 	resHeader = new(wire.MsgHeader)
 	var err error
 	switch reqHeader.OpCode {
 	case wire.OpCodeMsg:
-		var document *types.Document
 		msg := reqBody.(*wire.OpMsg)
-		document, err = msg.Document()
+		// handlers decide how to report too large documents, see wire.DocumentTooLargeError
+		document, _, err = msg.DocumentWithOversized()
 
 		command = document.Command()
 		if err == nil {
 			resHeader.OpCode = wire.OpCodeMsg
-			resBody, err = c.handleOpMsg(ctx, msg, command)
+			if started {
+				if c.connInfo.Connections != nil {
+					op := &conninfo.Op{
+						Client:         c.connInfo.PeerAddr.String(),
+						ClientMetadata: c.connInfo.ClientMetadata,
+						Command:        document,
+						Start:          start,
+					}
+					c.connInfo.Connections.StartOp(op)
+					defer c.connInfo.Connections.FinishOp(op)
+				}
+
+				ctx, span = c.startCommandSpan(ctx, command, document, "")
+				resBody, err = c.recoverHandler(command, document, func() (wire.MsgBody, error) {
+					return c.handleOpMsg(ctx, msg, command)
+				})
+			} else {
+				err = errShutdownInProgress()
+			}
 		}
 
 	case wire.OpCodeQuery:
 		query := reqBody.(*wire.OpQuery)
+		command = query.Query.Command()
 		resHeader.OpCode = wire.OpCodeReply
-		resBody, err = c.h.CmdQuery(ctx, query)
+		if started {
+			ctx, span = c.startCommandSpan(ctx, command, query.Query, query.FullCollectionName)
+			resBody, err = c.recoverHandler(command, query.Query, func() (wire.MsgBody, error) {
+				return c.handleOpQuery(ctx, query, command)
+			})
+		} else {
+			err = errShutdownInProgress()
+		}
 
 	case wire.OpCodeReply:
 		fallthrough
@@ -315,7 +597,22 @@ func (c *conn) route(ctx context.Context, reqHeader *wire.MsgHeader, reqBody wir
 		err = lazyerrors.Errorf("unexpected OpCode %s", reqHeader.OpCode)
 	}
 
-	requests.WithLabelValues(command).Inc()
+	requests.WithLabelValues(commandLabel(command)).Inc()
+
+	// include appName in all subsequent connection's log entries
+	if md := c.connInfo.ClientMetadata; md != nil && !c.clientMetadataLogged {
+		c.clientMetadataLogged = true
+
+		if md.AppName != "" {
+			c.l = c.l.With("appName", md.AppName)
+
+			if conns := c.connInfo.Connections; conns != nil {
+				conns.SetConnAppName(c.connID, md.AppName)
+			}
+		}
+
+		c.l.Infow("Client metadata", "driver", md.Driver.Name, "version", md.Driver.Version)
+	}
 
 	// set body for error
 	if err != nil {
@@ -328,11 +625,21 @@ func (c *conn) route(ctx context.Context, reqHeader *wire.MsgHeader, reqBody wir
 				Documents: []*types.Document{protoErr.Document()},
 			}))
 			resBody = &res
-			result = pointer.ToString(protoErr.Code().String())
+			code = protoErr.Code()
+			result = pointer.ToString(code.String())
 
-		case wire.OpCodeQuery:
-			fallthrough
 		case wire.OpCodeReply:
+			protoErr, recoverable := common.ProtocolError(err)
+			closeConn = !recoverable
+			resBody = &wire.OpReply{
+				ResponseFlags:  wire.OpReplyFlags(wire.OpReplyQueryFailure),
+				NumberReturned: 1,
+				Documents:      []*types.Document{common.QueryFailureDocument(protoErr)},
+			}
+			code = protoErr.Code()
+			result = pointer.ToString(code.String())
+
+		case wire.OpCodeQuery:
 			fallthrough
 		case wire.OpCodeUpdate:
 			fallthrough
@@ -368,6 +675,13 @@ func (c *conn) route(ctx context.Context, reqHeader *wire.MsgHeader, reqBody wir
 		}
 	}
 
+	// add checksum to the response if the client added it to the request
+	if reqMsg, ok := reqBody.(*wire.OpMsg); ok && reqMsg.FlagBits.FlagSet(wire.OpMsgChecksumPresent) {
+		if resMsg, ok := resBody.(*wire.OpMsg); ok {
+			resMsg.FlagBits |= wire.OpMsgFlags(wire.OpMsgChecksumPresent)
+		}
+	}
+
 	// TODO Don't call MarshalBinary there. Fix header in the caller?
 	// https://github.com/FerretDB/FerretDB/issues/273
 	b, err := resBody.MarshalBinary()
@@ -386,10 +700,39 @@ func (c *conn) route(ctx context.Context, reqHeader *wire.MsgHeader, reqBody wir
 	return
 }
 
+// commandLabel returns the metrics label value for the given command name.
+//
+// Command names come from clients, so unknown commands share a single label value
+// to keep metrics cardinality bounded.
+func commandLabel(command string) string {
+	if _, ok := common.Commands[command]; ok {
+		return command
+	}
+
+	return "unknown"
+}
+
+// compressResponse returns true if the response should be compressed with the request's compressor.
+func (c *conn) compressResponse(compressor wire.CompressorID, resHeader *wire.MsgHeader) bool {
+	if compressor == wire.CompressorNoop || resHeader.MessageLength <= compressionThreshold {
+		return false
+	}
+
+	return slices.Contains(c.connInfo.Compressors, compressor)
+}
+
 func (c *conn) handleOpMsg(ctx context.Context, msg *wire.OpMsg, cmd string) (*wire.OpMsg, error) {
-	if cmd, ok := common.Commands[cmd]; ok {
-		if cmd.Handler != nil {
-			return cmd.Handler(c.h, ctx, msg)
+	if command, ok := common.Commands[cmd]; ok {
+		if command.Handler != nil {
+			if err := c.checkAuth(cmd); err != nil {
+				return nil, err
+			}
+
+			if err := c.checkReadOnly(cmd); err != nil {
+				return nil, err
+			}
+
+			return command.Handler(c.h, ctx, msg)
 		}
 	}
 
@@ -397,6 +740,68 @@ func (c *conn) handleOpMsg(ctx context.Context, msg *wire.OpMsg, cmd string) (*w
 	return nil, common.NewErrorMsg(common.ErrCommandNotFound, errMsg)
 }
 
+// recoverHandler calls the given command handler function.
+//
+// If it panics, the panic is logged with the stack trace and the shape of the command document,
+// and InternalError with the correlation ID that is also present in the log is returned instead,
+// so the connection remains usable.
+func (c *conn) recoverHandler(command string, document *types.Document, f func() (wire.MsgBody, error)) (resBody wire.MsgBody, err error) { //nolint:lll // argument list is too long
+	defer func() {
+		p := recover()
+		if p == nil {
+			return
+		}
+
+		b := make([]byte, 8)
+		must.NotFail(rand.Read(b))
+		id := hex.EncodeToString(b)
+
+		c.m.panics.WithLabelValues(commandLabel(command)).Inc()
+
+		c.l.Desugar().Error(
+			"Command handler panicked",
+			zap.String("correlationID", id), zap.String("command", sanitize.Document(document)),
+			zap.Any("panic", p), zap.Stack("stack"),
+		)
+
+		resBody = nil
+		err = common.NewInternalErrorMsg(fmt.Sprintf("Internal error, correlation ID: %s", id))
+	}()
+
+	return f()
+}
+
+// handleOpQuery processes OP_QUERY request.
+//
+// OP_QUERY is supported only for the initial handshake (isMaster / hello on admin.$cmd);
+// any other request gets UnsupportedOpQueryCommand error like with MongoDB 5.1+.
+func (c *conn) handleOpQuery(ctx context.Context, query *wire.OpQuery, cmd string) (*wire.OpReply, error) {
+	if query.FullCollectionName == "admin.$cmd" {
+		switch cmd {
+		case "isMaster", "ismaster", "hello":
+			return c.h.CmdQuery(ctx, query)
+		}
+	}
+
+	var driver string
+	if md := c.connInfo.ClientMetadata; md != nil {
+		driver = strings.TrimSpace(md.Driver.Name + " " + md.Driver.Version)
+	}
+
+	c.l.Info(
+		"Unsupported OP_QUERY command",
+		zap.String("command", cmd), zap.String("collection", query.FullCollectionName),
+		zap.String("driver", driver),
+	)
+
+	errMsg := fmt.Sprintf(
+		"Unsupported OP_QUERY command: %s. The client driver may require an upgrade. "+
+			"For more details see https://dochub.mongodb.org/core/legacy-opcode-removal",
+		cmd,
+	)
+	return nil, common.NewErrorMsg(common.ErrUnsupportedOpQueryCommand, errMsg)
+}
+
 // Describe implements prometheus.Collector.
 func (c *conn) Describe(ch chan<- *prometheus.Desc) {
 	c.m.Describe(ch)
@@ -417,9 +822,14 @@ func (c *conn) Collect(ch chan<- prometheus.Metric) {
 // If there is no errors in the response, it will be logged as a debug.
 // If there is an error in the response, and connection is closed, it will be logged as an error.
 // If there is an error in the response, and connection is not closed, it will be logged as a warning.
+//
+// Unless logPayloads is set, only the shape of the body is logged, without values.
+// Error code and name are added to it for error responses.
 func (c *conn) logResponse(who string, resHeader *wire.MsgHeader, resBody wire.MsgBody, closeConn bool) zapcore.Level {
 	level := zap.DebugLevel
 
+	var errInfo string
+
 	if resHeader.OpCode == wire.OpCodeMsg {
 		doc := must.NotFail(resBody.(*wire.OpMsg).Document())
 
@@ -431,11 +841,51 @@ func (c *conn) logResponse(who string, resHeader *wire.MsgHeader, resBody wire.M
 			} else {
 				level = zap.WarnLevel
 			}
+
+			code, _ := doc.Get("code")
+			codeName, _ := doc.Get("codeName")
+			errInfo = fmt.Sprintf("\nerror: %v %v", code, codeName)
 		}
 	}
 
-	c.l.Desugar().Check(level, fmt.Sprintf("%s header: %s", who, resHeader)).Write()
-	c.l.Desugar().Check(level, fmt.Sprintf("%s message:\n%s\n\n\n", who, resBody)).Write()
+	l := c.l.Desugar()
+
+	if ce := l.Check(level, ""); ce != nil {
+		ce.Message = fmt.Sprintf("%s header: %s", who, resHeader)
+		ce.Write()
+	}
+
+	if ce := l.Check(level, ""); ce != nil {
+		if c.logPayloads {
+			ce.Message = fmt.Sprintf("%s message:\n%s\n\n\n", who, resBody)
+		} else {
+			ce.Message = fmt.Sprintf("%s message:\n%s%s\n\n\n", who, bodyShape(resBody), errInfo)
+		}
+
+		ce.Write()
+	}
 
 	return level
 }
+
+// logRequest logs request's header and body at debug level.
+//
+// Unless logPayloads is set, only the shape of the body is logged, without values.
+func (c *conn) logRequest(reqHeader *wire.MsgHeader, reqBody wire.MsgBody) {
+	l := c.l.Desugar()
+
+	if ce := l.Check(zap.DebugLevel, ""); ce != nil {
+		ce.Message = fmt.Sprintf("Request header: %s", reqHeader)
+		ce.Write()
+	}
+
+	if ce := l.Check(zap.DebugLevel, ""); ce != nil {
+		if c.logPayloads {
+			ce.Message = fmt.Sprintf("Request message:\n%s\n\n\n", reqBody)
+		} else {
+			ce.Message = fmt.Sprintf("Request message:\n%s\n\n\n", bodyShape(reqBody))
+		}
+
+		ce.Write()
+	}
+}
@@ -20,7 +20,11 @@ import "github.com/prometheus/client_golang/prometheus"
 type ConnMetrics struct {
 	requests          *prometheus.CounterVec
 	responses         *prometheus.CounterVec
+	commands          *prometheus.CounterVec
+	commandDuration   *prometheus.HistogramVec
 	aggregationStages *prometheus.CounterVec
+	idleClosed        prometheus.Counter
+	panics            *prometheus.CounterVec
 }
 
 // newConnMetrics creates new conn metrics.
@@ -44,6 +48,25 @@ func newConnMetrics() *ConnMetrics {
 			},
 			[]string{"opcode", "command", "result"},
 		),
+		commands: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "commands_total",
+				Help:      "Total number of handled commands.",
+			},
+			[]string{"command", "result"},
+		),
+		commandDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "command_duration_seconds",
+				Help:      "Command handling duration.",
+				Buckets:   prometheus.DefBuckets,
+			},
+			[]string{"command", "result"},
+		),
 		aggregationStages: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: namespace,
@@ -53,6 +76,23 @@ func newConnMetrics() *ConnMetrics {
 			},
 			[]string{"command", "stage"},
 		),
+		idleClosed: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "idle_closed_total",
+				Help:      "Total number of client connections closed due to the idle timeout.",
+			},
+		),
+		panics: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "panics_total",
+				Help:      "Total number of recovered command handler panics.",
+			},
+			[]string{"command"},
+		),
 	}
 }
 
@@ -60,14 +100,22 @@ func newConnMetrics() *ConnMetrics {
 func (cm *ConnMetrics) Describe(ch chan<- *prometheus.Desc) {
 	cm.requests.Describe(ch)
 	cm.responses.Describe(ch)
+	cm.commands.Describe(ch)
+	cm.commandDuration.Describe(ch)
 	cm.aggregationStages.Describe(ch)
+	cm.idleClosed.Describe(ch)
+	cm.panics.Describe(ch)
 }
 
 // Collect implements prometheus.Collector.
 func (cm *ConnMetrics) Collect(ch chan<- prometheus.Metric) {
 	cm.requests.Collect(ch)
 	cm.responses.Collect(ch)
+	cm.commands.Collect(ch)
+	cm.commandDuration.Collect(ch)
 	cm.aggregationStages.Collect(ch)
+	cm.idleClosed.Collect(ch)
+	cm.panics.Collect(ch)
 }
 
 // check interfaces
@@ -0,0 +1,97 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/dummy"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+func TestCommandMetrics(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	h, err := dummy.New()
+	require.NoError(t, err)
+
+	registry := prometheus.NewRegistry()
+	l := NewListener(&NewListenerOpts{
+		ListenAddr: "127.0.0.1:0",
+		Mode:       NormalMode,
+		Handler:    h,
+		Logger:     zaptest.NewLogger(t),
+		Metrics:    registry,
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.ErrorIs(t, l.Run(ctx), context.Canceled)
+	}()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	require.NoError(t, err)
+
+	bufr := bufio.NewReader(conn)
+	bufw := bufio.NewWriter(conn)
+
+	for i, doc := range []*types.Document{
+		must.NotFail(types.NewDocument("buildInfo", int32(1), "$db", "admin")),
+		must.NotFail(types.NewDocument("buildInfo", int32(1), "$db", "admin")),
+		must.NotFail(types.NewDocument("ping", int32(1), "$db", "admin")),
+		must.NotFail(types.NewDocument("noSuchCommand", int32(1), "$db", "admin")),
+		must.NotFail(types.NewDocument("anotherNoSuchCommand", int32(1), "$db", "admin")),
+	} {
+		header, msg := makeMsg(t, int32(i+1), doc)
+		require.NoError(t, wire.WriteMessage(bufw, header, msg))
+		require.NoError(t, bufw.Flush())
+
+		_, _, err = wire.ReadMessage(bufr)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, conn.Close())
+	cancel()
+	<-done
+
+	expected := `
+		# HELP ferretdb_client_commands_total Total number of handled commands.
+		# TYPE ferretdb_client_commands_total counter
+		ferretdb_client_commands_total{command="buildInfo",result="ok"} 2
+		ferretdb_client_commands_total{command="ping",result="NotImplemented"} 1
+		ferretdb_client_commands_total{command="unknown",result="CommandNotFound"} 2
+	`
+	err = testutil.GatherAndCompare(registry, strings.NewReader(expected), "ferretdb_client_commands_total")
+	assert.NoError(t, err)
+
+	assert.Equal(t, 3, testutil.CollectAndCount(l.metrics.connMetrics.commandDuration))
+	assert.Equal(t, 2.0, testutil.ToFloat64(l.metrics.connMetrics.requests.WithLabelValues("OP_MSG", "unknown")))
+}
@@ -0,0 +1,89 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+func TestExhaustMore(t *testing.T) {
+	t.Parallel()
+
+	msg := func(pairs ...any) *wire.OpMsg {
+		var m wire.OpMsg
+		must.NoError(m.SetSections(wire.OpMsgSection{
+			Documents: []*types.Document{must.NotFail(types.NewDocument(pairs...))},
+		}))
+		return &m
+	}
+
+	ok := msg("ok", float64(1))
+	otherVersion := must.NotFail(types.NewDocument("processId", types.NewObjectID(), "counter", int64(0)))
+
+	for name, tc := range map[string]struct {
+		req      *wire.OpMsg
+		res      *wire.OpMsg
+		expected bool
+	}{
+		"HelloAwaited": {
+			req:      msg("hello", int32(1), "topologyVersion", common.TopologyVersion(), "maxAwaitTimeMS", int64(100)),
+			res:      ok,
+			expected: true,
+		},
+		"HelloOtherProcess": {
+			req: msg("hello", int32(1), "topologyVersion", otherVersion, "maxAwaitTimeMS", int64(100)),
+			res: ok,
+		},
+		"HelloZeroMaxAwaitTimeMS": {
+			req: msg("isMaster", int32(1), "topologyVersion", common.TopologyVersion(), "maxAwaitTimeMS", int64(0)),
+			res: ok,
+		},
+		"HelloNotAwaitable": {
+			req: msg("hello", int32(1)),
+			res: ok,
+		},
+		"HelloError": {
+			req: msg("hello", int32(1), "topologyVersion", common.TopologyVersion(), "maxAwaitTimeMS", int64(100)),
+			res: msg("ok", float64(0)),
+		},
+		"GetMore": {
+			req:      msg("getMore", int64(1)),
+			res:      msg("cursor", must.NotFail(types.NewDocument("id", int64(1))), "ok", float64(1)),
+			expected: true,
+		},
+		"GetMoreExhausted": {
+			req: msg("getMore", int64(1)),
+			res: msg("cursor", must.NotFail(types.NewDocument("id", int64(0))), "ok", float64(1)),
+		},
+		"Find": {
+			req: msg("find", "test"),
+			res: ok,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, exhaustMore(tc.req, tc.res))
+		})
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conninfo
+
+import (
+	"sort"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// Driver represents client driver's name and version from the handshake's client metadata.
+type Driver struct {
+	Name    string
+	Version string
+}
+
+// DriverConnections represents the number of connections of the client driver.
+type DriverConnections struct {
+	Driver
+	Connections int32
+}
+
+// ClientMetadata represents client metadata sent by the driver in the first handshake.
+type ClientMetadata struct {
+	Document *types.Document // as sent by the client; should not be modified
+	AppName  string
+	Driver   Driver
+}
+
+// NewClientMetadata returns client metadata for the document sent by the client.
+func NewClientMetadata(document *types.Document) *ClientMetadata {
+	getString := func(path string) string {
+		v, _ := document.GetByPath(types.NewPathFromString(path))
+		s, _ := v.(string)
+		return s
+	}
+
+	return &ClientMetadata{
+		Document: document,
+		AppName:  getString("application.name"),
+		Driver: Driver{
+			Name:    getString("driver.name"),
+			Version: getString("driver.version"),
+		},
+	}
+}
+
+// AddDriver counts a connection of the given driver.
+func (c *Connections) AddDriver(d Driver) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.drivers[d]++
+}
+
+// RemoveDriver removes a connection of the given driver added by AddDriver.
+func (c *Connections) RemoveDriver(d Driver) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	if c.drivers[d]--; c.drivers[d] <= 0 {
+		delete(c.drivers, d)
+	}
+}
+
+// Drivers returns the numbers of connections per client driver sorted by name and version.
+func (c *Connections) Drivers() []DriverConnections {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	res := make([]DriverConnections, 0, len(c.drivers))
+	for d, n := range c.drivers {
+		res = append(res, DriverConnections{Driver: d, Connections: n})
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Name != res[j].Name {
+			return res[i].Name < res[j].Name
+		}
+
+		return res[i].Version < res[j].Version
+	})
+
+	return res
+}
@@ -21,6 +21,8 @@ import (
 	"net"
 
 	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
 // contextKey is a special type to represent context.WithValue keys a bit more safely.
@@ -30,9 +32,31 @@ type contextKey struct{}
 var connInfoKey = contextKey{}
 
 // ConnInfo represents connection info.
+//
+// The same value is used for all requests of the connection.
+// Requests are handled one by one, so it is not protected by a mutex.
 type ConnInfo struct {
 	PeerAddr          net.Addr
 	AggregationStages *prometheus.CounterVec
+	Commands          *prometheus.CounterVec // handled commands by command and result
+	Connections       *Connections           // listener-wide connection counters
+	SlowOps           *SlowOps               // listener-wide slow operations logging settings
+	ReadOnly          *ReadOnly              // listener-wide read-only mode setting
+
+	// OpStats of the current request; it is reset for each request.
+	OpStats OpStats
+
+	// Compressors negotiated by the last hello / isMaster command, in the client's order of preference.
+	Compressors []wire.CompressorID
+
+	// ClientMetadata sent in the first hello / isMaster command, if any.
+	ClientMetadata *ClientMetadata
+
+	// Auth is the authentication state of the connection.
+	Auth Auth
+
+	// closers are called when the connection is closed, see SetCloser.
+	closers map[any]func()
 }
 
 // WithConnInfo returns a new context with the given ConnInfo.
@@ -58,3 +82,26 @@ func GetConnInfo(ctx context.Context) *ConnInfo {
 
 	return connInfo
 }
+
+// SetCloser sets the function that is called when the connection is closed.
+//
+// Setting closer with the same key again replaces the previous one,
+// so it is safe to call it for each request with the same key.
+func (connInfo *ConnInfo) SetCloser(key any, f func()) {
+	if connInfo.closers == nil {
+		connInfo.closers = make(map[any]func())
+	}
+
+	connInfo.closers[key] = f
+}
+
+// Close calls all functions set with SetCloser.
+//
+// It is called by the connection itself when it is closed.
+func (connInfo *ConnInfo) Close() {
+	for _, f := range connInfo.closers {
+		f()
+	}
+
+	connInfo.closers = nil
+}
@@ -74,3 +74,22 @@ func TestConnInfo(t *testing.T) {
 		})
 	}
 }
+
+func TestConnInfoClose(t *testing.T) {
+	t.Parallel()
+
+	var connInfo ConnInfo
+
+	var a, b int
+	connInfo.SetCloser("a", func() { a++ })
+	connInfo.SetCloser("a", func() { a += 10 })
+	connInfo.SetCloser("b", func() { b++ })
+
+	connInfo.Close()
+	assert.Equal(t, 10, a)
+	assert.Equal(t, 1, b)
+
+	connInfo.Close()
+	assert.Equal(t, 10, a)
+	assert.Equal(t, 1, b)
+}
@@ -0,0 +1,104 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conninfo
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+)
+
+// Connections represents listener-wide connection counters, client drivers, and current operations.
+//
+// The same value is shared by all connections of the listener; it is safe for concurrent use.
+type Connections struct {
+	max          int32 // zero means no limit
+	current      int32 // accessed atomically
+	totalCreated int64 // accessed atomically
+	rejected     int64 // accessed atomically
+
+	m        sync.Mutex
+	drivers  map[Driver]int32
+	ops      map[int64]*Op
+	lastOpID int64
+	conns    map[string]*ConnState
+}
+
+// ConnectionsStats represents a snapshot of Connections counters.
+type ConnectionsStats struct {
+	Current      int32
+	Available    int32
+	TotalCreated int64
+	Rejected     int64
+}
+
+// NewConnections returns new connection counters with the given limit; zero means no limit.
+func NewConnections(max int32) *Connections {
+	return &Connections{
+		max:     max,
+		drivers: make(map[Driver]int32),
+		ops:     make(map[int64]*Op),
+		conns:   make(map[string]*ConnState),
+	}
+}
+
+// TryAdd adds a connection if the limit is not reached yet and returns true.
+// Otherwise, it counts a rejected connection and returns false.
+func (c *Connections) TryAdd() bool {
+	for {
+		current := atomic.LoadInt32(&c.current)
+		if c.max > 0 && current >= c.max {
+			atomic.AddInt64(&c.rejected, 1)
+			return false
+		}
+
+		if atomic.CompareAndSwapInt32(&c.current, current, current+1) {
+			atomic.AddInt64(&c.totalCreated, 1)
+			return true
+		}
+	}
+}
+
+// Remove removes a connection added by TryAdd.
+func (c *Connections) Remove() {
+	atomic.AddInt32(&c.current, -1)
+}
+
+// Max returns the connection limit; zero means no limit.
+func (c *Connections) Max() int32 {
+	return c.max
+}
+
+// Stats returns a snapshot of counters.
+func (c *Connections) Stats() ConnectionsStats {
+	current := atomic.LoadInt32(&c.current)
+
+	max := c.max
+	if max == 0 {
+		max = math.MaxInt32
+	}
+
+	available := max - current
+	if available < 0 {
+		available = 0
+	}
+
+	return ConnectionsStats{
+		Current:      current,
+		Available:    available,
+		TotalCreated: atomic.LoadInt64(&c.totalCreated),
+		Rejected:     atomic.LoadInt64(&c.rejected),
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conninfo
+
+import (
+	"sort"
+	"time"
+)
+
+// ConnState represents a snapshot of the client connection state.
+type ConnState struct {
+	ID          string
+	Client      string // peer address
+	AppName     string // empty if not reported by the client
+	Started     time.Time
+	LastRequest time.Time // zero if there were no requests yet
+}
+
+// AddConn registers a new client connection with the given ID.
+func (c *Connections) AddConn(id, client string, started time.Time) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.conns[id] = &ConnState{
+		ID:      id,
+		Client:  client,
+		Started: started,
+	}
+}
+
+// RemoveConn unregisters the connection registered by AddConn.
+func (c *Connections) RemoveConn(id string) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	delete(c.conns, id)
+}
+
+// ConnRequest records the time of the last request for the given connection.
+func (c *Connections) ConnRequest(id string, t time.Time) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	if s := c.conns[id]; s != nil {
+		s.LastRequest = t
+	}
+}
+
+// SetConnAppName sets the application name reported by the client for the given connection.
+func (c *Connections) SetConnAppName(id, appName string) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	if s := c.conns[id]; s != nil {
+		s.AppName = appName
+	}
+}
+
+// Conns returns snapshots of all registered connections sorted by start time.
+func (c *Connections) Conns() []ConnState {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	res := make([]ConnState, 0, len(c.conns))
+	for _, s := range c.conns {
+		res = append(res, *s)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if !res[i].Started.Equal(res[j].Started) {
+			return res[i].Started.Before(res[j].Started)
+		}
+		return res[i].ID < res[j].ID
+	})
+
+	return res
+}
@@ -0,0 +1,66 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conninfo
+
+import (
+	"sort"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// Op represents an operation in progress.
+//
+// Its fields are not modified after StartOp call.
+type Op struct {
+	ID             int64
+	Client         string          // peer address
+	ClientMetadata *ClientMetadata // may be nil
+	Command        *types.Document // request document; should not be modified
+	Start          time.Time
+}
+
+// StartOp registers an operation in progress and sets its ID.
+func (c *Connections) StartOp(op *Op) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.lastOpID++
+	op.ID = c.lastOpID
+	c.ops[op.ID] = op
+}
+
+// FinishOp unregisters the operation registered by StartOp.
+func (c *Connections) FinishOp(op *Op) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	delete(c.ops, op.ID)
+}
+
+// Ops returns operations in progress sorted by ID.
+func (c *Connections) Ops() []*Op {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	res := make([]*Op, 0, len(c.ops))
+	for _, op := range c.ops {
+		res = append(res, op)
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+
+	return res
+}
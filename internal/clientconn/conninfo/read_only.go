@@ -0,0 +1,47 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conninfo
+
+import "sync/atomic"
+
+// ReadOnly represents listener-wide read-only mode setting.
+//
+// The same value is shared by all connections of the listener; it is safe for concurrent use.
+type ReadOnly struct {
+	enabled uint32 // accessed atomically
+}
+
+// NewReadOnly returns new read-only mode setting.
+func NewReadOnly(enabled bool) *ReadOnly {
+	r := new(ReadOnly)
+	r.Set(enabled)
+
+	return r
+}
+
+// Enabled returns true if write commands are rejected.
+func (r *ReadOnly) Enabled() bool {
+	return atomic.LoadUint32(&r.enabled) == 1
+}
+
+// Set enables or disables read-only mode.
+func (r *ReadOnly) Set(enabled bool) {
+	var v uint32
+	if enabled {
+		v = 1
+	}
+
+	atomic.StoreUint32(&r.enabled, v)
+}
@@ -0,0 +1,65 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package conninfo
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// SlowOps represents listener-wide slow operations logging settings.
+//
+// The same value is shared by all connections of the listener; it is safe for concurrent use.
+type SlowOps struct {
+	slowMS     int64  // accessed atomically
+	sampleRate uint64 // float64 bits; accessed atomically
+}
+
+// NewSlowOps returns new slow operations logging settings.
+func NewSlowOps(slowMS int64, sampleRate float64) *SlowOps {
+	s := new(SlowOps)
+	s.SetSlowMS(slowMS)
+	s.SetSampleRate(sampleRate)
+
+	return s
+}
+
+// SlowMS returns the threshold in milliseconds above which operations are logged as slow.
+func (s *SlowOps) SlowMS() int64 {
+	return atomic.LoadInt64(&s.slowMS)
+}
+
+// SetSlowMS sets the threshold in milliseconds above which operations are logged as slow.
+func (s *SlowOps) SetSlowMS(slowMS int64) {
+	atomic.StoreInt64(&s.slowMS, slowMS)
+}
+
+// SampleRate returns the fraction of operations below the threshold that are also logged.
+func (s *SlowOps) SampleRate() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&s.sampleRate))
+}
+
+// SetSampleRate sets the fraction of operations below the threshold that are also logged.
+func (s *SlowOps) SetSampleRate(sampleRate float64) {
+	atomic.StoreUint64(&s.sampleRate, math.Float64bits(sampleRate))
+}
+
+// OpStats represents statistics of the current operation reported by the handler, if it can.
+type OpStats struct {
+	Reported     bool  // true if the handler reported statistics
+	DocsExamined int64 // documents fetched from the backend
+	DocsReturned int64 // documents returned or affected
+	Pushdown     bool  // true if any part of the query was executed by the backend
+}
@@ -0,0 +1,227 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/fjson"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// diffIgnoredPaths contains paths of response fields that are expected to differ
+// between FerretDB and proxy responses, by command.
+// Paths for the empty command are ignored for all commands.
+//
+// Path element "*" matches any field or array index.
+var diffIgnoredPaths = map[string][]string{
+	"": {
+		"localTime", "connectionId", "topologyVersion",
+		"operationTime", "$clusterTime", "electionId", "lastWrite",
+	},
+	"find":            {"cursor.id"},
+	"getMore":         {"cursor.id"},
+	"aggregate":       {"cursor.id"},
+	"listCollections": {"cursor.id"},
+	"listIndexes":     {"cursor.id"},
+	"update":          {"upserted.*._id"},
+	"findAndModify":   {"lastErrorObject.upserted"},
+	"serverStatus":    {"host", "pid", "uptime", "uptimeMillis", "uptimeEstimate", "metrics", "catalogStats"},
+	"hostInfo":        {"system", "os", "extra"},
+	"buildInfo":       {"gitVersion", "buildEnvironment", "ferretdbVersion", "ferretdbFeatures"},
+	"whatsmyuri":      {"you"},
+}
+
+// ParseDiffIgnoredPaths parses a comma-separated list of additional paths to ignore in diff modes.
+//
+// Each element is either a path ignored for all commands, or a path prefixed with command name and colon,
+// for example, "localTime,find:cursor.id".
+func ParseDiffIgnoredPaths(s string) map[string][]string {
+	res := make(map[string][]string)
+
+	for _, e := range strings.Split(s, ",") {
+		if e = strings.TrimSpace(e); e == "" {
+			continue
+		}
+
+		var command string
+		if i := strings.Index(e, ":"); i >= 0 {
+			command, e = e[:i], e[i+1:]
+		}
+
+		res[command] = append(res[command], e)
+	}
+
+	return res
+}
+
+// diffIgnored returns ignored paths for the given command, including extra ones.
+func diffIgnored(command string, extra map[string][]string) [][]string {
+	var res [][]string
+
+	for _, m := range []map[string][]string{diffIgnoredPaths, extra} {
+		for _, c := range []string{"", command} {
+			for _, p := range m[c] {
+				res = append(res, strings.Split(p, "."))
+			}
+
+			if command == "" {
+				break
+			}
+		}
+	}
+
+	return res
+}
+
+// requestCommand returns the command name of the request, or an empty string.
+func requestCommand(body wire.MsgBody) string {
+	switch body := body.(type) {
+	case *wire.OpMsg:
+		doc, err := body.Document()
+		if err != nil {
+			return ""
+		}
+
+		return doc.Command()
+
+	case *wire.OpQuery:
+		if body.Query == nil {
+			return ""
+		}
+
+		return body.Query.Command()
+
+	default:
+		return ""
+	}
+}
+
+// diffHeader returns the string representation of the header for diffing.
+//
+// Request IDs are always different, so they are not included.
+func diffHeader(header *wire.MsgHeader) string {
+	if header == nil {
+		return "<nil>"
+	}
+
+	return fmt.Sprintf("length: %d\nresponse_to: %d\nopcode: %s\n", header.MessageLength, header.ResponseTo, header.OpCode)
+}
+
+// diffBody returns the string representation of the response body to the given command for diffing.
+//
+// It contains one line per field with full field path and value;
+// values of ignored paths (volatile fields) are replaced with a placeholder.
+func diffBody(command string, body wire.MsgBody, extra map[string][]string) string {
+	ignored := diffIgnored(command, extra)
+
+	var lines []string
+	switch body := body.(type) {
+	case *wire.OpMsg:
+		lines = append(lines, "FlagBits: "+body.FlagBits.String())
+
+		doc, err := body.Document()
+		if err != nil {
+			return body.String()
+		}
+
+		diffLines(nil, doc, ignored, &lines)
+
+	case *wire.OpReply:
+		lines = append(lines, "ResponseFlags: "+body.ResponseFlags.String())
+
+		for i, doc := range body.Documents {
+			diffLines([]string{"Documents", strconv.Itoa(i)}, doc, ignored, &lines)
+		}
+
+	default:
+		return fmt.Sprintf("%s\n", body)
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// diffLines appends lines for the value with the given path to lines.
+func diffLines(path []string, v any, ignored [][]string, lines *[]string) {
+	p := strings.Join(path, ".")
+
+	if len(path) > 0 && pathIgnored(path, ignored) {
+		*lines = append(*lines, p+": <ignored>")
+		return
+	}
+
+	// copy to avoid sharing the underlying array between siblings
+	child := func(elem string) []string {
+		return append(append(make([]string, 0, len(path)+1), path...), elem)
+	}
+
+	switch v := v.(type) {
+	case *types.Document:
+		if v.Len() == 0 {
+			*lines = append(*lines, p+": {}")
+			return
+		}
+
+		for _, k := range v.Keys() {
+			diffLines(child(k), must.NotFail(v.Get(k)), ignored, lines)
+		}
+
+	case *types.Array:
+		if v.Len() == 0 {
+			*lines = append(*lines, p+": []")
+			return
+		}
+
+		for i := 0; i < v.Len(); i++ {
+			diffLines(child(strconv.Itoa(i)), must.NotFail(v.Get(i)), ignored, lines)
+		}
+
+	default:
+		b, err := fjson.Marshal(v)
+		if err != nil {
+			*lines = append(*lines, fmt.Sprintf("%s: <%v>", p, err))
+			return
+		}
+
+		*lines = append(*lines, p+": "+string(b))
+	}
+}
+
+// pathIgnored returns true if the path matches one of ignored paths.
+func pathIgnored(path []string, ignored [][]string) bool {
+	for _, ip := range ignored {
+		if len(ip) != len(path) {
+			continue
+		}
+
+		matched := true
+		for i, e := range ip {
+			if e != "*" && e != path[i] {
+				matched = false
+				break
+			}
+		}
+
+		if matched {
+			return true
+		}
+	}
+
+	return false
+}
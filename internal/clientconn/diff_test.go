@@ -0,0 +1,88 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestParseDiffIgnoredPaths(t *testing.T) {
+	t.Parallel()
+
+	expected := map[string][]string{
+		"":     {"a.b", "c"},
+		"find": {"cursor.id"},
+	}
+	assert.Equal(t, expected, ParseDiffIgnoredPaths(" a.b, find:cursor.id,,c"))
+	assert.Empty(t, ParseDiffIgnoredPaths(""))
+}
+
+func TestDiffBody(t *testing.T) {
+	t.Parallel()
+
+	makeRes := func(cursorID int64, localTime time.Time) *types.Document {
+		return must.NotFail(types.NewDocument(
+			"cursor", must.NotFail(types.NewDocument(
+				"firstBatch", must.NotFail(types.NewArray(
+					must.NotFail(types.NewDocument("_id", int32(1), "v", "foo")),
+				)),
+				"id", cursorID,
+				"ns", "db.test",
+			)),
+			"localTime", localTime,
+			"ok", float64(1),
+		))
+	}
+
+	_, res1 := makeMsg(t, 1, makeRes(1, time.Unix(1, 0)))
+	_, res2 := makeMsg(t, 2, makeRes(2, time.Unix(2, 0)))
+
+	t.Run("Find", func(t *testing.T) {
+		t.Parallel()
+
+		expected := "FlagBits: []\n" +
+			"cursor.firstBatch.0._id: 1\n" +
+			`cursor.firstBatch.0.v: "foo"` + "\n" +
+			"cursor.id: <ignored>\n" +
+			`cursor.ns: "db.test"` + "\n" +
+			"localTime: <ignored>\n" +
+			`ok: {"$f":1}` + "\n"
+
+		actual := diffBody("find", res1, nil)
+		assert.Equal(t, expected, actual)
+		assert.Equal(t, actual, diffBody("find", res2, nil))
+	})
+
+	t.Run("OtherCommand", func(t *testing.T) {
+		t.Parallel()
+
+		assert.NotEqual(t, diffBody("insert", res1, nil), diffBody("insert", res2, nil))
+	})
+
+	t.Run("Extra", func(t *testing.T) {
+		t.Parallel()
+
+		extra := ParseDiffIgnoredPaths("insert:cursor.id,*.firstBatch.*.v")
+		actual := diffBody("insert", res1, extra)
+		assert.Equal(t, actual, diffBody("insert", res2, extra))
+		assert.Contains(t, actual, "cursor.firstBatch.0.v: <ignored>\n")
+	})
+}
@@ -0,0 +1,79 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// routeUntilDisconnect calls route with the context that is canceled if the client disconnects
+// before the request is handled, so the handler does not waste backend resources.
+//
+// It waits for the client's disconnect by peeking at the next request;
+// bufr should not be used concurrently.
+//
+// Requests that are not replied to (with moreToCome flag) are not canceled,
+// as clients could send them just before closing the connection.
+func (c *conn) routeUntilDisconnect(ctx context.Context, bufr *bufio.Reader, reqHeader *wire.MsgHeader, reqBody wire.MsgBody) (*wire.MsgHeader, wire.MsgBody, bool) { //nolint:lll // argument list is too long
+	if msg, ok := reqBody.(*wire.OpMsg); ok && msg.FlagBits.FlagSet(wire.OpMsgMoreToCome) {
+		return c.route(ctx, reqHeader, reqBody)
+	}
+
+	reqCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	watchDone := make(chan struct{})
+	go func() {
+		defer close(watchDone)
+
+		// next pipelined request or idle timeout are not disconnects
+		_, err := bufr.Peek(1)
+
+		var netErr net.Error
+		if err == nil || (errors.As(err, &netErr) && netErr.Timeout()) {
+			return
+		}
+
+		c.l.Infof("Client disconnected, canceling request: %s", err)
+		cancel()
+	}()
+
+	resHeader, resBody, closeConn := c.route(reqCtx, reqHeader, reqBody)
+
+	// unblock Peek above and wait for it to return
+	if err := c.netConn.SetReadDeadline(time.Unix(0, 0)); err != nil {
+		c.l.Warnf("Failed to set deadline: %s", err)
+	}
+	<-watchDone
+
+	if err := c.netConn.SetReadDeadline(time.Time{}); err != nil {
+		c.l.Warnf("Failed to set deadline: %s", err)
+	}
+
+	// restore deadline set on ctx cancellation, see run
+	if ctx.Err() != nil {
+		if err := c.netConn.SetReadDeadline(time.Unix(0, 0)); err != nil {
+			c.l.Warnf("Failed to set deadline: %s", err)
+		}
+	}
+
+	return resHeader, resBody, closeConn
+}
@@ -0,0 +1,162 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/handlers"
+	"github.com/FerretDB/FerretDB/internal/handlers/dummy"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// blockingHandler is a dummy handler with ping command that blocks until canceled
+// or for the given time.
+type blockingHandler struct {
+	handlers.Interface
+	block    time.Duration
+	started  chan struct{}
+	canceled chan struct{}
+}
+
+// MsgPing implements handlers.Interface.
+func (h *blockingHandler) MsgPing(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	h.started <- struct{}{}
+
+	select {
+	case <-time.After(h.block):
+	case <-ctx.Done():
+		close(h.canceled)
+		return nil, ctx.Err()
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument("ok", float64(1)))},
+	}))
+
+	return &reply, nil
+}
+
+func TestDisconnectCancel(t *testing.T) {
+	t.Parallel()
+
+	ping := must.NotFail(types.NewDocument("ping", int32(1), "$db", "admin"))
+
+	setup := func(t *testing.T, block time.Duration) (*Listener, *blockingHandler) {
+		t.Helper()
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		dh, err := dummy.New()
+		require.NoError(t, err)
+
+		h := &blockingHandler{
+			Interface: dh,
+			block:     block,
+			started:   make(chan struct{}, 2),
+			canceled:  make(chan struct{}),
+		}
+
+		l := NewListener(&NewListenerOpts{
+			ListenAddr: "127.0.0.1:0",
+			Mode:       NormalMode,
+			Handler:    h,
+			Logger:     zaptest.NewLogger(t),
+			Metrics:    prometheus.NewRegistry(),
+		})
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			assert.ErrorIs(t, l.Run(ctx), context.Canceled)
+		}()
+
+		t.Cleanup(func() {
+			cancel()
+			<-done
+		})
+
+		return l, h
+	}
+
+	t.Run("Disconnect", func(t *testing.T) {
+		t.Parallel()
+
+		l, h := setup(t, time.Hour)
+
+		conn, err := net.Dial("tcp", l.Addr().String())
+		require.NoError(t, err)
+
+		header, msg := makeMsg(t, 1, ping)
+		bufw := bufio.NewWriter(conn)
+		require.NoError(t, wire.WriteMessage(bufw, header, msg))
+		require.NoError(t, bufw.Flush())
+
+		<-h.started
+		require.NoError(t, conn.Close())
+
+		select {
+		case <-h.canceled:
+		case <-time.After(5 * time.Second):
+			t.Fatal("request was not canceled")
+		}
+	})
+
+	t.Run("Pipelined", func(t *testing.T) {
+		t.Parallel()
+
+		l, h := setup(t, 100*time.Millisecond)
+
+		conn, err := net.Dial("tcp", l.Addr().String())
+		require.NoError(t, err)
+		defer conn.Close()
+
+		// the second request arrives while the first one is handled
+		bufw := bufio.NewWriter(conn)
+		for i := int32(1); i <= 2; i++ {
+			header, msg := makeMsg(t, i, ping)
+			require.NoError(t, wire.WriteMessage(bufw, header, msg))
+		}
+		require.NoError(t, bufw.Flush())
+
+		bufr := bufio.NewReader(conn)
+		for i := int32(1); i <= 2; i++ {
+			resHeader, resBody, err := wire.ReadMessage(bufr)
+			require.NoError(t, err)
+			assert.Equal(t, i, resHeader.ResponseTo)
+
+			doc := must.NotFail(resBody.(*wire.OpMsg).Document())
+			assert.Equal(t, float64(1), must.NotFail(doc.Get("ok")))
+		}
+
+		select {
+		case <-h.canceled:
+			t.Fatal("request was canceled")
+		default:
+		}
+	})
+}
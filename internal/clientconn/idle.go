@@ -0,0 +1,67 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+)
+
+// errIdleTimeout is returned by conn.run when the connection is closed due to the idle timeout.
+var errIdleTimeout = errors.New("idle timeout")
+
+// setIdleDeadline sets the read deadline for the next request if the idle timeout is configured.
+//
+// The deadline is set before each read, so any received request (including heartbeats)
+// and any sent reply (including streamed exhaust replies) count as activity.
+func (c *conn) setIdleDeadline(ctx context.Context) error {
+	if c.idleTimeout <= 0 {
+		return nil
+	}
+
+	if err := c.netConn.SetReadDeadline(time.Now().Add(c.idleTimeout)); err != nil {
+		return err
+	}
+
+	// the new deadline could overwrite the one set on ctx cancellation
+	return ctx.Err()
+}
+
+// checkIdle returns errIdleTimeout and logs the closure if the read error was caused by the idle timeout;
+// otherwise, it returns the given error.
+func (c *conn) checkIdle(ctx context.Context, err error) error {
+	if c.idleTimeout <= 0 || ctx.Err() != nil {
+		return err
+	}
+
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		return err
+	}
+
+	c.m.idleClosed.Inc()
+
+	// appName is already included by the logger
+	fields := []any{"idle", c.idleTimeout}
+	if md := c.connInfo.ClientMetadata; md != nil {
+		fields = append(fields, "driver", md.Driver.Name, "driverVersion", md.Driver.Version)
+	}
+
+	c.l.Infow("Closing idle connection", fields...)
+
+	return errIdleTimeout
+}
@@ -0,0 +1,87 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"context"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/dummy"
+)
+
+func TestIdleTimeout(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	h, err := dummy.New()
+	require.NoError(t, err)
+
+	const idleTimeout = 300 * time.Millisecond
+
+	l := NewListener(&NewListenerOpts{
+		ListenAddr:  "127.0.0.1:0",
+		IdleTimeout: idleTimeout,
+		Mode:        NormalMode,
+		Handler:     h,
+		Logger:      zaptest.NewLogger(t),
+		Metrics:     prometheus.NewRegistry(),
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.ErrorIs(t, l.Run(ctx), context.Canceled)
+	}()
+
+	idleConn, err := net.Dial("tcp", l.Addr().String())
+	require.NoError(t, err)
+	defer idleConn.Close()
+
+	activeConn, err := net.Dial("tcp", l.Addr().String())
+	require.NoError(t, err)
+	defer activeConn.Close()
+
+	testBuildInfo(t, idleConn)
+
+	// keep sending requests more often than the timeout
+	for start := time.Now(); time.Since(start) < 3*idleTimeout; {
+		testBuildInfo(t, activeConn)
+		time.Sleep(idleTimeout / 5)
+	}
+
+	// idle connection is closed by the server
+	require.NoError(t, idleConn.SetReadDeadline(time.Now().Add(10*time.Second)))
+	_, err = idleConn.Read(make([]byte, 1))
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, os.ErrDeadlineExceeded)
+
+	testBuildInfo(t, activeConn)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(l.metrics.connMetrics.idleClosed))
+
+	cancel()
+	<-done
+}
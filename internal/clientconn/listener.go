@@ -15,51 +15,139 @@
 package clientconn
 
 import (
+	"bufio"
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
 	"net"
+	"os"
 	"runtime/pprof"
 	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
 	"github.com/FerretDB/FerretDB/internal/handlers"
-	"github.com/FerretDB/FerretDB/internal/util/ctxutil"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
 // Listener accepts incoming client connections.
 type Listener struct {
-	opts      *NewListenerOpts
-	metrics   *ListenerMetrics
-	handler   handlers.Interface
-	listener  net.Listener
-	listening chan struct{}
+	opts         *NewListenerOpts
+	metrics      *ListenerMetrics
+	handler      handlers.Interface
+	recorder     *recorder
+	listener     net.Listener
+	tlsListener  net.Listener
+	unixListener net.Listener
+	inFlight     *inFlight
+	connections  *conninfo.Connections
+	slowOps      *conninfo.SlowOps
+	readOnly     *conninfo.ReadOnly
+	accessLog    *accessLog
+	listening    chan struct{}
+	tracer       trace.Tracer // nil if tracing is disabled
 }
 
 // NewListenerOpts represents listener configuration.
 type NewListenerOpts struct {
 	ListenAddr         string
 	ProxyAddr          string
+	DiffIgnoredPaths   map[string][]string // additional paths ignored in diff modes, by command
 	Mode               Mode
 	Handler            handlers.Interface
-	Logger             *zap.Logger
+	Logger             *zap.Logger           // nil means zap.L()
+	Metrics            prometheus.Registerer // listener metrics are registered there; nil means prometheus.DefaultRegisterer
+	TracerProvider     trace.TracerProvider  // nil disables tracing
+	LogPayloads        bool                  // log full request and response bodies at debug level; for development only
+	TLS                string                // TLS listen address; empty value disables TLS
+	TLSCertFile        string
+	TLSKeyFile         string
+	TLSCAFile          string // if set, client certificates are required and verified
+	Unix               string // Unix domain socket path; empty value disables it
+	UnixPerm           os.FileMode
+	MaxConnections     int32         // zero means no limit
+	RequireAuth        bool          // reject most commands on unauthenticated connections
+	ReadOnly           bool          // reject write commands; can be changed at runtime with setParameter
+	IdleTimeout        time.Duration // close connections without requests for that long; zero means no timeout
+	AccessLog          io.Writer     // JSON lines access log destination; nil disables it
+	SlowMS             int64         // slow operations threshold; zero means defaultSlowMS, negative disables
+	SlowOpSampleRate   float64       // fraction of faster operations that are also logged
+	RecordDir          string        // empty value disables wire traffic recording
+	RecordMaxSize      int64         // maximum total size of recorded messages in bytes
+	ShutdownTimeout    time.Duration // grace period for in-flight commands; zero means defaultShutdownTimeout
 	TestConnTimeout    time.Duration
-	TestRunCancelDelay time.Duration
+	TestRunCancelDelay time.Duration // additional time given to clients to disconnect after shutdown
 }
 
+// defaultShutdownTimeout is the default grace period for in-flight commands on shutdown.
+const defaultShutdownTimeout = 15 * time.Second
+
+// rejectTimeout is the time given to a rejected client to send the first request and read the reply.
+const rejectTimeout = 5 * time.Second
+
 // NewListener returns a new listener, configured by the NewListenerOpts argument.
+//
+// Listener metrics are registered in opts.Metrics.
+// It panics if they are already registered there by another listener.
 func NewListener(opts *NewListenerOpts) *Listener {
-	return &Listener{
-		opts:      opts,
-		metrics:   newListenerMetrics(),
-		handler:   opts.Handler,
-		listening: make(chan struct{}),
+	o := *opts
+	opts = &o
+
+	if opts.Logger == nil {
+		opts.Logger = zap.L()
+	}
+
+	if opts.Metrics == nil {
+		opts.Metrics = prometheus.DefaultRegisterer
+	}
+
+	var r *recorder
+	if opts.RecordDir != "" {
+		r = newRecorder(opts.RecordDir, opts.RecordMaxSize, opts.Logger.Named("recorder"))
+	}
+
+	connections := conninfo.NewConnections(opts.MaxConnections)
+
+	slowMS := opts.SlowMS
+	if slowMS == 0 {
+		slowMS = defaultSlowMS
 	}
+
+	var al *accessLog
+	if opts.AccessLog != nil {
+		al = newAccessLog(opts.AccessLog, accessLogBufferSize, opts.Logger.Named("accesslog"))
+	}
+
+	l := &Listener{
+		opts:        opts,
+		metrics:     newListenerMetrics(connections, al),
+		handler:     opts.Handler,
+		recorder:    r,
+		inFlight:    new(inFlight),
+		connections: connections,
+		slowOps:     conninfo.NewSlowOps(slowMS, opts.SlowOpSampleRate),
+		readOnly:    conninfo.NewReadOnly(opts.ReadOnly),
+		accessLog:   al,
+		listening:   make(chan struct{}),
+	}
+
+	if opts.TracerProvider != nil {
+		l.tracer = opts.TracerProvider.Tracer(tracerName)
+	}
+
+	opts.Metrics.MustRegister(l)
+
+	return l
 }
 
 // Run runs the listener until ctx is done or some unrecoverable error occurs.
@@ -68,23 +156,184 @@ func NewListener(opts *NewListenerOpts) *Listener {
 func (l *Listener) Run(ctx context.Context) error {
 	logger := l.opts.Logger.Named("listener")
 
-	var err error
-	if l.listener, err = net.Listen("tcp", l.opts.ListenAddr); err != nil {
-		return lazyerrors.Error(err)
-	}
+	tlsConfig, err := l.listen(logger)
 
+	// unblock Addr methods even if listening failed
 	close(l.listening)
-	logger.Sugar().Infof("Listening on %s ...", l.Addr())
+
+	if err != nil {
+		return err
+	}
+
+	if l.listener == nil && l.tlsListener == nil && l.unixListener == nil {
+		return lazyerrors.New("no listen address")
+	}
+
+	// connections are canceled only after graceful shutdown
+	connCtx, connCancel := context.WithCancel(context.Background())
+	defer connCancel()
+
+	stopped := make(chan struct{})
+
+	// all entries are written after all connections are done
+	if l.accessLog != nil {
+		go l.accessLog.run()
+		defer l.accessLog.close()
+	}
 
 	// handle ctx cancellation
+	shutdownDone := make(chan struct{})
 	go func() {
+		defer close(shutdownDone)
+
 		<-ctx.Done()
-		l.listener.Close()
+
+		// stop accepting new connections first
+
+		if l.listener != nil {
+			l.listener.Close()
+		}
+
+		if l.tlsListener != nil {
+			l.tlsListener.Close()
+		}
+
+		// the socket file is removed on close
+		if l.unixListener != nil {
+			l.unixListener.Close()
+		}
+
+		l.shutdown(logger, stopped)
+		connCancel()
 	}()
 
+	var wg sync.WaitGroup
+
+	if l.listener != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.accept(ctx, connCtx, l.listener, nil, logger)
+		}()
+	}
+
+	if l.tlsListener != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.accept(ctx, connCtx, l.tlsListener, tlsConfig, logger)
+		}()
+	}
+
+	if l.unixListener != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.accept(ctx, connCtx, l.unixListener, nil, logger)
+		}()
+	}
+
+	wg.Wait()
+
+	// accept returns only after ctx is done;
+	// wait for the goroutine above, so nothing is logged after Run returns
+	close(stopped)
+	<-shutdownDone
+
+	return ctx.Err()
+}
+
+// listen starts listening on all configured addresses.
+//
+// It returns TLS configuration to use for TLS connections, if any.
+// On error, already started listeners are closed.
+func (l *Listener) listen(logger *zap.Logger) (*tls.Config, error) {
+	var tlsConfig *tls.Config
+	if l.opts.TLS != "" {
+		var err error
+		if tlsConfig, err = newTLSConfig(l.opts.TLSCertFile, l.opts.TLSKeyFile, l.opts.TLSCAFile); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	if l.opts.ListenAddr != "" {
+		var err error
+		if l.listener, err = net.Listen("tcp", l.opts.ListenAddr); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		logger.Sugar().Infof("Listening on %s ...", l.listener.Addr())
+	}
+
+	if tlsConfig != nil {
+		var err error
+		if l.tlsListener, err = net.Listen("tcp", l.opts.TLS); err != nil {
+			if l.listener != nil {
+				l.listener.Close()
+			}
+			return nil, lazyerrors.Error(err)
+		}
+
+		logger.Sugar().Infof("Listening on %s (TLS) ...", l.tlsListener.Addr())
+	}
+
+	if l.opts.Unix != "" {
+		var err error
+		if l.unixListener, err = listenUnix(l.opts.Unix, l.opts.UnixPerm); err != nil {
+			if l.listener != nil {
+				l.listener.Close()
+			}
+			if l.tlsListener != nil {
+				l.tlsListener.Close()
+			}
+			return nil, lazyerrors.Error(err)
+		}
+
+		logger.Sugar().Infof("Listening on %s ...", l.unixListener.Addr())
+	}
+
+	return tlsConfig, nil
+}
+
+// shutdown waits for in-flight commands to complete, rejecting new ones,
+// for up to the shutdown timeout, and then for the test delay, if any.
+//
+// It returns early if stopped is closed.
+func (l *Listener) shutdown(logger *zap.Logger, stopped <-chan struct{}) {
+	timeout := l.opts.ShutdownTimeout
+	if timeout == 0 {
+		timeout = defaultShutdownTimeout
+	}
+
+	logger.Info("Waiting for in-flight commands to complete...", zap.Duration("timeout", timeout))
+
+	if !l.inFlight.shutdown(timeout) {
+		logger.Warn("Shutdown timeout exceeded, canceling in-flight commands")
+		return
+	}
+
+	if l.opts.TestRunCancelDelay == 0 {
+		return
+	}
+
+	t := time.NewTimer(l.opts.TestRunCancelDelay)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+	case <-stopped:
+	}
+}
+
+// accept accepts connections on the given listener until ctx is done,
+// and runs them until connCtx is done or they are closed by clients.
+// If tlsConfig is not nil, connections are wrapped with TLS.
+//
+// When this method returns, all accepted connections are closed.
+func (l *Listener) accept(ctx, connCtx context.Context, listener net.Listener, tlsConfig *tls.Config, logger *zap.Logger) {
 	var wg sync.WaitGroup
 	for {
-		netConn, err := l.listener.Accept()
+		netConn, err := listener.Accept()
 		if err != nil {
 			l.metrics.accepts.WithLabelValues("1").Inc()
 
@@ -101,18 +350,27 @@ func (l *Listener) Run(ctx context.Context) error {
 
 		wg.Add(1)
 		l.metrics.accepts.WithLabelValues("0").Inc()
-		l.metrics.connectedClients.Inc()
+
+		// reply to the first request with a clear error instead of just closing the connection
+		if !l.connections.TryAdd() {
+			go func() {
+				defer wg.Done()
+				l.reject(connCtx, netConn, tlsConfig, logger)
+			}()
+
+			continue
+		}
 
 		// run connection
 		go func() {
-			connID := fmt.Sprintf("%s -> %s", netConn.RemoteAddr(), netConn.LocalAddr())
-
-			// give clients a few seconds to disconnect after ctx is canceled
-			runCancelDelay := l.opts.TestRunCancelDelay
-			if runCancelDelay == 0 {
-				runCancelDelay = 3 * time.Second
+			// remote address of Unix domain socket connection is usually empty
+			remoteAddr := netConn.RemoteAddr().String()
+			if remoteAddr == "" {
+				remoteAddr = "unix"
 			}
-			runCtx, runCancel := ctxutil.WithDelay(ctx.Done(), runCancelDelay)
+			connID := fmt.Sprintf("%s -> %s", remoteAddr, netConn.LocalAddr())
+
+			runCtx, runCancel := context.WithCancel(connCtx)
 			defer runCancel()
 
 			if l.opts.TestConnTimeout != 0 {
@@ -126,28 +384,59 @@ func (l *Listener) Run(ctx context.Context) error {
 
 			defer func() {
 				netConn.Close()
-				l.metrics.connectedClients.Dec()
+				l.connections.Remove()
 				wg.Done()
 			}()
 
+			connFields := []zap.Field{zap.String("conn", connID)}
+
+			if tlsConfig != nil {
+				tlsConn, err := tlsHandshake(runCtx, netConn, tlsConfig)
+				if err != nil {
+					logger.Warn("TLS handshake failed", append(connFields, zap.Error(err))...)
+					return
+				}
+
+				netConn = tlsConn
+
+				state := tlsConn.ConnectionState()
+				connFields = append(
+					connFields,
+					zap.String("tls_version", tlsVersionName(state.Version)),
+					zap.String("tls_cipher", tls.CipherSuiteName(state.CipherSuite)),
+				)
+			}
+
 			opts := &newConnOpts{
 				netConn:     netConn,
 				mode:        l.opts.Mode,
 				l:           l.opts.Logger.Named("// " + connID + " "), // derive from the original unnamed logger
 				proxyAddr:   l.opts.ProxyAddr,
+				diffIgnored: l.opts.DiffIgnoredPaths,
 				handler:     l.opts.Handler,
 				connMetrics: l.metrics.connMetrics,
+				recorder:    l.recorder,
+				inFlight:    l.inFlight,
+				connections: l.connections,
+				slowOps:     l.slowOps,
+				readOnly:    l.readOnly,
+				requireAuth: l.opts.RequireAuth,
+				idleTimeout: l.opts.IdleTimeout,
+				connID:      connID,
+				accessLog:   l.accessLog,
+				tracer:      l.tracer,
+				logPayloads: l.opts.LogPayloads,
 			}
 			conn, e := newConn(opts)
 			if e != nil {
-				logger.Warn("Failed to create connection", zap.String("conn", connID), zap.Error(e))
+				logger.Warn("Failed to create connection", append(connFields, zap.Error(e))...)
 				return
 			}
 
-			logger.Info("Connection started", zap.String("conn", connID))
+			logger.Info("Connection started", connFields...)
 
 			e = conn.run(runCtx)
-			if e == io.EOF {
+			if e == io.EOF || errors.Is(e, errIdleTimeout) {
 				logger.Info("Connection stopped", zap.String("conn", connID))
 			} else {
 				logger.Warn("Connection stopped", zap.String("conn", connID), zap.Error(e))
@@ -157,17 +446,159 @@ func (l *Listener) Run(ctx context.Context) error {
 
 	logger.Info("Waiting for all connections to stop...")
 	wg.Wait()
+}
 
-	return ctx.Err()
+// reject replies to the first client's request with an error and closes the connection.
+// It is used when the maximum number of connections is reached.
+func (l *Listener) reject(ctx context.Context, netConn net.Conn, tlsConfig *tls.Config, logger *zap.Logger) {
+	defer netConn.Close()
+
+	max := l.connections.Max()
+	logger.Warn(
+		"Connection refused because too many open connections",
+		zap.Stringer("remote_addr", netConn.RemoteAddr()), zap.Int32("max", max),
+	)
+
+	// do not let slow clients hold rejected connections
+	if err := netConn.SetDeadline(time.Now().Add(rejectTimeout)); err != nil {
+		return
+	}
+
+	if tlsConfig != nil {
+		tlsConn, err := tlsHandshake(ctx, netConn, tlsConfig)
+		if err != nil {
+			return
+		}
+
+		netConn = tlsConn
+	}
+
+	bufr := bufio.NewReader(netConn)
+	bufw := bufio.NewWriter(netConn)
+
+	reqHeader, reqBody, err := wire.ReadMessage(bufr)
+	if err != nil {
+		return
+	}
+
+	if compressed, ok := reqBody.(*wire.OpCompressed); ok {
+		if reqHeader, reqBody, err = wire.Decompress(reqHeader, compressed); err != nil {
+			return
+		}
+	}
+
+	protoErr, _ := common.ProtocolError(common.NewErrorMsg(
+		common.ErrOperationFailed,
+		fmt.Sprintf("connection refused because too many open connections: %d", max),
+	))
+
+	resHeader := &wire.MsgHeader{
+		RequestID:  1,
+		ResponseTo: reqHeader.RequestID,
+	}
+
+	var resBody wire.MsgBody
+	switch reqHeader.OpCode {
+	case wire.OpCodeMsg:
+		var msg wire.OpMsg
+		must.NoError(msg.SetSections(wire.OpMsgSection{
+			Documents: []*types.Document{protoErr.Document()},
+		}))
+		resHeader.OpCode = wire.OpCodeMsg
+		resBody = &msg
+
+	case wire.OpCodeQuery:
+		resHeader.OpCode = wire.OpCodeReply
+		resBody = &wire.OpReply{
+			ResponseFlags:  wire.OpReplyFlags(wire.OpReplyQueryFailure),
+			NumberReturned: 1,
+			Documents:      []*types.Document{common.QueryFailureDocument(protoErr)},
+		}
+
+	default:
+		return
+	}
+
+	b, err := resBody.MarshalBinary()
+	if err != nil {
+		return
+	}
+	resHeader.MessageLength = int32(wire.MsgHeaderLen + len(b))
+
+	if err = wire.WriteMessage(bufw, resHeader, resBody); err == nil {
+		_ = bufw.Flush()
+	}
 }
 
 // Addr returns listener's address.
 // It can be used to determine an actually used port, if it was zero.
+//
+// It returns nil if listener does not listen for plaintext connections.
 func (l *Listener) Addr() net.Addr {
 	<-l.listening
+
+	if l.listener == nil {
+		return nil
+	}
+
 	return l.listener.Addr()
 }
 
+// TLSAddr returns listener's TLS address.
+// It can be used to determine an actually used port, if it was zero.
+//
+// It returns nil if listener does not listen for TLS connections.
+func (l *Listener) TLSAddr() net.Addr {
+	<-l.listening
+
+	if l.tlsListener == nil {
+		return nil
+	}
+
+	return l.tlsListener.Addr()
+}
+
+// UnixAddr returns listener's Unix domain socket address.
+//
+// It returns nil if listener does not listen on Unix domain socket.
+func (l *Listener) UnixAddr() net.Addr {
+	<-l.listening
+
+	if l.unixListener == nil {
+		return nil
+	}
+
+	return l.unixListener.Addr()
+}
+
+// listenUnix listens on the Unix domain socket with the given path and permissions.
+//
+// A stale socket file (for example, left after a crash) is removed first;
+// other files with that path are not touched.
+func listenUnix(path string, perm os.FileMode) (net.Listener, error) {
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, lazyerrors.Errorf("%s exists and is not a socket", path)
+		}
+
+		if err = os.Remove(path); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if err = os.Chmod(path, perm); err != nil {
+		listener.Close()
+		return nil, lazyerrors.Error(err)
+	}
+
+	return listener, nil
+}
+
 // Describe implements prometheus.Collector.
 func (l *Listener) Describe(ch chan<- *prometheus.Desc) {
 	l.metrics.Describe(ch)
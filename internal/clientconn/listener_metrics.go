@@ -14,7 +14,11 @@
 
 package clientconn
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+)
 
 const (
 	namespace = "ferretdb"
@@ -23,21 +27,55 @@ const (
 
 // ListenerMetrics represents listener metrics.
 type ListenerMetrics struct {
-	connectedClients prometheus.Gauge
+	connectedClients prometheus.GaugeFunc
+	availableConns   prometheus.GaugeFunc
+	rejectedConns    prometheus.CounterFunc
+	accessLogDropped prometheus.CounterFunc
 	accepts          *prometheus.CounterVec
 	connMetrics      *ConnMetrics
 }
 
 // newListenerMetrics creates new listener metrics.
-func newListenerMetrics() *ListenerMetrics {
+//
+// Connection counts are derived from the given counters, so they always agree with serverStatus.
+// Access log may be nil.
+func newListenerMetrics(conns *conninfo.Connections, al *accessLog) *ListenerMetrics {
 	return &ListenerMetrics{
-		connectedClients: prometheus.NewGauge(
+		connectedClients: prometheus.NewGaugeFunc(
 			prometheus.GaugeOpts{
 				Namespace: namespace,
 				Subsystem: subsystem,
 				Name:      "connected",
 				Help:      "The current number of connected clients.",
 			},
+			func() float64 { return float64(conns.Stats().Current) },
+		),
+		availableConns: prometheus.NewGaugeFunc(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "available",
+				Help:      "The number of available client connections.",
+			},
+			func() float64 { return float64(conns.Stats().Available) },
+		),
+		rejectedConns: prometheus.NewCounterFunc(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "rejected_total",
+				Help:      "Total number of client connections rejected due to the connections limit.",
+			},
+			func() float64 { return float64(conns.Stats().Rejected) },
+		),
+		accessLogDropped: prometheus.NewCounterFunc(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: subsystem,
+				Name:      "access_log_dropped_total",
+				Help:      "Total number of access log entries dropped due to the full buffer.",
+			},
+			func() float64 { return float64(al.dropped()) },
 		),
 		accepts: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
@@ -55,6 +93,9 @@ func newListenerMetrics() *ListenerMetrics {
 // Describe implements prometheus.Collector.
 func (lm *ListenerMetrics) Describe(ch chan<- *prometheus.Desc) {
 	lm.connectedClients.Describe(ch)
+	lm.availableConns.Describe(ch)
+	lm.rejectedConns.Describe(ch)
+	lm.accessLogDropped.Describe(ch)
 	lm.accepts.Describe(ch)
 	lm.connMetrics.Describe(ch)
 }
@@ -62,6 +103,9 @@ func (lm *ListenerMetrics) Describe(ch chan<- *prometheus.Desc) {
 // Collect implements prometheus.Collector.
 func (lm *ListenerMetrics) Collect(ch chan<- prometheus.Metric) {
 	lm.connectedClients.Collect(ch)
+	lm.availableConns.Collect(ch)
+	lm.rejectedConns.Collect(ch)
+	lm.accessLogDropped.Collect(ch)
 	lm.accepts.Collect(ch)
 	lm.connMetrics.Collect(ch)
 }
@@ -0,0 +1,107 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/util/debug"
+)
+
+// listenerState represents the listener state reported on the debug endpoint.
+type listenerState struct {
+	Connections []connState `json:"connections"`
+	InFlightOps []opState   `json:"inFlightOps"`
+}
+
+// connState represents a client connection state.
+type connState struct {
+	ID          string  `json:"id"`
+	Client      string  `json:"client"`
+	AppName     string  `json:"appName,omitempty"`
+	AgeSeconds  float64 `json:"ageSeconds"`
+	IdleSeconds float64 `json:"idleSeconds"` // since the last request
+}
+
+// opState represents an operation in progress.
+//
+// Only the command name and namespace are reported, not the command document.
+type opState struct {
+	ID            int64  `json:"opid"`
+	Client        string `json:"client"`
+	AppName       string `json:"appName,omitempty"`
+	Command       string `json:"command"`
+	NS            string `json:"ns,omitempty"`
+	RunningMillis int64  `json:"runningMillis"`
+}
+
+// DebugState implements debug.StateProvider.
+func (l *Listener) DebugState() any {
+	now := time.Now()
+
+	res := listenerState{
+		Connections: []connState{},
+		InFlightOps: []opState{},
+	}
+
+	for _, c := range l.connections.Conns() {
+		last := c.LastRequest
+		if last.IsZero() {
+			last = c.Started
+		}
+
+		res.Connections = append(res.Connections, connState{
+			ID:          c.ID,
+			Client:      c.Client,
+			AppName:     c.AppName,
+			AgeSeconds:  now.Sub(c.Started).Seconds(),
+			IdleSeconds: now.Sub(last).Seconds(),
+		})
+	}
+
+	for _, op := range l.connections.Ops() {
+		s := opState{
+			ID:            op.ID,
+			Client:        op.Client,
+			RunningMillis: now.Sub(op.Start).Milliseconds(),
+		}
+
+		if md := op.ClientMetadata; md != nil {
+			s.AppName = md.AppName
+		}
+
+		if op.Command != nil {
+			s.Command = op.Command.Command()
+
+			db, _ := op.Command.Get("$db")
+			collection, _ := op.Command.Get(s.Command)
+			if db, ok := db.(string); ok {
+				s.NS = db
+				if collection, ok := collection.(string); ok && collection != "" {
+					s.NS += "." + collection
+				}
+			}
+		}
+
+		res.InFlightOps = append(res.InFlightOps, s)
+	}
+
+	return &res
+}
+
+// check interfaces
+var (
+	_ debug.StateProvider = (*Listener)(nil)
+)
@@ -0,0 +1,347 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/dummy"
+	"github.com/FerretDB/FerretDB/internal/handlers/registry"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// testBuildInfo sends buildInfo command over the connection and checks the successful response.
+func testBuildInfo(t *testing.T, conn net.Conn) {
+	t.Helper()
+
+	header, msg := makeMsg(t, 1, must.NotFail(types.NewDocument("buildInfo", int32(1), "$db", "admin")))
+
+	bufw := bufio.NewWriter(conn)
+	require.NoError(t, wire.WriteMessage(bufw, header, msg))
+	require.NoError(t, bufw.Flush())
+
+	resHeader, resBody, err := wire.ReadMessage(bufio.NewReader(conn))
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), resHeader.ResponseTo)
+
+	doc := must.NotFail(resBody.(*wire.OpMsg).Document())
+	assert.Equal(t, float64(1), must.NotFail(doc.Get("ok")))
+}
+
+func TestListenUnix(t *testing.T) {
+	t.Parallel()
+
+	// keep the path short, as socket paths are limited to ~100 bytes
+	dir, err := os.MkdirTemp("", "ferretdb")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "mongodb.sock")
+
+	// create a stale socket file
+	stale, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+	require.FileExists(t, path)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	h, err := dummy.New()
+	require.NoError(t, err)
+
+	l := NewListener(&NewListenerOpts{
+		Unix:     path,
+		UnixPerm: 0o600,
+		Mode:     NormalMode,
+		Handler:  h,
+		Logger:   zaptest.NewLogger(t),
+		Metrics:  prometheus.NewRegistry(),
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.ErrorIs(t, l.Run(ctx), context.Canceled)
+	}()
+
+	assert.Nil(t, l.Addr())
+	assert.Nil(t, l.TLSAddr())
+	require.Equal(t, path, l.UnixAddr().String())
+
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), fi.Mode().Perm())
+
+	conn, err := net.Dial("unix", path)
+	require.NoError(t, err)
+
+	testBuildInfo(t, conn)
+	require.NoError(t, conn.Close())
+
+	cancel()
+	<-done
+
+	assert.NoFileExists(t, path)
+
+	t.Run("NotSocket", func(t *testing.T) {
+		t.Parallel()
+
+		file := filepath.Join(dir, "file")
+		require.NoError(t, os.WriteFile(file, []byte("data"), 0o666))
+
+		_, err := listenUnix(file, 0o700)
+		assert.Error(t, err)
+		assert.FileExists(t, file)
+	})
+}
+
+func TestMaxConnections(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	h, err := dummy.New()
+	require.NoError(t, err)
+
+	l := NewListener(&NewListenerOpts{
+		ListenAddr:     "127.0.0.1:0",
+		MaxConnections: 2,
+		Mode:           NormalMode,
+		Handler:        h,
+		Logger:         zaptest.NewLogger(t),
+		Metrics:        prometheus.NewRegistry(),
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.ErrorIs(t, l.Run(ctx), context.Canceled)
+	}()
+
+	addr := l.Addr().String()
+
+	conns := make([]net.Conn, 2)
+	for i := range conns {
+		conns[i], err = net.Dial("tcp", addr)
+		require.NoError(t, err)
+		defer conns[i].Close()
+
+		testBuildInfo(t, conns[i])
+	}
+
+	const errmsg = "connection refused because too many open connections: 2"
+
+	t.Run("OpMsg", func(t *testing.T) {
+		conn, err := net.Dial("tcp", addr)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		header, msg := makeMsg(t, 1, must.NotFail(types.NewDocument("hello", int32(1), "$db", "admin")))
+
+		bufw := bufio.NewWriter(conn)
+		require.NoError(t, wire.WriteMessage(bufw, header, msg))
+		require.NoError(t, bufw.Flush())
+
+		bufr := bufio.NewReader(conn)
+		resHeader, resBody, err := wire.ReadMessage(bufr)
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), resHeader.ResponseTo)
+
+		doc := must.NotFail(resBody.(*wire.OpMsg).Document())
+		assert.Equal(t, float64(0), must.NotFail(doc.Get("ok")))
+		assert.Equal(t, int32(common.ErrOperationFailed), must.NotFail(doc.Get("code")))
+		assert.Equal(t, errmsg, must.NotFail(doc.Get("errmsg")))
+
+		// connection is closed after the reply
+		_, _, err = wire.ReadMessage(bufr)
+		assert.Error(t, err)
+	})
+
+	t.Run("OpQuery", func(t *testing.T) {
+		conn, err := net.Dial("tcp", addr)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		query := &wire.OpQuery{
+			FullCollectionName: "admin.$cmd",
+			NumberToReturn:     -1,
+			Query:              must.NotFail(types.NewDocument("isMaster", int32(1))),
+		}
+		b, err := query.MarshalBinary()
+		require.NoError(t, err)
+
+		header := &wire.MsgHeader{
+			MessageLength: int32(wire.MsgHeaderLen + len(b)),
+			RequestID:     2,
+			OpCode:        wire.OpCodeQuery,
+		}
+
+		bufw := bufio.NewWriter(conn)
+		require.NoError(t, wire.WriteMessage(bufw, header, query))
+		require.NoError(t, bufw.Flush())
+
+		resHeader, resBody, err := wire.ReadMessage(bufio.NewReader(conn))
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), resHeader.ResponseTo)
+
+		reply := resBody.(*wire.OpReply)
+		assert.True(t, reply.ResponseFlags.FlagSet(wire.OpReplyQueryFailure))
+		require.Len(t, reply.Documents, 1)
+		assert.Equal(t, errmsg, must.NotFail(reply.Documents[0].Get("$err")))
+	})
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(l.metrics.rejectedConns))
+	assert.Equal(t, float64(2), testutil.ToFloat64(l.metrics.connectedClients))
+	assert.Equal(t, float64(0), testutil.ToFloat64(l.metrics.availableConns))
+
+	// a slot is freed once the connection is closed
+	require.NoError(t, conns[0].Close())
+	require.Eventually(t, func() bool {
+		return l.connections.Stats().Current == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	testBuildInfo(t, conn)
+
+	stats := l.connections.Stats()
+	assert.Equal(t, int64(3), stats.TotalCreated)
+	assert.Equal(t, int64(2), stats.Rejected)
+
+	cancel()
+	<-done
+}
+
+func TestListenerSideBySide(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// two full instances in the same process, each with its own metrics registry and logger
+	var dones []chan struct{}
+	metrics := make([]*prometheus.Registry, 2)
+	for i := range metrics {
+		metrics[i] = prometheus.NewRegistry()
+		logger := zaptest.NewLogger(t).Named(fmt.Sprintf("instance%d", i))
+
+		h, err := registry.NewHandler("dummy", &registry.NewHandlerOpts{
+			Ctx:     ctx,
+			Logger:  logger,
+			Metrics: metrics[i],
+		})
+		require.NoError(t, err)
+
+		l := NewListener(&NewListenerOpts{
+			ListenAddr: "127.0.0.1:0",
+			Mode:       NormalMode,
+			Handler:    h,
+			Logger:     logger,
+			Metrics:    metrics[i],
+		})
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			assert.ErrorIs(t, l.Run(ctx), context.Canceled)
+		}()
+		dones = append(dones, done)
+
+		// only the first instance handles a command
+		if i == 0 {
+			conn, err := net.Dial("tcp", l.Addr().String())
+			require.NoError(t, err)
+			testBuildInfo(t, conn)
+			require.NoError(t, conn.Close())
+		}
+	}
+
+	cancel()
+	for _, done := range dones {
+		<-done
+	}
+
+	for i, expected := range []int{1, 0} {
+		n, err := testutil.GatherAndCount(metrics[i], "ferretdb_client_commands_total")
+		require.NoError(t, err)
+		assert.Equal(t, expected, n, "instance %d", i)
+	}
+}
+
+func TestListenerDebugState(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	h, err := dummy.New()
+	require.NoError(t, err)
+
+	l := NewListener(&NewListenerOpts{
+		ListenAddr: "127.0.0.1:0",
+		Mode:       NormalMode,
+		Handler:    h,
+		Logger:     zaptest.NewLogger(t),
+		Metrics:    prometheus.NewRegistry(),
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.ErrorIs(t, l.Run(ctx), context.Canceled)
+	}()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	require.NoError(t, err)
+
+	testBuildInfo(t, conn)
+
+	state := l.DebugState().(*listenerState)
+	require.Len(t, state.Connections, 1)
+	assert.Equal(t, conn.LocalAddr().String(), state.Connections[0].Client)
+	assert.NotEmpty(t, state.Connections[0].ID)
+	assert.GreaterOrEqual(t, state.Connections[0].AgeSeconds, state.Connections[0].IdleSeconds)
+	assert.Empty(t, state.InFlightOps)
+
+	require.NoError(t, conn.Close())
+
+	assert.Eventually(t, func() bool {
+		return len(l.DebugState().(*listenerState).Connections) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+}
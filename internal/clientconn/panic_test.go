@@ -0,0 +1,101 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/FerretDB/FerretDB/internal/handlers"
+	"github.com/FerretDB/FerretDB/internal/handlers/dummy"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// panicHandler is a dummy handler with ping command that always panics.
+type panicHandler struct {
+	handlers.Interface
+}
+
+// MsgPing implements handlers.Interface.
+func (h *panicHandler) MsgPing(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	panic("test panic")
+}
+
+func TestRecoverHandler(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dh, err := dummy.New()
+	require.NoError(t, err)
+
+	core, logs := observer.New(zap.DebugLevel)
+	registry := prometheus.NewRegistry()
+
+	l := NewListener(&NewListenerOpts{
+		ListenAddr: "127.0.0.1:0",
+		Mode:       NormalMode,
+		Handler:    &panicHandler{Interface: dh},
+		Logger:     zap.New(core),
+		Metrics:    registry,
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.ErrorIs(t, l.Run(ctx), context.Canceled)
+	}()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	require.NoError(t, err)
+
+	for i := int32(1); i <= 2; i++ {
+		res := roundTrip(t, conn, i, must.NotFail(types.NewDocument("ping", int32(1), "$db", "admin")))
+		assert.Equal(t, float64(0), must.NotFail(res.Get("ok")))
+		assert.Equal(t, int32(1), must.NotFail(res.Get("code")))
+		assert.Equal(t, "InternalError", must.NotFail(res.Get("codeName")))
+
+		msg := must.NotFail(res.Get("errmsg")).(string)
+		require.True(t, strings.HasPrefix(msg, "Internal error, correlation ID: "), msg)
+		id := strings.TrimPrefix(msg, "Internal error, correlation ID: ")
+
+		entries := logs.FilterMessage("Command handler panicked").FilterField(zap.String("correlationID", id)).All()
+		require.Len(t, entries, 1)
+		assert.Equal(t, `{"ping": int, "$db": string}`, entries[0].ContextMap()["command"])
+		assert.Contains(t, entries[0].ContextMap()["stack"], "panicHandler")
+	}
+
+	// connection is still usable
+	testBuildInfo(t, conn)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(l.metrics.connMetrics.panics.WithLabelValues("ping")))
+
+	require.NoError(t, conn.Close())
+
+	cancel()
+	<-done
+}
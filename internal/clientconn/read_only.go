@@ -0,0 +1,52 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+)
+
+// writeCommands contains commands that are rejected in read-only mode.
+var writeCommands = map[string]struct{}{
+	"insert":           {},
+	"update":           {},
+	"delete":           {},
+	"findAndModify":    {},
+	"create":           {},
+	"drop":             {},
+	"dropDatabase":     {},
+	"createIndexes":    {},
+	"dropIndexes":      {},
+	"renameCollection": {},
+
+	// it stores the state in the database
+	"setFreeMonitoring": {},
+}
+
+// checkReadOnly returns NotWritablePrimary error if read-only mode is enabled
+// and the given command is a write command.
+//
+// Drivers handle that error like a write to a secondary.
+func (c *conn) checkReadOnly(command string) error {
+	if c.connInfo.ReadOnly == nil || !c.connInfo.ReadOnly.Enabled() {
+		return nil
+	}
+
+	if _, ok := writeCommands[command]; !ok {
+		return nil
+	}
+
+	return common.NewErrorMsg(common.ErrNotWritablePrimary, "not primary")
+}
@@ -0,0 +1,135 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/handlers"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/dummy"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// readOnlyHandler is a dummy handler that accepts inserts and reads.
+type readOnlyHandler struct {
+	handlers.Interface
+}
+
+// MsgInsert implements handlers.Interface.
+func (h *readOnlyHandler) MsgInsert(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	return okReply(), nil
+}
+
+// MsgFind implements handlers.Interface.
+func (h *readOnlyHandler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	return okReply(), nil
+}
+
+// MsgSetParameter implements handlers.Interface.
+func (h *readOnlyHandler) MsgSetParameter(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	return common.MsgSetParameter(ctx, msg, nil)
+}
+
+// okReply returns a reply with the single ok field.
+func okReply() *wire.OpMsg {
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument("ok", float64(1)))},
+	}))
+
+	return &reply
+}
+
+func TestReadOnly(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dh, err := dummy.New()
+	require.NoError(t, err)
+
+	l := NewListener(&NewListenerOpts{
+		ListenAddr: "127.0.0.1:0",
+		ReadOnly:   true,
+		Mode:       NormalMode,
+		Handler:    &readOnlyHandler{Interface: dh},
+		Logger:     zaptest.NewLogger(t),
+		Metrics:    prometheus.NewRegistry(),
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.ErrorIs(t, l.Run(ctx), context.Canceled)
+	}()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	var requestID int32
+	run := func(t *testing.T, doc *types.Document) *types.Document {
+		t.Helper()
+
+		requestID++
+		return roundTrip(t, conn, requestID, doc)
+	}
+
+	insert := must.NotFail(types.NewDocument("insert", "values", "$db", "test"))
+	find := must.NotFail(types.NewDocument("find", "values", "$db", "test"))
+	setFreeMonitoring := must.NotFail(types.NewDocument("setFreeMonitoring", int32(1), "action", "enable", "$db", "admin"))
+	setReadOnly := func(enabled bool) *types.Document {
+		return must.NotFail(types.NewDocument("setParameter", int32(1), "readOnly", enabled, "$db", "admin"))
+	}
+
+	res := run(t, insert)
+	assert.Equal(t, float64(0), must.NotFail(res.Get("ok")))
+	assert.Equal(t, int32(common.ErrNotWritablePrimary), must.NotFail(res.Get("code")))
+	assert.Equal(t, "not primary", must.NotFail(res.Get("errmsg")))
+
+	res = run(t, setFreeMonitoring)
+	assert.Equal(t, int32(common.ErrNotWritablePrimary), must.NotFail(res.Get("code")))
+
+	res = run(t, find)
+	assert.Equal(t, float64(1), must.NotFail(res.Get("ok")))
+
+	res = run(t, setReadOnly(false))
+	assert.Equal(t, float64(1), must.NotFail(res.Get("ok")))
+	assert.Equal(t, true, must.NotFail(res.Get("was")))
+
+	res = run(t, insert)
+	assert.Equal(t, float64(1), must.NotFail(res.Get("ok")))
+
+	res = run(t, setReadOnly(true))
+	assert.Equal(t, float64(1), must.NotFail(res.Get("ok")))
+	assert.Equal(t, false, must.NotFail(res.Get("was")))
+
+	res = run(t, insert)
+	assert.Equal(t, int32(common.ErrNotWritablePrimary), must.NotFail(res.Get("code")))
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,193 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// redactedCommands contains commands with payloads that are not recorded.
+var redactedCommands = map[string]struct{}{
+	"saslStart":    {},
+	"saslContinue": {},
+}
+
+// unsafeFileChars matches characters that are replaced in recording file names.
+var unsafeFileChars = regexp.MustCompile(`[^0-9A-Za-z.-]+`)
+
+// recorder writes wire traffic of all connections to per-connection files in the directory.
+//
+// Files can be read with wire.LoadRecords.
+type recorder struct {
+	dir     string
+	maxSize int64
+	l       *zap.Logger
+
+	size int64 // total size of recorded messages, accessed atomically
+	full int32 // 1 if maxSize is reached, accessed atomically
+}
+
+// newRecorder creates a new recorder for the given directory.
+//
+// Recording stops when the total size of recorded messages reaches maxSize bytes.
+func newRecorder(dir string, maxSize int64, l *zap.Logger) *recorder {
+	return &recorder{
+		dir:     dir,
+		maxSize: maxSize,
+		l:       l,
+	}
+}
+
+// open creates a new recording file for the connection with the given remote address.
+func (r *recorder) open(remoteAddr string) (*connRecorder, error) {
+	if err := os.MkdirAll(r.dir, 0o777); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	name := fmt.Sprintf("%d_%s.bin", time.Now().UnixNano(), unsafeFileChars.ReplaceAllString(remoteAddr, "_"))
+
+	f, err := os.Create(filepath.Join(r.dir, name))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return &connRecorder{
+		r:    r,
+		f:    f,
+		bufw: bufio.NewWriter(f),
+	}, nil
+}
+
+// reserve returns true if the message of the given length could be recorded without exceeding maxSize.
+func (r *recorder) reserve(l int32) bool {
+	if atomic.AddInt64(&r.size, int64(l)) <= r.maxSize {
+		return true
+	}
+
+	if atomic.CompareAndSwapInt32(&r.full, 0, 1) {
+		r.l.Warn("Recording stopped: maximum size reached", zap.String("dir", r.dir), zap.Int64("max_size", r.maxSize))
+	}
+
+	return false
+}
+
+// connRecorder records wire traffic of a single connection.
+//
+// Like conn, it is not safe for concurrent use.
+type connRecorder struct {
+	r    *recorder
+	f    *os.File
+	bufw *bufio.Writer
+
+	// lastCommand is the command of the last recorded request, used for redacting responses.
+	lastCommand string
+}
+
+// request records the request message; it should be uncompressed.
+func (cr *connRecorder) request(header *wire.MsgHeader, body wire.MsgBody) error {
+	cr.lastCommand = ""
+	if msg, ok := body.(*wire.OpMsg); ok {
+		if doc, err := msg.Document(); err == nil {
+			cr.lastCommand = doc.Command()
+		}
+	}
+
+	return cr.record(header, body)
+}
+
+// response records the response message for the last recorded request; it should be uncompressed.
+func (cr *connRecorder) response(header *wire.MsgHeader, body wire.MsgBody) error {
+	return cr.record(header, body)
+}
+
+// record writes the message to the file, redacting payloads if needed.
+func (cr *connRecorder) record(header *wire.MsgHeader, body wire.MsgBody) error {
+	if _, ok := redactedCommands[cr.lastCommand]; ok {
+		var err error
+		if header, body, err = redact(header, body); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	if !cr.r.reserve(header.MessageLength) {
+		return nil
+	}
+
+	if err := wire.WriteMessage(cr.bufw, header, body); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return cr.bufw.Flush()
+}
+
+// close closes the recording file.
+func (cr *connRecorder) close() error {
+	err := cr.bufw.Flush()
+
+	if e := cr.f.Close(); err == nil {
+		err = e
+	}
+
+	return err
+}
+
+// redact returns a copy of the OP_MSG message with the payload field replaced with empty binary data.
+//
+// Other messages are returned as is.
+func redact(header *wire.MsgHeader, body wire.MsgBody) (*wire.MsgHeader, wire.MsgBody, error) {
+	msg, ok := body.(*wire.OpMsg)
+	if !ok {
+		return header, body, nil
+	}
+
+	doc, err := msg.Document()
+	if err != nil {
+		return nil, nil, lazyerrors.Error(err)
+	}
+
+	if doc.Has("payload") {
+		must.NoError(doc.Set("payload", types.Binary{B: []byte{}}))
+	}
+
+	res := &wire.OpMsg{
+		FlagBits: msg.FlagBits,
+	}
+	if err = res.SetSections(wire.OpMsgSection{Documents: []*types.Document{doc}}); err != nil {
+		return nil, nil, lazyerrors.Error(err)
+	}
+
+	b, err := res.MarshalBinary()
+	if err != nil {
+		return nil, nil, lazyerrors.Error(err)
+	}
+
+	resHeader := *header
+	resHeader.MessageLength = int32(wire.MsgHeaderLen + len(b))
+
+	return &resHeader, res, nil
+}
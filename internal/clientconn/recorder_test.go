@@ -0,0 +1,92 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// makeMsg returns OP_MSG header and body for the given document.
+func makeMsg(t *testing.T, requestID int32, doc *types.Document) (*wire.MsgHeader, *wire.OpMsg) {
+	t.Helper()
+
+	var msg wire.OpMsg
+	require.NoError(t, msg.SetSections(wire.OpMsgSection{Documents: []*types.Document{doc}}))
+
+	b, err := msg.MarshalBinary()
+	require.NoError(t, err)
+
+	header := &wire.MsgHeader{
+		MessageLength: int32(wire.MsgHeaderLen + len(b)),
+		RequestID:     requestID,
+		OpCode:        wire.OpCodeMsg,
+	}
+
+	return header, &msg
+}
+
+func TestRecorder(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	payload := types.Binary{B: []byte("n,,n=user,r=secret")}
+	pingHeader, pingBody := makeMsg(t, 1, must.NotFail(types.NewDocument("ping", int32(1), "$db", "admin")))
+	saslHeader, saslBody := makeMsg(t, 2, must.NotFail(types.NewDocument(
+		"saslStart", int32(1), "mechanism", "SCRAM-SHA-256", "payload", payload, "$db", "admin",
+	)))
+	saslResHeader, saslResBody := makeMsg(t, 3, must.NotFail(types.NewDocument(
+		"conversationId", int32(1), "payload", payload, "ok", float64(1),
+	)))
+
+	// enough for the first three messages only
+	maxSize := int64(pingHeader.MessageLength + saslHeader.MessageLength + saslResHeader.MessageLength)
+
+	r := newRecorder(dir, maxSize, zap.NewNop())
+	cr, err := r.open("127.0.0.1:12345")
+	require.NoError(t, err)
+
+	require.NoError(t, cr.request(pingHeader, pingBody))
+	require.NoError(t, cr.request(saslHeader, saslBody))
+	require.NoError(t, cr.response(saslResHeader, saslResBody))
+	require.NoError(t, cr.request(pingHeader, pingBody)) // not recorded
+	require.NoError(t, cr.close())
+
+	records, err := wire.LoadRecords(dir)
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+
+	assert.Equal(t, pingHeader, records[0].Header)
+	assert.Equal(t, pingBody, records[0].Body)
+
+	for _, record := range records[1:] {
+		doc, err := record.Body.(*wire.OpMsg).Document()
+		require.NoError(t, err)
+		assert.Empty(t, must.NotFail(doc.Get("payload")).(types.Binary).B)
+	}
+
+	// originals are not modified
+	doc, err := saslBody.Document()
+	require.NoError(t, err)
+	assert.Equal(t, payload, must.NotFail(doc.Get("payload")))
+}
@@ -0,0 +1,91 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"sync"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+)
+
+// inFlight tracks in-flight handler invocations for graceful shutdown.
+//
+// The nil value is valid and does not track anything.
+type inFlight struct {
+	wg           sync.WaitGroup
+	m            sync.Mutex
+	shuttingDown bool
+}
+
+// start marks the start of a handler invocation.
+//
+// It returns false if shutdown is in progress; the request should be rejected then, and done should not be called.
+func (f *inFlight) start() bool {
+	if f == nil {
+		return true
+	}
+
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	if f.shuttingDown {
+		return false
+	}
+
+	f.wg.Add(1)
+
+	return true
+}
+
+// done marks the end of a handler invocation started by start.
+func (f *inFlight) done() {
+	if f == nil {
+		return
+	}
+
+	f.wg.Done()
+}
+
+// shutdown marks shutdown as in progress and waits up to timeout for in-flight invocations to complete.
+//
+// It returns true if all of them completed.
+func (f *inFlight) shutdown(timeout time.Duration) bool {
+	f.m.Lock()
+	f.shuttingDown = true
+	f.m.Unlock()
+
+	// start does not call Add after that, so Wait below is safe
+	drained := make(chan struct{})
+	go func() {
+		f.wg.Wait()
+		close(drained)
+	}()
+
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+
+	select {
+	case <-drained:
+		return true
+	case <-t.C:
+		return false
+	}
+}
+
+// errShutdownInProgress returns an error for requests received during graceful shutdown.
+func errShutdownInProgress() error {
+	return common.NewErrorMsg(common.ErrShutdownInProgress, "The server is in quiesce mode and will shut down")
+}
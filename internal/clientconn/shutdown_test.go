@@ -0,0 +1,190 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/handlers"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/dummy"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// slowHandler is a dummy handler with ping command that blocks until released or canceled.
+type slowHandler struct {
+	handlers.Interface
+	started chan struct{}
+	release chan struct{}
+}
+
+// MsgPing implements handlers.Interface.
+func (h *slowHandler) MsgPing(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	close(h.started)
+
+	select {
+	case <-h.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument("ok", float64(1)))},
+	}))
+
+	return &reply, nil
+}
+
+// roundTrip sends the command over the connection and returns the response document.
+func roundTrip(t *testing.T, conn net.Conn, requestID int32, doc *types.Document) *types.Document {
+	t.Helper()
+
+	header, msg := makeMsg(t, requestID, doc)
+
+	bufw := bufio.NewWriter(conn)
+	require.NoError(t, wire.WriteMessage(bufw, header, msg))
+	require.NoError(t, bufw.Flush())
+
+	_, resBody, err := wire.ReadMessage(bufio.NewReader(conn))
+	require.NoError(t, err)
+
+	return must.NotFail(resBody.(*wire.OpMsg).Document())
+}
+
+func TestShutdown(t *testing.T) {
+	t.Parallel()
+
+	ping := must.NotFail(types.NewDocument("ping", int32(1), "$db", "admin"))
+	buildInfo := must.NotFail(types.NewDocument("buildInfo", int32(1), "$db", "admin"))
+
+	// setup starts listener with slow handler and returns it with the channel closed when Run returns.
+	setup := func(t *testing.T, ctx context.Context, timeout time.Duration) (*Listener, *slowHandler, <-chan error) {
+		t.Helper()
+
+		dh, err := dummy.New()
+		require.NoError(t, err)
+
+		h := &slowHandler{
+			Interface: dh,
+			started:   make(chan struct{}),
+			release:   make(chan struct{}),
+		}
+
+		l := NewListener(&NewListenerOpts{
+			ListenAddr:      "127.0.0.1:0",
+			Mode:            NormalMode,
+			Handler:         h,
+			Logger:          zaptest.NewLogger(t),
+			Metrics:         prometheus.NewRegistry(),
+			ShutdownTimeout: timeout,
+		})
+
+		done := make(chan error, 1)
+		go func() {
+			done <- l.Run(ctx)
+		}()
+
+		return l, h, done
+	}
+
+	t.Run("Drain", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		l, h, done := setup(t, ctx, time.Minute)
+
+		slowConn, err := net.Dial("tcp", l.Addr().String())
+		require.NoError(t, err)
+		defer slowConn.Close()
+
+		idleConn, err := net.Dial("tcp", l.Addr().String())
+		require.NoError(t, err)
+		defer idleConn.Close()
+
+		testBuildInfo(t, idleConn)
+
+		pingRes := make(chan *types.Document, 1)
+		go func() {
+			pingRes <- roundTrip(t, slowConn, 1, ping)
+		}()
+
+		<-h.started
+		cancel()
+
+		// new commands are rejected once shutdown starts
+		var res *types.Document
+		for i := int32(2); ; i++ {
+			res = roundTrip(t, idleConn, i, buildInfo)
+			if must.NotFail(res.Get("ok")) == float64(0) {
+				break
+			}
+
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		assert.Equal(t, int32(common.ErrShutdownInProgress), must.NotFail(res.Get("code")))
+		assert.Equal(t, "ShutdownInProgress", must.NotFail(res.Get("codeName")))
+
+		// in-flight command completes
+		select {
+		case <-done:
+			t.Fatal("listener stopped before in-flight command completed")
+		default:
+		}
+
+		close(h.release)
+		assert.Equal(t, float64(1), must.NotFail((<-pingRes).Get("ok")))
+
+		assert.ErrorIs(t, <-done, context.Canceled)
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		l, h, done := setup(t, ctx, 100*time.Millisecond)
+
+		conn, err := net.Dial("tcp", l.Addr().String())
+		require.NoError(t, err)
+		defer conn.Close()
+
+		header, msg := makeMsg(t, 1, ping)
+		bufw := bufio.NewWriter(conn)
+		require.NoError(t, wire.WriteMessage(bufw, header, msg))
+		require.NoError(t, bufw.Flush())
+
+		<-h.started
+		cancel()
+
+		// in-flight command is canceled after timeout
+		assert.ErrorIs(t, <-done, context.Canceled)
+	})
+}
@@ -0,0 +1,160 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"math/rand"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// defaultSlowMS is the default threshold in milliseconds above which operations are logged as slow.
+const defaultSlowMS = 100
+
+// slowOpShapeFields contains the request fields with filters and updates, by command.
+// Their shapes are logged instead of values to avoid leaking data.
+var slowOpShapeFields = map[string][]string{
+	"find":          {"filter", "sort", "projection"},
+	"count":         {"query"},
+	"distinct":      {"query"},
+	"aggregate":     {"pipeline"},
+	"delete":        {"deletes"},
+	"update":        {"updates"},
+	"findAndModify": {"query", "sort", "update"},
+	"findandmodify": {"query", "sort", "update"},
+}
+
+// logSlowOp logs the operation if it took longer than the configured threshold
+// or if it was sampled.
+func (c *conn) logSlowOp(command string, document *types.Document, result string, d time.Duration) {
+	settings := c.connInfo.SlowOps
+	if settings == nil || document == nil {
+		return
+	}
+
+	slowMS := settings.SlowMS()
+	slow := slowMS >= 0 && d >= time.Duration(slowMS)*time.Millisecond
+
+	if !slow {
+		rate := settings.SampleRate()
+		if rate <= 0 || rand.Float64() >= rate { //nolint:gosec // we don't need a cryptographically secure RNG there
+			return
+		}
+	}
+
+	fields := []zap.Field{
+		zap.String("command", command),
+		zap.String("result", result),
+		zap.Int64("durationMillis", d.Milliseconds()),
+	}
+
+	if ns := requestNS(command, document); ns != "" {
+		fields = append(fields, zap.String("ns", ns))
+	}
+
+	for _, f := range slowOpShapeFields[command] {
+		if v, _ := document.Get(f); v != nil {
+			fields = append(fields, zap.String(f, opShape(v)))
+		}
+	}
+
+	if stats := c.connInfo.OpStats; stats.Reported {
+		fields = append(
+			fields,
+			zap.Int64("docsExamined", stats.DocsExamined),
+			zap.Int64("docsReturned", stats.DocsReturned),
+			zap.Bool("pushdown", stats.Pushdown),
+		)
+	}
+
+	if slow {
+		c.l.Desugar().Warn("Slow operation", fields...)
+		return
+	}
+
+	c.l.Desugar().Info("Sampled operation", fields...)
+}
+
+// opShape returns the shape of the value with all scalar values replaced by type placeholders.
+func opShape(v any) string {
+	var sb strings.Builder
+	writeOpShape(&sb, v)
+
+	return sb.String()
+}
+
+// writeOpShape writes the shape of the value to the builder.
+func writeOpShape(sb *strings.Builder, v any) {
+	switch v := v.(type) {
+	case *types.Document:
+		sb.WriteString("{")
+
+		for i, k := range v.Keys() {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+
+			sb.WriteString(k)
+			sb.WriteString(": ")
+			writeOpShape(sb, must.NotFail(v.Get(k)))
+		}
+
+		sb.WriteString("}")
+
+	case *types.Array:
+		sb.WriteString("[")
+
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+
+			writeOpShape(sb, must.NotFail(v.Get(i)))
+		}
+
+		sb.WriteString("]")
+
+	default:
+		sb.WriteString("?")
+		sb.WriteString(common.AliasFromType(v))
+	}
+}
+
+// requestNS returns the request's namespace: database and, if the command has it, collection name.
+func requestNS(command string, document *types.Document) string {
+	if document == nil {
+		return ""
+	}
+
+	db, _ := document.Get("$db")
+	ns, _ := db.(string)
+	if ns == "" {
+		return ""
+	}
+
+	if collection, _ := document.Get(command); collection != nil {
+		if collection, ok := collection.(string); ok {
+			ns += "." + collection
+		}
+	}
+
+	return ns
+}
@@ -0,0 +1,124 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestOpShape(t *testing.T) {
+	t.Parallel()
+
+	filter := must.NotFail(types.NewDocument(
+		"name", "secret",
+		"age", must.NotFail(types.NewDocument("$gt", int32(42))),
+		"tags", must.NotFail(types.NewDocument("$in", must.NotFail(types.NewArray("a", int64(1))))),
+		"created", time.Now(),
+	))
+
+	expected := "{name: ?string, age: {$gt: ?int}, tags: {$in: [?string, ?long]}, created: ?date}"
+	assert.Equal(t, expected, opShape(filter))
+}
+
+func TestLogSlowOp(t *testing.T) {
+	t.Parallel()
+
+	document := must.NotFail(types.NewDocument(
+		"find", "test",
+		"filter", must.NotFail(types.NewDocument("v", "secret")),
+		"$db", "db",
+	))
+
+	newConn := func(slowMS int64, sampleRate float64) (*conn, *observer.ObservedLogs) {
+		core, logs := observer.New(zap.InfoLevel)
+		c := &conn{
+			l: zap.New(core).Sugar(),
+			connInfo: &conninfo.ConnInfo{
+				SlowOps: conninfo.NewSlowOps(slowMS, sampleRate),
+				OpStats: conninfo.OpStats{
+					Reported:     true,
+					DocsExamined: 10,
+					DocsReturned: 1,
+				},
+			},
+		}
+
+		return c, logs
+	}
+
+	t.Run("Slow", func(t *testing.T) {
+		t.Parallel()
+
+		c, logs := newConn(100, 0)
+		c.logSlowOp("find", document, "ok", 150*time.Millisecond)
+
+		entries := logs.All()
+		require.Len(t, entries, 1)
+		assert.Equal(t, zapcore.WarnLevel, entries[0].Level)
+		assert.Equal(t, "Slow operation", entries[0].Message)
+
+		expected := map[string]any{
+			"command":        "find",
+			"result":         "ok",
+			"durationMillis": int64(150),
+			"ns":             "db.test",
+			"filter":         "{v: ?string}",
+			"docsExamined":   int64(10),
+			"docsReturned":   int64(1),
+			"pushdown":       false,
+		}
+		assert.Equal(t, expected, entries[0].ContextMap())
+		assert.NotContains(t, entries[0].ContextMap()["filter"], "secret")
+	})
+
+	t.Run("Fast", func(t *testing.T) {
+		t.Parallel()
+
+		c, logs := newConn(100, 0)
+		c.logSlowOp("find", document, "ok", 50*time.Millisecond)
+		assert.Zero(t, logs.Len())
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		t.Parallel()
+
+		c, logs := newConn(-1, 0)
+		c.logSlowOp("find", document, "ok", time.Hour)
+		assert.Zero(t, logs.Len())
+	})
+
+	t.Run("Sampled", func(t *testing.T) {
+		t.Parallel()
+
+		c, logs := newConn(100, 1)
+		c.logSlowOp("find", document, "ok", 50*time.Millisecond)
+
+		entries := logs.All()
+		require.Len(t, entries, 1)
+		assert.Equal(t, zapcore.InfoLevel, entries[0].Level)
+		assert.Equal(t, "Sampled operation", entries[0].Message)
+	})
+}
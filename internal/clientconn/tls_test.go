@@ -0,0 +1,148 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/dummy"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// certPath returns the path to the test certificate file with the given name.
+func certPath(name string) string {
+	return filepath.Join("..", "..", "build", "certs", name)
+}
+
+func TestTLS(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	h, err := dummy.New()
+	require.NoError(t, err)
+
+	// log to the test and to the observer
+	core, logs := observer.New(zap.WarnLevel)
+	logger := zaptest.NewLogger(t).WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(c, core)
+	}))
+
+	l := NewListener(&NewListenerOpts{
+		TLS:         "127.0.0.1:0",
+		TLSCertFile: certPath("server-cert.pem"),
+		TLSKeyFile:  certPath("server-key.pem"),
+		TLSCAFile:   certPath("rootCA-cert.pem"),
+		Mode:        NormalMode,
+		Handler:     h,
+		Logger:      logger,
+		Metrics:     prometheus.NewRegistry(),
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.ErrorIs(t, l.Run(ctx), context.Canceled)
+	}()
+
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	assert.Nil(t, l.Addr())
+	addr := l.TLSAddr().String()
+
+	ca := x509.NewCertPool()
+	require.True(t, ca.AppendCertsFromPEM(must.NotFail(os.ReadFile(certPath("rootCA-cert.pem")))))
+
+	clientCert, err := tls.LoadX509KeyPair(certPath("client.pem"), certPath("client.pem"))
+	require.NoError(t, err)
+
+	t.Run("BuildInfo", func(t *testing.T) {
+		conn, err := tls.Dial("tcp", addr, &tls.Config{
+			RootCAs:      ca,
+			Certificates: []tls.Certificate{clientCert},
+			ServerName:   "localhost",
+		})
+		require.NoError(t, err)
+		defer conn.Close()
+
+		testBuildInfo(t, conn)
+	})
+
+	t.Run("NoClientCertificate", func(t *testing.T) {
+		conn, err := tls.Dial("tcp", addr, &tls.Config{
+			RootCAs:    ca,
+			ServerName: "localhost",
+		})
+		if err == nil {
+			// with TLS 1.3, the client learns about the rejected certificate on the first read
+			defer conn.Close()
+
+			_, err = conn.Read(make([]byte, 1))
+		}
+		assert.Error(t, err)
+	})
+
+	t.Run("Plaintext", func(t *testing.T) {
+		conn, err := net.Dial("tcp", addr)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+
+		// the server should close the connection instead of replying
+		header, msg := makeMsg(t, 1, must.NotFail(types.NewDocument("buildInfo", int32(1), "$db", "admin")))
+		bufw := bufio.NewWriter(conn)
+		require.NoError(t, wire.WriteMessage(bufw, header, msg))
+		require.NoError(t, bufw.Flush())
+
+		_, err = io.ReadAll(conn)
+		require.NoError(t, err)
+
+		cancel()
+		<-done
+
+		var found bool
+		for _, e := range logs.FilterMessage("TLS handshake failed").All() {
+			if strings.Contains(e.ContextMap()["error"].(string), "plaintext connection on TLS port") {
+				found = true
+			}
+		}
+		assert.True(t, found, "%+v", logs.All())
+	})
+}
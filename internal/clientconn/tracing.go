@@ -0,0 +1,140 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// tracerName is the instrumentation name of the listener's tracer.
+const tracerName = "github.com/FerretDB/FerretDB"
+
+// Span attributes that are not defined by semantic conventions.
+const (
+	resultKey    = attribute.Key("ferretdb.result")
+	errorCodeKey = attribute.Key("ferretdb.error_code")
+)
+
+// startCommandSpan starts a span for the given command if tracing is enabled.
+//
+// The span is named after the command and has database and collection attributes;
+// collection is taken from the command document, unless fullCollectionName (for OP_QUERY) is set.
+// Other document fields are never recorded.
+// If tracing is disabled, the given context and nil span are returned.
+func (c *conn) startCommandSpan(ctx context.Context, command string, document *types.Document, fullCollectionName string) (context.Context, trace.Span) { //nolint:lll // argument list is too long
+	if c.tracer == nil {
+		return ctx, nil
+	}
+
+	attrs := []attribute.KeyValue{semconv.DBSystemMongoDB, semconv.DBOperationKey.String(command)}
+
+	var db, collection string
+	if document != nil {
+		if v, _ := document.Get("$db"); v != nil {
+			db, _ = v.(string)
+		}
+		if v, _ := document.Get(command); v != nil {
+			collection, _ = v.(string)
+		}
+	}
+
+	if fullCollectionName != "" {
+		db, collection, _ = strings.Cut(fullCollectionName, ".")
+	}
+
+	if db != "" {
+		attrs = append(attrs, semconv.DBNameKey.String(db))
+	}
+
+	if collection != "" {
+		attrs = append(attrs, semconv.DBMongoDBCollectionKey.String(collection))
+	}
+
+	opts := []trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attrs...),
+	}
+
+	if link, ok := commentLink(document); ok {
+		opts = append(opts, trace.WithLinks(link))
+	}
+
+	// span names should have low cardinality, so unknown commands share a single name
+	return c.tracer.Start(ctx, commandLabel(command), opts...)
+}
+
+// endCommandSpan sets the result attributes and status of the command span and ends it.
+func endCommandSpan(span trace.Span, code common.ErrorCode, result string) {
+	span.SetAttributes(resultKey.String(result))
+
+	if result != "ok" {
+		if code != 0 {
+			span.SetAttributes(errorCodeKey.Int(int(code)))
+		}
+
+		span.SetStatus(codes.Error, result)
+	}
+
+	span.End()
+}
+
+// commentLink returns a link to the remote span if the command comment carries W3C trace context.
+//
+// The comment could be a traceparent string itself,
+// or a document with "traceparent" and (optionally) "tracestate" string fields.
+func commentLink(document *types.Document) (trace.Link, bool) {
+	if document == nil {
+		return trace.Link{}, false
+	}
+
+	comment, err := document.Get("comment")
+	if err != nil {
+		return trace.Link{}, false
+	}
+
+	carrier := propagation.MapCarrier{}
+
+	switch comment := comment.(type) {
+	case string:
+		carrier["traceparent"] = comment
+	case *types.Document:
+		for _, k := range []string{"traceparent", "tracestate"} {
+			if v, _ := comment.Get(k); v != nil {
+				if s, ok := v.(string); ok {
+					carrier[k] = s
+				}
+			}
+		}
+	default:
+		return trace.Link{}, false
+	}
+
+	sc := trace.SpanContextFromContext(propagation.TraceContext{}.Extract(context.Background(), carrier))
+	if !sc.IsValid() {
+		return trace.Link{}, false
+	}
+
+	return trace.Link{SpanContext: sc}, true
+}
@@ -0,0 +1,126 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientconn
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/dummy"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+func TestCommandSpans(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	h, err := dummy.New()
+	require.NoError(t, err)
+
+	recorder := tracetest.NewSpanRecorder()
+
+	l := NewListener(&NewListenerOpts{
+		ListenAddr:     "127.0.0.1:0",
+		Mode:           NormalMode,
+		Handler:        h,
+		Logger:         zaptest.NewLogger(t),
+		Metrics:        prometheus.NewRegistry(),
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)),
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.ErrorIs(t, l.Run(ctx), context.Canceled)
+	}()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	require.NoError(t, err)
+
+	bufr := bufio.NewReader(conn)
+	bufw := bufio.NewWriter(conn)
+
+	const traceparent = "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+
+	for i, doc := range []*types.Document{
+		must.NotFail(types.NewDocument("buildInfo", int32(1), "comment", traceparent, "$db", "admin")),
+		must.NotFail(types.NewDocument(
+			"ping", int32(1),
+			"comment", must.NotFail(types.NewDocument("traceparent", traceparent)),
+			"$db", "test",
+		)),
+		must.NotFail(types.NewDocument("noSuchCommand", "values", "comment", "not a trace context", "$db", "test")),
+	} {
+		header, msg := makeMsg(t, int32(i+1), doc)
+		require.NoError(t, wire.WriteMessage(bufw, header, msg))
+		require.NoError(t, bufw.Flush())
+
+		_, _, err = wire.ReadMessage(bufr)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, conn.Close())
+	cancel()
+	<-done
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+
+	assert.Equal(t, "buildInfo", spans[0].Name())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Contains(t, spans[0].Attributes(), semconv.DBNameKey.String("admin"))
+	assert.Contains(t, spans[0].Attributes(), resultKey.String("ok"))
+
+	assert.Equal(t, "ping", spans[1].Name())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Contains(t, spans[1].Attributes(), semconv.DBNameKey.String("test"))
+	assert.Contains(t, spans[1].Attributes(), errorCodeKey.Int(int(common.ErrNotImplemented)))
+
+	for _, span := range spans[:2] {
+		assert.False(t, span.Parent().IsValid(), "links are not parents")
+		require.Len(t, span.Links(), 1)
+		assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", span.Links()[0].SpanContext.TraceID().String())
+		assert.Equal(t, "b7ad6b7169203331", span.Links()[0].SpanContext.SpanID().String())
+	}
+
+	// unknown commands share a single span name
+	assert.Equal(t, "unknown", spans[2].Name())
+	assert.Empty(t, spans[2].Links())
+	assert.ElementsMatch(t, []attribute.KeyValue{
+		semconv.DBSystemMongoDB,
+		semconv.DBOperationKey.String("noSuchCommand"),
+		semconv.DBNameKey.String("test"),
+		semconv.DBMongoDBCollectionKey.String("values"),
+		resultKey.String("CommandNotFound"),
+		errorCodeKey.Int(int(common.ErrCommandNotFound)),
+	}, spans[2].Attributes())
+}
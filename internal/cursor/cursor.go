@@ -0,0 +1,177 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cursor provides query cursors that are kept open between find and getMore commands.
+//
+// Handlers implement Iterator for their backends; Cursor and Registry are shared by all of them.
+package cursor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// ErrIteratorDone is returned by Iterator.Next when there are no more documents.
+var ErrIteratorDone = errors.New("iterator done")
+
+// Iterator is an iterator over query results implemented by backends.
+type Iterator interface {
+	// Next returns the next document or ErrIteratorDone if there are no more documents.
+	Next(ctx context.Context) (*types.Document, error)
+
+	// Close releases resources used by the iterator.
+	// It is safe to call it multiple times.
+	Close()
+}
+
+// Cursor represents an open query cursor.
+type Cursor struct {
+	DB         string
+	Collection string
+
+	// NoTimeout exempts the cursor from closing by Registry after the idle timeout.
+	// It should be set before the cursor is added to the registry.
+	NoTimeout bool
+
+	// conn is the connection that opened the cursor; set by Registry.Add.
+	conn *conninfo.ConnInfo
+
+	created  time.Time
+	lastUsed int64 // Unix time in nanoseconds; accessed atomically
+
+	// m protects iter and serializes batches.
+	m    sync.Mutex
+	iter Iterator // nil if closed
+}
+
+// New returns a new cursor for the given namespace with the given iterator.
+func New(db, collection string, iter Iterator) *Cursor {
+	c := &Cursor{
+		DB:         db,
+		Collection: collection,
+		iter:       iter,
+		created:    time.Now(),
+	}
+	c.touch()
+
+	return c
+}
+
+// touch updates the time the cursor was last used.
+func (c *Cursor) touch() {
+	atomic.StoreInt64(&c.lastUsed, time.Now().UnixNano())
+}
+
+// LastUsed returns the time the cursor was last used.
+func (c *Cursor) LastUsed() time.Time {
+	return time.Unix(0, atomic.LoadInt64(&c.lastUsed))
+}
+
+// NS returns cursor's namespace as reported to clients.
+func (c *Cursor) NS() string {
+	return c.DB + "." + c.Collection
+}
+
+// NextBatch returns up to batchSize next documents; zero batchSize means all remaining documents.
+//
+// It also returns true if the cursor is exhausted; in that case, the cursor is closed.
+func (c *Cursor) NextBatch(ctx context.Context, batchSize int64) (*types.Array, bool, error) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	// the batch itself could take a while
+	c.touch()
+	defer c.touch()
+
+	res := types.MakeArray(int(batchSize))
+
+	if c.iter == nil {
+		return res, true, nil
+	}
+
+	for batchSize == 0 || int64(res.Len()) < batchSize {
+		doc, err := c.iter.Next(ctx)
+		if err == ErrIteratorDone {
+			c.iter.Close()
+			c.iter = nil
+
+			return res, true, nil
+		}
+
+		if err != nil {
+			return nil, false, lazyerrors.Error(err)
+		}
+
+		if err = res.Append(doc); err != nil {
+			return nil, false, lazyerrors.Error(err)
+		}
+	}
+
+	return res, false, nil
+}
+
+// Close closes the cursor and its iterator.
+//
+// It is safe to call it multiple times.
+func (c *Cursor) Close() {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	if c.iter != nil {
+		c.iter.Close()
+		c.iter = nil
+	}
+}
+
+// sliceIterator is an Iterator over already fetched documents.
+type sliceIterator struct {
+	docs []*types.Document
+}
+
+// NewSliceIterator returns an iterator over the given documents.
+//
+// It is used when all results have to be fetched anyway, for example, for sorting.
+func NewSliceIterator(docs []*types.Document) Iterator {
+	return &sliceIterator{docs: docs}
+}
+
+// Next implements Iterator interface.
+func (it *sliceIterator) Next(ctx context.Context) (*types.Document, error) {
+	if len(it.docs) == 0 {
+		return nil, ErrIteratorDone
+	}
+
+	doc := it.docs[0]
+	it.docs[0] = nil
+	it.docs = it.docs[1:]
+
+	return doc, nil
+}
+
+// Close implements Iterator interface.
+func (it *sliceIterator) Close() {
+	it.docs = nil
+}
+
+// check interfaces
+var (
+	_ Iterator = (*sliceIterator)(nil)
+)
@@ -0,0 +1,184 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cursor
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// testIterator is a slice iterator that records Close calls.
+type testIterator struct {
+	Iterator
+	m      sync.Mutex
+	closed int
+}
+
+// Close implements Iterator interface.
+func (it *testIterator) Close() {
+	it.m.Lock()
+	defer it.m.Unlock()
+
+	it.closed++
+	it.Iterator.Close()
+}
+
+// closedCount returns the number of Close calls; it could be used concurrently with Close.
+func (it *testIterator) closedCount() int {
+	it.m.Lock()
+	defer it.m.Unlock()
+
+	return it.closed
+}
+
+func newTestIterator(n int) *testIterator {
+	docs := make([]*types.Document, n)
+	for i := range docs {
+		docs[i] = must.NotFail(types.NewDocument("_id", int32(i)))
+	}
+
+	return &testIterator{Iterator: NewSliceIterator(docs)}
+}
+
+func TestCursorNextBatch(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	iter := newTestIterator(5)
+	c := New("db", "coll", iter)
+	assert.Equal(t, "db.coll", c.NS())
+
+	batch, done, err := c.NextBatch(ctx, 2)
+	require.NoError(t, err)
+	assert.False(t, done)
+	assert.Equal(t, 2, batch.Len())
+	assert.Equal(t, int32(1), must.NotFail(must.NotFail(batch.Get(1)).(*types.Document).Get("_id")))
+
+	batch, done, err = c.NextBatch(ctx, 0)
+	require.NoError(t, err)
+	assert.True(t, done)
+	assert.Equal(t, 3, batch.Len())
+	assert.Equal(t, 1, iter.closed)
+
+	batch, done, err = c.NextBatch(ctx, 2)
+	require.NoError(t, err)
+	assert.True(t, done)
+	assert.Equal(t, 0, batch.Len())
+
+	c.Close()
+	assert.Equal(t, 1, iter.closed)
+}
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+
+	connInfo1, connInfo2 := new(conninfo.ConnInfo), new(conninfo.ConnInfo)
+	ctx1 := conninfo.WithConnInfo(context.Background(), connInfo1)
+	ctx2 := conninfo.WithConnInfo(context.Background(), connInfo2)
+
+	r := NewRegistry()
+
+	iter1, iter2, iter3 := newTestIterator(1), newTestIterator(1), newTestIterator(1)
+	id1 := r.Add(ctx1, New("db", "coll", iter1))
+	id2 := r.Add(ctx1, New("db", "coll", iter2))
+	id3 := r.Add(ctx2, New("db", "coll2", iter3))
+	assert.NotEqual(t, id1, id2)
+
+	list := r.List()
+	require.Len(t, list, 3)
+	assert.ElementsMatch(t, []int64{id1, id2, id3}, []int64{list[0].ID, list[1].ID, list[2].ID})
+	for _, info := range list {
+		assert.False(t, info.Created.IsZero())
+		if info.ID == id3 {
+			assert.Equal(t, "db.coll2", info.NS)
+		}
+	}
+
+	c := r.Get(id1)
+	require.NotNil(t, c)
+	assert.Equal(t, "db.coll", c.NS())
+
+	assert.True(t, r.Remove(id1))
+	assert.False(t, r.Remove(id1))
+	assert.Nil(t, r.Get(id1))
+	assert.Equal(t, 1, iter1.closed)
+	assert.Len(t, r.List(), 2)
+
+	connInfo1.Close()
+	assert.Nil(t, r.Get(id2))
+	assert.Equal(t, 1, iter2.closed)
+	assert.NotNil(t, r.Get(id3))
+
+	r.Close()
+	assert.Nil(t, r.Get(id3))
+	assert.Equal(t, 1, iter3.closed)
+}
+
+func TestRegistryTimeout(t *testing.T) {
+	t.Parallel()
+
+	ctx := conninfo.WithConnInfo(context.Background(), new(conninfo.ConnInfo))
+
+	r := NewRegistry()
+	defer r.Close()
+
+	assert.Equal(t, DefaultTimeout, r.Timeout())
+
+	iter1, iter2, iter3 := newTestIterator(3), newTestIterator(3), newTestIterator(10)
+	id1 := r.Add(ctx, New("db", "coll", iter1))
+
+	c2 := New("db", "coll", iter2)
+	c2.NoTimeout = true
+	id2 := r.Add(ctx, c2)
+
+	c3 := New("db", "coll", iter3)
+	id3 := r.Add(ctx, c3)
+
+	// not timed out yet
+	r.reap(time.Now())
+	assert.Equal(t, Stats{Open: 3, OpenNoTimeout: 1}, r.Stats())
+
+	r.SetTimeout(200 * time.Millisecond)
+
+	// keep the third cursor in use
+	for i := 0; i < 6; i++ {
+		time.Sleep(50 * time.Millisecond)
+		require.NotNil(t, r.Get(id3), "cursor in use should not be closed")
+
+		_, done, err := c3.NextBatch(ctx, 1)
+		require.NoError(t, err)
+		require.False(t, done)
+	}
+
+	assert.Eventually(t, func() bool { return iter1.closedCount() == 1 }, 5*time.Second, 10*time.Millisecond)
+	assert.Nil(t, r.Get(id1))
+
+	assert.Eventually(t, func() bool { return iter3.closedCount() == 1 }, 5*time.Second, 10*time.Millisecond)
+	assert.Nil(t, r.Get(id3))
+
+	assert.NotNil(t, r.Get(id2), "cursor with NoTimeout flag should not be closed")
+	assert.Equal(t, 0, iter2.closedCount())
+
+	assert.Equal(t, Stats{Open: 1, OpenNoTimeout: 1, TimedOut: 2}, r.Stats())
+}
@@ -0,0 +1,265 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cursor
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+)
+
+// DefaultTimeout is the default idle timeout after which cursors are closed,
+// the same as MongoDB's cursorTimeoutMillis default.
+const DefaultTimeout = 10 * time.Minute
+
+// maxReapInterval is the maximal interval between checks for timed out cursors.
+const maxReapInterval = 4 * time.Second
+
+// Registry contains open cursors by their IDs.
+//
+// Cursors that are not used for longer than the timeout are closed and removed,
+// unless they have NoTimeout flag set.
+type Registry struct {
+	rw      sync.RWMutex
+	cursors map[int64]*Cursor
+
+	timeout  int64 // time.Duration; accessed atomically
+	timedOut int64 // accessed atomically
+
+	wakeup chan struct{}
+	done   chan struct{}
+	once   sync.Once
+}
+
+// NewRegistry returns a new empty registry with DefaultTimeout.
+//
+// Close should be called to stop its background goroutine.
+func NewRegistry() *Registry {
+	r := &Registry{
+		cursors: make(map[int64]*Cursor),
+		timeout: int64(DefaultTimeout),
+		wakeup:  make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+
+	go r.run()
+
+	return r
+}
+
+// Timeout returns the idle timeout after which cursors are closed.
+func (r *Registry) Timeout() time.Duration {
+	return time.Duration(atomic.LoadInt64(&r.timeout))
+}
+
+// SetTimeout sets the idle timeout after which cursors are closed; it must be positive.
+func (r *Registry) SetTimeout(d time.Duration) {
+	if d <= 0 {
+		panic("timeout must be positive")
+	}
+
+	atomic.StoreInt64(&r.timeout, int64(d))
+
+	// recalculate reaping interval
+	select {
+	case r.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// run closes timed out cursors until the registry is closed.
+func (r *Registry) run() {
+	for {
+		interval := r.Timeout() / 2
+		if interval > maxReapInterval {
+			interval = maxReapInterval
+		}
+
+		t := time.NewTimer(interval)
+
+		select {
+		case <-r.done:
+			t.Stop()
+			return
+		case <-r.wakeup:
+			t.Stop()
+		case <-t.C:
+		}
+
+		r.reap(time.Now())
+	}
+}
+
+// reap closes and removes cursors that were not used since the timeout before now.
+func (r *Registry) reap(now time.Time) {
+	deadline := now.Add(-r.Timeout())
+
+	var closed []*Cursor
+
+	r.rw.Lock()
+	for id, c := range r.cursors {
+		if !c.NoTimeout && c.LastUsed().Before(deadline) {
+			closed = append(closed, c)
+			delete(r.cursors, id)
+		}
+	}
+	r.rw.Unlock()
+
+	atomic.AddInt64(&r.timedOut, int64(len(closed)))
+
+	for _, c := range closed {
+		c.Close()
+	}
+}
+
+// Stats represents cursor metrics reported by serverStatus.
+type Stats struct {
+	Open          int64 // number of open cursors
+	OpenNoTimeout int64 // number of open cursors with NoTimeout flag
+	TimedOut      int64 // total number of cursors closed after the idle timeout
+}
+
+// Stats returns cursor metrics.
+func (r *Registry) Stats() Stats {
+	r.rw.RLock()
+	defer r.rw.RUnlock()
+
+	res := Stats{
+		Open:     int64(len(r.cursors)),
+		TimedOut: atomic.LoadInt64(&r.timedOut),
+	}
+
+	for _, c := range r.cursors {
+		if c.NoTimeout {
+			res.OpenNoTimeout++
+		}
+	}
+
+	return res
+}
+
+// Add adds the given cursor opened by the connection from ctx and returns its new ID.
+//
+// The cursor is closed and removed when the connection is closed.
+// IDs are random, so cursors of the previous FerretDB process are not confused with new ones.
+func (r *Registry) Add(ctx context.Context, c *Cursor) int64 {
+	connInfo := conninfo.GetConnInfo(ctx)
+	connInfo.SetCloser(r, func() { r.closeConn(connInfo) })
+
+	c.conn = connInfo
+
+	r.rw.Lock()
+	defer r.rw.Unlock()
+
+	for {
+		id := rand.Int63()
+		if _, ok := r.cursors[id]; id == 0 || ok {
+			continue
+		}
+
+		r.cursors[id] = c
+
+		return id
+	}
+}
+
+// Info represents an open cursor reported for diagnostics.
+type Info struct {
+	ID      int64
+	NS      string
+	Created time.Time
+}
+
+// List returns information about all open cursors sorted by creation time.
+func (r *Registry) List() []Info {
+	r.rw.RLock()
+	res := make([]Info, 0, len(r.cursors))
+	for id, c := range r.cursors {
+		res = append(res, Info{
+			ID:      id,
+			NS:      c.NS(),
+			Created: c.created,
+		})
+	}
+	r.rw.RUnlock()
+
+	sort.Slice(res, func(i, j int) bool {
+		if !res[i].Created.Equal(res[j].Created) {
+			return res[i].Created.Before(res[j].Created)
+		}
+		return res[i].ID < res[j].ID
+	})
+
+	return res
+}
+
+// Get returns the cursor with the given ID or nil.
+func (r *Registry) Get(id int64) *Cursor {
+	r.rw.RLock()
+	defer r.rw.RUnlock()
+
+	return r.cursors[id]
+}
+
+// Remove closes and removes the cursor with the given ID and returns true if it existed.
+func (r *Registry) Remove(id int64) bool {
+	r.rw.Lock()
+	c, ok := r.cursors[id]
+	delete(r.cursors, id)
+	r.rw.Unlock()
+
+	if ok {
+		c.Close()
+	}
+
+	return ok
+}
+
+// Close closes and removes all cursors and stops the background goroutine.
+func (r *Registry) Close() {
+	r.once.Do(func() { close(r.done) })
+
+	r.rw.Lock()
+	cursors := r.cursors
+	r.cursors = make(map[int64]*Cursor)
+	r.rw.Unlock()
+
+	for _, c := range cursors {
+		c.Close()
+	}
+}
+
+// closeConn closes and removes all cursors opened by the given connection.
+func (r *Registry) closeConn(connInfo *conninfo.ConnInfo) {
+	var closed []*Cursor
+
+	r.rw.Lock()
+	for id, c := range r.cursors {
+		if c.conn == connInfo {
+			closed = append(closed, c)
+			delete(r.cursors, id)
+		}
+	}
+	r.rw.Unlock()
+
+	for _, c := range closed {
+		c.Close()
+	}
+}
@@ -0,0 +1,251 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ejson provides converters from/to MongoDB Extended JSON v2 for built-in and `types` types.
+//
+// It is a representation for diagnostics and tooling; it does not replace fjson storage format.
+// See https://github.com/mongodb/specifications/blob/master/source/extended-json.rst.
+//
+// # Mapping
+//
+// Composite types
+//
+//	*types.Document   JSON object
+//	*types.Array      JSON array
+//
+// Scalar types (canonical mode; relaxed mode differs where noted)
+//
+//	float64           {"$numberDouble": "<number>|Infinity|-Infinity|NaN"}; relaxed: JSON number for finite values
+//	string            JSON string
+//	types.Binary      {"$binary": {"base64": "<base64 string>", "subType": "<hex byte>"}}
+//	types.ObjectID    {"$oid": "<24 character hex string>"}
+//	bool              JSON true / false values
+//	time.Time         {"$date": {"$numberLong": "<milliseconds since epoch>"}}; relaxed: {"$date": "<ISO-8601>"}
+//	                  for years 1970-9999
+//	types.NullType    JSON null
+//	types.Regex       {"$regularExpression": {"pattern": "<string>", "options": "<sorted string>"}}
+//	int32             {"$numberInt": "<number>"}; relaxed: JSON number
+//	types.Timestamp   {"$timestamp": {"t": <seconds>, "i": <increment>}}
+//	int64             {"$numberLong": "<number>"}; relaxed: JSON number
+//	types.Decimal128  {"$numberDecimal": "<canonical string>"}
+//
+// MinKey and MaxKey are not supported because there are no matching types in the `types` package.
+package ejson
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// Mode represents Extended JSON output mode.
+type Mode int
+
+const (
+	// Canonical mode preserves type information for all values.
+	Canonical Mode = iota
+
+	// Relaxed mode uses native JSON numbers and ISO-8601 dates where possible;
+	// some type information (for example, int32 vs int64) is lost.
+	Relaxed
+)
+
+// relaxedDateFormat is the format of dates in relaxed mode.
+const relaxedDateFormat = "2006-01-02T15:04:05.999Z07:00"
+
+// Marshal encodes given built-in or types' package value into Extended JSON using the given mode.
+func Marshal(v any, mode Mode) ([]byte, error) {
+	if v == nil {
+		panic("v is nil")
+	}
+
+	var buf bytes.Buffer
+	if err := marshal(&buf, v, mode); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// marshal appends Extended JSON encoding of the given value to buf.
+func marshal(buf *bytes.Buffer, v any, mode Mode) error {
+	switch v := v.(type) {
+	case *types.Document:
+		buf.WriteByte('{')
+
+		for i, key := range v.Keys() {
+			if i != 0 {
+				buf.WriteByte(',')
+			}
+
+			writeString(buf, key)
+			buf.WriteByte(':')
+
+			if err := marshal(buf, must.NotFail(v.Get(key)), mode); err != nil {
+				return lazyerrors.Error(err)
+			}
+		}
+
+		buf.WriteByte('}')
+
+	case *types.Array:
+		buf.WriteByte('[')
+
+		iter := v.Iterator()
+		for {
+			i, value, ok := iter.Next()
+			if !ok {
+				break
+			}
+
+			if i != 0 {
+				buf.WriteByte(',')
+			}
+
+			if err := marshal(buf, value, mode); err != nil {
+				return lazyerrors.Error(err)
+			}
+		}
+
+		buf.WriteByte(']')
+
+	case float64:
+		s := formatDouble(v)
+		if mode == Relaxed && !math.IsInf(v, 0) && !math.IsNaN(v) {
+			buf.WriteString(s)
+			break
+		}
+
+		buf.WriteString(`{"$numberDouble":`)
+		writeString(buf, s)
+		buf.WriteByte('}')
+
+	case string:
+		writeString(buf, v)
+
+	case types.Binary:
+		buf.WriteString(`{"$binary":{"base64":`)
+		writeString(buf, base64.StdEncoding.EncodeToString(v.B))
+		fmt.Fprintf(buf, `,"subType":"%02x"}}`, byte(v.Subtype))
+
+	case types.ObjectID:
+		buf.WriteString(`{"$oid":"`)
+		buf.WriteString(hex.EncodeToString(v[:]))
+		buf.WriteString(`"}`)
+
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+
+	case time.Time:
+		v = v.UTC()
+		if mode == Relaxed && v.Year() >= 1970 && v.Year() <= 9999 {
+			buf.WriteString(`{"$date":`)
+			writeString(buf, v.Format(relaxedDateFormat))
+			buf.WriteByte('}')
+
+			break
+		}
+
+		buf.WriteString(`{"$date":{"$numberLong":"`)
+		buf.WriteString(strconv.FormatInt(v.UnixMilli(), 10))
+		buf.WriteString(`"}}`)
+
+	case types.NullType:
+		buf.WriteString("null")
+
+	case types.Regex:
+		options := []rune(v.Options)
+		sort.Slice(options, func(i, j int) bool { return options[i] < options[j] })
+
+		buf.WriteString(`{"$regularExpression":{"pattern":`)
+		writeString(buf, v.Pattern)
+		buf.WriteString(`,"options":`)
+		writeString(buf, string(options))
+		buf.WriteString(`}}`)
+
+	case int32:
+		if mode == Relaxed {
+			buf.WriteString(strconv.FormatInt(int64(v), 10))
+			break
+		}
+
+		buf.WriteString(`{"$numberInt":"`)
+		buf.WriteString(strconv.FormatInt(int64(v), 10))
+		buf.WriteString(`"}`)
+
+	case types.Timestamp:
+		fmt.Fprintf(buf, `{"$timestamp":{"t":%d,"i":%d}}`, uint32(uint64(v)>>32), uint32(v))
+
+	case int64:
+		if mode == Relaxed {
+			buf.WriteString(strconv.FormatInt(v, 10))
+			break
+		}
+
+		buf.WriteString(`{"$numberLong":"`)
+		buf.WriteString(strconv.FormatInt(v, 10))
+		buf.WriteString(`"}`)
+
+	case types.Decimal128:
+		buf.WriteString(`{"$numberDecimal":"`)
+		buf.WriteString(v.String())
+		buf.WriteString(`"}`)
+
+	default:
+		return lazyerrors.Errorf("ejson.marshal: unsupported type %T", v)
+	}
+
+	return nil
+}
+
+// formatDouble returns finite float64 value in the shortest form that is always parsed as double,
+// and special values as "Infinity", "-Infinity", or "NaN".
+func formatDouble(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	case math.IsNaN(f):
+		return "NaN"
+	}
+
+	s := strconv.FormatFloat(f, 'G', -1, 64)
+	if !strings.ContainsAny(s, ".E") {
+		s += ".0"
+	}
+
+	return s
+}
+
+// writeString writes the given string as JSON string to buf, without HTML escaping.
+func writeString(buf *bytes.Buffer, s string) {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	must.NoError(enc.Encode(s))
+
+	// remove newline added by Encode
+	buf.Truncate(buf.Len() - 1)
+}
@@ -0,0 +1,333 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ejson
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/bson"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+// corpusTestCase represents a valid test case from BSON corpus.
+//
+// See https://github.com/mongodb/specifications/tree/master/source/bson-corpus.
+type corpusTestCase struct {
+	name      string
+	bson      string // canonical BSON as hex string
+	canonical string // canonical Extended JSON
+	relaxed   string // relaxed Extended JSON; empty if the same as canonical
+}
+
+var corpusTestCases = []corpusTestCase{{
+	name:      "Int32/MinValue",
+	bson:      "0C0000001069000000008000",
+	canonical: `{"i" : {"$numberInt": "-2147483648"}}`,
+	relaxed:   `{"i" : -2147483648}`,
+}, {
+	name:      "Int32/One",
+	bson:      "0C0000001069000100000000",
+	canonical: `{"i" : {"$numberInt": "1"}}`,
+	relaxed:   `{"i" : 1}`,
+}, {
+	name:      "Int64/MinValue",
+	bson:      "10000000126100000000000000008000",
+	canonical: `{"a" : {"$numberLong" : "-9223372036854775808"}}`,
+	relaxed:   `{"a" : -9223372036854775808}`,
+}, {
+	name:      "Int64/One",
+	bson:      "10000000126100010000000000000000",
+	canonical: `{"a" : {"$numberLong" : "1"}}`,
+	relaxed:   `{"a" : 1}`,
+}, {
+	name:      "Double/One",
+	bson:      "10000000016400000000000000F03F00",
+	canonical: `{"d" : {"$numberDouble": "1.0"}}`,
+	relaxed:   `{"d" : 1.0}`,
+}, {
+	name:      "Double/NegativeZero",
+	bson:      "10000000016400000000000000008000",
+	canonical: `{"d" : {"$numberDouble": "-0.0"}}`,
+	relaxed:   `{"d" : -0.0}`,
+}, {
+	name:      "Double/Fraction",
+	bson:      "10000000016400000000008000F03F00",
+	canonical: `{"d" : {"$numberDouble": "1.0001220703125"}}`,
+	relaxed:   `{"d" : 1.0001220703125}`,
+}, {
+	name:      "Double/Exponent",
+	bson:      "100000000164002A1BF5F41022B14300",
+	canonical: `{"d" : {"$numberDouble": "1.2345678921232E+18"}}`,
+	relaxed:   `{"d" : 1.2345678921232E+18}`,
+}, {
+	name:      "Double/Infinity",
+	bson:      "10000000016400000000000000F07F00",
+	canonical: `{"d" : {"$numberDouble": "Infinity"}}`,
+}, {
+	name:      "String",
+	bson:      "10000000026100040000006162630000",
+	canonical: `{"a" : "abc"}`,
+}, {
+	name:      "Binary/Generic",
+	bson:      "0F0000000578000200000000FFFF00",
+	canonical: `{"x" : { "$binary" : {"base64" : "//8=", "subType" : "00"}}}`,
+}, {
+	name:      "Binary/UUID",
+	bson:      "1D000000057800100000000473FFD26444B34C6990E8E7D1DFC035D400",
+	canonical: `{"x" : { "$binary" : {"base64" : "c//SZESzTGmQ6OfR38A11A==", "subType" : "04"}}}`,
+}, {
+	name:      "Binary/User",
+	bson:      "0F0000000578000200000080FFFF00",
+	canonical: `{"x" : { "$binary" : {"base64" : "//8=", "subType" : "80"}}}`,
+}, {
+	name:      "DateTime/Epoch",
+	bson:      "10000000096100000000000000000000",
+	canonical: `{"a" : {"$date" : {"$numberLong" : "0"}}}`,
+	relaxed:   `{"a" : {"$date" : "1970-01-01T00:00:00Z"}}`,
+}, {
+	name:      "DateTime/Positive",
+	bson:      "10000000096100C5D8D6CC3B01000000",
+	canonical: `{"a" : {"$date" : {"$numberLong" : "1356351330501"}}}`,
+	relaxed:   `{"a" : {"$date" : "2012-12-24T12:15:30.501Z"}}`,
+}, {
+	name:      "DateTime/Negative",
+	bson:      "10000000096100C33CE7B9BDFFFFFF00",
+	canonical: `{"a" : {"$date" : {"$numberLong" : "-284643869501"}}}`,
+}, {
+	name:      "DateTime/Y10K",
+	bson:      "1000000009610000DC1FD277E6000000",
+	canonical: `{"a":{"$date":{"$numberLong":"253402300800000"}}}`,
+}, {
+	name:      "Null",
+	bson:      "080000000A610000",
+	canonical: `{"a" : null}`,
+}, {
+	name:      "Regex",
+	bson:      "0F0000000B610061626300696D0000",
+	canonical: `{"a" : {"$regularExpression" : { "pattern": "abc", "options" : "im"}}}`,
+}, {
+	name:      "Timestamp",
+	bson:      "100000001161002A00000015CD5B0700",
+	canonical: `{"a" : {"$timestamp" : {"t" : 123456789, "i" : 42} } }`,
+}, {
+	name:      "Timestamp/HighBits",
+	bson:      "10000000116100FFFFFFFFFFFFFFFF00",
+	canonical: `{"a" : {"$timestamp" : {"t" : 4294967295, "i" : 4294967295} } }`,
+}, {
+	name:      "ObjectID",
+	bson:      "1400000007610056E1FC72E0C917E9C471416100",
+	canonical: `{"a" : {"$oid" : "56e1fc72e0c917e9c4714161"}}`,
+}, {
+	name:      "Boolean",
+	bson:      "090000000862000100",
+	canonical: `{"b" : true}`,
+}, {
+	name:      "Decimal128",
+	bson:      "180000001364000A000000000000000000000000003E3000",
+	canonical: `{"d" : {"$numberDecimal" : "1.0"}}`,
+}, {
+	name:      "Array",
+	bson:      "1B0000000461001300000010300001000000103100020000000000",
+	canonical: `{"a" : [{"$numberInt": "1"}, {"$numberInt": "2"}]}`,
+	relaxed:   `{"a" : [1, 2]}`,
+}, {
+	name:      "Document",
+	bson:      "160000000378000E0000000261000200000062000000",
+	canonical: `{"x" : {"a" : "b"}}`,
+}}
+
+// compact returns compacted JSON.
+func compact(t testing.TB, s string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	require.NoError(t, json.Compact(&buf, []byte(s)))
+
+	return buf.String()
+}
+
+// readBSON decodes BSON document.
+func readBSON(t testing.TB, b []byte) *types.Document {
+	t.Helper()
+
+	var doc bson.Document
+	require.NoError(t, doc.ReadFrom(bufio.NewReader(bytes.NewReader(b))))
+
+	return must.NotFail(types.ConvertDocument(&doc))
+}
+
+func TestCorpus(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range corpusTestCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			b := must.NotFail(hex.DecodeString(tc.bson))
+			canonical := compact(t, tc.canonical)
+			relaxed := canonical
+			if tc.relaxed != "" {
+				relaxed = compact(t, tc.relaxed)
+			}
+
+			doc := readBSON(t, b)
+
+			t.Run("Canonical", func(t *testing.T) {
+				t.Parallel()
+
+				actual, err := Marshal(doc, Canonical)
+				require.NoError(t, err)
+				assert.Equal(t, canonical, string(actual))
+
+				v, err := Unmarshal([]byte(tc.canonical))
+				require.NoError(t, err)
+				testutil.AssertEqual(t, doc, v.(*types.Document))
+
+				actualB, err := bson.MustConvertDocument(v.(*types.Document)).MarshalBinary()
+				require.NoError(t, err)
+				assert.Equal(t, b, actualB)
+			})
+
+			t.Run("Relaxed", func(t *testing.T) {
+				t.Parallel()
+
+				actual, err := Marshal(doc, Relaxed)
+				require.NoError(t, err)
+				assert.Equal(t, relaxed, string(actual))
+
+				// relaxed mode loses some type information, so only JSON is round-tripped
+				v, err := Unmarshal([]byte(tc.relaxed))
+				if tc.relaxed == "" {
+					v, err = Unmarshal([]byte(tc.canonical))
+				}
+				require.NoError(t, err)
+
+				actual, err = Marshal(v, Relaxed)
+				require.NoError(t, err)
+				assert.Equal(t, relaxed, string(actual))
+			})
+		})
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	t.Parallel()
+
+	for name, data := range map[string]string{
+		"Empty":              ``,
+		"Trailing":           `{} {}`,
+		"Invalid":            `{"a":}`,
+		"DuplicateKey":       `{"a": 1, "a": 2}`,
+		"NumberIntType":      `{"a": {"$numberInt": 1}}`,
+		"NumberIntOverflow":  `{"a": {"$numberInt": "2147483648"}}`,
+		"NumberLongInvalid":  `{"a": {"$numberLong": "foo"}}`,
+		"OIDShort":           `{"a": {"$oid": "56e1fc72"}}`,
+		"OIDExtraKey":        `{"a": {"$oid": "56e1fc72e0c917e9c4714161", "b": 1}}`,
+		"BinaryMissing":      `{"x": {"$binary": {"base64": "//8="}}}`,
+		"BinarySubType":      `{"x": {"$binary": {"base64": "//8=", "subType": "100"}}}`,
+		"DateInvalid":        `{"a": {"$date": "yesterday"}}`,
+		"RegexType":          `{"a": {"$regularExpression": {"pattern": 1, "options": ""}}}`,
+		"TimestampNegative":  `{"a": {"$timestamp": {"t": -1, "i": 0}}}`,
+		"TimestampOverflow":  `{"a": {"$timestamp": {"t": 4294967296, "i": 0}}}`,
+		"DecimalInvalid":     `{"a": {"$numberDecimal": "foo"}}`,
+		"MinKeyNotSupported": `{"a": {"$minKey": 1}}`,
+	} {
+		name, data := name, data
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := Unmarshal([]byte(data))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestUnmarshalRelaxed(t *testing.T) {
+	t.Parallel()
+
+	v, err := Unmarshal([]byte(`{"i": 42, "l": 4294967296, "d": 42.0, "e": 1e3, "$db": "test"}`))
+	require.NoError(t, err)
+
+	expected := must.NotFail(types.NewDocument(
+		"i", int32(42),
+		"l", int64(4294967296),
+		"d", 42.0,
+		"e", 1000.0,
+		"$db", "test",
+	))
+	testutil.AssertEqual(t, expected, v.(*types.Document))
+}
+
+func TestMarshalNoHTMLEscaping(t *testing.T) {
+	t.Parallel()
+
+	actual, err := Marshal(must.NotFail(types.NewDocument("<a>", "b&c")), Canonical)
+	require.NoError(t, err)
+	assert.Equal(t, `{"<a>":"b&c"}`, string(actual))
+}
+
+func FuzzCanonical(f *testing.F) {
+	for _, tc := range corpusTestCases {
+		f.Add(must.NotFail(hex.DecodeString(tc.bson)))
+	}
+
+	for _, name := range []string{"all.hex", "handshake1.hex", "handshake2.hex"} {
+		f.Add(testutil.MustParseDumpFile("..", "bson", "testdata", name))
+	}
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		t.Parallel()
+
+		var bdoc bson.Document
+		if err := bdoc.ReadFrom(bufio.NewReader(bytes.NewReader(b))); err != nil {
+			t.Skip()
+		}
+
+		doc, err := types.ConvertDocument(&bdoc)
+		if err != nil {
+			t.Skip()
+		}
+
+		for _, mode := range []Mode{Canonical, Relaxed} {
+			j, err := Marshal(doc, mode)
+			require.NoError(t, err)
+			require.True(t, json.Valid(j), "%s", j)
+
+			v, err := Unmarshal(j)
+			if err != nil && strings.Contains(err.Error(), "invalid key") {
+				// keys like "$a" could not be stored in the document
+				t.Skip()
+			}
+			require.NoError(t, err, "%s", j)
+
+			// Extended JSON does not preserve NaN payloads and regex options order,
+			// so compare JSON representations
+			actualJ, err := Marshal(v, mode)
+			require.NoError(t, err)
+			assert.Equal(t, string(j), string(actualJ))
+		}
+	})
+}
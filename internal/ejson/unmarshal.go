@@ -0,0 +1,369 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ejson
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// Unmarshal decodes the given Extended JSON data in either canonical or relaxed mode.
+//
+// JSON numbers without fraction and exponent are decoded as int32 or int64 (whichever fits),
+// other JSON numbers are decoded as float64.
+func Unmarshal(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	v, err := unmarshalValue(dec)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if _, err = dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, lazyerrors.Errorf("ejson.Unmarshal: unexpected data after the value")
+	}
+
+	return v, nil
+}
+
+// unmarshalValue decodes the next value from the decoder.
+func unmarshalValue(dec *json.Decoder) (any, error) {
+	t, err := dec.Token()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	switch t := t.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			return unmarshalObject(dec)
+		case '[':
+			return unmarshalArray(dec)
+		default:
+			return nil, lazyerrors.Errorf("ejson.unmarshalValue: unexpected delimiter %q", t)
+		}
+
+	case string:
+		return t, nil
+
+	case bool:
+		return t, nil
+
+	case nil:
+		return types.Null, nil
+
+	case json.Number:
+		return unmarshalNumber(string(t))
+
+	default:
+		return nil, lazyerrors.Errorf("ejson.unmarshalValue: unexpected token %[1]T (%[1]v)", t)
+	}
+}
+
+// unmarshalNumber decodes relaxed JSON number.
+func unmarshalNumber(s string) (any, error) {
+	if !strings.ContainsAny(s, ".eE") {
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			if i >= math.MinInt32 && i <= math.MaxInt32 {
+				return int32(i), nil
+			}
+
+			return i, nil
+		}
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return f, nil
+}
+
+// unmarshalArray decodes array after the opening bracket.
+func unmarshalArray(dec *json.Decoder) (*types.Array, error) {
+	arr := types.MakeArray(0)
+
+	for dec.More() {
+		v, err := unmarshalValue(dec)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if err = arr.Append(v); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return arr, nil
+}
+
+// unmarshalObject decodes object after the opening brace.
+// It returns a document or a scalar value for objects with type wrappers like {"$oid": "..."}.
+func unmarshalObject(dec *json.Decoder) (any, error) {
+	var keys []string
+	var values []any
+
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		key, ok := t.(string)
+		if !ok {
+			return nil, lazyerrors.Errorf("ejson.unmarshalObject: unexpected key %[1]T (%[1]v)", t)
+		}
+
+		v, err := unmarshalValue(dec)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		keys = append(keys, key)
+		values = append(values, v)
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if len(keys) > 0 && strings.HasPrefix(keys[0], "$") {
+		v, ok, err := unmarshalWrapper(keys, values)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if ok {
+			return v, nil
+		}
+	}
+
+	doc := must.NotFail(types.NewDocument())
+	for i, key := range keys {
+		if doc.Has(key) {
+			return nil, lazyerrors.Errorf("ejson.unmarshalObject: duplicate key %q", key)
+		}
+
+		if err := doc.Set(key, values[i]); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	return doc, nil
+}
+
+// unmarshalWrapper decodes the type wrapper object with given keys and values.
+//
+// It returns false if the first key is not a known type wrapper.
+func unmarshalWrapper(keys []string, values []any) (any, bool, error) {
+	key := keys[0]
+
+	switch key {
+	case "$numberDouble", "$binary", "$oid", "$date", "$regularExpression",
+		"$numberInt", "$timestamp", "$numberLong", "$numberDecimal":
+		// handled below
+	case "$minKey", "$maxKey":
+		return nil, false, lazyerrors.Errorf("ejson.unmarshalWrapper: %s is not supported", key)
+	default:
+		return nil, false, nil
+	}
+
+	if len(keys) != 1 {
+		return nil, false, lazyerrors.Errorf("ejson.unmarshalWrapper: %s: unexpected keys %q", key, keys)
+	}
+
+	v, err := unmarshalWrapperValue(key, values[0])
+	if err != nil {
+		return nil, false, lazyerrors.Errorf("ejson.unmarshalWrapper: %s: %w", key, err)
+	}
+
+	return v, true, nil
+}
+
+// unmarshalWrapperValue converts the value of the type wrapper with the given key.
+func unmarshalWrapperValue(key string, value any) (any, error) {
+	if key == "$numberDouble" || key == "$oid" || key == "$numberInt" || key == "$numberLong" || key == "$numberDecimal" {
+		s, ok := value.(string)
+		if !ok {
+			return nil, lazyerrors.Errorf("expected string, got %T", value)
+		}
+
+		switch key {
+		case "$numberDouble":
+			switch s {
+			case "Infinity":
+				return math.Inf(1), nil
+			case "-Infinity":
+				return math.Inf(-1), nil
+			case "NaN":
+				return math.NaN(), nil
+			}
+
+			return strconv.ParseFloat(s, 64)
+
+		case "$oid":
+			var id types.ObjectID
+			if len(s) != hex.EncodedLen(len(id)) {
+				return nil, lazyerrors.Errorf("invalid ObjectID %q", s)
+			}
+
+			if _, err := hex.Decode(id[:], []byte(s)); err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+
+			return id, nil
+
+		case "$numberInt":
+			i, err := strconv.ParseInt(s, 10, 32)
+			if err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+
+			return int32(i), nil
+
+		case "$numberLong":
+			return strconv.ParseInt(s, 10, 64)
+
+		case "$numberDecimal":
+			return types.ParseDecimal128(s)
+		}
+	}
+
+	switch key {
+	case "$date":
+		switch value := value.(type) {
+		case string:
+			t, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+
+			return time.UnixMilli(t.UnixMilli()).UTC(), nil
+
+		case int64:
+			return time.UnixMilli(value).UTC(), nil
+
+		default:
+			return nil, lazyerrors.Errorf("expected string or $numberLong, got %T", value)
+		}
+
+	case "$binary":
+		fields, err := wrapperFields(value, "base64", "subType")
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		s, ok1 := fields[0].(string)
+		st, ok2 := fields[1].(string)
+		if !ok1 || !ok2 || len(st) == 0 || len(st) > 2 {
+			return nil, lazyerrors.Errorf("invalid fields %v", fields)
+		}
+
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		subtype, err := strconv.ParseUint(st, 16, 8)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		return types.Binary{Subtype: types.BinarySubtype(subtype), B: b}, nil
+
+	case "$regularExpression":
+		fields, err := wrapperFields(value, "pattern", "options")
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		pattern, ok1 := fields[0].(string)
+		options, ok2 := fields[1].(string)
+		if !ok1 || !ok2 {
+			return nil, lazyerrors.Errorf("invalid fields %v", fields)
+		}
+
+		return types.Regex{Pattern: pattern, Options: options}, nil
+
+	case "$timestamp":
+		fields, err := wrapperFields(value, "t", "i")
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		var ti [2]uint32
+		for n, f := range fields {
+			var i int64
+			switch f := f.(type) {
+			case int32:
+				i = int64(f)
+			case int64:
+				i = f
+			default:
+				return nil, lazyerrors.Errorf("invalid field %v", f)
+			}
+
+			if i < 0 || i > math.MaxUint32 {
+				return nil, lazyerrors.Errorf("invalid field %d", i)
+			}
+
+			ti[n] = uint32(i)
+		}
+
+		return types.Timestamp(uint64(ti[0])<<32 | uint64(ti[1])), nil
+	}
+
+	panic("not reached: " + key)
+}
+
+// wrapperFields returns values of the given fields of the wrapper document in the given order.
+// Document must contain exactly those fields, in any order.
+func wrapperFields(value any, fields ...string) ([]any, error) {
+	doc, ok := value.(*types.Document)
+	if !ok || doc.Len() != len(fields) {
+		return nil, lazyerrors.Errorf("expected document with fields %q, got %v", fields, value)
+	}
+
+	res := make([]any, len(fields))
+	for i, f := range fields {
+		v, err := doc.Get(f)
+		if err != nil {
+			return nil, lazyerrors.Errorf("expected document with fields %q, got %v", fields, value)
+		}
+
+		res[i] = v
+	}
+
+	return res, nil
+}
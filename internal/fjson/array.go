@@ -64,28 +64,10 @@ func (a *arrayType) UnmarshalJSON(data []byte) error {
 // MarshalJSON implements fjsontype interface.
 func (a *arrayType) MarshalJSON() ([]byte, error) {
 	var buf bytes.Buffer
-	buf.WriteByte('[')
-
-	ta := types.Array(*a)
-	l := ta.Len()
-	for i := 0; i < l; i++ {
-		if i != 0 {
-			buf.WriteByte(',')
-		}
-
-		el, err := ta.Get(i)
-		if err != nil {
-			return nil, lazyerrors.Error(err)
-		}
-		b, err := Marshal(el)
-		if err != nil {
-			return nil, lazyerrors.Error(err)
-		}
-
-		buf.Write(b)
+	if err := marshalArrayTo(&buf, (*types.Array)(a)); err != nil {
+		return nil, lazyerrors.Error(err)
 	}
 
-	buf.WriteByte(']')
 	return buf.Bytes(), nil
 }
 
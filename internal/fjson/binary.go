@@ -53,15 +53,26 @@ func (bin *binaryType) UnmarshalJSON(data []byte) error {
 		return lazyerrors.Error(err)
 	}
 
+	// keep zero-length payloads non-nil, so they are equal to values decoded from BSON
 	bin.B = o.B
+	if bin.B == nil {
+		bin.B = []byte{}
+	}
+
 	bin.Subtype = types.BinarySubtype(o.S)
 	return nil
 }
 
 // MarshalJSON implements fjsontype interface.
 func (bin *binaryType) MarshalJSON() ([]byte, error) {
+	b := bin.B
+	if b == nil {
+		// nil would be encoded as null, losing the value and its subtype
+		b = []byte{}
+	}
+
 	res, err := json.Marshal(binaryJSON{
-		B: bin.B,
+		B: b,
 		S: byte(bin.Subtype),
 	})
 	if err != nil {
@@ -80,43 +80,11 @@ func (doc *documentType) UnmarshalJSON(data []byte) error {
 
 // MarshalJSON implements fjsontype interface.
 func (doc *documentType) MarshalJSON() ([]byte, error) {
-	td := types.Document(*doc)
-
 	var buf bytes.Buffer
-
-	buf.WriteString(`{"$k":`)
-	keys := td.Keys()
-	if keys == nil {
-		keys = []string{}
-	}
-	b, err := json.Marshal(keys)
-	if err != nil {
+	if err := marshalDocumentTo(&buf, (*types.Document)(doc)); err != nil {
 		return nil, lazyerrors.Error(err)
 	}
-	buf.Write(b)
-
-	for _, key := range keys {
-		buf.WriteByte(',')
-
-		if b, err = json.Marshal(key); err != nil {
-			return nil, lazyerrors.Error(err)
-		}
-		buf.Write(b)
-		buf.WriteByte(':')
-
-		value, err := td.Get(key)
-		if err != nil {
-			return nil, lazyerrors.Error(err)
-		}
-		b, err := Marshal(value)
-		if err != nil {
-			return nil, lazyerrors.Error(err)
-		}
-
-		buf.Write(b)
-	}
 
-	buf.WriteByte('}')
 	return buf.Bytes(), nil
 }
 
@@ -36,7 +36,7 @@
 //	int32            JSON number
 //	types.Timestamp  {"$t": "<number as string>"}
 //	int64            {"$l": "<number as string>"}
-//	TODO Decimal128  {"$n": "<number as string>"}
+//	types.Decimal128 {"$n": "<number as canonical string>"}
 package fjson
 
 import (
@@ -106,6 +106,8 @@ func fromFJSON(v fjsontype) any {
 		return types.Timestamp(*v)
 	case *int64Type:
 		return int64(*v)
+	case *decimal128Type:
+		return types.Decimal128(*v)
 	}
 
 	panic(fmt.Sprintf("not reached: %T", v)) // for go-sumtype to work
@@ -140,6 +142,8 @@ func toFJSON(v any) fjsontype {
 		return pointer.To(timestampType(v))
 	case int64:
 		return pointer.To(int64Type(v))
+	case types.Decimal128:
+		return pointer.To(decimal128Type(v))
 	}
 
 	panic(fmt.Sprintf("not reached: %T", v)) // for go-sumtype to work
@@ -194,6 +198,10 @@ func Unmarshal(data []byte) (any, error) {
 			var o int64Type
 			err = o.UnmarshalJSON(data)
 			res = &o
+		case v["$n"] != nil:
+			var o decimal128Type
+			err = o.UnmarshalJSON(data)
+			res = &o
 		default:
 			err = lazyerrors.Errorf("fjson.Unmarshal: unhandled map %v", v)
 		}
@@ -226,10 +234,37 @@ func Marshal(v any) ([]byte, error) {
 		panic("v is nil")
 	}
 
-	b, err := toFJSON(v).MarshalJSON()
-	if err != nil {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	defer func() {
+		if buf.Cap() <= maxPooledBufferSize {
+			bufferPool.Put(buf)
+		}
+	}()
+
+	buf.Reset()
+
+	if err := marshalTo(buf, v); err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
+	b := make([]byte, buf.Len())
+	copy(b, buf.Bytes())
+
 	return b, nil
 }
+
+// MarshalTo appends fjson encoding of given built-in or types' package value to buf.
+//
+// The output is the same as for Marshal.
+// It allows callers to reuse the same buffer for many values.
+func MarshalTo(buf *bytes.Buffer, v any) error {
+	if v == nil {
+		panic("v is nil")
+	}
+
+	if err := marshalTo(buf, v); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
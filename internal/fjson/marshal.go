@@ -0,0 +1,241 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fjson
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// maxPooledBufferSize is the maximal capacity of buffers that are returned to bufferPool.
+const maxPooledBufferSize = 16 * 1024 * 1024
+
+// bufferPool contains buffers reused by Marshal.
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// marshalTo appends fjson encoding of the given value to buf.
+//
+// Composite types and the most common scalar types are written directly;
+// other types are encoded by their fjsontype's MarshalJSON.
+// In both cases, the output is the same.
+func marshalTo(buf *bytes.Buffer, v any) error {
+	// scratch space for numbers; it does not escape
+	var scratch [32]byte
+
+	switch v := v.(type) {
+	case *types.Document:
+		return marshalDocumentTo(buf, v)
+
+	case *types.Array:
+		return marshalArrayTo(buf, v)
+
+	case float64:
+		switch {
+		case v == 0 && math.Signbit(v):
+			buf.WriteString(`{"$f":"-0"}`)
+		case math.IsInf(v, 1):
+			buf.WriteString(`{"$f":"Infinity"}`)
+		case math.IsInf(v, -1):
+			buf.WriteString(`{"$f":"-Infinity"}`)
+		case math.IsNaN(v):
+			buf.WriteString(`{"$f":"NaN"}`)
+		default:
+			buf.WriteString(`{"$f":`)
+			buf.Write(appendFloat(scratch[:0], v))
+			buf.WriteByte('}')
+		}
+
+	case string:
+		writeString(buf, v)
+
+	case types.ObjectID:
+		buf.WriteString(`{"$o":"`)
+		n := hex.Encode(scratch[:], v[:])
+		buf.Write(scratch[:n])
+		buf.WriteString(`"}`)
+
+	case bool:
+		if v {
+			buf.WriteString("true")
+		} else {
+			buf.WriteString("false")
+		}
+
+	case time.Time:
+		buf.WriteString(`{"$d":`)
+		buf.Write(strconv.AppendInt(scratch[:0], v.UnixMilli(), 10))
+		buf.WriteByte('}')
+
+	case types.NullType:
+		buf.WriteString("null")
+
+	case int32:
+		buf.Write(strconv.AppendInt(scratch[:0], int64(v), 10))
+
+	case int64:
+		buf.WriteString(`{"$l":"`)
+		buf.Write(strconv.AppendInt(scratch[:0], v, 10))
+		buf.WriteString(`"}`)
+
+	default:
+		b, err := toFJSON(v).MarshalJSON()
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		buf.Write(b)
+	}
+
+	return nil
+}
+
+// marshalDocumentTo appends fjson encoding of the given document to buf.
+func marshalDocumentTo(buf *bytes.Buffer, doc *types.Document) error {
+	keys := doc.Keys()
+
+	buf.WriteString(`{"$k":[`)
+
+	for i, key := range keys {
+		if i != 0 {
+			buf.WriteByte(',')
+		}
+
+		writeString(buf, key)
+	}
+
+	buf.WriteByte(']')
+
+	for _, key := range keys {
+		buf.WriteByte(',')
+		writeString(buf, key)
+		buf.WriteByte(':')
+
+		value, err := doc.Get(key)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if err = marshalTo(buf, value); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	buf.WriteByte('}')
+
+	return nil
+}
+
+// marshalArrayTo appends fjson encoding of the given array to buf.
+func marshalArrayTo(buf *bytes.Buffer, arr *types.Array) error {
+	buf.WriteByte('[')
+
+	iter := arr.Iterator()
+	for {
+		i, v, ok := iter.Next()
+		if !ok {
+			break
+		}
+
+		if i != 0 {
+			buf.WriteByte(',')
+		}
+
+		if err := marshalTo(buf, v); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	buf.WriteByte(']')
+
+	return nil
+}
+
+// appendFloat appends finite float64 value as JSON number to b, exactly as encoding/json does.
+func appendFloat(b []byte, f float64) []byte {
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+
+	b = strconv.AppendFloat(b, f, format, -1, 64)
+
+	if format == 'e' {
+		// clean up e-09 to e-9
+		if n := len(b); n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+
+	return b
+}
+
+// writeString writes the given string as JSON string to buf, exactly as encoding/json does.
+func writeString(buf *bytes.Buffer, s string) {
+	if !isPlainString(s) {
+		// it is not worth reimplementing all encoding/json escaping rules
+		// (that also were changed between Go versions) for rare strings
+		b, err := json.Marshal(s)
+		if err != nil {
+			panic(err) // strings are always marshaled
+		}
+
+		buf.Write(b)
+
+		return
+	}
+
+	buf.WriteByte('"')
+	buf.WriteString(s)
+	buf.WriteByte('"')
+}
+
+// isPlainString returns true if the given string is encoded by encoding/json (with HTML escaping) as is.
+func isPlainString(s string) bool {
+	for i := 0; i < len(s); {
+		c := s[i]
+
+		if c < utf8.RuneSelf {
+			switch {
+			case c < 0x20, c == '"', c == '\\', c == '<', c == '>', c == '&':
+				return false
+			}
+
+			i++
+
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if (r == utf8.RuneError && size == 1) || r == '\u2028' || r == '\u2029' {
+			return false
+		}
+
+		i += size
+	}
+
+	return true
+}
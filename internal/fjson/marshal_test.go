@@ -0,0 +1,225 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// goldenDocument returns a document with values of all types and strings that require escaping.
+func goldenDocument() *types.Document {
+	return must.NotFail(types.NewDocument(
+		"_id", types.ObjectID{0x62, 0x56, 0xc5, 0xba, 0x0b, 0xad, 0xc0, 0xff, 0xee, 0xff, 0xff, 0xff},
+		"double", 42.13,
+		"double-zero", 0.0,
+		"double-negative-zero", math.Copysign(0, -1),
+		"double-max", math.MaxFloat64,
+		"double-smallest", math.SmallestNonzeroFloat64,
+		"double-big", 1e21,
+		"double-inf", math.Inf(1),
+		"double-neg-inf", math.Inf(-1),
+		"double-nan", math.NaN(),
+		"string", "foo",
+		"string-empty", "",
+		"string-escape", "\"\\/\n\r\t\x00\x1f",
+		"string-html", "<a href=\"x\">&</a>",
+		"string-unicode", "привет, 世界 \u2028\u2029",
+		"string-invalid", "\xff\xfe",
+		"key with \"quotes\" & <html>", true,
+		"document", must.NotFail(types.NewDocument(
+			"foo", int32(1),
+			"bar", must.NotFail(types.NewDocument()),
+		)),
+		"array", must.NotFail(types.NewArray(
+			int32(1), "two", must.NotFail(types.NewArray()), must.NotFail(types.NewDocument("three", int64(3))),
+		)),
+		"binary", types.Binary{Subtype: types.BinaryUser, B: []byte{0x42}},
+		"binary-empty", types.Binary{B: []byte{}},
+		"bool-true", true,
+		"bool-false", false,
+		"datetime", time.Date(2021, 11, 1, 10, 18, 42, 123000000, time.UTC),
+		"null", types.Null,
+		"regex", types.Regex{Pattern: "^foo<bar>$", Options: "i"},
+		"int32", int32(42),
+		"int32-min", int32(math.MinInt32),
+		"timestamp", types.Timestamp(42),
+		"int64", int64(42),
+		"int64-min", int64(math.MinInt64),
+		"decimal", must.NotFail(types.ParseDecimal128("42.13")),
+	))
+}
+
+// goldenJSON is the expected fjson encoding of goldenDocument.
+// It must not change, because it is stored in PostgreSQL.
+const goldenJSON = `{"$k":["_id","double","double-zero","double-negative-zero","double-max","double-smallest","double-bi` +
+	`g","double-inf","double-neg-inf","double-nan","string","string-empty","string-escape","string-html",` +
+	`"string-unicode","string-invalid","key with \"quotes\" \u0026 \u003chtml\u003e","document","array","` +
+	`binary","binary-empty","bool-true","bool-false","datetime","null","regex","int32","int32-min","times` +
+	`tamp","int64","int64-min","decimal"],"_id":{"$o":"6256c5ba0badc0ffeeffffff"},"double":{"$f":42.13},"` +
+	`double-zero":{"$f":0},"double-negative-zero":{"$f":"-0"},"double-max":{"$f":1.7976931348623157e+308}` +
+	`,"double-smallest":{"$f":5e-324},"double-big":{"$f":1e+21},"double-inf":{"$f":"Infinity"},"double-ne` +
+	`g-inf":{"$f":"-Infinity"},"double-nan":{"$f":"NaN"},"string":"foo","string-empty":"","string-escape"` +
+	`:"\"\\/\n\r\t\u0000\u001f","string-html":"\u003ca href=\"x\"\u003e\u0026\u003c/a\u003e","string-unic` +
+	`ode":"привет, 世界 \u2028\u2029","string-invalid":"��","key with \"quotes\" \u0026 \u003chtml\u003e":t` +
+	`rue,"document":{"$k":["foo","bar"],"foo":1,"bar":{"$k":[]}},"array":[1,"two",[],{"$k":["three"],"thr` +
+	`ee":{"$l":"3"}}],"binary":{"$b":"Qg==","s":128},"binary-empty":{"$b":"","s":0},"bool-true":true,"boo` +
+	`l-false":false,"datetime":{"$d":1635761922123},"null":null,"regex":{"$r":"^foo\u003cbar\u003e$","o":` +
+	`"i"},"int32":42,"int32-min":-2147483648,"timestamp":{"$t":"42"},"int64":{"$l":"42"},"int64-min":{"$l` +
+	`":"-9223372036854775808"},"decimal":{"$n":"42.13"}}`
+
+func TestMarshalGolden(t *testing.T) {
+	t.Parallel()
+
+	b, err := Marshal(goldenDocument())
+	require.NoError(t, err)
+	assert.Equal(t, goldenJSON, string(b))
+}
+
+func TestUnmarshalGolden(t *testing.T) {
+	t.Parallel()
+
+	v, err := Unmarshal([]byte(goldenJSON))
+	require.NoError(t, err)
+
+	doc := v.(*types.Document)
+
+	// -0, NaN and ±Infinity are stored as tagged strings and should be restored exactly
+	negZero := must.NotFail(doc.Get("double-negative-zero")).(float64)
+	assert.True(t, negZero == 0 && math.Signbit(negZero))
+	assert.True(t, math.IsInf(must.NotFail(doc.Get("double-inf")).(float64), +1))
+	assert.True(t, math.IsInf(must.NotFail(doc.Get("double-neg-inf")).(float64), -1))
+	assert.True(t, math.IsNaN(must.NotFail(doc.Get("double-nan")).(float64)))
+
+	b, err := Marshal(doc)
+	require.NoError(t, err)
+	assert.Equal(t, goldenJSON, string(b))
+}
+
+// benchmarkDocument returns a document with mixed types that is encoded to at least size bytes.
+func benchmarkDocument(size int) *types.Document {
+	doc := must.NotFail(types.NewDocument("_id", types.NewObjectID()))
+	step := size/1000 + 1
+
+	for i := 0; ; i++ {
+		var v any
+		switch i % 6 {
+		case 0:
+			v = "value of the field"
+		case 1:
+			v = int32(i)
+		case 2:
+			v = float64(i) / 3
+		case 3:
+			v = must.NotFail(types.NewArray(int64(i), true, types.Null))
+		case 4:
+			v = must.NotFail(types.NewDocument("nested", int32(i), "at", time.UnixMilli(int64(i)).UTC()))
+		case 5:
+			v = false
+		}
+
+		must.NoError(doc.Set(fmt.Sprintf("field%d", i), v))
+
+		if i%step == 0 && len(must.NotFail(Marshal(doc))) >= size {
+			return doc
+		}
+	}
+}
+
+func BenchmarkMarshal(b *testing.B) {
+	for name, size := range map[string]int{
+		"1KB": 1 << 10,
+		"1MB": 1 << 20,
+	} {
+		doc := benchmarkDocument(size)
+		expected := must.NotFail(Marshal(doc))
+
+		b.Run(name, func(b *testing.B) {
+			var actual []byte
+			var err error
+
+			b.ReportAllocs()
+			b.SetBytes(int64(len(expected)))
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				actual, err = Marshal(doc)
+			}
+
+			b.StopTimer()
+
+			require.NoError(b, err)
+			assert.Equal(b, expected, actual)
+		})
+	}
+}
+
+func TestMarshalTo(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	buf.WriteString("prefix")
+
+	require.NoError(t, MarshalTo(&buf, goldenDocument()))
+	assert.Equal(t, "prefix"+goldenJSON, buf.String())
+
+	buf.Reset()
+	require.NoError(t, MarshalTo(&buf, int32(42)))
+	assert.Equal(t, "42", buf.String())
+}
+
+func FuzzWriteString(f *testing.F) {
+	for _, s := range []string{"", "foo", "<&>", "\"\\", "\x00\x1f\x7f", "\b\f", "привет", "\u2028", "\xff"} {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		t.Parallel()
+
+		expected, err := json.Marshal(s)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		writeString(&buf, s)
+		assert.Equal(t, string(expected), buf.String())
+	})
+}
+
+func FuzzMarshalDouble(f *testing.F) {
+	for _, v := range []float64{0, 1, -1, 42.13, 1e-7, 1e20, 1e21, math.MaxFloat64, math.SmallestNonzeroFloat64} {
+		f.Add(v)
+	}
+
+	f.Fuzz(func(t *testing.T, v float64) {
+		t.Parallel()
+
+		expected, err := toFJSON(v).MarshalJSON()
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, MarshalTo(&buf, v))
+		assert.Equal(t, string(expected), buf.String())
+	})
+}
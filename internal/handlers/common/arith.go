@@ -0,0 +1,188 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// Arithmetic errors.
+var (
+	errUnexpectedLeftOpType  = fmt.Errorf("unexpected left operand type")
+	errUnexpectedRightOpType = fmt.Errorf("unexpected right operand type")
+	errLongExceeded          = fmt.Errorf("long exceeded")
+)
+
+// AddNumbers returns the sum of v1 and v2.
+//
+// It uses the same type promotion rules as MongoDB:
+//   - int32 + int32 is int32, or int64 if the result does not fit into int32;
+//   - int64 + int32 or int64 is int64; errLongExceeded is returned on overflow;
+//   - double + any number is double.
+//
+// Errors errUnexpectedLeftOpType and errUnexpectedRightOpType are returned for non-numeric values.
+// Decimal128 arithmetic is not implemented yet.
+func AddNumbers(v1, v2 any) (any, error) {
+	return arithmetic(v1, v2, addInt64, func(a, b float64) float64 { return a + b })
+}
+
+// MultiplyNumbers returns the product of v1 and v2.
+//
+// Type promotion rules and errors are the same as for AddNumbers.
+func MultiplyNumbers(v1, v2 any) (any, error) {
+	return arithmetic(v1, v2, multiplyInt64, func(a, b float64) float64 { return a * b })
+}
+
+// arithmetic applies intOp or floatOp to numbers v1 and v2 according to type promotion rules.
+//
+// intOp should return false on int64 overflow.
+func arithmetic(v1, v2 any, intOp func(a, b int64) (int64, bool), floatOp func(a, b float64) float64) (any, error) {
+	if !isNumber(v1) {
+		return nil, errUnexpectedLeftOpType
+	}
+	if !isNumber(v2) {
+		return nil, errUnexpectedRightOpType
+	}
+
+	_, d1 := v1.(types.Decimal128)
+	_, d2 := v2.(types.Decimal128)
+	if d1 || d2 {
+		return nil, NewErrorMsg(ErrNotImplemented, "Arithmetic on decimal values is not implemented yet")
+	}
+
+	_, f1 := v1.(float64)
+	_, f2 := v2.(float64)
+	if f1 || f2 {
+		return floatOp(toFloat64(v1), toFloat64(v2)), nil
+	}
+
+	res, ok := intOp(toInt64(v1), toInt64(v2))
+
+	_, l1 := v1.(int64)
+	_, l2 := v2.(int64)
+	if l1 || l2 {
+		if !ok {
+			return nil, errLongExceeded
+		}
+
+		return res, nil
+	}
+
+	// both values are int32, so the result can't overflow int64
+	if res < math.MinInt32 || res > math.MaxInt32 {
+		return res, nil
+	}
+
+	return int32(res), nil
+}
+
+// addInt64 returns a + b and false on overflow.
+func addInt64(a, b int64) (int64, bool) {
+	res := a + b
+
+	// overflow is possible only for operands of the same sign, and it changes the sign of the result
+	return res, (a < 0) != (b < 0) || (res < 0) == (a < 0)
+}
+
+// multiplyInt64 returns a * b and false on overflow.
+func multiplyInt64(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+
+	if (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, false
+	}
+
+	res := a * b
+
+	return res, res/b == a
+}
+
+// isNumber returns true if v is a BSON number.
+func isNumber(v any) bool {
+	switch v.(type) {
+	case float64, int32, int64, types.Decimal128:
+		return true
+	default:
+		return false
+	}
+}
+
+// toFloat64 converts float64, int32 or int64 to float64.
+//
+// Large int64 values may lose precision.
+func toFloat64(v any) float64 {
+	switch v := v.(type) {
+	case float64:
+		return v
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	default:
+		panic(fmt.Sprintf("unexpected type %T", v))
+	}
+}
+
+// toInt64 converts int32 or int64 to int64.
+func toInt64(v any) int64 {
+	switch v := v.(type) {
+	case int32:
+		return int64(v)
+	case int64:
+		return v
+	default:
+		panic(fmt.Sprintf("unexpected type %T", v))
+	}
+}
+
+// isIdenticalNumber returns true if numbers have the same type and value.
+//
+// Like in MongoDB, -0 is identical to 0, and NaN is not identical to anything.
+// It is used to detect no-op arithmetic updates.
+func isIdenticalNumber(a, b any) bool {
+	switch a := a.(type) {
+	case float64:
+		b, ok := b.(float64)
+		return ok && a == b
+	case int32:
+		b, ok := b.(int32)
+		return ok && a == b
+	case int64:
+		b, ok := b.(int64)
+		return ok && a == b
+	default:
+		return false
+	}
+}
+
+// numberDebugString returns the number representation used by MongoDB in arithmetic errors,
+// for example, (NumberLong)42.
+func numberDebugString(v any) string {
+	switch v := v.(type) {
+	case float64:
+		return fmt.Sprintf("(NumberDouble)%v", v)
+	case int32:
+		return fmt.Sprintf("(NumberInt)%d", v)
+	case int64:
+		return fmt.Sprintf("(NumberLong)%d", v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
@@ -0,0 +1,383 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// arithmeticTestCase describes AddNumbers or MultiplyNumbers test case.
+type arithmeticTestCase struct {
+	v1, v2   any
+	expected any
+	err      error
+}
+
+// testArithmetic runs test cases for the given arithmetic function.
+func testArithmetic(t *testing.T, f func(v1, v2 any) (any, error), testCases map[string]arithmeticTestCase) {
+	t.Helper()
+
+	for name, tc := range testCases { //nolint:paralleltest // false positive
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			res, err := f(tc.v1, tc.v2)
+			if tc.err != nil {
+				assert.Equal(t, tc.err, err)
+				assert.Nil(t, res)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, res)
+
+			// the result should not depend on the order of operands
+			res, err = f(tc.v2, tc.v1)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, res)
+		})
+	}
+}
+
+func TestAddNumbers(t *testing.T) {
+	t.Parallel()
+
+	testArithmetic(t, AddNumbers, map[string]arithmeticTestCase{
+		"Int32": {
+			v1:       int32(40),
+			v2:       int32(2),
+			expected: int32(42),
+		},
+		"Int32Max": {
+			v1:       int32(math.MaxInt32 - 1),
+			v2:       int32(1),
+			expected: int32(math.MaxInt32),
+		},
+		"Int32MaxPlusOne": {
+			v1:       int32(math.MaxInt32),
+			v2:       int32(1),
+			expected: int64(math.MaxInt32 + 1),
+		},
+		"Int32MinMinusOne": {
+			v1:       int32(math.MinInt32),
+			v2:       int32(-1),
+			expected: int64(math.MinInt32 - 1),
+		},
+		"Int32MaxPlusInt32Max": {
+			v1:       int32(math.MaxInt32),
+			v2:       int32(math.MaxInt32),
+			expected: int64(2 * math.MaxInt32),
+		},
+		"Int32Int64": {
+			v1:       int32(40),
+			v2:       int64(2),
+			expected: int64(42),
+		},
+		"Int32Int64Small": {
+			v1:       int32(1),
+			v2:       int64(-1),
+			expected: int64(0),
+		},
+		"Int64Max": {
+			v1:       int64(math.MaxInt64 - 1),
+			v2:       int32(1),
+			expected: int64(math.MaxInt64),
+		},
+		"Int64MaxPlusOne": {
+			v1:  int64(math.MaxInt64),
+			v2:  int32(1),
+			err: errLongExceeded,
+		},
+		"Int64MinMinusOne": {
+			v1:  int64(math.MinInt64),
+			v2:  int64(-1),
+			err: errLongExceeded,
+		},
+		"Int64MaxPlusInt64Min": {
+			v1:       int64(math.MaxInt64),
+			v2:       int64(math.MinInt64),
+			expected: int64(-1),
+		},
+		"Double": {
+			v1:       40.5,
+			v2:       1.5,
+			expected: 42.0,
+		},
+		"DoubleInt32": {
+			v1:       41.5,
+			v2:       int32(1),
+			expected: 42.5,
+		},
+		"DoubleInt64": {
+			v1:       41.5,
+			v2:       int64(1),
+			expected: 42.5,
+		},
+		"DoubleInt64Max": {
+			v1:       1.0,
+			v2:       int64(math.MaxInt64),
+			expected: float64(math.MaxInt64),
+		},
+		"DoublePrecisionLoss": {
+			v1:       0.0,
+			v2:       int64(1<<53 + 1),
+			expected: float64(1 << 53),
+		},
+		"DoubleMax": {
+			v1:       math.MaxFloat64,
+			v2:       math.MaxFloat64,
+			expected: math.Inf(+1),
+		},
+	})
+}
+
+func TestMultiplyNumbers(t *testing.T) {
+	t.Parallel()
+
+	testArithmetic(t, MultiplyNumbers, map[string]arithmeticTestCase{
+		"Int32": {
+			v1:       int32(6),
+			v2:       int32(7),
+			expected: int32(42),
+		},
+		"Int32Zero": {
+			v1:       int32(0),
+			v2:       int32(math.MinInt32),
+			expected: int32(0),
+		},
+		"Int32MaxTimesTwo": {
+			v1:       int32(math.MaxInt32),
+			v2:       int32(2),
+			expected: int64(2 * math.MaxInt32),
+		},
+		"Int32MinTimesMinusOne": {
+			v1:       int32(math.MinInt32),
+			v2:       int32(-1),
+			expected: int64(-math.MinInt32),
+		},
+		"Int32MaxTimesInt32Max": {
+			v1:       int32(math.MaxInt32),
+			v2:       int32(math.MaxInt32),
+			expected: int64(math.MaxInt32 * math.MaxInt32),
+		},
+		"Int32Int64": {
+			v1:       int32(6),
+			v2:       int64(7),
+			expected: int64(42),
+		},
+		"Int32ZeroInt64": {
+			v1:       int32(0),
+			v2:       int64(math.MaxInt64),
+			expected: int64(0),
+		},
+		"Int64MaxTimesMinusOne": {
+			v1:       int64(math.MaxInt64),
+			v2:       int32(-1),
+			expected: int64(-math.MaxInt64),
+		},
+		"Int64MaxTimesTwo": {
+			v1:  int64(math.MaxInt64),
+			v2:  int32(2),
+			err: errLongExceeded,
+		},
+		"Int64MinTimesMinusOne": {
+			v1:  int64(math.MinInt64),
+			v2:  int64(-1),
+			err: errLongExceeded,
+		},
+		"Int64MinTimesOne": {
+			v1:       int64(math.MinInt64),
+			v2:       int32(1),
+			expected: int64(math.MinInt64),
+		},
+		"Int64Big": {
+			v1:  int64(1 << 32),
+			v2:  int64(1 << 31),
+			err: errLongExceeded,
+		},
+		"Double": {
+			v1:       2.5,
+			v2:       4.0,
+			expected: 10.0,
+		},
+		"DoubleInt32": {
+			v1:       1.5,
+			v2:       int32(2),
+			expected: 3.0,
+		},
+		"DoubleInt64Max": {
+			v1:       2.0,
+			v2:       int64(math.MaxInt64),
+			expected: 2 * float64(math.MaxInt64),
+		},
+		"DoublePrecisionLoss": {
+			v1:       1.0,
+			v2:       int64(1<<53 + 1),
+			expected: float64(1 << 53),
+		},
+	})
+}
+
+func TestArithmeticErrors(t *testing.T) {
+	t.Parallel()
+
+	decimal := must.NotFail(types.ParseDecimal128("42"))
+
+	for name, f := range map[string]func(v1, v2 any) (any, error){
+		"Add":      AddNumbers,
+		"Multiply": MultiplyNumbers,
+	} {
+		name, f := name, f
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := f("foo", int32(1))
+			assert.Equal(t, errUnexpectedLeftOpType, err)
+
+			_, err = f("foo", "bar")
+			assert.Equal(t, errUnexpectedLeftOpType, err)
+
+			_, err = f(int32(1), types.Null)
+			assert.Equal(t, errUnexpectedRightOpType, err)
+
+			_, err = f(decimal, int32(1))
+			assert.Equal(t, NewErrorMsg(ErrNotImplemented, "Arithmetic on decimal values is not implemented yet"), err)
+
+			_, err = f("foo", decimal)
+			assert.Equal(t, errUnexpectedLeftOpType, err)
+		})
+	}
+}
+
+func TestArithmeticSpecialDoubles(t *testing.T) {
+	t.Parallel()
+
+	res, err := AddNumbers(math.NaN(), int32(1))
+	require.NoError(t, err)
+	assert.True(t, math.IsNaN(res.(float64)))
+
+	res, err = AddNumbers(math.Inf(+1), math.Inf(-1))
+	require.NoError(t, err)
+	assert.True(t, math.IsNaN(res.(float64)))
+
+	res, err = MultiplyNumbers(math.Inf(-1), int32(0))
+	require.NoError(t, err)
+	assert.True(t, math.IsNaN(res.(float64)))
+
+	// $mul sets missing field to the multiplier multiplied by int32 zero
+	res, err = MultiplyNumbers(-1.5, int32(0))
+	require.NoError(t, err)
+	assert.True(t, res.(float64) == 0 && math.Signbit(res.(float64)))
+}
+
+func TestUpdateDocumentArithmetic(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct { //nolint:paralleltest // false positive
+		update   *types.Document
+		expected *types.Document
+		changed  bool
+		err      error
+	}{
+		"IncInt32Overflow": {
+			update:   must.NotFail(types.NewDocument("$inc", must.NotFail(types.NewDocument("v", int32(1))))),
+			expected: must.NotFail(types.NewDocument("_id", "int32-max", "v", int64(math.MaxInt32+1))),
+			changed:  true,
+		},
+		"IncZero": {
+			update:   must.NotFail(types.NewDocument("$inc", must.NotFail(types.NewDocument("v", int32(0))))),
+			expected: must.NotFail(types.NewDocument("_id", "int32-max", "v", int32(math.MaxInt32))),
+		},
+		"IncZeroInt64": {
+			update:   must.NotFail(types.NewDocument("$inc", must.NotFail(types.NewDocument("v", int64(0))))),
+			expected: must.NotFail(types.NewDocument("_id", "int32-max", "v", int64(math.MaxInt32))),
+			changed:  true,
+		},
+		"IncInt64Overflow": {
+			update: must.NotFail(types.NewDocument("$inc", must.NotFail(types.NewDocument("v", int64(math.MaxInt64))))),
+			err: NewWriteErrorMsg(
+				ErrBadValue,
+				`Failed to apply $inc operations to current value ((NumberInt)2147483647) for document {_id: "int32-max"}`,
+			),
+		},
+		"IncMissing": {
+			update:   must.NotFail(types.NewDocument("$inc", must.NotFail(types.NewDocument("foo", int64(1))))),
+			expected: must.NotFail(types.NewDocument("_id", "int32-max", "v", int32(math.MaxInt32), "foo", int64(1))),
+			changed:  true,
+		},
+		"IncMissingNonNumeric": {
+			update: must.NotFail(types.NewDocument("$inc", must.NotFail(types.NewDocument("foo", "bar")))),
+			err:    NewWriteErrorMsg(ErrTypeMismatch, `Cannot increment with non-numeric argument: {foo: "bar"}`),
+		},
+		"MulInt32Overflow": {
+			update:   must.NotFail(types.NewDocument("$mul", must.NotFail(types.NewDocument("v", int32(2))))),
+			expected: must.NotFail(types.NewDocument("_id", "int32-max", "v", int64(2*math.MaxInt32))),
+			changed:  true,
+		},
+		"MulOne": {
+			update:   must.NotFail(types.NewDocument("$mul", must.NotFail(types.NewDocument("v", int32(1))))),
+			expected: must.NotFail(types.NewDocument("_id", "int32-max", "v", int32(math.MaxInt32))),
+		},
+		"MulInt64Overflow": {
+			update: must.NotFail(types.NewDocument("$mul", must.NotFail(types.NewDocument("v", int64(math.MaxInt64))))),
+			err: NewWriteErrorMsg(
+				ErrBadValue,
+				`Failed to apply $mul operations to current value ((NumberInt)2147483647) for document {_id: "int32-max"}`,
+			),
+		},
+		"MulMissing": {
+			update:   must.NotFail(types.NewDocument("$mul", must.NotFail(types.NewDocument("foo", int64(42))))),
+			expected: must.NotFail(types.NewDocument("_id", "int32-max", "v", int32(math.MaxInt32), "foo", int64(0))),
+			changed:  true,
+		},
+		"MulNonNumeric": {
+			update: must.NotFail(types.NewDocument("$mul", must.NotFail(types.NewDocument("v", "bar")))),
+			err:    NewWriteErrorMsg(ErrTypeMismatch, `Cannot multiply with non-numeric argument: {v: "bar"}`),
+		},
+		"MulNonNumericField": {
+			update: must.NotFail(types.NewDocument("$mul", must.NotFail(types.NewDocument("_id", int32(2))))),
+			err: NewWriteErrorMsg(
+				ErrTypeMismatch,
+				`Cannot apply $mul to a value of non-numeric type. `+
+					`{_id: "int32-max"} has the field '_id' of non-numeric type string`,
+			),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			doc := must.NotFail(types.NewDocument("_id", "int32-max", "v", int32(math.MaxInt32)))
+
+			changed, err := UpdateDocument(doc, tc.update)
+			if tc.err != nil {
+				assert.Equal(t, tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.changed, changed)
+			assert.Equal(t, tc.expected, doc)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// ClientMetadata stores client metadata from the handshake document (hello or isMaster), if present.
+//
+// Like MongoDB, it returns ClientMetadataCannotBeMutated error if metadata was already sent on this connection.
+func ClientMetadata(ctx context.Context, document *types.Document) error {
+	v, _ := document.Get("client")
+	if v == nil {
+		return nil
+	}
+
+	client, ok := v.(*types.Document)
+	if !ok {
+		return NewErrorMsg(
+			ErrTypeMismatch,
+			fmt.Sprintf("BSON field 'client' is the wrong type '%s', expected type 'object'", AliasFromType(v)),
+		)
+	}
+
+	connInfo := conninfo.GetConnInfo(ctx)
+	if connInfo.ClientMetadata != nil {
+		return NewErrorMsg(
+			ErrClientMetadataCannotBeMutated,
+			"The client metadata document may only be sent in the first hello",
+		)
+	}
+
+	connInfo.ClientMetadata = conninfo.NewClientMetadata(client)
+
+	if connInfo.Connections != nil {
+		connInfo.Connections.AddDriver(connInfo.ClientMetadata.Driver)
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestClientMetadata(t *testing.T) {
+	t.Parallel()
+
+	connInfo := &conninfo.ConnInfo{
+		Connections: conninfo.NewConnections(0),
+	}
+	ctx := conninfo.WithConnInfo(context.Background(), connInfo)
+
+	client := must.NotFail(types.NewDocument(
+		"application", must.NotFail(types.NewDocument("name", "app")),
+		"driver", must.NotFail(types.NewDocument("name", "nodejs", "version", "4.0.0")),
+	))
+	hello := must.NotFail(types.NewDocument("hello", int32(1), "client", client))
+
+	require.NoError(t, ClientMetadata(ctx, must.NotFail(types.NewDocument("hello", int32(1)))))
+	assert.Nil(t, connInfo.ClientMetadata)
+
+	require.NoError(t, ClientMetadata(ctx, hello))
+	require.NotNil(t, connInfo.ClientMetadata)
+	assert.Equal(t, "app", connInfo.ClientMetadata.AppName)
+	assert.Equal(t, conninfo.Driver{Name: "nodejs", Version: "4.0.0"}, connInfo.ClientMetadata.Driver)
+
+	expected := []conninfo.DriverConnections{{
+		Driver:      conninfo.Driver{Name: "nodejs", Version: "4.0.0"},
+		Connections: 1,
+	}}
+	assert.Equal(t, expected, connInfo.Connections.Drivers())
+
+	err := ClientMetadata(ctx, hello)
+	expectedErr := NewErrorMsg(
+		ErrClientMetadataCannotBeMutated,
+		"The client metadata document may only be sent in the first hello",
+	)
+	assert.Equal(t, expectedErr, err)
+
+	// hello without metadata is still allowed
+	require.NoError(t, ClientMetadata(ctx, must.NotFail(types.NewDocument("hello", int32(1)))))
+	assert.Equal(t, expected, connInfo.Connections.Drivers())
+
+	connInfo.Connections.RemoveDriver(connInfo.ClientMetadata.Driver)
+	assert.Empty(t, connInfo.Connections.Drivers())
+}
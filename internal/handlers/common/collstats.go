@@ -0,0 +1,105 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"sort"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// CollStatsParams contains `collStats` command parameters supported by all handlers.
+type CollStatsParams struct {
+	DB         string
+	Collection string
+	Scale      float64
+}
+
+// GetCollStatsParams returns `collStats` command parameters.
+func GetCollStatsParams(document *types.Document) (*CollStatsParams, error) {
+	var cp CollStatsParams
+	var err error
+
+	if cp.DB, err = GetRequiredParam[string](document, "$db"); err != nil {
+		return nil, err
+	}
+
+	if cp.Collection, err = GetRequiredParam[string](document, document.Command()); err != nil {
+		return nil, err
+	}
+
+	if cp.Scale, err = GetScaleParam(document, "collStats"); err != nil {
+		return nil, err
+	}
+
+	return &cp, nil
+}
+
+// CollStats contains unscaled collection statistics provided by the handler.
+//
+// Zero value represents a non-existent collection.
+type CollStats struct {
+	Count          int64
+	Size           int64
+	StorageSize    int64
+	TotalIndexSize int64
+	IndexSizes     map[string]int64
+	TotalSize      int64
+}
+
+// CollStatsReply returns `collStats` command reply for the given statistics.
+func CollStatsReply(params *CollStatsParams, stats *CollStats) (*wire.OpMsg, error) {
+	scale := int64(params.Scale)
+
+	var avgObjSize int64
+	if stats.Count > 0 {
+		avgObjSize = stats.Size / stats.Count
+	}
+
+	indexNames := make([]string, 0, len(stats.IndexSizes))
+	for name := range stats.IndexSizes {
+		indexNames = append(indexNames, name)
+	}
+	sort.Strings(indexNames)
+
+	indexSizes := types.MakeDocument(len(indexNames))
+	for _, name := range indexNames {
+		must.NoError(indexSizes.Set(name, stats.IndexSizes[name]/scale))
+	}
+
+	var reply wire.OpMsg
+	err := reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"ns", params.DB+"."+params.Collection,
+			"count", int32(stats.Count),
+			"size", stats.Size/scale,
+			"avgObjSize", avgObjSize,
+			"storageSize", stats.StorageSize/scale,
+			"totalIndexSize", stats.TotalIndexSize/scale,
+			"indexSizes", indexSizes,
+			"totalSize", stats.TotalSize/scale,
+			"scaleFactor", int32(scale),
+			"ok", float64(1),
+		))},
+	})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return &reply, nil
+}
@@ -0,0 +1,92 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestCollStatsReply(t *testing.T) {
+	t.Parallel()
+
+	stats := &CollStats{
+		Count:          4,
+		Size:           8_000,
+		StorageSize:    4_096,
+		TotalIndexSize: 2_048,
+		IndexSizes:     map[string]int64{"foo": 1_024, "_id_": 1_024},
+		TotalSize:      14_144,
+	}
+
+	for name, tc := range map[string]struct { //nolint:paralleltest // false positive
+		params   *CollStatsParams
+		stats    *CollStats
+		expected *types.Document
+	}{
+		"NotExist": {
+			params: &CollStatsParams{DB: "db", Collection: "c", Scale: 1},
+			stats:  new(CollStats),
+			expected: must.NotFail(types.NewDocument(
+				"ns", "db.c",
+				"count", int32(0),
+				"size", int64(0),
+				"avgObjSize", int64(0),
+				"storageSize", int64(0),
+				"totalIndexSize", int64(0),
+				"indexSizes", types.MakeDocument(0),
+				"totalSize", int64(0),
+				"scaleFactor", int32(1),
+				"ok", float64(1),
+			)),
+		},
+		"Scale": {
+			params: &CollStatsParams{DB: "db", Collection: "c", Scale: 1_000},
+			stats:  stats,
+			expected: must.NotFail(types.NewDocument(
+				"ns", "db.c",
+				"count", int32(4),
+				"size", int64(8),
+				"avgObjSize", int64(2_000),
+				"storageSize", int64(4),
+				"totalIndexSize", int64(2),
+				"indexSizes", must.NotFail(types.NewDocument(
+					"_id_", int64(1),
+					"foo", int64(1),
+				)),
+				"totalSize", int64(14),
+				"scaleFactor", int32(1_000),
+				"ok", float64(1),
+			)),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			reply, err := CollStatsReply(tc.params, tc.stats)
+			require.NoError(t, err)
+
+			actual, err := reply.Document()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
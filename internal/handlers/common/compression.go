@@ -0,0 +1,68 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// Compression negotiates compressors for the connection.
+//
+// It returns names of compressors that were requested by the client
+// in the `compression` field of the hello / isMaster command and are supported,
+// in the client's order of preference, and stores them in the connection info.
+//
+// The returned array is empty if there are no such compressors.
+func Compression(ctx context.Context, document *types.Document) (*types.Array, error) {
+	res := types.MakeArray(0)
+	var ids []wire.CompressorID
+
+	v, _ := document.Get("compression")
+	if v == nil {
+		conninfo.GetConnInfo(ctx).Compressors = ids
+		return res, nil
+	}
+
+	requested, ok := v.(*types.Array)
+	if !ok {
+		msg := fmt.Sprintf(
+			"BSON field 'compression' is the wrong type '%s', expected type 'array'",
+			AliasFromType(v),
+		)
+		return nil, NewErrorMsg(ErrTypeMismatch, msg)
+	}
+
+	for i := 0; i < requested.Len(); i++ {
+		name, ok := must.NotFail(requested.Get(i)).(string)
+		if !ok {
+			return nil, NewErrorMsg(ErrTypeMismatch, "BSON field 'compression' must be an array of strings")
+		}
+
+		if id, ok := wire.CompressorByName(name); ok && !res.Contains(name) {
+			must.NoError(res.Append(name))
+			ids = append(ids, id)
+		}
+	}
+
+	conninfo.GetConnInfo(ctx).Compressors = ids
+
+	return res, nil
+}
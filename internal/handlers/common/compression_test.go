@@ -0,0 +1,86 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+func TestCompression(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		document    *types.Document
+		expected    *types.Array
+		compressors []wire.CompressorID
+		err         error
+	}{
+		"Missing": {
+			document: must.NotFail(types.NewDocument("hello", int32(1))),
+			expected: types.MakeArray(0),
+		},
+		"Supported": {
+			document: must.NotFail(types.NewDocument(
+				"hello", int32(1),
+				"compression", must.NotFail(types.NewArray("zstd", "snappy", "unknown", "snappy", "noop", "zlib")),
+			)),
+			expected:    must.NotFail(types.NewArray("zstd", "snappy", "zlib")),
+			compressors: []wire.CompressorID{wire.CompressorZstd, wire.CompressorSnappy, wire.CompressorZlib},
+		},
+		"NotArray": {
+			document: must.NotFail(types.NewDocument("hello", int32(1), "compression", "snappy")),
+			err: NewErrorMsg(
+				ErrTypeMismatch,
+				"BSON field 'compression' is the wrong type 'string', expected type 'array'",
+			),
+		},
+		"NotString": {
+			document: must.NotFail(types.NewDocument(
+				"hello", int32(1),
+				"compression", must.NotFail(types.NewArray(int32(1))),
+			)),
+			err: NewErrorMsg(ErrTypeMismatch, "BSON field 'compression' must be an array of strings"),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			connInfo := &conninfo.ConnInfo{
+				Compressors: []wire.CompressorID{wire.CompressorSnappy},
+			}
+			ctx := conninfo.WithConnInfo(context.Background(), connInfo)
+
+			actual, err := Compression(ctx, tc.document)
+			if tc.err != nil {
+				assert.Equal(t, tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+			assert.Equal(t, tc.compressors, connInfo.Compressors)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// Connections returns serverStatus.connections document.
+func Connections(ctx context.Context) *types.Document {
+	var stats conninfo.ConnectionsStats
+	if c := conninfo.GetConnInfo(ctx).Connections; c != nil {
+		stats = c.Stats()
+	}
+
+	return must.NotFail(types.NewDocument(
+		"current", stats.Current,
+		"available", stats.Available,
+		"totalCreated", stats.TotalCreated,
+		"rejected", stats.Rejected,
+	))
+}
+
+// Drivers returns serverStatus.drivers array with the number of connections per client driver name and version.
+func Drivers(ctx context.Context) *types.Array {
+	var drivers []conninfo.DriverConnections
+	if c := conninfo.GetConnInfo(ctx).Connections; c != nil {
+		drivers = c.Drivers()
+	}
+
+	res := types.MakeArray(len(drivers))
+	for _, d := range drivers {
+		must.NoError(res.Append(must.NotFail(types.NewDocument(
+			"name", d.Name,
+			"version", d.Version,
+			"connections", d.Connections,
+		))))
+	}
+
+	return res
+}
@@ -0,0 +1,218 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/cursor"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// DefaultBatchSize is the default number of documents in the first batch, as in MongoDB.
+const DefaultBatchSize = 101
+
+// GetBatchSizeParam returns the value of the batchSize parameter of the given command,
+// or the given default value if it is absent.
+//
+// Whole doubles are accepted, fractional ones are rejected.
+func GetBatchSizeParam(document *types.Document, command string, def int64) (int64, error) {
+	v, _ := document.Get("batchSize")
+	if v == nil {
+		return def, nil
+	}
+
+	batchSize, err := getCountParam(command, "batchSize", v)
+	if err != nil {
+		return 0, err
+	}
+
+	if batchSize < 0 {
+		return 0, NewErrorMsg(
+			ErrValueNegative,
+			fmt.Sprintf("BatchSize value must be non-negative, but received: %d", batchSize),
+		)
+	}
+
+	return batchSize, nil
+}
+
+// FirstBatch returns the first batch of the given cursor for find-like commands
+// and the cursor ID for subsequent getMore commands.
+//
+// If the cursor is not exhausted by the first batch (and singleBatch is false),
+// it is added to the registry; otherwise, it is closed and the returned ID is 0.
+func FirstBatch(ctx context.Context, r *cursor.Registry, c *cursor.Cursor, batchSize int64, singleBatch bool) (*types.Array, int64, error) { //nolint:lll // argument list is too long
+	// zero batchSize means "all remaining documents" for getMore, but "none" for find
+	if batchSize == 0 {
+		if singleBatch {
+			c.Close()
+			return types.MakeArray(0), 0, nil
+		}
+
+		return types.MakeArray(0), r.Add(ctx, c), nil
+	}
+
+	batch, done, err := c.NextBatch(ctx, batchSize)
+	if err != nil {
+		c.Close()
+		return nil, 0, err
+	}
+
+	if done || singleBatch {
+		c.Close()
+		return batch, 0, nil
+	}
+
+	return batch, r.Add(ctx, c), nil
+}
+
+// GetMore implements getMore command for cursors of the given registry.
+func GetMore(ctx context.Context, document *types.Document, r *cursor.Registry) (*wire.OpMsg, error) {
+	id, err := GetRequiredParam[int64](document, document.Command())
+	if err != nil {
+		return nil, err
+	}
+
+	c := r.Get(id)
+	if c == nil {
+		return nil, NewErrorMsg(ErrCursorNotFound, fmt.Sprintf("cursor id %d not found", id))
+	}
+
+	collection, err := GetRequiredParam[string](document, "collection")
+	if err != nil {
+		return nil, err
+	}
+
+	if db, _ := document.Get("$db"); db != c.DB || collection != c.Collection {
+		errMsg := fmt.Sprintf(
+			"Requested getMore on namespace '%s.%s', but cursor belongs to a different namespace %s",
+			db, collection, c.NS(),
+		)
+
+		return nil, NewErrorMsg(ErrBadValue, errMsg)
+	}
+
+	batchSize, err := GetBatchSizeParam(document, document.Command(), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	nextBatch, done, err := c.NextBatch(ctx, batchSize)
+	if err != nil {
+		r.Remove(id)
+		return nil, lazyerrors.Error(err)
+	}
+
+	if done {
+		r.Remove(id)
+		id = 0
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"cursor", must.NotFail(types.NewDocument(
+				"nextBatch", nextBatch,
+				"id", id,
+				"ns", c.NS(),
+			)),
+			"ok", float64(1),
+		))},
+	}))
+
+	return &reply, nil
+}
+
+// KillCursors implements killCursors command.
+//
+// The kill function is called for each given cursor ID; it should return true if the cursor existed.
+func KillCursors(document *types.Document, kill func(id int64) bool) (*wire.OpMsg, error) {
+	cursors, err := GetRequiredParam[*types.Array](document, "cursors")
+	if err != nil {
+		return nil, err
+	}
+
+	killed := types.MakeArray(0)
+	notFound := types.MakeArray(0)
+
+	for i := 0; i < cursors.Len(); i++ {
+		id, err := AssertType[int64](must.NotFail(cursors.Get(i)))
+		if err != nil {
+			return nil, err
+		}
+
+		if kill(id) {
+			must.NoError(killed.Append(id))
+		} else {
+			must.NoError(notFound.Append(id))
+		}
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"cursorsKilled", killed,
+			"cursorsNotFound", notFound,
+			"cursorsAlive", types.MakeArray(0),
+			"cursorsUnknown", types.MakeArray(0),
+			"ok", float64(1),
+		))},
+	}))
+
+	return &reply, nil
+}
+
+// CursorMetrics returns serverStatus.metrics.cursor document for cursors of the given registry.
+func CursorMetrics(r *cursor.Registry) *types.Document {
+	stats := r.Stats()
+
+	return must.NotFail(types.NewDocument(
+		"timedOut", stats.TimedOut,
+		"open", must.NotFail(types.NewDocument(
+			"noTimeout", stats.OpenNoTimeout,
+			"total", stats.Open,
+		)),
+	))
+}
+
+// CursorState represents an open cursor reported on the debug endpoint.
+type CursorState struct {
+	ID         int64   `json:"id"`
+	NS         string  `json:"ns"`
+	AgeSeconds float64 `json:"ageSeconds"`
+}
+
+// CursorsState returns states of all open cursors of the given registry.
+func CursorsState(r *cursor.Registry) []CursorState {
+	now := time.Now()
+
+	list := r.List()
+	res := make([]CursorState, len(list))
+	for i, c := range list {
+		res[i] = CursorState{
+			ID:         c.ID,
+			NS:         c.NS,
+			AgeSeconds: now.Sub(c.Created).Seconds(),
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,126 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/cursor"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestGetBatchSizeParam(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct { //nolint:paralleltest // false positive
+		batchSize any // nil means absent
+		expected  int64
+		err       error
+	}{
+		"Absent": {
+			expected: 101,
+		},
+		"Int32": {
+			batchSize: int32(2),
+			expected:  2,
+		},
+		"Zero": {
+			batchSize: int64(0),
+			expected:  0,
+		},
+		"Double": {
+			batchSize: float64(2),
+			expected:  2,
+		},
+		"Fractional": {
+			batchSize: 2.9,
+			err:       NewErrorMsg(ErrBadValue, "Expected an integer: batchSize: 2.9"),
+		},
+		"Negative": {
+			batchSize: int32(-1),
+			err:       NewErrorMsg(ErrValueNegative, "BatchSize value must be non-negative, but received: -1"),
+		},
+		"String": {
+			batchSize: "2",
+			err: NewErrorMsg(
+				ErrTypeMismatch,
+				"BSON field 'find.batchSize' is the wrong type 'string', expected types '[long, int, decimal, double]'",
+			),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			document := must.NotFail(types.NewDocument("find", "c"))
+			if tc.batchSize != nil {
+				must.NoError(document.Set("batchSize", tc.batchSize))
+			}
+
+			actual, err := GetBatchSizeParam(document, "find", DefaultBatchSize)
+			if tc.err != nil {
+				assert.Equal(t, tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestFirstBatch(t *testing.T) {
+	t.Parallel()
+
+	ctx := conninfo.WithConnInfo(context.Background(), new(conninfo.ConnInfo))
+	r := cursor.NewRegistry()
+
+	newCursor := func() *cursor.Cursor {
+		docs := []*types.Document{
+			must.NotFail(types.NewDocument("_id", int32(1))),
+			must.NotFail(types.NewDocument("_id", int32(2))),
+			must.NotFail(types.NewDocument("_id", int32(3))),
+		}
+
+		return cursor.New("db", "c", cursor.NewSliceIterator(docs))
+	}
+
+	batch, id, err := FirstBatch(ctx, r, newCursor(), 2, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, batch.Len())
+	require.NotZero(t, id)
+	assert.NotNil(t, r.Get(id))
+
+	batch, id, err = FirstBatch(ctx, r, newCursor(), 2, true)
+	require.NoError(t, err)
+	assert.Equal(t, 2, batch.Len())
+	assert.Zero(t, id)
+
+	batch, id, err = FirstBatch(ctx, r, newCursor(), 5, false)
+	require.NoError(t, err)
+	assert.Equal(t, 3, batch.Len())
+	assert.Zero(t, id)
+
+	batch, id, err = FirstBatch(ctx, r, newCursor(), 0, false)
+	require.NoError(t, err)
+	assert.Equal(t, 0, batch.Len())
+	assert.NotZero(t, id)
+}
@@ -0,0 +1,123 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// DistinctParams contains `distinct` command parameters supported by at least one handler.
+type DistinctParams struct {
+	DB         string
+	Collection string
+	Key        string
+	Filter     *types.Document
+}
+
+// GetDistinctParams returns `distinct` command parameters.
+func GetDistinctParams(document *types.Document, l *zap.Logger) (*DistinctParams, error) {
+	unimplementedFields := []string{
+		"collation",
+	}
+	if err := Unimplemented(document, unimplementedFields...); err != nil {
+		return nil, err
+	}
+	ignoredFields := []string{
+		"readConcern",
+		"comment",
+	}
+	Ignored(document, l, ignoredFields...)
+
+	var dp DistinctParams
+	var err error
+
+	if dp.DB, err = GetRequiredParam[string](document, "$db"); err != nil {
+		return nil, err
+	}
+
+	collectionParam, err := document.Get(document.Command())
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	var ok bool
+	if dp.Collection, ok = collectionParam.(string); !ok {
+		return nil, NewErrorMsg(
+			ErrBadValue,
+			fmt.Sprintf("collection name has invalid type %s", AliasFromType(collectionParam)),
+		)
+	}
+
+	key, err := document.Get("key")
+	if err != nil {
+		return nil, NewErrorMsg(ErrMissingField, "BSON field 'distinct.key' is missing but a required field")
+	}
+
+	if dp.Key, ok = key.(string); !ok {
+		return nil, NewErrorMsg(
+			ErrTypeMismatch,
+			fmt.Sprintf("BSON field 'distinct.key' is the wrong type '%s', expected type 'string'", AliasFromType(key)),
+		)
+	}
+
+	if query, _ := document.Get("query"); query != nil && query != types.Null {
+		if dp.Filter, ok = query.(*types.Document); !ok {
+			return nil, NewErrorMsg(
+				ErrTypeMismatch,
+				fmt.Sprintf("BSON field 'distinct.query' is the wrong type '%s', expected type 'object'", AliasFromType(query)),
+			)
+		}
+	}
+
+	return &dp, nil
+}
+
+// FilterDistinctValues returns distinct values of the given key (that may be a dotted path) in documents.
+//
+// Arrays are flattened: their elements are used as values, and documents in arrays on the path are searched.
+// Values are deduplicated by BSON comparison (so 1 and 1.0 are the same value) and returned in BSON order.
+func FilterDistinctValues(docs []*types.Document, key string) (*types.Array, error) {
+	path := strings.Split(key, ".")
+
+	var values []any
+	for _, doc := range docs {
+		var emptyArray bool
+		collectPathValues(doc, path, &values, &emptyArray)
+	}
+
+	sort.SliceStable(values, func(i, j int) bool {
+		return types.CompareOrder(values[i], values[j]) == types.Less
+	})
+
+	res := types.MakeArray(len(values))
+	for i, v := range values {
+		if i > 0 && types.CompareOrder(values[i-1], v) == types.Equal {
+			continue
+		}
+
+		if err := res.Append(v); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,151 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestFilterDistinctValues(t *testing.T) {
+	t.Parallel()
+
+	docs := []*types.Document{
+		must.NotFail(types.NewDocument("_id", int32(0), "v", int32(1))),
+		must.NotFail(types.NewDocument("_id", int32(1), "v", float64(1))),
+		must.NotFail(types.NewDocument("_id", int32(2), "v", must.NotFail(types.NewArray("foo", int32(2), int32(1))))),
+		must.NotFail(types.NewDocument("_id", int32(3), "v", must.NotFail(types.NewArray(
+			must.NotFail(types.NewArray(int32(3))),
+		)))),
+		must.NotFail(types.NewDocument("_id", int32(4), "v", types.Null)),
+		must.NotFail(types.NewDocument("_id", int32(5))),
+		must.NotFail(types.NewDocument("_id", int32(6), "v", must.NotFail(types.NewArray()))),
+		must.NotFail(types.NewDocument("_id", int32(7), "v", must.NotFail(types.NewDocument("foo", "bar")))),
+		must.NotFail(types.NewDocument("_id", int32(8), "v", must.NotFail(types.NewArray(
+			must.NotFail(types.NewDocument("foo", "baz")),
+			must.NotFail(types.NewDocument("foo", must.NotFail(types.NewArray("bar", "qux")))),
+		)))),
+	}
+
+	for name, tc := range map[string]struct { //nolint:paralleltest // false positive
+		key      string
+		expected *types.Array
+	}{
+		"Flat": {
+			key: "v",
+			expected: must.NotFail(types.NewArray(
+				types.Null,
+				int32(1), // float64(1) is the same value
+				int32(2),
+				"foo",
+				must.NotFail(types.NewDocument("foo", "bar")),
+				must.NotFail(types.NewDocument("foo", "baz")),
+				must.NotFail(types.NewDocument("foo", must.NotFail(types.NewArray("bar", "qux")))),
+				must.NotFail(types.NewArray(int32(3))), // nested arrays are not flattened
+			)),
+		},
+		"Dotted": {
+			key:      "v.foo",
+			expected: must.NotFail(types.NewArray("bar", "baz", "qux")),
+		},
+		"Index": { // selected array [3] is flattened
+			key:      "v.0",
+			expected: must.NotFail(types.NewArray(int32(3), "foo", must.NotFail(types.NewDocument("foo", "baz")))),
+		},
+		"Missing": {
+			key:      "missing",
+			expected: must.NotFail(types.NewArray()),
+		},
+		"ID": {
+			key: "_id",
+			expected: must.NotFail(types.NewArray(
+				int32(0), int32(1), int32(2), int32(3), int32(4), int32(5), int32(6), int32(7), int32(8),
+			)),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := FilterDistinctValues(docs, tc.key)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestGetDistinctParams(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Valid", func(t *testing.T) {
+		t.Parallel()
+
+		filter := must.NotFail(types.NewDocument("v", int32(1)))
+		doc := must.NotFail(types.NewDocument("distinct", "c", "key", "v.foo", "query", filter, "$db", "db"))
+
+		dp, err := GetDistinctParams(doc, zap.NewNop())
+		require.NoError(t, err)
+		expected := &DistinctParams{DB: "db", Collection: "c", Key: "v.foo", Filter: filter}
+		assert.Equal(t, expected, dp)
+	})
+
+	for name, tc := range map[string]struct { //nolint:paralleltest // false positive
+		doc      *types.Document
+		expected error
+	}{
+		"MissingKey": {
+			doc: must.NotFail(types.NewDocument("distinct", "c", "$db", "db")),
+			expected: NewErrorMsg(
+				ErrMissingField,
+				"BSON field 'distinct.key' is missing but a required field",
+			),
+		},
+		"KeyType": {
+			doc: must.NotFail(types.NewDocument("distinct", "c", "key", int32(1), "$db", "db")),
+			expected: NewErrorMsg(
+				ErrTypeMismatch,
+				"BSON field 'distinct.key' is the wrong type 'int', expected type 'string'",
+			),
+		},
+		"QueryType": {
+			doc: must.NotFail(types.NewDocument("distinct", "c", "key", "v", "query", "v", "$db", "db")),
+			expected: NewErrorMsg(
+				ErrTypeMismatch,
+				"BSON field 'distinct.query' is the wrong type 'string', expected type 'object'",
+			),
+		},
+		"CollectionType": {
+			doc: must.NotFail(types.NewDocument("distinct", int32(1), "key", "v", "$db", "db")),
+			expected: NewErrorMsg(
+				ErrBadValue,
+				"collection name has invalid type int",
+			),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := GetDistinctParams(tc.doc, zap.NewNop())
+			assert.Equal(t, tc.expected, err)
+		})
+	}
+}
@@ -18,8 +18,11 @@ import (
 	"errors"
 	"fmt"
 
+	"golang.org/x/exp/slices"
+
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
 //go:generate ../../../bin/stringer -linecomment -type ErrorCode
@@ -30,18 +33,30 @@ type ErrorCode int32
 const (
 	errUnset = ErrorCode(0) // Unset
 
-	// For ProtocolError only.
+	// For ProtocolError and NewInternalErrorMsg only.
 	errInternalError = ErrorCode(1) // InternalError
 
 	// ErrBadValue indicates wrong input.
 	ErrBadValue = ErrorCode(2) // BadValue
 
+	// ErrHostUnreachable indicates that the backend is not available.
+	ErrHostUnreachable = ErrorCode(6) // HostUnreachable
+
 	// ErrFailedToParse indicates user input parsing failure.
 	ErrFailedToParse = ErrorCode(9) // FailedToParse
 
+	// ErrUnauthorized indicates that the command requires authentication.
+	ErrUnauthorized = ErrorCode(13) // Unauthorized
+
 	// ErrTypeMismatch for $sort indicates that the expression in the $sort is not an object.
 	ErrTypeMismatch = ErrorCode(14) // TypeMismatch
 
+	// ErrProtocolError indicates that there is no SASL conversation to continue.
+	ErrProtocolError = ErrorCode(17) // ProtocolError
+
+	// ErrAuthenticationFailed indicates that given credentials are invalid.
+	ErrAuthenticationFailed = ErrorCode(18) // AuthenticationFailed
+
 	// ErrNamespaceNotFound indicates that a collection is not found.
 	ErrNamespaceNotFound = ErrorCode(26) // NamespaceNotFound
 
@@ -51,36 +66,78 @@ const (
 	// ErrConflictingUpdateOperators indicates that $set, $inc or $setOnInsert were used together.
 	ErrConflictingUpdateOperators = ErrorCode(40) // ConflictingUpdateOperators
 
+	// ErrCursorNotFound indicates that a cursor with the given ID does not exist.
+	ErrCursorNotFound = ErrorCode(43) // CursorNotFound
+
+	// ErrDollarPrefixedFieldName indicates that the field name starts with "$" where it is not allowed.
+	ErrDollarPrefixedFieldName = ErrorCode(52) // DollarPrefixedFieldName
+
 	// ErrNamespaceExists indicates that the collection already exists.
 	ErrNamespaceExists = ErrorCode(48) // NamespaceExists
 
 	// ErrCommandNotFound indicates unknown command input.
 	ErrCommandNotFound = ErrorCode(59) // CommandNotFound
 
+	// ErrInvalidOptions indicates that the command options are invalid.
+	ErrInvalidOptions = ErrorCode(72) // InvalidOptions
+
 	// ErrInvalidNamespace indicates that the collection name is invalid.
 	ErrInvalidNamespace = ErrorCode(73) // InvalidNamespace
 
+	// ErrShutdownInProgress indicates that the server is shutting down and does not accept new commands.
+	ErrShutdownInProgress = ErrorCode(91) // ShutdownInProgress
+
+	// ErrOperationFailed indicates that the operation failed for a reason not covered by other codes.
+	ErrOperationFailed = ErrorCode(96) // OperationFailed
+
 	// ErrDocumentValidationFailure indicates that document validation failed.
 	ErrDocumentValidationFailure = ErrorCode(121) // DocumentValidationFailure
 
+	// ErrClientMetadataCannotBeMutated indicates that the client metadata was sent after the first handshake.
+	ErrClientMetadataCannotBeMutated = ErrorCode(186) // ClientMetadataCannotBeMutated
+
+	// ErrBSONObjectTooLarge indicates that a document is larger than types.MaxDocumentLen.
+	ErrBSONObjectTooLarge = ErrorCode(10334) // BSONObjectTooLarge
+
+	// ErrDuplicateKey indicates that a document with the same unique key already exists.
+	ErrDuplicateKey = ErrorCode(11000) // DuplicateKey
+
+	// ErrNotWritablePrimary indicates that write commands are rejected because the server is read-only.
+	ErrNotWritablePrimary = ErrorCode(10107) // NotWritablePrimary
+
 	// ErrNotImplemented indicates that a flag or command is not implemented.
 	ErrNotImplemented = ErrorCode(238) // NotImplemented
 
+	// ErrMechanismUnavailable indicates that the authentication mechanism is not supported.
+	ErrMechanismUnavailable = ErrorCode(334) // MechanismUnavailable
+
+	// ErrUnsupportedOpQueryCommand indicates that OP_QUERY is used for something other than the handshake.
+	ErrUnsupportedOpQueryCommand = ErrorCode(352) // UnsupportedOpQueryCommand
+
 	// ErrFailedToParseInput indicates invalid input (absent or malformed fields).
 	ErrFailedToParseInput = ErrorCode(40415) // Location40415
 
+	// ErrSortTooManyKeys indicates that sort specification has too many keys.
+	ErrSortTooManyKeys = ErrorCode(13103) // Location13103
+
 	// ErrSortBadValue indicates bad value in sort input.
 	ErrSortBadValue = ErrorCode(15974) // Location15974
 
 	// ErrSortBadOrder indicates bad sort order input.
 	ErrSortBadOrder = ErrorCode(15975) // Location15975
 
+	// ErrPathContainsEmptyElement indicates that dotted path contains an empty element.
+	ErrPathContainsEmptyElement = ErrorCode(15998) // Location15998
+
 	// ErrInvalidArg indicates invalid argument in projection document.
 	ErrInvalidArg = ErrorCode(28667) // Location28667
 
 	// ErrSliceFirstArg for $slice indicates that the first argument is not an array.
 	ErrSliceFirstArg = ErrorCode(28724) // Location28724
 
+	// ErrProjectionPathCollision indicates that projection paths overlap.
+	ErrProjectionPathCollision = ErrorCode(31250) // Location31250
+
 	// ErrProjectionInEx for $elemMatch indicates that inclusion statement found
 	// while projection document already marked as exlusion.
 	ErrProjectionInEx = ErrorCode(31253) // Location31253
@@ -89,10 +146,19 @@ const (
 	// while projection document already marked as inclusion.
 	ErrProjectionExIn = ErrorCode(31254) // Location31254
 
+	// ErrMissingField indicates that the required field in command is missing.
+	ErrMissingField = ErrorCode(40414) // Location40414
+
+	// ErrChangeStreamNotSupported indicates that $changeStream stage is not enabled.
+	ErrChangeStreamNotSupported = ErrorCode(40573) // Location40573
+
 	// ErrFreeMonitoringDisabled indicates that free monitoring is disabled
 	// by command-line or config file.
 	ErrFreeMonitoringDisabled = ErrorCode(50840) // Location50840
 
+	// ErrValueNegative indicates that value must not be negative.
+	ErrValueNegative = ErrorCode(51024) // Location51024
+
 	// ErrRegexOptions indicates regex options error.
 	ErrRegexOptions = ErrorCode(51075) // Location51075
 
@@ -100,6 +166,10 @@ const (
 	ErrRegexMissingParen = ErrorCode(51091) // Location51091
 )
 
+// RetryableWriteErrorLabel is an error label indicating that the failed write command
+// could be safely retried by the client.
+const RetryableWriteErrorLabel = "RetryableWriteError"
+
 // ProtoErr represents protocol error type.
 type ProtoErr interface {
 	error
@@ -112,6 +182,7 @@ type ProtoErr interface {
 // ProtocolError converts any error to wire protocol error.
 //
 // Nil panics, *Error or *WriteErrors (possibly wrapped) is returned unwrapped with true,
+// *wire.DocumentTooLargeError (possibly wrapped) is converted to BSONObjectTooLarge error and returned with true,
 // any other value is wrapped with InternalError and returned with false.
 func ProtocolError(err error) (ProtoErr, bool) {
 	if err == nil {
@@ -128,6 +199,11 @@ func ProtocolError(err error) (ProtoErr, bool) {
 		return writeErr, true
 	}
 
+	var tooLarge *wire.DocumentTooLargeError
+	if errors.As(err, &tooLarge) {
+		return NewError(ErrBSONObjectTooLarge, tooLarge).(*Error), true
+	}
+
 	return NewError(errInternalError, err).(*Error), false
 }
 
@@ -136,8 +212,9 @@ type CommandError = Error
 
 // Error is a deprecated name for CommandError; instead, use the later version in the new code.
 type Error struct {
-	err  error
-	code ErrorCode
+	err    error
+	code   ErrorCode
+	labels []string
 }
 
 // There should not be NewError function variant that accepts printf-like format specifiers.
@@ -156,6 +233,14 @@ func NewError(code ErrorCode, err error) error {
 	}
 }
 
+// NewInternalErrorMsg creates a new InternalError with the given message.
+//
+// Unlike other errors converted to InternalError by ProtocolError,
+// it does not close the client connection.
+func NewInternalErrorMsg(msg string) error {
+	return NewErrorMsg(errInternalError, msg)
+}
+
 // NewErrorMsg is variant for NewError with error string.
 //
 // Code can't be zero, err can't be empty.
@@ -163,6 +248,31 @@ func NewErrorMsg(code ErrorCode, msg string) error {
 	return NewError(code, errors.New(msg))
 }
 
+// WithErrorLabels returns a copy of the given error with added error labels.
+//
+// *Error (possibly wrapped) is copied with labels added,
+// any other error is wrapped with InternalError first.
+func WithErrorLabels(err error, labels ...string) error {
+	var e *Error
+	if !errors.As(err, &e) {
+		e = NewError(errInternalError, err).(*Error)
+	}
+
+	res := &Error{
+		err:    e.err,
+		code:   e.code,
+		labels: slices.Clone(e.labels),
+	}
+
+	for _, l := range labels {
+		if !slices.Contains(res.labels, l) {
+			res.labels = append(res.labels, l)
+		}
+	}
+
+	return res
+}
+
 // Error implements error interface.
 func (e *Error) Error() string {
 	return fmt.Sprintf("%[1]s (%[1]d): %[2]v", e.code, e.err)
@@ -178,6 +288,11 @@ func (e *Error) Unwrap() error {
 	return e.err
 }
 
+// Labels returns error labels.
+func (e *Error) Labels() []string {
+	return e.labels
+}
+
 // Document returns wire protocol error document.
 func (e *Error) Document() *types.Document {
 	d := must.NotFail(types.NewDocument(
@@ -188,6 +303,32 @@ func (e *Error) Document() *types.Document {
 		must.NoError(d.Set("code", int32(e.code)))
 		must.NoError(d.Set("codeName", e.code.String()))
 	}
+	if len(e.labels) > 0 {
+		labels := types.MakeArray(len(e.labels))
+		for _, l := range e.labels {
+			must.NoError(labels.Append(l))
+		}
+		must.NoError(d.Set("errorLabels", labels))
+	}
+	return d
+}
+
+// QueryFailureDocument returns OP_REPLY document for the given protocol error.
+//
+// It is sent with QueryFailure flag set.
+func QueryFailureDocument(err ProtoErr) *types.Document {
+	msg := err.Error()
+	if errmsg, _ := err.Document().Get("errmsg"); errmsg != nil {
+		msg = errmsg.(string)
+	}
+
+	d := must.NotFail(types.NewDocument(
+		"$err", msg,
+		"code", int32(err.Code()),
+	))
+	if code := err.Code(); code != errUnset {
+		must.NoError(d.Set("codeName", code.String()))
+	}
 	return d
 }
 
@@ -196,6 +337,8 @@ func (e *Error) Document() *types.Document {
 type WriteErrors []writeError
 
 // NewWriteErrorMsg creates a new protocol write error with given ErrorCode and message.
+//
+// It has no document index; use Append to set it.
 func NewWriteErrorMsg(code ErrorCode, msg string) error {
 	return &WriteErrors{{
 		code: code,
@@ -203,6 +346,28 @@ func NewWriteErrorMsg(code ErrorCode, msg string) error {
 	}}
 }
 
+// Append converts the given error to write error for the document with the given index and appends it.
+//
+// *Error and *WriteErrors (possibly wrapped) keep their codes, any other value gets InternalError code.
+func (we *WriteErrors) Append(err error, index int32) {
+	var writeErr *WriteErrors
+	if errors.As(err, &writeErr) {
+		for _, e := range *writeErr {
+			*we = append(*we, writeError{index: &index, code: e.code, err: e.err})
+		}
+
+		return
+	}
+
+	var cmdErr *Error
+	if errors.As(err, &cmdErr) {
+		*we = append(*we, writeError{index: &index, code: cmdErr.code, err: cmdErr.err.Error()})
+		return
+	}
+
+	*we = append(*we, writeError{index: &index, code: errInternalError, err: err.Error()})
+}
+
 // Error implements error interface.
 func (we *WriteErrors) Error() string {
 	var err string
@@ -235,10 +400,16 @@ func (we *WriteErrors) Document() *types.Document {
 	for _, e := range *we {
 		// Fields "code" and "errmsg" must always be filled in so that clients can parse the error message.
 		// Otherwise, the mongo client would parse it as a CommandError.
-		must.NoError(errs.Append(must.NotFail(types.NewDocument(
-			"code", int32(e.code),
-			"errmsg", e.err,
-		))))
+		doc := must.NotFail(types.NewDocument())
+
+		// "index" is present only if it was set by Append
+		if e.index != nil {
+			must.NoError(doc.Set("index", *e.index))
+		}
+
+		must.NoError(doc.Set("code", int32(e.code)))
+		must.NoError(doc.Set("errmsg", e.err))
+		must.NoError(errs.Append(doc))
 	}
 
 	// "writeErrors" field must be present in the result document so that clients can parse it as WriteErrors.
@@ -252,8 +423,9 @@ func (we *WriteErrors) Document() *types.Document {
 // writeError represents protocol write error.
 // It required to build the correct write error result.
 type writeError struct {
-	code ErrorCode
-	err  string
+	index *int32 // index of the document in the request, nil if not set
+	code  ErrorCode
+	err   string
 }
 
 // formatBitwiseOperatorErr formats protocol error for given internal error and bitwise operator.
@@ -0,0 +1,146 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestErrorDocument(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct { //nolint:paralleltest // false positive
+		err      error
+		expected *types.Document
+	}{
+		"CommandError": {
+			err: NewErrorMsg(ErrBadValue, "bad value"),
+			expected: must.NotFail(types.NewDocument(
+				"ok", float64(0),
+				"errmsg", "bad value",
+				"code", int32(2),
+				"codeName", "BadValue",
+			)),
+		},
+		"Internal": {
+			err: errors.New("oops"),
+			expected: must.NotFail(types.NewDocument(
+				"ok", float64(0),
+				"errmsg", "oops",
+				"code", int32(1),
+				"codeName", "InternalError",
+			)),
+		},
+		"Labels": {
+			err: WithErrorLabels(NewErrorMsg(ErrOperationFailed, "failed"), RetryableWriteErrorLabel),
+			expected: must.NotFail(types.NewDocument(
+				"ok", float64(0),
+				"errmsg", "failed",
+				"code", int32(96),
+				"codeName", "OperationFailed",
+				"errorLabels", must.NotFail(types.NewArray(RetryableWriteErrorLabel)),
+			)),
+		},
+		"WriteError": {
+			err: NewWriteErrorMsg(ErrDuplicateKey, "duplicate"),
+			expected: must.NotFail(types.NewDocument(
+				"ok", float64(1),
+				"writeErrors", must.NotFail(types.NewArray(must.NotFail(types.NewDocument(
+					"code", int32(11000),
+					"errmsg", "duplicate",
+				)))),
+			)),
+		},
+		"LabelsInternal": {
+			err: WithErrorLabels(errors.New("connection lost"), RetryableWriteErrorLabel, RetryableWriteErrorLabel),
+			expected: must.NotFail(types.NewDocument(
+				"ok", float64(0),
+				"errmsg", "connection lost",
+				"code", int32(1),
+				"codeName", "InternalError",
+				"errorLabels", must.NotFail(types.NewArray(RetryableWriteErrorLabel)),
+			)),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			protoErr, _ := ProtocolError(tc.err)
+			testutil.AssertEqual(t, tc.expected, protoErr.Document())
+		})
+	}
+}
+
+func TestWriteErrorsAppend(t *testing.T) {
+	t.Parallel()
+
+	// failures of documents 1, 3 and 4 out of 5, as for unordered insert
+	var we WriteErrors
+	we.Append(NewWriteErrorMsg(ErrDuplicateKey, "duplicate"), 1)
+	we.Append(fmt.Errorf("wrapped: %w", NewErrorMsg(ErrBadValue, "bad value")), 3)
+	we.Append(errors.New("oops"), 4)
+
+	expected := must.NotFail(types.NewDocument(
+		"ok", float64(1),
+		"writeErrors", must.NotFail(types.NewArray(
+			must.NotFail(types.NewDocument("index", int32(1), "code", int32(11000), "errmsg", "duplicate")),
+			must.NotFail(types.NewDocument("index", int32(3), "code", int32(2), "errmsg", "bad value")),
+			must.NotFail(types.NewDocument("index", int32(4), "code", int32(1), "errmsg", "oops")),
+		)),
+	))
+	testutil.AssertEqual(t, expected, we.Document())
+
+	// the original error is not modified
+	orig := NewWriteErrorMsg(ErrDuplicateKey, "duplicate")
+	we = nil
+	we.Append(orig, 2)
+
+	origErrs := must.NotFail(orig.(*WriteErrors).Document().Get("writeErrors")).(*types.Array)
+	require.Equal(t, 1, origErrs.Len())
+	assert.False(t, must.NotFail(origErrs.Get(0)).(*types.Document).Has("index"))
+}
+
+func TestWithErrorLabels(t *testing.T) {
+	t.Parallel()
+
+	orig := NewErrorMsg(ErrBadValue, "bad value")
+	wrapped := fmt.Errorf("wrapped: %w", orig)
+
+	err := WithErrorLabels(wrapped, "Label1")
+	err = WithErrorLabels(err, "Label2")
+
+	var e *Error
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, ErrBadValue, e.Code())
+	assert.Equal(t, []string{"Label1", "Label2"}, e.Labels())
+
+	// the original error is not modified
+	require.True(t, errors.As(orig, &e))
+	assert.Empty(t, e.Labels())
+
+	// the connection is not closed for labeled internal errors
+	_, recoverable := ProtocolError(WithErrorLabels(errors.New("oops"), RetryableWriteErrorLabel))
+	assert.True(t, recoverable)
+}
@@ -11,54 +11,96 @@ func _() {
 	_ = x[errUnset-0]
 	_ = x[errInternalError-1]
 	_ = x[ErrBadValue-2]
+	_ = x[ErrHostUnreachable-6]
 	_ = x[ErrFailedToParse-9]
+	_ = x[ErrUnauthorized-13]
 	_ = x[ErrTypeMismatch-14]
+	_ = x[ErrProtocolError-17]
+	_ = x[ErrAuthenticationFailed-18]
 	_ = x[ErrNamespaceNotFound-26]
 	_ = x[ErrUnsuitableValueType-28]
 	_ = x[ErrConflictingUpdateOperators-40]
+	_ = x[ErrCursorNotFound-43]
+	_ = x[ErrDollarPrefixedFieldName-52]
 	_ = x[ErrNamespaceExists-48]
 	_ = x[ErrCommandNotFound-59]
+	_ = x[ErrInvalidOptions-72]
 	_ = x[ErrInvalidNamespace-73]
+	_ = x[ErrShutdownInProgress-91]
+	_ = x[ErrOperationFailed-96]
 	_ = x[ErrDocumentValidationFailure-121]
+	_ = x[ErrClientMetadataCannotBeMutated-186]
+	_ = x[ErrBSONObjectTooLarge-10334]
+	_ = x[ErrDuplicateKey-11000]
+	_ = x[ErrNotWritablePrimary-10107]
 	_ = x[ErrNotImplemented-238]
+	_ = x[ErrMechanismUnavailable-334]
+	_ = x[ErrUnsupportedOpQueryCommand-352]
 	_ = x[ErrFailedToParseInput-40415]
+	_ = x[ErrSortTooManyKeys-13103]
 	_ = x[ErrSortBadValue-15974]
 	_ = x[ErrSortBadOrder-15975]
+	_ = x[ErrPathContainsEmptyElement-15998]
 	_ = x[ErrInvalidArg-28667]
 	_ = x[ErrSliceFirstArg-28724]
+	_ = x[ErrProjectionPathCollision-31250]
 	_ = x[ErrProjectionInEx-31253]
 	_ = x[ErrProjectionExIn-31254]
+	_ = x[ErrMissingField-40414]
+	_ = x[ErrChangeStreamNotSupported-40573]
 	_ = x[ErrFreeMonitoringDisabled-50840]
+	_ = x[ErrValueNegative-51024]
 	_ = x[ErrRegexOptions-51075]
 	_ = x[ErrRegexMissingParen-51091]
 }
 
-const _ErrorCode_name = "UnsetInternalErrorBadValueFailedToParseTypeMismatchNamespaceNotFoundUnsuitableValueTypeConflictingUpdateOperatorsNamespaceExistsCommandNotFoundInvalidNamespaceDocumentValidationFailureNotImplementedLocation15974Location15975Location28667Location28724Location31253Location31254Location40415Location50840Location51075Location51091"
+const _ErrorCode_name = "UnsetInternalErrorBadValueHostUnreachableFailedToParseUnauthorizedTypeMismatchProtocolErrorAuthenticationFailedNamespaceNotFoundUnsuitableValueTypeConflictingUpdateOperatorsCursorNotFoundNamespaceExistsDollarPrefixedFieldNameCommandNotFoundInvalidOptionsInvalidNamespaceShutdownInProgressOperationFailedDocumentValidationFailureClientMetadataCannotBeMutatedNotImplementedMechanismUnavailableUnsupportedOpQueryCommandNotWritablePrimaryBSONObjectTooLargeDuplicateKeyLocation13103Location15974Location15975Location15998Location28667Location28724Location31250Location31253Location31254Location40414Location40415Location40573Location50840Location51024Location51075Location51091"
 
 var _ErrorCode_map = map[ErrorCode]string{
 	0:     _ErrorCode_name[0:5],
 	1:     _ErrorCode_name[5:18],
 	2:     _ErrorCode_name[18:26],
-	9:     _ErrorCode_name[26:39],
-	14:    _ErrorCode_name[39:51],
-	26:    _ErrorCode_name[51:68],
-	28:    _ErrorCode_name[68:87],
-	40:    _ErrorCode_name[87:113],
-	48:    _ErrorCode_name[113:128],
-	59:    _ErrorCode_name[128:143],
-	73:    _ErrorCode_name[143:159],
-	121:   _ErrorCode_name[159:184],
-	238:   _ErrorCode_name[184:198],
-	15974: _ErrorCode_name[198:211],
-	15975: _ErrorCode_name[211:224],
-	28667: _ErrorCode_name[224:237],
-	28724: _ErrorCode_name[237:250],
-	31253: _ErrorCode_name[250:263],
-	31254: _ErrorCode_name[263:276],
-	40415: _ErrorCode_name[276:289],
-	50840: _ErrorCode_name[289:302],
-	51075: _ErrorCode_name[302:315],
-	51091: _ErrorCode_name[315:328],
+	6:     _ErrorCode_name[26:41],
+	9:     _ErrorCode_name[41:54],
+	13:    _ErrorCode_name[54:66],
+	14:    _ErrorCode_name[66:78],
+	17:    _ErrorCode_name[78:91],
+	18:    _ErrorCode_name[91:111],
+	26:    _ErrorCode_name[111:128],
+	28:    _ErrorCode_name[128:147],
+	40:    _ErrorCode_name[147:173],
+	43:    _ErrorCode_name[173:187],
+	48:    _ErrorCode_name[187:202],
+	52:    _ErrorCode_name[202:225],
+	59:    _ErrorCode_name[225:240],
+	72:    _ErrorCode_name[240:254],
+	73:    _ErrorCode_name[254:270],
+	91:    _ErrorCode_name[270:288],
+	96:    _ErrorCode_name[288:303],
+	121:   _ErrorCode_name[303:328],
+	186:   _ErrorCode_name[328:357],
+	238:   _ErrorCode_name[357:371],
+	334:   _ErrorCode_name[371:391],
+	352:   _ErrorCode_name[391:416],
+	10107: _ErrorCode_name[416:434],
+	10334: _ErrorCode_name[434:452],
+	11000: _ErrorCode_name[452:464],
+	13103: _ErrorCode_name[464:477],
+	15974: _ErrorCode_name[477:490],
+	15975: _ErrorCode_name[490:503],
+	15998: _ErrorCode_name[503:516],
+	28667: _ErrorCode_name[516:529],
+	28724: _ErrorCode_name[529:542],
+	31250: _ErrorCode_name[542:555],
+	31253: _ErrorCode_name[555:568],
+	31254: _ErrorCode_name[568:581],
+	40414: _ErrorCode_name[581:594],
+	40415: _ErrorCode_name[594:607],
+	40573: _ErrorCode_name[607:620],
+	50840: _ErrorCode_name[620:633],
+	51024: _ErrorCode_name[633:646],
+	51075: _ErrorCode_name[646:659],
+	51091: _ErrorCode_name[659:672],
 }
 
 func (i ErrorCode) String() string {
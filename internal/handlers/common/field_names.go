@@ -0,0 +1,51 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// Field names rules follow MongoDB 5.0: dots and "$"-prefixed field names are allowed in embedded documents,
+// and dots are allowed in top-level field names, but "$"-prefixed top-level field names are not.
+// Literal dotted field names are stored as is; they are interpreted as paths only in filters and update operators.
+//
+// Field names with null bytes are rejected by the types package and wire decoding itself.
+
+// ValidateInsertDocument returns an error if the document can't be inserted because of its field names.
+func ValidateInsertDocument(doc *types.Document) error {
+	for _, key := range doc.Keys() {
+		if strings.HasPrefix(key, "$") {
+			return NewErrorMsg(ErrBadValue, fmt.Sprintf("Document can't have $ prefixed field names: %s", key))
+		}
+	}
+
+	return nil
+}
+
+// validateUpdateFieldName returns an error if the update operator would create "$"-prefixed top-level field.
+func validateUpdateFieldName(key string) error {
+	if prefix, _, _ := strings.Cut(key, "."); strings.HasPrefix(prefix, "$") {
+		return NewWriteErrorMsg(
+			ErrDollarPrefixedFieldName,
+			fmt.Sprintf("The dollar ($) prefixed field '%s' in '%s' is not valid for storage.", prefix, key),
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestValidateInsertDocument(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct { //nolint:paralleltest // false positive
+		doc *types.Document
+		err string
+	}{
+		"Valid": {
+			doc: must.NotFail(types.NewDocument("_id", int32(1), "foo", "bar")),
+		},
+		"Dotted": {
+			doc: must.NotFail(types.NewDocument("a.b", int32(1))),
+		},
+		"EmbeddedDollar": {
+			doc: must.NotFail(types.NewDocument("a", must.NotFail(types.NewDocument("$bar", int32(1))))),
+		},
+		"Dollar": {
+			doc: must.NotFail(types.NewDocument("$foo", int32(1))),
+			err: "Document can't have $ prefixed field names: $foo",
+		},
+		"DollarSecond": {
+			doc: must.NotFail(types.NewDocument("_id", int32(1), "$bar", int32(1))),
+			err: "Document can't have $ prefixed field names: $bar",
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := ValidateInsertDocument(tc.doc)
+			if tc.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.Equal(t, NewErrorMsg(ErrBadValue, tc.err), err)
+		})
+	}
+}
+
+func TestUpdateDocumentFieldNames(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct { //nolint:paralleltest // false positive
+		update *types.Document
+		err    string
+	}{
+		"Set": {
+			update: must.NotFail(types.NewDocument("$set", must.NotFail(types.NewDocument("$foo", int32(1))))),
+			err:    "The dollar ($) prefixed field '$foo' in '$foo' is not valid for storage.",
+		},
+		"SetDotted": {
+			update: must.NotFail(types.NewDocument("$set", must.NotFail(types.NewDocument("$foo.bar", int32(1))))),
+			err:    "The dollar ($) prefixed field '$foo' in '$foo.bar' is not valid for storage.",
+		},
+		"SetEmbedded": {
+			update: must.NotFail(types.NewDocument("$set", must.NotFail(types.NewDocument("foo.$bar", int32(1))))),
+		},
+		"Inc": {
+			update: must.NotFail(types.NewDocument("$inc", must.NotFail(types.NewDocument("$foo", int32(1))))),
+			err:    "The dollar ($) prefixed field '$foo' in '$foo' is not valid for storage.",
+		},
+		"CurrentDate": {
+			update: must.NotFail(types.NewDocument("$currentDate", must.NotFail(types.NewDocument("$foo", true)))),
+			err:    "The dollar ($) prefixed field '$foo' in '$foo' is not valid for storage.",
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			doc := must.NotFail(types.NewDocument("_id", int32(1)))
+
+			_, err := UpdateDocument(doc, tc.update)
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			assert.Equal(t, NewWriteErrorMsg(ErrDollarPrefixedFieldName, tc.err), err)
+		})
+	}
+}
+
+func TestUpdateDocumentCurrentDatePath(t *testing.T) {
+	t.Parallel()
+
+	doc := must.NotFail(types.NewDocument("_id", int32(1), "a", must.NotFail(types.NewDocument())))
+	update := must.NotFail(types.NewDocument("$currentDate", must.NotFail(types.NewDocument("a.b", true))))
+
+	changed, err := UpdateDocument(doc, update)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	assert.False(t, doc.Has("a.b"))
+
+	_, err = doc.GetByPath(types.NewPathFromString("a.b"))
+	assert.NoError(t, err)
+}
@@ -29,20 +29,155 @@ import (
 
 // FilterDocument returns true if given document satisfies given filter expression.
 //
+// The whole filter is parsed and validated first, so invalid filters return an error
+// regardless of the document's content.
+//
 // Passed arguments must not be modified.
 func FilterDocument(doc, filter *types.Document) (bool, error) {
-	filterMap := filter.Map()
-	if len(filterMap) == 0 {
+	if filter.Len() == 0 {
 		return true, nil
 	}
 
-	// top-level filters are ANDed together
-	for _, filterKey := range filter.Keys() {
-		filterValue := filterMap[filterKey]
-		matches, err := filterDocumentPair(doc, filterKey, filterValue)
+	node, err := parseFilter(filter)
+	if err != nil {
+		return false, err
+	}
+
+	return node.match(doc)
+}
+
+// filterNode is a node of the filter expression tree.
+type filterNode interface {
+	// match returns true if the given document satisfies that node.
+	match(doc *types.Document) (bool, error)
+}
+
+// filterAnd is a filterNode that matches documents satisfying all its nodes.
+//
+// Top-level filter entries are implicitly ANDed together.
+type filterAnd []filterNode
+
+// filterOr is a filterNode that matches documents satisfying at least one of its nodes.
+type filterOr []filterNode
+
+// filterNor is a filterNode that matches documents satisfying none of its nodes.
+type filterNor []filterNode
+
+// filterPair is a filterNode for a single field condition {key: value}.
+type filterPair struct {
+	key   string
+	value any
+}
+
+// parseFilter parses filter document into an implicit AND of all its entries.
+func parseFilter(filter *types.Document) (filterAnd, error) {
+	res := make(filterAnd, 0, filter.Len())
+
+	for _, key := range filter.Keys() {
+		value := must.NotFail(filter.Get(key))
+
+		if !strings.HasPrefix(key, "$") {
+			res = append(res, &filterPair{key: key, value: value})
+			continue
+		}
+
+		node, err := parseFilterOperator(key, value)
+		if err != nil {
+			return nil, err
+		}
+
+		if node != nil {
+			res = append(res, node)
+		}
+	}
+
+	return res, nil
+}
+
+// parseFilterOperator parses a top-level operator filter {$operator: value}.
+//
+// It returns nil node for operators that do not affect matching, like $comment.
+func parseFilterOperator(operator string, value any) (filterNode, error) {
+	switch operator {
+	case "$and":
+		// {$and: [{expr1}, {expr2}, ...]}
+		nodes, err := parseFilterOperatorArray(operator, value)
+		if err != nil {
+			return nil, err
+		}
+
+		return filterAnd(nodes), nil
+
+	case "$or":
+		// {$or: [{expr1}, {expr2}, ...]}
+		nodes, err := parseFilterOperatorArray(operator, value)
+		if err != nil {
+			return nil, err
+		}
+
+		return filterOr(nodes), nil
+
+	case "$nor":
+		// {$nor: [{expr1}, {expr2}, ...]}
+		nodes, err := parseFilterOperatorArray(operator, value)
+		if err != nil {
+			return nil, err
+		}
+
+		return filterNor(nodes), nil
+
+	case "$comment":
+		return nil, nil
+
+	default:
+		msg := fmt.Sprintf(
+			`unknown top level operator: %s. `+
+				`If you have a field name that starts with a '$' symbol, consider using $getField or $setField.`,
+			operator,
+		)
+		return nil, NewErrorMsg(ErrBadValue, msg)
+	}
+}
+
+// parseFilterOperatorArray parses the value of $and, $or, or $nor operator
+// that must be a non-empty array of filter documents.
+func parseFilterOperatorArray(operator string, value any) ([]filterNode, error) {
+	exprs, ok := value.(*types.Array)
+	if !ok {
+		return nil, NewErrorMsg(ErrBadValue, operator+" must be an array")
+	}
+
+	if exprs.Len() == 0 {
+		return nil, NewErrorMsg(ErrBadValue, "$and/$or/$nor must be a nonempty array")
+	}
+
+	res := make([]filterNode, exprs.Len())
+
+	for i := 0; i < exprs.Len(); i++ {
+		expr, ok := must.NotFail(exprs.Get(i)).(*types.Document)
+		if !ok {
+			return nil, NewErrorMsg(ErrBadValue, "$or/$and/$nor entries need to be full objects")
+		}
+
+		node, err := parseFilter(expr)
+		if err != nil {
+			return nil, err
+		}
+
+		res[i] = node
+	}
+
+	return res, nil
+}
+
+// match implements filterNode interface.
+func (f filterAnd) match(doc *types.Document) (bool, error) {
+	for _, node := range f {
+		matches, err := node.match(doc)
 		if err != nil {
 			return false, err
 		}
+
 		if !matches {
 			return false, nil
 		}
@@ -51,6 +186,37 @@ func FilterDocument(doc, filter *types.Document) (bool, error) {
 	return true, nil
 }
 
+// match implements filterNode interface.
+func (f filterOr) match(doc *types.Document) (bool, error) {
+	for _, node := range f {
+		matches, err := node.match(doc)
+		if err != nil {
+			return false, err
+		}
+
+		if matches {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// match implements filterNode interface.
+func (f filterNor) match(doc *types.Document) (bool, error) {
+	matches, err := filterOr(f).match(doc)
+	if err != nil {
+		return false, err
+	}
+
+	return !matches, nil
+}
+
+// match implements filterNode interface.
+func (f *filterPair) match(doc *types.Document) (bool, error) {
+	return filterDocumentPair(doc, f.key, f.value)
+}
+
 // filterDocumentPair handles a single filter element key/value pair {filterKey: filterValue}.
 func filterDocumentPair(doc *types.Document, filterKey string, filterValue any) (bool, error) {
 	if strings.ContainsRune(filterKey, '.') {
@@ -92,7 +258,16 @@ func filterDocumentPair(doc *types.Document, filterKey string, filterValue any)
 
 	if strings.HasPrefix(filterKey, "$") {
 		// {$operator: filterValue}
-		return filterOperator(doc, filterKey, filterValue)
+		node, err := parseFilterOperator(filterKey, filterValue)
+		if err != nil {
+			return false, err
+		}
+
+		if node == nil {
+			return true, nil
+		}
+
+		return node.match(doc)
 	}
 
 	switch filterValue := filterValue.(type) {
@@ -133,95 +308,6 @@ func filterDocumentPair(doc *types.Document, filterKey string, filterValue any)
 	}
 }
 
-// filterOperator handles a top-level operator filter {$operator: filterValue}.
-func filterOperator(doc *types.Document, operator string, filterValue any) (bool, error) {
-	switch operator {
-	case "$and":
-		// {$and: [{expr1}, {expr2}, ...]}
-		exprs, ok := filterValue.(*types.Array)
-		if !ok {
-			return false, NewErrorMsg(ErrBadValue, "$and must be an array")
-		}
-		for i := 0; i < exprs.Len(); i++ {
-			value := must.NotFail(exprs.Get(i))
-
-			expr, ok := value.(*types.Document)
-			if !ok {
-				return false, NewErrorMsg(ErrBadValue, "$or/$and/$nor entries need to be full objects")
-			}
-			matches, err := FilterDocument(doc, expr)
-			if err != nil {
-				return false, err
-			}
-			if !matches {
-				return false, nil
-			}
-		}
-		return true, nil
-
-	case "$or":
-		// {$or: [{expr1}, {expr2}, ...]}
-		exprs, ok := filterValue.(*types.Array)
-		if !ok {
-			return false, NewErrorMsg(ErrBadValue, "$or must be an array")
-		}
-		for i := 0; i < exprs.Len(); i++ {
-			value, err := exprs.Get(i)
-			if err != nil {
-				return false, err
-			}
-			expr, ok := value.(*types.Document)
-			if !ok {
-				return false, NewErrorMsg(ErrBadValue, "$or/$and/$nor entries need to be full objects")
-			}
-			matches, err := FilterDocument(doc, expr)
-			if err != nil {
-				return false, err
-			}
-			if matches {
-				return true, nil
-			}
-		}
-		return false, nil
-
-	case "$nor":
-		// {$nor: [{expr1}, {expr2}, ...]}
-		exprs, ok := filterValue.(*types.Array)
-		if !ok {
-			return false, NewErrorMsg(ErrBadValue, "$nor must be an array")
-		}
-		for i := 0; i < exprs.Len(); i++ {
-			value, err := exprs.Get(i)
-			if err != nil {
-				return false, err
-			}
-			expr, ok := value.(*types.Document)
-			if !ok {
-				return false, NewErrorMsg(ErrBadValue, "$or/$and/$nor entries need to be full objects")
-			}
-			matches, err := FilterDocument(doc, expr)
-			if err != nil {
-				return false, err
-			}
-			if matches {
-				return false, nil
-			}
-		}
-		return true, nil
-
-	case "$comment":
-		return true, nil
-
-	default:
-		msg := fmt.Sprintf(
-			`unknown top level operator: %s. `+
-				`If you have a field name that starts with a '$' symbol, consider using $getField or $setField.`,
-			operator,
-		)
-		return false, NewErrorMsg(ErrBadValue, msg)
-	}
-}
-
 // filterFieldExpr handles {field: {expr}} or {field: {document}} filter.
 func filterFieldExpr(doc *types.Document, filterKey string, expr *types.Document) (bool, error) {
 	// check if both documents are empty
@@ -555,9 +641,6 @@ func filterFieldExpr(doc *types.Document, filterKey string, expr *types.Document
 // for pattern matching strings in queries, even if the strings are in an array.
 func filterFieldRegex(fieldValue any, regex types.Regex) (bool, error) {
 	re, err := regex.Compile()
-	if err != nil && err == types.ErrOptionNotImplemented {
-		return false, NewErrorMsg(ErrNotImplemented, `option 'x' not implemented`)
-	}
 	if err != nil {
 		return false, NewError(ErrRegexMissingParen, err)
 	}
@@ -1161,15 +1244,19 @@ func filterFieldValueByTypeCode(fieldValue any, code typeCode) (bool, error) {
 		if _, ok := fieldValue.(int64); !ok {
 			return false, nil
 		}
+	case typeCodeDecimal:
+		if _, ok := fieldValue.(types.Decimal128); !ok {
+			return false, nil
+		}
 	case typeCodeNumber:
-		// typeCodeNumber should match int32, int64 and float64 types
+		// typeCodeNumber should match int32, int64, float64 and Decimal128 types
 		switch fieldValue.(type) {
-		case float64, int32, int64:
+		case float64, int32, int64, types.Decimal128:
 			return true, nil
 		default:
 			return false, nil
 		}
-	case typeCodeDecimal, typeCodeMinKey, typeCodeMaxKey:
+	case typeCodeMinKey, typeCodeMaxKey:
 		return false, NewErrorMsg(ErrNotImplemented, fmt.Sprintf(`Type code %v not implemented`, code))
 	default:
 		return false, NewErrorMsg(ErrBadValue, fmt.Sprintf(`Unknown type name alias: %s`, code.String()))
@@ -0,0 +1,251 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// FindAndModifyParams contains `findAndModify` command parameters supported by at least one handler.
+type FindAndModifyParams struct {
+	DB                 string
+	Collection         string
+	Comment            string
+	Query              *types.Document
+	Sort               *types.Document
+	Update             *types.Document
+	Fields             *types.Document
+	Remove             bool
+	Upsert             bool
+	ReturnNewDocument  bool
+	HasUpdateOperators bool
+	MaxTimeMS          int32
+}
+
+// GetFindAndModifyParams returns `findAndModify` command parameters.
+func GetFindAndModifyParams(document *types.Document, l *zap.Logger) (*FindAndModifyParams, error) {
+	unimplementedFields := []string{
+		"arrayFilters",
+		"let",
+	}
+	if err := Unimplemented(document, unimplementedFields...); err != nil {
+		return nil, err
+	}
+
+	ignoredFields := []string{
+		"bypassDocumentValidation",
+		"writeConcern",
+		"collation",
+		"hint",
+	}
+	Ignored(document, l, ignoredFields...)
+
+	var params FindAndModifyParams
+	var err error
+
+	command := document.Command()
+
+	if params.DB, err = GetRequiredParam[string](document, "$db"); err != nil {
+		return nil, err
+	}
+	if params.Collection, err = GetRequiredParam[string](document, command); err != nil {
+		return nil, err
+	}
+
+	if params.Collection == "" {
+		return nil, NewErrorMsg(
+			ErrInvalidNamespace,
+			fmt.Sprintf("Invalid namespace specified '%s.'", params.DB),
+		)
+	}
+
+	if params.Remove, err = GetBoolOptionalParam(document, "remove"); err != nil {
+		return nil, err
+	}
+	if params.ReturnNewDocument, err = GetBoolOptionalParam(document, "new"); err != nil {
+		return nil, err
+	}
+	if params.Upsert, err = GetBoolOptionalParam(document, "upsert"); err != nil {
+		return nil, err
+	}
+
+	if params.Query, err = GetOptionalParam(document, "query", params.Query); err != nil {
+		return nil, err
+	}
+
+	if params.Sort, err = GetOptionalParam(document, "sort", params.Sort); err != nil {
+		return nil, err
+	}
+
+	if params.Fields, err = GetOptionalParam(document, "fields", params.Fields); err != nil {
+		return nil, err
+	}
+
+	if params.MaxTimeMS, err = GetOptionalPositiveNumber(document, "maxTimeMS"); err != nil {
+		return nil, err
+	}
+
+	updateParam, err := document.Get("update")
+	if err != nil && !params.Remove {
+		return nil, NewErrorMsg(ErrFailedToParse, "Either an update or remove=true must be specified")
+	}
+	if err == nil {
+		switch updateParam := updateParam.(type) {
+		case *types.Document:
+			params.Update = updateParam
+		case *types.Array:
+			// TODO aggregation pipeline stages metrics
+			return nil, NewErrorMsg(ErrNotImplemented, "Aggregation pipelines are not supported yet")
+		default:
+			return nil, NewErrorMsg(ErrFailedToParse, "Update argument must be either an object or an array")
+		}
+	}
+
+	if params.Update != nil && params.Remove {
+		return nil, NewErrorMsg(ErrFailedToParse, "Cannot specify both an update and remove=true")
+	}
+	if params.Upsert && params.Remove {
+		return nil, NewErrorMsg(ErrFailedToParse, "Cannot specify both upsert=true and remove=true")
+	}
+	if params.ReturnNewDocument && params.Remove {
+		return nil, NewErrorMsg(
+			ErrFailedToParse,
+			"Cannot specify both new=true and remove=true; 'remove' always returns the deleted document",
+		)
+	}
+
+	if params.HasUpdateOperators, err = HasSupportedUpdateModifiers(params.Update); err != nil {
+		return nil, err
+	}
+
+	// get comment from a "comment" field
+	if params.Comment, err = GetOptionalParam(document, "comment", params.Comment); err != nil {
+		return nil, err
+	}
+
+	// get comment from query, e.g. db.collection.FindAndModify({"_id":"string", "$comment: "test"},{$set:{"v":"foo""}})
+	if params.Comment, err = GetOptionalParam(params.Query, "$comment", params.Comment); err != nil {
+		return nil, err
+	}
+
+	return &params, nil
+}
+
+// FindAndModifyDocument returns a new version of the found document modified by the `findAndModify` update,
+// or a new document to insert if found is nil.
+//
+// The new document gets `_id` of the found document, or `_id` from the query, or a new ObjectID.
+func FindAndModifyDocument(params *FindAndModifyParams, found *types.Document) (*types.Document, error) {
+	var doc *types.Document
+
+	if params.HasUpdateOperators {
+		if found != nil {
+			doc = found.DeepCopy()
+		} else {
+			doc = must.NotFail(types.NewDocument())
+		}
+
+		if _, err := UpdateDocument(doc, params.Update); err != nil {
+			return nil, err
+		}
+	} else {
+		doc = params.Update.DeepCopy()
+	}
+
+	if !doc.Has("_id") {
+		switch {
+		case found != nil:
+			must.NoError(doc.Set("_id", must.NotFail(found.Get("_id"))))
+		case params.Query != nil && params.Query.Has("_id"):
+			must.NoError(doc.Set("_id", must.NotFail(params.Query.Get("_id"))))
+		default:
+			must.NoError(doc.Set("_id", types.NewObjectID()))
+		}
+	}
+
+	return doc, nil
+}
+
+// FindAndModifyReply returns `findAndModify` command reply.
+//
+// found is the document matched by the query, if any;
+// modified is its new version or the upserted document, if any.
+func FindAndModifyReply(params *FindAndModifyParams, found, modified *types.Document, upserted bool) (*wire.OpMsg, error) {
+	var lastErrorObject, value *types.Document
+
+	switch {
+	case params.Update != nil:
+		if found == nil && !upserted {
+			lastErrorObject = must.NotFail(types.NewDocument("n", int32(0), "updatedExisting", false))
+			break
+		}
+
+		if params.ReturnNewDocument || found == nil {
+			value = modified
+		} else {
+			value = found
+		}
+
+		lastErrorObject = must.NotFail(types.NewDocument(
+			"n", int32(1),
+			"updatedExisting", found != nil,
+		))
+
+		if upserted {
+			must.NoError(lastErrorObject.Set("upserted", must.NotFail(value.Get("_id"))))
+		}
+
+	case params.Remove:
+		if found == nil {
+			lastErrorObject = must.NotFail(types.NewDocument("n", int32(0)))
+			break
+		}
+
+		value = found
+		lastErrorObject = must.NotFail(types.NewDocument("n", int32(1)))
+
+	default:
+		return nil, lazyerrors.New("bad flags combination")
+	}
+
+	res := must.NotFail(types.NewDocument(
+		"lastErrorObject", lastErrorObject,
+	))
+
+	if value != nil {
+		values := []*types.Document{value}
+		if err := ProjectDocuments(values, params.Fields); err != nil {
+			return nil, err
+		}
+
+		must.NoError(res.Set("value", values[0]))
+	}
+
+	must.NoError(res.Set("ok", float64(1)))
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{res},
+	}))
+
+	return &reply, nil
+}
@@ -0,0 +1,176 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestFindAndModifyDocument(t *testing.T) {
+	t.Parallel()
+
+	found := must.NotFail(types.NewDocument("_id", "found", "v", int32(42), "foo", "bar"))
+
+	for name, tc := range map[string]struct { //nolint:paralleltest // false positive
+		params   *FindAndModifyParams
+		found    *types.Document
+		expected *types.Document
+	}{
+		"Operators": {
+			params: &FindAndModifyParams{
+				Update:             must.NotFail(types.NewDocument("$set", must.NotFail(types.NewDocument("v", int32(43))))),
+				HasUpdateOperators: true,
+			},
+			found:    found,
+			expected: must.NotFail(types.NewDocument("_id", "found", "v", int32(43), "foo", "bar")),
+		},
+		"Replacement": {
+			params: &FindAndModifyParams{
+				Update: must.NotFail(types.NewDocument("v", int32(43))),
+			},
+			found:    found,
+			expected: must.NotFail(types.NewDocument("_id", "found", "v", int32(43))),
+		},
+		"UpsertOperatorsQueryID": {
+			params: &FindAndModifyParams{
+				Query:              must.NotFail(types.NewDocument("_id", "query")),
+				Update:             must.NotFail(types.NewDocument("$set", must.NotFail(types.NewDocument("v", int32(43))))),
+				HasUpdateOperators: true,
+			},
+			expected: must.NotFail(types.NewDocument("_id", "query", "v", int32(43))),
+		},
+		"UpsertReplacementID": {
+			params: &FindAndModifyParams{
+				Query:  must.NotFail(types.NewDocument("_id", "query")),
+				Update: must.NotFail(types.NewDocument("_id", "update", "v", int32(43))),
+			},
+			expected: must.NotFail(types.NewDocument("_id", "update", "v", int32(43))),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := FindAndModifyDocument(tc.params, tc.found)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+
+	t.Run("UpsertNewID", func(t *testing.T) {
+		t.Parallel()
+
+		params := &FindAndModifyParams{
+			Update:             must.NotFail(types.NewDocument("$set", must.NotFail(types.NewDocument("v", int32(43))))),
+			HasUpdateOperators: true,
+		}
+
+		actual, err := FindAndModifyDocument(params, nil)
+		require.NoError(t, err)
+		assert.IsType(t, types.ObjectID{}, must.NotFail(actual.Get("_id")))
+	})
+}
+
+func TestFindAndModifyReply(t *testing.T) {
+	t.Parallel()
+
+	found := must.NotFail(types.NewDocument("_id", "id", "v", int32(42), "foo", "bar"))
+	modified := must.NotFail(types.NewDocument("_id", "id", "v", int32(43), "foo", "bar"))
+	update := must.NotFail(types.NewDocument("$set", must.NotFail(types.NewDocument("v", int32(43)))))
+
+	for name, tc := range map[string]struct { //nolint:paralleltest // false positive
+		params   *FindAndModifyParams
+		found    *types.Document
+		modified *types.Document
+		upserted bool
+		expected *types.Document
+	}{
+		"UpdateNotFound": {
+			params: &FindAndModifyParams{Update: update},
+			expected: must.NotFail(types.NewDocument(
+				"lastErrorObject", must.NotFail(types.NewDocument("n", int32(0), "updatedExisting", false)),
+				"ok", float64(1),
+			)),
+		},
+		"UpdateOld": {
+			params:   &FindAndModifyParams{Update: update},
+			found:    found,
+			modified: modified,
+			expected: must.NotFail(types.NewDocument(
+				"lastErrorObject", must.NotFail(types.NewDocument("n", int32(1), "updatedExisting", true)),
+				"value", found,
+				"ok", float64(1),
+			)),
+		},
+		"UpdateNewFields": {
+			params: &FindAndModifyParams{
+				Update:            update,
+				ReturnNewDocument: true,
+				Fields:            must.NotFail(types.NewDocument("v", int32(1))),
+			},
+			found:    found,
+			modified: modified,
+			expected: must.NotFail(types.NewDocument(
+				"lastErrorObject", must.NotFail(types.NewDocument("n", int32(1), "updatedExisting", true)),
+				"value", must.NotFail(types.NewDocument("_id", "id", "v", int32(43))),
+				"ok", float64(1),
+			)),
+		},
+		"Upserted": {
+			params:   &FindAndModifyParams{Update: update, Upsert: true, Fields: must.NotFail(types.NewDocument("_id", false))},
+			modified: modified,
+			upserted: true,
+			expected: must.NotFail(types.NewDocument(
+				"lastErrorObject", must.NotFail(types.NewDocument("n", int32(1), "updatedExisting", false, "upserted", "id")),
+				"value", must.NotFail(types.NewDocument("v", int32(43), "foo", "bar")),
+				"ok", float64(1),
+			)),
+		},
+		"RemoveNotFound": {
+			params: &FindAndModifyParams{Remove: true},
+			expected: must.NotFail(types.NewDocument(
+				"lastErrorObject", must.NotFail(types.NewDocument("n", int32(0))),
+				"ok", float64(1),
+			)),
+		},
+		"Remove": {
+			params: &FindAndModifyParams{Remove: true},
+			found:  found,
+			expected: must.NotFail(types.NewDocument(
+				"lastErrorObject", must.NotFail(types.NewDocument("n", int32(1))),
+				"value", found,
+				"ok", float64(1),
+			)),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			reply, err := FindAndModifyReply(tc.params, tc.found, tc.modified, tc.upserted)
+			require.NoError(t, err)
+
+			actual, err := reply.Document()
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
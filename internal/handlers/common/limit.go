@@ -14,7 +14,88 @@
 
 package common
 
-import "github.com/FerretDB/FerretDB/internal/types"
+import (
+	"fmt"
+	"math"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// GetLimitParam returns the value of the limit parameter of the given command.
+//
+// Zero value means no limit.
+// Negative value -n means a single batch of at most n documents, as in legacy MongoDB clients;
+// in that case, n is returned as the limit, and singleBatch is true.
+// Whole doubles are accepted, fractional ones are rejected.
+func GetLimitParam(document *types.Document, command string) (limit int64, singleBatch bool, err error) {
+	v, _ := document.Get("limit")
+	if v == nil {
+		return 0, false, nil
+	}
+
+	if limit, err = getCountParam(command, "limit", v); err != nil {
+		return 0, false, err
+	}
+
+	if limit >= 0 {
+		return limit, false, nil
+	}
+
+	if limit < math.MinInt32 {
+		return 0, false, NewErrorMsg(
+			ErrValueNegative,
+			fmt.Sprintf("BSON field 'limit' value must be >= %d, actual value '%d'", math.MinInt32, limit),
+		)
+	}
+
+	return -limit, true, nil
+}
+
+// GetSkipParam returns the value of the skip parameter of the given command.
+//
+// Whole doubles are accepted, fractional ones are rejected.
+func GetSkipParam(document *types.Document, command string) (int64, error) {
+	v, _ := document.Get("skip")
+	if v == nil {
+		return 0, nil
+	}
+
+	skip, err := getCountParam(command, "skip", v)
+	if err != nil {
+		return 0, err
+	}
+
+	if skip < 0 {
+		return 0, NewErrorMsg(
+			ErrValueNegative,
+			fmt.Sprintf("BSON field 'skip' value must be >= 0, actual value '%d'", skip),
+		)
+	}
+
+	return skip, nil
+}
+
+// getCountParam returns the integer value of the given command's parameter,
+// such as skip, limit, or batchSize.
+//
+// It returns MongoDB-compatible errors for values of wrong types and for fractional doubles.
+func getCountParam(command, key string, v any) (int64, error) {
+	res, err := GetWholeNumberParam(v)
+	switch err {
+	case nil:
+		return res, nil
+	case errNotWholeNumber:
+		return 0, NewErrorMsg(ErrBadValue, fmt.Sprintf("Expected an integer: %s: %v", key, v))
+	default:
+		return 0, NewErrorMsg(
+			ErrTypeMismatch,
+			fmt.Sprintf(
+				"BSON field '%s.%s' is the wrong type '%s', expected types '[long, int, decimal, double]'",
+				command, key, AliasFromType(v),
+			),
+		)
+	}
+}
 
 // LimitDocuments returns a subslice of given documents according to the given limit.
 func LimitDocuments(docs []*types.Document, limit int64) ([]*types.Document, error) {
@@ -31,3 +112,21 @@ func LimitDocuments(docs []*types.Document, limit int64) ([]*types.Document, err
 		return nil, NewErrorMsg(ErrNotImplemented, "LimitDocuments: negative limit values are not supported")
 	}
 }
+
+// SkipDocuments returns a subslice of given documents according to the given skip value.
+func SkipDocuments(docs []*types.Document, skip int64) ([]*types.Document, error) {
+	switch {
+	case skip == 0:
+		return docs, nil
+	case skip > 0:
+		if int64(len(docs)) <= skip {
+			return []*types.Document{}, nil
+		}
+		return docs[skip:], nil
+	default:
+		return nil, NewErrorMsg(
+			ErrValueNegative,
+			fmt.Sprintf("BSON field 'skip' value must be >= 0, actual value '%d'", skip),
+		)
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestGetLimitParam(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct { //nolint:paralleltest // false positive
+		v           any // nil means absent
+		limit       int64
+		singleBatch bool
+		err         error
+	}{
+		"Absent":   {},
+		"Zero":     {v: int32(0)},
+		"Positive": {v: int32(5), limit: 5},
+		"Long":     {v: int64(math.MaxInt32 + 1), limit: math.MaxInt32 + 1},
+		"Double":   {v: float64(5), limit: 5},
+		"MinusOne": {v: int32(-1), limit: 1, singleBatch: true},
+		"Negative": {v: float64(-5), limit: 5, singleBatch: true},
+		"MinInt32": {v: int32(math.MinInt32), limit: -math.MinInt32, singleBatch: true},
+		"BelowMinInt32": {
+			v:   int64(math.MinInt32 - 1),
+			err: NewErrorMsg(ErrValueNegative, "BSON field 'limit' value must be >= -2147483648, actual value '-2147483649'"),
+		},
+		"Fractional": {
+			v:   1.5,
+			err: NewErrorMsg(ErrBadValue, "Expected an integer: limit: 1.5"),
+		},
+		"String": {
+			v: "5",
+			err: NewErrorMsg(
+				ErrTypeMismatch,
+				"BSON field 'find.limit' is the wrong type 'string', expected types '[long, int, decimal, double]'",
+			),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			doc := must.NotFail(types.NewDocument("find", "values"))
+			if tc.v != nil {
+				must.NoError(doc.Set("limit", tc.v))
+			}
+
+			limit, singleBatch, err := GetLimitParam(doc, "find")
+			if tc.err != nil {
+				assert.Equal(t, tc.err, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.limit, limit)
+			assert.Equal(t, tc.singleBatch, singleBatch)
+		})
+	}
+}
+
+func TestGetSkipParam(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct { //nolint:paralleltest // false positive
+		v    any // nil means absent
+		skip int64
+		err  error
+	}{
+		"Absent": {},
+		"Int32":  {v: int32(5), skip: 5},
+		"Double": {v: float64(5), skip: 5},
+		"Negative": {
+			v:   int64(-1),
+			err: NewErrorMsg(ErrValueNegative, "BSON field 'skip' value must be >= 0, actual value '-1'"),
+		},
+		"Fractional": {
+			v:   -0.5,
+			err: NewErrorMsg(ErrBadValue, "Expected an integer: skip: -0.5"),
+		},
+		"Bool": {
+			v: true,
+			err: NewErrorMsg(
+				ErrTypeMismatch,
+				"BSON field 'count.skip' is the wrong type 'bool', expected types '[long, int, decimal, double]'",
+			),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			doc := must.NotFail(types.NewDocument("count", "values"))
+			if tc.v != nil {
+				must.NoError(doc.Set("skip", tc.v))
+			}
+
+			skip, err := GetSkipParam(doc, "count")
+			if tc.err != nil {
+				assert.Equal(t, tc.err, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.skip, skip)
+		})
+	}
+}
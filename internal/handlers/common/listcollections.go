@@ -0,0 +1,131 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"crypto/sha1"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// ListCollectionsParams contains `listCollections` command parameters supported by all handlers.
+type ListCollectionsParams struct {
+	DB       string
+	Filter   *types.Document
+	NameOnly bool
+}
+
+// GetListCollectionsParams returns `listCollections` command parameters.
+func GetListCollectionsParams(document *types.Document, l *zap.Logger) (*ListCollectionsParams, error) {
+	Ignored(document, l, "comment", "authorizedCollections")
+
+	var lp ListCollectionsParams
+	var err error
+
+	if lp.DB, err = GetRequiredParam[string](document, "$db"); err != nil {
+		return nil, err
+	}
+
+	lp.Filter = new(types.Document)
+	if v, _ := document.Get("filter"); v != nil && v != types.Null {
+		var ok bool
+		if lp.Filter, ok = v.(*types.Document); !ok {
+			return nil, NewErrorMsg(
+				ErrTypeMismatch,
+				fmt.Sprintf("BSON field 'listCollections.filter' is the wrong type '%s', expected type 'object'", AliasFromType(v)),
+			)
+		}
+	}
+
+	if lp.NameOnly, err = GetBoolOptionalParam(document, "nameOnly"); err != nil {
+		return nil, err
+	}
+
+	return &lp, nil
+}
+
+// ListCollectionsInfos returns `listCollections` cursor documents for the given collection names
+// that match the filter.
+//
+// The filter is applied to the full collection information documents; nameOnly is applied after that.
+func ListCollectionsInfos(params *ListCollectionsParams, names []string) (*types.Array, error) {
+	collections := types.MakeArray(len(names))
+
+	for _, name := range names {
+		d := collectionInfo(params.DB, name)
+
+		matches, err := FilterDocument(d, params.Filter)
+		if err != nil {
+			return nil, err
+		}
+
+		if !matches {
+			continue
+		}
+
+		if params.NameOnly {
+			d = must.NotFail(types.NewDocument(
+				"name", name,
+				"type", "collection",
+			))
+		}
+
+		if err = collections.Append(d); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	return collections, nil
+}
+
+// collectionInfo returns `listCollections` information document for the given collection.
+func collectionInfo(db, collection string) *types.Document {
+	return must.NotFail(types.NewDocument(
+		"name", collection,
+		"type", "collection",
+		"options", types.MakeDocument(0),
+		"info", must.NotFail(types.NewDocument(
+			"readOnly", false,
+			"uuid", CollectionUUID(db, collection),
+		)),
+		"idIndex", must.NotFail(types.NewDocument(
+			"v", int32(2),
+			"key", must.NotFail(types.NewDocument("_id", int32(1))),
+			"name", "_id_",
+		)),
+	))
+}
+
+// CollectionUUID returns a stable UUID for the given collection.
+//
+// Neither PostgreSQL nor Tigris backends store collection UUIDs,
+// so it is derived from database and collection names as a name-based (version 5) UUID.
+func CollectionUUID(db, collection string) types.Binary {
+	sum := sha1.Sum([]byte(db + "." + collection))
+
+	b := sum[:16]
+	b[6] = (b[6] & 0x0f) | 0x50 // version 5
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+
+	return types.Binary{
+		Subtype: types.BinaryUUID,
+		B:       b,
+	}
+}
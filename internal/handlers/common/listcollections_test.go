@@ -0,0 +1,97 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestListCollectionsInfos(t *testing.T) {
+	t.Parallel()
+
+	names := []string{"bar", "baz", "foo"}
+
+	for name, tc := range map[string]struct { //nolint:paralleltest // false positive
+		filter   *types.Document
+		nameOnly bool
+		expected []string
+	}{
+		"Empty": {
+			filter:   new(types.Document),
+			expected: []string{"bar", "baz", "foo"},
+		},
+		"Regex": {
+			filter:   must.NotFail(types.NewDocument("name", types.Regex{Pattern: "^ba"})),
+			expected: []string{"bar", "baz"},
+		},
+		"RegexNameOnly": {
+			filter:   must.NotFail(types.NewDocument("name", types.Regex{Pattern: "o$"})),
+			nameOnly: true,
+			expected: []string{"foo"},
+		},
+		"Type": {
+			filter:   must.NotFail(types.NewDocument("type", "view")),
+			expected: []string{},
+		},
+		"ReadOnly": {
+			filter:   must.NotFail(types.NewDocument("info.readOnly", false)),
+			nameOnly: true,
+			expected: []string{"bar", "baz", "foo"},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			params := &ListCollectionsParams{DB: "db", Filter: tc.filter, NameOnly: tc.nameOnly}
+			actual, err := ListCollectionsInfos(params, names)
+			require.NoError(t, err)
+
+			actualNames := make([]string, actual.Len())
+			for i := 0; i < actual.Len(); i++ {
+				d := must.NotFail(actual.Get(i)).(*types.Document)
+				actualNames[i] = must.NotFail(d.Get("name")).(string)
+
+				if tc.nameOnly {
+					assert.Equal(t, []string{"name", "type"}, d.Keys())
+				} else {
+					assert.Equal(t, []string{"name", "type", "options", "info", "idIndex"}, d.Keys())
+				}
+			}
+
+			assert.Equal(t, tc.expected, actualNames)
+		})
+	}
+}
+
+func TestCollectionUUID(t *testing.T) {
+	t.Parallel()
+
+	u := CollectionUUID("db", "foo")
+	assert.Equal(t, types.BinaryUUID, u.Subtype)
+	require.Len(t, u.B, 16)
+	assert.Equal(t, byte(0x50), u.B[6]&0xf0)
+	assert.Equal(t, byte(0x80), u.B[8]&0xc0)
+
+	assert.Equal(t, u, CollectionUUID("db", "foo"))
+	assert.NotEqual(t, u, CollectionUUID("db", "bar"))
+	assert.NotEqual(t, u, CollectionUUID("other", "foo"))
+}
@@ -52,3 +52,16 @@ func MsgBuildInfo(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
 
 	return &reply, nil
 }
+
+// FerretDBBuild returns FerretDB build details for serverStatus.
+func FerretDBBuild() *types.Document {
+	info := version.Get()
+
+	return must.NotFail(types.NewDocument(
+		"version", info.Version,
+		"gitVersion", info.Commit,
+		"branch", info.Branch,
+		"dirty", info.Dirty,
+		"package", info.Package,
+	))
+}
@@ -17,6 +17,7 @@ package common
 import (
 	"context"
 
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
@@ -25,11 +26,19 @@ import (
 
 // MsgConnectionStatus is a common implementation of the connectionStatus command.
 func MsgConnectionStatus(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	users := must.NotFail(types.NewArray())
+	if auth := conninfo.GetConnInfo(ctx).Auth; auth.Authenticated {
+		must.NoError(users.Append(must.NotFail(types.NewDocument(
+			"user", auth.Username,
+			"db", auth.Database,
+		))))
+	}
+
 	var reply wire.OpMsg
 	err := reply.SetSections(wire.OpMsgSection{
 		Documents: []*types.Document{must.NotFail(types.NewDocument(
 			"authInfo", must.NotFail(types.NewDocument(
-				"authenticatedUsers", must.NotFail(types.NewArray()),
+				"authenticatedUsers", users,
 				"authenticatedUserRoles", must.NotFail(types.NewArray()),
 				"authenticatedUserPrivileges", must.NotFail(types.NewArray()),
 			)),
@@ -0,0 +1,126 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// MsgCurrentOp is a common implementation of the currentOp command.
+//
+// Only in-progress commands of all clients are reported; other fields are used as a filter.
+func MsgCurrentOp(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	command := document.Command()
+
+	filter := types.MakeDocument(0)
+	for _, k := range document.Keys() {
+		switch k {
+		case command, "$all", "$ownOps":
+			continue
+		}
+
+		if IsGenericField(k) {
+			continue
+		}
+
+		if strings.HasPrefix(k, "$") && k != "$and" && k != "$or" && k != "$nor" {
+			continue
+		}
+
+		must.NoError(filter.Set(k, must.NotFail(document.Get(k))))
+	}
+
+	var ops []*conninfo.Op
+	if c := conninfo.GetConnInfo(ctx).Connections; c != nil {
+		ops = c.Ops()
+	}
+
+	inprog := types.MakeArray(len(ops))
+
+	for _, op := range ops {
+		entry := currentOpEntry(op)
+
+		matches, err := FilterDocument(entry, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		if matches {
+			must.NoError(inprog.Append(entry))
+		}
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"inprog", inprog,
+			"ok", float64(1),
+		))},
+	}))
+
+	return &reply, nil
+}
+
+// currentOpEntry returns currentOp's inprog entry for the given operation.
+func currentOpEntry(op *conninfo.Op) *types.Document {
+	running := time.Since(op.Start)
+
+	entry := must.NotFail(types.NewDocument(
+		"type", "op",
+		"active", true,
+		"opid", op.ID,
+		"client", op.Client,
+	))
+
+	if md := op.ClientMetadata; md != nil {
+		if md.AppName != "" {
+			must.NoError(entry.Set("appName", md.AppName))
+		}
+
+		must.NoError(entry.Set("clientMetadata", md.Document))
+	}
+
+	must.NoError(entry.Set("secs_running", int64(running.Seconds())))
+	must.NoError(entry.Set("microsecs_running", running.Microseconds()))
+	must.NoError(entry.Set("op", "command"))
+
+	if op.Command != nil {
+		ns, _ := op.Command.Get("$db")
+		if db, ok := ns.(string); ok {
+			if collection, ok := must.NotFail(op.Command.Get(op.Command.Command())).(string); ok {
+				ns = db + "." + collection
+			}
+
+			must.NoError(entry.Set("ns", ns))
+		}
+
+		must.NoError(entry.Set("command", op.Command))
+	}
+
+	return entry
+}
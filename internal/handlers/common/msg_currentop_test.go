@@ -0,0 +1,90 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+func TestMsgCurrentOp(t *testing.T) {
+	t.Parallel()
+
+	conns := conninfo.NewConnections(0)
+	ctx := conninfo.WithConnInfo(context.Background(), &conninfo.ConnInfo{Connections: conns})
+
+	md := conninfo.NewClientMetadata(must.NotFail(types.NewDocument(
+		"application", must.NotFail(types.NewDocument("name", "app")),
+	)))
+
+	find := &conninfo.Op{
+		Client:         "127.0.0.1:12345",
+		ClientMetadata: md,
+		Command:        must.NotFail(types.NewDocument("find", "values", "$db", "test")),
+		Start:          time.Now(),
+	}
+	conns.StartOp(find)
+
+	ping := &conninfo.Op{
+		Client:  "127.0.0.1:12346",
+		Command: must.NotFail(types.NewDocument("ping", int32(1), "$db", "admin")),
+		Start:   time.Now(),
+	}
+	conns.StartOp(ping)
+
+	currentOp := func(t *testing.T, document *types.Document) *types.Array {
+		t.Helper()
+
+		var msg wire.OpMsg
+		require.NoError(t, msg.SetSections(wire.OpMsgSection{Documents: []*types.Document{document}}))
+
+		reply, err := MsgCurrentOp(ctx, &msg)
+		require.NoError(t, err)
+
+		return must.NotFail(must.NotFail(reply.Document()).Get("inprog")).(*types.Array)
+	}
+
+	inprog := currentOp(t, must.NotFail(types.NewDocument("currentOp", int32(1), "$db", "admin")))
+	require.Equal(t, 2, inprog.Len())
+
+	entry := must.NotFail(inprog.Get(0)).(*types.Document)
+	assert.Equal(t, find.ID, must.NotFail(entry.Get("opid")))
+	assert.Equal(t, "app", must.NotFail(entry.Get("appName")))
+	assert.Equal(t, "test.values", must.NotFail(entry.Get("ns")))
+
+	entry = must.NotFail(inprog.Get(1)).(*types.Document)
+	assert.Equal(t, ping.ID, must.NotFail(entry.Get("opid")))
+	assert.Equal(t, "admin", must.NotFail(entry.Get("ns")))
+	assert.False(t, entry.Has("appName"))
+
+	inprog = currentOp(t, must.NotFail(types.NewDocument("currentOp", int32(1), "appName", "app", "$db", "admin")))
+	require.Equal(t, 1, inprog.Len())
+	assert.Equal(t, find.ID, must.NotFail(must.NotFail(inprog.Get(0)).(*types.Document).Get("opid")))
+
+	conns.FinishOp(find)
+	conns.FinishOp(ping)
+
+	inprog = currentOp(t, must.NotFail(types.NewDocument("currentOp", int32(1), "$db", "admin")))
+	assert.Equal(t, 0, inprog.Len())
+}
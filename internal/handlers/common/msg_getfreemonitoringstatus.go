@@ -23,12 +23,20 @@ import (
 	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
-// MsgGetFreeMonitoringStatus is a common implementation of the getFreeMonitoringStatus command.
+// Free monitoring states.
+const (
+	FreeMonitoringUndecided = "undecided"
+	FreeMonitoringEnabled   = "enabled"
+	FreeMonitoringDisabled  = "disabled"
+)
+
+// MsgGetFreeMonitoringStatus is a common implementation of the getFreeMonitoringStatus command
+// for handlers that can't store free monitoring state.
 func MsgGetFreeMonitoringStatus(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
 	var reply wire.OpMsg
 	err := reply.SetSections(wire.OpMsgSection{
 		Documents: []*types.Document{must.NotFail(types.NewDocument(
-			"state", "disabled",
+			"state", FreeMonitoringDisabled,
 			"message", "monitoring is not enabled",
 			"ok", float64(1),
 		))},
@@ -38,3 +46,18 @@ func MsgGetFreeMonitoringStatus(ctx context.Context, msg *wire.OpMsg) (*wire.OpM
 	}
 	return &reply, nil
 }
+
+// GetFreeMonitoringStatusReply returns a reply for the getFreeMonitoringStatus command with the given state.
+func GetFreeMonitoringStatusReply(state string) (*wire.OpMsg, error) {
+	var reply wire.OpMsg
+	err := reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"state", state,
+			"ok", float64(1),
+		))},
+	})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+	return &reply, nil
+}
@@ -75,6 +75,10 @@ var Commands = map[string]command{
 		Help:    "Creates indexes on a collection.",
 		Handler: (handlers.Interface).MsgCreateIndexes,
 	},
+	"currentOp": {
+		Help:    "Returns information about in-progress operations.",
+		Handler: (handlers.Interface).MsgCurrentOp,
+	},
 	"dataSize": {
 		Help:    "Returns the size of the collection in bytes.",
 		Handler: (handlers.Interface).MsgDataSize,
@@ -91,6 +95,10 @@ var Commands = map[string]command{
 		Help:    "Deletes documents matched by the query.",
 		Handler: (handlers.Interface).MsgDelete,
 	},
+	"distinct": {
+		Help:    "Returns an array of distinct values for the given field.",
+		Handler: (handlers.Interface).MsgDistinct,
+	},
 	"drop": {
 		Help:    "Drops the collection.",
 		Handler: (handlers.Interface).MsgDrop,
@@ -123,6 +131,10 @@ var Commands = map[string]command{
 		Help:    "Returns the most recent logged events from memory.",
 		Handler: (handlers.Interface).MsgGetLog,
 	},
+	"getMore": {
+		Help:    "Returns the next batch of documents from the cursor.",
+		Handler: (handlers.Interface).MsgGetMore,
+	},
 	"getParameter": {
 		Help:    "Returns the value of the parameter.",
 		Handler: (handlers.Interface).MsgGetParameter,
@@ -147,6 +159,10 @@ var Commands = map[string]command{
 		Help:    "Returns the role of the FerretDB instance.",
 		Handler: (handlers.Interface).MsgIsMaster,
 	},
+	"killCursors": {
+		Help:    "Closes cursors.",
+		Handler: (handlers.Interface).MsgKillCursors,
+	},
 	"listCollections": {
 		Help:    "Returns the information of the collections and views in the database.",
 		Handler: (handlers.Interface).MsgListCollections,
@@ -159,10 +175,22 @@ var Commands = map[string]command{
 		Help:    "Returns a summary of all the databases.",
 		Handler: (handlers.Interface).MsgListDatabases,
 	},
+	"logout": {
+		Help:    "Logs out the current user.",
+		Handler: (handlers.Interface).MsgLogout,
+	},
 	"ping": {
 		Help:    "Returns a pong response.",
 		Handler: (handlers.Interface).MsgPing,
 	},
+	"saslContinue": {
+		Help:    "Continues the SASL authentication conversation.",
+		Handler: (handlers.Interface).MsgSaslContinue,
+	},
+	"saslStart": {
+		Help:    "Starts the SASL authentication conversation.",
+		Handler: (handlers.Interface).MsgSaslStart,
+	},
 	"serverStatus": {
 		Help:    "Returns an overview of the databases state.",
 		Handler: (handlers.Interface).MsgServerStatus,
@@ -171,6 +199,10 @@ var Commands = map[string]command{
 		Help:    "Toggles free monitoring.",
 		Handler: (handlers.Interface).MsgSetFreeMonitoring,
 	},
+	"setParameter": {
+		Help:    "Sets the value of server parameters.",
+		Handler: (handlers.Interface).MsgSetParameter,
+	},
 	"update": {
 		Help:    "Updates documents that are matched by the query.",
 		Handler: (handlers.Interface).MsgUpdate,
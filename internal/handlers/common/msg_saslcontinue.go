@@ -0,0 +1,53 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// MsgSaslContinue is a common implementation of the saslContinue command.
+//
+// PLAIN conversations are finished by saslStart,
+// so continuing the last successful one just confirms that it is done.
+func MsgSaslContinue(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	conversationID, err := GetRequiredParam[int32](document, "conversationId")
+	if err != nil {
+		return nil, err
+	}
+
+	auth := conninfo.GetConnInfo(ctx).Auth
+	if !auth.Authenticated || auth.ConversationID != conversationID {
+		return nil, NewErrorMsg(ErrProtocolError, "No SASL session state found")
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{saslReply(conversationID)},
+	}))
+
+	return &reply, nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// AuthenticateFunc checks given credentials.
+//
+// It returns false if credentials are invalid, and error if they could not be checked.
+type AuthenticateFunc func(ctx context.Context, username, password string) (bool, error)
+
+// MsgSaslStart is a common implementation of the saslStart command.
+//
+// Only PLAIN mechanism is supported; credentials are checked by the given function.
+// On success, the connection's authentication state is updated.
+func MsgSaslStart(ctx context.Context, msg *wire.OpMsg, authenticate AuthenticateFunc) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	var db, mechanism string
+	if db, err = GetRequiredParam[string](document, "$db"); err != nil {
+		return nil, err
+	}
+
+	if mechanism, err = GetRequiredParam[string](document, "mechanism"); err != nil {
+		return nil, err
+	}
+
+	if mechanism != "PLAIN" {
+		return nil, NewErrorMsg(
+			ErrMechanismUnavailable,
+			fmt.Sprintf("Received authentication for mechanism %s which is not enabled", mechanism),
+		)
+	}
+
+	payload, err := GetRequiredParam[types.Binary](document, "payload")
+	if err != nil {
+		return nil, err
+	}
+
+	// authzid, authcid, and password separated by NUL bytes, see RFC 4616
+	parts := bytes.Split(payload.B, []byte{0})
+	if len(parts) != 3 || len(parts[1]) == 0 {
+		return nil, NewErrorMsg(ErrBadValue, "Incorrectly formatted PLAIN client message")
+	}
+
+	username, password := string(parts[1]), string(parts[2])
+
+	connInfo := conninfo.GetConnInfo(ctx)
+	connInfo.Auth = conninfo.Auth{
+		ConversationID: connInfo.Auth.ConversationID + 1,
+	}
+
+	ok, err := authenticate(ctx, username, password)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if !ok {
+		return nil, NewErrorMsg(ErrAuthenticationFailed, "Authentication failed.")
+	}
+
+	connInfo.Auth.Mechanism = mechanism
+	connInfo.Auth.Username = username
+	connInfo.Auth.Database = db
+	connInfo.Auth.Authenticated = true
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{saslReply(connInfo.Auth.ConversationID)},
+	}))
+
+	return &reply, nil
+}
+
+// saslReply returns a reply document for the finished SASL conversation.
+func saslReply(conversationID int32) *types.Document {
+	return must.NotFail(types.NewDocument(
+		"conversationId", conversationID,
+		"done", true,
+		"payload", types.Binary{},
+		"ok", float64(1),
+	))
+}
@@ -18,36 +18,49 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
-// MsgSetFreeMonitoring is a common implementation of the setFreeMonitoring command.
+// MsgSetFreeMonitoring is a common implementation of the setFreeMonitoring command
+// for handlers that can't store free monitoring state.
 func MsgSetFreeMonitoring(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
 	document, err := msg.Document()
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
-	command := document.Command()
-	var action string
-	if action, err = GetRequiredParam[string](document, "action"); err != nil {
+	if _, err = GetFreeMonitoringAction(document); err != nil {
 		return nil, err
 	}
 
+	return nil, NewErrorMsg(
+		ErrFreeMonitoringDisabled,
+		"Free Monitoring has been disabled via the command-line and/or config file",
+	)
+}
+
+// GetFreeMonitoringAction returns the free monitoring state requested by the setFreeMonitoring command:
+// FreeMonitoringEnabled for "enable" action, and FreeMonitoringDisabled for "disable" action.
+func GetFreeMonitoringAction(document *types.Document) (string, error) {
+	action, err := GetRequiredParam[string](document, "action")
+	if err != nil {
+		return "", err
+	}
+
 	switch action {
-	case "enable", "disable":
-		return nil, NewErrorMsg(
-			ErrFreeMonitoringDisabled,
-			"Free Monitoring has been disabled via the command-line and/or config file",
-		)
+	case "enable":
+		return FreeMonitoringEnabled, nil
+	case "disable":
+		return FreeMonitoringDisabled, nil
 	default:
-		return nil, NewErrorMsg(
+		return "", NewErrorMsg(
 			ErrBadValue,
 			fmt.Sprintf(
-				"Enumeration value '%s' for field '%s' is not a valid value.",
+				"Enumeration value '%s' for field '%s' is not a valid value. Expected one of: 'enable', 'disable'.",
 				action,
-				command+".action",
+				document.Command()+".action",
 			),
 		)
 	}
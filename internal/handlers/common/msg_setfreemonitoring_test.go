@@ -0,0 +1,82 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestGetFreeMonitoringAction(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		action   any
+		expected string
+		err      error
+	}{
+		"Enable": {
+			action:   "enable",
+			expected: FreeMonitoringEnabled,
+		},
+		"Disable": {
+			action:   "disable",
+			expected: FreeMonitoringDisabled,
+		},
+		"Invalid": {
+			action: "foobar",
+			err: NewErrorMsg(
+				ErrBadValue,
+				"Enumeration value 'foobar' for field 'setFreeMonitoring.action' is not a valid value. "+
+					"Expected one of: 'enable', 'disable'.",
+			),
+		},
+		"Empty": {
+			action: "",
+			err: NewErrorMsg(
+				ErrBadValue,
+				"Enumeration value '' for field 'setFreeMonitoring.action' is not a valid value. "+
+					"Expected one of: 'enable', 'disable'.",
+			),
+		},
+		"NotString": {
+			action: int32(1),
+			err: NewErrorMsg(
+				ErrBadValue,
+				`required parameter "action" has type int32 (expected string)`,
+			),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			doc := must.NotFail(types.NewDocument("setFreeMonitoring", int32(1), "action", tc.action))
+			actual, err := GetFreeMonitoringAction(doc)
+			if tc.err != nil {
+				assert.Equal(t, tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/cursor"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// SlowOpsParameters returns slowms and sampleRate parameters values.
+func SlowOpsParameters(ctx context.Context) (slowMS int64, sampleRate float64) {
+	if s := conninfo.GetConnInfo(ctx).SlowOps; s != nil {
+		return s.SlowMS(), s.SampleRate()
+	}
+
+	return 0, 0
+}
+
+// ReadOnly returns true if the listener's read-only mode is enabled.
+func ReadOnly(ctx context.Context) bool {
+	if r := conninfo.GetConnInfo(ctx).ReadOnly; r != nil {
+		return r.Enabled()
+	}
+
+	return false
+}
+
+// MsgSetParameter is a common implementation of the setParameter command.
+//
+// Only slowms, sampleRate, readOnly, and cursorTimeoutMillis parameters are supported.
+// The last one sets the timeout of the given cursor registry; it is not supported if registry is nil.
+// readOnly is not supported if the connection has no read-only mode setting.
+func MsgSetParameter(ctx context.Context, msg *wire.OpMsg, cursors *cursor.Registry) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	s := conninfo.GetConnInfo(ctx).SlowOps
+	if s == nil {
+		return nil, lazyerrors.New("slow operations settings are not set")
+	}
+
+	command := document.Command()
+
+	// validate all parameters before setting any of them
+	var slowMS *int64
+	var sampleRate *float64
+	var readOnly *bool
+	var cursorTimeout *time.Duration
+	var was any
+
+	for _, k := range document.Keys() {
+		if k == command || IsGenericField(k) || strings.HasPrefix(k, "$") {
+			continue
+		}
+
+		v := must.NotFail(document.Get(k))
+
+		switch k {
+		case "slowms":
+			ms, err := GetWholeNumberParam(v)
+			if err != nil {
+				return nil, NewErrorMsg(
+					ErrBadValue,
+					fmt.Sprintf("BSON field '%s' must be a whole number, got %s", k, AliasFromType(v)),
+				)
+			}
+
+			slowMS = &ms
+			was = s.SlowMS()
+
+		case "sampleRate":
+			var rate float64
+			switch v := v.(type) {
+			case float64:
+				rate = v
+			case int32:
+				rate = float64(v)
+			case int64:
+				rate = float64(v)
+			default:
+				return nil, NewErrorMsg(
+					ErrBadValue,
+					fmt.Sprintf("BSON field '%s' must be a number, got %s", k, AliasFromType(v)),
+				)
+			}
+
+			if !(rate >= 0 && rate <= 1) {
+				return nil, NewErrorMsg(ErrBadValue, "'sampleRate' must be between 0.0 and 1.0 inclusive")
+			}
+
+			sampleRate = &rate
+			was = s.SampleRate()
+
+		case "readOnly":
+			r := conninfo.GetConnInfo(ctx).ReadOnly
+			if r == nil {
+				return nil, NewErrorMsg(
+					ErrInvalidOptions,
+					fmt.Sprintf("attempted to set unrecognized parameter [%s], use help:true to see options ", k),
+				)
+			}
+
+			enabled, ok := v.(bool)
+			if !ok {
+				return nil, NewErrorMsg(
+					ErrBadValue,
+					fmt.Sprintf("BSON field '%s' must be a boolean, got %s", k, AliasFromType(v)),
+				)
+			}
+
+			readOnly = &enabled
+			was = r.Enabled()
+
+		case "cursorTimeoutMillis":
+			if cursors == nil {
+				return nil, NewErrorMsg(
+					ErrInvalidOptions,
+					fmt.Sprintf("attempted to set unrecognized parameter [%s], use help:true to see options ", k),
+				)
+			}
+
+			ms, err := GetWholeNumberParam(v)
+			if err != nil {
+				return nil, NewErrorMsg(
+					ErrBadValue,
+					fmt.Sprintf("BSON field '%s' must be a whole number, got %s", k, AliasFromType(v)),
+				)
+			}
+
+			if ms <= 0 {
+				return nil, NewErrorMsg(ErrBadValue, "'cursorTimeoutMillis' must be greater than 0")
+			}
+
+			d := time.Duration(ms) * time.Millisecond
+			cursorTimeout = &d
+			was = cursors.Timeout().Milliseconds()
+
+		default:
+			return nil, NewErrorMsg(
+				ErrInvalidOptions,
+				fmt.Sprintf("attempted to set unrecognized parameter [%s], use help:true to see options ", k),
+			)
+		}
+	}
+
+	if was == nil {
+		return nil, NewErrorMsg(ErrInvalidOptions, "no option found to set, use help:true to see options ")
+	}
+
+	if slowMS != nil {
+		s.SetSlowMS(*slowMS)
+	}
+
+	if sampleRate != nil {
+		s.SetSampleRate(*sampleRate)
+	}
+
+	if readOnly != nil {
+		conninfo.GetConnInfo(ctx).ReadOnly.Set(*readOnly)
+	}
+
+	if cursorTimeout != nil {
+		cursors.SetTimeout(*cursorTimeout)
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"was", was,
+			"ok", float64(1),
+		))},
+	}))
+
+	return &reply, nil
+}
@@ -0,0 +1,154 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/cursor"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+func TestMsgSetParameter(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		document      *types.Document
+		was           any
+		slowMS        int64
+		sampleRate    float64
+		readOnly      bool
+		cursorTimeout time.Duration // zero means cursor.DefaultTimeout
+		err           error
+	}{
+		"SlowMS": {
+			document:   must.NotFail(types.NewDocument("setParameter", int32(1), "slowms", int32(200), "$db", "admin")),
+			was:        int64(100),
+			slowMS:     200,
+			sampleRate: 0,
+		},
+		"SampleRate": {
+			document:   must.NotFail(types.NewDocument("setParameter", int32(1), "sampleRate", 0.5, "$db", "admin")),
+			was:        float64(0),
+			slowMS:     100,
+			sampleRate: 0.5,
+		},
+		"ReadOnly": {
+			document:   must.NotFail(types.NewDocument("setParameter", int32(1), "readOnly", true, "$db", "admin")),
+			was:        false,
+			slowMS:     100,
+			sampleRate: 0,
+			readOnly:   true,
+		},
+		"ReadOnlyNotBool": {
+			document: must.NotFail(types.NewDocument(
+				"setParameter", int32(1), "slowms", int32(200), "readOnly", int32(1), "$db", "admin",
+			)),
+			slowMS:     100,
+			sampleRate: 0,
+			err:        NewErrorMsg(ErrBadValue, "BSON field 'readOnly' must be a boolean, got int"),
+		},
+		"CursorTimeoutMillis": {
+			document: must.NotFail(types.NewDocument(
+				"setParameter", int32(1), "cursorTimeoutMillis", int64(1000), "$db", "admin",
+			)),
+			was:           int64(600000),
+			slowMS:        100,
+			sampleRate:    0,
+			cursorTimeout: time.Second,
+		},
+		"CursorTimeoutMillisZero": {
+			document: must.NotFail(types.NewDocument(
+				"setParameter", int32(1), "slowms", int32(200), "cursorTimeoutMillis", int32(0), "$db", "admin",
+			)),
+			slowMS:     100,
+			sampleRate: 0,
+			err:        NewErrorMsg(ErrBadValue, "'cursorTimeoutMillis' must be greater than 0"),
+		},
+		"SampleRateTooLarge": {
+			document:   must.NotFail(types.NewDocument("setParameter", int32(1), "sampleRate", 1.5, "$db", "admin")),
+			slowMS:     100,
+			sampleRate: 0,
+			err:        NewErrorMsg(ErrBadValue, "'sampleRate' must be between 0.0 and 1.0 inclusive"),
+		},
+		"SlowMSNotNumber": {
+			document: must.NotFail(types.NewDocument(
+				"setParameter", int32(1), "sampleRate", 0.5, "slowms", "fast", "$db", "admin",
+			)),
+			slowMS:     100,
+			sampleRate: 0,
+			err:        NewErrorMsg(ErrBadValue, "BSON field 'slowms' must be a whole number, got string"),
+		},
+		"Unknown": {
+			document:   must.NotFail(types.NewDocument("setParameter", int32(1), "foo", int32(1), "$db", "admin")),
+			slowMS:     100,
+			sampleRate: 0,
+			err: NewErrorMsg(
+				ErrInvalidOptions,
+				"attempted to set unrecognized parameter [foo], use help:true to see options ",
+			),
+		},
+		"Empty": {
+			document:   must.NotFail(types.NewDocument("setParameter", int32(1), "$db", "admin")),
+			slowMS:     100,
+			sampleRate: 0,
+			err:        NewErrorMsg(ErrInvalidOptions, "no option found to set, use help:true to see options "),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			s := conninfo.NewSlowOps(100, 0)
+			r := conninfo.NewReadOnly(false)
+			ctx := conninfo.WithConnInfo(context.Background(), &conninfo.ConnInfo{SlowOps: s, ReadOnly: r})
+
+			var msg wire.OpMsg
+			require.NoError(t, msg.SetSections(wire.OpMsgSection{Documents: []*types.Document{tc.document}}))
+
+			cursors := cursor.NewRegistry()
+			defer cursors.Close()
+
+			res, err := MsgSetParameter(ctx, &msg, cursors)
+
+			// settings are not changed partially on error
+			assert.Equal(t, tc.slowMS, s.SlowMS())
+			assert.Equal(t, tc.sampleRate, s.SampleRate())
+			assert.Equal(t, tc.readOnly, r.Enabled())
+
+			cursorTimeout := tc.cursorTimeout
+			if cursorTimeout == 0 {
+				cursorTimeout = cursor.DefaultTimeout
+			}
+			assert.Equal(t, cursorTimeout, cursors.Timeout())
+
+			if tc.err != nil {
+				assert.Equal(t, tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.was, must.NotFail(must.NotFail(res.Document()).Get("was")))
+		})
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// opcountersCommands maps command names to serverStatus.opcounters fields.
+// All other commands are counted as "command".
+var opcountersCommands = map[string]string{
+	"insert":  "insert",
+	"find":    "query",
+	"update":  "update",
+	"delete":  "delete",
+	"getMore": "getmore",
+}
+
+// Opcounters returns serverStatus.opcounters document.
+//
+// It is derived from the handled commands metrics, so both always agree.
+func Opcounters(ctx context.Context) *types.Document {
+	counters := map[string]int64{
+		"insert":  0,
+		"query":   0,
+		"update":  0,
+		"delete":  0,
+		"getmore": 0,
+		"command": 0,
+	}
+
+	if m := conninfo.GetConnInfo(ctx).Commands; m != nil {
+		ch := make(chan prometheus.Metric)
+		go func() {
+			m.Collect(ch)
+			close(ch)
+		}()
+
+		for metric := range ch {
+			var pb dto.Metric
+			must.NoError(metric.Write(&pb))
+
+			field := "command"
+			for _, l := range pb.GetLabel() {
+				if l.GetName() != "command" {
+					continue
+				}
+
+				if f, ok := opcountersCommands[l.GetValue()]; ok {
+					field = f
+				}
+			}
+
+			counters[field] += int64(pb.GetCounter().GetValue())
+		}
+	}
+
+	return must.NotFail(types.NewDocument(
+		"insert", counters["insert"],
+		"query", counters["query"],
+		"update", counters["update"],
+		"delete", counters["delete"],
+		"getmore", counters["getmore"],
+		"command", counters["command"],
+	))
+}
@@ -0,0 +1,51 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestOpcounters(t *testing.T) {
+	t.Parallel()
+
+	m := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "commands_total"}, []string{"command", "result"})
+	m.WithLabelValues("insert", "ok").Add(2)
+	m.WithLabelValues("insert", "DuplicateKey").Inc()
+	m.WithLabelValues("find", "ok").Inc()
+	m.WithLabelValues("getMore", "ok").Inc()
+	m.WithLabelValues("ping", "ok").Add(3)
+	m.WithLabelValues("unknown", "CommandNotFound").Inc()
+
+	ctx := conninfo.WithConnInfo(context.Background(), &conninfo.ConnInfo{Commands: m})
+
+	expected := must.NotFail(types.NewDocument(
+		"insert", int64(3),
+		"query", int64(1),
+		"update", int64(0),
+		"delete", int64(0),
+		"getmore", int64(1),
+		"command", int64(4),
+	))
+	assert.Equal(t, expected, Opcounters(ctx))
+}
@@ -70,6 +70,25 @@ func GetBoolOptionalParam(doc *types.Document, key string) (bool, error) {
 		return false, nil
 	}
 
+	return boolParam(key, v)
+}
+
+// GetBoolParam returns doc's bool value for the required key.
+// Values are converted the same way as in GetBoolOptionalParam,
+// but a missing field returns a protocol error.
+func GetBoolParam(doc *types.Document, key string) (bool, error) {
+	v, err := doc.Get(key)
+	if err != nil {
+		msg := fmt.Sprintf("required parameter %q is missing", key)
+		return false, NewErrorMsg(ErrBadValue, msg)
+	}
+
+	return boolParam(key, v)
+}
+
+// boolParam converts the given value of the given parameter to bool,
+// see GetBoolOptionalParam.
+func boolParam(key string, v any) (bool, error) {
 	switch v := v.(type) {
 	case float64:
 		return v != 0, nil
@@ -110,12 +129,10 @@ func AssertType[T types.Type](value any) (T, error) {
 }
 
 var (
-	errUnexpectedType        = fmt.Errorf("unexpected type")
-	errNotWholeNumber        = fmt.Errorf("not a whole number")
-	errNegativeNumber        = fmt.Errorf("negative number")
-	errNotBinaryMask         = fmt.Errorf("not a binary mask")
-	errUnexpectedLeftOpType  = fmt.Errorf("unexpected left operand type")
-	errUnexpectedRightOpType = fmt.Errorf("unexpected right operand type")
+	errUnexpectedType = fmt.Errorf("unexpected type")
+	errNotWholeNumber = fmt.Errorf("not a whole number")
+	errNegativeNumber = fmt.Errorf("negative number")
+	errNotBinaryMask  = fmt.Errorf("not a binary mask")
 )
 
 // GetWholeNumberParam checks if the given value is int32, int64, or float64 containing a whole number,
@@ -221,49 +238,6 @@ func parseTypeCode(alias string) (typeCode, error) {
 	return code, nil
 }
 
-// addNumbers returns the result of v1 and v2 addition and error if addition failed.
-// The v1 and v2 parameters could be float64, int32, int64.
-// The result would be the broader type possible, i.e. int32 + int64 produces int64.
-func addNumbers(v1, v2 any) (any, error) {
-	switch v1 := v1.(type) {
-	case float64:
-		switch v2 := v2.(type) {
-		case float64:
-			return v1 + v2, nil
-		case int32:
-			return v1 + float64(v2), nil
-		case int64:
-			return v1 + float64(v2), nil
-		default:
-			return nil, errUnexpectedRightOpType
-		}
-	case int32:
-		switch v2 := v2.(type) {
-		case float64:
-			return v2 + float64(v1), nil
-		case int32:
-			return v1 + v2, nil
-		case int64:
-			return v2 + int64(v1), nil
-		default:
-			return nil, errUnexpectedRightOpType
-		}
-	case int64:
-		switch v2 := v2.(type) {
-		case float64:
-			return v2 + float64(v1), nil
-		case int32:
-			return v1 + int64(v2), nil
-		case int64:
-			return v1 + v2, nil
-		default:
-			return nil, errUnexpectedRightOpType
-		}
-	default:
-		return nil, errUnexpectedLeftOpType
-	}
-}
-
 // GetOptionalPositiveNumber returns doc's value for key or protocol error for invalid parameter.
 func GetOptionalPositiveNumber(document *types.Document, key string) (int32, error) {
 	v, err := document.Get(key)
@@ -307,3 +281,37 @@ func GetOptionalPositiveNumber(document *types.Document, key string) (int32, err
 
 	return value, nil
 }
+
+// GetScaleParam returns `scale` parameter value of `dbStats`-like commands or protocol error for invalid parameter.
+//
+// Absent or null value means no scaling; the fractional part is discarded.
+func GetScaleParam(document *types.Document, command string) (float64, error) {
+	v, err := document.Get("scale")
+	if err != nil || v == types.Null {
+		return 1, nil
+	}
+
+	var scale float64
+	switch v := v.(type) {
+	case float64:
+		scale = math.Trunc(v)
+	case int32:
+		scale = float64(v)
+	case int64:
+		scale = float64(v)
+	default:
+		return 0, NewErrorMsg(
+			ErrTypeMismatch,
+			fmt.Sprintf(
+				"BSON field '%s.scale' is the wrong type '%s', expected types '[long, int, decimal, double]'",
+				command, AliasFromType(v),
+			),
+		)
+	}
+
+	if !(scale > 0) {
+		return 0, NewErrorMsg(ErrValueNegative, "scale has to be > 0")
+	}
+
+	return scale, nil
+}
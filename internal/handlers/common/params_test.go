@@ -0,0 +1,92 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestGetBoolParam(t *testing.T) {
+	t.Parallel()
+
+	typeMismatch := func(alias string) error {
+		return NewErrorMsg(
+			ErrTypeMismatch,
+			"BSON field 'v' is the wrong type '"+alias+"', expected types '[bool, long, int, decimal, double]'",
+		)
+	}
+
+	for name, tc := range map[string]struct { //nolint:paralleltest // false positive
+		v        any // nil means absent
+		expected bool
+		err      error
+		reqErr   error // for GetBoolParam, if different
+	}{
+		"Absent": {
+			expected: false,
+			reqErr:   NewErrorMsg(ErrBadValue, `required parameter "v" is missing`),
+		},
+		"True":       {v: true, expected: true},
+		"False":      {v: false, expected: false},
+		"Null":       {v: types.Null, expected: false},
+		"Int32":      {v: int32(1), expected: true},
+		"Int32Zero":  {v: int32(0), expected: false},
+		"Int64":      {v: int64(-1), expected: true},
+		"Int64Zero":  {v: int64(0), expected: false},
+		"Double":     {v: 0.5, expected: true},
+		"DoubleZero": {v: 0.0, expected: false},
+		"String":     {v: "true", err: typeMismatch("string")},
+		"Document":   {v: must.NotFail(types.NewDocument()), err: typeMismatch("object")},
+		"Array":      {v: must.NotFail(types.NewArray()), err: typeMismatch("array")},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			doc := must.NotFail(types.NewDocument())
+			if tc.v != nil {
+				must.NoError(doc.Set("v", tc.v))
+			}
+
+			actual, err := GetBoolOptionalParam(doc, "v")
+			if tc.err != nil {
+				assert.Equal(t, tc.err, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, actual)
+			}
+
+			reqErr := tc.err
+			if tc.reqErr != nil {
+				reqErr = tc.reqErr
+			}
+
+			actual, err = GetBoolParam(doc, "v")
+			if reqErr != nil {
+				assert.Equal(t, reqErr, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
@@ -17,7 +17,7 @@ package common
 import (
 	"fmt"
 	"math"
-	"strconv"
+	"strings"
 
 	"golang.org/x/exp/slices"
 
@@ -101,7 +101,11 @@ func isProjectionInclusion(projection *types.Document) (inclusion bool, err erro
 	return
 }
 
-// ProjectDocuments modifies given documents in places according to the given projection.
+// ProjectDocuments replaces given documents with their copies modified according to the given projection.
+//
+// Dotted projection keys go through embedded documents and arrays of them (see projectionNode).
+//
+// Documents are copied first because nested documents and arrays could be shared with other documents.
 func ProjectDocuments(docs []*types.Document, projection *types.Document) error {
 	if projection.Len() == 0 {
 		return nil
@@ -112,8 +116,15 @@ func ProjectDocuments(docs []*types.Document, projection *types.Document) error
 		return err
 	}
 
+	root, err := newProjectionTree(projection)
+	if err != nil {
+		return err
+	}
+
 	for i := 0; i < len(docs); i++ {
-		err = projectDocument(inclusion, docs[i], projection)
+		docs[i] = docs[i].DeepCopy()
+
+		err = root.projectDocument(inclusion, docs[i], true)
 		if err != nil {
 			return err
 		}
@@ -122,42 +133,156 @@ func ProjectDocuments(docs []*types.Document, projection *types.Document) error
 	return nil
 }
 
-func projectDocument(inclusion bool, doc *types.Document, projection *types.Document) error {
-	projectionMap := projection.Map()
+// projectionNode is a node of the projection tree built from (possibly dotted) projection keys.
+//
+// For example, projection {"a.b": 1, "a.c": 1, d: 1} is a tree with "a" and "d" children of the root node;
+// "a" has "b" and "c" leaf children.
+//
+// When a path goes through an array, the rest of the path is applied to each array element:
+// documents are projected, nested arrays are handled the same way, and other values are dropped
+// in inclusion mode and kept in exclusion mode.
+type projectionNode struct {
+	children map[string]*projectionNode // nil for leaf nodes
+	value    any                        // projection value of leaf nodes
+}
+
+// newProjectionTree returns the root node of the projection tree.
+func newProjectionTree(projection *types.Document) (*projectionNode, error) {
+	root := &projectionNode{children: map[string]*projectionNode{}}
+
+	for _, key := range projection.Keys() {
+		path := strings.Split(key, ".")
+
+		node := root
+		for i, k := range path {
+			if node.children == nil {
+				return nil, NewErrorMsg(
+					ErrProjectionPathCollision,
+					fmt.Sprintf("Path collision at %s remaining portion %s", key, strings.Join(path[i:], ".")),
+				)
+			}
 
-	for k1 := range doc.Map() {
-		projectionVal, ok := projectionMap[k1]
+			child, ok := node.children[k]
+			if !ok {
+				child = new(projectionNode)
+				if i < len(path)-1 {
+					child.children = map[string]*projectionNode{}
+				}
+
+				node.children[k] = child
+			} else if i == len(path)-1 {
+				return nil, NewErrorMsg(ErrProjectionPathCollision, fmt.Sprintf("Path collision at %s", key))
+			}
+
+			node = child
+		}
+
+		node.value = must.NotFail(projection.Get(key))
+	}
+
+	return root, nil
+}
+
+// projectDocument modifies doc in place according to the projection tree rooted at n.
+//
+// top is true for the top-level document where _id is included by default.
+func (n *projectionNode) projectDocument(inclusion bool, doc *types.Document, top bool) error {
+	for _, key := range slices.Clone(doc.Keys()) {
+		child, ok := n.children[key]
 		if !ok {
-			if k1 == "_id" { // if _id is not in projection map, do not do anything with it
+			if top && key == "_id" { // if _id is not in projection map, do not do anything with it
 				continue
 			}
-			if inclusion { // k1 from doc is absent in projection, remove from doc only if projection type inclusion
-				doc.Remove(k1)
+			if inclusion { // key from doc is absent in projection, remove from doc only if projection type inclusion
+				doc.Remove(key)
 			}
 			continue
 		}
 
-		switch projectionVal := projectionVal.(type) { // found in the projection
-		case *types.Document: // field: { $elemMatch: { field2: value }}
-			if err := applyComplexProjection(k1, doc, projectionVal); err != nil {
+		if child.children == nil {
+			if err := child.projectLeaf(key, doc); err != nil {
 				return err
 			}
+			continue
+		}
 
-		case float64, int32, int64: // field: number
-			result := types.Compare(projectionVal, int32(0))
-			if types.ContainsCompareResult(result, types.Equal) {
-				doc.Remove(k1)
+		switch v := must.NotFail(doc.Get(key)).(type) {
+		case *types.Document:
+			if err := child.projectDocument(inclusion, v, false); err != nil {
+				return err
 			}
 
-		case bool: // field: bool
-			if !projectionVal {
-				doc.Remove(k1)
+		case *types.Array:
+			arr, err := child.projectArray(inclusion, v)
+			if err != nil {
+				return err
+			}
+
+			must.NoError(doc.Set(key, arr))
+
+		default:
+			// the rest of the path can't be applied to scalar values
+			if inclusion {
+				doc.Remove(key)
+			}
+		}
+	}
+
+	return nil
+}
+
+// projectArray returns a copy of arr with the projection tree rooted at n applied to each element.
+func (n *projectionNode) projectArray(inclusion bool, arr *types.Array) (*types.Array, error) {
+	res := types.MakeArray(arr.Len())
+
+	for i := 0; i < arr.Len(); i++ {
+		switch v := must.NotFail(arr.Get(i)).(type) {
+		case *types.Document:
+			if err := n.projectDocument(inclusion, v, false); err != nil {
+				return nil, err
+			}
+
+			must.NoError(res.Append(v))
+
+		case *types.Array:
+			nested, err := n.projectArray(inclusion, v)
+			if err != nil {
+				return nil, err
 			}
 
+			must.NoError(res.Append(nested))
+
 		default:
-			return lazyerrors.Errorf("unsupported operation %s %v (%T)", k1, projectionVal, projectionVal)
+			if !inclusion {
+				must.NoError(res.Append(v))
+			}
 		}
 	}
+
+	return res, nil
+}
+
+// projectLeaf applies the projection value of the leaf node n to the given field of doc.
+func (n *projectionNode) projectLeaf(key string, doc *types.Document) error {
+	switch projectionVal := n.value.(type) { // found in the projection
+	case *types.Document: // field: { $elemMatch: { field2: value }}
+		return applyComplexProjection(key, doc, projectionVal)
+
+	case float64, int32, int64: // field: number
+		result := types.Compare(projectionVal, int32(0))
+		if types.ContainsCompareResult(result, types.Equal) {
+			doc.Remove(key)
+		}
+
+	case bool: // field: bool
+		if !projectionVal {
+			doc.Remove(key)
+		}
+
+	default:
+		return lazyerrors.Errorf("unsupported operation %s %v (%T)", key, projectionVal, projectionVal)
+	}
+
 	return nil
 }
 
@@ -182,7 +307,7 @@ func applyComplexProjection(k1 string, doc, projectionVal *types.Document) (err
 			conditions := must.NotFail(projectionVal.Get(projectionType)).(*types.Document)
 
 			var found int
-			found, err = filterFieldArrayElemMatch(k1, doc, conditions, docValueArray)
+			found, err = filterFieldArrayElemMatch(conditions, docValueArray)
 			if found < 0 {
 				doc.Remove(k1)
 			}
@@ -218,7 +343,7 @@ func applyComplexProjection(k1 string, doc, projectionVal *types.Document) (err
 }
 
 // filterFieldArrayElemMatch is for elemMatch conditions.
-func filterFieldArrayElemMatch(k1 string, doc, conditions *types.Document, docValueArray *types.Array) (found int, err error) {
+func filterFieldArrayElemMatch(conditions *types.Document, docValueArray *types.Array) (found int, err error) {
 	for k2ConditionField, conditionValue := range conditions.Map() {
 		switch elemMatchFieldCondition := conditionValue.(type) {
 		case *types.Document: // TODO field2: { $gte: 10 }
@@ -239,7 +364,8 @@ func filterFieldArrayElemMatch(k1 string, doc, conditions *types.Document, docVa
 				case *types.Document:
 					docVal, err := cmpVal.Get(k2ConditionField)
 					if err != nil {
-						doc.RemoveByPath(types.NewPath([]string{k1, strconv.Itoa(j)}))
+						must.NoError(docValueArray.RemoveAt(j))
+						j = j - 1
 						continue
 					}
 					result := types.Compare(docVal, elemMatchFieldCondition)
@@ -248,7 +374,7 @@ func filterFieldArrayElemMatch(k1 string, doc, conditions *types.Document, docVa
 						found = j
 						break
 					}
-					doc.RemoveByPath(types.NewPath([]string{k1, strconv.Itoa(j)}))
+					must.NoError(docValueArray.RemoveAt(j))
 					j = j - 1
 				}
 			}
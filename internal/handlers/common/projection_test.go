@@ -0,0 +1,154 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestProjectDocumentsSharedValues(t *testing.T) {
+	t.Parallel()
+
+	// both documents reference the same nested array
+	shared := must.NotFail(types.NewArray(
+		must.NotFail(types.NewDocument("b", int32(1))),
+		must.NotFail(types.NewDocument("b", int32(2))),
+	))
+	doc1 := must.NotFail(types.NewDocument("_id", int32(1), "a", shared))
+	doc2 := must.NotFail(types.NewDocument("_id", int32(2), "a", shared))
+
+	projection := must.NotFail(types.NewDocument(
+		"a", must.NotFail(types.NewDocument("$elemMatch", must.NotFail(types.NewDocument("b", int32(2))))),
+	))
+
+	docs := []*types.Document{doc1}
+	require.NoError(t, ProjectDocuments(docs, projection))
+
+	expected := must.NotFail(types.NewDocument(
+		"_id", int32(1),
+		"a", must.NotFail(types.NewArray(must.NotFail(types.NewDocument("b", int32(2))))),
+	))
+	assert.Equal(t, expected, docs[0])
+
+	assert.Equal(t, 2, shared.Len())
+	assert.Same(t, shared, must.NotFail(doc2.Get("a")))
+	assert.Equal(t, 2, must.NotFail(doc1.Get("a")).(*types.Array).Len())
+}
+
+func TestProjectDocumentsDotted(t *testing.T) {
+	t.Parallel()
+
+	doc := func(pairs ...any) *types.Document { return must.NotFail(types.NewDocument(pairs...)) }
+	arr := func(values ...any) *types.Array { return must.NotFail(types.NewArray(values...)) }
+
+	src := doc(
+		"_id", int32(1),
+		"items", arr(
+			doc("name", "a", "price", int32(1)),
+			"scalar",
+			arr(doc("name", "b", "price", int32(2)), int32(3)),
+			doc("price", int32(3)),
+		),
+		"a", doc("b", int32(1), "c", int32(2)),
+		"s", int32(5),
+	)
+
+	for name, tc := range map[string]struct { //nolint:paralleltest // false positive
+		projection *types.Document
+		expected   *types.Document
+		err        error
+	}{
+		"IncludeArrayField": {
+			projection: doc("items.name", int32(1)),
+			expected:   doc("_id", int32(1), "items", arr(doc("name", "a"), arr(doc("name", "b")), doc())),
+		},
+		"ExcludeArrayField": {
+			projection: doc("items.price", false),
+			expected: doc(
+				"_id", int32(1),
+				"items", arr(doc("name", "a"), "scalar", arr(doc("name", "b"), int32(3)), doc()),
+				"a", doc("b", int32(1), "c", int32(2)),
+				"s", int32(5),
+			),
+		},
+		"IncludeEmbedded": {
+			projection: doc("a.b", int32(1), "s.x", int64(1)),
+			expected:   doc("_id", int32(1), "a", doc("b", int32(1))),
+		},
+		"ExcludeEmbeddedAndID": {
+			projection: doc("_id", int32(0), "a.c", float64(0), "s.x", int32(0)),
+			expected: doc(
+				"items", src.Map()["items"],
+				"a", doc("b", int32(1)),
+				"s", int32(5),
+			),
+		},
+		"IncludeSiblings": {
+			projection: doc("a.b", true, "a.c", true),
+			expected:   doc("_id", int32(1), "a", doc("b", int32(1), "c", int32(2))),
+		},
+		"PathCollision": {
+			projection: doc("a", int32(1), "a.b", int32(1)),
+			err:        NewErrorMsg(ErrProjectionPathCollision, "Path collision at a.b remaining portion b"),
+		},
+		"PathCollisionPrefix": {
+			projection: doc("a.b", int32(1), "a", int32(1)),
+			err:        NewErrorMsg(ErrProjectionPathCollision, "Path collision at a"),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			docs := []*types.Document{src}
+			err := ProjectDocuments(docs, tc.projection)
+			if tc.err != nil {
+				assert.Equal(t, tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+			testutil.AssertEqual(t, tc.expected, docs[0])
+		})
+	}
+}
+
+func TestUpdateDocumentSharedValues(t *testing.T) {
+	t.Parallel()
+
+	update := must.NotFail(types.NewDocument(
+		"$set", must.NotFail(types.NewDocument("a", must.NotFail(types.NewDocument("b", int32(1))))),
+	))
+
+	doc1 := must.NotFail(types.NewDocument("_id", int32(1)))
+	doc2 := must.NotFail(types.NewDocument("_id", int32(2)))
+
+	_, err := UpdateDocument(doc1, update)
+	require.NoError(t, err)
+	_, err = UpdateDocument(doc2, update)
+	require.NoError(t, err)
+
+	must.NoError(doc1.SetByPath(types.NewPathFromString("a.b"), int32(42)))
+
+	assert.Equal(t, int32(1), must.NotFail(doc2.GetByPath(types.NewPathFromString("a.b"))))
+	assert.Equal(t, int32(1), must.NotFail(update.GetByPath(types.NewPathFromString("$set.a.b"))))
+}
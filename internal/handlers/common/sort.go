@@ -17,23 +17,36 @@ package common
 import (
 	"fmt"
 	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/slices"
 
 	"github.com/FerretDB/FerretDB/internal/types"
-	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 )
 
 // SortDocuments sorts given documents in place according to the given sorting conditions.
+//
+// Documents are compared by sort keys in the same way as MongoDB does it:
+//   - a missing field is the same as null;
+//   - for an array, the minimal element is used for ascending order, and the maximal one for descending;
+//   - an empty array is less than null;
+//   - dotted paths go through arrays of documents.
+//
+// Sort keys are used in the specification order: each next key breaks ties between documents
+// that are equal by all previous keys.
+// Sorting is stable: documents with equal sort keys keep their original order.
 func SortDocuments(docs []*types.Document, sort *types.Document) error {
 	if sort.Len() == 0 {
 		return nil
 	}
 
-	if sort.Len() > 32 {
-		return lazyerrors.Errorf("maximum sort keys exceeded: %v", sort.Len())
+	if sort.Len() > maxSortKeys {
+		return NewErrorMsg(ErrSortTooManyKeys, "too many compound keys")
 	}
 
-	sortFuncs := make([]sortFunc, len(sort.Keys()))
+	fields := make([]sortField, sort.Len())
 	for i, sortKey := range sort.Keys() {
 		sortField := must.NotFail(sort.Get(sortKey))
 		sortType, err := getSortType(sortKey, sortField)
@@ -41,64 +54,155 @@ func SortDocuments(docs []*types.Document, sort *types.Document) error {
 			return err
 		}
 
-		sortFuncs[i] = lessFunc(sortKey, sortType)
+		if slices.Contains(strings.Split(sortKey, "."), "") {
+			return NewErrorMsg(ErrPathContainsEmptyElement, "FieldPath field names may not be empty strings.")
+		}
+
+		fields[i] = newSortField(sortKey, sortType)
+	}
+
+	// extract sort keys once instead of doing it on every comparison
+	keys := make([][]sortKey, len(docs))
+	for i, doc := range docs {
+		keys[i] = make([]sortKey, len(fields))
+		for j, f := range fields {
+			keys[i][j] = f.key(doc)
+		}
 	}
 
-	sorter := &docsSorter{docs: docs, sorts: sortFuncs}
-	sorter.Sort(docs)
+	sorter := &docsSorter{docs: docs, keys: keys, fields: fields}
+	sorter.Sort()
 
 	return nil
 }
 
-// lessFunc takes sort key and type and returns sort.Interface's Less function which
-// compares selected key of 2 documents.
-func lessFunc(sortKey string, sortType types.SortType) func(a, b *types.Document) bool {
-	return func(a, b *types.Document) bool {
-		aField, err := a.Get(sortKey)
-		if err != nil {
-			return false
-		}
+// maxSortKeys is the maximal number of keys in the sort specification.
+const maxSortKeys = 32
 
-		bField, err := b.Get(sortKey)
-		if err != nil {
-			return false
+// sortField represents a single field of the sort specification.
+type sortField struct {
+	path     []string
+	sortType types.SortType
+}
+
+// newSortField returns a new sortField for the given (possibly dotted) key and sort type.
+func newSortField(key string, sortType types.SortType) sortField {
+	return sortField{
+		path:     strings.Split(key, "."),
+		sortType: sortType,
+	}
+}
+
+// sortKey represents a value used for comparing documents.
+type sortKey struct {
+	value      any  // nil for empty arrays
+	emptyArray bool // true if the value is an empty array that is less than everything else
+}
+
+// key returns document's sort key for that field.
+func (f sortField) key(doc *types.Document) sortKey {
+	var values []any
+	var emptyArray bool
+	collectPathValues(doc, f.path, &values, &emptyArray)
+
+	if len(values) == 0 {
+		if emptyArray {
+			return sortKey{emptyArray: true}
 		}
 
-		result := types.CompareOrder(aField, bField, sortType)
+		return sortKey{value: types.Null}
+	}
 
-		switch result {
-		case types.Less:
-			switch sortType {
-			case types.Ascending:
-				return true
-			case types.Descending:
-				return false
+	res := values[0]
+	for _, v := range values[1:] {
+		switch f.sortType {
+		case types.Ascending:
+			if types.CompareOrder(v, res) == types.Less {
+				res = v
 			}
-		case types.Greater:
-			switch sortType {
-			case types.Ascending:
-				return false
-			case types.Descending:
-				return true
+		case types.Descending:
+			if types.CompareOrder(v, res) == types.Greater {
+				res = v
 			}
-		case types.Incomparable, types.Equal:
-			return false
 		}
+	}
 
-		return false
+	return sortKey{value: res}
+}
+
+// collectPathValues appends to values all values found by the given path from v.
+//
+// Arrays on the path are traversed: their elements that are documents are searched for the rest of the path,
+// and a numeric path element also selects an array element by index.
+// The last array on the path is expanded to its elements; emptyArray is set if it is empty.
+// Nested arrays are not expanded.
+func collectPathValues(v any, path []string, values *[]any, emptyArray *bool) {
+	if len(path) == 0 {
+		arr, ok := v.(*types.Array)
+		if !ok {
+			*values = append(*values, v)
+			return
+		}
+
+		if arr.Len() == 0 {
+			*emptyArray = true
+			return
+		}
+
+		for i := 0; i < arr.Len(); i++ {
+			*values = append(*values, must.NotFail(arr.Get(i)))
+		}
+
+		return
+	}
+
+	switch v := v.(type) {
+	case *types.Document:
+		fieldValue, err := v.Get(path[0])
+		if err != nil {
+			return
+		}
+
+		collectPathValues(fieldValue, path[1:], values, emptyArray)
+
+	case *types.Array:
+		if index, err := strconv.Atoi(path[0]); err == nil && index >= 0 && index < v.Len() {
+			collectPathValues(must.NotFail(v.Get(index)), path[1:], values, emptyArray)
+			return
+		}
+
+		for i := 0; i < v.Len(); i++ {
+			if doc, ok := must.NotFail(v.Get(i)).(*types.Document); ok {
+				collectPathValues(doc, path, values, emptyArray)
+			}
+		}
 	}
 }
 
-type sortFunc func(a, b *types.Document) bool
+// compareSortKeys compares sort keys.
+func compareSortKeys(a, b sortKey) types.CompareResult {
+	switch {
+	case a.emptyArray && b.emptyArray:
+		return types.Equal
+	case a.emptyArray:
+		return types.Less
+	case b.emptyArray:
+		return types.Greater
+	default:
+		return types.CompareOrder(a.value, b.value)
+	}
+}
 
+// docsSorter implements sort.Interface for documents and their sort keys.
 type docsSorter struct {
-	docs  []*types.Document
-	sorts []sortFunc
+	docs   []*types.Document
+	keys   [][]sortKey
+	fields []sortField
 }
 
-func (ds *docsSorter) Sort(docs []*types.Document) {
-	ds.docs = docs
-	sort.Sort(ds)
+// Sort sorts documents keeping the original order of equal ones.
+func (ds *docsSorter) Sort() {
+	sort.Stable(ds)
 }
 
 func (ds *docsSorter) Len() int {
@@ -107,27 +211,25 @@ func (ds *docsSorter) Len() int {
 
 func (ds *docsSorter) Swap(i, j int) {
 	ds.docs[i], ds.docs[j] = ds.docs[j], ds.docs[i]
+	ds.keys[i], ds.keys[j] = ds.keys[j], ds.keys[i]
 }
 
 func (ds *docsSorter) Less(i, j int) bool {
-	p, q := ds.docs[i], ds.docs[j]
-	// Try all but the last comparison.
-	var k int
-	for k = 0; k < len(ds.sorts)-1; k++ {
-		sortFunc := ds.sorts[k]
-		switch {
-		case sortFunc(p, q):
-			// p < q, so we have a decision.
-			return true
-		case sortFunc(q, p):
-			// p > q, so we have a decision.
-			return false
+	for k, f := range ds.fields {
+		res := compareSortKeys(ds.keys[i][k], ds.keys[j][k])
+		if res == types.Equal {
+			// try the next field
+			continue
+		}
+
+		if f.sortType == types.Descending {
+			return res == types.Greater
 		}
-		// p == q; try the next comparison.
+
+		return res == types.Less
 	}
-	// All comparisons to here said "equal", so just return whatever
-	// the final comparison reports.
-	return ds.sorts[k](p, q)
+
+	return false
 }
 
 // getSortType determines SortType from input sort value.
@@ -141,7 +243,7 @@ func getSortType(key string, value any) (types.SortType, error) {
 			}
 			return 0, NewErrorMsg(ErrSortBadValue, fmt.Sprintf(`Illegal key in $sort specification: %v: %v`, key, value))
 		case errNotWholeNumber:
-			return 0, NewErrorMsg(ErrBadValue, "$sort must be a whole number")
+			return 0, NewErrorMsg(ErrSortBadOrder, "$sort key ordering must be 1 (for ascending) or -1 (for descending)")
 		default:
 			return 0, err
 		}
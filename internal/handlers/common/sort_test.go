@@ -0,0 +1,209 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestSortDocuments(t *testing.T) {
+	t.Parallel()
+
+	// values of the "v" field by _id; nil means missing field
+	values := []any{
+		must.NotFail(types.NewArray(int32(5), int32(1))), // 0: min 1, max 5
+		int32(3),                       // 1
+		nil,                            // 2: missing is null
+		types.Null,                     // 3
+		must.NotFail(types.NewArray()), // 4: less than null
+		must.NotFail(types.NewArray(int32(2), "foo")),                        // 5: min 2, max "foo"
+		must.NotFail(types.NewArray(must.NotFail(types.NewArray(int32(0))))), // 6: nested array is not expanded
+		int32(3), // 7: equal to 1
+	}
+
+	for name, tc := range map[string]struct { //nolint:paralleltest // false positive
+		sort     *types.Document
+		expected []int32
+	}{
+		"Ascending": {
+			sort:     must.NotFail(types.NewDocument("v", int32(1))),
+			expected: []int32{4, 2, 3, 0, 5, 1, 7, 6},
+		},
+		"Descending": {
+			sort:     must.NotFail(types.NewDocument("v", int32(-1))),
+			expected: []int32{6, 5, 0, 1, 7, 2, 3, 4},
+		},
+		"AscendingThenDescendingW": {
+			sort:     must.NotFail(types.NewDocument("v", int32(1), "w", int32(-1))),
+			expected: []int32{4, 3, 2, 0, 5, 7, 1, 6},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			docs := make([]*types.Document, len(values))
+			for i, v := range values {
+				docs[i] = must.NotFail(types.NewDocument("_id", int32(i), "w", int32(i)))
+				if v != nil {
+					must.NoError(docs[i].Set("v", v))
+				}
+			}
+
+			require.NoError(t, SortDocuments(docs, tc.sort))
+
+			actual := make([]int32, len(docs))
+			for i, doc := range docs {
+				actual[i] = must.NotFail(doc.Get("_id")).(int32)
+			}
+
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestSortDocumentsDotted(t *testing.T) {
+	t.Parallel()
+
+	docs := []*types.Document{
+		// a.b values are 3 and 7
+		must.NotFail(types.NewDocument("_id", int32(0), "a", must.NotFail(types.NewArray(
+			must.NotFail(types.NewDocument("b", int32(7))),
+			must.NotFail(types.NewDocument("b", int32(3))),
+		)))),
+		// a.b values are 1 and 4; not a document element is ignored
+		must.NotFail(types.NewDocument("_id", int32(1), "a", must.NotFail(types.NewArray(
+			int32(100),
+			must.NotFail(types.NewDocument("b", must.NotFail(types.NewArray(int32(4), int32(1))))),
+		)))),
+		// a.b value is 5
+		must.NotFail(types.NewDocument("_id", int32(2), "a", must.NotFail(types.NewDocument("b", int32(5))))),
+		// a.b is missing
+		must.NotFail(types.NewDocument("_id", int32(3), "a", must.NotFail(types.NewArray(int32(0))))),
+	}
+
+	ids := func() []int32 {
+		res := make([]int32, len(docs))
+		for i, doc := range docs {
+			res[i] = must.NotFail(doc.Get("_id")).(int32)
+		}
+		return res
+	}
+
+	require.NoError(t, SortDocuments(docs, must.NotFail(types.NewDocument("a.b", int32(1)))))
+	assert.Equal(t, []int32{3, 1, 0, 2}, ids())
+
+	require.NoError(t, SortDocuments(docs, must.NotFail(types.NewDocument("a.b", int32(-1)))))
+	assert.Equal(t, []int32{0, 2, 1, 3}, ids())
+
+	// numeric path element selects array element by index
+	require.NoError(t, SortDocuments(docs, must.NotFail(types.NewDocument("a.1.b", int32(1)))))
+	assert.Equal(t, []int32{2, 3, 1, 0}, ids())
+}
+
+func TestSortDocumentsCompound(t *testing.T) {
+	t.Parallel()
+
+	// a and b values by _id
+	values := [][2]any{
+		{int32(1), "foo"},    // 0
+		{"foo", int32(1)},    // 1
+		{int32(1), int32(2)}, // 2
+		{float64(1), "bar"},  // 3: equal to 1 by a
+		{nil, int32(5)},      // 4
+		{"foo", nil},         // 5
+		{int32(1), "foo"},    // 6: equal to 0 by both
+	}
+
+	docs := make([]*types.Document, len(values))
+	for i, v := range values {
+		docs[i] = must.NotFail(types.NewDocument("_id", int32(i)))
+		if v[0] != nil {
+			must.NoError(docs[i].Set("a", v[0]))
+		}
+		if v[1] != nil {
+			must.NoError(docs[i].Set("b", v[1]))
+		}
+	}
+
+	ids := func() []int32 {
+		res := make([]int32, len(docs))
+		for i, doc := range docs {
+			res[i] = must.NotFail(doc.Get("_id")).(int32)
+		}
+		return res
+	}
+
+	require.NoError(t, SortDocuments(docs, must.NotFail(types.NewDocument("a", int32(-1), "b", int64(1)))))
+	assert.Equal(t, []int32{5, 1, 2, 3, 0, 6, 4}, ids())
+
+	require.NoError(t, SortDocuments(docs, must.NotFail(types.NewDocument("a", float64(1), "b", int32(-1)))))
+	assert.Equal(t, []int32{4, 0, 6, 3, 2, 1, 5}, ids())
+}
+
+func TestSortDocumentsErrors(t *testing.T) {
+	t.Parallel()
+
+	tooMany := types.MakeDocument(maxSortKeys + 1)
+	for i := 0; i <= maxSortKeys; i++ {
+		must.NoError(tooMany.Set(fmt.Sprintf("f%d", i), int32(1)))
+	}
+
+	for name, tc := range map[string]struct { //nolint:paralleltest // false positive
+		sort *types.Document
+		err  error
+	}{
+		"Zero": {
+			sort: must.NotFail(types.NewDocument("a", int32(1), "b", int32(0))),
+			err:  NewErrorMsg(ErrSortBadOrder, "$sort key ordering must be 1 (for ascending) or -1 (for descending)"),
+		},
+		"Fractional": {
+			sort: must.NotFail(types.NewDocument("a", float64(1.5))),
+			err:  NewErrorMsg(ErrSortBadOrder, "$sort key ordering must be 1 (for ascending) or -1 (for descending)"),
+		},
+		"String": {
+			sort: must.NotFail(types.NewDocument("a", "asc")),
+			err:  NewErrorMsg(ErrSortBadValue, "Illegal key in $sort specification: a: asc"),
+		},
+		"EmptyPathElement": {
+			sort: must.NotFail(types.NewDocument("a..b", int32(1))),
+			err:  NewErrorMsg(ErrPathContainsEmptyElement, "FieldPath field names may not be empty strings."),
+		},
+		"TrailingDot": {
+			sort: must.NotFail(types.NewDocument("a.", int32(1))),
+			err:  NewErrorMsg(ErrPathContainsEmptyElement, "FieldPath field names may not be empty strings."),
+		},
+		"TooManyKeys": {
+			sort: tooMany,
+			err:  NewErrorMsg(ErrSortTooManyKeys, "too many compound keys"),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			docs := []*types.Document{must.NotFail(types.NewDocument("_id", int32(0)))}
+			err := SortDocuments(docs, tc.sort)
+			assert.Equal(t, tc.err, err)
+		})
+	}
+}
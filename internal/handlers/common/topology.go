@@ -0,0 +1,89 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/ctxutil"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// topologyProcessID identifies this FerretDB process in topologyVersion.
+var topologyProcessID = types.NewObjectID()
+
+// TopologyVersion returns the topologyVersion field for hello and isMaster responses.
+//
+// FerretDB topology never changes, so the counter is always zero.
+func TopologyVersion() *types.Document {
+	return must.NotFail(types.NewDocument(
+		"processId", topologyProcessID,
+		"counter", int64(0),
+	))
+}
+
+// AwaitTopologyChange implements awaitable hello and isMaster commands.
+//
+// If the document contains the current topologyVersion and maxAwaitTimeMS fields,
+// it waits for the topology change for that time or until ctx is canceled.
+// As the topology never changes, the caller then replies with the same topologyVersion.
+func AwaitTopologyChange(ctx context.Context, document *types.Document) error {
+	d, err := topologyAwaitTime(document)
+	if err != nil || d == 0 {
+		return err
+	}
+
+	return ctxutil.Sleep(ctx, d)
+}
+
+// TopologyChangeAwaited returns true if AwaitTopologyChange actually waits for the given document
+// instead of replying immediately.
+func TopologyChangeAwaited(document *types.Document) bool {
+	d, err := topologyAwaitTime(document)
+	return err == nil && d > 0
+}
+
+// topologyAwaitTime returns the time AwaitTopologyChange waits for the given document;
+// zero means that it replies immediately.
+func topologyAwaitTime(document *types.Document) (time.Duration, error) {
+	v, _ := document.Get("topologyVersion")
+	if v == nil {
+		return 0, nil
+	}
+
+	tv, ok := v.(*types.Document)
+	if !ok {
+		return 0, NewErrorMsg(ErrTypeMismatch, "BSON field 'topologyVersion' is the wrong type, expected type 'object'")
+	}
+
+	v, _ = document.Get("maxAwaitTimeMS")
+	if v == nil {
+		return 0, NewErrorMsg(ErrBadValue, "A request with a 'topologyVersion' must include 'maxAwaitTimeMS'")
+	}
+
+	maxAwaitTimeMS, err := GetWholeNumberParam(v)
+	if err != nil || maxAwaitTimeMS < 0 {
+		return 0, NewErrorMsg(ErrBadValue, "'maxAwaitTimeMS' must be a non-negative integer")
+	}
+
+	// the client has a topologyVersion of another process, reply immediately
+	if processID, _ := tv.Get("processId"); processID != topologyProcessID {
+		return 0, nil
+	}
+
+	return time.Duration(maxAwaitTimeMS) * time.Millisecond, nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestAwaitTopologyChange(t *testing.T) {
+	t.Parallel()
+
+	otherVersion := must.NotFail(types.NewDocument("processId", types.NewObjectID(), "counter", int64(0)))
+
+	for name, tc := range map[string]struct {
+		document *types.Document
+		wait     bool
+		err      error
+	}{
+		"NotAwaitable": {
+			document: must.NotFail(types.NewDocument("hello", int32(1))),
+		},
+		"Current": {
+			document: must.NotFail(types.NewDocument(
+				"hello", int32(1),
+				"topologyVersion", TopologyVersion(),
+				"maxAwaitTimeMS", int64(100),
+			)),
+			wait: true,
+		},
+		"ZeroMaxAwaitTimeMS": {
+			document: must.NotFail(types.NewDocument(
+				"hello", int32(1),
+				"topologyVersion", TopologyVersion(),
+				"maxAwaitTimeMS", int64(0),
+			)),
+		},
+		"OtherProcess": {
+			document: must.NotFail(types.NewDocument(
+				"hello", int32(1),
+				"topologyVersion", otherVersion,
+				"maxAwaitTimeMS", int64(10_000),
+			)),
+		},
+		"NoMaxAwaitTimeMS": {
+			document: must.NotFail(types.NewDocument("hello", int32(1), "topologyVersion", TopologyVersion())),
+			err:      NewErrorMsg(ErrBadValue, "A request with a 'topologyVersion' must include 'maxAwaitTimeMS'"),
+		},
+		"NegativeMaxAwaitTimeMS": {
+			document: must.NotFail(types.NewDocument(
+				"hello", int32(1),
+				"topologyVersion", TopologyVersion(),
+				"maxAwaitTimeMS", int32(-1),
+			)),
+			err: NewErrorMsg(ErrBadValue, "'maxAwaitTimeMS' must be a non-negative integer"),
+		},
+		"NotDocument": {
+			document: must.NotFail(types.NewDocument(
+				"hello", int32(1),
+				"topologyVersion", "version",
+				"maxAwaitTimeMS", int32(100),
+			)),
+			err: NewErrorMsg(ErrTypeMismatch, "BSON field 'topologyVersion' is the wrong type, expected type 'object'"),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			start := time.Now()
+			err := AwaitTopologyChange(context.Background(), tc.document)
+			if tc.err != nil {
+				assert.Equal(t, tc.err, err)
+				assert.False(t, TopologyChangeAwaited(tc.document))
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.wait, TopologyChangeAwaited(tc.document))
+
+			if tc.wait {
+				assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+			} else {
+				assert.Less(t, time.Since(start), 100*time.Millisecond)
+			}
+		})
+	}
+
+	t.Run("Canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		document := must.NotFail(types.NewDocument(
+			"hello", int32(1),
+			"topologyVersion", TopologyVersion(),
+			"maxAwaitTimeMS", int64(10_000),
+		))
+		assert.ErrorIs(t, AwaitTopologyChange(ctx, document), context.Canceled)
+	})
+}
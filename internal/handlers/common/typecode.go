@@ -28,7 +28,8 @@ import (
 // typeCode represents BSON type codes.
 // BSON type codes represent corresponding codes in BSON specification.
 // They could be used to query fields with particular type values using $type operator.
-// Type code `number` is added to support MongoDB surrogate alias `number` which matches double, int and long type values.
+// Type code `number` is added to support MongoDB surrogate alias `number` which matches double, int, long
+// and decimal type values.
 type typeCode int32
 
 const (
@@ -45,11 +46,11 @@ const (
 	typeCodeInt       = typeCode(16) // int
 	typeCodeTimestamp = typeCode(17) // timestamp
 	typeCodeLong      = typeCode(18) // long
+	typeCodeDecimal   = typeCode(19) // decimal
 	// Not implemented.
-	typeCodeDecimal = typeCode(19)  // decimal
-	typeCodeMinKey  = typeCode(-1)  // minKey
-	typeCodeMaxKey  = typeCode(127) // maxKey
-	// Not actual type code. `number` matches double, int and long.
+	typeCodeMinKey = typeCode(-1)  // minKey
+	typeCodeMaxKey = typeCode(127) // maxKey
+	// Not actual type code. `number` matches double, int, long and decimal.
 	typeCodeNumber = typeCode(-128) // number
 )
 
@@ -59,9 +60,9 @@ func newTypeCode(code int32) (typeCode, error) {
 	switch c {
 	case typeCodeDouble, typeCodeString, typeCodeObject, typeCodeArray,
 		typeCodeBinData, typeCodeObjectID, typeCodeBool, typeCodeDate,
-		typeCodeNull, typeCodeRegex, typeCodeInt, typeCodeTimestamp, typeCodeLong, typeCodeNumber:
+		typeCodeNull, typeCodeRegex, typeCodeInt, typeCodeTimestamp, typeCodeLong, typeCodeDecimal, typeCodeNumber:
 		return c, nil
-	case typeCodeDecimal, typeCodeMinKey, typeCodeMaxKey:
+	case typeCodeMinKey, typeCodeMaxKey:
 		return 0, NewErrorMsg(ErrNotImplemented, fmt.Sprintf(`Type code %v not implemented`, code))
 	default:
 		return 0, NewErrorMsg(ErrBadValue, fmt.Sprintf(`Invalid numerical type code: %d`, code))
@@ -103,7 +104,7 @@ func init() {
 	for _, i := range []typeCode{
 		typeCodeDouble, typeCodeString, typeCodeObject, typeCodeArray,
 		typeCodeBinData, typeCodeObjectID, typeCodeBool, typeCodeDate, typeCodeNull,
-		typeCodeRegex, typeCodeInt, typeCodeTimestamp, typeCodeLong, typeCodeNumber,
+		typeCodeRegex, typeCodeInt, typeCodeTimestamp, typeCodeLong, typeCodeDecimal, typeCodeNumber,
 	} {
 		aliasToTypeCode[i.String()] = i
 	}
@@ -138,6 +139,8 @@ func AliasFromType(v any) string {
 		return typeCodeTimestamp.String()
 	case int64:
 		return typeCodeLong.String()
+	case types.Decimal128:
+		return typeCodeDecimal.String()
 	default:
 		panic(fmt.Sprintf("not supported type %T", v))
 	}
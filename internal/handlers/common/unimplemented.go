@@ -22,13 +22,68 @@ import (
 	"github.com/FerretDB/FerretDB/internal/types"
 )
 
-// Unimplemented returns ErrNotImplemented if doc has any of the given fields.
+// genericFields are fields that drivers and shells may add to any command.
+// They don't change the meaning of the command, so handlers should not treat them as command parameters.
+var genericFields = []string{
+	"$db",
+	"$clusterTime",
+	"$readPreference",
+	"lsid",
+	"txnNumber",
+	"apiVersion",
+	"apiStrict",
+	"apiDeprecationErrors",
+	"comment",
+}
+
+// IsGenericField returns true if the given field is one of the fields that drivers and shells
+// may add to any command, such as $db, $clusterTime, lsid, or apiVersion.
+//
+// Handlers that treat all command's fields as parameters should skip them.
+func IsGenericField(field string) bool {
+	for _, f := range genericFields {
+		if field == f {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isDefaultValue returns true if the given value is the same as an absent field for most parameters:
+// null, false, zero number, empty document or array.
+func isDefaultValue(v any) bool {
+	switch v := v.(type) {
+	case types.NullType:
+		return true
+	case bool:
+		return !v
+	case float64:
+		return v == 0
+	case int32:
+		return v == 0
+	case int64:
+		return v == 0
+	case *types.Document:
+		return v.Len() == 0
+	case *types.Array:
+		return v.Len() == 0
+	default:
+		return false
+	}
+}
+
+// Unimplemented returns ErrNotImplemented if doc has any of the given fields with non-default value.
+// Fields with default values (see isDefaultValue) are ignored.
 func Unimplemented(doc *types.Document, fields ...string) error {
 	for _, field := range fields {
-		if v, err := doc.Get(field); err == nil || v != nil {
-			err = fmt.Errorf("%s: support for field %q is not implemented yet", doc.Command(), field)
-			return NewError(ErrNotImplemented, err)
+		v, err := doc.Get(field)
+		if err != nil || isDefaultValue(v) {
+			continue
 		}
+
+		err = fmt.Errorf("%s: support for field %q is not implemented yet", doc.Command(), field)
+		return NewError(ErrNotImplemented, err)
 	}
 
 	return nil
@@ -50,11 +105,17 @@ func UnimplementedNonDefault(doc *types.Document, field string, isDefault func(v
 	return NewError(ErrNotImplemented, err)
 }
 
-// Ignored logs a message if doc has any of the given fields.
+// Ignored logs a message if doc has any of the given fields, and removes them from doc,
+// so they are not handled by mistake later.
+//
+// doc should be a (shallow) copy made by wire.OpMsg.Document, not the original message's document.
 func Ignored(doc *types.Document, l *zap.Logger, fields ...string) {
 	for _, field := range fields {
-		if v, err := doc.Get(field); err == nil || v != nil {
-			l.Debug("ignoring field", zap.String("command", doc.Command()), zap.String("field", field))
+		if !doc.Has(field) {
+			continue
 		}
+
+		l.Debug("ignoring field", zap.String("command", doc.Command()), zap.String("field", field))
+		doc.Remove(field)
 	}
 }
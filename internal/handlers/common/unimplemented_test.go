@@ -0,0 +1,105 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestUnimplemented(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct { //nolint:paralleltest // false positive
+		v   any // nil means absent
+		err error
+	}{
+		"Absent":        {},
+		"Null":          {v: types.Null},
+		"False":         {v: false},
+		"Zero":          {v: int32(0)},
+		"ZeroLong":      {v: int64(0)},
+		"ZeroDouble":    {v: float64(0)},
+		"EmptyDocument": {v: types.MakeDocument(0)},
+		"EmptyArray":    {v: types.MakeArray(0)},
+		"True": {
+			v:   true,
+			err: NewErrorMsg(ErrNotImplemented, `find: support for field "tailable" is not implemented yet`),
+		},
+		"One": {
+			v:   int32(1),
+			err: NewErrorMsg(ErrNotImplemented, `find: support for field "tailable" is not implemented yet`),
+		},
+		"Document": {
+			v:   must.NotFail(types.NewDocument("locale", "en")),
+			err: NewErrorMsg(ErrNotImplemented, `find: support for field "tailable" is not implemented yet`),
+		},
+		"String": {
+			v:   "",
+			err: NewErrorMsg(ErrNotImplemented, `find: support for field "tailable" is not implemented yet`),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			doc := must.NotFail(types.NewDocument("find", "values"))
+			if tc.v != nil {
+				must.NoError(doc.Set("tailable", tc.v))
+			}
+
+			err := Unimplemented(doc, "tailable")
+			assert.Equal(t, tc.err, err)
+		})
+	}
+}
+
+func TestIgnored(t *testing.T) {
+	t.Parallel()
+
+	doc := must.NotFail(types.NewDocument(
+		"insert", "values",
+		"writeConcern", must.NotFail(types.NewDocument("w", "majority")),
+		"ordered", true,
+		"$db", "test",
+	))
+
+	Ignored(doc, zap.NewNop(), "writeConcern", "bypassDocumentValidation")
+
+	expected := must.NotFail(types.NewDocument(
+		"insert", "values",
+		"ordered", true,
+		"$db", "test",
+	))
+	assert.Equal(t, expected, doc)
+}
+
+func TestIsGenericField(t *testing.T) {
+	t.Parallel()
+
+	for _, f := range []string{"$db", "$clusterTime", "$readPreference", "lsid", "apiVersion", "comment"} {
+		require.True(t, IsGenericField(f), f)
+	}
+
+	for _, f := range []string{"find", "filter", "slowms", "$all"} {
+		require.False(t, IsGenericField(f), f)
+	}
+}
@@ -16,7 +16,6 @@ package common
 
 import (
 	"fmt"
-	"math"
 	"sort"
 	"strings"
 	"time"
@@ -35,21 +34,24 @@ func UpdateDocument(doc, update *types.Document) (bool, error) {
 	for _, updateOp := range update.Keys() {
 		updateV := must.NotFail(update.Get(updateOp))
 
+		// later operators must not reset changes made by previous ones
+		var opChanged bool
+
 		switch updateOp {
 		case "$currentDate":
-			changed, err = processCurrentDateFieldExpression(doc, updateV)
+			opChanged, err = processCurrentDateFieldExpression(doc, updateV)
 			if err != nil {
 				return false, err
 			}
 
 		case "$set":
-			changed, err = processSetFieldExpression(doc, updateV.(*types.Document), false)
+			opChanged, err = processSetFieldExpression(doc, updateV.(*types.Document), false)
 			if err != nil {
 				return false, err
 			}
 
 		case "$setOnInsert":
-			changed, err = processSetFieldExpression(doc, updateV.(*types.Document), true)
+			opChanged, err = processSetFieldExpression(doc, updateV.(*types.Document), true)
 			if err != nil {
 				return false, err
 			}
@@ -61,18 +63,18 @@ func UpdateDocument(doc, update *types.Document) (bool, error) {
 				path := types.NewPathFromString(key)
 				if doc.HasByPath(path) {
 					doc.RemoveByPath(path)
-					changed = true
+					opChanged = true
 				}
 			}
 
-		case "$inc":
-			changed, err = processIncFieldExpression(doc, updateV)
+		case "$inc", "$mul":
+			opChanged, err = processArithmeticFieldExpression(doc, updateOp, updateV)
 			if err != nil {
 				return false, err
 			}
 
 		case "$pop":
-			changed, err = processPopFieldExpression(doc, updateV.(*types.Document))
+			opChanged, err = processPopFieldExpression(doc, updateV.(*types.Document))
 			if err != nil {
 				return false, err
 			}
@@ -100,8 +102,10 @@ func UpdateDocument(doc, update *types.Document) (bool, error) {
 				}
 			}
 
-			changed = true
+			opChanged = true
 		}
+
+		changed = changed || opChanged
 	}
 
 	return changed, nil
@@ -115,17 +119,16 @@ func processSetFieldExpression(doc, setDoc *types.Document, setOnInsert bool) (b
 	sort.Strings(setDoc.Keys())
 
 	for _, setKey := range setDoc.Keys() {
+		if err := validateUpdateFieldName(setKey); err != nil {
+			return false, err
+		}
+
 		setValue := must.NotFail(setDoc.Get(setKey))
 
 		path := types.NewPathFromString(setKey)
 
 		if doc.HasByPath(path) {
-			result := types.Compare(setValue, must.NotFail(doc.GetByPath(path)))
-			if len(result) != 1 {
-				panic("$set: there should be only one result")
-			}
-
-			if result[0] == types.Equal {
+			if types.CompareOrder(setValue, must.NotFail(doc.GetByPath(path))) == types.Equal {
 				continue
 			}
 		}
@@ -135,7 +138,7 @@ func processSetFieldExpression(doc, setDoc *types.Document, setOnInsert bool) (b
 			continue
 		}
 
-		err := doc.SetByPath(path, setValue)
+		err := doc.SetByPath(path, deepCopyValue(setValue))
 		if err != nil {
 			return false, err
 		}
@@ -146,6 +149,19 @@ func processSetFieldExpression(doc, setDoc *types.Document, setOnInsert bool) (b
 	return changed, nil
 }
 
+// deepCopyValue returns a deep copy of composite values and the same value for scalars.
+// It is used to avoid sharing nested documents and arrays of the update with updated documents.
+func deepCopyValue(value any) any {
+	switch value := value.(type) {
+	case *types.Document:
+		return value.DeepCopy()
+	case *types.Array:
+		return value.DeepCopy()
+	default:
+		return value
+	}
+}
+
 // processPopFieldExpression changes document according to $pop operator.
 // If the document was changed it returns true.
 func processPopFieldExpression(doc *types.Document, update *types.Document) (bool, error) {
@@ -187,9 +203,9 @@ func processPopFieldExpression(doc *types.Document, update *types.Document) (boo
 		}
 
 		if popValue == -1 {
-			array.Remove(0)
+			must.NoError(array.RemoveAt(0))
 		} else {
-			array.Remove(array.Len() - 1)
+			must.NoError(array.RemoveAt(array.Len() - 1))
 		}
 
 		err = doc.SetByPath(path, array)
@@ -203,90 +219,96 @@ func processPopFieldExpression(doc *types.Document, update *types.Document) (boo
 	return changed, nil
 }
 
-// processIncFieldExpression changes document according to $inc operator.
+// processArithmeticFieldExpression changes document according to $inc or $mul operator.
 // If the document was changed it returns true.
-func processIncFieldExpression(doc *types.Document, updateV any) (bool, error) {
+func processArithmeticFieldExpression(doc *types.Document, op string, updateV any) (bool, error) {
 	// expecting here a document since all checks were made in ValidateUpdateOperators func
-	incDoc := updateV.(*types.Document)
-
-	var changed bool
-
-	for _, incKey := range incDoc.Keys() {
-		incValue := must.NotFail(incDoc.Get(incKey))
-
-		path := types.NewPathFromString(incKey)
+	opDoc := updateV.(*types.Document)
 
-		if !doc.HasByPath(path) {
-			err := doc.SetByPath(path, incValue)
-			if err != nil {
-				return false, NewWriteErrorMsg(
-					ErrUnsuitableValueType,
-					err.Error(),
-				)
-			}
-
-			changed = true
+	arithmeticFunc, nonNumericArgMsg := AddNumbers, "Cannot increment with non-numeric argument"
+	if op == "$mul" {
+		arithmeticFunc, nonNumericArgMsg = MultiplyNumbers, "Cannot multiply with non-numeric argument"
+	}
 
-			continue
-		}
+	var changed bool
 
-		docValue, err := doc.GetByPath(types.NewPathFromString(incKey))
-		if err != nil {
+	for _, key := range opDoc.Keys() {
+		if err := validateUpdateFieldName(key); err != nil {
 			return false, err
 		}
 
-		incremented, err := addNumbers(incValue, docValue)
-		if err == nil {
-			err := doc.SetByPath(path, incremented)
-			if err != nil {
-				return false, NewWriteErrorMsg(
-					ErrUnsuitableValueType,
-					fmt.Sprintf(`Cannot create field in element {%s: %v}`, path.Prefix(), docValue),
-				)
-			}
-
-			result := types.Compare(docValue, incremented)
-
-			if len(result) != 1 {
-				panic("$inc: there should be only one result")
-			}
-
-			docFloat, ok := docValue.(float64)
-			if result[0] == types.Equal &&
-				// if the document value is NaN we should consider it as changed.
-				(ok && !math.IsNaN(docFloat)) {
-				continue
-			}
+		opValue := must.NotFail(opDoc.Get(key))
 
-			changed = true
+		path := types.NewPathFromString(key)
 
-			continue
+		// missing field is treated as int32 zero: $inc sets it to the increment,
+		// $mul sets it to the multiplier multiplied by zero (that is zero of the multiplier type, or NaN)
+		var docValue any = int32(0)
+		exists := doc.HasByPath(path)
+		if exists {
+			docValue = must.NotFail(doc.GetByPath(path))
 		}
 
+		res, err := arithmeticFunc(opValue, docValue)
 		switch err {
+		case nil:
+			// nothing
+
 		case errUnexpectedLeftOpType:
 			return false, NewWriteErrorMsg(
 				ErrTypeMismatch,
-				fmt.Sprintf(
-					`Cannot increment with non-numeric argument: {%s: %#v}`,
-					incKey,
-					incValue,
-				),
+				fmt.Sprintf(`%s: {%s: %#v}`, nonNumericArgMsg, key, opValue),
 			)
+
 		case errUnexpectedRightOpType:
 			return false, NewWriteErrorMsg(
 				ErrTypeMismatch,
 				fmt.Sprintf(
-					`Cannot apply $inc to a value of non-numeric type. `+
+					`Cannot apply %s to a value of non-numeric type. `+
 						`{_id: "%s"} has the field '%s' of non-numeric type %s`,
+					op,
 					must.NotFail(doc.Get("_id")),
-					incKey,
+					key,
 					AliasFromType(docValue),
 				),
 			)
+
+		case errLongExceeded:
+			return false, NewWriteErrorMsg(
+				ErrBadValue,
+				fmt.Sprintf(
+					`Failed to apply %s operations to current value (%s) for document {_id: "%s"}`,
+					op,
+					numberDebugString(docValue),
+					must.NotFail(doc.Get("_id")),
+				),
+			)
+
 		default:
 			return false, err
 		}
+
+		if !exists && op == "$inc" {
+			// the increment is set as is; that preserves -0
+			res = opValue
+		}
+
+		if exists && isIdenticalNumber(docValue, res) {
+			continue
+		}
+
+		if err = doc.SetByPath(path, res); err != nil {
+			if !exists {
+				return false, NewWriteErrorMsg(ErrUnsuitableValueType, err.Error())
+			}
+
+			return false, NewWriteErrorMsg(
+				ErrUnsuitableValueType,
+				fmt.Sprintf(`Cannot create field in element {%s: %v}`, path.Prefix(), docValue),
+			)
+		}
+
+		changed = true
 	}
 
 	return changed, nil
@@ -303,13 +325,20 @@ func processCurrentDateFieldExpression(doc *types.Document, currentDateVal any)
 	sort.Strings(currentDateExpression.Keys())
 
 	for _, field := range currentDateExpression.Keys() {
+		if err := validateUpdateFieldName(field); err != nil {
+			return false, err
+		}
+
 		currentDateField := must.NotFail(currentDateExpression.Get(field))
 
+		// field is a path, not a literal field name
+		path := types.NewPathFromString(field)
+
 		switch currentDateField := currentDateField.(type) {
 		case *types.Document:
 			currentDateType, err := currentDateField.Get("$type")
 			if err != nil { // default is date
-				if err := doc.Set(field, now); err != nil {
+				if err := doc.SetByPath(path, now); err != nil {
 					return false, err
 				}
 				changed = true
@@ -319,20 +348,20 @@ func processCurrentDateFieldExpression(doc *types.Document, currentDateVal any)
 			currentDateType = currentDateType.(string)
 			switch currentDateType {
 			case "timestamp":
-				if err := doc.Set(field, types.NextTimestamp(now)); err != nil {
+				if err := doc.SetByPath(path, types.NextTimestamp(now)); err != nil {
 					return false, err
 				}
 				changed = true
 
 			case "date":
-				if err := doc.Set(field, now); err != nil {
+				if err := doc.SetByPath(path, now); err != nil {
 					return false, err
 				}
 				changed = true
 			}
 
 		case bool:
-			if err = doc.Set(field, now); err != nil {
+			if err = doc.SetByPath(path, now); err != nil {
 				return false, err
 			}
 			changed = true
@@ -353,6 +382,11 @@ func ValidateUpdateOperators(update *types.Document) error {
 		return err
 	}
 
+	mul, err := extractValueFromUpdateOperator("$mul", update)
+	if err != nil {
+		return err
+	}
+
 	set, err := extractValueFromUpdateOperator("$set", update)
 	if err != nil {
 		return err
@@ -377,6 +411,14 @@ func ValidateUpdateOperators(update *types.Document) error {
 		return err
 	}
 
+	if err = checkConflictingChanges(set, mul); err != nil {
+		return err
+	}
+
+	if err = checkConflictingChanges(inc, mul); err != nil {
+		return err
+	}
+
 	if err = validateCurrentDateExpression(update); err != nil {
 		return err
 	}
@@ -392,6 +434,8 @@ func HasSupportedUpdateModifiers(update *types.Document) (bool, error) {
 			fallthrough
 		case "$inc":
 			fallthrough
+		case "$mul":
+			fallthrough
 		case "$set":
 			fallthrough
 		case "$setOnInsert":
@@ -25,7 +25,8 @@ import (
 //
 // Those methods are called to handle clients' requests sent over wire protocol.
 // MsgXXX methods handle OP_MSG commands.
-// CmdQuery handles a limited subset of OP_QUERY messages.
+// CmdQuery handles OP_QUERY handshake (isMaster / hello on admin.$cmd);
+// other OP_QUERY messages are rejected before reaching handlers.
 //
 // Handlers are shared between all connections! Be careful when you need connection-specific information.
 // Currently, we pass connection information through context, see `ConnectionInfo` and its usage.
@@ -35,8 +36,8 @@ type Interface interface {
 	// Close gracefully shutdowns handler.
 	Close()
 
-	// CmdQuery queries collections for documents.
-	// Used by deprecated OP_QUERY message during connection handshake with an old client.
+	// CmdQuery handles isMaster and hello commands.
+	// Used by deprecated OP_QUERY message during connection handshake.
 	CmdQuery(ctx context.Context, query *wire.OpQuery) (*wire.OpReply, error)
 
 	// OP_MSG commands, sorted alphabetically
@@ -63,6 +64,9 @@ type Interface interface {
 	// MsgCreateIndexes creates indexes on a collection.
 	MsgCreateIndexes(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgCurrentOp returns information about in-progress operations.
+	MsgCurrentOp(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgDataSize returns the size of the collection in bytes.
 	MsgDataSize(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
@@ -75,6 +79,9 @@ type Interface interface {
 	// MsgDelete deletes documents matched by the query.
 	MsgDelete(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgDistinct returns an array of distinct values for the given field.
+	MsgDistinct(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgDrop drops the collection.
 	MsgDrop(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
@@ -99,6 +106,9 @@ type Interface interface {
 	// MsgGetLog returns the most recent logged events from memory.
 	MsgGetLog(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgGetMore returns the next batch of documents from the cursor.
+	MsgGetMore(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgGetParameter returns the value of the parameter.
 	MsgGetParameter(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
@@ -114,6 +124,9 @@ type Interface interface {
 	// MsgIsMaster returns the role of the FerretDB instance.
 	MsgIsMaster(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgKillCursors closes cursors.
+	MsgKillCursors(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgListCollections returns the information of the collections and views in the database.
 	MsgListCollections(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
@@ -123,15 +136,27 @@ type Interface interface {
 	// MsgListDatabases returns a summary of all the databases.
 	MsgListDatabases(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgLogout clears the authentication state of the connection.
+	MsgLogout(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgPing returns a pong response.
 	MsgPing(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgSaslContinue continues the SASL authentication conversation.
+	MsgSaslContinue(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
+	// MsgSaslStart starts the SASL authentication conversation.
+	MsgSaslStart(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgServerStatus returns an overview of the databases state.
 	MsgServerStatus(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
 	// MsgSetFreeMonitoring toggles free monitoring.
 	MsgSetFreeMonitoring(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
+	// MsgSetParameter sets the value of server parameters.
+	MsgSetParameter(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
+
 	// MsgUpdate updates documents that are matched by the query.
 	MsgUpdate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error)
 
@@ -0,0 +1,341 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pg
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/pg/pgdb"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/ctxutil"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+const (
+	// defaultChangeStreamBatchSize is the default maximum number of changes in a single batch.
+	defaultChangeStreamBatchSize = 101
+
+	// defaultChangeStreamAwait is the default time getMore waits for new changes.
+	defaultChangeStreamAwait = time.Second
+
+	// changeStreamPollInterval is the interval between change table reads while getMore waits.
+	changeStreamPollInterval = 100 * time.Millisecond
+
+	// changesTrimmingInterval is the interval between removals of old recorded changes.
+	changesTrimmingInterval = time.Minute
+)
+
+// changeStreamCursor represents an open $changeStream cursor.
+type changeStreamCursor struct {
+	db         string
+	collection string // empty for the whole database
+
+	// lastID is the ID of the last returned change (or the starting point),
+	// protected by m; it is also the resume token.
+	m      sync.Mutex
+	lastID int64
+}
+
+// changeStreamCursors contains open $changeStream cursors by their IDs.
+type changeStreamCursors struct {
+	rw      sync.RWMutex
+	cursors map[int64]*changeStreamCursor
+}
+
+// newChangeStreamCursors returns a new empty set of cursors.
+func newChangeStreamCursors() *changeStreamCursors {
+	return &changeStreamCursors{
+		cursors: make(map[int64]*changeStreamCursor),
+	}
+}
+
+// add adds the given cursor and returns its new ID.
+//
+// IDs are random, so cursors of the previous FerretDB process are not confused with new ones.
+func (cs *changeStreamCursors) add(c *changeStreamCursor) int64 {
+	cs.rw.Lock()
+	defer cs.rw.Unlock()
+
+	for {
+		id := rand.Int63()
+		if _, ok := cs.cursors[id]; id == 0 || ok {
+			continue
+		}
+
+		cs.cursors[id] = c
+
+		return id
+	}
+}
+
+// get returns the cursor with the given ID or nil.
+func (cs *changeStreamCursors) get(id int64) *changeStreamCursor {
+	cs.rw.RLock()
+	defer cs.rw.RUnlock()
+
+	return cs.cursors[id]
+}
+
+// remove removes the cursor with the given ID and returns true if it existed.
+func (cs *changeStreamCursors) remove(id int64) bool {
+	cs.rw.Lock()
+	defer cs.rw.Unlock()
+
+	_, ok := cs.cursors[id]
+	delete(cs.cursors, id)
+
+	return ok
+}
+
+// len returns the number of open cursors.
+func (cs *changeStreamCursors) len() int {
+	cs.rw.RLock()
+	defer cs.rw.RUnlock()
+
+	return len(cs.cursors)
+}
+
+// recordChanges records changes in the given transaction if change streams are enabled.
+func (h *Handler) recordChanges(ctx context.Context, tx pgx.Tx, sp *pgdb.SQLParam, op pgdb.ChangeOperation, docs ...*types.Document) error { //nolint:lll // argument list is too long
+	if !h.changeStreams || len(docs) == 0 {
+		return nil
+	}
+
+	changes := make([]pgdb.Change, len(docs))
+	for i, doc := range docs {
+		changes[i] = pgdb.Change{
+			Collection:  sp.Collection,
+			Operation:   op,
+			DocumentKey: must.NotFail(doc.Get("_id")),
+		}
+
+		if op == pgdb.ChangeInsert || op == pgdb.ChangeReplace {
+			changes[i].FullDocument = doc
+		}
+	}
+
+	return pgdb.RecordChanges(ctx, tx, sp.DB, changes)
+}
+
+// trimChanges periodically removes changes recorded earlier than retention ago until ctx is canceled.
+func (h *Handler) trimChanges(ctx context.Context, retention time.Duration) {
+	for ctxutil.Sleep(ctx, changesTrimmingInterval) == nil {
+		n, err := pgdb.TrimChanges(ctx, h.pgPool, time.Now().Add(-retention))
+		if err != nil {
+			if ctx.Err() == nil {
+				h.l.Warn("Failed to remove old changes.", zap.Error(err))
+			}
+
+			continue
+		}
+
+		if n > 0 {
+			h.l.Debug("Old changes removed.", zap.Int64("count", n))
+		}
+	}
+}
+
+// changeStreamToken returns the resume token for the given change ID.
+func changeStreamToken(id int64) *types.Document {
+	return must.NotFail(types.NewDocument("_data", fmt.Sprintf("%016X", id)))
+}
+
+// parseChangeStreamToken returns the change ID for the given resume token.
+func parseChangeStreamToken(token any) (int64, error) {
+	if doc, ok := token.(*types.Document); ok && doc.Len() == 1 {
+		if data, ok := doc.Map()["_data"].(string); ok {
+			if id, err := strconv.ParseInt(data, 16, 64); err == nil && id >= 0 {
+				return id, nil
+			}
+		}
+	}
+
+	return 0, common.NewErrorMsg(common.ErrBadValue, fmt.Sprintf("invalid resume token: %v", token))
+}
+
+// changeEvent returns a change stream event document for the given change.
+func changeEvent(db string, c *pgdb.Change) *types.Document {
+	doc := must.NotFail(types.NewDocument(
+		"_id", changeStreamToken(c.ID),
+		"operationType", string(c.Operation),
+		"clusterTime", types.Timestamp(c.ClusterTime.Unix()<<32|(c.ID&0xffffffff)),
+		"wallTime", c.ClusterTime,
+		"ns", must.NotFail(types.NewDocument("db", db, "coll", c.Collection)),
+		"documentKey", must.NotFail(types.NewDocument("_id", c.DocumentKey)),
+	))
+
+	if c.FullDocument != nil {
+		must.NoError(doc.Set("fullDocument", c.FullDocument))
+	}
+
+	return doc
+}
+
+// nextChanges returns up to batchSize change events of the given cursor, waiting up to await for them.
+//
+// The cursor's resume token is advanced.
+func (h *Handler) nextChanges(ctx context.Context, c *changeStreamCursor, batchSize int, await time.Duration) (*types.Array, error) { //nolint:lll // argument list is too long
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	deadline := time.Now().Add(await)
+
+	for {
+		changes, err := pgdb.ListChanges(ctx, h.pgPool, c.db, c.collection, c.lastID, batchSize)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if len(changes) > 0 || batchSize == 0 || !time.Now().Add(changeStreamPollInterval).Before(deadline) {
+			res := types.MakeArray(len(changes))
+			for i := range changes {
+				must.NoError(res.Append(changeEvent(c.db, &changes[i])))
+				c.lastID = changes[i].ID
+			}
+
+			return res, nil
+		}
+
+		if err = ctxutil.Sleep(ctx, changeStreamPollInterval); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// changeStreamNS returns namespace of the given cursor as reported to clients.
+func changeStreamNS(c *changeStreamCursor) string {
+	if c.collection == "" {
+		return c.db + ".$cmd.aggregate"
+	}
+
+	return c.db + "." + c.collection
+}
+
+// msgChangeStream handles aggregate command with $changeStream stage.
+func (h *Handler) msgChangeStream(ctx context.Context, document *types.Document, pipeline *types.Array) (*types.Document, error) { //nolint:lll // argument list is too long
+	if !h.changeStreams {
+		return nil, common.NewErrorMsg(
+			common.ErrChangeStreamNotSupported,
+			"The $changeStream stage is only supported when change streams are enabled",
+		)
+	}
+
+	if pipeline.Len() != 1 {
+		return nil, common.NewErrorMsg(
+			common.ErrNotImplemented,
+			"$changeStream stage can't be combined with other stages yet",
+		)
+	}
+
+	db, err := common.GetRequiredParam[string](document, "$db")
+	if err != nil {
+		return nil, err
+	}
+
+	c := &changeStreamCursor{db: db}
+
+	// collection name or 1 for the whole database
+	switch collection := must.NotFail(document.Get(document.Command())).(type) {
+	case string:
+		c.collection = collection
+	default:
+		if n, err := common.GetWholeNumberParam(collection); err != nil || n != 1 {
+			return nil, common.NewErrorMsg(
+				common.ErrBadValue,
+				fmt.Sprintf("collection name has invalid type %s", common.AliasFromType(collection)),
+			)
+		}
+	}
+
+	stage := must.NotFail(pipeline.Get(0)).(*types.Document)
+
+	opts, err := common.GetRequiredParam[*types.Document](stage, "$changeStream")
+	if err != nil {
+		return nil, err
+	}
+
+	if err = common.Unimplemented(opts, "startAtOperationTime", "allChangesForCluster", "showExpandedEvents"); err != nil {
+		return nil, err
+	}
+
+	fullDocument, err := common.GetOptionalParam(opts, "fullDocument", "default")
+	if err != nil {
+		return nil, err
+	}
+	if fullDocument != "default" {
+		return nil, common.NewErrorMsg(common.ErrNotImplemented, fmt.Sprintf("fullDocument %q is not implemented yet", fullDocument))
+	}
+
+	var token any
+	for _, k := range []string{"resumeAfter", "startAfter"} {
+		if v, _ := opts.Get(k); v != nil {
+			if token != nil {
+				return nil, common.NewErrorMsg(common.ErrBadValue, "Only one type of resume option is allowed, but multiple were found")
+			}
+
+			token = v
+		}
+	}
+
+	if token != nil {
+		if c.lastID, err = parseChangeStreamToken(token); err != nil {
+			return nil, err
+		}
+	} else {
+		if c.lastID, err = pgdb.LastChangeID(ctx, h.pgPool, db); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+	}
+
+	size := int64(defaultChangeStreamBatchSize)
+	if cursor, _ := document.Get("cursor"); cursor != nil {
+		cursorDoc, err := common.AssertType[*types.Document](cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		if size, err = common.GetBatchSizeParam(cursorDoc, "aggregate.cursor", size); err != nil {
+			return nil, err
+		}
+	}
+
+	firstBatch, err := h.nextChanges(ctx, c, int(size), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// tailable cursor is always open
+	id := h.changeStreamCursors.add(c)
+
+	return must.NotFail(types.NewDocument(
+		"cursor", must.NotFail(types.NewDocument(
+			"firstBatch", firstBatch,
+			"postBatchResumeToken", changeStreamToken(c.lastID),
+			"id", id,
+			"ns", changeStreamNS(c),
+		)),
+		"ok", float64(1),
+	)), nil
+}
@@ -29,23 +29,42 @@ import (
 func (h *Handler) CmdQuery(ctx context.Context, query *wire.OpQuery) (*wire.OpReply, error) {
 	if query.FullCollectionName == "admin.$cmd" {
 		switch cmd := query.Query.Command(); cmd {
-		case "ismaster", "isMaster": // both are valid
+		case "ismaster", "isMaster", "hello": // all are valid
+			if err := common.ClientMetadata(ctx, query.Query); err != nil {
+				return nil, err
+			}
+
+			compression, err := common.Compression(ctx, query.Query)
+			if err != nil {
+				return nil, err
+			}
+
+			primaryKey := "ismaster" // only lowercase
+			if cmd == "hello" {
+				primaryKey = "isWritablePrimary"
+			}
+
+			res := must.NotFail(types.NewDocument(
+				primaryKey, true,
+				"topologyVersion", common.TopologyVersion(),
+				"maxBsonObjectSize", int32(types.MaxDocumentLen),
+				"maxMessageSizeBytes", int32(wire.MaxMsgLen),
+				"maxWriteBatchSize", int32(100000),
+				"localTime", time.Now(),
+				// logicalSessionTimeoutMinutes
+				// connectionId
+				"minWireVersion", int32(13),
+				"maxWireVersion", int32(13),
+				"readOnly", common.ReadOnly(ctx),
+			))
+			if compression.Len() > 0 {
+				must.NoError(res.Set("compression", compression))
+			}
+			must.NoError(res.Set("ok", float64(1)))
+
 			reply := &wire.OpReply{
 				NumberReturned: 1,
-				Documents: []*types.Document{must.NotFail(types.NewDocument(
-					"ismaster", true, // only lowercase
-					// topologyVersion
-					"maxBsonObjectSize", int32(types.MaxDocumentLen),
-					"maxMessageSizeBytes", int32(wire.MaxMsgLen),
-					"maxWriteBatchSize", int32(100000),
-					"localTime", time.Now(),
-					// logicalSessionTimeoutMinutes
-					// connectionId
-					"minWireVersion", int32(13),
-					"maxWireVersion", int32(13),
-					"readOnly", false,
-					"ok", float64(1),
-				))},
+				Documents:      []*types.Document{res},
 			}
 			return reply, nil
 
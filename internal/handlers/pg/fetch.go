@@ -0,0 +1,98 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pg
+
+import (
+	"context"
+	"errors"
+
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/pg/pgdb"
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// opStats returns statistics of the current operation and marks them as reported.
+func opStats(ctx context.Context) *conninfo.OpStats {
+	stats := &conninfo.GetConnInfo(ctx).OpStats
+	stats.Reported = true
+
+	return stats
+}
+
+// fetchMatching reads documents from the iterator and returns those matching the filter.
+//
+// If maxDocs is positive, it stops reading after that number of matching documents.
+func fetchMatching(ctx context.Context, iter *pgdb.Iterator, filter *types.Document, maxDocs int64) ([]*types.Document, error) {
+	stats := opStats(ctx)
+	res := make([]*types.Document, 0, 16)
+
+	for maxDocs <= 0 || int64(len(res)) < maxDocs {
+		doc, err := iter.Next(ctx)
+		if err != nil {
+			if errors.Is(err, pgdb.ErrIteratorDone) {
+				break
+			}
+
+			return nil, err
+		}
+
+		stats.DocsExamined++
+
+		matches, err := common.FilterDocument(doc, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		if matches {
+			res = append(res, doc)
+		}
+	}
+
+	return res, nil
+}
+
+// countMatching reads documents from the iterator and returns the number of documents matching the filter
+// without keeping them in memory.
+//
+// If maxDocs is positive, it stops reading after that number of matching documents.
+func countMatching(ctx context.Context, iter *pgdb.Iterator, filter *types.Document, maxDocs int64) (int64, error) {
+	stats := opStats(ctx)
+	var n int64
+
+	for maxDocs <= 0 || n < maxDocs {
+		doc, err := iter.Next(ctx)
+		if err != nil {
+			if errors.Is(err, pgdb.ErrIteratorDone) {
+				break
+			}
+
+			return 0, err
+		}
+
+		stats.DocsExamined++
+
+		matches, err := common.FilterDocument(doc, filter)
+		if err != nil {
+			return 0, err
+		}
+
+		if matches {
+			n++
+		}
+	}
+
+	return n, nil
+}
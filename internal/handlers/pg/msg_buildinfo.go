@@ -17,11 +17,50 @@ package pg
 import (
 	"context"
 
+	"go.uber.org/zap"
+
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
 // MsgBuildInfo implements HandlerInterface.
 func (h *Handler) MsgBuildInfo(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
-	return common.MsgBuildInfo(ctx, msg)
+	reply, err := common.MsgBuildInfo(ctx, msg)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	// buildInfo should work even if PostgreSQL is not available, so the version is added only if it is known
+	info, err := h.pgPool.ServerInfo(ctx)
+	if err != nil {
+		h.l.Warn("Failed to get PostgreSQL server info.", zap.Error(err))
+		return reply, nil
+	}
+
+	document, err := reply.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	// keep "ok" the last field
+	ok := document.Remove("ok")
+
+	if err = document.Set("postgresqlVersion", info.Version); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if err = document.Set("ok", ok); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	var res wire.OpMsg
+	if err = res.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{document},
+	}); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return &res, nil
 }
@@ -17,10 +17,11 @@ package pg
 import (
 	"context"
 
+	"github.com/jackc/pgx/v4"
+
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
-	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/handlers/pg/pgdb"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
-	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
@@ -31,37 +32,48 @@ func (h *Handler) MsgCollStats(ctx context.Context, msg *wire.OpMsg) (*wire.OpMs
 		return nil, lazyerrors.Error(err)
 	}
 
-	command := document.Command()
-
-	var db, collection string
-	if db, err = common.GetRequiredParam[string](document, "$db"); err != nil {
-		return nil, err
-	}
-	if collection, err = common.GetRequiredParam[string](document, command); err != nil {
+	params, err := common.GetCollStatsParams(document)
+	if err != nil {
 		return nil, err
 	}
 
+	db, collection := params.DB, params.Collection
+
 	stats, err := h.pgPool.SchemaStats(ctx, db, collection)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
-	var reply wire.OpMsg
-	err = reply.SetSections(wire.OpMsgSection{
-		Documents: []*types.Document{must.NotFail(types.NewDocument(
-			"ns", db+"."+collection,
-			"count", stats.CountRows,
-			"size", stats.SizeTotal,
-			"storageSize", stats.SizeRelation,
-			"totalIndexSize", stats.SizeIndexes,
-			"totalSize", stats.SizeTotal,
-			"scaleFactor", int32(1),
-			"ok", float64(1),
-		))},
+	// exact count is not required there, so use planner statistics if available
+	var count int64
+	err = h.pgPool.InReadTransactionRetry(ctx, func(tx pgx.Tx) error {
+		count, err = pgdb.CountDocuments(ctx, tx, db, collection, true)
+		return err
 	})
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
-	return &reply, nil
+	indexSizes := map[string]int64{}
+	var jsonbIndexSize int64
+	var jsonbIndexExists bool
+	err = h.pgPool.InReadTransactionRetry(ctx, func(tx pgx.Tx) error {
+		jsonbIndexSize, jsonbIndexExists, err = pgdb.JSONBIndexSize(ctx, tx, db, collection)
+		return err
+	})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+	if jsonbIndexExists {
+		indexSizes[pgdb.JSONBIndexName] = jsonbIndexSize
+	}
+
+	return common.CollStatsReply(params, &common.CollStats{
+		Count:          count,
+		Size:           stats.SizeTotal,
+		StorageSize:    stats.SizeRelation,
+		TotalIndexSize: stats.SizeIndexes,
+		IndexSizes:     indexSizes,
+		TotalSize:      stats.SizeTotal,
+	})
 }
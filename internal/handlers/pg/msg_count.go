@@ -36,7 +36,6 @@ func (h *Handler) MsgCount(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, e
 	}
 
 	unimplementedFields := []string{
-		"skip",
 		"collation",
 	}
 	if err := common.Unimplemented(document, unimplementedFields...); err != nil {
@@ -54,11 +53,15 @@ func (h *Handler) MsgCount(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, e
 		return nil, err
 	}
 
-	var limit int64
-	if l, _ := document.Get("limit"); l != nil {
-		if limit, err = common.GetWholeNumberParam(l); err != nil {
-			return nil, err
-		}
+	// negative limit is the same as positive for count
+	limit, _, err := common.GetLimitParam(document, document.Command())
+	if err != nil {
+		return nil, err
+	}
+
+	skip, err := common.GetSkipParam(document, document.Command())
+	if err != nil {
+		return nil, err
 	}
 
 	var sp pgdb.SQLParam
@@ -77,53 +80,69 @@ func (h *Handler) MsgCount(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, e
 		)
 	}
 
-	resDocs := make([]*types.Document, 0, 16)
-	err = h.pgPool.InTransaction(ctx, func(tx pgx.Tx) error {
-		fetchedChan, err := h.pgPool.QueryDocuments(ctx, tx, sp)
-		if err != nil {
+	// filters are not pushed down yet, so only an empty filter allows counting in PostgreSQL
+	if filter.Len() == 0 {
+		var n int64
+		err = h.pgPool.InReadTransactionRetry(ctx, func(tx pgx.Tx) error {
+			n, err = pgdb.CountDocuments(ctx, tx, sp.DB, sp.Collection, false)
 			return err
+		})
+		if err != nil {
+			return nil, lazyerrors.Error(err)
 		}
-		defer func() {
-			// Drain the channel to prevent leaking goroutines.
-			// TODO Offer a better design instead of channels: https://github.com/FerretDB/FerretDB/issues/898.
-			for range fetchedChan {
-			}
-		}()
-
-		for fetchedItem := range fetchedChan {
-			if fetchedItem.Err != nil {
-				return fetchedItem.Err
-			}
-
-			for _, doc := range fetchedItem.Docs {
-				matches, err := common.FilterDocument(doc, filter)
-				if err != nil {
-					return err
-				}
-
-				if !matches {
-					continue
-				}
-
-				resDocs = append(resDocs, doc)
-			}
+
+		return countReply(skipAndLimit(n, skip, limit))
+	}
+
+	// there is no need to examine documents after skipped and limited ones
+	var maxDocs int64
+	if limit > 0 {
+		maxDocs = skip + limit
+	}
+
+	var n int64
+	err = h.pgPool.InReadTransactionRetry(ctx, func(tx pgx.Tx) error {
+		iter, _, err := pgdb.QueryIterator(ctx, tx, sp)
+		if err != nil {
+			return err
 		}
+		defer iter.Close()
 
-		return nil
+		n, err = countMatching(ctx, iter, filter, maxDocs)
+		return err
 	})
 
 	if err != nil {
 		return nil, err
 	}
 
-	if resDocs, err = common.LimitDocuments(resDocs, limit); err != nil {
-		return nil, err
+	n = skipAndLimit(n, skip, limit)
+
+	opStats(ctx).DocsReturned = n
+
+	return countReply(n)
+}
+
+// skipAndLimit returns the number of documents left from n documents after applying
+// non-negative skip and limit values.
+func skipAndLimit(n, skip, limit int64) int64 {
+	if n -= skip; n < 0 {
+		n = 0
+	}
+
+	if limit > 0 && n > limit {
+		n = limit
 	}
 
+	return n
+}
+
+// countReply returns count command reply with the given number of documents.
+func countReply(n int64) (*wire.OpMsg, error) {
 	var reply wire.OpMsg
-	err = reply.SetSections(wire.OpMsgSection{
+	err := reply.SetSections(wire.OpMsgSection{
 		Documents: []*types.Document{must.NotFail(types.NewDocument(
-			"n", int32(len(resDocs)),
+			"n", int32(n),
 			"ok", float64(1),
 		))},
 	})
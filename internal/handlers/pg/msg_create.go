@@ -37,7 +37,6 @@ func (h *Handler) MsgCreate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 	}
 
 	unimplementedFields := []string{
-		"capped",
 		"timeseries",
 		"expireAfterSeconds",
 		"size",
@@ -48,13 +47,14 @@ func (h *Handler) MsgCreate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		"viewOn",
 		"pipeline",
 		"collation",
+		"capped",
 	}
 	if err := common.Unimplemented(document, unimplementedFields...); err != nil {
 		return nil, err
 	}
+
 	ignoredFields := []string{
 		"autoIndexId",
-		"storageEngine",
 		"indexOptionDefaults",
 		"writeConcern",
 		"comment",
@@ -71,16 +71,41 @@ func (h *Handler) MsgCreate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		return nil, err
 	}
 
+	opts := &pgdb.CreateCollectionOpts{
+		DisableJSONBIndex: h.disableJSONBIndex,
+	}
+
+	// storageEngine: {postgresql: {jsonbIndex: <bool>}} overrides handler's default for that collection
+	var storageEngine *types.Document
+	if storageEngine, err = common.GetOptionalParam(document, "storageEngine", storageEngine); err != nil {
+		return nil, err
+	}
+	if storageEngine != nil {
+		var pgOpts *types.Document
+		if pgOpts, err = common.GetOptionalParam(storageEngine, "postgresql", pgOpts); err != nil {
+			return nil, err
+		}
+
+		if pgOpts != nil {
+			var jsonbIndex bool
+			if jsonbIndex, err = common.GetOptionalParam(pgOpts, "jsonbIndex", !opts.DisableJSONBIndex); err != nil {
+				return nil, err
+			}
+
+			opts.DisableJSONBIndex = !jsonbIndex
+		}
+	}
+
 	err = h.pgPool.InTransaction(ctx, func(tx pgx.Tx) error {
 		if err := pgdb.CreateDatabaseIfNotExists(ctx, tx, db); err != nil {
-			if errors.Is(pgdb.ErrInvalidDatabaseName, err) {
+			if errors.Is(err, pgdb.ErrInvalidDatabaseName) {
 				msg := fmt.Sprintf("Invalid namespace: %s.%s", db, collection)
 				return common.NewErrorMsg(common.ErrInvalidNamespace, msg)
 			}
 			return lazyerrors.Error(err)
 		}
 
-		if err := pgdb.CreateCollection(ctx, tx, db, collection); err != nil {
+		if err := pgdb.CreateCollectionWithOpts(ctx, tx, db, collection, opts); err != nil {
 			if errors.Is(err, pgdb.ErrAlreadyExist) {
 				msg := fmt.Sprintf("Collection already exists. NS: %s.%s", db, collection)
 				return common.NewErrorMsg(common.ErrNamespaceExists, msg)
@@ -94,7 +119,7 @@ func (h *Handler) MsgCreate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		return nil
 	})
 	if err != nil {
-		return nil, err
+		return nil, connectionError(err)
 	}
 
 	var reply wire.OpMsg
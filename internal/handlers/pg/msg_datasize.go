@@ -16,7 +16,6 @@ package pg
 
 import (
 	"context"
-	"errors"
 	"strings"
 	"time"
 
@@ -37,44 +36,58 @@ func (h *Handler) MsgDataSize(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg
 		return nil, lazyerrors.Error(err)
 	}
 
+	// ranges are not supported; the size of the whole collection is returned only without them
 	if err := common.Unimplemented(document, "keyPattern", "min", "max"); err != nil {
 		return nil, err
 	}
-	common.Ignored(document, h.l, "estimate")
+
+	estimate, err := common.GetBoolOptionalParam(document, "estimate")
+	if err != nil {
+		return nil, err
+	}
 
 	m := document.Map()
 	target, ok := m["dataSize"].(string)
 	if !ok {
 		return nil, lazyerrors.New("no target collection")
 	}
-	targets := strings.Split(target, ".")
-	if len(targets) != 2 {
+	// database names can't contain dots, but collection names can
+	db, collection, ok := strings.Cut(target, ".")
+	if !ok {
 		return nil, lazyerrors.New("target collection must be like: 'database.collection'")
 	}
-	db, collection := targets[0], targets[1]
 
 	started := time.Now()
-	stats, err := h.pgPool.SchemaStats(ctx, db, collection)
-	elapses := time.Since(started)
 
-	addEstimate := true
-	if err != nil {
-		if !errors.Is(err, pgx.ErrNoRows) {
-			return nil, lazyerrors.Error(err)
+	var exists bool
+	var size, count int64
+	err = h.pgPool.InReadTransactionRetry(ctx, func(tx pgx.Tx) error {
+		var err error
+		if exists, err = pgdb.CollectionExists(ctx, tx, db, collection); err != nil || !exists {
+			return err
 		}
 
-		// return zeroes for non-existent collection
-		stats = new(pgdb.DBStats)
-		addEstimate = false
+		if size, err = pgdb.CollectionDataSize(ctx, tx, db, collection); err != nil {
+			return err
+		}
+
+		count, err = pgdb.CountDocuments(ctx, tx, db, collection, estimate)
+		return err
+	})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
 	}
 
+	elapses := time.Since(started)
+
+	// return zeroes without estimate field for non-existent collection
 	var pairs []any
-	if addEstimate {
-		pairs = append(pairs, "estimate", false)
+	if exists {
+		pairs = append(pairs, "estimate", estimate)
 	}
 	pairs = append(pairs,
-		"size", int32(stats.SizeTotal),
-		"numObjects", stats.CountRows,
+		"size", int32(size),
+		"numObjects", int32(count),
 		"millis", int32(elapses.Milliseconds()),
 		"ok", float64(1),
 	)
@@ -16,8 +16,13 @@ package pg
 
 import (
 	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v4"
+	"golang.org/x/exp/slices"
 
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/pg/pgdb"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
@@ -36,20 +41,73 @@ func (h *Handler) MsgDBStats(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		return nil, err
 	}
 
-	m := document.Map()
-	scale, ok := m["scale"].(float64)
-	if !ok {
-		scale = 1
+	scale, err := common.GetScaleParam(document, "dbStats")
+	if err != nil {
+		return nil, err
+	}
+
+	// PostgreSQL schemas that are not FerretDB databases are reported as empty databases
+	databases, err := pgdb.Databases(ctx, h.pgPool)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	exists := slices.Contains(databases, db)
+
+	stats := new(pgdb.DBStats)
+	if exists {
+		if stats, err = h.pgPool.SchemaStats(ctx, db, ""); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
 	}
 
-	stats, err := h.pgPool.SchemaStats(ctx, db, "")
+	// exact count is not required there, so use planner statistics if available
+	var objects, dataSize, totalSize int64
+	err = h.pgPool.InReadTransactionRetry(ctx, func(tx pgx.Tx) error {
+		objects, dataSize, totalSize = 0, 0, 0
+
+		if !exists {
+			return nil
+		}
+
+		var err error
+		if totalSize, err = pgdb.DatabaseSize(ctx, tx, db); err != nil {
+			return err
+		}
+
+		collections, err := pgdb.Collections(ctx, tx, db)
+		if err != nil {
+			if errors.Is(err, pgdb.ErrSchemaNotExist) {
+				return nil
+			}
+			return err
+		}
+
+		for _, collection := range collections {
+			n, err := pgdb.CountDocuments(ctx, tx, db, collection, true)
+			if err != nil {
+				return err
+			}
+
+			objects += n
+
+			size, err := pgdb.CollectionDataSize(ctx, tx, db, collection)
+			if err != nil {
+				return err
+			}
+
+			dataSize += size
+		}
+
+		return nil
+	})
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
 	var avgObjSize float64
-	if stats.CountRows > 0 {
-		avgObjSize = float64(stats.SizeRelation) / float64(stats.CountRows)
+	if objects > 0 {
+		avgObjSize = float64(dataSize) / float64(objects)
 	}
 
 	var reply wire.OpMsg
@@ -59,12 +117,12 @@ func (h *Handler) MsgDBStats(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 			"collections", stats.CountTables,
 			// TODO https://github.com/FerretDB/FerretDB/issues/176
 			"views", int32(0),
-			"objects", stats.CountRows,
+			"objects", int32(objects),
 			"avgObjSize", avgObjSize,
-			"dataSize", float64(stats.SizeRelation)/scale,
+			"dataSize", float64(dataSize)/scale,
 			"indexes", stats.CountIndexes,
 			"indexSize", float64(stats.SizeIndexes)/scale,
-			"totalSize", float64(stats.SizeTotal)/scale,
+			"totalSize", float64(totalSize)/scale,
 			"scaleFactor", scale,
 			"ok", float64(1),
 		))},
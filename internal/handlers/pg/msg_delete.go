@@ -38,7 +38,6 @@ func (h *Handler) MsgDelete(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 	if err := common.Unimplemented(document, "let"); err != nil {
 		return nil, err
 	}
-	common.Ignored(document, h.l, "ordered") // TODO https://github.com/FerretDB/FerretDB/issues/848
 	common.Ignored(document, h.l, "writeConcern")
 
 	var deletes *types.Array
@@ -46,136 +45,180 @@ func (h *Handler) MsgDelete(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		return nil, err
 	}
 
+	ordered := true
+	if document.Has("ordered") {
+		if ordered, err = common.GetBoolOptionalParam(document, "ordered"); err != nil {
+			return nil, err
+		}
+	}
+
+	var sp pgdb.SQLParam
+	if sp.DB, err = common.GetRequiredParam[string](document, "$db"); err != nil {
+		return nil, err
+	}
+	collectionParam, err := document.Get(document.Command())
+	if err != nil {
+		return nil, err
+	}
+	var ok bool
+	if sp.Collection, ok = collectionParam.(string); !ok {
+		return nil, common.NewErrorMsg(
+			common.ErrBadValue,
+			fmt.Sprintf("collection name has invalid type %s", common.AliasFromType(collectionParam)),
+		)
+	}
+
+	// get comment from options.Delete().SetComment() method
+	if sp.Comment, err = common.GetOptionalParam(document, "comment", sp.Comment); err != nil {
+		return nil, err
+	}
+
 	var deleted int32
+	var writeErrs common.WriteErrors
 	for i := 0; i < deletes.Len(); i++ {
 		d, err := common.AssertType[*types.Document](must.NotFail(deletes.Get(i)))
 		if err != nil {
 			return nil, err
 		}
 
-		if err := common.Unimplemented(d, "collation", "hint"); err != nil {
-			return nil, err
-		}
+		n, err := h.execDelete(ctx, sp, d)
+		deleted += n
 
-		var filter *types.Document
-		if filter, err = common.GetOptionalParam(d, "q", filter); err != nil {
-			return nil, err
+		if err == nil {
+			continue
 		}
 
-		var limit int64
-		if l, _ := d.Get("limit"); l != nil {
-			if limit, err = common.GetWholeNumberParam(l); err != nil {
-				return nil, err
-			}
+		// errors of individual statements are reported as write errors
+		if _, ok := common.ProtocolError(err); !ok {
+			return nil, retryableWriteError(document, err)
 		}
 
-		var sp pgdb.SQLParam
-		if sp.DB, err = common.GetRequiredParam[string](document, "$db"); err != nil {
-			return nil, err
-		}
-		collectionParam, err := document.Get(document.Command())
-		if err != nil {
-			return nil, err
-		}
-		var ok bool
-		if sp.Collection, ok = collectionParam.(string); !ok {
-			return nil, common.NewErrorMsg(
-				common.ErrBadValue,
-				fmt.Sprintf("collection name has invalid type %s", common.AliasFromType(collectionParam)),
-			)
-		}
+		writeErrs.Append(err, int32(i))
 
-		// get comment from options.Delete().SetComment() method
-		if sp.Comment, err = common.GetOptionalParam(document, "comment", sp.Comment); err != nil {
-			return nil, err
+		if ordered {
+			break
 		}
+	}
 
-		// get comment from query, e.g. db.collection.DeleteOne({"_id":"string", "$comment: "test"})
-		if sp.Comment, err = common.GetOptionalParam(filter, "$comment", sp.Comment); err != nil {
-			return nil, err
+	opStats(ctx).DocsReturned = int64(deleted)
+
+	replyDoc := must.NotFail(types.NewDocument(
+		"n", deleted,
+	))
+	if len(writeErrs) > 0 {
+		must.NoError(replyDoc.Set("writeErrors", must.NotFail(writeErrs.Document().Get("writeErrors"))))
+	}
+	must.NoError(replyDoc.Set("ok", float64(1)))
+
+	var reply wire.OpMsg
+	err = reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{replyDoc},
+	})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return &reply, nil
+}
+
+// execDelete executes a single delete statement and returns the number of deleted documents.
+func (h *Handler) execDelete(ctx context.Context, sp pgdb.SQLParam, d *types.Document) (int32, error) {
+	if err := common.Unimplemented(d, "collation", "hint"); err != nil {
+		return 0, err
+	}
+
+	var filter *types.Document
+	var err error
+	if filter, err = common.GetOptionalParam(d, "q", filter); err != nil {
+		return 0, err
+	}
+
+	var limit int64
+	if l, _ := d.Get("limit"); l != nil {
+		if limit, err = common.GetWholeNumberParam(l); err != nil {
+			return 0, err
 		}
+	}
 
-		resDocs := make([]*types.Document, 0, 16)
-		err = h.pgPool.InTransaction(ctx, func(tx pgx.Tx) error {
-			fetchedChan, err := h.pgPool.QueryDocuments(ctx, tx, sp)
+	// get comment from query, e.g. db.collection.DeleteOne({"_id":"string", "$comment: "test"})
+	if sp.Comment, err = common.GetOptionalParam(filter, "$comment", sp.Comment); err != nil {
+		return 0, err
+	}
+
+	// negative limits are not supported yet
+	if _, err = common.LimitDocuments(nil, limit); err != nil {
+		return 0, err
+	}
+
+	sp.Filter = filter
+
+	var rowsDeleted int32
+	err = h.pgPool.InTransactionRetry(ctx, func(tx pgx.Tx) error {
+		rowsDeleted = 0
+
+		// simple filters are handled by a single DELETE statement,
+		// unless changes are recorded and deleted documents should be known
+		if !h.changeStreams {
+			n, pushdown, err := pgdb.DeleteDocumentsByFilter(ctx, tx, &sp, limit)
 			if err != nil {
 				return err
 			}
-			defer func() {
-				// Drain the channel to prevent leaking goroutines.
-				// TODO Offer a better design instead of channels: https://github.com/FerretDB/FerretDB/issues/898.
-				for range fetchedChan {
-				}
-			}()
-
-			for fetchedItem := range fetchedChan {
-				if fetchedItem.Err != nil {
-					return fetchedItem.Err
-				}
-
-				for _, doc := range fetchedItem.Docs {
-					matches, err := common.FilterDocument(doc, filter)
-					if err != nil {
-						return err
-					}
-
-					if !matches {
-						continue
-					}
-
-					resDocs = append(resDocs, doc)
-				}
-
-				if resDocs, err = common.LimitDocuments(resDocs, limit); err != nil {
-					return err
-				}
-
-				if len(resDocs) == 0 {
-					continue
-				}
-
-				rowsDeleted, err := h.delete(ctx, &sp, resDocs)
-				if err != nil {
-					return err
-				}
-
-				deleted += int32(rowsDeleted)
+			if pushdown {
+				opStats(ctx).Pushdown = true
+				rowsDeleted = int32(n)
+				return nil
 			}
+		}
 
+		iter, _, err := pgdb.QueryIterator(ctx, tx, sp)
+		if err != nil {
+			return err
+		}
+
+		resDocs, err := fetchMatching(ctx, iter, filter, limit)
+		iter.Close()
+
+		if err != nil {
+			return err
+		}
+
+		if len(resDocs) == 0 {
 			return nil
-		})
+		}
 
+		n, err := h.delete(ctx, tx, &sp, resDocs)
 		if err != nil {
-			return nil, err
+			return err
 		}
-	}
 
-	var reply wire.OpMsg
-	err = reply.SetSections(wire.OpMsgSection{
-		Documents: []*types.Document{must.NotFail(types.NewDocument(
-			"n", deleted,
-			"ok", float64(1),
-		))},
+		rowsDeleted = int32(n)
+
+		return nil
 	})
 	if err != nil {
-		return nil, lazyerrors.Error(err)
+		return 0, err
 	}
 
-	return &reply, nil
+	return rowsDeleted, nil
 }
 
 // delete deletes documents by _id.
-func (h *Handler) delete(ctx context.Context, sp *pgdb.SQLParam, docs []*types.Document) (int64, error) {
+func (h *Handler) delete(ctx context.Context, tx pgx.Tx, sp *pgdb.SQLParam, docs []*types.Document) (int64, error) {
 	ids := make([]any, len(docs))
 	for i, doc := range docs {
 		id := must.NotFail(doc.Get("_id"))
 		ids[i] = id
 	}
 
-	rowsDeleted, err := h.pgPool.DeleteDocumentsByID(ctx, sp, ids)
+	rowsDeleted, err := pgdb.DeleteDocumentsByID(ctx, tx, sp, ids)
 	if err != nil {
 		// TODO check error code
 		return 0, common.NewError(common.ErrNamespaceNotFound, fmt.Errorf("delete: ns not found: %w", err))
 	}
+
+	if err = h.recordChanges(ctx, tx, sp, pgdb.ChangeDelete, docs...); err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
 	return rowsDeleted, nil
 }
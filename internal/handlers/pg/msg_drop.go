@@ -52,7 +52,7 @@ func (h *Handler) MsgDrop(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 	case errors.Is(err, pgdb.ErrSchemaNotExist), errors.Is(err, pgdb.ErrTableNotExist):
 		return nil, common.NewErrorMsg(common.ErrNamespaceNotFound, "ns not found")
 	default:
-		return nil, lazyerrors.Error(err)
+		return nil, connectionError(lazyerrors.Error(err))
 	}
 
 	var reply wire.OpMsg
@@ -17,6 +17,7 @@ package pg
 import (
 	"context"
 	"errors"
+	"fmt"
 
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
 	"github.com/FerretDB/FerretDB/internal/handlers/pg/pgdb"
@@ -40,18 +41,21 @@ func (h *Handler) MsgDropDatabase(ctx context.Context, msg *wire.OpMsg) (*wire.O
 		return nil, err
 	}
 
-	res := must.NotFail(types.NewDocument())
-	err = h.pgPool.DropDatabase(ctx, db)
-	switch {
-	case err == nil:
-		res.Set("dropped", db)
-	case errors.Is(err, pgdb.ErrSchemaNotExist):
-		// nothing
-	default:
-		return nil, lazyerrors.Error(err)
+	dropped, err := pgdb.DropDatabaseIfExists(ctx, h.pgPool, db)
+	if err != nil {
+		if errors.Is(err, pgdb.ErrInvalidDatabaseName) {
+			msg := fmt.Sprintf("Invalid database name: '%s'", db)
+			return nil, common.NewErrorMsg(common.ErrInvalidNamespace, msg)
+		}
+		return nil, connectionError(lazyerrors.Error(err))
 	}
 
-	res.Set("ok", float64(1))
+	// "dropped" field is present only if database existed
+	res := must.NotFail(types.NewDocument())
+	if dropped {
+		must.NoError(res.Set("dropped", db))
+	}
+	must.NoError(res.Set("ok", float64(1)))
 
 	var reply wire.OpMsg
 	err = reply.SetSections(wire.OpMsgSection{
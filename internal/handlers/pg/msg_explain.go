@@ -54,12 +54,30 @@ func (h *Handler) MsgExplain(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		return nil, lazyerrors.Error(err)
 	}
 
+	if sp.Filter, err = common.GetOptionalParam(command, "filter", sp.Filter); err != nil {
+		return nil, err
+	}
+	if sp.Sort, err = common.GetOptionalParam(command, "sort", sp.Sort); err != nil {
+		return nil, err
+	}
+	if l, _ := command.Get("limit"); l != nil {
+		if sp.Limit, err = common.GetWholeNumberParam(l); err != nil {
+			return nil, err
+		}
+	}
+	if s, _ := command.Get("skip"); s != nil {
+		if sp.Skip, err = common.GetWholeNumberParam(s); err != nil {
+			return nil, err
+		}
+	}
+
 	sp.Explain = true
 
 	var queryPlanner *types.Array
-	err = h.pgPool.InTransaction(ctx, func(tx pgx.Tx) error {
+	var qr *pgdb.QueryResults
+	err = h.pgPool.InReadTransactionRetry(ctx, func(tx pgx.Tx) error {
 		var err error
-		queryPlanner, err = pgdb.Explain(ctx, tx, sp)
+		queryPlanner, qr, err = pgdb.Explain(ctx, tx, sp)
 		return err
 	})
 	if err != nil {
@@ -73,8 +91,8 @@ func (h *Handler) MsgExplain(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 
 	var port int32
 	connInfo := conninfo.GetConnInfo(ctx)
-	if connInfo.PeerAddr != nil {
-		port = int32(connInfo.PeerAddr.(*net.TCPAddr).Port)
+	if addr, ok := connInfo.PeerAddr.(*net.TCPAddr); ok { // nil or *net.UnixAddr for Unix domain sockets
+		port = int32(addr.Port)
 	}
 
 	serverInfo := must.NotFail(types.NewDocument(
@@ -90,6 +108,8 @@ func (h *Handler) MsgExplain(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		Documents: []*types.Document{must.NotFail(types.NewDocument(
 			"queryPlanner", queryPlanner,
 			"explainVersion", int32(1),
+			"sortPushdown", qr.SortPushdown,
+			"limitPushdown", qr.LimitPushdown,
 			"command", command,
 			"serverInfo", serverInfo,
 			"ok", float64(1),
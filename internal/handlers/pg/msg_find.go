@@ -21,6 +21,7 @@ import (
 
 	"github.com/jackc/pgx/v4"
 
+	"github.com/FerretDB/FerretDB/internal/cursor"
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
 	"github.com/FerretDB/FerretDB/internal/handlers/pg/pgdb"
 	"github.com/FerretDB/FerretDB/internal/types"
@@ -37,12 +38,10 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 	}
 
 	unimplementedFields := []string{
-		"skip",
 		"returnKey",
 		"showRecordId",
 		"tailable",
 		"oplogReplay",
-		"noCursorTimeout",
 		"awaitData",
 		"allowPartialResults",
 		"collation",
@@ -54,8 +53,6 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 	}
 	ignoredFields := []string{
 		"hint",
-		"batchSize",
-		"singleBatch",
 		"readConcern",
 		"max",
 		"min",
@@ -85,11 +82,34 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 		ctx = ctxWithTimeout
 	}
 
-	var limit int64
-	if l, _ := document.Get("limit"); l != nil {
-		if limit, err = common.GetWholeNumberParam(l); err != nil {
-			return nil, err
-		}
+	batchSize, err := common.GetBatchSizeParam(document, document.Command(), common.DefaultBatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	singleBatch, err := common.GetBoolOptionalParam(document, "singleBatch")
+	if err != nil {
+		return nil, err
+	}
+
+	noCursorTimeout, err := common.GetBoolOptionalParam(document, "noCursorTimeout")
+	if err != nil {
+		return nil, err
+	}
+
+	// negative limit means a single batch
+	limit, singleBatchLimit, err := common.GetLimitParam(document, document.Command())
+	if err != nil {
+		return nil, err
+	}
+	if singleBatchLimit {
+		singleBatch = true
+		batchSize = limit
+	}
+
+	skip, err := common.GetSkipParam(document, document.Command())
+	if err != nil {
+		return nil, err
 	}
 
 	var sp pgdb.SQLParam
@@ -119,39 +139,42 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 		}
 	}
 
-	resDocs := make([]*types.Document, 0, 16)
-	err = h.pgPool.InTransaction(ctx, func(tx pgx.Tx) error {
-		fetchedChan, err := h.pgPool.QueryDocuments(ctx, tx, sp)
-		if err != nil {
+	sp.Filter = filter
+	sp.Sort = sort
+	sp.Limit = limit
+	sp.Skip = skip
+
+	var resDocs []*types.Document
+	var qr *pgdb.QueryResults
+	err = h.pgPool.InReadTransactionRetry(ctx, func(tx pgx.Tx) error {
+		if resDocs, qr, err = fetchFind(ctx, tx, sp); err != nil {
 			return err
 		}
-		defer func() {
-			// Drain the channel to prevent leaking goroutines.
-			// TODO Offer a better design instead of channels: https://github.com/FerretDB/FerretDB/issues/898.
-			for range fetchedChan {
-			}
-		}()
-
-		for fetchedItem := range fetchedChan {
-			if fetchedItem.Err != nil {
-				return fetchedItem.Err
-			}
-
-			for _, doc := range fetchedItem.Docs {
-				matches, err := common.FilterDocument(doc, filter)
-				if err != nil {
-					return err
-				}
 
-				if !matches {
-					continue
-				}
+		if !qr.SortPushdown {
+			return nil
+		}
 
-				resDocs = append(resDocs, doc)
+		// only returned documents should be checked, see SortPushdownExact
+		window := resDocs
+		if !qr.LimitPushdown {
+			if int64(len(window)) > skip {
+				window = window[skip:]
+			} else {
+				window = nil
 			}
 		}
 
-		return nil
+		if pgdb.SortPushdownExact(window) {
+			return nil
+		}
+
+		// PostgreSQL could sort some _id values differently, repeat the query and sort in memory
+		noSortPushdown := sp
+		noSortPushdown.DisableSortPushdown = true
+
+		resDocs, qr, err = fetchFind(ctx, tx, noSortPushdown)
+		return err
 	})
 
 	if err != nil {
@@ -161,18 +184,28 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 	if err = common.SortDocuments(resDocs, sort); err != nil {
 		return nil, err
 	}
-	if resDocs, err = common.LimitDocuments(resDocs, limit); err != nil {
-		return nil, err
+	if !qr.LimitPushdown {
+		if resDocs, err = common.SkipDocuments(resDocs, skip); err != nil {
+			return nil, err
+		}
+		if resDocs, err = common.LimitDocuments(resDocs, limit); err != nil {
+			return nil, err
+		}
 	}
 	if err = common.ProjectDocuments(resDocs, projection); err != nil {
 		return nil, err
 	}
 
-	firstBatch := types.MakeArray(len(resDocs))
-	for _, doc := range resDocs {
-		if err = firstBatch.Append(doc); err != nil {
-			return nil, err
-		}
+	stats := opStats(ctx)
+	stats.DocsReturned = int64(len(resDocs))
+	stats.Pushdown = qr.SortPushdown || qr.LimitPushdown
+
+	c := cursor.New(sp.DB, sp.Collection, cursor.NewSliceIterator(resDocs))
+	c.NoTimeout = noCursorTimeout
+
+	firstBatch, id, err := common.FirstBatch(ctx, h.cursors, c, batchSize, singleBatch)
+	if err != nil {
+		return nil, err
 	}
 
 	var reply wire.OpMsg
@@ -180,8 +213,8 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 		Documents: []*types.Document{must.NotFail(types.NewDocument(
 			"cursor", must.NotFail(types.NewDocument(
 				"firstBatch", firstBatch,
-				"id", int64(0), // TODO
-				"ns", sp.DB+"."+sp.Collection,
+				"id", id,
+				"ns", c.NS(),
 			)),
 			"ok", float64(1),
 		))},
@@ -192,3 +225,27 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 
 	return &reply, nil
 }
+
+// fetchFind fetches documents matching the filter for the given query parameters.
+//
+// If documents should be returned in the natural or pushed down order,
+// it stops reading as soon as there are enough matching documents for skip and limit.
+func fetchFind(ctx context.Context, tx pgx.Tx, sp pgdb.SQLParam) ([]*types.Document, *pgdb.QueryResults, error) {
+	iter, qr, err := pgdb.QueryIterator(ctx, tx, sp)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer iter.Close()
+
+	var maxDocs int64
+	if (sp.Sort.Len() == 0 || qr.SortPushdown) && !qr.LimitPushdown && sp.Limit > 0 && sp.Skip >= 0 {
+		maxDocs = sp.Skip + sp.Limit
+	}
+
+	docs, err := fetchMatching(ctx, iter, sp.Filter, maxDocs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return docs, qr, nil
+}
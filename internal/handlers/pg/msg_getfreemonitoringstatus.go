@@ -18,10 +18,31 @@ import (
 	"context"
 
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/pg/pgdb"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
 // MsgGetFreeMonitoringStatus implements HandlerInterface.
 func (h *Handler) MsgGetFreeMonitoringStatus(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
-	return common.MsgGetFreeMonitoringStatus(ctx, msg)
+	state, err := h.freeMonitoringState(ctx)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return common.GetFreeMonitoringStatusReply(state)
+}
+
+// freeMonitoringState returns the stored free monitoring state, or "undecided" if it was never set.
+func (h *Handler) freeMonitoringState(ctx context.Context) (string, error) {
+	state, err := pgdb.GetFreeMonitoringState(ctx, h.pgPool)
+	if err != nil {
+		return "", lazyerrors.Error(err)
+	}
+
+	if state == "" {
+		state = common.FreeMonitoringUndecided
+	}
+
+	return state, nil
 }
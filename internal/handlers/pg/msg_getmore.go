@@ -0,0 +1,94 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pg
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// MsgGetMore implements HandlerInterface.
+func (h *Handler) MsgGetMore(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	id, err := common.GetRequiredParam[int64](document, document.Command())
+	if err != nil {
+		return nil, err
+	}
+
+	c := h.changeStreamCursors.get(id)
+	if c == nil {
+		return common.GetMore(ctx, document, h.cursors)
+	}
+
+	collection, err := common.GetRequiredParam[string](document, "collection")
+	if err != nil {
+		return nil, err
+	}
+
+	if db, _ := document.Get("$db"); db != c.db || (c.collection != "" && collection != c.collection) {
+		errMsg := fmt.Sprintf(
+			"Requested getMore on namespace '%s.%s', but cursor belongs to a different namespace %s",
+			db, collection, changeStreamNS(c),
+		)
+
+		return nil, common.NewErrorMsg(common.ErrBadValue, errMsg)
+	}
+
+	size, err := common.GetBatchSizeParam(document, document.Command(), defaultChangeStreamBatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	await := defaultChangeStreamAwait
+	if v, _ := document.Get("maxTimeMS"); v != nil {
+		ms, err := common.GetWholeNumberParam(v)
+		if err != nil || ms < 0 {
+			return nil, common.NewErrorMsg(common.ErrBadValue, fmt.Sprintf("invalid maxTimeMS: %v", v))
+		}
+
+		await = time.Duration(ms) * time.Millisecond
+	}
+
+	nextBatch, err := h.nextChanges(ctx, c, int(size), await)
+	if err != nil {
+		return nil, err
+	}
+
+	var reply wire.OpMsg
+	must.NoError(reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"cursor", must.NotFail(types.NewDocument(
+				"nextBatch", nextBatch,
+				"postBatchResumeToken", changeStreamToken(c.lastID),
+				"id", id,
+				"ns", changeStreamNS(c),
+			)),
+			"ok", float64(1),
+		))},
+	}))
+
+	return &reply, nil
+}
@@ -36,6 +36,8 @@ func (h *Handler) MsgGetParameter(ctx context.Context, msg *wire.OpMsg) (*wire.O
 		return nil, lazyerrors.Error(err)
 	}
 
+	slowMS, sampleRate := common.SlowOpsParameters(ctx)
+
 	resDB := must.NotFail(types.NewDocument(
 		"acceptApiVersion2", must.NotFail(types.NewDocument(
 			"value", false,
@@ -62,6 +64,26 @@ func (h *Handler) MsgGetParameter(ctx context.Context, msg *wire.OpMsg) (*wire.O
 			"settableAtRuntime", true,
 			"settableAtStartup", true,
 		)),
+		"slowms", must.NotFail(types.NewDocument(
+			"value", slowMS,
+			"settableAtRuntime", true,
+			"settableAtStartup", true,
+		)),
+		"sampleRate", must.NotFail(types.NewDocument(
+			"value", sampleRate,
+			"settableAtRuntime", true,
+			"settableAtStartup", true,
+		)),
+		"readOnly", must.NotFail(types.NewDocument(
+			"value", common.ReadOnly(ctx),
+			"settableAtRuntime", true,
+			"settableAtStartup", true,
+		)),
+		"cursorTimeoutMillis", must.NotFail(types.NewDocument(
+			"value", h.cursors.Timeout().Milliseconds(),
+			"settableAtRuntime", true,
+			"settableAtStartup", true,
+		)),
 		"ok", float64(1),
 	))
 
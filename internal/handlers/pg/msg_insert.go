@@ -31,12 +31,22 @@ import (
 
 // MsgInsert implements HandlerInterface.
 func (h *Handler) MsgInsert(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
-	document, err := msg.Document()
+	document, oversized, err := msg.DocumentWithOversized()
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
-	common.Ignored(document, h.l, "ordered", "writeConcern", "bypassDocumentValidation", "comment")
+	// too large documents are reported as write errors
+	docErrs := make(map[int]error, len(oversized))
+	for _, e := range oversized {
+		if e.Identifier != "documents" {
+			return nil, e
+		}
+
+		docErrs[e.Index] = common.NewError(common.ErrBSONObjectTooLarge, e)
+	}
+
+	common.Ignored(document, h.l, "writeConcern", "bypassDocumentValidation", "comment")
 
 	var sp pgdb.SQLParam
 	if sp.DB, err = common.GetRequiredParam[string](document, "$db"); err != nil {
@@ -54,32 +64,54 @@ func (h *Handler) MsgInsert(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		)
 	}
 
-	var docs *types.Array
-	if docs, err = common.GetOptionalParam(document, "documents", docs); err != nil {
+	var docsParam *types.Array
+	if docsParam, err = common.GetOptionalParam(document, "documents", docsParam); err != nil {
 		return nil, err
 	}
 
-	var inserted int32
-	for i := 0; i < docs.Len(); i++ {
-		doc, err := docs.Get(i)
-		if err != nil {
-			return nil, lazyerrors.Error(err)
+	ordered := true
+	if document.Has("ordered") {
+		if ordered, err = common.GetBoolOptionalParam(document, "ordered"); err != nil {
+			return nil, err
 		}
+	}
 
-		err = h.insert(ctx, sp, doc)
-		if err != nil {
-			return nil, err
+	docs := make([]*types.Document, docsParam.Len())
+	for i := 0; i < docsParam.Len(); i++ {
+		doc := must.NotFail(docsParam.Get(i))
+
+		if docs[i], ok = doc.(*types.Document); !ok {
+			return nil, common.NewErrorMsg(
+				common.ErrBadValue,
+				fmt.Sprintf("document has invalid type %s", common.AliasFromType(doc)),
+			)
 		}
 
-		inserted++
+		if _, ok := docErrs[i]; ok {
+			continue
+		}
+
+		if err := common.ValidateInsertDocument(docs[i]); err != nil {
+			docErrs[i] = err
+		}
 	}
 
+	inserted, writeErrs, err := h.insertDocuments(ctx, sp, docs, docErrs, ordered)
+	if err != nil {
+		return nil, retryableWriteError(document, err)
+	}
+
+	replyDoc := must.NotFail(types.NewDocument(
+		"n", inserted,
+	))
+	if len(writeErrs) > 0 {
+		must.NoError(replyDoc.Set("writeErrors", must.NotFail(writeErrs.Document().Get("writeErrors"))))
+	}
+	must.NoError(replyDoc.Set("ok", float64(1)))
+
 	var reply wire.OpMsg
 	err = reply.SetSections(wire.OpMsgSection{
-		Documents: []*types.Document{must.NotFail(types.NewDocument(
-			"n", inserted,
-			"ok", float64(1),
-		))},
+		Documents: []*types.Document{replyDoc},
 	})
 	if err != nil {
 		return nil, lazyerrors.Error(err)
@@ -88,8 +120,102 @@ func (h *Handler) MsgInsert(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 	return &reply, nil
 }
 
-// insert prepares and executes actual INSERT request to Postgres.
-func (h *Handler) insert(ctx context.Context, sp pgdb.SQLParam, doc any) error {
+// insertDocuments inserts documents into PostgreSQL in a single transaction.
+//
+// Documents are inserted with multi-row INSERT statements first.
+// If that fails, they are inserted one by one, each in its own savepoint, to find failing documents.
+// In ordered mode, the first failing document stops the insertion.
+//
+// docErrs contains errors for documents (by index) that should not be inserted at all,
+// like too large ones; they are reported in the same way as failing documents.
+//
+// Errors for individual documents are returned as WriteErrors;
+// errors that affect all documents (like invalid namespace) are returned as error.
+func (h *Handler) insertDocuments(ctx context.Context, sp pgdb.SQLParam, docs []*types.Document, docErrs map[int]error, ordered bool) (int32, common.WriteErrors, error) { //nolint:lll // argument list is too long
+	var inserted int32
+	var writeErrs common.WriteErrors
+
+	err := h.pgPool.InTransactionRetry(ctx, func(tx pgx.Tx) error {
+		inserted = 0
+		writeErrs = nil
+
+		// create collection outside of savepoints, so it is not rolled back with failed documents
+		if _, err := h.ensureCollection(ctx, tx, sp.DB, sp.Collection); err != nil {
+			if nsErr := insertError(sp, err); nsErr != nil {
+				return nsErr
+			}
+			return err
+		}
+
+		if len(docErrs) == 0 {
+			err := inSavepoint(ctx, tx, func(tx pgx.Tx) error {
+				if err := pgdb.InsertDocuments(ctx, tx, sp.DB, sp.Collection, docs); err != nil {
+					return err
+				}
+
+				return h.recordChanges(ctx, tx, &sp, pgdb.ChangeInsert, docs...)
+			})
+			if err == nil {
+				inserted = int32(len(docs))
+				return nil
+			}
+
+			if fatalErr := insertError(sp, err); fatalErr != nil {
+				return fatalErr
+			}
+		}
+
+		// find failing documents
+		for i, doc := range docs {
+			if err, ok := docErrs[i]; ok {
+				writeErrs.Append(err, int32(i))
+
+				if ordered {
+					break
+				}
+
+				continue
+			}
+
+			err := inSavepoint(ctx, tx, func(tx pgx.Tx) error {
+				if err := pgdb.InsertDocument(ctx, tx, sp.DB, sp.Collection, doc); err != nil {
+					return err
+				}
+
+				return h.recordChanges(ctx, tx, &sp, pgdb.ChangeInsert, doc)
+			})
+			if err == nil {
+				inserted++
+				continue
+			}
+
+			if fatalErr := insertError(sp, err); fatalErr != nil {
+				return fatalErr
+			}
+
+			if errors.Is(err, pgdb.ErrUniqueViolation) {
+				err = duplicateKeyError(sp)
+			}
+
+			writeErrs.Append(err, int32(i))
+
+			if ordered {
+				break
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return inserted, writeErrs, nil
+}
+
+// insert prepares and executes actual INSERT request to Postgres in the given transaction.
+// If needed, the collection is created.
+func (h *Handler) insert(ctx context.Context, tx pgx.Tx, sp pgdb.SQLParam, doc any) error {
 	d, ok := doc.(*types.Document)
 	if !ok {
 		return common.NewErrorMsg(
@@ -98,16 +224,102 @@ func (h *Handler) insert(ctx context.Context, sp pgdb.SQLParam, doc any) error {
 		)
 	}
 
-	err := h.pgPool.InTransaction(ctx, func(tx pgx.Tx) error {
-		if err := pgdb.InsertDocument(ctx, tx, sp.DB, sp.Collection, d); err != nil {
-			if errors.Is(pgdb.ErrInvalidTableName, err) ||
-				errors.Is(pgdb.ErrInvalidDatabaseName, err) {
-				msg := fmt.Sprintf("Invalid namespace: %s.%s", sp.DB, sp.Collection)
-				return common.NewErrorMsg(common.ErrInvalidNamespace, msg)
-			}
-			return lazyerrors.Error(err)
+	if _, err := h.ensureCollection(ctx, tx, sp.DB, sp.Collection); err != nil {
+		if nsErr := insertError(sp, err); nsErr != nil {
+			return nsErr
+		}
+		return err
+	}
+
+	if err := pgdb.InsertDocument(ctx, tx, sp.DB, sp.Collection, d); err != nil {
+		if nsErr := insertError(sp, err); nsErr != nil {
+			return nsErr
+		}
+		if errors.Is(err, pgdb.ErrUniqueViolation) {
+			return duplicateKeyError(sp)
 		}
+		return lazyerrors.Error(err)
+	}
+
+	if err := h.recordChanges(ctx, tx, &sp, pgdb.ChangeInsert, d); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// insertError returns an error for errors that affect all documents, or nil.
+func insertError(sp pgdb.SQLParam, err error) error {
+	switch {
+	case errors.Is(err, pgdb.ErrInvalidTableName), errors.Is(err, pgdb.ErrInvalidDatabaseName):
+		msg := fmt.Sprintf("Invalid namespace: %s.%s", sp.DB, sp.Collection)
+		return common.NewErrorMsg(common.ErrInvalidNamespace, msg)
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded),
+		pgdb.IsRetryable(err), pgdb.IsRetryableWrite(err):
+		// the whole transaction should be aborted (and maybe retried)
+		return err
+	case pgdb.IsUndefinedObject(err):
+		// the table was dropped concurrently, and cached settings may be stale;
+		// abort the whole transaction so InTransactionRetry could re-read them
+		return err
+	default:
 		return nil
-	})
-	return err
+	}
+}
+
+// duplicateKeyError returns an error for a document with duplicate _id.
+func duplicateKeyError(sp pgdb.SQLParam) error {
+	msg := fmt.Sprintf("E11000 duplicate key error collection: %s.%s index: _id_", sp.DB, sp.Collection)
+	return common.NewWriteErrorMsg(common.ErrDuplicateKey, msg)
+}
+
+// retryableWriteError adds RetryableWriteError label to the error of the write command
+// that carried txnNumber (so it is a retryable write) if that error could be fixed by a client's retry.
+// Connection errors are converted with connectionError.
+// Other errors are returned as is.
+func retryableWriteError(document *types.Document, err error) error {
+	retryable := document.Has("txnNumber") && pgdb.IsRetryableWrite(err)
+
+	err = connectionError(err)
+	if !retryable {
+		return err
+	}
+
+	return common.WithErrorLabels(err, common.RetryableWriteErrorLabel)
+}
+
+// connectionError returns HostUnreachable error if the write failed because the connection
+// to PostgreSQL was lost (for example, because PostgreSQL was restarted),
+// so drivers could handle it like a network error and retry.
+// Writes are not retried by FerretDB itself because it is unknown whether they were committed.
+// Other errors are returned as is.
+func connectionError(err error) error {
+	if !pgdb.IsConnectionError(err) {
+		return err
+	}
+
+	return common.NewErrorMsg(common.ErrHostUnreachable, "connection to PostgreSQL was lost")
+}
+
+// inSavepoint runs f in a savepoint of the given transaction.
+// The savepoint is rolled back if f returns an error.
+func inSavepoint(ctx context.Context, tx pgx.Tx, f func(pgx.Tx) error) error {
+	savepoint, err := tx.Begin(ctx)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if err = f(savepoint); err != nil {
+		if rerr := savepoint.Rollback(ctx); rerr != nil {
+			return lazyerrors.Error(rerr)
+		}
+
+		return err
+	}
+
+	if err = savepoint.Commit(ctx); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
 }
@@ -47,7 +47,7 @@ func (h *Handler) MsgListDatabases(ctx context.Context, msg *wire.OpMsg) (*wire.
 	}
 
 	var databases *types.Array
-	err = h.pgPool.InTransaction(ctx, func(tx pgx.Tx) error {
+	err = h.pgPool.InReadTransactionRetry(ctx, func(tx pgx.Tx) error {
 		var databaseNames []string
 		var err error
 		databaseNames, err = pgdb.Databases(ctx, tx)
@@ -57,23 +57,11 @@ func (h *Handler) MsgListDatabases(ctx context.Context, msg *wire.OpMsg) (*wire.
 
 		databases = types.MakeArray(len(databaseNames))
 		for _, databaseName := range databaseNames {
-			tables, err := pgdb.Tables(ctx, tx, databaseName)
+			sizeOnDisk, err := pgdb.DatabaseSize(ctx, tx, databaseName)
 			if err != nil {
 				return lazyerrors.Error(err)
 			}
 
-			// iterate over result to collect sizes
-			var sizeOnDisk int64
-			for _, name := range tables {
-				var tableSize int64
-				fullName := pgx.Identifier{databaseName, name}.Sanitize()
-				err = tx.QueryRow(ctx, "SELECT pg_total_relation_size($1)", fullName).Scan(&tableSize)
-				if err != nil {
-					return lazyerrors.Error(err)
-				}
-				sizeOnDisk += tableSize
-			}
-
 			d := must.NotFail(types.NewDocument(
 				"name", databaseName,
 				"sizeOnDisk", sizeOnDisk,
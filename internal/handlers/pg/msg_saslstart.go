@@ -0,0 +1,43 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pg
+
+import (
+	"context"
+	"errors"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/pg/pgdb"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// MsgSaslStart implements HandlerInterface.
+//
+// Credentials are checked by PostgreSQL.
+func (h *Handler) MsgSaslStart(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	return common.MsgSaslStart(ctx, msg, func(ctx context.Context, username, password string) (bool, error) {
+		err := h.pgPool.CheckAuth(ctx, username, password)
+		if errors.Is(err, pgdb.ErrAuthenticationFailed) {
+			return false, nil
+		}
+
+		if err != nil {
+			return false, lazyerrors.Error(err)
+		}
+
+		return true, nil
+	})
+}
@@ -20,6 +20,9 @@ import (
 	"path/filepath"
 	"time"
 
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
@@ -56,6 +59,35 @@ func (h *Handler) MsgServerStatus(ctx context.Context, msg *wire.OpMsg) (*wire.O
 		return nil, lazyerrors.Error(err)
 	}
 
+	serverInfo, err := h.pgPool.ServerInfo(ctx)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	freeMonitoring, err := h.freeMonitoringState(ctx)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	poolStats := h.pgPool.Stats()
+
+	health := h.pgPool.Health()
+
+	queryStats := h.pgPool.QueryStats()
+
+	queries := types.MakeDocument(len(queryStats.Kinds))
+	for _, k := range queryStats.Kinds {
+		must.NoError(queries.Set(k.Kind, k.Count))
+	}
+
+	queryErrors := types.MakeDocument(len(queryStats.Errors))
+	classes := maps.Keys(queryStats.Errors)
+	slices.Sort(classes)
+
+	for _, class := range classes {
+		must.NoError(queryErrors.Set(class, queryStats.Errors[class]))
+	}
+
 	var reply wire.OpMsg
 	err = reply.SetSections(wire.OpMsgSection{
 		Documents: []*types.Document{must.NotFail(types.NewDocument(
@@ -75,8 +107,39 @@ func (h *Handler) MsgServerStatus(ctx context.Context, msg *wire.OpMsg) (*wire.O
 				"internalCollections", int32(0),
 				"internalViews", int32(0),
 			)),
+			"connections", common.Connections(ctx),
+			"drivers", common.Drivers(ctx),
+			"opcounters", common.Opcounters(ctx),
 			"freeMonitoring", must.NotFail(types.NewDocument(
-				"state", "disabled",
+				"state", freeMonitoring,
+			)),
+			"metrics", must.NotFail(types.NewDocument(
+				"transactionRetries", h.pgPool.TransactionRetries(),
+				"cursor", common.CursorMetrics(h.cursors),
+			)),
+			"ferretdb", must.NotFail(types.NewDocument(
+				"build", common.FerretDBBuild(),
+				"postgresql", must.NotFail(types.NewDocument(
+					"version", serverInfo.Version,
+					"versionNum", int64(serverInfo.VersionNum),
+				)),
+				"pool", must.NotFail(types.NewDocument(
+					"acquired", poolStats.AcquiredConns,
+					"idle", poolStats.IdleConns,
+					"total", poolStats.TotalConns,
+					"max", poolStats.MaxConns,
+					"acquireCount", poolStats.AcquireCount,
+					"emptyAcquireCount", poolStats.EmptyAcquireCount,
+					"canceledAcquireCount", poolStats.CanceledAcquireCount,
+					"acquireWaitMillis", poolStats.AcquireDuration.Milliseconds(),
+				)),
+				"health", must.NotFail(types.NewDocument(
+					"ready", health.Ready,
+					"failures", health.Failures,
+					"connectionLosses", health.ConnectionLosses,
+				)),
+				"queries", queries,
+				"queryErrors", queryErrors,
 			)),
 			"ok", float64(1),
 		))},
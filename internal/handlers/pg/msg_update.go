@@ -63,10 +63,10 @@ func (h *Handler) MsgUpdate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		return nil, err
 	}
 
-	created, err := pgdb.CreateCollectionIfNotExist(ctx, h.pgPool, sp.DB, sp.Collection)
+	created, err := h.ensureCollection(ctx, h.pgPool, sp.DB, sp.Collection)
 	if err != nil {
-		if errors.Is(pgdb.ErrInvalidTableName, err) ||
-			errors.Is(pgdb.ErrInvalidDatabaseName, err) {
+		if errors.Is(err, pgdb.ErrInvalidTableName) ||
+			errors.Is(err, pgdb.ErrInvalidDatabaseName) {
 			msg := fmt.Sprintf("Invalid namespace: %s.%s", sp.DB, sp.Collection)
 			return nil, common.NewErrorMsg(common.ErrInvalidNamespace, msg)
 		}
@@ -95,8 +95,6 @@ func (h *Handler) MsgUpdate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		}
 
 		var q, u *types.Document
-		var upsert bool
-		var multi bool
 		if q, err = common.GetOptionalParam(update, "q", q); err != nil {
 			return nil, err
 		}
@@ -115,57 +113,82 @@ func (h *Handler) MsgUpdate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 			return nil, err
 		}
 
+		var replace bool
 		if u != nil {
 			if err = common.ValidateUpdateOperators(u); err != nil {
 				return nil, err
 			}
+
+			var hasUpdateOperators bool
+			if hasUpdateOperators, err = common.HasSupportedUpdateModifiers(u); err != nil {
+				return nil, err
+			}
+
+			replace = !hasUpdateOperators
 		}
 
-		if upsert, err = common.GetOptionalParam(update, "upsert", upsert); err != nil {
+		upsert, err := common.GetBoolOptionalParam(update, "upsert")
+		if err != nil {
 			return nil, err
 		}
 
-		if multi, err = common.GetOptionalParam(update, "multi", multi); err != nil {
+		multi, err := common.GetBoolOptionalParam(update, "multi")
+		if err != nil {
 			return nil, err
 		}
 
-		resDocs := make([]*types.Document, 0, 16)
-		err = h.pgPool.InTransaction(ctx, func(tx pgx.Tx) error {
-			fetchedChan, err := h.pgPool.QueryDocuments(ctx, tx, sp)
-			if err != nil {
-				return err
-			}
-			defer func() {
-				// Drain the channel to prevent leaking goroutines.
-				// TODO Offer a better design instead of channels: https://github.com/FerretDB/FerretDB/issues/898.
-				for range fetchedChan {
+		sp.Filter = q
+
+		var resDocs []*types.Document
+		var n, nModified int64
+		var pushdown, inserted bool
+		err = h.pgPool.InTransactionRetry(ctx, func(tx pgx.Tx) error {
+			resDocs = make([]*types.Document, 0, 16)
+
+			// simple upserts by _id are handled by a single INSERT ... ON CONFLICT statement;
+			// pushdowns are not used when changes are recorded, as recording needs the resulting documents
+			if upsert && !h.changeStreams {
+				inserted, nModified, pushdown, err = pgdb.UpsertDocumentByID(ctx, tx, &sp, u)
+				if err != nil || pushdown {
+					n = 1
+					return err
 				}
-			}()
+			}
 
-			for fetchedItem := range fetchedChan {
-				if fetchedItem.Err != nil {
-					return fetchedItem.Err
+			// simple updates are handled by a single UPDATE statement
+			if !h.changeStreams {
+				n, nModified, pushdown, err = pgdb.UpdateDocumentsByFilter(ctx, tx, &sp, u, multi)
+				if err != nil || pushdown {
+					return err
 				}
+			}
 
-				for _, doc := range fetchedItem.Docs {
-					matches, err := common.FilterDocument(doc, q)
-					if err != nil {
-						return err
-					}
-
-					if !matches {
-						continue
-					}
-
-					resDocs = append(resDocs, doc)
-				}
+			iter, _, err := pgdb.QueryIterator(ctx, tx, sp)
+			if err != nil {
+				return err
 			}
+			defer iter.Close()
 
-			return nil
+			resDocs, err = fetchMatching(ctx, iter, q, 0)
+			return err
 		})
 
 		if err != nil {
-			return nil, err
+			return nil, retryableWriteError(document, err)
+		}
+
+		if pushdown && inserted {
+			must.NoError(upserted.Append(must.NotFail(types.NewDocument(
+				"index", int32(i),
+				"_id", must.NotFail(q.Get("_id")),
+			))))
+		}
+
+		if pushdown && n > 0 {
+			opStats(ctx).Pushdown = true
+			matched += int32(n)
+			modified += int32(nModified)
+			continue
 		}
 
 		if len(resDocs) == 0 {
@@ -183,12 +206,15 @@ func (h *Handler) MsgUpdate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 			}
 
 			must.NoError(upserted.Append(must.NotFail(types.NewDocument(
-				"index", int32(0), // TODO
+				"index", int32(i),
 				"_id", must.NotFail(doc.Get("_id")),
 			))))
 
-			if err = h.insert(ctx, sp, doc); err != nil {
-				return nil, err
+			err = h.pgPool.InTransactionRetry(ctx, func(tx pgx.Tx) error {
+				return h.insert(ctx, tx, sp, doc)
+			})
+			if err != nil {
+				return nil, retryableWriteError(document, err)
 			}
 
 			matched++
@@ -211,14 +237,20 @@ func (h *Handler) MsgUpdate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 				continue
 			}
 
-			rowsChanged, err := h.update(ctx, &sp, doc)
+			var rowsChanged int64
+			err = h.pgPool.InTransactionRetry(ctx, func(tx pgx.Tx) error {
+				rowsChanged, err = h.update(ctx, tx, &sp, doc, replace)
+				return err
+			})
 			if err != nil {
-				return nil, err
+				return nil, retryableWriteError(document, err)
 			}
 			modified += int32(rowsChanged)
 		}
 	}
 
+	opStats(ctx).DocsReturned = int64(modified)
+
 	res := must.NotFail(types.NewDocument(
 		"n", matched,
 	))
@@ -240,12 +272,27 @@ func (h *Handler) MsgUpdate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 }
 
 // update updates documents by _id.
-func (h *Handler) update(ctx context.Context, sp *pgdb.SQLParam, doc *types.Document) (int64, error) {
+//
+// Replace should be true if doc replaces the whole document instead of being produced by update operators;
+// it is used only for recorded changes.
+func (h *Handler) update(ctx context.Context, tx pgx.Tx, sp *pgdb.SQLParam, doc *types.Document, replace bool) (int64, error) { //nolint:lll // argument list is too long
 	id := must.NotFail(doc.Get("_id"))
 
-	rowsUpdated, err := h.pgPool.SetDocumentByID(ctx, sp, id, doc)
+	rowsUpdated, err := pgdb.SetDocumentByID(ctx, tx, sp, id, doc)
 	if err != nil {
 		return 0, err
 	}
+
+	if rowsUpdated > 0 {
+		op := pgdb.ChangeUpdate
+		if replace {
+			op = pgdb.ChangeReplace
+		}
+
+		if err = h.recordChanges(ctx, tx, sp, op, doc); err != nil {
+			return 0, lazyerrors.Error(err)
+		}
+	}
+
 	return rowsUpdated, nil
 }
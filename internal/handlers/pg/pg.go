@@ -16,44 +16,177 @@
 package pg
 
 import (
+	"context"
 	"time"
 
+	"github.com/jackc/pgtype/pgxtype"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 
+	"github.com/FerretDB/FerretDB/internal/cursor"
 	"github.com/FerretDB/FerretDB/internal/handlers"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
 	"github.com/FerretDB/FerretDB/internal/handlers/pg/pgdb"
+	"github.com/FerretDB/FerretDB/internal/util/debug"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 )
 
 // Handler implements handlers.Interface on top of PostgreSQL.
 type Handler struct {
 	// TODO replace those fields with embedded *NewOpts to sync with Tigris handler
-	pgPool    *pgdb.Pool
-	l         *zap.Logger
-	startTime time.Time
+	pgPool            *pgdb.Pool
+	l                 *zap.Logger
+	startTime         time.Time
+	disableJSONBIndex bool
+
+	cursors *cursor.Registry
+
+	changeStreams       bool
+	changeStreamCursors *changeStreamCursors
+	stopChangesTrimming context.CancelFunc
+	changesTrimmingDone chan struct{}
 }
 
 // NewOpts represents handler configuration.
 type NewOpts struct {
 	PgPool *pgdb.Pool
 	L      *zap.Logger
+
+	// DisableJSONBIndex disables creation of GIN index on _jsonb column for new collections.
+	DisableJSONBIndex bool
+
+	// ChangeStreams enables recording of changes and $changeStream aggregation stage.
+	// Concurrent writes to the same database are serialized when enabled.
+	ChangeStreams bool
+
+	// ChangeStreamsRetention is the time recorded changes are kept for.
+	// Zero means pgdb.DefaultChangesRetention.
+	ChangeStreamsRetention time.Duration
 }
 
 // New returns a new handler.
 func New(opts *NewOpts) (handlers.Interface, error) {
 	h := &Handler{
-		pgPool:    opts.PgPool,
-		l:         opts.L,
-		startTime: time.Now(),
+		pgPool:              opts.PgPool,
+		l:                   opts.L,
+		startTime:           time.Now(),
+		disableJSONBIndex:   opts.DisableJSONBIndex,
+		cursors:             cursor.NewRegistry(),
+		changeStreams:       opts.ChangeStreams,
+		changeStreamCursors: newChangeStreamCursors(),
+	}
+
+	if h.changeStreams {
+		retention := opts.ChangeStreamsRetention
+		if retention == 0 {
+			retention = pgdb.DefaultChangesRetention
+		}
+
+		var ctx context.Context
+		ctx, h.stopChangesTrimming = context.WithCancel(context.Background())
+		h.changesTrimmingDone = make(chan struct{})
+
+		go func() {
+			defer close(h.changesTrimmingDone)
+			h.trimChanges(ctx, retention)
+		}()
 	}
+
 	return h, nil
 }
 
+// ensureCollection creates FerretDB database and collection if they do not exist
+// using handler's options for new collections.
+//
+// It is safe to call it concurrently for the same collection.
+// True is returned if collection was created.
+func (h *Handler) ensureCollection(ctx context.Context, querier pgxtype.Querier, db, collection string) (bool, error) {
+	opts := &pgdb.CreateCollectionOpts{
+		DisableJSONBIndex: h.disableJSONBIndex,
+	}
+
+	created, err := pgdb.CreateCollectionIfNotExistWithOpts(ctx, querier, db, collection, opts)
+	if err != nil {
+		return false, lazyerrors.Error(err)
+	}
+
+	return created, nil
+}
+
+// Describe implements prometheus.Collector.
+func (h *Handler) Describe(ch chan<- *prometheus.Desc) {
+	h.pgPool.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (h *Handler) Collect(ch chan<- prometheus.Metric) {
+	h.pgPool.Collect(ch)
+}
+
+// handlerState represents the handler state reported on the debug endpoint.
+type handlerState struct {
+	Pool                poolState            `json:"pool"`
+	Health              healthState          `json:"health"`
+	Cursors             []common.CursorState `json:"cursors"`
+	ChangeStreamCursors int                  `json:"changeStreamCursors"`
+}
+
+// poolState represents PostgreSQL connection pool state.
+type poolState struct {
+	Acquired          int32 `json:"acquired"`
+	Idle              int32 `json:"idle"`
+	Total             int32 `json:"total"`
+	Max               int32 `json:"max"`
+	AcquireWaitMillis int64 `json:"acquireWaitMillis"`
+}
+
+// healthState represents PostgreSQL availability state.
+type healthState struct {
+	Ready            bool      `json:"ready"`
+	LastCheck        time.Time `json:"lastCheck"`
+	LastError        string    `json:"lastError,omitempty"`
+	Failures         int64     `json:"failures"`
+	ConnectionLosses int64     `json:"connectionLosses"`
+}
+
+// DebugState implements debug.StateProvider.
+func (h *Handler) DebugState() any {
+	s := h.pgPool.Stats()
+	health := h.pgPool.Health()
+
+	return &handlerState{
+		Pool: poolState{
+			Acquired:          s.AcquiredConns,
+			Idle:              s.IdleConns,
+			Total:             s.TotalConns,
+			Max:               s.MaxConns,
+			AcquireWaitMillis: s.AcquireDuration.Milliseconds(),
+		},
+		Health: healthState{
+			Ready:            health.Ready,
+			LastCheck:        health.LastCheck,
+			LastError:        health.LastError,
+			Failures:         health.Failures,
+			ConnectionLosses: health.ConnectionLosses,
+		},
+		Cursors:             common.CursorsState(h.cursors),
+		ChangeStreamCursors: h.changeStreamCursors.len(),
+	}
+}
+
 // Close implements HandlerInterface.
 func (h *Handler) Close() {
+	if h.stopChangesTrimming != nil {
+		h.stopChangesTrimming()
+		<-h.changesTrimmingDone
+	}
+
+	h.cursors.Close()
 	h.pgPool.Close()
 }
 
 // check interfaces
 var (
-	_ handlers.Interface = (*Handler)(nil)
+	_ handlers.Interface  = (*Handler)(nil)
+	_ debug.StateProvider = (*Handler)(nil)
 )
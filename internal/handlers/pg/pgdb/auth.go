@@ -0,0 +1,53 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v4"
+
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// CheckAuth checks that PostgreSQL accepts given credentials
+// by opening a separate connection with the pool's settings.
+//
+// It returns ErrAuthenticationFailed if credentials are rejected.
+func (pgPool *Pool) CheckAuth(ctx context.Context, username, password string) error {
+	config := pgPool.Config().ConnConfig
+	config.User = username
+	config.Password = password
+
+	conn, err := pgx.ConnectConfig(ctx, config)
+	if err == nil {
+		return conn.Close(ctx)
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return lazyerrors.Error(err)
+	}
+
+	switch pgErr.Code {
+	case pgerrcode.InvalidPassword, pgerrcode.InvalidAuthorizationSpecification:
+		return ErrAuthenticationFailed
+	default:
+		return lazyerrors.Error(err)
+	}
+}
@@ -17,13 +17,13 @@ package pgdb
 import (
 	"context"
 	"errors"
-	"regexp"
-	"strings"
 
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgtype/pgxtype"
 	"github.com/jackc/pgx/v4"
+	"go.uber.org/zap"
+	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
 
 	"github.com/FerretDB/FerretDB/internal/types"
@@ -31,46 +31,77 @@ import (
 	"github.com/FerretDB/FerretDB/internal/util/must"
 )
 
-var (
-	// Regex validateCollectionNameRe validates collection names.
-	validateCollectionNameRe = regexp.MustCompile("^[a-zA-Z_][a-zA-Z0-9_]{0,119}$")
-
-	// Regex validateDatabaseNameRe validates database names.
-	validateDatabaseNameRe = regexp.MustCompile("^[a-z_][a-z0-9_]{0,62}$")
-)
-
 // Collections returns a sorted list of FerretDB collection names.
 //
+// Only collections from FerretDB settings mapping that are stored in plain tables with _jsonb column are returned.
+// Other relations in the schema (views, foreign tables, user tables, etc.) are ignored;
+// mapped collections stored in such relations are skipped and logged once per Pool.
+//
 // It returns (possibly wrapped) ErrSchemaNotExist if FerretDB database / PostgreSQL schema does not exist.
 func Collections(ctx context.Context, querier pgxtype.Querier, db string) ([]string, error) {
-	schemaExists, err := schemaExists(ctx, querier, db)
+	settings, err := getCachedSettings(ctx, querier, db)
 	if err != nil {
-		return nil, lazyerrors.Error(err)
+		return nil, err
 	}
 
-	if !schemaExists {
-		return nil, ErrSchemaNotExist
+	collectionsDoc := must.NotFail(settings.Get("collections"))
+
+	collections, ok := collectionsDoc.(*types.Document)
+	if !ok {
+		return nil, lazyerrors.Errorf("invalid settings document: %v", collectionsDoc)
 	}
 
-	settings, err := getSettingsTable(ctx, querier, db)
+	relations, err := schemaRelations(ctx, querier, db)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
-	collectionsDoc := must.NotFail(settings.Get("collections"))
+	names := make([]string, 0, collections.Len())
+	for _, name := range collections.Keys() {
+		table, ok := must.NotFail(collections.Get(name)).(string)
+		if !ok {
+			return nil, lazyerrors.Errorf("invalid table name for collection %q", name)
+		}
 
-	collections, ok := collectionsDoc.(*types.Document)
-	if !ok {
-		return nil, lazyerrors.Errorf("invalid settings document: %v", collectionsDoc)
+		// the mapping could exist without a table, for example, if it was added by getTableName
+		rel, ok := relations[table]
+		if !ok {
+			continue
+		}
+
+		if !rel.collection {
+			poolFor(querier).reportInvalidRelation(db, table, name)
+			continue
+		}
+
+		names = append(names, name)
 	}
 
 	// TODO sort collections on update
-	names := collections.Keys()
 	slices.Sort(names)
 
 	return names, nil
 }
 
+// reportInvalidRelation logs once that FerretDB collection is mapped to a relation
+// that can't store it (view, foreign table, table without _jsonb column, etc.).
+//
+// Nothing is logged for nil pgPool (for queriers not created by Pool).
+func (pgPool *Pool) reportInvalidRelation(db, table, collection string) {
+	if pgPool == nil {
+		return
+	}
+
+	if _, loaded := pgPool.reportedRelations.LoadOrStore(db+"."+table, struct{}{}); loaded {
+		return
+	}
+
+	pgPool.logger.Warn(
+		"Relation is not a FerretDB table, collection is ignored.",
+		zap.String("schema", db), zap.String("relation", table), zap.String("collection", collection),
+	)
+}
+
 // CollectionExists returns true if FerretDB collection exists.
 func CollectionExists(ctx context.Context, querier pgxtype.Querier, db, collection string) (bool, error) {
 	collections, err := Collections(ctx, querier, db)
@@ -85,6 +116,7 @@ func CollectionExists(ctx context.Context, querier pgxtype.Querier, db, collecti
 }
 
 // CreateCollection creates a new FerretDB collection in existing schema.
+// GIN index on _jsonb column is created too; use CreateCollectionWithOpts to disable that.
 //
 // It returns a possibly wrapped error:
 //   - ErrInvalidTableName - if a FerretDB collection name doesn't conform to restrictions.
@@ -93,71 +125,122 @@ func CollectionExists(ctx context.Context, querier pgxtype.Querier, db, collecti
 //
 // Please use errors.Is to check the error.
 func CreateCollection(ctx context.Context, querier pgxtype.Querier, db, collection string) error {
-	if !validateCollectionNameRe.MatchString(collection) ||
-		strings.HasPrefix(collection, reservedPrefix) {
-		return ErrInvalidTableName
-	}
+	return CreateCollectionWithOpts(ctx, querier, db, collection, nil)
+}
 
-	schemaExists, err := schemaExists(ctx, querier, db)
-	if err != nil {
-		return lazyerrors.Error(err)
-	}
+// CreateCollectionOpts represents options for CreateCollectionWithOpts.
+type CreateCollectionOpts struct {
+	// DisableJSONBIndex disables creation of GIN index on _jsonb column.
+	DisableJSONBIndex bool
+}
 
-	if !schemaExists {
-		return ErrSchemaNotExist
+// CreateCollectionWithOpts creates a new FerretDB collection in existing schema with given options.
+// Nil opts are the same as zero value opts.
+//
+// It returns the same errors as CreateCollection.
+func CreateCollectionWithOpts(ctx context.Context, querier pgxtype.Querier, db, collection string, opts *CreateCollectionOpts) error { //nolint:lll // argument list is too long
+	if opts == nil {
+		opts = new(CreateCollectionOpts)
 	}
 
-	table := formatCollectionName(collection)
-	tables, err := tables(ctx, querier, db)
-	if err != nil {
+	if err := validateCollectionName(db, collection); err != nil {
 		return err
 	}
-	if slices.Contains(tables, table) {
-		return ErrAlreadyExist
-	}
 
-	settings, err := getSettingsTable(ctx, querier, db)
-	if err != nil {
-		return lazyerrors.Error(err)
-	}
+	return InTransaction(ctx, querier, func(tx pgx.Tx) error {
+		if err := lockCollection(ctx, tx, db, collection); err != nil {
+			return err
+		}
 
-	collectionsDoc := must.NotFail(settings.Get("collections"))
-	collections, ok := collectionsDoc.(*types.Document)
-	if !ok {
-		return lazyerrors.Errorf("expected document but got %[1]T: %[1]v", collectionsDoc)
-	}
+		schemaExists, err := schemaExists(ctx, tx, db)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
 
-	if collections.Has(collection) {
-		return nil
-	}
+		if !schemaExists {
+			return ErrSchemaNotExist
+		}
 
-	// TODO keep "collections" sorted after each update
-	must.NoError(collections.Set(collection, table))
-	must.NoError(settings.Set("collections", collections))
+		relations, err := schemaRelations(ctx, tx, db)
+		if err != nil {
+			return err
+		}
 
-	err = updateSettingsTable(ctx, querier, db, settings)
-	if err != nil {
-		return lazyerrors.Error(err)
-	}
+		settings, err := getSettingsTableForUpdate(ctx, tx, db)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
 
-	sql := `CREATE TABLE IF NOT EXISTS ` + pgx.Identifier{db, table}.Sanitize() + ` (_jsonb jsonb)`
-	if _, err = querier.Exec(ctx, sql); err == nil {
-		return nil
-	}
+		collectionsDoc := must.NotFail(settings.Get("collections"))
+		collections, ok := collectionsDoc.(*types.Document)
+		if !ok {
+			return lazyerrors.Errorf("expected document but got %[1]T: %[1]v", collectionsDoc)
+		}
 
-	var pgErr *pgconn.PgError
-	if !errors.As(err, &pgErr) {
-		return lazyerrors.Error(err)
-	}
+		// the mapping could exist without a table, for example, if it was added by getTableName
+		var table string
+		if collections.Has(collection) {
+			if table, ok = must.NotFail(collections.Get(collection)).(string); !ok {
+				return lazyerrors.Errorf("invalid table name for collection %q", collection)
+			}
+
+			if rel, exists := relations[table]; exists {
+				if rel.collection {
+					return ErrAlreadyExist
+				}
+
+				// the name is taken by some other relation, so use a new one
+				table = ""
+			}
+		}
 
-	switch pgErr.Code {
-	case pgerrcode.UniqueViolation, pgerrcode.DuplicateObject, pgerrcode.DuplicateTable:
-		// https://www.postgresql.org/message-id/CA+TgmoZAdYVtwBfp1FL2sMZbiHCWT4UPrzRLNnX1Nb30Ku3-gg@mail.gmail.com
-		// Reproducible by integration tests.
-		return ErrAlreadyExist
-	default:
-		return lazyerrors.Error(err)
-	}
+		if table == "" {
+			table = newTableName(collection, collections, maps.Keys(relations))
+
+			// TODO keep "collections" sorted after each update
+			must.NoError(collections.Set(collection, table))
+			must.NoError(settings.Set("collections", collections))
+
+			err = updateSettingsTable(ctx, tx, db, settings)
+			if err != nil {
+				return lazyerrors.Error(err)
+			}
+		}
+
+		sql := `CREATE TABLE IF NOT EXISTS ` + pgx.Identifier{db, table}.Sanitize() + ` (_jsonb jsonb)`
+		if _, err = tx.Exec(ctx, sql); err == nil {
+			// a new empty table, so there is no need for CONCURRENTLY there
+			sql = `CREATE UNIQUE INDEX IF NOT EXISTS ` + pgx.Identifier{idIndexName(table)}.Sanitize() +
+				` ON ` + pgx.Identifier{db, table}.Sanitize() + ` ((_jsonb->'_id'))`
+			_, err = tx.Exec(ctx, sql)
+		}
+
+		if err == nil {
+			if opts.DisableJSONBIndex {
+				return nil
+			}
+
+			sql = `CREATE INDEX IF NOT EXISTS ` + pgx.Identifier{jsonbIndexName(table)}.Sanitize() +
+				` ON ` + pgx.Identifier{db, table}.Sanitize() + ` USING gin (_jsonb jsonb_path_ops)`
+			if _, err = tx.Exec(ctx, sql); err == nil {
+				return nil
+			}
+		}
+
+		var pgErr *pgconn.PgError
+		if !errors.As(err, &pgErr) {
+			return lazyerrors.Error(err)
+		}
+
+		switch pgErr.Code {
+		case pgerrcode.UniqueViolation, pgerrcode.DuplicateObject, pgerrcode.DuplicateTable:
+			// https://www.postgresql.org/message-id/CA+TgmoZAdYVtwBfp1FL2sMZbiHCWT4UPrzRLNnX1Nb30Ku3-gg@mail.gmail.com
+			// Reproducible by integration tests.
+			return ErrAlreadyExist
+		default:
+			return lazyerrors.Error(err)
+		}
+	})
 }
 
 // CreateCollectionIfNotExist ensures that given FerretDB database / PostgreSQL schema
@@ -166,6 +249,19 @@ func CreateCollection(ctx context.Context, querier pgxtype.Querier, db, collecti
 //
 // True is returned if table was created.
 func CreateCollectionIfNotExist(ctx context.Context, querier pgxtype.Querier, db, collection string) (bool, error) {
+	return CreateCollectionIfNotExistWithOpts(ctx, querier, db, collection, nil)
+}
+
+// CreateCollectionIfNotExistWithOpts is CreateCollectionIfNotExist with options for a new collection.
+//
+// It is safe to call it concurrently for the same collection from different clients
+// (and different FerretDB instances): creation is serialized with an advisory lock,
+// and the collection created by a concurrent client is not an error.
+func CreateCollectionIfNotExistWithOpts(ctx context.Context, querier pgxtype.Querier, db, collection string, opts *CreateCollectionOpts) (bool, error) { //nolint:lll // argument list is too long
+	if err := validateCollectionName(db, collection); err != nil {
+		return false, err
+	}
+
 	exists, err := CollectionExists(ctx, querier, db, collection)
 	if err != nil {
 		return false, lazyerrors.Error(err)
@@ -175,23 +271,45 @@ func CreateCollectionIfNotExist(ctx context.Context, querier pgxtype.Querier, db
 		return false, nil
 	}
 
-	// Table (or even schema) does not exist. Try to create it,
-	// but keep in mind that it can be created in concurrent connection.
+	var created bool
+	err = InTransaction(ctx, querier, func(tx pgx.Tx) error {
+		created = false
 
-	if err := CreateDatabase(ctx, querier, db); err != nil && !errors.Is(err, ErrAlreadyExist) {
-		return false, lazyerrors.Error(err)
-	}
+		if err := lockCollection(ctx, tx, db, collection); err != nil {
+			return err
+		}
 
-	// TODO use a transaction instead of pgPool: https://github.com/FerretDB/FerretDB/issues/866
-	if err := CreateCollection(ctx, querier, db, collection); err != nil {
-		if errors.Is(err, ErrAlreadyExist) {
-			return false, nil
+		// re-check after acquiring the lock: collection might be created by a concurrent client
+		exists, err := CollectionExists(ctx, tx, db, collection)
+		if err != nil {
+			return lazyerrors.Error(err)
 		}
 
-		return false, lazyerrors.Error(err)
+		if exists {
+			return nil
+		}
+
+		if err := CreateDatabaseIfNotExists(ctx, tx, db); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if err := CreateCollectionWithOpts(ctx, tx, db, collection, opts); err != nil {
+			if errors.Is(err, ErrAlreadyExist) {
+				return nil
+			}
+
+			return lazyerrors.Error(err)
+		}
+
+		created = true
+
+		return nil
+	})
+	if err != nil {
+		return false, err
 	}
 
-	return true, nil
+	return created, nil
 }
 
 // DropCollection drops FerretDB collection.
@@ -208,29 +326,37 @@ func DropCollection(ctx context.Context, querier pgxtype.Querier, schema, collec
 		return ErrSchemaNotExist
 	}
 
-	table := formatCollectionName(collection)
-	tables, err := tables(ctx, querier, schema)
-	if err != nil {
-		return lazyerrors.Error(err)
-	}
-	if !slices.Contains(tables, table) {
-		return ErrTableNotExist
-	}
+	return InTransaction(ctx, querier, func(tx pgx.Tx) error {
+		if err := lockCollection(ctx, tx, schema, collection); err != nil {
+			return err
+		}
 
-	err = removeTableFromSettings(ctx, querier, schema, collection)
-	if err != nil && !errors.Is(err, ErrTableNotExist) {
-		return lazyerrors.Error(err)
-	}
-	if errors.Is(err, ErrTableNotExist) {
-		return ErrTableNotExist
-	}
+		table, err := removeTableFromSettings(ctx, tx, schema, collection)
+		if err != nil && !errors.Is(err, ErrTableNotExist) {
+			return lazyerrors.Error(err)
+		}
+		if errors.Is(err, ErrTableNotExist) {
+			return ErrTableNotExist
+		}
 
-	// TODO https://github.com/FerretDB/FerretDB/issues/811
-	sql := `DROP TABLE IF EXISTS ` + pgx.Identifier{schema, table}.Sanitize() + ` CASCADE`
-	_, err = querier.Exec(ctx, sql)
-	if err != nil {
-		return lazyerrors.Error(err)
-	}
+		// other relations are not dropped even if they are mapped
+		relations, err := schemaRelations(ctx, tx, schema)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+		if !relations[table].collection {
+			return ErrTableNotExist
+		}
 
-	return nil
+		// TODO https://github.com/FerretDB/FerretDB/issues/811
+		sql := `DROP TABLE IF EXISTS ` + pgx.Identifier{schema, table}.Sanitize() + ` CASCADE`
+		_, err = tx.Exec(ctx, sql)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		knownIDIndexes.Delete([2]string{schema, table})
+
+		return nil
+	})
 }
@@ -0,0 +1,170 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestCollectionsForeignRelations(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	core, logs := observer.New(zap.WarnLevel)
+	pool := getPool(ctx, t, zaptest.NewLogger(t, zaptest.WrapOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(c, core)
+	}))))
+	dbName := testutil.DatabaseName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(t, CreateDatabase(ctx, pool, dbName))
+	require.NoError(t, CreateCollection(ctx, pool, dbName, "foo"))
+	require.NoError(t, CreateCollection(ctx, pool, dbName, "bar"))
+
+	fooTable, err := getTableName(ctx, pool, dbName, "foo")
+	require.NoError(t, err)
+	barTable, err := getTableName(ctx, pool, dbName, "bar")
+	require.NoError(t, err)
+
+	// objects created by users or extensions
+	for _, sql := range []string{
+		`CREATE VIEW ` + pgx.Identifier{dbName, "view"}.Sanitize() +
+			` AS SELECT _jsonb FROM ` + pgx.Identifier{dbName, fooTable}.Sanitize(),
+		`CREATE TABLE ` + pgx.Identifier{dbName, "plain"}.Sanitize() + ` (a int)`,
+
+		// replace the table of "bar" collection with a view that has the same columns
+		`DROP TABLE ` + pgx.Identifier{dbName, barTable}.Sanitize(),
+		`CREATE VIEW ` + pgx.Identifier{dbName, barTable}.Sanitize() +
+			` AS SELECT _jsonb FROM ` + pgx.Identifier{dbName, fooTable}.Sanitize(),
+	} {
+		_, err = pool.Exec(ctx, sql)
+		require.NoError(t, err)
+	}
+
+	collections, err := Collections(ctx, pool, dbName)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"foo"}, collections)
+
+	exists, err := CollectionExists(ctx, pool, dbName, "bar")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// the view is reported once by the pool's logger
+	assert.Equal(t, 1, logs.FilterMessage("Relation is not a FerretDB table, collection is ignored.").Len())
+
+	err = pool.InTransaction(ctx, func(tx pgx.Tx) error {
+		iter, _, err := QueryIterator(ctx, tx, SQLParam{DB: dbName, Collection: "bar"})
+		require.NoError(t, err)
+		defer iter.Close()
+
+		_, err = iter.Next(ctx)
+		assert.Equal(t, ErrIteratorDone, err)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	// the view is not dropped
+	err = DropCollection(ctx, pool, dbName, "bar")
+	assert.ErrorIs(t, err, ErrTableNotExist)
+
+	relations, err := schemaRelations(ctx, pool, dbName)
+	require.NoError(t, err)
+	assert.Contains(t, relations, barTable)
+	assert.Contains(t, relations, "view")
+	assert.Contains(t, relations, "plain")
+	assert.False(t, relations["view"].collection)
+	assert.False(t, relations["plain"].collection)
+
+	// collection could be created again in a new table
+	require.NoError(t, CreateCollection(ctx, pool, dbName, "bar"))
+
+	newBarTable, err := getTableName(ctx, pool, dbName, "bar")
+	require.NoError(t, err)
+	assert.NotEqual(t, barTable, newBarTable)
+
+	collections, err = Collections(ctx, pool, dbName)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bar", "foo"}, collections)
+
+	// extra objects do not prevent dropping the database
+	require.NoError(t, DropDatabase(ctx, pool, dbName))
+}
+
+func TestCollectionNames(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(t, CreateDatabase(ctx, pool, dbName))
+
+	// the longest valid name for that database, with multi-byte characters
+	long := strings.Repeat("я", (maxNamespaceLength-len(dbName)-1)/2)
+
+	names := []string{"a.b.c", "a", "a.b", "коллекция", long}
+	for _, name := range names {
+		require.NoError(t, CreateCollection(ctx, pool, dbName, name), "%q", name)
+		require.NoError(t, InsertDocument(ctx, pool, dbName, name, must.NotFail(types.NewDocument("_id", name))))
+	}
+
+	// implicit creation uses the same validation
+	_, err := CreateCollectionIfNotExist(ctx, pool, dbName, long+"я")
+	require.ErrorIs(t, err, ErrInvalidTableName)
+	_, err = CreateCollectionIfNotExist(ctx, pool, dbName, "system.test")
+	require.ErrorIs(t, err, ErrInvalidTableName)
+
+	collections, err := Collections(ctx, pool, dbName)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, names, collections)
+
+	// each collection is stored in its own table
+	for _, name := range names {
+		n, err := CountDocuments(ctx, pool, dbName, name, false)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n, "%q", name)
+	}
+
+	require.NoError(t, DropCollection(ctx, pool, dbName, "a.b"))
+
+	collections, err = Collections(ctx, pool, dbName)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"a.b.c", "a", "коллекция", long}, collections)
+}
@@ -0,0 +1,69 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"context"
+
+	"github.com/jackc/pgtype/pgxtype"
+	"github.com/jackc/pgx/v4"
+
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// CountDocuments returns the number of documents in the given FerretDB database and collection.
+//
+// If estimated is true, PostgreSQL planner statistics (pg_class.reltuples) are used instead of an exact count;
+// if statistics are not collected yet, an exact count is returned anyway.
+//
+// It returns 0 and no error if database or collection does not exist.
+func CountDocuments(ctx context.Context, querier pgxtype.Querier, db, collection string, estimated bool) (int64, error) {
+	exists, err := CollectionExists(ctx, querier, db, collection)
+	if err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+	if !exists {
+		return 0, nil
+	}
+
+	table, err := getTableName(ctx, querier, db, collection)
+	if err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	if estimated {
+		var n int64
+		sql := `SELECT c.reltuples::bigint ` +
+			`FROM pg_catalog.pg_class AS c ` +
+			`JOIN pg_catalog.pg_namespace AS n ON n.oid = c.relnamespace ` +
+			`WHERE n.nspname = $1 AND c.relname = $2`
+		if err = querier.QueryRow(ctx, sql, db, table).Scan(&n); err != nil {
+			return 0, lazyerrors.Error(err)
+		}
+
+		// reltuples is -1 (PostgreSQL 14+) or 0 (older versions) for tables that were never analyzed
+		if n > 0 {
+			return n, nil
+		}
+	}
+
+	var n int64
+	sql := `SELECT COUNT(*) FROM ` + pgx.Identifier{db, table}.Sanitize()
+	if err = querier.QueryRow(ctx, sql).Scan(&n); err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	return n, nil
+}
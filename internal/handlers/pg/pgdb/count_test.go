@@ -0,0 +1,138 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestCountDocuments(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+
+	t.Run("NoDatabase", func(t *testing.T) {
+		n, err := CountDocuments(ctx, pool, dbName, collectionName, false)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), n)
+	})
+
+	require.NoError(t, CreateDatabase(ctx, pool, dbName))
+
+	t.Run("NoCollection", func(t *testing.T) {
+		n, err := CountDocuments(ctx, pool, dbName, collectionName, true)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), n)
+	})
+
+	require.NoError(t, CreateCollection(ctx, pool, dbName, collectionName))
+
+	for i := int32(0); i < 3; i++ {
+		doc := must.NotFail(types.NewDocument("_id", i))
+		require.NoError(t, InsertDocument(ctx, pool, dbName, collectionName, doc))
+	}
+
+	t.Run("Exact", func(t *testing.T) {
+		n, err := CountDocuments(ctx, pool, dbName, collectionName, false)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), n)
+	})
+
+	t.Run("Estimated", func(t *testing.T) {
+		table, err := getTableName(ctx, pool, dbName, collectionName)
+		require.NoError(t, err)
+
+		_, err = pool.Exec(ctx, `ANALYZE `+pgx.Identifier{dbName, table}.Sanitize())
+		require.NoError(t, err)
+
+		n, err := CountDocuments(ctx, pool, dbName, collectionName, true)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), n)
+	})
+}
+
+func BenchmarkCountDocuments(b *testing.B) {
+	ctx := testutil.Ctx(b)
+
+	pool := getPool(ctx, b, zaptest.NewLogger(b))
+	dbName := testutil.DatabaseName(b)
+	collectionName := testutil.CollectionName(b)
+
+	b.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(b, CreateDatabase(ctx, pool, dbName))
+	require.NoError(b, CreateCollection(ctx, pool, dbName, collectionName))
+
+	table, err := getTableName(ctx, pool, dbName, collectionName)
+	require.NoError(b, err)
+
+	// insert 1M documents {_id: i} directly, in fjson format
+	sql := `INSERT INTO ` + pgx.Identifier{dbName, table}.Sanitize() + ` (_jsonb) ` +
+		`SELECT jsonb_build_object('$k', jsonb_build_array('_id'), '_id', i) FROM generate_series(1, 1000000) AS i`
+	_, err = pool.Exec(ctx, sql)
+	require.NoError(b, err)
+
+	_, err = pool.Exec(ctx, `ANALYZE `+pgx.Identifier{dbName, table}.Sanitize())
+	require.NoError(b, err)
+
+	b.Run("Exact", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, err := CountDocuments(ctx, pool, dbName, collectionName, false)
+			require.NoError(b, err)
+		}
+	})
+
+	b.Run("Estimated", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, err := CountDocuments(ctx, pool, dbName, collectionName, true)
+			require.NoError(b, err)
+		}
+	})
+
+	b.Run("Fetch", func(b *testing.B) {
+		sp := SQLParam{DB: dbName, Collection: collectionName}
+
+		for i := 0; i < b.N; i++ {
+			fetchedChan, _, err := pool.QueryDocuments(ctx, pool, sp)
+			require.NoError(b, err)
+
+			for f := range fetchedChan {
+				require.NoError(b, f.Err)
+			}
+		}
+	})
+}
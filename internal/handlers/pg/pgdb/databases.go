@@ -23,14 +23,44 @@ import (
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgtype/pgxtype"
 	"github.com/jackc/pgx/v4"
+	"golang.org/x/exp/slices"
 
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 )
 
+// DatabasesOpts represents options for DatabasesWithOpts.
+type DatabasesOpts struct {
+	// MissingSettings makes DatabasesWithOpts return schemas that look like FerretDB databases
+	// (they contain tables with _jsonb column) but do not have the settings table,
+	// instead of valid FerretDB databases. It is used to detect databases that need repair,
+	// see RepairDatabases.
+	MissingSettings bool
+}
+
 // Databases returns a sorted list of FerretDB database names / PostgreSQL schema names.
+//
+// Only schemas that contain the FerretDB settings table are returned;
+// use Schemas to get all PostgreSQL schemas.
 func Databases(ctx context.Context, querier pgxtype.Querier) ([]string, error) {
-	sql := "SELECT schema_name FROM information_schema.schemata ORDER BY schema_name"
-	rows, err := querier.Query(ctx, sql)
+	return DatabasesWithOpts(ctx, querier, nil)
+}
+
+// DatabasesWithOpts returns a sorted list of FerretDB database names / PostgreSQL schema names
+// with given options.
+func DatabasesWithOpts(ctx context.Context, querier pgxtype.Querier, opts *DatabasesOpts) ([]string, error) {
+	if opts == nil {
+		opts = new(DatabasesOpts)
+	}
+
+	sql := `SELECT table_schema FROM information_schema.tables WHERE table_name = $1`
+	if opts.MissingSettings {
+		sql = `SELECT table_schema FROM information_schema.columns ` +
+			`WHERE column_name = '_jsonb' AND data_type = 'jsonb' ` +
+			`EXCEPT ` + sql
+	}
+	sql += ` ORDER BY table_schema`
+
+	rows, err := querier.Query(ctx, sql, settingsTableName)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
@@ -47,6 +77,11 @@ func Databases(ctx context.Context, querier pgxtype.Querier) ([]string, error) {
 			continue
 		}
 
+		// schemas with reserved prefix (like serverSettingsSchema) are not FerretDB databases
+		if strings.HasPrefix(name, reservedPrefix) {
+			continue
+		}
+
 		res = append(res, name)
 	}
 	if err = rows.Err(); err != nil {
@@ -65,15 +100,25 @@ func Databases(ctx context.Context, querier pgxtype.Querier) ([]string, error) {
 //
 // Use errors.Is to check the error.
 func CreateDatabase(ctx context.Context, querier pgxtype.Querier, db string) error {
-	if !validateDatabaseNameRe.MatchString(db) ||
-		strings.HasPrefix(db, reservedPrefix) {
-		return ErrInvalidDatabaseName
+	if err := validateDatabaseName(db); err != nil {
+		return err
 	}
 
-	_, err := querier.Exec(ctx, `CREATE SCHEMA `+pgx.Identifier{db}.Sanitize())
-	if err == nil {
-		err = createSettingsTable(ctx, querier, db)
-	}
+	err := InTransaction(ctx, querier, func(tx pgx.Tx) error {
+		if err := lockDatabase(ctx, tx, db); err != nil {
+			return err
+		}
+
+		if err := checkDatabaseNameCase(ctx, tx, db); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `CREATE SCHEMA `+pgx.Identifier{db}.Sanitize()); err != nil {
+			return err
+		}
+
+		return createSettingsTable(ctx, tx, db)
+	})
 
 	if err == nil {
 		return nil
@@ -97,50 +142,148 @@ func CreateDatabase(ctx context.Context, querier pgxtype.Querier, db string) err
 }
 
 // CreateDatabaseIfNotExists creates a new FerretDB database (PostgreSQL schema).
-// If the schema already exists, no error is returned.
+// If the schema already exists (including the case when it was created concurrently by another client),
+// no error is returned.
+//
+// It returns (possibly wrapped) ErrInvalidDatabaseName if db name doesn't comply with the rules.
 func CreateDatabaseIfNotExists(ctx context.Context, querier pgxtype.Querier, db string) error {
-	if !validateDatabaseNameRe.MatchString(db) ||
-		strings.HasPrefix(db, reservedPrefix) {
-		return ErrInvalidDatabaseName
+	if err := validateDatabaseName(db); err != nil {
+		return err
 	}
 
-	_, err := querier.Exec(ctx, `CREATE SCHEMA IF NOT EXISTS `+pgx.Identifier{db}.Sanitize())
-	if err == nil {
-		err = createSettingsTable(ctx, querier, db)
-	}
+	err := InTransaction(ctx, querier, func(tx pgx.Tx) error {
+		if err := lockDatabase(ctx, tx, db); err != nil {
+			return err
+		}
+
+		if err := checkDatabaseNameCase(ctx, tx, db); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `CREATE SCHEMA IF NOT EXISTS `+pgx.Identifier{db}.Sanitize()); err != nil {
+			return err
+		}
+
+		return createSettingsTable(ctx, tx, db)
+	})
 
 	if err == nil || errors.Is(err, ErrAlreadyExist) {
 		return nil
 	}
 
+	if errors.Is(err, ErrInvalidDatabaseName) {
+		return err
+	}
+
 	var pgErr *pgconn.PgError
 	if !errors.As(err, &pgErr) {
 		return lazyerrors.Error(err)
 	}
 
 	switch pgErr.Code {
-	case pgerrcode.DuplicateSchema:
-		return ErrAlreadyExist
-	case pgerrcode.UniqueViolation, pgerrcode.DuplicateObject:
+	case pgerrcode.DuplicateSchema, pgerrcode.DuplicateTable, pgerrcode.UniqueViolation, pgerrcode.DuplicateObject:
+		// Schema or settings table was created by a concurrent client.
 		// https://www.postgresql.org/message-id/CA+TgmoZAdYVtwBfp1FL2sMZbiHCWT4UPrzRLNnX1Nb30Ku3-gg@mail.gmail.com
 		// The same thing for schemas. Reproducible by integration tests.
-		return ErrAlreadyExist
+		return nil
 	default:
 		return lazyerrors.Error(err)
 	}
 }
 
+// RepairDatabases restores the settings table of all schemas that look like FerretDB databases
+// but do not have it (see DatabasesOpts.MissingSettings), so they become FerretDB databases again.
+//
+// Collections are restored from existing plain tables with jsonb _jsonb column.
+// It is never called implicitly because such schemas could belong to other applications;
+// names of repaired databases are returned.
+func RepairDatabases(ctx context.Context, querier pgxtype.Querier) ([]string, error) {
+	broken, err := DatabasesWithOpts(ctx, querier, &DatabasesOpts{MissingSettings: true})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	res := make([]string, 0, len(broken))
+
+	for _, db := range broken {
+		var repaired bool
+
+		err = InTransaction(ctx, querier, func(tx pgx.Tx) error {
+			if err := lockDatabase(ctx, tx, db); err != nil {
+				return err
+			}
+
+			// the settings table could be restored by a concurrent client
+			err := createSettingsTable(ctx, tx, db)
+			if errors.Is(err, ErrAlreadyExist) || errors.Is(err, ErrTableNotExist) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			repaired = true
+
+			return repairSettingsTable(ctx, tx, db)
+		})
+		if err != nil {
+			return nil, lazyerrors.Errorf("failed to repair database %q: %w", db, err)
+		}
+
+		if repaired {
+			res = append(res, db)
+		}
+	}
+
+	return res, nil
+}
+
 // DropDatabase drops FerretDB database.
 //
-// It returns ErrSchemaNotExist if schema does not exist.
+// Only schemas that contain the FerretDB settings table are dropped,
+// so other schemas in the same PostgreSQL database are never removed.
+//
+// It returns (possibly wrapped):
+//
+//   - ErrInvalidDatabaseName if db name doesn't comply with the rules (for example, if it has a reserved prefix);
+//   - ErrSchemaNotExist if schema does not exist or it is not a FerretDB database.
 func DropDatabase(ctx context.Context, querier pgxtype.Querier, db string) error {
-	_, err := querier.Exec(ctx, `DROP SCHEMA `+pgx.Identifier{db}.Sanitize()+` CASCADE`)
+	if err := validateDatabaseName(db); err != nil {
+		return err
+	}
+
+	defer settingsCacheFor(querier).invalidate(querier, db)
+
+	err := InTransaction(ctx, querier, func(tx pgx.Tx) error {
+		// serialize with concurrent creation and dropping of the same database
+		if err := lockDatabase(ctx, tx, db); err != nil {
+			return err
+		}
+
+		tables, err := tables(ctx, tx, db)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if !slices.Contains(tables, settingsTableName) {
+			return ErrSchemaNotExist
+		}
+
+		_, err = tx.Exec(ctx, `DROP SCHEMA `+pgx.Identifier{db}.Sanitize()+` CASCADE`)
+		return err
+	})
 	if err == nil {
+		forgetIDIndexes(db)
+		knownOplogTables.Delete(db)
 		return nil
 	}
 
-	pgErr, ok := err.(*pgconn.PgError)
-	if !ok {
+	if errors.Is(err, ErrSchemaNotExist) {
+		return ErrSchemaNotExist
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
 		return lazyerrors.Error(err)
 	}
 
@@ -151,3 +294,24 @@ func DropDatabase(ctx context.Context, querier pgxtype.Querier, db string) error
 		return lazyerrors.Error(err)
 	}
 }
+
+// DropDatabaseIfExists drops FerretDB database if it exists.
+//
+// True is returned if database was dropped, false if it did not exist
+// (including the case when it was dropped concurrently by another client)
+// or it is not a FerretDB database.
+// It returns (possibly wrapped) ErrInvalidDatabaseName if db name doesn't comply with the rules.
+func DropDatabaseIfExists(ctx context.Context, querier pgxtype.Querier, db string) (bool, error) {
+	// Do not use DROP SCHEMA IF EXISTS there: it does not tell if schema was dropped.
+	// Concurrent DropDatabase waits for the lock and then does not find the settings table,
+	// so it returns ErrSchemaNotExist.
+	err := DropDatabase(ctx, querier, db)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, ErrSchemaNotExist):
+		return false, nil
+	default:
+		return false, lazyerrors.Error(err)
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestDatabases(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+
+	t.Run("ForeignSchema", func(t *testing.T) {
+		t.Parallel()
+
+		databaseName := testutil.DatabaseName(t)
+		schemaName := databaseName + "_foreign"
+
+		dropSchema := func() {
+			pool.DropDatabase(ctx, databaseName)
+			pool.Exec(ctx, `DROP SCHEMA IF EXISTS `+pgx.Identifier{schemaName}.Sanitize()+` CASCADE`)
+		}
+		t.Cleanup(dropSchema)
+
+		dropSchema()
+		require.NoError(t, CreateDatabase(ctx, pool, databaseName))
+
+		_, err := pool.Exec(ctx, `CREATE SCHEMA `+pgx.Identifier{schemaName}.Sanitize())
+		require.NoError(t, err)
+		_, err = pool.Exec(ctx, `CREATE TABLE `+pgx.Identifier{schemaName, "test"}.Sanitize()+` (id int)`)
+		require.NoError(t, err)
+
+		databases, err := Databases(ctx, pool)
+		require.NoError(t, err)
+		assert.Contains(t, databases, databaseName)
+		assert.NotContains(t, databases, schemaName)
+
+		schemas, err := Schemas(ctx, pool)
+		require.NoError(t, err)
+		assert.Contains(t, schemas, databaseName)
+		assert.Contains(t, schemas, schemaName)
+
+		broken, err := DatabasesWithOpts(ctx, pool, &DatabasesOpts{MissingSettings: true})
+		require.NoError(t, err)
+		assert.NotContains(t, broken, databaseName)
+		assert.NotContains(t, broken, schemaName)
+
+		require.ErrorIs(t, DropDatabase(ctx, pool, schemaName), ErrSchemaNotExist)
+
+		dropped, err := DropDatabaseIfExists(ctx, pool, schemaName)
+		require.NoError(t, err)
+		assert.False(t, dropped)
+
+		schemas, err = Schemas(ctx, pool)
+		require.NoError(t, err)
+		assert.Contains(t, schemas, schemaName)
+	})
+
+	t.Run("NotDropped", func(t *testing.T) {
+		t.Parallel()
+
+		require.ErrorIs(t, DropDatabase(ctx, pool, "public"), ErrSchemaNotExist)
+		require.ErrorIs(t, DropDatabase(ctx, pool, serverSettingsSchema), ErrInvalidDatabaseName)
+
+		_, err := DropDatabaseIfExists(ctx, pool, reservedPrefix+"test")
+		require.ErrorIs(t, err, ErrInvalidDatabaseName)
+
+		schemas, err := Schemas(ctx, pool)
+		require.NoError(t, err)
+		assert.Contains(t, schemas, "public")
+	})
+
+	t.Run("MissingSettings", func(t *testing.T) {
+		t.Parallel()
+
+		databaseName := testutil.DatabaseName(t)
+		collectionName := testutil.CollectionName(t)
+
+		dropSchema := func() {
+			pool.Exec(ctx, `DROP SCHEMA IF EXISTS `+pgx.Identifier{databaseName}.Sanitize()+` CASCADE`)
+		}
+		t.Cleanup(dropSchema)
+
+		dropSchema()
+		require.NoError(t, CreateDatabase(ctx, pool, databaseName))
+		require.NoError(t, CreateCollection(ctx, pool, databaseName, collectionName))
+
+		_, err := pool.Exec(ctx, `DROP TABLE `+pgx.Identifier{databaseName, settingsTableName}.Sanitize())
+		require.NoError(t, err)
+		pool.settingsCache.invalidateAll()
+
+		databases, err := Databases(ctx, pool)
+		require.NoError(t, err)
+		assert.NotContains(t, databases, databaseName)
+
+		broken, err := DatabasesWithOpts(ctx, pool, &DatabasesOpts{MissingSettings: true})
+		require.NoError(t, err)
+		assert.Contains(t, broken, databaseName)
+
+		// databases without settings are not dropped
+		require.ErrorIs(t, DropDatabase(ctx, pool, databaseName), ErrSchemaNotExist)
+
+		repaired, err := RepairDatabases(ctx, pool)
+		require.NoError(t, err)
+		assert.Contains(t, repaired, databaseName)
+
+		databases, err = Databases(ctx, pool)
+		require.NoError(t, err)
+		assert.Contains(t, databases, databaseName)
+
+		collections, err := Collections(ctx, pool, databaseName)
+		require.NoError(t, err)
+		assert.Equal(t, []string{collectionName}, collections)
+	})
+}
+
+func TestCreateDatabaseCaseCollision(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+
+	databaseName := testutil.DatabaseName(t)
+	upperName := strings.ToUpper(databaseName)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, databaseName)
+		pool.DropDatabase(ctx, upperName)
+	})
+
+	pool.DropDatabase(ctx, databaseName)
+	pool.DropDatabase(ctx, upperName)
+	require.NoError(t, CreateDatabase(ctx, pool, databaseName))
+
+	err := CreateDatabase(ctx, pool, upperName)
+	require.ErrorIs(t, err, ErrInvalidDatabaseName)
+	assert.Contains(t, err.Error(), upperName)
+	assert.Contains(t, err.Error(), databaseName)
+
+	err = CreateDatabaseIfNotExists(ctx, pool, upperName)
+	require.ErrorIs(t, err, ErrInvalidDatabaseName)
+
+	_, err = CreateCollectionIfNotExist(ctx, pool, upperName, "test")
+	require.ErrorIs(t, err, ErrInvalidDatabaseName)
+
+	// the same name is fine
+	require.NoError(t, CreateDatabaseIfNotExists(ctx, pool, databaseName))
+	require.ErrorIs(t, CreateDatabase(ctx, pool, databaseName), ErrAlreadyExist)
+
+	schemas, err := Schemas(ctx, pool)
+	require.NoError(t, err)
+	assert.NotContains(t, schemas, upperName)
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/jackc/pgx/v4"
 
 	"github.com/FerretDB/FerretDB/internal/fjson"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 )
 
@@ -37,7 +38,7 @@ func DeleteDocumentsByID(ctx context.Context, tx pgx.Tx, sp *SQLParam, ids []any
 
 	for i, id := range ids {
 		placeholders[i] = p.Next()
-		idsMarshalled[i] = must.NotFail(fjson.Marshal(id))
+		idsMarshalled[i] = string(must.NotFail(fjson.Marshal(id)))
 	}
 
 	sql := `DELETE `
@@ -59,3 +60,57 @@ func DeleteDocumentsByID(ctx context.Context, tx pgx.Tx, sp *SQLParam, ids []any
 
 	return tag.RowsAffected(), nil
 }
+
+// DeleteDocumentsByFilter deletes documents matching sp.Filter with a single DELETE statement.
+//
+// If limit is 1, only one matching document is deleted; 0 means no limit.
+// Other SQLParam fields except DB, Collection and Comment are ignored.
+//
+// If the filter can't be pushed down, it returns false, and the caller should delete documents
+// by fetching and filtering them in memory.
+// If the collection doesn't exist, it returns 0, true, and no error.
+func DeleteDocumentsByFilter(ctx context.Context, tx pgx.Tx, sp *SQLParam, limit int64) (int64, bool, error) {
+	var p Placeholder
+	where, args, ok := prepareWhereClause(sp.Filter, &p)
+	if !ok || limit < 0 || limit > 1 {
+		return 0, false, nil
+	}
+
+	exists, err := CollectionExists(ctx, tx, sp.DB, sp.Collection)
+	if err != nil {
+		return 0, false, lazyerrors.Error(err)
+	}
+	if !exists {
+		return 0, true, nil
+	}
+
+	table, err := getTableName(ctx, tx, sp.DB, sp.Collection)
+	if err != nil {
+		return 0, false, lazyerrors.Error(err)
+	}
+
+	sql := `DELETE `
+
+	if c := sp.Comment; c != "" {
+		c = strings.ReplaceAll(c, "/*", "/ *")
+		c = strings.ReplaceAll(c, "*/", "* /")
+
+		sql += `/* ` + c + ` */ `
+	}
+
+	tableName := pgx.Identifier{sp.DB, table}.Sanitize()
+	sql += `FROM ` + tableName
+
+	if limit == 1 {
+		sql += ` WHERE ctid IN (SELECT ctid FROM ` + tableName + where + ` LIMIT 1)`
+	} else {
+		sql += where
+	}
+
+	tag, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, false, lazyerrors.Error(err)
+	}
+
+	return tag.RowsAffected(), true, nil
+}
@@ -0,0 +1,245 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+// deleteTestDocuments returns documents with all kinds of values that could confuse pushdown.
+func deleteTestDocuments() []*types.Document {
+	oid := types.ObjectID{0x62, 0x56, 0xc5, 0xba, 0x0b, 0xad, 0xc0, 0xff, 0xee, 0xff, 0xff, 0xff}
+	date := time.Date(2022, time.March, 4, 5, 6, 7, 0, time.UTC)
+
+	return []*types.Document{
+		must.NotFail(types.NewDocument("_id", "string", "v", "foo")),
+		must.NotFail(types.NewDocument("_id", "string-empty", "v", "")),
+		must.NotFail(types.NewDocument("_id", "bool", "v", true)),
+		must.NotFail(types.NewDocument("_id", "int32", "v", int32(42))),
+		must.NotFail(types.NewDocument("_id", "double", "v", 42.0)),
+		must.NotFail(types.NewDocument("_id", "objectid", "v", oid)),
+		must.NotFail(types.NewDocument("_id", "datetime", "v", date)),
+		must.NotFail(types.NewDocument("_id", "null", "v", types.Null)),
+		must.NotFail(types.NewDocument("_id", "missing")),
+		must.NotFail(types.NewDocument("_id", "array", "v", must.NotFail(types.NewArray("foo", true, oid)))),
+		must.NotFail(types.NewDocument("_id", "array-nested", "v", must.NotFail(types.NewArray(
+			must.NotFail(types.NewArray("foo")),
+		)))),
+		must.NotFail(types.NewDocument("_id", "document", "v", must.NotFail(types.NewDocument("foo", "foo")))),
+		must.NotFail(types.NewDocument("_id", oid, "v", "bar")),
+	}
+}
+
+func TestDeleteDocumentsByFilter(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(t, CreateDatabase(ctx, pool, dbName))
+
+	oid := types.ObjectID{0x62, 0x56, 0xc5, 0xba, 0x0b, 0xad, 0xc0, 0xff, 0xee, 0xff, 0xff, 0xff}
+	date := time.Date(2022, time.March, 4, 5, 6, 7, 0, time.UTC)
+
+	for name, tc := range map[string]struct {
+		filter   *types.Document
+		pushdown bool
+	}{
+		"Empty":       {filter: must.NotFail(types.NewDocument()), pushdown: true},
+		"Comment":     {filter: must.NotFail(types.NewDocument("$comment", "test")), pushdown: true},
+		"IDString":    {filter: must.NotFail(types.NewDocument("_id", "string")), pushdown: true},
+		"IDObjectID":  {filter: must.NotFail(types.NewDocument("_id", oid)), pushdown: true},
+		"String":      {filter: must.NotFail(types.NewDocument("v", "foo")), pushdown: true},
+		"StringEmpty": {filter: must.NotFail(types.NewDocument("v", "")), pushdown: true},
+		"Bool":        {filter: must.NotFail(types.NewDocument("v", true)), pushdown: true},
+		"ObjectID":    {filter: must.NotFail(types.NewDocument("v", oid)), pushdown: true},
+		"Datetime":    {filter: must.NotFail(types.NewDocument("v", date)), pushdown: true},
+		"Several":     {filter: must.NotFail(types.NewDocument("_id", "string", "v", "foo")), pushdown: true},
+		"NoMatch":     {filter: must.NotFail(types.NewDocument("v", "baz")), pushdown: true},
+		"Number":      {filter: must.NotFail(types.NewDocument("v", int32(42))), pushdown: false},
+		"Null":        {filter: must.NotFail(types.NewDocument("v", types.Null)), pushdown: false},
+		"Operator": {
+			filter:   must.NotFail(types.NewDocument("v", must.NotFail(types.NewDocument("$eq", "foo")))),
+			pushdown: false,
+		},
+		"DotNotation":      {filter: must.NotFail(types.NewDocument("v.foo", "foo")), pushdown: false},
+		"TopLevelOperator": {filter: must.NotFail(types.NewDocument("$and", must.NotFail(types.NewArray()))), pushdown: false},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			for _, limit := range []int64{0, 1} {
+				collectionName := fmt.Sprintf("%s_%d", testutil.CollectionName(t), limit)
+				docs := deleteTestDocuments()
+
+				var expected int64
+				for _, doc := range docs {
+					matches, err := common.FilterDocument(doc, tc.filter)
+					require.NoError(t, err)
+					if matches {
+						expected++
+					}
+				}
+				if limit == 1 && expected > 1 {
+					expected = 1
+				}
+
+				err := pool.InTransaction(ctx, func(tx pgx.Tx) error {
+					if err := InsertDocuments(ctx, tx, dbName, collectionName, docs); err != nil {
+						return err
+					}
+
+					sp := &SQLParam{DB: dbName, Collection: collectionName, Filter: tc.filter}
+					n, pushdown, err := DeleteDocumentsByFilter(ctx, tx, sp, limit)
+					require.NoError(t, err)
+					assert.Equal(t, tc.pushdown, pushdown)
+
+					if !pushdown {
+						return nil
+					}
+
+					assert.Equal(t, expected, n)
+
+					// all other documents are kept
+					remaining, err := CountDocuments(ctx, tx, dbName, collectionName, false)
+					require.NoError(t, err)
+					assert.Equal(t, int64(len(docs))-expected, remaining)
+
+					return nil
+				})
+				require.NoError(t, err)
+			}
+		})
+	}
+
+	t.Run("NonExistentCollection", func(t *testing.T) {
+		t.Parallel()
+
+		err := pool.InTransaction(ctx, func(tx pgx.Tx) error {
+			sp := &SQLParam{DB: dbName, Collection: "no_such_collection"}
+			n, pushdown, err := DeleteDocumentsByFilter(ctx, tx, sp, 0)
+			require.NoError(t, err)
+			assert.True(t, pushdown)
+			assert.Zero(t, n)
+
+			return nil
+		})
+		require.NoError(t, err)
+	})
+}
+
+func BenchmarkDeleteDocuments(b *testing.B) {
+	ctx := testutil.Ctx(b)
+
+	pool := getPool(ctx, b, zaptest.NewLogger(b))
+	dbName := testutil.DatabaseName(b)
+	collectionName := testutil.CollectionName(b)
+
+	b.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(b, CreateDatabase(ctx, pool, dbName))
+
+	// every tenth document is tagged
+	docs := make([]*types.Document, 10_000)
+	for i := range docs {
+		tag := "other"
+		if i%10 == 0 {
+			tag = "tagged"
+		}
+
+		docs[i] = must.NotFail(types.NewDocument("_id", int32(i), "tag", tag))
+	}
+
+	err := pool.InTransaction(ctx, func(tx pgx.Tx) error {
+		return InsertDocuments(ctx, tx, dbName, collectionName, docs)
+	})
+	require.NoError(b, err)
+
+	filter := must.NotFail(types.NewDocument("tag", "tagged"))
+	sp := &SQLParam{DB: dbName, Collection: collectionName, Filter: filter}
+
+	// deletions are rolled back, so every iteration deletes the same documents
+	errRollback := fmt.Errorf("rollback")
+
+	b.Run("Pushdown", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			err := pool.InTransaction(ctx, func(tx pgx.Tx) error {
+				n, pushdown, err := DeleteDocumentsByFilter(ctx, tx, sp, 0)
+				require.NoError(b, err)
+				require.True(b, pushdown)
+				require.Equal(b, int64(len(docs)/10), n)
+
+				return errRollback
+			})
+			require.ErrorIs(b, err, errRollback)
+		}
+	})
+
+	b.Run("InMemory", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			err := pool.InTransaction(ctx, func(tx pgx.Tx) error {
+				iter, _, err := QueryIterator(ctx, tx, *sp)
+				require.NoError(b, err)
+
+				var ids []any
+				for {
+					doc, err := iter.Next(ctx)
+					if errors.Is(err, ErrIteratorDone) {
+						break
+					}
+					require.NoError(b, err)
+
+					matches, err := common.FilterDocument(doc, filter)
+					require.NoError(b, err)
+					if matches {
+						ids = append(ids, must.NotFail(doc.Get("_id")))
+					}
+				}
+				iter.Close()
+
+				n, err := DeleteDocumentsByID(ctx, tx, sp, ids)
+				require.NoError(b, err)
+				require.Equal(b, int64(len(docs)/10), n)
+
+				return errRollback
+			})
+			require.ErrorIs(b, err, errRollback)
+		}
+	})
+}
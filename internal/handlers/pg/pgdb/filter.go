@@ -0,0 +1,85 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"strings"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/fjson"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// prepareWhereClause returns WHERE clause with arguments for the given filter,
+// or false if the filter can't be handled by PostgreSQL exactly as it is handled in memory.
+//
+// Only an empty filter and equality of top-level fields to strings, booleans, ObjectIDs and dates
+// are pushed down: their fjson representations are equal if and only if values are equal.
+// Numbers are never pushed down because values of different BSON types could be equal.
+// Empty filter results in an empty clause.
+func prepareWhereClause(filter *types.Document, p *Placeholder) (string, []any, bool) {
+	if filter.Len() == 0 {
+		return "", nil, true
+	}
+
+	var conds []string
+	var args []any
+
+	for _, key := range filter.Keys() {
+		value := must.NotFail(filter.Get(key))
+
+		if key == "$comment" {
+			if _, ok := value.(string); ok {
+				continue
+			}
+
+			return "", nil, false
+		}
+
+		if key == "" || strings.HasPrefix(key, "$") || strings.Contains(key, ".") {
+			return "", nil, false
+		}
+
+		switch value.(type) {
+		case string, bool, types.ObjectID, time.Time:
+		default:
+			return "", nil, false
+		}
+
+		keyP, valueP := p.Next(), p.Next()
+		args = append(args, key, string(must.NotFail(fjson.Marshal(value))))
+
+		if key == "_id" {
+			// _id can't be an array
+			conds = append(conds, `_jsonb->`+keyP+` = `+valueP+`::jsonb`)
+			continue
+		}
+
+		// a top-level array matches if any of its elements is equal to the value;
+		// containment of an array with a single scalar or ObjectID/date object is exact there
+		arrP := p.Next()
+		args = append(args, string(must.NotFail(fjson.Marshal(must.NotFail(types.NewArray(value))))))
+
+		conds = append(conds, `(_jsonb->`+keyP+` = `+valueP+`::jsonb OR `+
+			`(jsonb_typeof(_jsonb->`+keyP+`) = 'array' AND _jsonb->`+keyP+` @> `+arrP+`::jsonb))`)
+	}
+
+	if len(conds) == 0 {
+		return "", nil, true
+	}
+
+	return ` WHERE ` + strings.Join(conds, ` AND `), args, true
+}
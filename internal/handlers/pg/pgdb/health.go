@@ -0,0 +1,238 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v4"
+	"go.uber.org/zap"
+)
+
+// Health check parameters.
+const (
+	// DefaultHealthCheckInterval is the default interval between health checks of available PostgreSQL.
+	DefaultHealthCheckInterval = 5 * time.Second
+
+	// healthCheckTimeout limits a single health check.
+	healthCheckTimeout = 2 * time.Second
+
+	// minHealthCheckBackoff is the first delay before the next check of unavailable PostgreSQL;
+	// it is doubled after each failed check up to the health check interval.
+	minHealthCheckBackoff = 100 * time.Millisecond
+)
+
+// Health represents PostgreSQL availability as seen by the pool.
+type Health struct {
+	// Ready is true if the last health check succeeded,
+	// and no connection loss was detected after it.
+	Ready bool
+
+	// LastCheck is the time of the last health check; zero if there were none.
+	LastCheck time.Time
+
+	// LastError is the error of the last failed health check or detected connection loss;
+	// it is empty if PostgreSQL is ready.
+	LastError string
+
+	// Failures is the number of consecutive failed health checks.
+	Failures int64
+
+	// ConnectionLosses is the total number of detected connection losses.
+	ConnectionLosses int64
+}
+
+// Health returns the current PostgreSQL availability state.
+func (pgPool *Pool) Health() Health {
+	pgPool.healthM.Lock()
+	defer pgPool.healthM.Unlock()
+
+	return pgPool.health
+}
+
+// IsConnectionError returns true if the operation failed with (possibly wrapped) error
+// caused by a lost or failed connection to PostgreSQL,
+// for example, because PostgreSQL was restarted or the backend was terminated.
+//
+// Client timeouts and cancellations are not connection errors.
+func IsConnectionError(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgerrcode.AdminShutdown, pgerrcode.CrashShutdown, pgerrcode.CannotConnectNow:
+			return true
+		default:
+			return pgerrcode.IsConnectionException(pgErr.Code)
+		}
+	}
+
+	// timeouts and cancellations are caused by the client, not by the connection
+	if pgconn.Timeout(err) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var safeErr interface{ SafeToRetry() bool }
+	if errors.As(err, &safeErr) && safeErr.SafeToRetry() {
+		return true
+	}
+
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// InReadTransactionRetry wraps the given function f in a transaction like InTransactionRetry.
+//
+// It should be used only for functions f that do not modify data.
+// In addition to InTransactionRetry's retries, if the transaction fails because the connection
+// to PostgreSQL was lost (for example, because PostgreSQL was restarted),
+// it is retried once with a new connection.
+func (pgPool *Pool) InReadTransactionRetry(ctx context.Context, f func(pgx.Tx) error) error {
+	err := pgPool.InTransactionRetry(ctx, f)
+	if err == nil || ctx.Err() != nil || !IsConnectionError(err) {
+		return err
+	}
+
+	// idle connections were already closed by connectionLost
+	pgPool.logger.Debug("Retrying read transaction after connection loss", zap.Error(err))
+
+	return pgPool.InTransactionRetry(ctx, f)
+}
+
+// connectionLost handles the (possibly wrapped) connection error returned by the operation.
+//
+// It marks PostgreSQL as not ready, invalidates cached settings, closes idle connections
+// (that are likely broken too, for example, if PostgreSQL was restarted),
+// and triggers the health check.
+func (pgPool *Pool) connectionLost(err error) {
+	pgPool.healthM.Lock()
+	wasReady := pgPool.health.Ready
+	pgPool.health.Ready = false
+	pgPool.health.LastError = err.Error()
+	pgPool.health.ConnectionLosses++
+	pgPool.healthM.Unlock()
+
+	if wasReady {
+		pgPool.logger.Warn("PostgreSQL connection lost", zap.Error(err))
+	}
+
+	pgPool.settingsCache.invalidateAll()
+	pgPool.closeIdle()
+
+	select {
+	case pgPool.healthWakeup <- struct{}{}:
+	default:
+	}
+}
+
+// closeIdle closes all idle connections in the pool.
+func (pgPool *Pool) closeIdle() {
+	// use a new context: the operation's one could be canceled already
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+
+	for _, conn := range pgPool.AcquireAllIdle(ctx) {
+		_ = conn.Conn().Close(ctx)
+		conn.Release() // destroys closed connection
+	}
+}
+
+// runHealthChecks checks PostgreSQL availability until ctx is done.
+//
+// Checks run every interval while PostgreSQL is available,
+// and with exponential backoff (up to interval) after a failure.
+func (pgPool *Pool) runHealthChecks(ctx context.Context, interval time.Duration) {
+	backoff := minHealthCheckBackoff
+
+	for {
+		delay := interval
+		if err := pgPool.checkHealth(ctx); err != nil {
+			delay = backoff
+			if backoff *= 2; backoff > interval {
+				backoff = interval
+			}
+		} else {
+			backoff = minHealthCheckBackoff
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		t := time.NewTimer(delay)
+
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return
+		case <-pgPool.healthWakeup:
+			t.Stop()
+		case <-t.C:
+		}
+	}
+}
+
+// checkHealth checks PostgreSQL availability once and updates the health state.
+func (pgPool *Pool) checkHealth(ctx context.Context) error {
+	checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	err := pgPool.Ping(checkCtx)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if err != nil && IsConnectionError(err) {
+		pgPool.closeIdle()
+	}
+
+	pgPool.healthM.Lock()
+	defer pgPool.healthM.Unlock()
+
+	wasReady := pgPool.health.Ready
+
+	pgPool.health.LastCheck = time.Now()
+
+	if err != nil {
+		pgPool.health.Ready = false
+		pgPool.health.LastError = err.Error()
+		pgPool.health.Failures++
+
+		if wasReady || pgPool.health.Failures == 1 {
+			pgPool.logger.Warn("PostgreSQL is not available", zap.Error(err))
+		}
+
+		return err
+	}
+
+	if !wasReady && pgPool.health.LastError != "" {
+		pgPool.logger.Info("PostgreSQL is available again", zap.Int64("failures", pgPool.health.Failures))
+	}
+
+	pgPool.health.Ready = true
+	pgPool.health.LastError = ""
+	pgPool.health.Failures = 0
+
+	return nil
+}
@@ -0,0 +1,198 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestIsConnectionError(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		err      error
+		expected bool
+	}{
+		"AdminShutdown": {
+			err:      lazyerrors.Error(&pgconn.PgError{Code: pgerrcode.AdminShutdown}),
+			expected: true,
+		},
+		"ConnectionFailure": {
+			err:      &pgconn.PgError{Code: pgerrcode.ConnectionFailure},
+			expected: true,
+		},
+		"CannotConnectNow": {
+			err:      &pgconn.PgError{Code: pgerrcode.CannotConnectNow},
+			expected: true,
+		},
+		"EOF": {
+			err:      lazyerrors.Error(io.ErrUnexpectedEOF),
+			expected: true,
+		},
+		"SerializationFailure": {
+			err:      &pgconn.PgError{Code: pgerrcode.SerializationFailure},
+			expected: false,
+		},
+		"Canceled": {
+			err:      lazyerrors.Error(context.Canceled),
+			expected: false,
+		},
+		"Other": {
+			err:      ErrTableNotExist,
+			expected: false,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, IsConnectionError(tc.err))
+		})
+	}
+}
+
+func TestHealthChecks(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	// nothing listens there
+	connString := "postgres://postgres@127.0.0.1:1/ferretdb?sslmode=disable&connect_timeout=1"
+	pool, err := NewPoolWithOpts(ctx, connString, zaptest.NewLogger(t), &NewPoolOpts{
+		Lazy:                true,
+		HealthCheckInterval: 500 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	assert.Eventually(t, func() bool { return pool.Health().Failures >= 3 }, 10*time.Second, 10*time.Millisecond)
+
+	h := pool.Health()
+	assert.False(t, h.Ready)
+	assert.NotEmpty(t, h.LastError)
+	assert.False(t, h.LastCheck.IsZero())
+
+	err = pool.InReadTransactionRetry(ctx, func(tx pgx.Tx) error {
+		t.Fatal("should not be called")
+		return nil
+	})
+	require.Error(t, err)
+	assert.True(t, IsConnectionError(err), "%+v", err)
+	assert.Equal(t, int64(2), pool.Health().ConnectionLosses)
+}
+
+func TestConnectionLoss(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	// use a unique application name to find the pool's backends
+	applicationName := testutil.DatabaseName(t)
+	connString := testutil.PostgreSQLURL(t, &testutil.PostgreSQLURLOpts{
+		Params: map[string]string{"application_name": applicationName},
+	})
+
+	// background checks are disabled to make the test deterministic
+	pool, err := NewPoolWithOpts(ctx, connString, zaptest.NewLogger(t), &NewPoolOpts{
+		SimpleProtocol:      *simpleProtocolF,
+		HealthCheckInterval: -1,
+	})
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	assert.True(t, pool.Health().Ready)
+
+	admin := getPool(ctx, t, zaptest.NewLogger(t))
+
+	// terminateBackends terminates all pool's connections like PostgreSQL restart does.
+	terminateBackends := func(t *testing.T) {
+		t.Helper()
+
+		count := func(sql string) int {
+			var n int
+			require.NoError(t, admin.QueryRow(ctx, sql, applicationName).Scan(&n))
+			return n
+		}
+
+		n := count(`SELECT count(pg_terminate_backend(pid)) FROM pg_stat_activity WHERE application_name = $1`)
+		require.Positive(t, n)
+
+		// pg_terminate_backend does not wait for backends to exit
+		assert.Eventually(t, func() bool {
+			return count(`SELECT count(*) FROM pg_stat_activity WHERE application_name = $1`) == 0
+		}, 10*time.Second, 10*time.Millisecond)
+	}
+
+	read := func(tx pgx.Tx) error {
+		var v int
+		return tx.QueryRow(ctx, `SELECT 1`).Scan(&v)
+	}
+
+	// create an idle connection
+	require.NoError(t, pool.InReadTransactionRetry(ctx, read))
+
+	t.Run("Read", func(t *testing.T) {
+		terminateBackends(t)
+
+		var calls int
+		err := pool.InReadTransactionRetry(ctx, func(tx pgx.Tx) error {
+			calls++
+			return read(tx)
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, calls)
+
+		h := pool.Health()
+		assert.False(t, h.Ready)
+		assert.Equal(t, int64(1), h.ConnectionLosses)
+	})
+
+	t.Run("Write", func(t *testing.T) {
+		terminateBackends(t)
+
+		var calls int
+		err := pool.InTransactionRetry(ctx, func(tx pgx.Tx) error {
+			calls++
+			return read(tx)
+		})
+		require.Error(t, err)
+		assert.True(t, IsConnectionError(err), "%+v", err)
+		assert.True(t, IsRetryableWrite(err))
+		assert.LessOrEqual(t, calls, 1)
+		assert.Equal(t, int64(2), pool.Health().ConnectionLosses)
+
+		// the next transaction uses a new connection
+		require.NoError(t, pool.InTransactionRetry(ctx, read))
+	})
+
+	require.NoError(t, pool.checkHealth(ctx))
+
+	h := pool.Health()
+	assert.True(t, h.Ready)
+	assert.Empty(t, h.LastError)
+}
@@ -0,0 +1,203 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/jackc/pgtype/pgxtype"
+	"github.com/jackc/pgx/v4"
+
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// JSONBIndexName is the name of GIN index on _jsonb column as reported to clients.
+const JSONBIndexName = "_jsonb_gin"
+
+// jsonbIndexName returns PostgreSQL name of GIN index on _jsonb column for the given table.
+func jsonbIndexName(table string) string {
+	name := table + JSONBIndexName
+	if len(name) > maxTableNameLength {
+		name = formatCollectionName(name)
+	}
+
+	return name
+}
+
+// idIndexSuffix is the suffix of PostgreSQL name of unique index on _id field.
+const idIndexSuffix = "_id_"
+
+// idIndexName returns PostgreSQL name of unique index on _id field for the given table.
+//
+// That index is created together with the table; tables created by older versions don't have it.
+func idIndexName(table string) string {
+	name := table + idIndexSuffix
+	if len(name) > maxTableNameLength {
+		name = formatCollectionName(name)
+	}
+
+	return name
+}
+
+// knownIDIndexes contains [2]string{schema, table} keys of tables known to have a valid unique index on _id field.
+var knownIDIndexes sync.Map
+
+// hasIDIndex returns true if the given table has a valid unique index on _id field.
+//
+// Only positive results are cached, so tables created by older versions are checked every time.
+func hasIDIndex(ctx context.Context, querier pgxtype.Querier, db, table string) (bool, error) {
+	key := [2]string{db, table}
+	if _, ok := knownIDIndexes.Load(key); ok {
+		return true, nil
+	}
+
+	valid, err := indexValid(ctx, querier, db, idIndexName(table))
+	switch {
+	case err == nil:
+		// nothing
+	case errors.Is(err, ErrTableNotExist):
+		return false, nil
+	default:
+		return false, lazyerrors.Error(err)
+	}
+
+	if valid {
+		knownIDIndexes.Store(key, struct{}{})
+	}
+
+	return valid, nil
+}
+
+// forgetIDIndexes removes cached hasIDIndex results for the given schema.
+func forgetIDIndexes(db string) {
+	knownIDIndexes.Range(func(key, _ any) bool {
+		if key.([2]string)[0] == db {
+			knownIDIndexes.Delete(key)
+		}
+
+		return true
+	})
+}
+
+// EnsureJSONBIndex creates GIN index on _jsonb column of the given FerretDB collection if it does not exist.
+//
+// It uses CREATE INDEX CONCURRENTLY, so it does not block writes to existing large collections,
+// but querier must not be a transaction.
+// An invalid index left by previously failed attempt is re-created.
+//
+// It returns (possibly wrapped) ErrTableNotExist if database or collection does not exist.
+func EnsureJSONBIndex(ctx context.Context, querier pgxtype.Querier, db, collection string) error {
+	if querierConn(querier) != nil {
+		return lazyerrors.Errorf("pgdb.EnsureJSONBIndex: can't be used in a transaction")
+	}
+
+	exists, err := CollectionExists(ctx, querier, db, collection)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+	if !exists {
+		return ErrTableNotExist
+	}
+
+	table, err := getTableName(ctx, querier, db, collection)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	index := jsonbIndexName(table)
+
+	valid, err := indexValid(ctx, querier, db, index)
+	switch {
+	case err == nil && valid:
+		return nil
+	case err == nil && !valid:
+		sql := `DROP INDEX CONCURRENTLY IF EXISTS ` + pgx.Identifier{db, index}.Sanitize()
+		if _, err = querier.Exec(ctx, sql); err != nil {
+			return lazyerrors.Error(err)
+		}
+	case errors.Is(err, ErrTableNotExist):
+		// nothing, create it
+	default:
+		return lazyerrors.Error(err)
+	}
+
+	sql := `CREATE INDEX CONCURRENTLY IF NOT EXISTS ` + pgx.Identifier{index}.Sanitize() +
+		` ON ` + pgx.Identifier{db, table}.Sanitize() + ` USING gin (_jsonb jsonb_path_ops)`
+	if _, err = querier.Exec(ctx, sql); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// JSONBIndexSize returns the size in bytes of GIN index on _jsonb column of the given FerretDB collection.
+//
+// False is returned if database, collection, or index does not exist.
+func JSONBIndexSize(ctx context.Context, querier pgxtype.Querier, db, collection string) (int64, bool, error) {
+	exists, err := CollectionExists(ctx, querier, db, collection)
+	if err != nil || !exists {
+		return 0, false, err
+	}
+
+	table, err := getTableName(ctx, querier, db, collection)
+	if err != nil {
+		return 0, false, lazyerrors.Error(err)
+	}
+
+	sql := `SELECT pg_relation_size(c.oid) ` +
+		`FROM pg_catalog.pg_class AS c ` +
+		`JOIN pg_catalog.pg_namespace AS n ON n.oid = c.relnamespace ` +
+		`WHERE n.nspname = $1 AND c.relname = $2 AND c.relkind = 'i'`
+	rows, err := querier.Query(ctx, sql, db, jsonbIndexName(table))
+	if err != nil {
+		return 0, false, lazyerrors.Error(err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return 0, false, lazyerrors.Error(rows.Err())
+	}
+
+	var size int64
+	if err = rows.Scan(&size); err != nil {
+		return 0, false, lazyerrors.Error(err)
+	}
+
+	return size, true, nil
+}
+
+// indexValid returns true if the given index exists and is valid.
+//
+// It returns ErrTableNotExist if index does not exist.
+func indexValid(ctx context.Context, querier pgxtype.Querier, db, index string) (bool, error) {
+	sql := `SELECT i.indisvalid ` +
+		`FROM pg_catalog.pg_index AS i ` +
+		`JOIN pg_catalog.pg_class AS c ON c.oid = i.indexrelid ` +
+		`JOIN pg_catalog.pg_namespace AS n ON n.oid = c.relnamespace ` +
+		`WHERE n.nspname = $1 AND c.relname = $2`
+
+	var valid bool
+	err := querier.QueryRow(ctx, sql, db, index).Scan(&valid)
+	switch {
+	case err == nil:
+		return valid, nil
+	case errors.Is(err, pgx.ErrNoRows):
+		return false, ErrTableNotExist
+	default:
+		return false, lazyerrors.Error(err)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestJSONBIndexName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "users_5e7cc513_jsonb_gin", jsonbIndexName(formatCollectionName("users")))
+
+	long := jsonbIndexName(formatCollectionName(strings.Repeat("a", 100)))
+	assert.LessOrEqual(t, len(long), maxTableNameLength)
+	assert.NotEqual(t, long, jsonbIndexName(formatCollectionName(strings.Repeat("a", 101))))
+}
+
+func TestIDIndexName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "users_5e7cc513_id_", idIndexName(formatCollectionName("users")))
+
+	long := idIndexName(formatCollectionName(strings.Repeat("a", 100)))
+	assert.LessOrEqual(t, len(long), maxTableNameLength)
+	assert.NotEqual(t, long, idIndexName(formatCollectionName(strings.Repeat("a", 101))))
+}
+
+func TestJSONBIndex(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(t, CreateDatabase(ctx, pool, dbName))
+
+	t.Run("Default", func(t *testing.T) {
+		collection := collectionName + "_default"
+		require.NoError(t, CreateCollection(ctx, pool, dbName, collection))
+
+		size, exists, err := JSONBIndexSize(ctx, pool, dbName, collection)
+		require.NoError(t, err)
+		assert.True(t, exists)
+		assert.Positive(t, size)
+
+		// already exists
+		require.NoError(t, EnsureJSONBIndex(ctx, pool, dbName, collection))
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		collection := collectionName + "_disabled"
+		opts := &CreateCollectionOpts{DisableJSONBIndex: true}
+		require.NoError(t, CreateCollectionWithOpts(ctx, pool, dbName, collection, opts))
+
+		_, exists, err := JSONBIndexSize(ctx, pool, dbName, collection)
+		require.NoError(t, err)
+		assert.False(t, exists)
+
+		require.NoError(t, EnsureJSONBIndex(ctx, pool, dbName, collection))
+
+		_, exists, err = JSONBIndexSize(ctx, pool, dbName, collection)
+		require.NoError(t, err)
+		assert.True(t, exists)
+	})
+
+	t.Run("NoCollection", func(t *testing.T) {
+		_, exists, err := JSONBIndexSize(ctx, pool, dbName, collectionName+"_none")
+		require.NoError(t, err)
+		assert.False(t, exists)
+
+		err = EnsureJSONBIndex(ctx, pool, dbName, collectionName+"_none")
+		require.ErrorIs(t, err, ErrTableNotExist)
+	})
+
+	t.Run("Transaction", func(t *testing.T) {
+		err := pool.InTransaction(ctx, func(tx pgx.Tx) error {
+			return EnsureJSONBIndex(ctx, tx, dbName, collectionName+"_default")
+		})
+		require.Error(t, err)
+	})
+}
@@ -15,9 +15,13 @@
 package pgdb
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"strings"
 
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgtype/pgxtype"
 	"github.com/jackc/pgx/v4"
 
@@ -27,35 +31,114 @@ import (
 	"github.com/FerretDB/FerretDB/internal/util/must"
 )
 
+// maxInsertParams is the maximum number of parameters in a single INSERT statement.
+//
+// PostgreSQL wire protocol uses uint16 for the number of parameters.
+const maxInsertParams = 65535
+
 // InsertDocument inserts a document into FerretDB database and collection.
 // If database or collection does not exist, it will be created.
 func InsertDocument(ctx context.Context, querier pgxtype.Querier, db, collection string, doc *types.Document) error {
-	exists, err := CollectionExists(ctx, querier, db, collection)
+	table, err := prepareInsert(ctx, querier, db, collection)
 	if err != nil {
 		return err
 	}
 
-	if !exists {
-		if err := CreateDatabaseIfNotExists(ctx, querier, db); err != nil {
-			return lazyerrors.Error(err)
-		}
+	sql := `INSERT INTO ` + pgx.Identifier{db, table}.Sanitize() +
+		` (_jsonb) VALUES ($1)`
 
-		if err := CreateCollection(ctx, querier, db, collection); err != nil && !errors.Is(err, ErrAlreadyExist) {
-			return lazyerrors.Error(err)
-		}
+	var buf bytes.Buffer
+	if _, err = querier.Exec(ctx, sql, marshalJSONB(&buf, doc)); err != nil {
+		return insertError(err)
 	}
 
-	table, err := getTableName(ctx, querier, db, collection)
+	return nil
+}
+
+// InsertDocuments inserts documents into FerretDB database and collection
+// using multi-row INSERT statements.
+// If database or collection does not exist, it will be created.
+//
+// Documents are inserted in chunks with up to maxInsertParams documents each.
+// If some chunk fails, previous chunks are not rolled back; pass a transaction as querier
+// to make it atomic.
+func InsertDocuments(ctx context.Context, querier pgxtype.Querier, db, collection string, docs []*types.Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	table, err := prepareInsert(ctx, querier, db, collection)
 	if err != nil {
-		return lazyerrors.Error(err)
+		return err
 	}
 
-	sql := `INSERT INTO ` + pgx.Identifier{db, table}.Sanitize() +
-		` (_jsonb) VALUES ($1)`
+	prefix := `INSERT INTO ` + pgx.Identifier{db, table}.Sanitize() + ` (_jsonb) VALUES `
+
+	// the same buffer is reused for all documents
+	var buf bytes.Buffer
+
+	for len(docs) > 0 {
+		n := len(docs)
+		if n > maxInsertParams {
+			n = maxInsertParams
+		}
+
+		var q strings.Builder
+		q.WriteString(prefix)
+
+		var p Placeholder
+		args := make([]any, n)
 
-	if _, err = querier.Exec(ctx, sql, must.NotFail(fjson.Marshal(doc))); err != nil {
-		return lazyerrors.Error(err)
+		for i, doc := range docs[:n] {
+			if i > 0 {
+				q.WriteString(`, `)
+			}
+
+			q.WriteString(`(` + p.Next() + `)`)
+			args[i] = marshalJSONB(&buf, doc)
+		}
+
+		if _, err = querier.Exec(ctx, q.String(), args...); err != nil {
+			return insertError(err)
+		}
+
+		docs = docs[n:]
 	}
 
 	return nil
 }
+
+// marshalJSONB returns fjson encoding of the given document, using buf as a scratch space.
+//
+// JSON is returned as string, not []byte, because the simple protocol encodes []byte as bytea.
+func marshalJSONB(buf *bytes.Buffer, doc *types.Document) string {
+	buf.Reset()
+	must.NoError(fjson.MarshalTo(buf, doc))
+
+	return buf.String()
+}
+
+// insertError converts a unique index violation to ErrUniqueViolation.
+func insertError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+		return lazyerrors.Error(ErrUniqueViolation)
+	}
+
+	return lazyerrors.Error(err)
+}
+
+// prepareInsert creates database and collection if they do not exist,
+// and returns the name of the table for the given collection.
+func prepareInsert(ctx context.Context, querier pgxtype.Querier, db, collection string) (string, error) {
+	if _, err := CreateCollectionIfNotExist(ctx, querier, db, collection); err != nil {
+		return "", lazyerrors.Error(err)
+	}
+
+	table, err := getTableName(ctx, querier, db, collection)
+	if err != nil {
+		return "", lazyerrors.Error(err)
+	}
+
+	return table, nil
+}
@@ -0,0 +1,143 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestInsertDocuments(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+
+	// more than one chunk
+	docs := make([]*types.Document, maxInsertParams+10)
+	for i := range docs {
+		docs[i] = must.NotFail(types.NewDocument("_id", int32(i)))
+	}
+
+	err := pool.InTransaction(ctx, func(tx pgx.Tx) error {
+		return InsertDocuments(ctx, tx, dbName, collectionName, docs)
+	})
+	require.NoError(t, err)
+
+	n, err := CountDocuments(ctx, pool, dbName, collectionName, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(docs)), n)
+
+	err = pool.InTransaction(ctx, func(tx pgx.Tx) error {
+		return InsertDocuments(ctx, tx, dbName, collectionName, nil)
+	})
+	require.NoError(t, err)
+}
+
+func TestInsertDocumentDuplicateID(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+
+	doc := must.NotFail(types.NewDocument("_id", "foo"))
+	require.NoError(t, InsertDocument(ctx, pool, dbName, collectionName, doc))
+
+	err := InsertDocument(ctx, pool, dbName, collectionName, doc)
+	require.ErrorIs(t, err, ErrUniqueViolation)
+
+	docs := []*types.Document{must.NotFail(types.NewDocument("_id", "bar")), doc}
+	err = pool.InTransaction(ctx, func(tx pgx.Tx) error {
+		return InsertDocuments(ctx, tx, dbName, collectionName, docs)
+	})
+	require.ErrorIs(t, err, ErrUniqueViolation)
+
+	n, err := CountDocuments(ctx, pool, dbName, collectionName, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+}
+
+func BenchmarkInsertDocuments(b *testing.B) {
+	ctx := testutil.Ctx(b)
+
+	pool := getPool(ctx, b, zaptest.NewLogger(b))
+	dbName := testutil.DatabaseName(b)
+
+	b.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(b, CreateDatabase(ctx, pool, dbName))
+
+	docs := make([]*types.Document, 10_000)
+	for i := range docs {
+		docs[i] = must.NotFail(types.NewDocument("_id", int32(i), "v", fmt.Sprintf("value %d", i)))
+	}
+
+	b.Run("Batch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			collectionName := fmt.Sprintf("batch_%d", i)
+
+			err := pool.InTransaction(ctx, func(tx pgx.Tx) error {
+				return InsertDocuments(ctx, tx, dbName, collectionName, docs)
+			})
+			require.NoError(b, err)
+		}
+	})
+
+	b.Run("OneByOne", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			collectionName := fmt.Sprintf("one_by_one_%d", i)
+
+			err := pool.InTransaction(ctx, func(tx pgx.Tx) error {
+				for _, doc := range docs {
+					if err := InsertDocument(ctx, tx, dbName, collectionName, doc); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			require.NoError(b, err)
+		}
+	})
+}
@@ -0,0 +1,166 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/jackc/pgx/v4"
+
+	"github.com/FerretDB/FerretDB/internal/fjson"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// iteratorFetchSize is the number of rows fetched from the cursor at once.
+const iteratorFetchSize = 100
+
+// lastCursorID is used to generate unique cursor names, accessed atomically.
+var lastCursorID uint64
+
+// Iterator represents a streaming iterator over documents returned by QueryIterator.
+//
+// It is backed by PostgreSQL cursor, so only a small batch of documents is kept in memory.
+// The transaction used to create an iterator must not be committed or rolled back until Close is called.
+// Iterator is not safe for concurrent use.
+type Iterator struct {
+	tx     pgx.Tx
+	cursor string // empty if collection does not exist
+	buf    []*types.Document
+	done   bool
+	closed bool
+}
+
+// QueryIterator returns an iterator over documents of the given FerretDB database and collection.
+//
+// If the collection doesn't exist, it returns an iterator without documents and no error.
+// The caller should always call Close.
+//
+// The returned QueryResults is always non-nil.
+func QueryIterator(ctx context.Context, tx pgx.Tx, sp SQLParam) (*Iterator, *QueryResults, error) {
+	q, args, res, err := buildQuery(ctx, tx, &sp)
+	if err != nil {
+		if errors.Is(err, ErrTableNotExist) {
+			return &Iterator{done: true}, new(QueryResults), nil
+		}
+		return nil, new(QueryResults), lazyerrors.Error(err)
+	}
+
+	cursor := fmt.Sprintf("ferretdb_cursor_%d", atomic.AddUint64(&lastCursorID, 1))
+
+	if _, err = tx.Exec(ctx, `DECLARE `+pgx.Identifier{cursor}.Sanitize()+` NO SCROLL CURSOR FOR `+q, args...); err != nil {
+		return nil, new(QueryResults), lazyerrors.Error(err)
+	}
+
+	it := &Iterator{
+		tx:     tx,
+		cursor: cursor,
+	}
+
+	return it, res, nil
+}
+
+// Next returns the next document.
+//
+// It returns ErrIteratorDone when there are no more documents.
+// Context cancellation is returned as an error.
+func (it *Iterator) Next(ctx context.Context) (*types.Document, error) {
+	if it.closed {
+		return nil, ErrIteratorDone
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(it.buf) == 0 {
+		if it.done {
+			return nil, ErrIteratorDone
+		}
+
+		if err := it.fetch(ctx); err != nil {
+			return nil, err
+		}
+
+		if len(it.buf) == 0 {
+			return nil, ErrIteratorDone
+		}
+	}
+
+	doc := it.buf[0]
+	it.buf[0] = nil
+	it.buf = it.buf[1:]
+
+	return doc, nil
+}
+
+// fetch fetches the next batch of documents from the cursor.
+func (it *Iterator) fetch(ctx context.Context) error {
+	sql := fmt.Sprintf(`FETCH %d FROM %s`, iteratorFetchSize, pgx.Identifier{it.cursor}.Sanitize())
+	rows, err := it.tx.Query(ctx, sql)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+	defer rows.Close()
+
+	it.buf = make([]*types.Document, 0, iteratorFetchSize)
+
+	for rows.Next() {
+		var b []byte
+		if err = rows.Scan(&b); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		doc, err := fjson.Unmarshal(b)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		it.buf = append(it.buf, doc.(*types.Document))
+	}
+
+	if err = rows.Err(); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	if len(it.buf) < iteratorFetchSize {
+		it.done = true
+	}
+
+	return nil
+}
+
+// Close closes the iterator and the underlying cursor.
+//
+// It is safe to call it multiple times and after context cancellation
+// (in that case, the transaction is aborted anyway, and the cursor is closed with it).
+func (it *Iterator) Close() {
+	if it.closed {
+		return
+	}
+
+	it.closed = true
+	it.buf = nil
+
+	if it.cursor == "" {
+		return
+	}
+
+	// errors are ignored: the cursor is closed at the end of the transaction anyway
+	_, _ = it.tx.Exec(context.Background(), `CLOSE `+pgx.Identifier{it.cursor}.Sanitize())
+}
@@ -0,0 +1,217 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestQueryIterator(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(t, CreateDatabase(ctx, pool, dbName))
+
+	// more than one fetch batch
+	docs := make([]*types.Document, iteratorFetchSize*2+1)
+	for i := range docs {
+		docs[i] = must.NotFail(types.NewDocument("_id", int32(i)))
+	}
+
+	err := pool.InTransaction(ctx, func(tx pgx.Tx) error {
+		return InsertDocuments(ctx, tx, dbName, collectionName, docs)
+	})
+	require.NoError(t, err)
+
+	t.Run("NonExistentCollection", func(t *testing.T) {
+		t.Parallel()
+
+		err := pool.InTransaction(ctx, func(tx pgx.Tx) error {
+			iter, _, err := QueryIterator(ctx, tx, SQLParam{DB: dbName, Collection: "no_such_collection"})
+			require.NoError(t, err)
+			defer iter.Close()
+
+			doc, err := iter.Next(ctx)
+			assert.Nil(t, doc)
+			assert.Equal(t, ErrIteratorDone, err)
+
+			return nil
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("All", func(t *testing.T) {
+		t.Parallel()
+
+		err := pool.InTransaction(ctx, func(tx pgx.Tx) error {
+			iter, _, err := QueryIterator(ctx, tx, SQLParam{DB: dbName, Collection: collectionName})
+			require.NoError(t, err)
+			defer iter.Close()
+
+			var n int
+			for {
+				_, err = iter.Next(ctx)
+				if errors.Is(err, ErrIteratorDone) {
+					break
+				}
+				require.NoError(t, err)
+				n++
+			}
+
+			assert.Equal(t, len(docs), n)
+
+			// iterator stays exhausted
+			_, err = iter.Next(ctx)
+			assert.Equal(t, ErrIteratorDone, err)
+
+			return nil
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("EarlyClose", func(t *testing.T) {
+		t.Parallel()
+
+		err := pool.InTransaction(ctx, func(tx pgx.Tx) error {
+			iter, _, err := QueryIterator(ctx, tx, SQLParam{DB: dbName, Collection: collectionName})
+			require.NoError(t, err)
+
+			doc, err := iter.Next(ctx)
+			require.NoError(t, err)
+			assert.NotNil(t, doc)
+
+			iter.Close()
+			iter.Close()
+
+			_, err = iter.Next(ctx)
+			assert.Equal(t, ErrIteratorDone, err)
+
+			// transaction is still usable
+			var one int
+			return tx.QueryRow(ctx, `SELECT 1`).Scan(&one)
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("CanceledContext", func(t *testing.T) {
+		t.Parallel()
+
+		err := pool.InTransaction(ctx, func(tx pgx.Tx) error {
+			iter, _, err := QueryIterator(ctx, tx, SQLParam{DB: dbName, Collection: collectionName})
+			require.NoError(t, err)
+			defer iter.Close()
+
+			cancelCtx, cancel := context.WithCancel(ctx)
+			cancel()
+
+			_, err = iter.Next(cancelCtx)
+			assert.ErrorIs(t, err, context.Canceled)
+
+			return nil
+		})
+		require.NoError(t, err)
+	})
+}
+
+func BenchmarkQueryIterator(b *testing.B) {
+	ctx := testutil.Ctx(b)
+
+	pool := getPool(ctx, b, zaptest.NewLogger(b))
+	dbName := testutil.DatabaseName(b)
+	collectionName := testutil.CollectionName(b)
+
+	b.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(b, CreateDatabase(ctx, pool, dbName))
+
+	docs := make([]*types.Document, 10_000)
+	for i := range docs {
+		docs[i] = must.NotFail(types.NewDocument("_id", int32(i)))
+	}
+
+	err := pool.InTransaction(ctx, func(tx pgx.Tx) error {
+		return InsertDocuments(ctx, tx, dbName, collectionName, docs)
+	})
+	require.NoError(b, err)
+
+	sp := SQLParam{DB: dbName, Collection: collectionName}
+
+	// both variants read the first document only;
+	// the iterator does not read the rest of the collection
+	b.Run("Iterator", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			err := pool.InTransaction(ctx, func(tx pgx.Tx) error {
+				iter, _, err := QueryIterator(ctx, tx, sp)
+				if err != nil {
+					return err
+				}
+				defer iter.Close()
+
+				_, err = iter.Next(ctx)
+				return err
+			})
+			require.NoError(b, err)
+		}
+	})
+
+	b.Run("Channel", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			err := pool.InTransaction(ctx, func(tx pgx.Tx) error {
+				fetchedChan, _, err := pool.QueryDocuments(ctx, tx, sp)
+				if err != nil {
+					return err
+				}
+
+				// channel must be drained to the end even if only the first document is needed
+				for fetchedItem := range fetchedChan {
+					if fetchedItem.Err != nil {
+						return fetchedItem.Err
+					}
+				}
+
+				return nil
+			})
+			require.NoError(b, err)
+		}
+	})
+}
@@ -0,0 +1,73 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"context"
+	"hash/fnv"
+	"strings"
+
+	"github.com/jackc/pgtype/pgxtype"
+
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// collectionLockKey returns a key of PostgreSQL advisory lock for the given FerretDB collection.
+func collectionLockKey(db, collection string) int64 {
+	h := fnv.New64a()
+	_ = must.NotFail(h.Write([]byte(db)))
+	_ = must.NotFail(h.Write([]byte{0}))
+	_ = must.NotFail(h.Write([]byte(collection)))
+
+	return int64(h.Sum64())
+}
+
+// lockCollection acquires a transaction-level advisory lock for the given FerretDB collection.
+//
+// It serializes concurrent creations of the same collection (or database and collection),
+// so the settings table and the collection's table can't diverge.
+// The lock is released at the end of the transaction; querier should be a transaction.
+// Transaction-level locks are compatible with PgBouncer in transaction pooling mode.
+func lockCollection(ctx context.Context, querier pgxtype.Querier, db, collection string) error {
+	if _, err := querier.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, collectionLockKey(db, collection)); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// lockSettings acquires a transaction-level advisory lock for the settings of the given FerretDB database.
+//
+// It serializes settings migrations; see lockCollection for details.
+func lockSettings(ctx context.Context, querier pgxtype.Querier, db string) error {
+	if _, err := querier.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, collectionLockKey(db, settingsTableName)); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// lockDatabase acquires a transaction-level advisory lock for the given FerretDB database name.
+//
+// The name is lowercased, so creations of databases with names that differ only in case are serialized too;
+// see lockCollection for details.
+func lockDatabase(ctx context.Context, querier pgxtype.Querier, db string) error {
+	if _, err := querier.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, collectionLockKey(strings.ToLower(db), "")); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgtype/pgxtype"
+	"github.com/jackc/pgx/v4"
+	"golang.org/x/exp/slices"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// settingsVersion is the current version of FerretDB settings layout.
+//
+// It is stored in the "version" field of the settings document;
+// settings without that field have version 0.
+const settingsVersion = int32(1)
+
+// settingsMigration upgrades settings document of a single database by one version in place.
+// It is called in a transaction, and may also change other objects of the database.
+type settingsMigration func(ctx context.Context, tx pgx.Tx, db string, settings *types.Document) error
+
+// settingsMigrations contains all migrations in order:
+// settingsMigrations[i] upgrades settings from version i to version i+1.
+var settingsMigrations = []settingsMigration{
+	migrateSettingsV1,
+}
+
+// newSettings returns a new settings document of the current version with the given collections mapping.
+func newSettings(collections *types.Document) *types.Document {
+	return must.NotFail(types.NewDocument(
+		"collections", collections,
+		"version", settingsVersion,
+	))
+}
+
+// getSettingsVersion returns the version of the given settings document.
+func getSettingsVersion(settings *types.Document) (int32, error) {
+	if !settings.Has("version") {
+		return 0, nil
+	}
+
+	version, ok := must.NotFail(settings.Get("version")).(int32)
+	if !ok || version < 0 {
+		return 0, lazyerrors.Errorf("invalid settings version: %v", must.NotFail(settings.Get("version")))
+	}
+
+	return version, nil
+}
+
+// checkSettingsVersion returns true if the given settings document should be migrated.
+//
+// It returns (possibly wrapped) ErrSettingsVersion if settings were created by a newer FerretDB version.
+func checkSettingsVersion(db string, settings *types.Document) (bool, error) {
+	version, err := getSettingsVersion(settings)
+	if err != nil {
+		return false, err
+	}
+
+	if version > settingsVersion {
+		return false, fmt.Errorf(
+			"%w: database %q has settings version %d, but this FerretDB version supports only versions up to %d",
+			ErrSettingsVersion, db, version, settingsVersion,
+		)
+	}
+
+	return version < settingsVersion, nil
+}
+
+// migrateSettings upgrades settings of the given database to the current version.
+//
+// Migrations are run in a single transaction under an advisory lock,
+// so concurrent clients (and FerretDB instances) migrate each database only once.
+func migrateSettings(ctx context.Context, querier pgxtype.Querier, db string) error {
+	return InTransaction(ctx, querier, func(tx pgx.Tx) error {
+		if err := lockSettings(ctx, tx, db); err != nil {
+			return err
+		}
+
+		// re-read after acquiring the lock: settings might be migrated by a concurrent client
+		settings, err := readSettingsDocument(ctx, tx, db, true)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		migrate, err := checkSettingsVersion(db, settings)
+		if err != nil || !migrate {
+			return err
+		}
+
+		version := must.NotFail(getSettingsVersion(settings))
+		for ; version < settingsVersion; version++ {
+			if err = settingsMigrations[version](ctx, tx, db, settings); err != nil {
+				return lazyerrors.Errorf("migration of database %q to settings version %d: %w", db, version+1, err)
+			}
+
+			must.NoError(settings.Set("version", version+1))
+		}
+
+		return updateSettingsTable(ctx, tx, db, settings)
+	})
+}
+
+// migrateSettingsV1 upgrades settings from version 0 (without version field) to version 1.
+//
+// It validates the collections mapping and sorts it by collection names.
+func migrateSettingsV1(ctx context.Context, tx pgx.Tx, db string, settings *types.Document) error {
+	collectionsDoc := must.NotFail(settings.Get("collections"))
+	collections, ok := collectionsDoc.(*types.Document)
+	if !ok {
+		return lazyerrors.Errorf("expected document but got %[1]T: %[1]v", collectionsDoc)
+	}
+
+	names := slices.Clone(collections.Keys())
+	slices.Sort(names)
+
+	sorted := types.MakeDocument(len(names))
+	for _, name := range names {
+		table, ok := must.NotFail(collections.Get(name)).(string)
+		if !ok {
+			return lazyerrors.Errorf("invalid table name for collection %q", name)
+		}
+
+		must.NoError(sorted.Set(name, table))
+	}
+
+	must.NoError(settings.Set("collections", sorted))
+
+	return nil
+}
@@ -0,0 +1,133 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jackc/pgtype/pgxtype"
+
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// maxDatabaseNameLength is the maximum length of database name in bytes.
+//
+// It is stricter than MongoDB's limit of 64 bytes
+// because database names are used as PostgreSQL schema names as is.
+const maxDatabaseNameLength = 63
+
+// maxNamespaceLength is the maximum length of the full "<database>.<collection>" namespace in bytes,
+// the same as in MongoDB.
+//
+// Table names are shortened to PostgreSQL identifier limit by formatCollectionName;
+// the full collection name is kept in the settings table.
+const maxNamespaceLength = 255
+
+// databaseNameForbiddenChars contains characters that MongoDB does not allow in database names.
+const databaseNameForbiddenChars = "/\\. \"$\x00"
+
+// validateDatabaseName checks that the given database name conforms to MongoDB rules and FerretDB restrictions.
+//
+// It returns (possibly wrapped) ErrInvalidDatabaseName with the reason if it does not.
+func validateDatabaseName(db string) error {
+	switch {
+	case db == "":
+		return fmt.Errorf("%w: database name is empty", ErrInvalidDatabaseName)
+
+	case len(db) > maxDatabaseNameLength:
+		return fmt.Errorf("%w: %q is longer than %d bytes", ErrInvalidDatabaseName, db, maxDatabaseNameLength)
+
+	case !utf8.ValidString(db):
+		return fmt.Errorf("%w: %q is not a valid UTF-8 string", ErrInvalidDatabaseName, db)
+
+	case strings.ContainsAny(db, databaseNameForbiddenChars):
+		return fmt.Errorf("%w: %q contains forbidden characters", ErrInvalidDatabaseName, db)
+
+	case strings.HasPrefix(db, reservedPrefix), strings.HasPrefix(strings.ToLower(db), "pg_"):
+		// pg_ prefix is reserved by PostgreSQL for system schemas
+		return fmt.Errorf("%w: %q starts with a reserved prefix", ErrInvalidDatabaseName, db)
+	}
+
+	return nil
+}
+
+// collectionNameForbiddenChars contains characters that MongoDB does not allow in collection names.
+//
+// Dots are allowed: collection names like "a.b.c" are valid, and they are never split
+// because database names can't contain dots.
+const collectionNameForbiddenChars = "$\x00"
+
+// validateCollectionName checks that the given collection name conforms to MongoDB rules and FerretDB restrictions.
+// The database name is used only to check the namespace length.
+//
+// It returns (possibly wrapped) ErrInvalidTableName with the reason if it does not.
+func validateCollectionName(db, collection string) error {
+	switch {
+	case collection == "":
+		return fmt.Errorf("%w: collection name is empty", ErrInvalidTableName)
+
+	case len(db)+1+len(collection) > maxNamespaceLength:
+		return fmt.Errorf(
+			"%w: namespace %q is longer than %d bytes",
+			ErrInvalidTableName, db+"."+collection, maxNamespaceLength,
+		)
+
+	case !utf8.ValidString(collection):
+		return fmt.Errorf("%w: %q is not a valid UTF-8 string", ErrInvalidTableName, collection)
+
+	case strings.ContainsAny(collection, collectionNameForbiddenChars):
+		return fmt.Errorf("%w: %q contains forbidden characters", ErrInvalidTableName, collection)
+
+	case strings.HasPrefix(collection, "system."):
+		return fmt.Errorf("%w: %q is reserved for system collections", ErrInvalidTableName, collection)
+
+	case strings.HasPrefix(collection, reservedPrefix):
+		return fmt.Errorf("%w: %q starts with a reserved prefix", ErrInvalidTableName, collection)
+	}
+
+	return nil
+}
+
+// checkDatabaseNameCase checks that there is no FerretDB database / PostgreSQL schema
+// with the name that differs from the given one only in case.
+// PostgreSQL schema names are case-sensitive, but MongoDB does not allow such databases.
+//
+// It returns (possibly wrapped) ErrInvalidDatabaseName naming both databases if there is one.
+func checkDatabaseNameCase(ctx context.Context, querier pgxtype.Querier, db string) error {
+	sql := `SELECT nspname FROM pg_catalog.pg_namespace WHERE lower(nspname) = lower($1) AND nspname <> $1 LIMIT 1`
+	rows, err := querier.Query(ctx, sql, db)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		return nil
+	}
+
+	var existing string
+	if err = rows.Scan(&existing); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	return fmt.Errorf("%w: database %q differs only in case from existing database %q", ErrInvalidDatabaseName, db, existing)
+}
@@ -0,0 +1,112 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateDatabaseName(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		db    string
+		valid bool
+	}{
+		"Simple":           {db: "test", valid: true},
+		"Uppercase":        {db: "Test", valid: true},
+		"Dash":             {db: "test-db", valid: true},
+		"Unicode":          {db: "тест", valid: true},
+		"MaxLength":        {db: strings.Repeat("a", maxDatabaseNameLength), valid: true},
+		"Empty":            {db: ""},
+		"TooLong":          {db: strings.Repeat("a", maxDatabaseNameLength+1)},
+		"TooLongBytes":     {db: strings.Repeat("я", maxDatabaseNameLength/2+1)},
+		"InvalidUTF8":      {db: "test\xff"},
+		"Slash":            {db: "test/db"},
+		"Backslash":        {db: `test\db`},
+		"Dot":              {db: "test.db"},
+		"Space":            {db: "test db"},
+		"DoubleQuote":      {db: `test"db`},
+		"Dollar":           {db: "test$db"},
+		"NullByte":         {db: "test\x00db"},
+		"ReservedPrefix":   {db: reservedPrefix + "test"},
+		"PostgreSQLPrefix": {db: "PG_test"},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateDatabaseName(tc.db)
+			if tc.valid {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, ErrInvalidDatabaseName)
+			if tc.db != "" {
+				assert.Contains(t, err.Error(), strconv.Quote(tc.db), "error should name the database")
+			}
+		})
+	}
+}
+
+func TestValidateCollectionName(t *testing.T) {
+	t.Parallel()
+
+	const db = "testdb"
+
+	// the longest collection name for that database
+	maxName := strings.Repeat("a", maxNamespaceLength-len(db)-1)
+
+	for name, tc := range map[string]struct {
+		collection string
+		valid      bool
+	}{
+		"Simple":         {collection: "test", valid: true},
+		"Dots":           {collection: "a.b.c", valid: true},
+		"LeadingDigit":   {collection: "1test", valid: true},
+		"Space":          {collection: "test collection", valid: true},
+		"Unicode":        {collection: "коллекция_日本語", valid: true},
+		"SystemInside":   {collection: "my.system.test", valid: true},
+		"MaxLength":      {collection: maxName, valid: true},
+		"MaxLengthBytes": {collection: strings.Repeat("я", len(maxName)/2), valid: true},
+		"TooLong":        {collection: maxName + "a"},
+		"TooLongBytes":   {collection: strings.Repeat("я", len(maxName)/2+1)},
+		"Empty":          {collection: ""},
+		"InvalidUTF8":    {collection: "test\xff"},
+		"Dollar":         {collection: "test$"},
+		"NullByte":       {collection: "test\x00"},
+		"System":         {collection: "system.test"},
+		"ReservedPrefix": {collection: reservedPrefix + "test"},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateCollectionName(db, tc.collection)
+			if tc.valid {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, ErrInvalidTableName)
+		})
+	}
+}
@@ -0,0 +1,263 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgtype/pgxtype"
+	"github.com/jackc/pgx/v4"
+
+	"github.com/FerretDB/FerretDB/internal/fjson"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// DefaultChangesRetention is the default time recorded changes are kept for.
+const DefaultChangesRetention = 24 * time.Hour
+
+// oplogTableName is the name of the table that contains recorded changes of all collections in the database.
+const oplogTableName = reservedPrefix + "oplog"
+
+// ChangeOperation represents the type of the recorded change.
+type ChangeOperation string
+
+// Recorded change types.
+const (
+	ChangeInsert  = ChangeOperation("insert")
+	ChangeUpdate  = ChangeOperation("update")
+	ChangeReplace = ChangeOperation("replace")
+	ChangeDelete  = ChangeOperation("delete")
+)
+
+// Change represents a single recorded change of a single document.
+type Change struct {
+	// ID is assigned by PostgreSQL; it grows in commit order within the database.
+	ID int64
+
+	// ClusterTime is the time of the change, assigned by PostgreSQL.
+	ClusterTime time.Time
+
+	Collection  string
+	Operation   ChangeOperation
+	DocumentKey any // _id value
+
+	// FullDocument is set for inserts and replacements only.
+	FullDocument *types.Document
+}
+
+// knownOplogTables contains names of databases known to have the change table.
+var knownOplogTables sync.Map
+
+// RecordChanges records the given changes of documents in the database's change table,
+// creating that table if needed.
+//
+// It should be called in the same transaction that makes those changes, so the change table is always consistent
+// with collections. Concurrent writers to the same database are serialized until the end of their transactions,
+// so change IDs are assigned in commit order and readers never miss a change with a smaller ID.
+func RecordChanges(ctx context.Context, tx pgx.Tx, db string, changes []Change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	// the same lock serializes both table creation and writes
+	if err := lockCollection(ctx, tx, db, oplogTableName); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	table := pgx.Identifier{db, oplogTableName}.Sanitize()
+
+	if _, ok := knownOplogTables.Load(db); !ok {
+		sql := `CREATE TABLE IF NOT EXISTS ` + table + ` (` +
+			`id bigserial PRIMARY KEY, ` +
+			`cluster_time timestamptz NOT NULL DEFAULT now(), ` +
+			`collection text NOT NULL, ` +
+			`operation text NOT NULL, ` +
+			`document_key jsonb NOT NULL, ` +
+			`full_document jsonb` +
+			`)`
+		if _, err := tx.Exec(ctx, sql); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		knownOplogTables.Store(db, struct{}{})
+	}
+
+	var p Placeholder
+	values := make([]string, len(changes))
+	args := make([]any, 0, len(changes)*4)
+
+	for i, c := range changes {
+		values[i] = `(` + p.Next() + `, ` + p.Next() + `, ` + p.Next() + `::jsonb, ` + p.Next() + `::jsonb)`
+
+		// JSON is passed as string, not []byte, because the simple protocol encodes []byte as bytea
+		var fullDocument *string
+		if c.FullDocument != nil {
+			s := string(must.NotFail(fjson.Marshal(c.FullDocument)))
+			fullDocument = &s
+		}
+
+		args = append(args, c.Collection, string(c.Operation), string(must.NotFail(fjson.Marshal(c.DocumentKey))), fullDocument)
+	}
+
+	sql := `INSERT INTO ` + table + ` (collection, operation, document_key, full_document) VALUES ` +
+		strings.Join(values, ", ")
+
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		// the table was dropped with the database or not committed; the caller's transaction could be retried
+		if IsUndefinedObject(err) {
+			knownOplogTables.Delete(db)
+		}
+
+		return lazyerrors.Error(err)
+	}
+
+	return nil
+}
+
+// LastChangeID returns the ID of the last recorded change in the database, or 0 if there are none.
+func LastChangeID(ctx context.Context, querier pgxtype.Querier, db string) (int64, error) {
+	sql := `SELECT COALESCE(max(id), 0) FROM ` + pgx.Identifier{db, oplogTableName}.Sanitize()
+
+	var id int64
+	err := querier.QueryRow(ctx, sql).Scan(&id)
+	switch {
+	case err == nil:
+		return id, nil
+	case IsUndefinedObject(err):
+		return 0, nil
+	default:
+		return 0, lazyerrors.Error(err)
+	}
+}
+
+// ListChanges returns up to limit changes in the database after the change with the given ID, ordered by ID.
+//
+// If collection is not empty, only changes of that collection are returned.
+// If there are no changes yet, an empty slice is returned.
+func ListChanges(ctx context.Context, querier pgxtype.Querier, db, collection string, afterID int64, limit int) ([]Change, error) { //nolint:lll // argument list is too long
+	var p Placeholder
+	sql := `SELECT id, cluster_time, collection, operation, document_key, full_document ` +
+		`FROM ` + pgx.Identifier{db, oplogTableName}.Sanitize() + ` WHERE id > ` + p.Next()
+	args := []any{afterID}
+
+	if collection != "" {
+		sql += ` AND collection = ` + p.Next()
+		args = append(args, collection)
+	}
+
+	sql += ` ORDER BY id LIMIT ` + p.Next()
+	args = append(args, limit)
+
+	res := []Change{}
+
+	rows, err := querier.Query(ctx, sql, args...)
+	if err != nil {
+		if IsUndefinedObject(err) {
+			return res, nil
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c Change
+		var operation string
+		var documentKey, fullDocument []byte
+		if err = rows.Scan(&c.ID, &c.ClusterTime, &c.Collection, &operation, &documentKey, &fullDocument); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		c.Operation = ChangeOperation(operation)
+		c.ClusterTime = c.ClusterTime.UTC()
+
+		if c.DocumentKey, err = fjson.Unmarshal(documentKey); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if fullDocument != nil {
+			doc, err := fjson.Unmarshal(fullDocument)
+			if err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+
+			c.FullDocument = doc.(*types.Document)
+		}
+
+		res = append(res, c)
+	}
+
+	if err = rows.Err(); err != nil {
+		if IsUndefinedObject(err) {
+			return res, nil
+		}
+
+		return nil, lazyerrors.Error(err)
+	}
+
+	return res, nil
+}
+
+// TrimChanges removes changes recorded before the given time from change tables of all databases.
+//
+// It returns the number of removed changes.
+func TrimChanges(ctx context.Context, querier pgxtype.Querier, before time.Time) (int64, error) {
+	rows, err := querier.Query(ctx, `SELECT schemaname FROM pg_catalog.pg_tables WHERE tablename = $1`, oplogTableName)
+	if err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	var dbs []string
+	for rows.Next() {
+		var db string
+		if err = rows.Scan(&db); err != nil {
+			rows.Close()
+			return 0, lazyerrors.Error(err)
+		}
+
+		dbs = append(dbs, db)
+	}
+
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	var res int64
+
+	for _, db := range dbs {
+		sql := `DELETE FROM ` + pgx.Identifier{db, oplogTableName}.Sanitize() + ` WHERE cluster_time < $1`
+
+		tag, err := querier.Exec(ctx, sql, before)
+		if err != nil {
+			// the database could be dropped concurrently
+			if IsUndefinedObject(err) {
+				continue
+			}
+
+			return res, lazyerrors.Error(err)
+		}
+
+		res += tag.RowsAffected()
+	}
+
+	return res, nil
+}
@@ -0,0 +1,124 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestChanges(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(t, CreateDatabaseIfNotExists(ctx, pool, dbName))
+
+	t.Run("Empty", func(t *testing.T) {
+		id, err := LastChangeID(ctx, pool, dbName)
+		require.NoError(t, err)
+		assert.Zero(t, id)
+
+		changes, err := ListChanges(ctx, pool, dbName, "", 0, 10)
+		require.NoError(t, err)
+		assert.Empty(t, changes)
+	})
+
+	doc := must.NotFail(types.NewDocument("_id", int32(1), "v", "foo"))
+
+	err := pool.InTransaction(ctx, func(tx pgx.Tx) error {
+		return RecordChanges(ctx, tx, dbName, []Change{
+			{Collection: collectionName, Operation: ChangeInsert, DocumentKey: int32(1), FullDocument: doc},
+			{Collection: "other", Operation: ChangeInsert, DocumentKey: "other"},
+		})
+	})
+	require.NoError(t, err)
+
+	err = pool.InTransaction(ctx, func(tx pgx.Tx) error {
+		return RecordChanges(ctx, tx, dbName, []Change{
+			{Collection: collectionName, Operation: ChangeDelete, DocumentKey: int32(1)},
+		})
+	})
+	require.NoError(t, err)
+
+	t.Run("List", func(t *testing.T) {
+		changes, err := ListChanges(ctx, pool, dbName, "", 0, 10)
+		require.NoError(t, err)
+		require.Len(t, changes, 3)
+
+		for i := 1; i < len(changes); i++ {
+			assert.Less(t, changes[i-1].ID, changes[i].ID)
+		}
+
+		assert.Equal(t, collectionName, changes[0].Collection)
+		assert.Equal(t, ChangeInsert, changes[0].Operation)
+		assert.Equal(t, int32(1), changes[0].DocumentKey)
+		assert.Equal(t, doc, changes[0].FullDocument)
+		assert.WithinDuration(t, time.Now(), changes[0].ClusterTime, time.Minute)
+
+		assert.Equal(t, "other", changes[1].Collection)
+		assert.Nil(t, changes[1].FullDocument)
+
+		assert.Equal(t, ChangeDelete, changes[2].Operation)
+
+		last, err := LastChangeID(ctx, pool, dbName)
+		require.NoError(t, err)
+		assert.Equal(t, changes[2].ID, last)
+
+		changes, err = ListChanges(ctx, pool, dbName, collectionName, changes[0].ID, 10)
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+		assert.Equal(t, ChangeDelete, changes[0].Operation)
+
+		changes, err = ListChanges(ctx, pool, dbName, "", 0, 1)
+		require.NoError(t, err)
+		assert.Len(t, changes, 1)
+	})
+
+	t.Run("Trim", func(t *testing.T) {
+		n, err := TrimChanges(ctx, pool, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, n, int64(0))
+
+		changes, err := ListChanges(ctx, pool, dbName, "", 0, 10)
+		require.NoError(t, err)
+		assert.Len(t, changes, 3)
+
+		_, err = TrimChanges(ctx, pool, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+
+		changes, err = ListChanges(ctx, pool, dbName, "", 0, 10)
+		require.NoError(t, err)
+		assert.Empty(t, changes)
+	})
+}
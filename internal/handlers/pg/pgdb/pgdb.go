@@ -35,4 +35,20 @@ var (
 
 	// ErrInvalidDatabaseName indicates that a database name didn't passed checks.
 	ErrInvalidDatabaseName = fmt.Errorf("invalid database name")
+
+	// ErrPoolExhausted indicates that all pool connections are in use
+	// and no connection became available in time.
+	ErrPoolExhausted = fmt.Errorf("connection pool exhausted")
+
+	// ErrSettingsVersion indicates that the database was created or upgraded by a newer FerretDB version.
+	ErrSettingsVersion = fmt.Errorf("unsupported settings version")
+
+	// ErrUniqueViolation indicates that a document with the same _id already exists.
+	ErrUniqueViolation = fmt.Errorf("duplicate _id")
+
+	// ErrIteratorDone is returned by Iterator.Next when there are no more documents.
+	ErrIteratorDone = fmt.Errorf("iterator is read to the end")
+
+	// ErrAuthenticationFailed indicates that PostgreSQL rejected given credentials.
+	ErrAuthenticationFailed = fmt.Errorf("authentication failed")
 )
@@ -16,30 +16,62 @@ package pgdb
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strings"
-
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgtype/pgxtype"
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/log/zapadapter"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"go.uber.org/zap"
 
 	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/ctxutil"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 )
 
+// Transaction retry parameters for InTransactionRetry.
 const (
-	// Supported encoding.
-	encUTF8 = "UTF8"
-
-	// Supported locales: (For more info see: https://www.gnu.org/software/libc/manual/html_node/Standard-Locales.html)
-	localeC     = "C"
-	localePOSIX = "POSIX"
+	maxTransactionRetries = 5
+	transactionRetryDelay = 10 * time.Millisecond
 )
 
 // Pool represents PostgreSQL concurrency-safe connection pool.
 type Pool struct {
 	*pgxpool.Pool
+
+	// transactionRetries is the total number of InTransactionRetry retries, accessed atomically.
+	transactionRetries int64
+
+	acquireTimeout time.Duration
+
+	queryMetrics *queryMetrics
+
+	// settingsCache caches settings of FerretDB databases accessed through that pool.
+	settingsCache *settingsCache
+
+	// reportedRelations contains "schema.relation" keys of relations already reported by reportInvalidRelation.
+	reportedRelations sync.Map
+
+	logger              *zap.Logger
+	minServerVersionNum int
+
+	// health is protected by healthM, see health.go.
+	healthM      sync.Mutex
+	health       Health
+	healthWakeup chan struct{}
+	stopHealth   context.CancelFunc // nil if health checks are disabled
+	healthDone   chan struct{}
+
+	// serverInfo is set by the first successful server check, protected by serverInfoM.
+	serverInfoM sync.Mutex
+	serverInfo  *ServerInfo
 }
 
 // DBStats describes statistics for a database.
@@ -53,32 +85,62 @@ type DBStats struct {
 	CountIndexes int32
 }
 
+// NewPoolOpts represents connection pool configuration.
+//
+// Zero values mean pgxpool defaults (or values from the connection string).
+type NewPoolOpts struct {
+	// Lazy makes pool connection to PostgreSQL lazily; connection settings are not checked.
+	Lazy bool
+
+	MaxConns        int32
+	MinConns        int32
+	MaxConnLifetime time.Duration
+	MaxConnIdleTime time.Duration
+
+	// SimpleProtocol makes pool use PostgreSQL simple query protocol without prepared statements
+	// and session-level settings. It is required for PgBouncer in transaction pooling mode.
+	SimpleProtocol bool
+
+	// AcquireTimeout limits the time spent waiting for a free connection in InTransaction.
+	// If it is exceeded, ErrPoolExhausted is returned.
+	AcquireTimeout time.Duration
+
+	// MinServerVersionNum is the minimal supported PostgreSQL version in server_version_num format
+	// (for example, 120000 for PostgreSQL 12). Zero means DefaultMinServerVersionNum.
+	MinServerVersionNum int
+
+	// HealthCheckInterval is the interval between background checks of PostgreSQL availability.
+	// Zero means DefaultHealthCheckInterval; negative value disables checks.
+	HealthCheckInterval time.Duration
+}
+
 // NewPool returns a new concurrency-safe connection pool.
 //
 // Passed context is used only by the first checking connection.
 // Canceling it after that function returns does nothing.
 func NewPool(ctx context.Context, connString string, logger *zap.Logger, lazy bool) (*Pool, error) {
-	config, err := pgxpool.ParseConfig(connString)
+	return NewPoolWithOpts(ctx, connString, logger, &NewPoolOpts{Lazy: lazy})
+}
+
+// NewPoolWithOpts returns a new concurrency-safe connection pool with given options.
+//
+// Passed context is used only by the first checking connection.
+// Canceling it after that function returns does nothing.
+func NewPoolWithOpts(ctx context.Context, connString string, logger *zap.Logger, opts *NewPoolOpts) (*Pool, error) {
+	if opts == nil {
+		opts = new(NewPoolOpts)
+	}
+
+	config, err := newPoolConfig(connString, opts)
 	if err != nil {
 		return nil, fmt.Errorf("pgdb.NewPool: %w", err)
 	}
 
-	config.LazyConnect = lazy
-
-	// That only affects text protocol; pgx mostly uses a binary one.
-	// See:
-	// * https://github.com/jackc/pgx/issues/520
-	// * https://github.com/jackc/pgx/issues/789
-	// * https://github.com/jackc/pgx/issues/863
-	// * https://github.com/FerretDB/FerretDB/issues/43
-	config.ConnConfig.RuntimeParams["timezone"] = "UTC"
-
-	config.ConnConfig.RuntimeParams["application_name"] = "FerretDB"
-	config.ConnConfig.RuntimeParams["search_path"] = ""
-
-	// try to log everything; logger's configuration will skip extra levels if needed
+	// try to log everything (query metrics rely on Query and Exec messages);
+	// logger's configuration will skip extra levels if needed
 	config.ConnConfig.LogLevel = pgx.LogLevelTrace
-	config.ConnConfig.Logger = zapadapter.NewLogger(logger.Named("pgdb"))
+	queryMetrics := newQueryMetrics(zapadapter.NewLogger(logger.Named("pgdb")))
+	config.ConnConfig.Logger = queryMetrics
 
 	p, err := pgxpool.ConnectConfig(ctx, config)
 	if err != nil {
@@ -86,85 +148,115 @@ func NewPool(ctx context.Context, connString string, logger *zap.Logger, lazy bo
 	}
 
 	res := &Pool{
-		Pool: p,
+		Pool:                p,
+		acquireTimeout:      opts.AcquireTimeout,
+		queryMetrics:        queryMetrics,
+		settingsCache:       newSettingsCache(),
+		logger:              logger.Named("pgdb"),
+		minServerVersionNum: opts.MinServerVersionNum,
+		healthWakeup:        make(chan struct{}, 1),
 	}
 
-	if !lazy {
-		err = res.checkConnection(ctx)
+	if res.minServerVersionNum == 0 {
+		res.minServerVersionNum = DefaultMinServerVersionNum
 	}
 
-	return res, err
-}
+	if !opts.Lazy {
+		if _, err = res.ServerInfo(ctx); err != nil {
+			p.Close()
+			return nil, fmt.Errorf("pgdb.NewPool: %w", err)
+		}
 
-// isValidUTF8Locale Currently supported locale variants, compromised between https://www.postgresql.org/docs/9.3/multibyte.html
-// and https://www.gnu.org/software/libc/manual/html_node/Locale-Names.html.
-//
-// Valid examples:
-// * en_US.utf8,
-// * en_US.utf-8
-// * en_US.UTF8,
-// * en_US.UTF-8.
-func isValidUTF8Locale(setting string) bool {
-	lowered := strings.ToLower(setting)
-
-	return lowered == "en_us.utf8" || lowered == "en_us.utf-8"
+		res.health.Ready = true
+		res.health.LastCheck = time.Now()
+	}
+
+	interval := opts.HealthCheckInterval
+	if interval == 0 {
+		interval = DefaultHealthCheckInterval
+	}
+
+	if interval > 0 {
+		var healthCtx context.Context
+		healthCtx, res.stopHealth = context.WithCancel(context.Background())
+		res.healthDone = make(chan struct{})
+
+		go func() {
+			defer close(res.healthDone)
+			res.runHealthChecks(healthCtx, interval)
+		}()
+	}
+
+	return res, nil
 }
 
-// checkConnection checks PostgreSQL settings.
-func (pgPool *Pool) checkConnection(ctx context.Context) error {
-	logger := pgPool.Config().ConnConfig.Logger
+// Close stops health checks and closes all connections in the pool.
+func (pgPool *Pool) Close() {
+	if pgPool.stopHealth != nil {
+		pgPool.stopHealth()
+		<-pgPool.healthDone
+	}
 
-	rows, err := pgPool.Query(ctx, "SHOW ALL")
+	pgPool.Pool.Close()
+}
+
+// newPoolConfig parses the given connection string (URL or keyword/value DSN)
+// and overrides only the settings FerretDB requires or that are set in opts.
+//
+// Everything else, including Unix socket hosts, TLS and target_session_attrs settings,
+// and passwords, is preserved as parsed by pgx.
+func newPoolConfig(connString string, opts *NewPoolOpts) (*pgxpool.Config, error) {
+	config, err := pgxpool.ParseConfig(connString)
 	if err != nil {
-		return fmt.Errorf("pgdb.checkConnection: %w", err)
+		return nil, err
 	}
-	defer rows.Close()
 
-	for rows.Next() {
-		var name, setting, description string
-		if err := rows.Scan(&name, &setting, &description); err != nil {
-			return fmt.Errorf("pgdb.checkConnection: %w", err)
-		}
+	config.LazyConnect = opts.Lazy
 
-		switch name {
-		case "server_encoding":
-			if setting != encUTF8 {
-				return fmt.Errorf("pgdb.checkConnection: %q is %q, want %q", name, setting, encUTF8)
-			}
-		case "client_encoding":
-			if setting != encUTF8 {
-				return fmt.Errorf("pgdb.checkConnection: %q is %q, want %q", name, setting, encUTF8)
-			}
-		case "lc_collate":
-			if setting != localeC && setting != localePOSIX && !isValidUTF8Locale(setting) {
-				return fmt.Errorf("pgdb.checkConnection: %q is %q", name, setting)
-			}
-		case "lc_ctype":
-			if setting != localeC && setting != localePOSIX && !isValidUTF8Locale(setting) {
-				return fmt.Errorf("pgdb.checkConnection: %q is %q", name, setting)
-			}
-		default:
-			continue
-		}
+	if opts.MaxConns > 0 {
+		config.MaxConns = opts.MaxConns
+	}
+	if opts.MinConns > 0 {
+		config.MinConns = opts.MinConns
+	}
+	if config.MinConns > config.MaxConns {
+		return nil, fmt.Errorf("min connections (%d) exceeds max connections (%d)", config.MinConns, config.MaxConns)
+	}
+	if opts.MaxConnLifetime > 0 {
+		config.MaxConnLifetime = opts.MaxConnLifetime
+	}
+	if opts.MaxConnIdleTime > 0 {
+		config.MaxConnIdleTime = opts.MaxConnIdleTime
+	}
 
-		if logger != nil {
-			logger.Log(ctx, pgx.LogLevelDebug, "PostgreSQL setting", map[string]any{
-				"name":    name,
-				"setting": setting,
-			})
-		}
+	// That only affects text protocol; pgx mostly uses a binary one.
+	// See:
+	// * https://github.com/jackc/pgx/issues/520
+	// * https://github.com/jackc/pgx/issues/789
+	// * https://github.com/jackc/pgx/issues/863
+	// * https://github.com/FerretDB/FerretDB/issues/43
+	config.ConnConfig.RuntimeParams["timezone"] = "UTC"
+
+	if _, ok := config.ConnConfig.RuntimeParams["application_name"]; !ok {
+		config.ConnConfig.RuntimeParams["application_name"] = "ferretdb"
 	}
 
-	if err := rows.Err(); err != nil {
-		return fmt.Errorf("pgdb.checkConnection: %w", err)
+	if opts.SimpleProtocol {
+		// PgBouncer in transaction pooling mode shares server connections between clients,
+		// so prepared statements can't be cached, and search_path startup parameter is rejected;
+		// we always use fully-qualified names anyway.
+		config.ConnConfig.PreferSimpleProtocol = true
+		config.ConnConfig.BuildStatementCache = nil
+	} else {
+		config.ConnConfig.RuntimeParams["search_path"] = ""
 	}
 
-	return nil
+	return config, nil
 }
 
 // DropDatabase drops FerretDB database.
 //
-// It returns ErrTableNotExist if schema does not exist.
+// It returns ErrSchemaNotExist if schema does not exist or it is not a FerretDB database.
 //
 // Deprecated: use function instead.
 func (pgPool *Pool) DropDatabase(ctx context.Context, db string) error {
@@ -204,8 +296,22 @@ func (pgPool *Pool) SchemaStats(ctx context.Context, schema, collection string)
 
 	args := []any{schema}
 	if collection != "" {
+		// resolve the table name through the mapping without creating it for non-existing collection
+		table := formatCollectionName(collection)
+
+		exists, err := CollectionExists(ctx, pgPool, schema, collection)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if exists {
+			if table, err = getTableName(ctx, pgPool, schema, collection); err != nil {
+				return nil, lazyerrors.Error(err)
+			}
+		}
+
 		sql = sql + " AND t.table_name = $2"
-		args = append(args, collection)
+		args = append(args, table)
 	}
 
 	res.Name = schema
@@ -222,7 +328,7 @@ func (pgPool *Pool) SchemaStats(ctx context.Context, schema, collection string)
 // Deprecated: use function instead.
 func (pgPool *Pool) SetDocumentByID(ctx context.Context, sp *SQLParam, id any, doc *types.Document) (int64, error) {
 	var n int64
-	err := pgPool.InTransaction(ctx, func(tx pgx.Tx) error {
+	err := pgPool.InTransactionRetry(ctx, func(tx pgx.Tx) error {
 		var err error
 		n, err = SetDocumentByID(ctx, tx, sp, id, doc)
 		return err
@@ -236,7 +342,7 @@ func (pgPool *Pool) SetDocumentByID(ctx context.Context, sp *SQLParam, id any, d
 // Deprecated: use function instead.
 func (pgPool *Pool) DeleteDocumentsByID(ctx context.Context, sp *SQLParam, ids []any) (int64, error) {
 	var n int64
-	err := pgPool.InTransaction(ctx, func(tx pgx.Tx) error {
+	err := pgPool.InTransactionRetry(ctx, func(tx pgx.Tx) error {
 		var err error
 		n, err = DeleteDocumentsByID(ctx, tx, sp, ids)
 		return err
@@ -246,38 +352,285 @@ func (pgPool *Pool) DeleteDocumentsByID(ctx context.Context, sp *SQLParam, ids [
 }
 
 // InTransaction wraps the given function f in a transaction.
-// If f returns an error, the transaction is rolled back.
+// If f returns an error or panics, the transaction is rolled back.
+// Cached settings changed by f are invalidated after the end of the transaction.
 // Errors are wrapped with lazyerrors.Error,
 // so the caller needs to use errors.Is to check the error,
 // for example, errors.Is(err, ErrSchemaNotExist).
-func (pgPool *Pool) InTransaction(ctx context.Context, f func(pgx.Tx) error) (err error) {
-	var tx pgx.Tx
-	if tx, err = pgPool.Begin(ctx); err != nil {
-		err = lazyerrors.Error(err)
-		return
+func (pgPool *Pool) InTransaction(ctx context.Context, f func(pgx.Tx) error) error {
+	if err := pgPool.inTransaction(ctx, f); err != nil {
+		return lazyerrors.Error(err)
 	}
 
+	return nil
+}
+
+// inTransaction is InTransaction that does not wrap errors returned by f.
+func (pgPool *Pool) inTransaction(ctx context.Context, f func(pgx.Tx) error) error {
+	_, err := pgPool.inTransactionStale(ctx, f)
+	return err
+}
+
+// inTransactionStale is inTransaction that also returns names of databases
+// which cached settings were used by the transaction that failed because table or schema was not found.
+// Those settings are likely stale, and they are invalidated.
+func (pgPool *Pool) inTransactionStale(ctx context.Context, f func(pgx.Tx) error) (stale []string, err error) {
+	// runs last, so the broken connection is already released and destroyed
 	defer func() {
-		if err == nil {
+		if err != nil && IsConnectionError(err) {
+			pgPool.connectionLost(err)
+		}
+	}()
+
+	conn, err := pgPool.acquire(ctx)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	// runs after rollback
+	defer func() {
+		stale = pgPool.settingsCache.endTransaction(tx.Conn(), err != nil && IsUndefinedObject(err))
+	}()
+
+	return nil, runTransaction(ctx, &poolTx{Tx: tx, pool: pgPool}, f)
+}
+
+// poolTx is a transaction (or a savepoint) started by Pool.
+//
+// It allows functions that accept pgxtype.Querier to use the pool's settings cache and logger.
+type poolTx struct {
+	pgx.Tx
+	pool *Pool
+}
+
+// poolFor returns the Pool the given querier belongs to.
+//
+// It returns nil for queriers that were not created by Pool (for example, *pgx.Conn).
+func poolFor(querier pgxtype.Querier) *Pool {
+	switch q := querier.(type) {
+	case *Pool:
+		return q
+	case *poolTx:
+		return q.pool
+	default:
+		return nil
+	}
+}
+
+// Begin starts a pseudo nested transaction (savepoint) that uses the same settings cache.
+func (tx *poolTx) Begin(ctx context.Context) (pgx.Tx, error) {
+	savepoint, err := tx.Tx.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &poolTx{Tx: savepoint, pool: tx.pool}, nil
+}
+
+// BeginFunc starts a pseudo nested transaction (savepoint) and calls f like InTransaction.
+func (tx *poolTx) BeginFunc(ctx context.Context, f func(pgx.Tx) error) error {
+	return InTransaction(ctx, tx, f)
+}
+
+// InTransaction wraps the given function f in a transaction using the given querier.
+//
+// If querier is already a transaction, f is called in a nested pseudo-transaction (savepoint),
+// so an error rolls back only changes made by f, and the outer transaction could be continued.
+// If querier is a Pool, it is the same as Pool.InTransaction, but without wrapping errors.
+// Other queriers should be able to begin a transaction (like *pgxpool.Pool or *pgx.Conn).
+//
+// If f returns an error or panics, the (pseudo-)transaction is rolled back.
+// The error returned by f is returned as is.
+func InTransaction(ctx context.Context, querier pgxtype.Querier, f func(pgx.Tx) error) error {
+	switch q := querier.(type) {
+	case *Pool:
+		return q.inTransaction(ctx, f)
+
+	case pgx.Tx:
+		tx, err := q.Begin(ctx)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		return runTransaction(ctx, tx, f)
+
+	case interface {
+		Begin(context.Context) (pgx.Tx, error)
+	}:
+		// settings are not cached for such queriers, see settingsCacheFor
+		tx, err := q.Begin(ctx)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		return runTransaction(ctx, tx, f)
+
+	default:
+		return lazyerrors.Errorf("pgdb.InTransaction: %T can't begin a transaction", querier)
+	}
+}
+
+// runTransaction calls f with the given transaction, then commits it.
+// If f returns an error or panics, the transaction is rolled back.
+// Errors returned by f are not wrapped.
+func runTransaction(ctx context.Context, tx pgx.Tx, f func(pgx.Tx) error) (err error) {
+	var committed bool
+
+	defer func() {
+		if committed {
 			return
 		}
-		if rerr := tx.Rollback(ctx); rerr != nil {
-			pgPool.Config().ConnConfig.Logger.Log(
-				ctx, pgx.LogLevelError, "failed to perform rollback",
-				map[string]any{"error": rerr},
-			)
+
+		if rerr := tx.Rollback(ctx); rerr != nil && !errors.Is(rerr, pgx.ErrTxClosed) {
+			if logger := tx.Conn().Config().Logger; logger != nil {
+				logger.Log(ctx, pgx.LogLevelError, "failed to perform rollback", map[string]any{"error": rerr})
+			}
 		}
 	}()
 
 	if err = f(tx); err != nil {
-		err = lazyerrors.Error(err)
-		return
+		return err
 	}
 
 	if err = tx.Commit(ctx); err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	committed = true
+
+	return nil
+}
+
+// acquire returns a connection from the pool.
+//
+// If all connections are in use for longer than the configured acquire timeout,
+// it returns ErrPoolExhausted.
+func (pgPool *Pool) acquire(ctx context.Context) (*pgxpool.Conn, error) {
+	if pgPool.acquireTimeout <= 0 {
+		return pgPool.Acquire(ctx)
+	}
+
+	acquireCtx, cancel := context.WithTimeout(ctx, pgPool.acquireTimeout)
+	defer cancel()
+
+	conn, err := pgPool.Acquire(acquireCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(acquireCtx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf(
+			"%w: all %d connections are in use for %s; consider increasing the pool limit",
+			ErrPoolExhausted, pgPool.Config().MaxConns, pgPool.acquireTimeout,
+		)
+	}
+
+	return conn, err
+}
+
+// InTransactionRetry wraps the given function f in a transaction like InTransaction.
+//
+// If the transaction fails with a serialization failure or a deadlock,
+// it is retried with a new transaction up to maxTransactionRetries times with a jittered exponential backoff.
+// If it fails because table or schema was not found after cached settings were used
+// (for example, because they were stale after another FerretDB instance dropped the collection),
+// cached settings of the affected databases are invalidated, and the transaction is retried once immediately,
+// so settings are re-read.
+// That means that f could be called several times, so it should not have side effects outside of the transaction.
+//
+// Other errors are returned as is, without retries.
+// If ctx is canceled while waiting for the next attempt, the last error is returned.
+func (pgPool *Pool) InTransactionRetry(ctx context.Context, f func(pgx.Tx) error) error {
+	var reread bool
+
+	for attempt := 0; ; {
+		stale, err := pgPool.inTransactionStale(ctx, f)
+		if err == nil {
+			return nil
+		}
+
 		err = lazyerrors.Error(err)
-		return
+
+		if len(stale) > 0 && !reread {
+			reread = true
+			atomic.AddInt64(&pgPool.transactionRetries, 1)
+
+			pgPool.logger.Debug("Retrying transaction with stale settings", zap.Strings("databases", stale), zap.Error(err))
+
+			continue
+		}
+
+		if attempt == maxTransactionRetries || !IsRetryable(err) {
+			return err
+		}
+
+		atomic.AddInt64(&pgPool.transactionRetries, 1)
+
+		// full jitter, see https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/
+		delay := time.Duration(rand.Int63n(int64(transactionRetryDelay << attempt)))
+		attempt++
+
+		pgPool.logger.Debug("Retrying transaction", zap.Int("attempt", attempt), zap.Duration("delay", delay), zap.Error(err))
+
+		if ctxutil.Sleep(ctx, delay) != nil {
+			return err
+		}
+	}
+}
+
+// TransactionRetries returns the total number of transaction retries made by InTransactionRetry.
+func (pgPool *Pool) TransactionRetries() int64 {
+	return atomic.LoadInt64(&pgPool.transactionRetries)
+}
+
+// IsRetryable returns true if the transaction failed with (possibly wrapped) error that could be fixed by a retry.
+func IsRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+
+	switch pgErr.Code {
+	case pgerrcode.SerializationFailure, pgerrcode.DeadlockDetected:
+		return true
+	default:
+		// missing tables and schemas are not retried with backoff, see InTransactionRetry
+		return false
+	}
+}
+
+// IsRetryableWrite returns true if the write failed with (possibly wrapped) error
+// after which the client could safely retry the whole command:
+// transaction serialization failure or deadlock (after InTransactionRetry gave up),
+// or lost connection to PostgreSQL (see IsConnectionError).
+func IsRetryableWrite(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgerrcode.SerializationFailure, pgerrcode.DeadlockDetected:
+			return true
+		}
 	}
 
-	return
+	return IsConnectionError(err)
+}
+
+// IsUndefinedObject returns true if the transaction failed with (possibly wrapped) error
+// caused by a missing table or schema.
+//
+// For transactions that used cached settings, such errors are handled by InTransactionRetry.
+func IsUndefinedObject(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+
+	switch pgErr.Code {
+	case pgerrcode.UndefinedTable, pgerrcode.InvalidSchemaName:
+		return true
+	default:
+		return false
+	}
 }
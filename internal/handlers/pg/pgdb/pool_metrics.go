@@ -0,0 +1,132 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	namespace = "ferretdb"
+	subsystem = "postgresql_pool"
+)
+
+// PoolStats represents connection pool statistics.
+type PoolStats struct {
+	AcquiredConns        int32
+	IdleConns            int32
+	TotalConns           int32
+	MaxConns             int32
+	AcquireCount         int64
+	EmptyAcquireCount    int64
+	CanceledAcquireCount int64
+	AcquireDuration      time.Duration
+}
+
+// Stats returns connection pool statistics.
+func (pgPool *Pool) Stats() *PoolStats {
+	s := pgPool.Stat()
+
+	return &PoolStats{
+		AcquiredConns:        s.AcquiredConns(),
+		IdleConns:            s.IdleConns(),
+		TotalConns:           s.TotalConns(),
+		MaxConns:             s.MaxConns(),
+		AcquireCount:         s.AcquireCount(),
+		EmptyAcquireCount:    s.EmptyAcquireCount(),
+		CanceledAcquireCount: s.CanceledAcquireCount(),
+		AcquireDuration:      s.AcquireDuration(),
+	}
+}
+
+var (
+	acquiredConnsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "acquired_connections"),
+		"The current number of acquired connections.",
+		nil, nil,
+	)
+	idleConnsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "idle_connections"),
+		"The current number of idle connections.",
+		nil, nil,
+	)
+	totalConnsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "connections"),
+		"The current number of connections.",
+		nil, nil,
+	)
+	maxConnsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "max_connections"),
+		"The maximum number of connections.",
+		nil, nil,
+	)
+	acquiresDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "acquires_total"),
+		"Total number of successful connection acquires.",
+		nil, nil,
+	)
+	emptyAcquiresDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "empty_acquires_total"),
+		"Total number of successful connection acquires that waited for a connection.",
+		nil, nil,
+	)
+	canceledAcquiresDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "canceled_acquires_total"),
+		"Total number of connection acquires that were canceled.",
+		nil, nil,
+	)
+	acquireDurationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, subsystem, "acquire_wait_seconds_total"),
+		"Total time spent waiting for connection acquires.",
+		nil, nil,
+	)
+)
+
+// Describe implements prometheus.Collector.
+func (pgPool *Pool) Describe(ch chan<- *prometheus.Desc) {
+	ch <- acquiredConnsDesc
+	ch <- idleConnsDesc
+	ch <- totalConnsDesc
+	ch <- maxConnsDesc
+	ch <- acquiresDesc
+	ch <- emptyAcquiresDesc
+	ch <- canceledAcquiresDesc
+	ch <- acquireDurationDesc
+
+	pgPool.queryMetrics.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (pgPool *Pool) Collect(ch chan<- prometheus.Metric) {
+	s := pgPool.Stats()
+
+	ch <- prometheus.MustNewConstMetric(acquiredConnsDesc, prometheus.GaugeValue, float64(s.AcquiredConns))
+	ch <- prometheus.MustNewConstMetric(idleConnsDesc, prometheus.GaugeValue, float64(s.IdleConns))
+	ch <- prometheus.MustNewConstMetric(totalConnsDesc, prometheus.GaugeValue, float64(s.TotalConns))
+	ch <- prometheus.MustNewConstMetric(maxConnsDesc, prometheus.GaugeValue, float64(s.MaxConns))
+	ch <- prometheus.MustNewConstMetric(acquiresDesc, prometheus.CounterValue, float64(s.AcquireCount))
+	ch <- prometheus.MustNewConstMetric(emptyAcquiresDesc, prometheus.CounterValue, float64(s.EmptyAcquireCount))
+	ch <- prometheus.MustNewConstMetric(canceledAcquiresDesc, prometheus.CounterValue, float64(s.CanceledAcquireCount))
+	ch <- prometheus.MustNewConstMetric(acquireDurationDesc, prometheus.CounterValue, s.AcquireDuration.Seconds())
+
+	pgPool.queryMetrics.Collect(ch)
+}
+
+// check interfaces
+var (
+	_ prometheus.Collector = (*Pool)(nil)
+)
@@ -51,6 +51,36 @@ type SQLParam struct {
 	Collection string
 	Comment    string
 	Explain    bool
+
+	// Filter is an optional filter.
+	// It is not pushed down for queries yet, but it is used to decide whether Limit and Skip could be.
+	// Simple filters are pushed down by DeleteDocumentsByFilter.
+	Filter *types.Document
+
+	// Sort is an optional sort specification.
+	// It is pushed down only for _id with positive Limit, see prepareOrderByClause and QueryResults.SortPushdown.
+	// Otherwise, it is used to decide whether Limit and Skip could be pushed down.
+	Sort *types.Document
+
+	// DisableSortPushdown disables Sort pushdown.
+	// It is used to repeat the query when SortPushdownExact returns false.
+	DisableSortPushdown bool
+
+	// Limit and Skip are optional values (zero means none, negative values are never pushed down).
+	// They are pushed down to PostgreSQL's LIMIT and OFFSET only if Filter is empty
+	// and Sort is empty or pushed down, see QueryResults.LimitPushdown.
+	Limit int64
+	Skip  int64
+}
+
+// QueryResults describes which parts of the query were handled by PostgreSQL.
+// The caller is responsible for applying the rest in memory.
+type QueryResults struct {
+	// SortPushdown is true if documents are sorted by SQLParam.Sort (see SortPushdownExact).
+	SortPushdown bool
+
+	// LimitPushdown is true if SQLParam.Limit and SQLParam.Skip were already applied.
+	LimitPushdown bool
 }
 
 // QueryDocuments returns a channel with buffer FetchedChannelBufSize
@@ -64,22 +94,24 @@ type SQLParam struct {
 // Context cancellation is not considered an error.
 //
 // If the collection doesn't exist, fetch returns a closed channel and no error.
-func (pgPool *Pool) QueryDocuments(ctx context.Context, querier pgxtype.Querier, sp SQLParam) (<-chan FetchedDocs, error) {
+//
+// The returned QueryResults is always non-nil.
+func (pgPool *Pool) QueryDocuments(ctx context.Context, querier pgxtype.Querier, sp SQLParam) (<-chan FetchedDocs, *QueryResults, error) { //nolint:lll // argument list is too long
 	fetchedChan := make(chan FetchedDocs, FetchedChannelBufSize)
 
-	q, err := buildQuery(ctx, querier, &sp)
+	q, args, res, err := buildQuery(ctx, querier, &sp)
 	if err != nil {
 		close(fetchedChan)
 		if errors.Is(err, ErrTableNotExist) {
-			return fetchedChan, nil
+			return fetchedChan, new(QueryResults), nil
 		}
-		return fetchedChan, lazyerrors.Error(err)
+		return fetchedChan, new(QueryResults), lazyerrors.Error(err)
 	}
 
-	rows, err := querier.Query(ctx, q)
+	rows, err := querier.Query(ctx, q, args...)
 	if err != nil {
 		close(fetchedChan)
-		return fetchedChan, lazyerrors.Error(err)
+		return fetchedChan, new(QueryResults), lazyerrors.Error(err)
 	}
 
 	go func() {
@@ -103,19 +135,19 @@ func (pgPool *Pool) QueryDocuments(ctx context.Context, querier pgxtype.Querier,
 		}
 	}()
 
-	return fetchedChan, nil
+	return fetchedChan, res, nil
 }
 
 // Explain returns SQL EXPLAIN results for given query parameters.
-func Explain(ctx context.Context, querier pgxtype.Querier, sp SQLParam) (*types.Array, error) {
-	q, err := buildQuery(ctx, querier, &sp)
+func Explain(ctx context.Context, querier pgxtype.Querier, sp SQLParam) (*types.Array, *QueryResults, error) {
+	q, args, qr, err := buildQuery(ctx, querier, &sp)
 	if err != nil {
-		return nil, lazyerrors.Error(err)
+		return nil, nil, lazyerrors.Error(err)
 	}
 
-	rows, err := querier.Query(ctx, q)
+	rows, err := querier.Query(ctx, q, args...)
 	if err != nil {
-		return nil, lazyerrors.Error(err)
+		return nil, nil, lazyerrors.Error(err)
 	}
 	defer rows.Close()
 
@@ -123,47 +155,51 @@ func Explain(ctx context.Context, querier pgxtype.Querier, sp SQLParam) (*types.
 	for rows.Next() {
 		var b []byte
 		if err = rows.Scan(&b); err != nil {
-			return nil, lazyerrors.Error(err)
+			return nil, nil, lazyerrors.Error(err)
 		}
 
 		var plans []map[string]any
 		if err = json.Unmarshal(b, &plans); err != nil {
-			return nil, lazyerrors.Error(err)
+			return nil, nil, lazyerrors.Error(err)
 		}
 
 		for _, p := range plans {
 			doc := convertJSON(p).(*types.Document)
 			if err = res.Append(doc); err != nil {
-				return nil, lazyerrors.Error(err)
+				return nil, nil, lazyerrors.Error(err)
 			}
 		}
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, lazyerrors.Error(err)
+		return nil, nil, lazyerrors.Error(err)
 	}
 
-	return &res, nil
+	return &res, qr, nil
 }
 
-// buildQuery builds SELECT or EXPLAIN SELECT query.
+// buildQuery builds SELECT or EXPLAIN SELECT query with its arguments.
 //
 // It returns (possibly wrapped) ErrSchemaNotExist or ErrTableNotExist
 // if schema/database or table/collection does not exist.
-func buildQuery(ctx context.Context, querier pgxtype.Querier, sp *SQLParam) (string, error) {
+func buildQuery(ctx context.Context, querier pgxtype.Querier, sp *SQLParam) (string, []any, *QueryResults, error) {
 	exists, err := CollectionExists(ctx, querier, sp.DB, sp.Collection)
 	if err != nil {
-		return "", lazyerrors.Error(err)
+		return "", nil, nil, lazyerrors.Error(err)
 	}
 	if !exists {
-		return "", lazyerrors.Error(ErrTableNotExist)
+		return "", nil, nil, lazyerrors.Error(ErrTableNotExist)
 	}
 
 	table, err := getTableName(ctx, querier, sp.DB, sp.Collection)
 	if err != nil {
-		return "", lazyerrors.Error(err)
+		return "", nil, nil, lazyerrors.Error(err)
 	}
 
+	var res QueryResults
+	var args []any
+	var p Placeholder
+
 	q := `SELECT _jsonb `
 	if c := sp.Comment; c != "" {
 		// prevent SQL injections
@@ -174,11 +210,38 @@ func buildQuery(ctx context.Context, querier pgxtype.Querier, sp *SQLParam) (str
 	}
 	q += `FROM ` + pgx.Identifier{sp.DB, table}.Sanitize()
 
+	// filters are not pushed down yet, so only an empty filter allows that
+	filterPushdown := sp.Filter.Len() == 0
+
+	limitPushdown := sp.Limit >= 0 && sp.Skip >= 0 && (sp.Limit > 0 || sp.Skip > 0)
+
+	// without limit, all documents are fetched anyway, and sorting them in memory is always exact
+	if sp.Sort.Len() != 0 && !sp.DisableSortPushdown && sp.Limit > 0 && sp.Skip >= 0 {
+		if orderBy := prepareOrderByClause(sp.Sort); orderBy != "" {
+			q += orderBy
+			res.SortPushdown = true
+		}
+	}
+
+	if limitPushdown && filterPushdown && (sp.Sort.Len() == 0 || res.SortPushdown) {
+		if sp.Limit > 0 {
+			q += ` LIMIT ` + p.Next()
+			args = append(args, sp.Limit)
+		}
+
+		if sp.Skip > 0 {
+			q += ` OFFSET ` + p.Next()
+			args = append(args, sp.Skip)
+		}
+
+		res.LimitPushdown = true
+	}
+
 	if sp.Explain {
 		q = "EXPLAIN (VERBOSE true, FORMAT JSON) " + q
 	}
 
-	return q, nil
+	return q, args, &res, nil
 }
 
 // iterateFetch iterates over the rows returned by the query and sends FetchedDocs to fetched channel.
@@ -0,0 +1,257 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// Query kinds used as metric labels.
+const (
+	queryKindQuery  = "query"
+	queryKindExec   = "exec"
+	queryKindInsert = "insert"
+	queryKindUpdate = "update"
+	queryKindDelete = "delete"
+)
+
+// queryKinds contains all query kinds in a stable order.
+var queryKinds = []string{queryKindQuery, queryKindExec, queryKindInsert, queryKindUpdate, queryKindDelete}
+
+// Error classes that are not PostgreSQL SQLSTATE classes.
+const (
+	errorClassCanceled = "canceled"
+	errorClassOther    = "other"
+)
+
+var (
+	queriesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "postgresql", "queries_total"),
+		"Total number of PostgreSQL queries by kind.",
+		[]string{"kind"}, nil,
+	)
+	queryErrorsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "postgresql", "query_errors_total"),
+		"Total number of failed PostgreSQL queries by SQLSTATE class.",
+		[]string{"class"}, nil,
+	)
+)
+
+// queryKindMetrics contains metrics for a single query kind.
+type queryKindMetrics struct {
+	count    int64 // atomic
+	duration prometheus.Observer
+}
+
+// queryMetrics is a pgx.Logger that records query metrics and passes all messages to the wrapped logger.
+//
+// pgx logs every completed Query and Exec call with its duration and error (if any),
+// so that gives us metrics without wrapping all querier implementations.
+// Only statement kinds and durations are recorded; SQL texts and arguments are never stored.
+type queryMetrics struct {
+	logger pgx.Logger
+
+	kinds     map[string]*queryKindMetrics // read-only after creation
+	durations *prometheus.HistogramVec
+
+	errorsM sync.Mutex
+	errors  map[string]int64
+}
+
+// newQueryMetrics returns a new queryMetrics wrapping the given logger.
+func newQueryMetrics(logger pgx.Logger) *queryMetrics {
+	m := &queryMetrics{
+		logger: logger,
+		kinds:  make(map[string]*queryKindMetrics, len(queryKinds)),
+		durations: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Subsystem: "postgresql",
+				Name:      "query_duration_seconds",
+				Help:      "PostgreSQL query durations by kind.",
+				Buckets:   prometheus.ExponentialBuckets(0.0001, 4, 10),
+			},
+			[]string{"kind"},
+		),
+		errors: make(map[string]int64),
+	}
+
+	// create all children upfront so recording does not need label lookups
+	for _, kind := range queryKinds {
+		m.kinds[kind] = &queryKindMetrics{
+			duration: m.durations.WithLabelValues(kind),
+		}
+	}
+
+	return m
+}
+
+// Log implements pgx.Logger.
+func (m *queryMetrics) Log(ctx context.Context, level pgx.LogLevel, msg string, data map[string]any) {
+	switch msg {
+	case "Query", "Exec":
+		sql, _ := data["sql"].(string)
+		kind := queryKind(msg, sql)
+
+		m.record(kind, data)
+		traceQuery(ctx, kind, data)
+	}
+
+	if m.logger != nil {
+		m.logger.Log(ctx, level, msg, data)
+	}
+}
+
+// record records metrics for a single pgx Query or Exec log message with the given query kind.
+func (m *queryMetrics) record(kind string, data map[string]any) {
+	km := m.kinds[kind]
+
+	atomic.AddInt64(&km.count, 1)
+
+	if d, ok := data["time"].(time.Duration); ok {
+		km.duration.Observe(d.Seconds())
+	}
+
+	if err, ok := data["err"].(error); ok && err != nil {
+		class := errorClass(err)
+
+		m.errorsM.Lock()
+		m.errors[class]++
+		m.errorsM.Unlock()
+	}
+}
+
+// queryKind returns a query kind for the given pgx log message ("Query" or "Exec") and SQL text.
+func queryKind(msg, sql string) string {
+	sql = strings.TrimLeft(sql, " \t\r\n(")
+
+	// compare only a prefix to avoid allocations
+	for _, kind := range []string{queryKindInsert, queryKindUpdate, queryKindDelete} {
+		if len(sql) >= len(kind) && strings.EqualFold(sql[:len(kind)], kind) {
+			return kind
+		}
+	}
+
+	if msg == "Exec" {
+		return queryKindExec
+	}
+
+	return queryKindQuery
+}
+
+// errorClass returns a SQLSTATE class (the first two characters of the error code) for the given error.
+//
+// See https://www.postgresql.org/docs/current/errcodes-appendix.html.
+func errorClass(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && len(pgErr.Code) == 5 {
+		return pgErr.Code[:2]
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return errorClassCanceled
+	}
+
+	return errorClassOther
+}
+
+// QueryStats represents PostgreSQL query statistics.
+type QueryStats struct {
+	// Kinds contains statistics for all query kinds in a stable order.
+	Kinds []QueryKindStats
+
+	// Errors contains the number of failed queries by SQLSTATE class
+	// (or "canceled" and "other" for errors without SQLSTATE).
+	Errors map[string]int64
+}
+
+// QueryKindStats represents statistics for a single query kind.
+type QueryKindStats struct {
+	Kind  string
+	Count int64
+}
+
+// stats returns query statistics.
+func (m *queryMetrics) stats() *QueryStats {
+	res := &QueryStats{
+		Kinds: make([]QueryKindStats, len(queryKinds)),
+	}
+
+	for i, kind := range queryKinds {
+		res.Kinds[i] = QueryKindStats{
+			Kind:  kind,
+			Count: atomic.LoadInt64(&m.kinds[kind].count),
+		}
+	}
+
+	m.errorsM.Lock()
+	defer m.errorsM.Unlock()
+
+	res.Errors = make(map[string]int64, len(m.errors))
+	for class, n := range m.errors {
+		res.Errors[class] = n
+	}
+
+	return res
+}
+
+// Describe implements prometheus.Collector.
+func (m *queryMetrics) Describe(ch chan<- *prometheus.Desc) {
+	ch <- queriesDesc
+	ch <- queryErrorsDesc
+	m.durations.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (m *queryMetrics) Collect(ch chan<- prometheus.Metric) {
+	s := m.stats()
+
+	for _, k := range s.Kinds {
+		ch <- prometheus.MustNewConstMetric(queriesDesc, prometheus.CounterValue, float64(k.Count), k.Kind)
+	}
+
+	classes := maps.Keys(s.Errors)
+	slices.Sort(classes)
+
+	for _, class := range classes {
+		ch <- prometheus.MustNewConstMetric(queryErrorsDesc, prometheus.CounterValue, float64(s.Errors[class]), class)
+	}
+
+	m.durations.Collect(ch)
+}
+
+// QueryStats returns PostgreSQL query statistics.
+//
+// They are the same values that are exposed as Prometheus metrics.
+func (pgPool *Pool) QueryStats() *QueryStats {
+	return pgPool.queryMetrics.stats()
+}
+
+// check interfaces
+var (
+	_ pgx.Logger           = (*queryMetrics)(nil)
+	_ prometheus.Collector = (*queryMetrics)(nil)
+)
@@ -0,0 +1,131 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestQueryKind(t *testing.T) {
+	t.Parallel()
+
+	for tc, expected := range map[[2]string]string{
+		{"Query", "SELECT _jsonb FROM t"}:                         queryKindQuery,
+		{"Query", "  \n WITH matched AS (SELECT 1) SELECT 1"}:     queryKindQuery,
+		{"Query", "INSERT INTO t (_jsonb) VALUES ($1) RETURNING"}: queryKindInsert,
+		{"Exec", "insert into t (_jsonb) values ($1)"}:            queryKindInsert,
+		{"Exec", "UPDATE t SET _jsonb = $1"}:                      queryKindUpdate,
+		{"Exec", "DELETE FROM t WHERE _jsonb->'_id' = $1"}:        queryKindDelete,
+		{"Exec", "CREATE TABLE t (_jsonb jsonb)"}:                 queryKindExec,
+		{"Exec", "del"}: queryKindExec,
+		{"Exec", ""}:    queryKindExec,
+	} {
+		assert.Equal(t, expected, queryKind(tc[0], tc[1]), "%s", tc)
+	}
+}
+
+func TestErrorClass(t *testing.T) {
+	t.Parallel()
+
+	pgErr := &pgconn.PgError{Code: pgerrcode.UniqueViolation}
+	assert.Equal(t, "23", errorClass(pgErr))
+	assert.Equal(t, "23", errorClass(fmt.Errorf("wrapped: %w", pgErr)))
+	assert.Equal(t, errorClassCanceled, errorClass(context.Canceled))
+	assert.Equal(t, errorClassCanceled, errorClass(context.DeadlineExceeded))
+	assert.Equal(t, errorClassOther, errorClass(errors.New("some error")))
+}
+
+func TestQueryMetricsLog(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	m := newQueryMetrics(nil)
+
+	m.Log(ctx, pgx.LogLevelInfo, "Exec", map[string]any{"sql": "INSERT INTO t", "time": time.Millisecond})
+	m.Log(ctx, pgx.LogLevelInfo, "Query", map[string]any{"sql": "SELECT 1", "time": time.Millisecond})
+	m.Log(ctx, pgx.LogLevelError, "Query", map[string]any{
+		"sql":  "SELECT 1",
+		"time": time.Millisecond,
+		"err":  &pgconn.PgError{Code: pgerrcode.UndefinedTable},
+	})
+	m.Log(ctx, pgx.LogLevelInfo, "closed connection", nil)
+
+	s := m.stats()
+	assert.Equal(t, []QueryKindStats{
+		{Kind: queryKindQuery, Count: 2},
+		{Kind: queryKindExec, Count: 0},
+		{Kind: queryKindInsert, Count: 1},
+		{Kind: queryKindUpdate, Count: 0},
+		{Kind: queryKindDelete, Count: 0},
+	}, s.Kinds)
+	assert.Equal(t, map[string]int64{"42": 1}, s.Errors)
+
+	// queries + errors + histograms for all kinds
+	assert.Equal(t, len(queryKinds)+1+len(queryKinds), collect(m))
+}
+
+func TestQueryMetricsPool(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+
+	before := pool.QueryStats()
+
+	_, err := pool.Exec(ctx, "SELECT 1")
+	require.NoError(t, err)
+
+	_, err = pool.Exec(ctx, "SELECT * FROM no_such_table_for_metrics")
+	require.Error(t, err)
+
+	after := pool.QueryStats()
+
+	assert.Equal(t, before.Kinds[1].Kind, queryKindExec)
+	assert.Equal(t, before.Kinds[1].Count+2, after.Kinds[1].Count)
+	assert.Equal(t, before.Errors["42"]+1, after.Errors["42"])
+}
+
+// collect returns the number of metrics collected from the given collector.
+func collect(c prometheus.Collector) int {
+	ch := make(chan prometheus.Metric)
+
+	go func() {
+		c.Collect(ch)
+		close(ch)
+	}()
+
+	var n int
+	for range ch {
+		n++
+	}
+
+	return n
+}
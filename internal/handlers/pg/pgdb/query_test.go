@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/jackc/pgx/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap/zaptest"
@@ -99,7 +100,7 @@ func TestQueryDocuments(t *testing.T) {
 			}
 
 			sp := SQLParam{DB: dbName, Collection: tc.collection}
-			fetchedChan, err := pool.QueryDocuments(ctx, tx, sp)
+			fetchedChan, _, err := pool.QueryDocuments(ctx, tx, sp)
 			require.NoError(t, err)
 
 			iter := 0
@@ -132,7 +133,7 @@ func TestQueryDocuments(t *testing.T) {
 
 		sp := SQLParam{DB: dbName, Collection: collectionName + "_cancel"}
 		ctx, cancel := context.WithCancel(context.Background())
-		fetchedChan, err := pool.QueryDocuments(ctx, pool, sp)
+		fetchedChan, _, err := pool.QueryDocuments(ctx, pool, sp)
 		cancel()
 		require.NoError(t, err)
 
@@ -157,7 +158,7 @@ func TestQueryDocuments(t *testing.T) {
 		require.NoError(t, err)
 
 		sp := SQLParam{DB: dbName, Collection: collectionName + "_non-existing"}
-		fetchedChan, err := pool.QueryDocuments(context.Background(), tx, sp)
+		fetchedChan, _, err := pool.QueryDocuments(context.Background(), tx, sp)
 		require.NoError(t, err)
 		res, ok := <-fetchedChan
 		require.False(t, ok)
@@ -167,3 +168,147 @@ func TestQueryDocuments(t *testing.T) {
 		require.NoError(t, tx.Commit(ctx))
 	})
 }
+
+func TestQueryDocumentsLimitPushdown(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(t, CreateDatabase(ctx, pool, dbName))
+
+	for i := int32(0); i < 10; i++ {
+		doc := must.NotFail(types.NewDocument("_id", i, "v", i%3))
+		require.NoError(t, InsertDocument(ctx, pool, dbName, collectionName, doc))
+	}
+
+	for name, tc := range map[string]struct {
+		sp       SQLParam
+		expected []int32 // expected _id values (in any order without sort) if pushed down
+	}{
+		"Limit": {
+			sp:       SQLParam{Limit: 3},
+			expected: []int32{0, 1, 2},
+		},
+		"Skip": {
+			sp:       SQLParam{Skip: 8},
+			expected: []int32{8, 9},
+		},
+		"SkipEverything": {
+			sp:       SQLParam{Skip: 100},
+			expected: []int32{},
+		},
+		"NegativeLimit": {
+			sp: SQLParam{Limit: -3},
+		},
+		"Filter": {
+			sp: SQLParam{Filter: must.NotFail(types.NewDocument("v", int32(1))), Limit: 1},
+		},
+		"Sort": {
+			sp:       SQLParam{Sort: must.NotFail(types.NewDocument("_id", int32(-1))), Limit: 2, Skip: 1},
+			expected: []int32{8, 7},
+		},
+		"SortWithoutLimit": {
+			sp: SQLParam{Sort: must.NotFail(types.NewDocument("_id", int32(-1))), Skip: 1},
+		},
+		"SortField": {
+			sp: SQLParam{Sort: must.NotFail(types.NewDocument("v", int32(1))), Limit: 2},
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			sp := tc.sp
+			sp.DB = dbName
+			sp.Collection = collectionName
+
+			fetchedChan, qr, err := pool.QueryDocuments(ctx, pool, sp)
+			require.NoError(t, err)
+
+			actual := []int32{}
+			for fetched := range fetchedChan {
+				require.NoError(t, fetched.Err)
+
+				for _, doc := range fetched.Docs {
+					actual = append(actual, must.NotFail(doc.Get("_id")).(int32))
+				}
+			}
+
+			if tc.expected == nil {
+				assert.False(t, qr.LimitPushdown)
+				assert.False(t, qr.SortPushdown)
+				assert.Len(t, actual, 10)
+				return
+			}
+
+			assert.True(t, qr.LimitPushdown)
+
+			if tc.sp.Sort != nil {
+				assert.True(t, qr.SortPushdown)
+				assert.Equal(t, tc.expected, actual)
+				return
+			}
+
+			// order is not defined without sort
+			assert.Len(t, actual, len(tc.expected))
+		})
+	}
+}
+
+// BenchmarkQueryDocumentsLimit shows that documents are not fetched from the whole table
+// if limit is pushed down.
+func BenchmarkQueryDocumentsLimit(b *testing.B) {
+	ctx := testutil.Ctx(b)
+
+	pool := getPool(ctx, b, zaptest.NewLogger(b))
+	dbName := testutil.DatabaseName(b)
+	collectionName := testutil.CollectionName(b)
+
+	b.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(b, CreateDatabase(ctx, pool, dbName))
+	require.NoError(b, CreateCollection(ctx, pool, dbName, collectionName))
+
+	table, err := getTableName(ctx, pool, dbName, collectionName)
+	require.NoError(b, err)
+
+	// insert 1M documents {_id: i} directly, in fjson format
+	sql := `INSERT INTO ` + pgx.Identifier{dbName, table}.Sanitize() + ` (_jsonb) ` +
+		`SELECT jsonb_build_object('$k', jsonb_build_array('_id'), '_id', i) FROM generate_series(1, 1000000) AS i`
+	_, err = pool.Exec(ctx, sql)
+	require.NoError(b, err)
+
+	for name, sp := range map[string]SQLParam{
+		"Pushdown":   {DB: dbName, Collection: collectionName, Limit: 10},
+		"NoPushdown": {DB: dbName, Collection: collectionName, Filter: must.NotFail(types.NewDocument("_id", int32(1)))},
+	} {
+		sp := sp
+		b.Run(name, func(b *testing.B) {
+			var fetched int
+
+			for i := 0; i < b.N; i++ {
+				fetchedChan, _, err := pool.QueryDocuments(ctx, pool, sp)
+				require.NoError(b, err)
+
+				for f := range fetchedChan {
+					require.NoError(b, f.Err)
+					fetched += len(f.Docs)
+				}
+			}
+
+			b.ReportMetric(float64(fetched)/float64(b.N), "docs/op")
+		})
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation name of the pgdb tracer.
+const tracerName = "github.com/FerretDB/FerretDB/internal/handlers/pg/pgdb"
+
+// traceQuery records a child span for a completed query
+// if the query's context carries a recording span (typically, command's span).
+//
+// pgx reports queries after they are completed, so the span is created with the past start time.
+// Only the query kind is recorded; SQL texts, arguments, and error messages
+// (that may contain document contents) are never stored.
+func traceQuery(ctx context.Context, kind string, data map[string]any) {
+	parent := trace.SpanFromContext(ctx)
+	if !parent.IsRecording() {
+		return
+	}
+
+	end := time.Now()
+	start := end
+	if d, ok := data["time"].(time.Duration); ok {
+		start = end.Add(-d)
+	}
+
+	_, span := parent.TracerProvider().Tracer(tracerName).Start(
+		ctx, "pgdb."+kind,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(start),
+		trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBOperationKey.String(kind)),
+	)
+
+	if err, ok := data["err"].(error); ok && err != nil {
+		span.SetStatus(codes.Error, errorClass(err))
+	}
+
+	span.End(trace.WithTimestamp(end))
+}
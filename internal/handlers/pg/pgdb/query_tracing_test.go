@@ -0,0 +1,85 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+)
+
+func TestTraceQuery(t *testing.T) {
+	t.Parallel()
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	m := newQueryMetrics(nil)
+
+	// no spans without a parent
+	m.Log(context.Background(), pgx.LogLevelInfo, "Query", map[string]any{"sql": "SELECT 1", "time": time.Millisecond})
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "find")
+	m.Log(ctx, pgx.LogLevelInfo, "Query", map[string]any{"sql": "SELECT _jsonb FROM t", "time": time.Millisecond})
+	m.Log(ctx, pgx.LogLevelError, "Exec", map[string]any{
+		"sql":  "INSERT INTO t (_jsonb) VALUES ($1)",
+		"args": []any{`{"secret": "value"}`},
+		"time": time.Millisecond,
+		"err":  &pgconn.PgError{Code: pgerrcode.UniqueViolation, Detail: `Key (_id)=("secret") already exists.`},
+	})
+	m.Log(ctx, pgx.LogLevelInfo, "closed connection", nil)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+
+	parentSpan := spans[2]
+	assert.Equal(t, "find", parentSpan.Name())
+
+	for i, expected := range []struct {
+		name   string
+		kind   string
+		status codes.Code
+	}{
+		{name: "pgdb.query", kind: queryKindQuery, status: codes.Unset},
+		{name: "pgdb.insert", kind: queryKindInsert, status: codes.Error},
+	} {
+		span := spans[i]
+		assert.Equal(t, expected.name, span.Name())
+		assert.Equal(t, parentSpan.SpanContext().SpanID(), span.Parent().SpanID())
+		assert.Equal(t, parentSpan.SpanContext().TraceID(), span.SpanContext().TraceID())
+		assert.Equal(t, time.Millisecond, span.EndTime().Sub(span.StartTime()))
+		assert.Equal(t, expected.status, span.Status().Code)
+
+		assert.ElementsMatch(t, []attribute.KeyValue{
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationKey.String(expected.kind),
+		}, span.Attributes())
+	}
+
+	// only the error class is recorded
+	assert.Equal(t, "23", spans[1].Status().Description)
+}
@@ -16,6 +16,7 @@ package pgdb
 
 import (
 	"context"
+	"strings"
 
 	"github.com/jackc/pgtype/pgxtype"
 
@@ -42,3 +43,35 @@ func schemaExists(ctx context.Context, querier pgxtype.Querier, db string) (bool
 
 	return false, nil
 }
+
+// Schemas returns a sorted list of all PostgreSQL schema names, excluding system schemas.
+//
+// Unlike Databases, it also returns schemas that are not FerretDB databases.
+// It should be used only for diagnostics.
+func Schemas(ctx context.Context, querier pgxtype.Querier) ([]string, error) {
+	sql := "SELECT schema_name FROM information_schema.schemata ORDER BY schema_name"
+	rows, err := querier.Query(ctx, sql)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+	defer rows.Close()
+
+	res := make([]string, 0, 2)
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		if strings.HasPrefix(name, "pg_") || name == "information_schema" {
+			continue
+		}
+
+		res = append(res, name)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return res, nil
+}
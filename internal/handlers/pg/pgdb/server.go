@@ -0,0 +1,197 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgtype/pgxtype"
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// DefaultMinServerVersionNum is the default minimal supported PostgreSQL version
+// in server_version_num format.
+const DefaultMinServerVersionNum = 120000
+
+const (
+	// Supported encoding.
+	encUTF8 = "UTF8"
+
+	// Supported locales: (For more info see: https://www.gnu.org/software/libc/manual/html_node/Standard-Locales.html)
+	localeC     = "C"
+	localePOSIX = "POSIX"
+)
+
+// ServerInfo describes PostgreSQL server checked by the pool.
+type ServerInfo struct {
+	// Version is a human-readable server version (server_version setting), for example, "14.5".
+	Version string
+
+	// VersionNum is a server version as a number (server_version_num setting), for example, 140005.
+	VersionNum int
+
+	// Settings contains values of checked settings by their names.
+	Settings map[string]string
+}
+
+// ServerInfo returns information about PostgreSQL server.
+//
+// The server is checked on the first call (or when the pool is created, unless it is lazy),
+// and the result is cached. Errors are not cached, so the next call checks the server again.
+func (pgPool *Pool) ServerInfo(ctx context.Context) (*ServerInfo, error) {
+	pgPool.serverInfoM.Lock()
+	defer pgPool.serverInfoM.Unlock()
+
+	if pgPool.serverInfo != nil {
+		return pgPool.serverInfo, nil
+	}
+
+	info, err := checkServer(ctx, pgPool, pgPool.minServerVersionNum)
+	if err != nil {
+		return nil, err
+	}
+
+	pgPool.logger.Info(
+		"PostgreSQL server checked.",
+		zap.String("version", info.Version), zap.Int("version_num", info.VersionNum), zap.Any("settings", info.Settings),
+	)
+
+	pgPool.serverInfo = info
+
+	return info, nil
+}
+
+// isValidUTF8Locale Currently supported locale variants, compromised between https://www.postgresql.org/docs/9.3/multibyte.html
+// and https://www.gnu.org/software/libc/manual/html_node/Locale-Names.html.
+//
+// Valid examples:
+// * en_US.utf8,
+// * en_US.utf-8
+// * en_US.UTF8,
+// * en_US.UTF-8.
+func isValidUTF8Locale(setting string) bool {
+	lowered := strings.ToLower(setting)
+
+	return lowered == "en_us.utf8" || lowered == "en_us.utf-8"
+}
+
+// checkServer reads PostgreSQL settings with the given querier and checks them.
+//
+// It returns a single error naming the first offending setting.
+func checkServer(ctx context.Context, querier pgxtype.Querier, minVersionNum int) (*ServerInfo, error) {
+	rows, err := querier.Query(ctx, "SHOW ALL")
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+	defer rows.Close()
+
+	settings := make(map[string]string, 8)
+
+	for rows.Next() {
+		var name, setting, description string
+		if err = rows.Scan(&name, &setting, &description); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		switch name {
+		case "server_version", "server_version_num", "server_encoding", "client_encoding",
+			"lc_collate", "lc_ctype", "standard_conforming_strings":
+			settings[name] = setting
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return checkSettings(settings, minVersionNum)
+}
+
+// checkSettings checks PostgreSQL settings read by checkServer.
+func checkSettings(settings map[string]string, minVersionNum int) (*ServerInfo, error) {
+	get := func(name string) (string, error) {
+		setting, ok := settings[name]
+		if !ok {
+			return "", fmt.Errorf("PostgreSQL setting %q is not available", name)
+		}
+
+		return setting, nil
+	}
+
+	version, err := get("server_version")
+	if err != nil {
+		return nil, err
+	}
+
+	versionNumS, err := get("server_version_num")
+	if err != nil {
+		return nil, err
+	}
+
+	versionNum, err := strconv.Atoi(versionNumS)
+	if err != nil {
+		return nil, fmt.Errorf("PostgreSQL setting %q has invalid value %q", "server_version_num", versionNumS)
+	}
+
+	if versionNum < minVersionNum {
+		return nil, fmt.Errorf(
+			"PostgreSQL version %s (server_version_num %d) is not supported, the minimal supported version is %d",
+			version, versionNum, minVersionNum,
+		)
+	}
+
+	for _, name := range []string{"server_encoding", "client_encoding"} {
+		setting, err := get(name)
+		if err != nil {
+			return nil, err
+		}
+
+		if setting != encUTF8 {
+			return nil, fmt.Errorf("PostgreSQL setting %q is %q, want %q", name, setting, encUTF8)
+		}
+	}
+
+	for _, name := range []string{"lc_collate", "lc_ctype"} {
+		// they are not settings since PostgreSQL 16
+		setting, ok := settings[name]
+		if !ok {
+			continue
+		}
+
+		if setting != localeC && setting != localePOSIX && !isValidUTF8Locale(setting) {
+			return nil, fmt.Errorf("PostgreSQL setting %q is %q, want %q, %q, or UTF-8 locale", name, setting, localeC, localePOSIX)
+		}
+	}
+
+	setting, err := get("standard_conforming_strings")
+	if err != nil {
+		return nil, err
+	}
+
+	if setting != "on" {
+		return nil, fmt.Errorf("PostgreSQL setting %q is %q, want %q", "standard_conforming_strings", setting, "on")
+	}
+
+	return &ServerInfo{
+		Version:    version,
+		VersionNum: versionNum,
+		Settings:   settings,
+	}, nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgtype/pgxtype"
+	"github.com/jackc/pgx/v4"
+
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// serverSettingsSchema is a PostgreSQL schema with a settings table that stores server-wide FerretDB settings.
+//
+// It is not a FerretDB database: its name has a reserved prefix,
+// so clients can't access it, and it is not returned by Databases.
+const serverSettingsSchema = reservedPrefix + "server"
+
+// freeMonitoringKey is the field of the server settings document that stores free monitoring state.
+const freeMonitoringKey = "freeMonitoring"
+
+// GetFreeMonitoringState returns the stored free monitoring state.
+//
+// Empty string is returned if the state was never set.
+func GetFreeMonitoringState(ctx context.Context, querier pgxtype.Querier) (string, error) {
+	settings, err := getCachedSettings(ctx, querier, serverSettingsSchema)
+	if errors.Is(err, ErrSchemaNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", lazyerrors.Error(err)
+	}
+
+	if !settings.Has(freeMonitoringKey) {
+		return "", nil
+	}
+
+	v := must.NotFail(settings.Get(freeMonitoringKey))
+	state, ok := v.(string)
+	if !ok {
+		return "", lazyerrors.Errorf("invalid free monitoring state: %v", v)
+	}
+
+	return state, nil
+}
+
+// SetFreeMonitoringState stores the given free monitoring state.
+//
+// Server settings schema and table are created if they do not exist.
+func SetFreeMonitoringState(ctx context.Context, querier pgxtype.Querier, state string) error {
+	return InTransaction(ctx, querier, func(tx pgx.Tx) error {
+		// the lock also serializes creation of the server settings schema by concurrent clients
+		if err := lockSettings(ctx, tx, serverSettingsSchema); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `CREATE SCHEMA IF NOT EXISTS `+pgx.Identifier{serverSettingsSchema}.Sanitize()); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		if err := createSettingsTable(ctx, tx, serverSettingsSchema); err != nil && !errors.Is(err, ErrAlreadyExist) {
+			return lazyerrors.Error(err)
+		}
+
+		settings, err := getSettingsTableForUpdate(ctx, tx, serverSettingsSchema)
+		if err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		must.NoError(settings.Set(freeMonitoringKey, state))
+
+		if err = updateSettingsTable(ctx, tx, serverSettingsSchema, settings); err != nil {
+			return lazyerrors.Error(err)
+		}
+
+		return nil
+	})
+}
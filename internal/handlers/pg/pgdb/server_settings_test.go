@@ -0,0 +1,64 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v4"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestFreeMonitoringState(t *testing.T) {
+	// server settings are shared by the whole PostgreSQL database, so it should not run in parallel
+	// with other tests that change them
+
+	ctx := testutil.Ctx(t)
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+
+	drop := func() {
+		pool.Exec(ctx, `DROP SCHEMA IF EXISTS `+pgx.Identifier{serverSettingsSchema}.Sanitize()+` CASCADE`)
+	}
+	t.Cleanup(drop)
+	drop()
+
+	state, err := GetFreeMonitoringState(ctx, pool)
+	require.NoError(t, err)
+	assert.Empty(t, state)
+
+	require.NoError(t, SetFreeMonitoringState(ctx, pool, "enabled"))
+
+	state, err = GetFreeMonitoringState(ctx, pool)
+	require.NoError(t, err)
+	assert.Equal(t, "enabled", state)
+
+	databases, err := Databases(ctx, pool)
+	require.NoError(t, err)
+	assert.NotContains(t, databases, serverSettingsSchema)
+
+	require.NoError(t, SetFreeMonitoringState(ctx, pool, "disabled"))
+
+	// state is persisted and is visible to a new pool with empty settings cache
+	newPool := getPool(ctx, t, zaptest.NewLogger(t))
+
+	state, err = GetFreeMonitoringState(ctx, newPool)
+	require.NoError(t, err)
+	assert.Equal(t, "disabled", state)
+}
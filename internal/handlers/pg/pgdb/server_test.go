@@ -0,0 +1,216 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgtype/pgxtype"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+// showAllQuerier is a pgxtype.Querier that returns given settings for SHOW ALL.
+type showAllQuerier struct {
+	pgxtype.Querier // not implemented methods panic
+	settings        [][3]string
+	err             error
+}
+
+// Query implements pgxtype.Querier.
+func (q *showAllQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if sql != "SHOW ALL" {
+		return nil, errors.New("unexpected query " + sql)
+	}
+
+	if q.err != nil {
+		return nil, q.err
+	}
+
+	return &showAllRows{settings: q.settings, i: -1}, nil
+}
+
+// showAllRows is a pgx.Rows for SHOW ALL results.
+type showAllRows struct {
+	pgx.Rows // not implemented methods panic
+	settings [][3]string
+	i        int
+}
+
+// Close implements pgx.Rows.
+func (r *showAllRows) Close() {}
+
+// Err implements pgx.Rows.
+func (r *showAllRows) Err() error { return nil }
+
+// Next implements pgx.Rows.
+func (r *showAllRows) Next() bool {
+	r.i++
+	return r.i < len(r.settings)
+}
+
+// Scan implements pgx.Rows.
+func (r *showAllRows) Scan(dest ...any) error {
+	for i, d := range dest {
+		*d.(*string) = r.settings[r.i][i]
+	}
+
+	return nil
+}
+
+// testSettings returns SHOW ALL output with valid settings and the given overrides.
+// Empty override value removes the setting.
+func testSettings(overrides map[string]string) [][3]string {
+	settings := map[string]string{
+		"client_encoding":             "UTF8",
+		"lc_collate":                  "en_US.utf8",
+		"lc_ctype":                    "C",
+		"search_path":                 `"$user", public`,
+		"server_encoding":             "UTF8",
+		"server_version":              "14.5 (Debian 14.5-1.pgdg110+1)",
+		"server_version_num":          "140005",
+		"standard_conforming_strings": "on",
+	}
+
+	for k, v := range overrides {
+		if v == "" {
+			delete(settings, k)
+			continue
+		}
+
+		settings[k] = v
+	}
+
+	res := make([][3]string, 0, len(settings))
+	for k, v := range settings {
+		res = append(res, [3]string{k, v, "description"})
+	}
+
+	return res
+}
+
+func TestCheckServer(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	t.Run("Valid", func(t *testing.T) {
+		t.Parallel()
+
+		info, err := checkServer(ctx, &showAllQuerier{settings: testSettings(nil)}, DefaultMinServerVersionNum)
+		require.NoError(t, err)
+		assert.Equal(t, "14.5 (Debian 14.5-1.pgdg110+1)", info.Version)
+		assert.Equal(t, 140005, info.VersionNum)
+		assert.Equal(t, "on", info.Settings["standard_conforming_strings"])
+		assert.NotContains(t, info.Settings, "search_path")
+	})
+
+	t.Run("NoLocales", func(t *testing.T) {
+		t.Parallel()
+
+		settings := testSettings(map[string]string{"lc_collate": "", "lc_ctype": ""})
+		_, err := checkServer(ctx, &showAllQuerier{settings: settings}, DefaultMinServerVersionNum)
+		require.NoError(t, err)
+	})
+
+	t.Run("QueryError", func(t *testing.T) {
+		t.Parallel()
+
+		queryErr := errors.New("connection refused")
+		_, err := checkServer(ctx, &showAllQuerier{err: queryErr}, DefaultMinServerVersionNum)
+		require.ErrorIs(t, err, queryErr)
+	})
+
+	for name, tc := range map[string]struct {
+		overrides     map[string]string
+		minVersionNum int
+		err           string
+	}{
+		"OldVersion": {
+			overrides: map[string]string{"server_version": "11.16", "server_version_num": "110016"},
+			err:       "PostgreSQL version 11.16 (server_version_num 110016) is not supported, the minimal supported version is 120000",
+		},
+		"MinVersion": {
+			minVersionNum: 150000,
+			err:           "PostgreSQL version 14.5 (Debian 14.5-1.pgdg110+1) (server_version_num 140005) is not supported, the minimal supported version is 150000", //nolint:lll // error message is too long
+		},
+		"InvalidVersionNum": {
+			overrides: map[string]string{"server_version_num": "fourteen"},
+			err:       `PostgreSQL setting "server_version_num" has invalid value "fourteen"`,
+		},
+		"MissingVersion": {
+			overrides: map[string]string{"server_version": ""},
+			err:       `PostgreSQL setting "server_version" is not available`,
+		},
+		"ServerEncoding": {
+			overrides: map[string]string{"server_encoding": "LATIN1"},
+			err:       `PostgreSQL setting "server_encoding" is "LATIN1", want "UTF8"`,
+		},
+		"ClientEncoding": {
+			overrides: map[string]string{"client_encoding": "SQL_ASCII"},
+			err:       `PostgreSQL setting "client_encoding" is "SQL_ASCII", want "UTF8"`,
+		},
+		"Collate": {
+			overrides: map[string]string{"lc_collate": "de_DE.ISO-8859-1"},
+			err:       `PostgreSQL setting "lc_collate" is "de_DE.ISO-8859-1", want "C", "POSIX", or UTF-8 locale`,
+		},
+		"StandardConformingStrings": {
+			overrides: map[string]string{"standard_conforming_strings": "off"},
+			err:       `PostgreSQL setting "standard_conforming_strings" is "off", want "on"`,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			minVersionNum := tc.minVersionNum
+			if minVersionNum == 0 {
+				minVersionNum = DefaultMinServerVersionNum
+			}
+
+			_, err := checkServer(ctx, &showAllQuerier{settings: testSettings(tc.overrides)}, minVersionNum)
+			require.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestServerInfo(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+
+	info, err := pool.ServerInfo(ctx)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, info.VersionNum, DefaultMinServerVersionNum)
+	assert.NotEmpty(t, info.Version)
+
+	// cached
+	info2, err := pool.ServerInfo(ctx)
+	require.NoError(t, err)
+	assert.Same(t, info, info2)
+
+	_, err = NewPoolWithOpts(ctx, testutil.PostgreSQLURL(t, nil), zaptest.NewLogger(t), &NewPoolOpts{
+		MinServerVersionNum: 990000,
+	})
+	require.ErrorContains(t, err, "the minimal supported version is 990000")
+}
@@ -18,11 +18,15 @@ import (
 	"context"
 	"fmt"
 	"hash/fnv"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgtype/pgxtype"
 	"github.com/jackc/pgx/v4"
+	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
 
 	"github.com/FerretDB/FerretDB/internal/fjson"
@@ -78,9 +82,11 @@ func createSettingsTable(ctx context.Context, querier pgxtype.Querier, db string
 		}
 	}
 
-	settings := must.NotFail(types.NewDocument("collections", must.NotFail(types.NewDocument())))
+	defer settingsCacheFor(querier).invalidate(querier, db)
+
+	settings := newSettings(must.NotFail(types.NewDocument()))
 	sql = fmt.Sprintf(`INSERT INTO %s (settings) VALUES ($1)`, pgx.Identifier{db, settingsTableName}.Sanitize())
-	_, err = querier.Exec(ctx, sql, must.NotFail(fjson.Marshal(settings)))
+	_, err = querier.Exec(ctx, sql, string(must.NotFail(fjson.Marshal(settings))))
 	if err != nil {
 		return lazyerrors.Error(err)
 	}
@@ -92,6 +98,14 @@ func createSettingsTable(ctx context.Context, querier pgxtype.Querier, db string
 // If the settings table doesn't exist, it will be created.
 // If the record for collection doesn't exist, it will be created.
 func getTableName(ctx context.Context, querier pgxtype.Querier, db, collection string) (string, error) {
+	if settings, _ := settingsCacheFor(querier).get(querier, db); settings != nil {
+		if collections, ok := must.NotFail(settings.Get("collections")).(*types.Document); ok && collections.Has(collection) {
+			if table, ok := must.NotFail(collections.Get(collection)).(string); ok {
+				return table, nil
+			}
+		}
+	}
+
 	schemaExists, err := schemaExists(ctx, querier, db)
 	if err != nil {
 		return "", lazyerrors.Error(err)
@@ -101,34 +115,38 @@ func getTableName(ctx context.Context, querier pgxtype.Querier, db, collection s
 		return formatCollectionName(collection), nil
 	}
 
-	tables, err := tables(ctx, querier, db)
+	relations, err := schemaRelations(ctx, querier, db)
 	if err != nil {
 		return "", lazyerrors.Error(err)
 	}
 
-	if !slices.Contains(tables, settingsTableName) {
+	if !relations[settingsTableName].table {
 		err = createSettingsTable(ctx, querier, db)
 		if err != nil {
 			return "", err
 		}
 	}
 
-	settings, err := getSettingsTable(ctx, querier, db)
-	if err != nil {
-		return "", lazyerrors.Error(err)
-	}
+	// read without locking first, and lock only if the mapping should be added
+	var settings *types.Document
+	var collections *types.Document
+	for _, forUpdate := range []bool{false, true} {
+		if settings, err = readSettingsTable(ctx, querier, db, forUpdate); err != nil {
+			return "", lazyerrors.Error(err)
+		}
 
-	collectionsDoc := must.NotFail(settings.Get("collections"))
-	collections, ok := collectionsDoc.(*types.Document)
-	if !ok {
-		return "", lazyerrors.Errorf("expected document but got %[1]T: %[1]v", collectionsDoc)
-	}
+		collectionsDoc := must.NotFail(settings.Get("collections"))
+		var ok bool
+		if collections, ok = collectionsDoc.(*types.Document); !ok {
+			return "", lazyerrors.Errorf("expected document but got %[1]T: %[1]v", collectionsDoc)
+		}
 
-	if collections.Has(collection) {
-		return must.NotFail(collections.Get(collection)).(string), nil
+		if collections.Has(collection) {
+			return must.NotFail(collections.Get(collection)).(string), nil
+		}
 	}
 
-	tableName := formatCollectionName(collection)
+	tableName := newTableName(collection, collections, maps.Keys(relations))
 	must.NoError(collections.Set(collection, tableName))
 	must.NoError(settings.Set("collections", collections))
 
@@ -140,9 +158,83 @@ func getTableName(ctx context.Context, querier pgxtype.Querier, db, collection s
 	return tableName, nil
 }
 
+// getCachedSettings returns FerretDB settings from the cache, or reads and caches them.
+//
+// It should be used only for reading; use getSettingsTable for read-modify-write cycles.
+//
+// It returns (possibly wrapped) ErrSchemaNotExist if FerretDB database / PostgreSQL schema does not exist.
+func getCachedSettings(ctx context.Context, querier pgxtype.Querier, db string) (*types.Document, error) {
+	settings, gen := settingsCacheFor(querier).get(querier, db)
+	if settings != nil {
+		return settings, nil
+	}
+
+	schemaExists, err := schemaExists(ctx, querier, db)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if !schemaExists {
+		return nil, ErrSchemaNotExist
+	}
+
+	if settings, err = getSettingsTable(ctx, querier, db); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	settingsCacheFor(querier).set(querier, db, settings, gen)
+
+	return settings, nil
+}
+
 // getSettingsTable returns FerretDB settings table.
+//
+// It always reads the table, bypassing the cache.
 func getSettingsTable(ctx context.Context, querier pgxtype.Querier, db string) (*types.Document, error) {
+	return readSettingsTable(ctx, querier, db, false)
+}
+
+// getSettingsTableForUpdate is getSettingsTable that also locks the settings row until the end of the transaction.
+//
+// It should be used for read-modify-write cycles, so concurrent transactions
+// that modify settings of the same database do not overwrite each other's changes.
+func getSettingsTableForUpdate(ctx context.Context, querier pgxtype.Querier, db string) (*types.Document, error) {
+	return readSettingsTable(ctx, querier, db, true)
+}
+
+// readSettingsTable reads FerretDB settings table, optionally locking it for update.
+//
+// Settings of older versions are migrated first, see migrateSettings.
+// It returns (possibly wrapped) ErrSettingsVersion if settings were created by a newer FerretDB version.
+func readSettingsTable(ctx context.Context, querier pgxtype.Querier, db string, forUpdate bool) (*types.Document, error) {
+	settings, err := readSettingsDocument(ctx, querier, db, forUpdate)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	migrate, err := checkSettingsVersion(db, settings)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if !migrate {
+		return settings, nil
+	}
+
+	if err = migrateSettings(ctx, querier, db); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return readSettingsDocument(ctx, querier, db, forUpdate)
+}
+
+// readSettingsDocument reads FerretDB settings document as is, optionally locking it for update.
+func readSettingsDocument(ctx context.Context, querier pgxtype.Querier, db string, forUpdate bool) (*types.Document, error) {
 	sql := `SELECT settings FROM ` + pgx.Identifier{db, settingsTableName}.Sanitize()
+	if forUpdate {
+		sql += ` FOR UPDATE`
+	}
+
 	rows, err := querier.Query(ctx, sql)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
@@ -173,25 +265,35 @@ func getSettingsTable(ctx context.Context, querier pgxtype.Querier, db string) (
 
 // updateSettingsTable updates FerretDB settings table.
 func updateSettingsTable(ctx context.Context, querier pgxtype.Querier, db string, settings *types.Document) error {
+	defer settingsCacheFor(querier).invalidate(querier, db)
+
 	sql := `UPDATE ` + pgx.Identifier{db, settingsTableName}.Sanitize() + `SET settings = $1`
-	_, err := querier.Exec(ctx, sql, must.NotFail(fjson.Marshal(settings)))
+	_, err := querier.Exec(ctx, sql, string(must.NotFail(fjson.Marshal(settings))))
 	return err
 }
 
 // removeTableFromSettings removes collection from FerretDB settings table.
-func removeTableFromSettings(ctx context.Context, querier pgxtype.Querier, db, collection string) error {
-	settings, err := getSettingsTable(ctx, querier, db)
+//
+// It returns the name of the table that was mapped to the collection,
+// or ErrTableNotExist if there was no mapping.
+func removeTableFromSettings(ctx context.Context, querier pgxtype.Querier, db, collection string) (string, error) {
+	settings, err := getSettingsTableForUpdate(ctx, querier, db)
 	if err != nil {
-		return lazyerrors.Error(err)
+		return "", lazyerrors.Error(err)
 	}
 
 	collections, ok := must.NotFail(settings.Get("collections")).(*types.Document)
 	if !ok {
-		return lazyerrors.Errorf("invalid settings document")
+		return "", lazyerrors.Errorf("invalid settings document")
 	}
 
 	if !collections.Has(collection) {
-		return ErrTableNotExist
+		return "", ErrTableNotExist
+	}
+
+	table, ok := must.NotFail(collections.Get(collection)).(string)
+	if !ok {
+		return "", lazyerrors.Errorf("invalid table name for collection %q", collection)
 	}
 
 	collections.Remove(collection)
@@ -199,6 +301,49 @@ func removeTableFromSettings(ctx context.Context, querier pgxtype.Querier, db, c
 	must.NoError(settings.Set("collections", collections))
 
 	if err := updateSettingsTable(ctx, querier, db, settings); err != nil {
+		return "", lazyerrors.Error(err)
+	}
+
+	return table, nil
+}
+
+// repairSettingsTable fills FerretDB settings table with collections
+// restored from existing plain tables with jsonb _jsonb column.
+//
+// It is used for databases that lost their settings table.
+// Collection names are restored from table names created by formatCollectionName if possible;
+// otherwise, the table name is used as collection name.
+func repairSettingsTable(ctx context.Context, querier pgxtype.Querier, db string) error {
+	relations, err := schemaRelations(ctx, querier, db)
+	if err != nil {
+		return lazyerrors.Error(err)
+	}
+
+	tables := make([]string, 0, len(relations))
+	for table, rel := range relations {
+		if rel.collection && !strings.HasPrefix(table, reservedPrefix) {
+			tables = append(tables, table)
+		}
+	}
+
+	slices.Sort(tables)
+
+	collections := must.NotFail(types.NewDocument())
+	for _, table := range tables {
+		collection := table
+		if i := strings.LastIndexByte(table, '_'); i > 0 && formatCollectionName(table[:i]) == table {
+			collection = table[:i]
+		}
+
+		if collections.Has(collection) {
+			collection = table
+		}
+
+		must.NoError(collections.Set(collection, table))
+	}
+
+	settings := newSettings(collections)
+	if err = updateSettingsTable(ctx, querier, db, settings); err != nil {
 		return lazyerrors.Error(err)
 	}
 
@@ -206,6 +351,9 @@ func removeTableFromSettings(ctx context.Context, querier pgxtype.Querier, db, c
 }
 
 // formatCollectionName returns collection name in form <shortened_name>_<name_hash>.
+//
+// The name is shortened at UTF-8 character boundary so the result never exceeds
+// PostgreSQL identifier length limit and is always valid UTF-8.
 func formatCollectionName(name string) string {
 	hash32 := fnv.New32a()
 	_ = must.NotFail(hash32.Write([]byte(name)))
@@ -214,7 +362,37 @@ func formatCollectionName(name string) string {
 	truncateTo := len(name)
 	if truncateTo > nameSymbolsLeft {
 		truncateTo = nameSymbolsLeft
+		for truncateTo > 0 && !utf8.RuneStart(name[truncateTo]) {
+			truncateTo--
+		}
 	}
 
 	return name[:truncateTo] + "_" + fmt.Sprintf("%x", hash32.Sum([]byte{}))
 }
+
+// newTableName returns a new table name for the given collection.
+//
+// Usually, it is the result of formatCollectionName.
+// If that table name is already used (by existing table or by another collection in the mapping),
+// it is disambiguated by hashing the collection name with a numeric suffix.
+func newTableName(collection string, collections *types.Document, tables []string) string {
+	used := make(map[string]struct{}, len(tables)+collections.Len())
+	for _, t := range tables {
+		used[t] = struct{}{}
+	}
+
+	for _, c := range collections.Keys() {
+		if t, ok := must.NotFail(collections.Get(c)).(string); ok {
+			used[t] = struct{}{}
+		}
+	}
+
+	table := formatCollectionName(collection)
+	for i := 1; ; i++ {
+		if _, ok := used[table]; !ok {
+			return table
+		}
+
+		table = formatCollectionName(collection + "_" + strconv.Itoa(i))
+	}
+}
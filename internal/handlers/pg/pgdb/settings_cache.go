@@ -0,0 +1,226 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jackc/pgtype/pgxtype"
+	"github.com/jackc/pgx/v4"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+)
+
+// settingsCacheTTL is the time after which cached settings are re-read
+// to pick up changes made by other FerretDB instances.
+const settingsCacheTTL = 5 * time.Second
+
+// settingsCache is a concurrency-safe cache of FerretDB settings documents, keyed by database / PostgreSQL schema.
+//
+// Each Pool has its own cache, see settingsCacheFor.
+// Entries are populated lazily by readers and are invalidated by writers both immediately
+// and after the end of the transaction that changed settings (see Pool.InTransaction).
+// Stale positive entries (for tables dropped by other FerretDB instances)
+// are handled by Pool.InTransactionRetry.
+//
+// All methods can be called on nil cache; they do nothing in that case.
+type settingsCache struct {
+	rw      sync.RWMutex
+	entries map[string]settingsCacheEntry
+
+	// generations are incremented on each invalidation
+	// to prevent caching values read before it
+	generations map[string]uint64
+
+	// pending contains databases changed in not finished transactions, keyed by connection
+	pending map[*pgx.Conn]map[string]struct{}
+
+	// used contains databases which cached settings were used by not finished transactions, keyed by connection
+	used map[*pgx.Conn]map[string]struct{}
+}
+
+// settingsCacheEntry represents a single settingsCache entry.
+type settingsCacheEntry struct {
+	settings *types.Document
+	expires  time.Time
+}
+
+// newSettingsCache creates a new empty settings cache.
+func newSettingsCache() *settingsCache {
+	return &settingsCache{
+		entries:     map[string]settingsCacheEntry{},
+		generations: map[string]uint64{},
+		pending:     map[*pgx.Conn]map[string]struct{}{},
+		used:        map[*pgx.Conn]map[string]struct{}{},
+	}
+}
+
+// settingsCacheFor returns the settings cache of the Pool the given querier belongs to.
+//
+// It returns nil for queriers that were not created by Pool (for example, *pgx.Conn),
+// so settings are not cached for them.
+func settingsCacheFor(querier pgxtype.Querier) *settingsCache {
+	if pgPool := poolFor(querier); pgPool != nil {
+		return pgPool.settingsCache
+	}
+
+	return nil
+}
+
+// get returns a copy of cached settings for the given database, or nil.
+// It also returns the current generation that should be passed to set.
+//
+// If querier is a transaction, the usage of cached settings is recorded,
+// so they could be invalidated by endTransaction if they turn out to be stale.
+func (c *settingsCache) get(querier pgxtype.Querier, db string) (*types.Document, uint64) {
+	if c == nil {
+		return nil, 0
+	}
+
+	c.rw.Lock()
+	defer c.rw.Unlock()
+
+	gen := c.generations[db]
+
+	e, ok := c.entries[db]
+	if !ok || time.Now().After(e.expires) {
+		return nil, gen
+	}
+
+	if conn := querierConn(querier); conn != nil {
+		addConnDatabase(c.used, conn, db)
+	}
+
+	return e.settings.DeepCopy(), gen
+}
+
+// set caches a copy of settings for the given database
+// unless they were invalidated after get returned the given generation,
+// or they were read by the querier in the transaction that changed them.
+func (c *settingsCache) set(querier pgxtype.Querier, db string, settings *types.Document, gen uint64) {
+	if c == nil {
+		return
+	}
+
+	c.rw.Lock()
+	defer c.rw.Unlock()
+
+	if c.generations[db] != gen {
+		return
+	}
+
+	if conn := querierConn(querier); conn != nil {
+		if _, ok := c.pending[conn][db]; ok {
+			return
+		}
+	}
+
+	c.entries[db] = settingsCacheEntry{
+		settings: settings.DeepCopy(),
+		expires:  time.Now().Add(settingsCacheTTL),
+	}
+}
+
+// invalidate removes cached settings for the given database.
+//
+// If querier is a transaction, settings are invalidated again by endTransaction.
+func (c *settingsCache) invalidate(querier pgxtype.Querier, db string) {
+	if c == nil {
+		return
+	}
+
+	c.rw.Lock()
+	defer c.rw.Unlock()
+
+	c.invalidateLocked(db)
+
+	if conn := querierConn(querier); conn != nil {
+		addConnDatabase(c.pending, conn, db)
+	}
+}
+
+// invalidateAll removes all cached settings.
+func (c *settingsCache) invalidateAll() {
+	if c == nil {
+		return
+	}
+
+	c.rw.Lock()
+	defer c.rw.Unlock()
+
+	for db := range c.entries {
+		c.invalidateLocked(db)
+	}
+}
+
+// endTransaction invalidates settings changed in the finished (committed or rolled back) transaction.
+//
+// If stale is true, cached settings used by that transaction are invalidated too,
+// and names of their databases are returned.
+func (c *settingsCache) endTransaction(conn *pgx.Conn, stale bool) []string {
+	if c == nil {
+		return nil
+	}
+
+	c.rw.Lock()
+	defer c.rw.Unlock()
+
+	for db := range c.pending[conn] {
+		c.invalidateLocked(db)
+	}
+
+	var res []string
+	if stale {
+		for db := range c.used[conn] {
+			c.invalidateLocked(db)
+			res = append(res, db)
+		}
+	}
+
+	delete(c.pending, conn)
+	delete(c.used, conn)
+
+	return res
+}
+
+// invalidateLocked removes cached settings for the given database. Write lock must be held.
+func (c *settingsCache) invalidateLocked(db string) {
+	delete(c.entries, db)
+	c.generations[db]++
+}
+
+// addConnDatabase adds the database to the set of the given connection.
+func addConnDatabase(m map[*pgx.Conn]map[string]struct{}, conn *pgx.Conn, db string) {
+	dbs := m[conn]
+	if dbs == nil {
+		dbs = map[string]struct{}{}
+		m[conn] = dbs
+	}
+
+	dbs[db] = struct{}{}
+}
+
+// querierConn returns connection of the transaction, or nil if the querier is not a transaction.
+//
+// The same connection is returned for transaction's savepoints.
+func querierConn(querier pgxtype.Querier) *pgx.Conn {
+	tx, ok := querier.(pgx.Tx)
+	if !ok {
+		return nil
+	}
+
+	return tx.Conn()
+}
@@ -0,0 +1,305 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestSettingsCache(t *testing.T) {
+	t.Parallel()
+
+	settings := must.NotFail(types.NewDocument("collections", must.NotFail(types.NewDocument("foo", "foo_table"))))
+
+	t.Run("GetSet", func(t *testing.T) {
+		t.Parallel()
+
+		c := newSettingsCache()
+
+		actual, gen := c.get(nil, "db")
+		assert.Nil(t, actual)
+
+		c.set(nil, "db", settings, gen)
+		actual, _ = c.get(nil, "db")
+		assert.Equal(t, settings, actual)
+
+		// a copy is returned
+		must.NoError(actual.Set("collections", "changed"))
+		actual, _ = c.get(nil, "db")
+		assert.Equal(t, settings, actual)
+	})
+
+	t.Run("Invalidate", func(t *testing.T) {
+		t.Parallel()
+
+		c := newSettingsCache()
+
+		_, gen := c.get(nil, "db")
+		c.set(nil, "db", settings, gen)
+		c.invalidate(nil, "db")
+
+		actual, _ := c.get(nil, "db")
+		assert.Nil(t, actual)
+
+		// old generation is rejected
+		c.set(nil, "db", settings, gen)
+		actual, _ = c.get(nil, "db")
+		assert.Nil(t, actual)
+	})
+
+	t.Run("InvalidateAll", func(t *testing.T) {
+		t.Parallel()
+
+		c := newSettingsCache()
+
+		_, gen1 := c.get(nil, "db1")
+		c.set(nil, "db1", settings, gen1)
+		_, gen2 := c.get(nil, "db2")
+		c.set(nil, "db2", settings, gen2)
+		c.invalidateAll()
+
+		actual, _ := c.get(nil, "db1")
+		assert.Nil(t, actual)
+		actual, _ = c.get(nil, "db2")
+		assert.Nil(t, actual)
+	})
+
+	t.Run("Stale", func(t *testing.T) {
+		t.Parallel()
+
+		c := newSettingsCache()
+		tx := &fakeTx{conn: new(pgx.Conn)}
+
+		for _, db := range []string{"db1", "db2", "db3"} {
+			_, gen := c.get(nil, db)
+			c.set(nil, db, settings, gen)
+		}
+
+		// only db1 and db2 are used by the transaction
+		_, _ = c.get(tx, "db1")
+		_, _ = c.get(tx, "db2")
+
+		assert.Empty(t, c.endTransaction(tx.conn, false))
+		actual, _ := c.get(nil, "db1")
+		assert.NotNil(t, actual, "settings are not invalidated for successful transactions")
+
+		_, _ = c.get(tx, "db1")
+		_, _ = c.get(tx, "db2")
+
+		assert.ElementsMatch(t, []string{"db1", "db2"}, c.endTransaction(tx.conn, true))
+
+		actual, _ = c.get(nil, "db1")
+		assert.Nil(t, actual)
+		actual, _ = c.get(nil, "db2")
+		assert.Nil(t, actual)
+		actual, _ = c.get(nil, "db3")
+		assert.Equal(t, settings, actual)
+	})
+
+	t.Run("Nil", func(t *testing.T) {
+		t.Parallel()
+
+		var c *settingsCache
+
+		c.set(nil, "db", settings, 0)
+		actual, _ := c.get(nil, "db")
+		assert.Nil(t, actual)
+
+		c.invalidate(nil, "db")
+		c.invalidateAll()
+		assert.Nil(t, c.endTransaction(nil, true))
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		t.Parallel()
+
+		c := newSettingsCache()
+
+		_, gen := c.get(nil, "db")
+		c.set(nil, "db", settings, gen)
+		e := c.entries["db"]
+		e.expires = time.Now().Add(-time.Second)
+		c.entries["db"] = e
+
+		actual, _ := c.get(nil, "db")
+		assert.Nil(t, actual)
+	})
+}
+
+// fakeTx is a pgx.Tx that only implements Conn.
+type fakeTx struct {
+	pgx.Tx
+	conn *pgx.Conn
+}
+
+// Conn implements pgx.Tx.
+func (tx *fakeTx) Conn() *pgx.Conn {
+	return tx.conn
+}
+
+func TestSettingsCachePools(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool1 := getPool(ctx, t, zaptest.NewLogger(t))
+	pool2 := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool1.DropDatabase(ctx, dbName)
+	})
+
+	pool1.DropDatabase(ctx, dbName)
+	require.NoError(t, CreateDatabase(ctx, pool1, dbName))
+	require.NoError(t, CreateCollection(ctx, pool1, dbName, collectionName))
+
+	_, err := getCachedSettings(ctx, pool1, dbName)
+	require.NoError(t, err)
+
+	err = pool1.InTransaction(ctx, func(tx pgx.Tx) error {
+		assert.Same(t, pool1.settingsCache, settingsCacheFor(tx))
+
+		return InTransaction(ctx, tx, func(savepoint pgx.Tx) error {
+			assert.Same(t, pool1.settingsCache, settingsCacheFor(savepoint))
+			return nil
+		})
+	})
+	require.NoError(t, err)
+
+	// each pool has its own cache
+	actual, _ := pool1.settingsCache.get(nil, dbName)
+	assert.NotNil(t, actual)
+	actual, _ = pool2.settingsCache.get(nil, dbName)
+	assert.Nil(t, actual)
+}
+
+func TestSettingsCacheStale(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(t, CreateDatabase(ctx, pool, dbName))
+	require.NoError(t, InsertDocument(ctx, pool, dbName, collectionName, must.NotFail(types.NewDocument("_id", int32(1)))))
+
+	// populate cache
+	exists, err := CollectionExists(ctx, pool, dbName, collectionName)
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	table, err := getTableName(ctx, pool, dbName, collectionName)
+	require.NoError(t, err)
+
+	// emulate another FerretDB instance dropping the collection
+	_, err = pool.Exec(ctx, `DROP TABLE `+pgx.Identifier{dbName, table}.Sanitize())
+	require.NoError(t, err)
+	_, err = pool.Exec(
+		ctx,
+		`UPDATE `+pgx.Identifier{dbName, settingsTableName}.Sanitize()+` SET settings = $1`,
+		`{"$k": ["collections"], "collections": {"$k": []}}`,
+	)
+	require.NoError(t, err)
+
+	// settings are still cached, but dropped tables are not reported as collections
+	exists, err = CollectionExists(ctx, pool, dbName, collectionName)
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	sp := SQLParam{DB: dbName, Collection: collectionName}
+
+	var docs []*types.Document
+	err = pool.InTransactionRetry(ctx, func(tx pgx.Tx) error {
+		docs = nil
+
+		fetchedChan, _, err := pool.QueryDocuments(ctx, tx, sp)
+		if err != nil {
+			return err
+		}
+
+		for f := range fetchedChan {
+			if f.Err != nil {
+				return f.Err
+			}
+			docs = append(docs, f.Docs...)
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Empty(t, docs)
+
+	exists, err = CollectionExists(ctx, pool, dbName, collectionName)
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func BenchmarkQueryDocumentsSettingsCache(b *testing.B) {
+	ctx := testutil.Ctx(b)
+
+	pool := getPool(ctx, b, zaptest.NewLogger(b))
+	dbName := testutil.DatabaseName(b)
+	collectionName := testutil.CollectionName(b)
+
+	b.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(b, CreateDatabase(ctx, pool, dbName))
+	require.NoError(b, InsertDocument(ctx, pool, dbName, collectionName, must.NotFail(types.NewDocument("_id", int32(1)))))
+
+	sp := SQLParam{DB: dbName, Collection: collectionName, Limit: 1}
+
+	for name, invalidate := range map[string]bool{
+		"Cached":   false,
+		"Uncached": true,
+	} {
+		invalidate := invalidate
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if invalidate {
+					pool.settingsCache.invalidate(nil, dbName)
+				}
+
+				fetchedChan, _, err := pool.QueryDocuments(ctx, pool, sp)
+				require.NoError(b, err)
+
+				for f := range fetchedChan {
+					require.NoError(b, f.Err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestFormatCollectionName(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		collection string
+		expected   string
+	}{
+		"Short": {
+			collection: "users",
+			expected:   "users_5e7cc513",
+		},
+		"Long": {
+			collection: strings.Repeat("a", 210),
+			expected:   strings.Repeat("a", 54) + "_8cfcfd87",
+		},
+		"LongNonASCII": {
+			collection: strings.Repeat("коллекция", 25),
+		},
+		"LongMixed": {
+			collection: "a" + strings.Repeat("日本語", 70),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual := formatCollectionName(tc.collection)
+			assert.LessOrEqual(t, len(actual), maxTableNameLength)
+			assert.True(t, utf8.ValidString(actual), "%q", actual)
+			assert.Equal(t, actual, formatCollectionName(tc.collection), "must be deterministic")
+
+			if tc.expected != "" {
+				assert.Equal(t, tc.expected, actual)
+			}
+		})
+	}
+
+	t.Run("SamePrefix", func(t *testing.T) {
+		t.Parallel()
+
+		prefix := strings.Repeat("ü", 100)
+		assert.NotEqual(t, formatCollectionName(prefix+"1"), formatCollectionName(prefix+"2"))
+	})
+}
+
+func TestNewTableName(t *testing.T) {
+	t.Parallel()
+
+	collection := strings.Repeat("x", 200)
+	table := formatCollectionName(collection)
+
+	t.Run("NoCollision", func(t *testing.T) {
+		t.Parallel()
+
+		collections := must.NotFail(types.NewDocument("other", "other_table"))
+		assert.Equal(t, table, newTableName(collection, collections, []string{"other_table"}))
+	})
+
+	t.Run("TableCollision", func(t *testing.T) {
+		t.Parallel()
+
+		collections := must.NotFail(types.NewDocument())
+		actual := newTableName(collection, collections, []string{table})
+		assert.NotEqual(t, table, actual)
+		assert.LessOrEqual(t, len(actual), maxTableNameLength)
+	})
+
+	t.Run("MappingCollision", func(t *testing.T) {
+		t.Parallel()
+
+		collections := must.NotFail(types.NewDocument("other", table))
+		actual := newTableName(collection, collections, nil)
+		assert.NotEqual(t, table, actual)
+
+		must.NoError(collections.Set(collection, actual))
+		assert.NotContains(t, []string{table, actual}, newTableName(collection+"2", collections, nil))
+	})
+}
+
+func TestLongCollectionNames(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(t, CreateDatabase(ctx, pool, dbName))
+
+	// 2 bytes per character, more than 200 bytes total, the same shortened prefix
+	prefix := strings.Repeat("ж", 110)
+	collection1 := prefix + "_1"
+	collection2 := prefix + "_2"
+
+	require.NoError(t, CreateCollection(ctx, pool, dbName, collection1))
+	require.NoError(t, CreateCollection(ctx, pool, dbName, collection2))
+	require.ErrorIs(t, CreateCollection(ctx, pool, dbName, collection1), ErrAlreadyExist)
+
+	require.NoError(t, InsertDocument(ctx, pool, dbName, collection1, must.NotFail(types.NewDocument("_id", int32(1)))))
+
+	collections, err := Collections(ctx, pool, dbName)
+	require.NoError(t, err)
+	assert.Equal(t, []string{collection1, collection2}, collections)
+
+	table1, err := getTableName(ctx, pool, dbName, collection1)
+	require.NoError(t, err)
+	table2, err := getTableName(ctx, pool, dbName, collection2)
+	require.NoError(t, err)
+	assert.NotEqual(t, table1, table2)
+
+	n, err := CountDocuments(ctx, pool, dbName, collection1, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	n, err = CountDocuments(ctx, pool, dbName, collection2, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), n)
+
+	require.NoError(t, DropCollection(ctx, pool, dbName, collection1))
+	require.ErrorIs(t, DropCollection(ctx, pool, dbName, collection1), ErrTableNotExist)
+
+	collections, err = Collections(ctx, pool, dbName)
+	require.NoError(t, err)
+	assert.Equal(t, []string{collection2}, collections)
+}
+
+func TestSettingsMigration(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+
+	// createV0 creates a database with settings table as it was created by FerretDB before settings versioning
+	createV0 := func(t *testing.T, dbName string) {
+		t.Helper()
+
+		t.Cleanup(func() {
+			pool.DropDatabase(ctx, dbName)
+		})
+
+		pool.DropDatabase(ctx, dbName)
+
+		for _, sql := range []string{
+			`CREATE SCHEMA ` + pgx.Identifier{dbName}.Sanitize(),
+			`CREATE TABLE ` + pgx.Identifier{dbName, settingsTableName}.Sanitize() + ` (settings jsonb)`,
+			`INSERT INTO ` + pgx.Identifier{dbName, settingsTableName}.Sanitize() + ` (settings) VALUES (` +
+				`'{"$k": ["collections"], "collections": {"$k": ["foo", "bar"], "foo": "foo_table", "bar": "bar_table"}}')`,
+			`CREATE TABLE ` + pgx.Identifier{dbName, "foo_table"}.Sanitize() + ` (_jsonb jsonb)`,
+			`CREATE TABLE ` + pgx.Identifier{dbName, "bar_table"}.Sanitize() + ` (_jsonb jsonb)`,
+		} {
+			_, err := pool.Exec(ctx, sql)
+			require.NoError(t, err)
+		}
+	}
+
+	t.Run("V0", func(t *testing.T) {
+		t.Parallel()
+
+		dbName := testutil.DatabaseName(t)
+		createV0(t, dbName)
+
+		// first access migrates settings transparently
+		collections, err := Collections(ctx, pool, dbName)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"bar", "foo"}, collections)
+
+		settings, err := readSettingsDocument(ctx, pool, dbName, false)
+		require.NoError(t, err)
+
+		version, err := getSettingsVersion(settings)
+		require.NoError(t, err)
+		assert.Equal(t, settingsVersion, version)
+
+		expected := must.NotFail(types.NewDocument("bar", "bar_table", "foo", "foo_table"))
+		assert.Equal(t, expected, must.NotFail(settings.Get("collections")))
+
+		// existing data is still accessible, and new collections could be created
+		require.NoError(t, InsertDocument(ctx, pool, dbName, "foo", must.NotFail(types.NewDocument("_id", int32(1)))))
+		require.NoError(t, CreateCollection(ctx, pool, dbName, "baz"))
+
+		collections, err = Collections(ctx, pool, dbName)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"bar", "baz", "foo"}, collections)
+	})
+
+	t.Run("Newer", func(t *testing.T) {
+		t.Parallel()
+
+		dbName := testutil.DatabaseName(t)
+		createV0(t, dbName)
+
+		_, err := pool.Exec(
+			ctx,
+			`UPDATE `+pgx.Identifier{dbName, settingsTableName}.Sanitize()+` SET settings = $1`,
+			`{"$k": ["collections", "version"], "collections": {"$k": []}, "version": 1000}`,
+		)
+		require.NoError(t, err)
+
+		_, err = Collections(ctx, pool, dbName)
+		require.ErrorIs(t, err, ErrSettingsVersion)
+		assert.Contains(t, err.Error(), "1000")
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		t.Parallel()
+
+		dbName := testutil.DatabaseName(t)
+		createV0(t, dbName)
+
+		const n = 10
+		errs := make(chan error, n)
+		for i := 0; i < n; i++ {
+			go func() {
+				_, err := getSettingsTable(ctx, pool, dbName)
+				errs <- err
+			}()
+		}
+
+		for i := 0; i < n; i++ {
+			require.NoError(t, <-errs)
+		}
+
+		settings, err := readSettingsDocument(ctx, pool, dbName, false)
+		require.NoError(t, err)
+		assert.Equal(t, settingsVersion, must.NotFail(settings.Get("version")))
+	})
+}
@@ -0,0 +1,90 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgtype/pgxtype"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// DatabaseSize returns the total size in bytes of all FerretDB collections' tables
+// (including indexes and TOAST data) in the given FerretDB database / PostgreSQL schema.
+//
+// It returns 0 if the database does not exist.
+func DatabaseSize(ctx context.Context, querier pgxtype.Querier, db string) (int64, error) {
+	sql := `SELECT COALESCE(SUM(pg_total_relation_size(c.oid)), 0) ` +
+		`FROM pg_catalog.pg_class c JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace ` +
+		`WHERE n.nspname = $1 AND c.relkind = 'r' AND left(c.relname, $2) <> $3`
+
+	var size int64
+	if err := querier.QueryRow(ctx, sql, db, len(reservedPrefix), reservedPrefix).Scan(&size); err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	return size, nil
+}
+
+// CollectionSize returns the total size in bytes of FerretDB collection's table
+// including indexes and TOAST data.
+//
+// It returns 0 if the database or collection does not exist.
+func CollectionSize(ctx context.Context, querier pgxtype.Querier, db, collection string) (int64, error) {
+	return collectionSize(ctx, querier, db, collection, "pg_total_relation_size")
+}
+
+// CollectionDataSize returns the size in bytes of FerretDB collection's table without indexes.
+//
+// It returns 0 if the database or collection does not exist.
+func CollectionDataSize(ctx context.Context, querier pgxtype.Querier, db, collection string) (int64, error) {
+	return collectionSize(ctx, querier, db, collection, "pg_relation_size")
+}
+
+// collectionSize returns the size of FerretDB collection's table using the given PostgreSQL size function.
+func collectionSize(ctx context.Context, querier pgxtype.Querier, db, collection, sizeFunc string) (int64, error) {
+	settings, err := getCachedSettings(ctx, querier, db)
+	if err != nil {
+		if errors.Is(err, ErrSchemaNotExist) {
+			return 0, nil
+		}
+		return 0, lazyerrors.Error(err)
+	}
+
+	collections, ok := must.NotFail(settings.Get("collections")).(*types.Document)
+	if !ok || !collections.Has(collection) {
+		return 0, nil
+	}
+
+	table, ok := must.NotFail(collections.Get(collection)).(string)
+	if !ok {
+		return 0, lazyerrors.Errorf("invalid table name for collection %q", collection)
+	}
+
+	sql := `SELECT COALESCE(SUM(` + sizeFunc + `(c.oid)), 0) ` +
+		`FROM pg_catalog.pg_class c JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace ` +
+		`WHERE n.nspname = $1 AND c.relname = $2 AND c.relkind = 'r'`
+
+	var size int64
+	if err = querier.QueryRow(ctx, sql, db, table).Scan(&size); err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	return size, nil
+}
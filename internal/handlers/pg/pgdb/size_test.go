@@ -0,0 +1,95 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestSizes(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+
+	t.Run("NotExist", func(t *testing.T) {
+		t.Parallel()
+
+		databaseName := testutil.DatabaseName(t)
+		collectionName := testutil.CollectionName(t)
+		pool.DropDatabase(ctx, databaseName)
+
+		size, err := DatabaseSize(ctx, pool, databaseName)
+		require.NoError(t, err)
+		assert.Zero(t, size)
+
+		size, err = CollectionSize(ctx, pool, databaseName, collectionName)
+		require.NoError(t, err)
+		assert.Zero(t, size)
+
+		require.NoError(t, CreateDatabase(ctx, pool, databaseName))
+		t.Cleanup(func() {
+			pool.DropDatabase(ctx, databaseName)
+		})
+
+		size, err = DatabaseSize(ctx, pool, databaseName)
+		require.NoError(t, err)
+		assert.Zero(t, size, "settings table should not be counted")
+
+		size, err = CollectionDataSize(ctx, pool, databaseName, collectionName)
+		require.NoError(t, err)
+		assert.Zero(t, size)
+	})
+
+	t.Run("Exist", func(t *testing.T) {
+		t.Parallel()
+
+		databaseName := testutil.DatabaseName(t)
+		collectionName := testutil.CollectionName(t)
+
+		t.Cleanup(func() {
+			pool.DropDatabase(ctx, databaseName)
+		})
+
+		pool.DropDatabase(ctx, databaseName)
+
+		docs := make([]*types.Document, 100)
+		for i := range docs {
+			docs[i] = must.NotFail(types.NewDocument("_id", int32(i), "v", "foo"))
+		}
+		require.NoError(t, InsertDocuments(ctx, pool, databaseName, collectionName, docs))
+
+		total, err := CollectionSize(ctx, pool, databaseName, collectionName)
+		require.NoError(t, err)
+		assert.Positive(t, total)
+
+		data, err := CollectionDataSize(ctx, pool, databaseName, collectionName)
+		require.NoError(t, err)
+		assert.Positive(t, data)
+		assert.Less(t, data, total, "GIN index should be counted in total size only")
+
+		db, err := DatabaseSize(ctx, pool, databaseName)
+		require.NoError(t, err)
+		assert.Equal(t, total, db)
+	})
+}
@@ -0,0 +1,202 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"strings"
+	"time"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// maxSortKeys is the maximum number of sort keys.
+// It matches the limit of the in-memory sorter.
+const maxSortKeys = 32
+
+// maxExactInt64 is the maximum absolute int64 value that is sorted exactly by PostgreSQL,
+// see SortPushdownExact.
+const maxExactInt64 = 1 << 53
+
+// idSortValue is a jsonb expression for the top-level _id field.
+const idSortValue = `_jsonb->'_id'`
+
+// idSortExprs are ORDER BY expressions for _id values without sort order.
+//
+// They approximate types.CompareOrder for fjson-encoded values:
+// the first one is the BSON type rank (see types.CompareOrder), then there are
+// infinity sign for doubles and decimals, numeric value, byte-wise text value, and boolean value.
+// Only one of the last three is not null for a given value.
+//
+// Values of some types are ordered incorrectly even within their rank
+// (for example, documents, arrays, binaries, and regexes are not ordered at all).
+// See SortPushdownExact for details.
+var idSortExprs = []string{
+	`CASE jsonb_typeof(` + idSortValue + `) ` +
+		`WHEN 'null' THEN 1 ` +
+		`WHEN 'number' THEN 3 ` +
+		`WHEN 'string' THEN 4 ` +
+		`WHEN 'array' THEN 6 ` +
+		`WHEN 'boolean' THEN 9 ` +
+		`ELSE CASE ` +
+		`WHEN ` + idSortValue + ` ? '$k' THEN 5 ` +
+		`WHEN ` + idSortValue + ` ? '$f' THEN CASE WHEN ` + idSortValue + `->>'$f' = 'NaN' THEN 2 ELSE 3 END ` +
+		`WHEN ` + idSortValue + ` ? '$l' THEN 3 ` +
+		`WHEN ` + idSortValue + ` ? '$n' THEN CASE WHEN ` + idSortValue + `->>'$n' = 'NaN' THEN 2 ELSE 3 END ` +
+		`WHEN ` + idSortValue + ` ? '$b' THEN 7 ` +
+		`WHEN ` + idSortValue + ` ? '$o' THEN 8 ` +
+		`WHEN ` + idSortValue + ` ? '$d' THEN 10 ` +
+		`WHEN ` + idSortValue + ` ? '$t' THEN 11 ` +
+		`ELSE 12 END END`,
+
+	`CASE WHEN jsonb_typeof(` + idSortValue + `) <> 'object' OR ` + idSortValue + ` ? '$k' THEN 0 ` +
+		`WHEN ` + idSortValue + `->>'$f' = '-Infinity' OR ` + idSortValue + `->>'$n' = '-Infinity' THEN -1 ` +
+		`WHEN ` + idSortValue + `->>'$f' = 'Infinity' OR ` + idSortValue + `->>'$n' = 'Infinity' THEN 1 ` +
+		`ELSE 0 END`,
+
+	`CASE jsonb_typeof(` + idSortValue + `) ` +
+		`WHEN 'number' THEN (` + idSortValue + `)::numeric ` +
+		`WHEN 'object' THEN CASE ` +
+		`WHEN ` + idSortValue + ` ? '$k' THEN NULL ` +
+		`WHEN jsonb_typeof(` + idSortValue + `->'$f') = 'number' THEN (` + idSortValue + `->'$f')::numeric ` +
+		`WHEN ` + idSortValue + `->>'$f' = '-0' THEN 0 ` +
+		`WHEN ` + idSortValue + ` ? '$l' THEN (` + idSortValue + `->>'$l')::numeric ` +
+		`WHEN ` + idSortValue + `->>'$n' ~ '^[-+]?[0-9]+(\.[0-9]*)?([eE][-+]?[0-9]+)?$' ` +
+		`THEN (` + idSortValue + `->>'$n')::numeric ` +
+		`WHEN ` + idSortValue + ` ? '$d' THEN (` + idSortValue + `->'$d')::numeric ` +
+		`WHEN ` + idSortValue + ` ? '$t' THEN (` + idSortValue + `->>'$t')::numeric ` +
+		`END END`,
+
+	`(CASE jsonb_typeof(` + idSortValue + `) ` +
+		`WHEN 'string' THEN ` + idSortValue + `#>>'{}' ` +
+		`WHEN 'object' THEN CASE WHEN ` + idSortValue + ` ? '$k' THEN NULL ELSE ` + idSortValue + `->>'$o' END ` +
+		`END) COLLATE "C"`,
+
+	`CASE WHEN jsonb_typeof(` + idSortValue + `) = 'boolean' THEN (` + idSortValue + `)::boolean END`,
+}
+
+// prepareOrderByClause returns ORDER BY clause for the given sort specification,
+// or an empty string if sorting can't be done by PostgreSQL.
+//
+// Sorting is pushed down only if the first sort key is _id with a valid sort order,
+// and all other keys are valid. Since _id values are unique, other keys don't affect the order.
+// Other fields are not pushed down because their array values are sorted by their elements.
+//
+// The ORDER BY clause is not exact for all types; see SortPushdownExact.
+func prepareOrderByClause(sort *types.Document) string {
+	keys, ok := parseSort(sort)
+	if !ok || keys[0].key != "_id" {
+		return ""
+	}
+
+	return ` ORDER BY ` + orderByExprs(keys[0].order)
+}
+
+// orderByExprs returns comma-separated ORDER BY expressions for _id values with the given sort order.
+func orderByExprs(order types.SortType) string {
+	var suffix string
+	switch order {
+	case types.Ascending:
+		suffix = ` ASC NULLS FIRST`
+	case types.Descending:
+		suffix = ` DESC NULLS LAST`
+	default:
+		panic("unexpected sort order")
+	}
+
+	exprs := make([]string, len(idSortExprs))
+	for i, expr := range idSortExprs {
+		exprs[i] = expr + suffix
+	}
+
+	return strings.Join(exprs, ", ")
+}
+
+// sortKey represents a single top-level sort key.
+type sortKey struct {
+	key   string
+	order types.SortType
+}
+
+// parseSort returns sort keys if all of them are top-level fields with valid sort orders.
+func parseSort(sort *types.Document) ([]sortKey, bool) {
+	if sort.Len() == 0 || sort.Len() > maxSortKeys {
+		return nil, false
+	}
+
+	res := make([]sortKey, sort.Len())
+
+	for i, key := range sort.Keys() {
+		if key == "" || strings.HasPrefix(key, "$") || strings.Contains(key, ".") {
+			return nil, false
+		}
+
+		var order float64
+		switch v := must.NotFail(sort.Get(key)).(type) {
+		case float64:
+			order = v
+		case int32:
+			order = float64(v)
+		case int64:
+			order = float64(v)
+		default:
+			return nil, false
+		}
+
+		switch order {
+		case 1:
+			res[i] = sortKey{key: key, order: types.Ascending}
+		case -1:
+			res[i] = sortKey{key: key, order: types.Descending}
+		default:
+			return nil, false
+		}
+	}
+
+	return res, true
+}
+
+// SortPushdownExact returns true if documents returned with QueryResults.SortPushdown
+// are guaranteed to be in the same positions as they would be after sorting in memory.
+//
+// PostgreSQL sorts _id values of some types in a different way than types.CompareOrder does:
+// for example, documents, arrays, binaries, regexes, doubles, decimals, and large int64 values.
+// Such values could only be misplaced relative to each other, so all other values
+// keep the same positions.
+// Because of that, it is enough to check the values of the returned documents
+// (after skipping, if skip was not pushed down); if that function returns false,
+// the query should be repeated with SQLParam.DisableSortPushdown.
+func SortPushdownExact(docs []*types.Document) bool {
+	for _, doc := range docs {
+		v, _ := doc.Get("_id")
+		if !sortValueExact(v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sortValueExact returns true if the given value is always sorted exactly by idSortExprs.
+func sortValueExact(v any) bool {
+	switch v := v.(type) {
+	case types.NullType, int32, string, types.ObjectID, bool, time.Time, types.Timestamp:
+		return true
+	case int64:
+		return -maxExactInt64 <= v && v <= maxExactInt64
+	default:
+		return false
+	}
+}
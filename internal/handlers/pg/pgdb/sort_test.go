@@ -0,0 +1,232 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+func TestParseSort(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		sort     *types.Document
+		expected []sortKey
+	}{
+		"Nil": {
+			sort: nil,
+		},
+		"Empty": {
+			sort: must.NotFail(types.NewDocument()),
+		},
+		"Asc": {
+			sort:     must.NotFail(types.NewDocument("a", int32(1))),
+			expected: []sortKey{{key: "a", order: types.Ascending}},
+		},
+		"Multiple": {
+			sort: must.NotFail(types.NewDocument("a", float64(-1), "b", int64(1))),
+			expected: []sortKey{
+				{key: "a", order: types.Descending},
+				{key: "b", order: types.Ascending},
+			},
+		},
+		"DottedPath": {
+			sort: must.NotFail(types.NewDocument("a.b", int32(1))),
+		},
+		"Operator": {
+			sort: must.NotFail(types.NewDocument("$natural", int32(1))),
+		},
+		"BadOrder": {
+			sort: must.NotFail(types.NewDocument("a", int32(2))),
+		},
+		"NotWholeNumber": {
+			sort: must.NotFail(types.NewDocument("a", 1.5)),
+		},
+		"String": {
+			sort: must.NotFail(types.NewDocument("a", "1")),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, ok := parseSort(tc.sort)
+			assert.Equal(t, tc.expected != nil, ok)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestPrepareOrderByClause(t *testing.T) {
+	t.Parallel()
+
+	sort := must.NotFail(types.NewDocument("_id", int32(1)))
+	assert.Equal(t, ` ORDER BY `+orderByExprs(types.Ascending), prepareOrderByClause(sort))
+
+	sort = must.NotFail(types.NewDocument("_id", int64(-1), "a", 1.0))
+	assert.Equal(t, ` ORDER BY `+orderByExprs(types.Descending), prepareOrderByClause(sort))
+
+	for name, sort := range map[string]*types.Document{
+		"Field":      must.NotFail(types.NewDocument("a", int32(1))),
+		"IDSubfield": must.NotFail(types.NewDocument("_id.a", int32(1))),
+		"BadOrder":   must.NotFail(types.NewDocument("_id", int32(1), "a", int32(2))),
+	} {
+		assert.Empty(t, prepareOrderByClause(sort), name)
+	}
+}
+
+func TestSortPushdownExact(t *testing.T) {
+	t.Parallel()
+
+	for _, v := range []any{
+		types.Null, int32(42), int64(-maxExactInt64), "foo", types.ObjectID{1}, true, time.Now(), types.Timestamp(42),
+	} {
+		doc := must.NotFail(types.NewDocument("_id", v))
+		assert.True(t, SortPushdownExact([]*types.Document{doc}), "%[1]T %[1]v", v)
+	}
+
+	for _, v := range []any{
+		int64(maxExactInt64 + 1), 42.0, must.NotFail(types.ParseDecimal128("42")),
+		must.NotFail(types.NewDocument()), types.Binary{B: []byte{42}}, types.Regex{Pattern: "foo"},
+	} {
+		docs := []*types.Document{
+			must.NotFail(types.NewDocument("_id", int32(42))),
+			must.NotFail(types.NewDocument("_id", v)),
+		}
+		assert.False(t, SortPushdownExact(docs), "%[1]T %[1]v", v)
+	}
+
+	assert.True(t, SortPushdownExact(nil))
+}
+
+// TestSortPushdown compares pushed down and in-memory sort results for _id values of mixed types.
+func TestSortPushdown(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(t, CreateDatabase(ctx, pool, dbName))
+
+	values := []any{
+		types.Null,
+		math.NaN(),
+		must.NotFail(types.ParseDecimal128("NaN")),
+		math.Inf(-1),
+		must.NotFail(types.ParseDecimal128("-Infinity")),
+		int64(math.MinInt64),
+		int64(-1 << 60),
+		-1.5,
+		int32(-1),
+		math.Copysign(0, -1),
+		int32(0),
+		must.NotFail(types.ParseDecimal128("0.10000000000000000001")),
+		0.1,
+		int64(1),
+		must.NotFail(types.ParseDecimal128("1.5E+3")),
+		int32(1501),
+		9007199254740992.0,
+		int64(9007199254740993),
+		int64(1<<60 + 1),
+		float64(1 << 60),
+		math.Inf(1),
+		must.NotFail(types.ParseDecimal128("Infinity")),
+		"",
+		"B",
+		"a",
+		"ab",
+		"я",
+		must.NotFail(types.NewDocument()),
+		must.NotFail(types.NewDocument("a", int32(1))),
+		types.Binary{B: []byte{1}},
+		types.Binary{Subtype: types.BinaryUser, B: []byte{}},
+		types.ObjectID{0x01},
+		types.ObjectID{0x0a},
+		types.ObjectID{0xff},
+		false,
+		true,
+		time.Date(1969, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		types.Timestamp(1),
+		types.Timestamp(math.MaxInt64),
+		types.Regex{Pattern: "b"},
+		types.Regex{Pattern: "a", Options: "i"},
+	}
+
+	// insert in a different order
+	for i := range values {
+		v := values[(i*7)%len(values)]
+		doc := must.NotFail(types.NewDocument("_id", v))
+		require.NoError(t, InsertDocument(ctx, pool, dbName, collectionName, doc), "%[1]T %[1]v", v)
+	}
+
+	for _, order := range []int32{1, -1} {
+		order := order
+		t.Run(fmt.Sprint(order), func(t *testing.T) {
+			t.Parallel()
+
+			sort := must.NotFail(types.NewDocument("_id", order))
+			sp := SQLParam{DB: dbName, Collection: collectionName, Sort: sort, Limit: int64(len(values))}
+
+			fetchedChan, qr, err := pool.QueryDocuments(ctx, pool, sp)
+			require.NoError(t, err)
+
+			var actual []*types.Document
+			for fetched := range fetchedChan {
+				require.NoError(t, fetched.Err)
+				actual = append(actual, fetched.Docs...)
+			}
+
+			require.True(t, qr.SortPushdown)
+			require.True(t, qr.LimitPushdown)
+			require.Len(t, actual, len(values))
+
+			expected := make([]*types.Document, len(actual))
+			copy(expected, actual)
+			require.NoError(t, common.SortDocuments(expected, sort))
+
+			// documents with exact values should be in the same positions
+			for i := range actual {
+				if !SortPushdownExact(actual[i : i+1]) {
+					continue
+				}
+
+				expectedID := must.NotFail(expected[i].Get("_id"))
+				actualID := must.NotFail(actual[i].Get("_id"))
+				assert.Equal(t, types.Equal, types.CompareOrder(expectedID, actualID), "%d: %v != %v", i, expectedID, actualID)
+			}
+		})
+	}
+}
@@ -19,11 +19,12 @@ import (
 	"strings"
 
 	"github.com/jackc/pgtype/pgxtype"
+	"golang.org/x/exp/slices"
 
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 )
 
-// Tables returns a sorted list of PostgreSQL table names.
+// Tables returns a sorted list of PostgreSQL plain table names.
 // Returns empty slice if schema does not exist.
 // Tables with prefix "_ferretdb_" are filtered out.
 func Tables(ctx context.Context, querier pgxtype.Querier, schema string) ([]string, error) {
@@ -44,31 +45,63 @@ func Tables(ctx context.Context, querier pgxtype.Querier, schema string) ([]stri
 	return filtered, nil
 }
 
-// tables returns a list of PostgreSQL table names.
+// tables returns a sorted list of PostgreSQL plain table names.
+//
+// Views, foreign tables and other relations are not included.
 func tables(ctx context.Context, querier pgxtype.Querier, schema string) ([]string, error) {
-	sql := `SELECT table_name ` +
-		`FROM information_schema.columns ` +
-		`WHERE table_schema = $1 ` +
-		`GROUP BY table_name ` +
-		`ORDER BY table_name`
+	relations, err := schemaRelations(ctx, querier, schema)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	tables := make([]string, 0, len(relations))
+	for name, rel := range relations {
+		if rel.table {
+			tables = append(tables, name)
+		}
+	}
+
+	slices.Sort(tables)
+
+	return tables, nil
+}
+
+// relation describes a single PostgreSQL relation.
+type relation struct {
+	table      bool // true for plain tables
+	collection bool // true for plain tables with jsonb _jsonb column that could store FerretDB collection
+}
+
+// schemaRelations returns all PostgreSQL relations of the given schema by their names.
+//
+// All kinds of relations are included (tables, views, foreign tables, indexes, sequences, etc.)
+// because they all share the same namespace and could conflict with table names.
+func schemaRelations(ctx context.Context, querier pgxtype.Querier, schema string) (map[string]relation, error) {
+	sql := `SELECT c.relname, c.relkind = 'r', c.relkind = 'r' AND EXISTS (` +
+		`SELECT 1 FROM pg_catalog.pg_attribute a ` +
+		`WHERE a.attrelid = c.oid AND a.attname = '_jsonb' AND a.atttypid = 'jsonb'::regtype AND NOT a.attisdropped` +
+		`) ` +
+		`FROM pg_catalog.pg_class c JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace ` +
+		`WHERE n.nspname = $1`
 	rows, err := querier.Query(ctx, sql, schema)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 	defer rows.Close()
 
-	tables := make([]string, 0, 2)
-	var name string
+	res := make(map[string]relation, 2)
 	for rows.Next() {
-		if err = rows.Scan(&name); err != nil {
+		var name string
+		var rel relation
+		if err = rows.Scan(&name, &rel.table, &rel.collection); err != nil {
 			return nil, lazyerrors.Error(err)
 		}
 
-		tables = append(tables, name)
+		res[name] = rel
 	}
 	if err = rows.Err(); err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
-	return tables, nil
+	return res, nil
 }
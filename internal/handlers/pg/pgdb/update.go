@@ -16,12 +16,17 @@ package pgdb
 
 import (
 	"context"
+	"errors"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v4"
+	"golang.org/x/exp/slices"
 
 	"github.com/FerretDB/FerretDB/internal/fjson"
 	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 )
 
@@ -43,10 +48,288 @@ func SetDocumentByID(ctx context.Context, tx pgx.Tx, sp *SQLParam, id any, doc *
 
 	sql += pgx.Identifier{sp.DB, table}.Sanitize() + " SET _jsonb = $1 WHERE _jsonb->'_id' = $2"
 
-	tag, err := tx.Exec(ctx, sql, must.NotFail(fjson.Marshal(doc)), must.NotFail(fjson.Marshal(id)))
+	tag, err := tx.Exec(ctx, sql, string(must.NotFail(fjson.Marshal(doc))), string(must.NotFail(fjson.Marshal(id))))
 	if err != nil {
 		return 0, err
 	}
 
 	return tag.RowsAffected(), nil
 }
+
+// UpdateDocumentsByFilter updates documents matching sp.Filter with a single UPDATE statement.
+//
+// Only updates containing $set and $unset operators for top-level fields are handled,
+// and only values that are compared exactly as in memory, see prepareUpdateExpr.
+// If multi is false, only the first matching document is updated.
+// It returns the number of matching and modified documents.
+// Other SQLParam fields except DB, Collection and Comment are ignored.
+//
+// If the filter or the update can't be pushed down, it returns false, and the caller should update documents
+// by fetching, filtering and modifying them in memory.
+// If the collection doesn't exist, it returns 0, 0, true, and no error.
+func UpdateDocumentsByFilter(ctx context.Context, tx pgx.Tx, sp *SQLParam, update *types.Document, multi bool) (int64, int64, bool, error) { //nolint:lll // argument list is too long
+	var p Placeholder
+	where, args, ok := prepareWhereClause(sp.Filter, &p)
+	if !ok {
+		return 0, 0, false, nil
+	}
+
+	expr, changed, updateArgs, ok := prepareUpdateExpr(update, `_jsonb`, &p)
+	if !ok {
+		return 0, 0, false, nil
+	}
+	args = append(args, updateArgs...)
+
+	exists, err := CollectionExists(ctx, tx, sp.DB, sp.Collection)
+	if err != nil {
+		return 0, 0, false, lazyerrors.Error(err)
+	}
+	if !exists {
+		return 0, 0, true, nil
+	}
+
+	table, err := getTableName(ctx, tx, sp.DB, sp.Collection)
+	if err != nil {
+		return 0, 0, false, lazyerrors.Error(err)
+	}
+
+	tableName := pgx.Identifier{sp.DB, table}.Sanitize()
+
+	matched := `SELECT ctid FROM ` + tableName + where
+	if !multi {
+		matched += ` LIMIT 1`
+	}
+
+	// both counts are computed in the same snapshot
+	sql := `WITH matched AS (` + matched + `), updated AS (UPDATE `
+
+	if c := sp.Comment; c != "" {
+		c = strings.ReplaceAll(c, "/*", "/ *")
+		c = strings.ReplaceAll(c, "*/", "* /")
+
+		sql += `/* ` + c + ` */ `
+	}
+
+	sql += tableName + ` SET _jsonb = ` + expr +
+		` WHERE ctid IN (SELECT ctid FROM matched) AND (` + changed + `) RETURNING 1) ` +
+		`SELECT (SELECT count(*) FROM matched), (SELECT count(*) FROM updated)`
+
+	var n, nModified int64
+	if err = tx.QueryRow(ctx, sql, args...).Scan(&n, &nModified); err != nil {
+		return 0, 0, false, lazyerrors.Error(err)
+	}
+
+	return n, nModified, true, nil
+}
+
+// UpsertDocumentByID updates or inserts a single document with a single INSERT ... ON CONFLICT statement.
+//
+// Only sp.Filter containing just _id equality to a value supported by prepareWhereClause is handled,
+// and only updates handled by UpdateDocumentsByFilter.
+// The new document contains _id and fields set by the update, like in memory.
+// It returns true if the document was inserted, and the number of modified documents (0 or 1) otherwise;
+// in both cases exactly one document is matched.
+// Other SQLParam fields except DB, Collection and Comment are ignored.
+//
+// If the filter or the update can't be pushed down, the collection doesn't exist,
+// or it doesn't have a unique index on _id field (tables created by older versions),
+// it returns false, and the caller should use the existing update/insert path.
+func UpsertDocumentByID(ctx context.Context, tx pgx.Tx, sp *SQLParam, update *types.Document) (bool, int64, bool, error) { //nolint:lll // argument list is too long
+	if sp.Filter == nil || sp.Filter.Len() != 1 || !sp.Filter.Has("_id") {
+		return false, 0, false, nil
+	}
+
+	id := must.NotFail(sp.Filter.Get("_id"))
+	switch id.(type) {
+	case string, bool, types.ObjectID, time.Time:
+	default:
+		return false, 0, false, nil
+	}
+
+	var p Placeholder
+	docP := p.Next()
+
+	expr, changed, args, ok := prepareUpdateExpr(update, `t._jsonb`, &p)
+	if !ok {
+		return false, 0, false, nil
+	}
+
+	exists, err := CollectionExists(ctx, tx, sp.DB, sp.Collection)
+	if err != nil {
+		return false, 0, false, lazyerrors.Error(err)
+	}
+	if !exists {
+		return false, 0, false, nil
+	}
+
+	table, err := getTableName(ctx, tx, sp.DB, sp.Collection)
+	if err != nil {
+		return false, 0, false, lazyerrors.Error(err)
+	}
+
+	ok, err = hasIDIndex(ctx, tx, sp.DB, table)
+	if err != nil || !ok {
+		return false, 0, false, err
+	}
+
+	// the same document as produced in memory: _id, then set fields in sorted order
+	doc := must.NotFail(types.NewDocument("_id", id))
+
+	if set, _ := update.Get("$set"); set != nil {
+		setDoc := set.(*types.Document)
+		keys := slices.Clone(setDoc.Keys())
+		slices.Sort(keys)
+
+		for _, key := range keys {
+			must.NoError(doc.Set(key, must.NotFail(setDoc.Get(key))))
+		}
+	}
+
+	sql := `INSERT `
+
+	if c := sp.Comment; c != "" {
+		c = strings.ReplaceAll(c, "/*", "/ *")
+		c = strings.ReplaceAll(c, "*/", "* /")
+
+		sql += `/* ` + c + ` */ `
+	}
+
+	// the existing row should be referenced by the table alias, unqualified _jsonb is ambiguous there;
+	// xmax is zero only for inserted rows, and no row is returned if the existing one is not changed
+	sql += `INTO ` + pgx.Identifier{sp.DB, table}.Sanitize() + ` AS t (_jsonb) VALUES (` + docP + `::jsonb) ` +
+		`ON CONFLICT ((_jsonb->'_id')) DO UPDATE SET _jsonb = ` + expr + ` WHERE ` + changed + ` ` +
+		`RETURNING t.xmax = 0`
+
+	args = append([]any{string(must.NotFail(fjson.Marshal(doc)))}, args...)
+
+	var inserted bool
+	err = tx.QueryRow(ctx, sql, args...).Scan(&inserted)
+	switch {
+	case err == nil:
+		// nothing
+	case errors.Is(err, pgx.ErrNoRows):
+		return false, 0, true, nil
+	default:
+		return false, 0, false, lazyerrors.Error(err)
+	}
+
+	if inserted {
+		return true, 0, true, nil
+	}
+
+	return false, 1, true, nil
+}
+
+// prepareUpdateExpr returns SQL expression for the new _jsonb value, condition for the document being changed,
+// and their arguments for the given update document and column reference (such as _jsonb),
+// or false if the update can't be handled by PostgreSQL exactly as it is handled in memory.
+//
+// Only $set and $unset operators for top-level fields other than _id are handled.
+// Set values are limited to types for which jsonb equality matches BSON comparison
+// (see prepareWhereClause), so documents are considered unchanged in the same cases.
+// New fields are appended in sorted order, existing fields keep their positions.
+func prepareUpdateExpr(update *types.Document, col string, p *Placeholder) (string, string, []any, bool) {
+	if update.Len() == 0 {
+		return "", "", nil, false
+	}
+
+	var set, unset *types.Document
+	for _, op := range update.Keys() {
+		var ok bool
+		switch op {
+		case "$set":
+			set, ok = must.NotFail(update.Get(op)).(*types.Document)
+		case "$unset":
+			unset, ok = must.NotFail(update.Get(op)).(*types.Document)
+		}
+
+		if !ok {
+			return "", "", nil, false
+		}
+	}
+
+	validKey := func(key string) bool {
+		return key != "" && key != "_id" && !strings.HasPrefix(key, "$") && !strings.Contains(key, ".")
+	}
+
+	setKeys := make([]string, 0, set.Len())
+	for _, key := range set.Keys() {
+		if !validKey(key) {
+			return "", "", nil, false
+		}
+
+		switch must.NotFail(set.Get(key)).(type) {
+		case string, bool, types.ObjectID, time.Time:
+		default:
+			return "", "", nil, false
+		}
+
+		setKeys = append(setKeys, key)
+	}
+
+	unsetKeys := make([]string, 0, unset.Len())
+	for _, key := range unset.Keys() {
+		if !validKey(key) || set.Has(key) {
+			return "", "", nil, false
+		}
+
+		unsetKeys = append(unsetKeys, key)
+	}
+
+	if len(setKeys) == 0 && len(unsetKeys) == 0 {
+		return "", "", nil, false
+	}
+
+	// new fields are added in the same order as in memory
+	sort.Strings(setKeys)
+
+	var args []any
+	var pairs, conds []string
+
+	for _, key := range setKeys {
+		keyP, valueP := p.Next(), p.Next()
+		args = append(args, key, string(must.NotFail(fjson.Marshal(must.NotFail(set.Get(key))))))
+
+		pairs = append(pairs, keyP+`::text, `+valueP+`::jsonb`)
+		conds = append(conds, col+`->`+keyP+`::text IS DISTINCT FROM `+valueP+`::jsonb`)
+	}
+
+	for _, key := range unsetKeys {
+		keyP := p.Next()
+		args = append(args, key)
+
+		conds = append(conds, col+` ? `+keyP+`::text`)
+	}
+
+	setKeysP, unsetKeysP := p.Next(), p.Next()
+	args = append(args,
+		string(must.NotFail(fjson.Marshal(stringsArray(setKeys)))),
+		string(must.NotFail(fjson.Marshal(stringsArray(unsetKeys)))),
+	)
+	unsetArray := `ARRAY(SELECT jsonb_array_elements_text(` + unsetKeysP + `::jsonb))`
+
+	obj := col
+	if len(pairs) > 0 {
+		obj = `(` + obj + ` || jsonb_build_object(` + strings.Join(pairs, ", ") + `))`
+	}
+	obj = `(` + obj + ` - ` + unsetArray + `)`
+
+	keys := `((` + col + `->'$k') || COALESCE(` +
+		`(SELECT jsonb_agg(k ORDER BY o) FROM jsonb_array_elements_text(` + setKeysP + `::jsonb) ` +
+		`WITH ORDINALITY AS s(k, o) WHERE NOT ` + col + ` ? k), '[]'::jsonb)` +
+		` - ` + unsetArray + `)`
+
+	expr := `jsonb_set(` + obj + `, '{$k}', ` + keys + `)`
+
+	return expr, strings.Join(conds, ` OR `), args, true
+}
+
+// stringsArray returns an array of the given strings.
+func stringsArray(strs []string) *types.Array {
+	arr := types.MakeArray(len(strs))
+	for _, s := range strs {
+		must.NoError(arr.Append(s))
+	}
+
+	return arr
+}
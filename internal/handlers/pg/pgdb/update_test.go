@@ -0,0 +1,432 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgdb
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/fjson"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+// TestUpdateDocumentsByFilter compares updates made by PostgreSQL with the same updates made in memory.
+func TestUpdateDocumentsByFilter(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(t, CreateDatabase(ctx, pool, dbName))
+
+	oid := types.ObjectID{0x62, 0x56, 0xc5, 0xba, 0x0b, 0xad, 0xc0, 0xff, 0xee, 0xff, 0xff, 0xff}
+	date := time.Date(2022, time.March, 4, 5, 6, 7, 0, time.UTC)
+
+	// values stored in documents, nil means missing field
+	storedValues := []any{
+		nil, "x", "y", "", true, false, int32(1), 1.0, int64(1), oid, date, types.Null,
+		must.NotFail(types.NewArray("x", true)),
+		must.NotFail(types.NewDocument("x", "x")),
+	}
+
+	// values that could be pushed down
+	setValues := []any{"x", "y", "", true, false, oid, date}
+
+	fields := []string{"a", "b", "c", "d"}
+
+	r := rand.New(rand.NewSource(1))
+
+	randomDocuments := func() []*types.Document {
+		docs := make([]*types.Document, 50)
+		for i := range docs {
+			doc := must.NotFail(types.NewDocument("_id", int32(i)))
+
+			for _, f := range r.Perm(len(fields)) {
+				if v := storedValues[r.Intn(len(storedValues))]; v != nil {
+					must.NoError(doc.Set(fields[f], v))
+				}
+			}
+
+			docs[i] = doc
+		}
+
+		return docs
+	}
+
+	randomFilter := func() *types.Document {
+		switch r.Intn(4) {
+		case 0:
+			return must.NotFail(types.NewDocument())
+		case 1:
+			return must.NotFail(types.NewDocument("_id", "no_such_id"))
+		default:
+			return must.NotFail(types.NewDocument(fields[r.Intn(len(fields))], setValues[r.Intn(len(setValues))]))
+		}
+	}
+
+	randomUpdate := func() *types.Document {
+		set := must.NotFail(types.NewDocument())
+		unset := must.NotFail(types.NewDocument())
+
+		for _, f := range fields {
+			switch r.Intn(3) {
+			case 0:
+				must.NoError(set.Set(f, setValues[r.Intn(len(setValues))]))
+			case 1:
+				must.NoError(unset.Set(f, ""))
+			}
+		}
+
+		update := must.NotFail(types.NewDocument())
+		if set.Len() > 0 {
+			must.NoError(update.Set("$set", set))
+		}
+		if unset.Len() > 0 || update.Len() == 0 {
+			must.NoError(update.Set("$unset", unset))
+		}
+
+		return update
+	}
+
+	errRollback := fmt.Errorf("rollback")
+
+	for i := 0; i < 100; i++ {
+		docs := randomDocuments()
+		filter := randomFilter()
+		update := randomUpdate()
+
+		// in-memory implementation, as in MsgUpdate
+		var expectedN, expectedModified int64
+		expected := make(map[int32]*types.Document, len(docs))
+		for _, doc := range docs {
+			doc = doc.DeepCopy()
+			expected[must.NotFail(doc.Get("_id")).(int32)] = doc
+
+			matches, err := common.FilterDocument(doc, filter)
+			require.NoError(t, err)
+			if !matches {
+				continue
+			}
+			expectedN++
+
+			changed, err := common.UpdateDocument(doc, update.DeepCopy())
+			require.NoError(t, err)
+			if changed {
+				expectedModified++
+			}
+		}
+
+		err := pool.InTransaction(ctx, func(tx pgx.Tx) error {
+			if err := InsertDocuments(ctx, tx, dbName, collectionName, docs); err != nil {
+				return err
+			}
+
+			msg := fmt.Sprintf("filter %v, update %v", filter, update)
+
+			sp := &SQLParam{DB: dbName, Collection: collectionName, Filter: filter}
+			n, nModified, pushdown, err := UpdateDocumentsByFilter(ctx, tx, sp, update, true)
+			require.NoError(t, err)
+			require.True(t, pushdown, msg)
+
+			assert.Equal(t, expectedN, n, msg)
+			assert.Equal(t, expectedModified, nModified, msg)
+
+			iter, _, err := QueryIterator(ctx, tx, SQLParam{DB: dbName, Collection: collectionName})
+			require.NoError(t, err)
+			defer iter.Close()
+
+			var count int
+			for {
+				doc, err := iter.Next(ctx)
+				if errors.Is(err, ErrIteratorDone) {
+					break
+				}
+				require.NoError(t, err)
+				count++
+
+				expectedDoc := expected[must.NotFail(doc.Get("_id")).(int32)]
+				require.NotNil(t, expectedDoc)
+
+				expectedB := string(must.NotFail(fjson.Marshal(expectedDoc)))
+				actualB := string(must.NotFail(fjson.Marshal(doc)))
+				assert.Equal(t, expectedB, actualB, msg)
+			}
+
+			assert.Equal(t, len(docs), count)
+
+			// keep the collection empty for the next iteration
+			return errRollback
+		})
+		require.ErrorIs(t, err, errRollback)
+	}
+}
+
+func TestUpdateDocumentsByFilterFallback(t *testing.T) {
+	t.Parallel()
+
+	for name, update := range map[string]*types.Document{
+		"Empty":       must.NotFail(types.NewDocument()),
+		"Replacement": must.NotFail(types.NewDocument("v", "foo")),
+		"Inc":         must.NotFail(types.NewDocument("$inc", must.NotFail(types.NewDocument("v", int32(1))))),
+		"SetNumber":   must.NotFail(types.NewDocument("$set", must.NotFail(types.NewDocument("v", int32(1))))),
+		"SetNull":     must.NotFail(types.NewDocument("$set", must.NotFail(types.NewDocument("v", types.Null)))),
+		"SetID":       must.NotFail(types.NewDocument("$set", must.NotFail(types.NewDocument("_id", "foo")))),
+		"SetDotted":   must.NotFail(types.NewDocument("$set", must.NotFail(types.NewDocument("v.foo", "foo")))),
+		"SetEmpty":    must.NotFail(types.NewDocument("$set", must.NotFail(types.NewDocument()))),
+		"UnsetID":     must.NotFail(types.NewDocument("$unset", must.NotFail(types.NewDocument("_id", "")))),
+		"SetAndInc": must.NotFail(types.NewDocument(
+			"$set", must.NotFail(types.NewDocument("v", "foo")),
+			"$inc", must.NotFail(types.NewDocument("w", int32(1))),
+		)),
+		"SetAndUnsetSameField": must.NotFail(types.NewDocument(
+			"$set", must.NotFail(types.NewDocument("v", "foo")),
+			"$unset", must.NotFail(types.NewDocument("v", "")),
+		)),
+	} {
+		name, update := name, update
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			var p Placeholder
+			_, _, _, ok := prepareUpdateExpr(update, `_jsonb`, &p)
+			assert.False(t, ok)
+		})
+	}
+}
+
+// TestUpsertDocumentByID compares upserts made by PostgreSQL with the same upserts made in memory.
+func TestUpsertDocumentByID(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(t, CreateDatabase(ctx, pool, dbName))
+
+	oid := types.ObjectID{0x62, 0x56, 0xc5, 0xba, 0x0b, 0xad, 0xc0, 0xff, 0xee, 0xff, 0xff, 0xff}
+	date := time.Date(2022, time.March, 4, 5, 6, 7, 0, time.UTC)
+
+	storedValues := []any{nil, "x", "", true, int32(1), oid, date, types.Null}
+	setValues := []any{"x", "", true, oid, date}
+	fields := []string{"a", "b", "c"}
+
+	errRollback := fmt.Errorf("rollback")
+
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 100; i++ {
+		docs := make([]*types.Document, 5)
+		for j := range docs {
+			doc := must.NotFail(types.NewDocument("_id", fmt.Sprint(j)))
+
+			for _, f := range r.Perm(len(fields)) {
+				if v := storedValues[r.Intn(len(storedValues))]; v != nil {
+					must.NoError(doc.Set(fields[f], v))
+				}
+			}
+
+			docs[j] = doc
+		}
+
+		// existing and missing _id values
+		id := fmt.Sprint(r.Intn(len(docs) * 2))
+		filter := must.NotFail(types.NewDocument("_id", id))
+
+		set := must.NotFail(types.NewDocument())
+		unset := must.NotFail(types.NewDocument())
+
+		for _, f := range r.Perm(len(fields)) {
+			switch r.Intn(3) {
+			case 0:
+				must.NoError(set.Set(fields[f], setValues[r.Intn(len(setValues))]))
+			case 1:
+				must.NoError(unset.Set(fields[f], ""))
+			}
+		}
+
+		update := must.NotFail(types.NewDocument())
+		if set.Len() > 0 {
+			must.NoError(update.Set("$set", set))
+		}
+		if unset.Len() > 0 || update.Len() == 0 {
+			must.NoError(update.Set("$unset", unset))
+		}
+
+		// in-memory implementation, as in MsgUpdate
+		var expectedInserted bool
+		var expectedModified int64
+		expected := make(map[string]*types.Document, len(docs)+1)
+		for _, doc := range docs {
+			expected[must.NotFail(doc.Get("_id")).(string)] = doc.DeepCopy()
+		}
+
+		if doc, ok := expected[id]; ok {
+			changed, err := common.UpdateDocument(doc, update.DeepCopy())
+			require.NoError(t, err)
+			if changed {
+				expectedModified = 1
+			}
+		} else {
+			doc = filter.DeepCopy()
+			_, err := common.UpdateDocument(doc, update.DeepCopy())
+			require.NoError(t, err)
+
+			expected[id] = doc
+			expectedInserted = true
+		}
+
+		err := pool.InTransaction(ctx, func(tx pgx.Tx) error {
+			if err := InsertDocuments(ctx, tx, dbName, collectionName, docs); err != nil {
+				return err
+			}
+
+			msg := fmt.Sprintf("filter %v, update %v", filter, update)
+
+			sp := &SQLParam{DB: dbName, Collection: collectionName, Filter: filter}
+			inserted, nModified, pushdown, err := UpsertDocumentByID(ctx, tx, sp, update)
+			require.NoError(t, err)
+			require.True(t, pushdown, msg)
+
+			assert.Equal(t, expectedInserted, inserted, msg)
+			assert.Equal(t, expectedModified, nModified, msg)
+
+			iter, _, err := QueryIterator(ctx, tx, SQLParam{DB: dbName, Collection: collectionName})
+			require.NoError(t, err)
+			defer iter.Close()
+
+			var count int
+			for {
+				doc, err := iter.Next(ctx)
+				if errors.Is(err, ErrIteratorDone) {
+					break
+				}
+				require.NoError(t, err)
+				count++
+
+				expectedDoc := expected[must.NotFail(doc.Get("_id")).(string)]
+				require.NotNil(t, expectedDoc)
+
+				expectedB := string(must.NotFail(fjson.Marshal(expectedDoc)))
+				actualB := string(must.NotFail(fjson.Marshal(doc)))
+				assert.Equal(t, expectedB, actualB, msg)
+			}
+
+			assert.Equal(t, len(expected), count)
+
+			// keep the collection empty for the next iteration
+			return errRollback
+		})
+		require.ErrorIs(t, err, errRollback)
+	}
+}
+
+func TestUpsertDocumentByIDFallback(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Ctx(t)
+
+	pool := getPool(ctx, t, zaptest.NewLogger(t))
+	dbName := testutil.DatabaseName(t)
+	collectionName := testutil.CollectionName(t)
+
+	t.Cleanup(func() {
+		pool.DropDatabase(ctx, dbName)
+	})
+
+	pool.DropDatabase(ctx, dbName)
+	require.NoError(t, CreateDatabase(ctx, pool, dbName))
+	require.NoError(t, CreateCollection(ctx, pool, dbName, collectionName))
+
+	set := must.NotFail(types.NewDocument("$set", must.NotFail(types.NewDocument("v", "foo"))))
+
+	for name, tc := range map[string]struct {
+		filter *types.Document
+		update *types.Document
+	}{
+		"NoFilter":    {filter: nil, update: set},
+		"EmptyFilter": {filter: must.NotFail(types.NewDocument()), update: set},
+		"OtherField":  {filter: must.NotFail(types.NewDocument("v", "foo")), update: set},
+		"IDAndOther":  {filter: must.NotFail(types.NewDocument("_id", "foo", "v", "foo")), update: set},
+		"IntID":       {filter: must.NotFail(types.NewDocument("_id", int32(1))), update: set},
+		"Inc": {
+			filter: must.NotFail(types.NewDocument("_id", "foo")),
+			update: must.NotFail(types.NewDocument("$inc", must.NotFail(types.NewDocument("v", int32(1))))),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := pool.InTransaction(ctx, func(tx pgx.Tx) error {
+				sp := &SQLParam{DB: dbName, Collection: collectionName, Filter: tc.filter}
+				_, _, pushdown, err := UpsertDocumentByID(ctx, tx, sp, tc.update)
+				require.NoError(t, err)
+				assert.False(t, pushdown)
+				return nil
+			})
+			require.NoError(t, err)
+		})
+	}
+
+	t.Run("NoIndex", func(t *testing.T) {
+		t.Parallel()
+
+		// emulate a collection created by an older version
+		oldCollectionName := collectionName + "_old"
+		require.NoError(t, CreateCollection(ctx, pool, dbName, oldCollectionName))
+
+		table, err := getTableName(ctx, pool, dbName, oldCollectionName)
+		require.NoError(t, err)
+
+		_, err = pool.Exec(ctx, `DROP INDEX `+pgx.Identifier{dbName, idIndexName(table)}.Sanitize())
+		require.NoError(t, err)
+
+		err = pool.InTransaction(ctx, func(tx pgx.Tx) error {
+			sp := &SQLParam{DB: dbName, Collection: oldCollectionName, Filter: must.NotFail(types.NewDocument("_id", "foo"))}
+			_, _, pushdown, err := UpsertDocumentByID(ctx, tx, sp, set)
+			require.NoError(t, err)
+			assert.False(t, pushdown)
+			return nil
+		})
+		require.NoError(t, err)
+	})
+}
@@ -50,6 +50,9 @@ func (r *Router) Close() {
 }
 
 // Route routes the message by sending it to another wire protocol compatible service.
+//
+// For OP_MSG requests with moreToCome flag, it returns nil header and body,
+// as the service does not reply to them.
 func (r *Router) Route(ctx context.Context, header *wire.MsgHeader, body wire.MsgBody) (*wire.MsgHeader, wire.MsgBody, bool) {
 	deadline, _ := ctx.Deadline()
 	r.conn.SetDeadline(deadline)
@@ -62,10 +65,21 @@ func (r *Router) Route(ctx context.Context, header *wire.MsgHeader, body wire.Ms
 		panic(err)
 	}
 
+	if msg, ok := body.(*wire.OpMsg); ok && msg.FlagBits.FlagSet(wire.OpMsgMoreToCome) {
+		return nil, nil, false
+	}
+
 	resHeader, resBody, err := wire.ReadMessage(r.bufr)
 	if err != nil {
 		panic(err)
 	}
 
+	// FerretDB's response is not compressed at that point, so unwrap for diffing
+	if compressed, ok := resBody.(*wire.OpCompressed); ok {
+		if resHeader, resBody, err = wire.Decompress(resHeader, compressed); err != nil {
+			panic(err)
+		}
+	}
+
 	return resHeader, resBody, false
 }
@@ -0,0 +1,58 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/FerretDB/FerretDB/internal/handlers"
+	"github.com/FerretDB/FerretDB/internal/handlers/pg"
+	"github.com/FerretDB/FerretDB/internal/handlers/pg/pgdb"
+)
+
+// init registers "pg" handler for PostgreSQL.
+func init() {
+	Register("pg", func(opts *NewHandlerOpts) (handlers.Interface, error) {
+		poolOpts := opts.PostgreSQLPool
+		poolOpts.Lazy = true
+
+		pgPool, err := pgdb.NewPoolWithOpts(opts.Ctx, opts.PostgreSQLURL, opts.Logger, &poolOpts)
+		if err != nil {
+			return nil, err
+		}
+
+		if opts.PostgreSQLRepairDatabases {
+			repaired, err := pgdb.RepairDatabases(opts.Ctx, pgPool)
+			if err != nil {
+				pgPool.Close()
+				return nil, err
+			}
+
+			if len(repaired) > 0 {
+				opts.Logger.Warn("Repaired databases with missing settings tables", zap.Strings("databases", repaired))
+			}
+		}
+
+		handlerOpts := &pg.NewOpts{
+			PgPool:            pgPool,
+			L:                 opts.Logger,
+			DisableJSONBIndex: opts.PostgreSQLDisableJSONBIndex,
+
+			ChangeStreams:          opts.PostgreSQLChangeStreams,
+			ChangeStreamsRetention: opts.PostgreSQLChangeStreamsRetention,
+		}
+		return pg.New(handlerOpts)
+	})
+}
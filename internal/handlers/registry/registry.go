@@ -18,40 +18,71 @@ package registry
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
 
 	"github.com/FerretDB/FerretDB/internal/handlers"
-	"github.com/FerretDB/FerretDB/internal/handlers/dummy"
-	"github.com/FerretDB/FerretDB/internal/handlers/pg"
 	"github.com/FerretDB/FerretDB/internal/handlers/pg/pgdb"
 )
 
-// newHandlerFunc represents a function that constructs a new handler.
-type newHandlerFunc func(opts *NewHandlerOpts) (handlers.Interface, error)
+// NewHandlerFunc represents a function that constructs a new handler.
+//
+// Common options (Ctx, Logger, Metrics) are always set when it is called.
+type NewHandlerFunc func(opts *NewHandlerOpts) (handlers.Interface, error)
 
 // registry maps handler names to constructors.
 //
-// Map values must be added through the `init()` functions in separate files
+// Map values must be added with Register, usually from the `init()` functions in separate files
 // so that we can control which handlers will be included in the build with build tags.
-var registry = map[string]newHandlerFunc{}
+var registry = map[string]NewHandlerFunc{}
+
+// Register makes a handler constructor available by the provided name.
+//
+// It should be called from the `init()` function of the file or package providing the handler,
+// before NewHandler or Handlers are used; it is not safe for concurrent use.
+// It panics if name is empty, newHandler is nil, or name is already registered.
+func Register(name string, newHandler NewHandlerFunc) {
+	if name == "" {
+		panic("registry: handler name is empty")
+	}
+
+	if newHandler == nil {
+		panic(fmt.Sprintf("registry: constructor for handler %q is nil", name))
+	}
+
+	if _, ok := registry[name]; ok {
+		panic(fmt.Sprintf("registry: handler %q is already registered", name))
+	}
+
+	registry[name] = newHandler
+}
 
 // NewHandlerOpts represents configuration for constructing handlers.
 type NewHandlerOpts struct {
 	// for all handlers
-	Ctx    context.Context
-	Logger *zap.Logger
+	Ctx     context.Context       // must not be nil
+	Logger  *zap.Logger           // nil means zap.L()
+	Metrics prometheus.Registerer // handler metrics, if any, are registered there; nil means prometheus.DefaultRegisterer
 
 	// for `pg` handler
-	PostgreSQLURL string
+	PostgreSQLURL                    string
+	PostgreSQLDisableJSONBIndex      bool
+	PostgreSQLRepairDatabases        bool
+	PostgreSQLPool                   pgdb.NewPoolOpts
+	PostgreSQLChangeStreams          bool
+	PostgreSQLChangeStreamsRetention time.Duration
 
 	// for `tigris` handler
 	TigrisURL string
 }
 
-// NewHandler constructs a new handler.
+// NewHandler constructs a new handler registered with the given name.
+//
+// If handler implements prometheus.Collector, it is registered in opts.Metrics.
 func NewHandler(name string, opts *NewHandlerOpts) (handlers.Interface, error) {
 	if opts == nil {
 		return nil, fmt.Errorf("opts is nil")
@@ -65,32 +96,35 @@ func NewHandler(name string, opts *NewHandlerOpts) (handlers.Interface, error) {
 		return nil, fmt.Errorf("unknown handler %q", name)
 	}
 
-	return newHandler(opts)
-}
+	o := *opts
+	opts = &o
 
-// Handlers returns a list of all handlers registered at compile-time.
-func Handlers() []string {
-	handlers := maps.Keys(registry)
-	slices.Sort(handlers)
-	return handlers
-}
+	if opts.Logger == nil {
+		opts.Logger = zap.L()
+	}
 
-// init registers handlers that are always enabled.
-func init() {
-	registry["dummy"] = func(*NewHandlerOpts) (handlers.Interface, error) {
-		return dummy.New()
+	if opts.Metrics == nil {
+		opts.Metrics = prometheus.DefaultRegisterer
 	}
 
-	registry["pg"] = func(opts *NewHandlerOpts) (handlers.Interface, error) {
-		pgPool, err := pgdb.NewPool(opts.Ctx, opts.PostgreSQLURL, opts.Logger, true)
-		if err != nil {
-			return nil, err
-		}
+	h, err := newHandler(opts)
+	if err != nil {
+		return nil, err
+	}
 
-		handlerOpts := &pg.NewOpts{
-			PgPool: pgPool,
-			L:      opts.Logger,
+	if c, ok := h.(prometheus.Collector); ok {
+		if err = opts.Metrics.Register(c); err != nil {
+			h.Close()
+			return nil, fmt.Errorf("failed to register handler metrics: %w", err)
 		}
-		return pg.New(handlerOpts)
 	}
+
+	return h, nil
+}
+
+// Handlers returns a sorted list of names of all registered handlers.
+func Handlers() []string {
+	handlers := maps.Keys(registry)
+	slices.Sort(handlers)
+	return handlers
 }
@@ -0,0 +1,215 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/FerretDB/FerretDB/internal/handlers"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/dummy"
+	"github.com/FerretDB/FerretDB/internal/handlers/registry"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/wire"
+)
+
+// memoryHandler is a tiny in-memory handler defined outside of the registry package.
+//
+// It embeds the dummy handler for commands it does not implement.
+type memoryHandler struct {
+	*dummy.Handler
+
+	rw          sync.RWMutex
+	collections map[string]int32
+
+	inserted prometheus.Counter
+}
+
+// newMemoryHandler implements registry.NewHandlerFunc.
+func newMemoryHandler(opts *registry.NewHandlerOpts) (handlers.Interface, error) {
+	return &memoryHandler{
+		Handler:     new(dummy.Handler),
+		collections: map[string]int32{},
+		inserted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "ferretdb",
+			Subsystem: "memory",
+			Name:      "inserted_total",
+			Help:      "The total number of inserted documents.",
+		}),
+	}, nil
+}
+
+// MsgInsert implements handlers.Interface.
+func (h *memoryHandler) MsgInsert(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, err
+	}
+
+	collection, err := common.GetRequiredParam[string](document, document.Command())
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err := common.GetRequiredParam[*types.Array](document, "documents")
+	if err != nil {
+		return nil, err
+	}
+
+	h.rw.Lock()
+	h.collections[collection] += int32(docs.Len())
+	h.rw.Unlock()
+
+	h.inserted.Add(float64(docs.Len()))
+
+	return reply("n", int32(docs.Len()), "ok", float64(1)), nil
+}
+
+// MsgCount implements handlers.Interface.
+func (h *memoryHandler) MsgCount(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
+	document, err := msg.Document()
+	if err != nil {
+		return nil, err
+	}
+
+	collection, err := common.GetRequiredParam[string](document, document.Command())
+	if err != nil {
+		return nil, err
+	}
+
+	h.rw.RLock()
+	n := h.collections[collection]
+	h.rw.RUnlock()
+
+	return reply("n", n, "ok", float64(1)), nil
+}
+
+// Describe implements prometheus.Collector.
+func (h *memoryHandler) Describe(ch chan<- *prometheus.Desc) {
+	h.inserted.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (h *memoryHandler) Collect(ch chan<- prometheus.Metric) {
+	h.inserted.Collect(ch)
+}
+
+// reply returns a reply with a single document built from the given pairs.
+func reply(pairs ...any) *wire.OpMsg {
+	var msg wire.OpMsg
+	must.NoError(msg.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(pairs...))},
+	}))
+
+	return &msg
+}
+
+// request returns a request with a single document built from the given pairs.
+func request(pairs ...any) *wire.OpMsg {
+	return reply(pairs...)
+}
+
+// check interfaces
+var (
+	_ handlers.Interface   = (*memoryHandler)(nil)
+	_ prometheus.Collector = (*memoryHandler)(nil)
+)
+
+func init() {
+	registry.Register("memory", newMemoryHandler)
+}
+
+func TestRegister(t *testing.T) {
+	t.Parallel()
+
+	assert.Contains(t, registry.Handlers(), "dummy")
+	assert.Contains(t, registry.Handlers(), "pg")
+	assert.Contains(t, registry.Handlers(), "memory")
+
+	metrics := prometheus.NewRegistry()
+
+	h, err := registry.NewHandler("memory", &registry.NewHandlerOpts{
+		Ctx:     context.Background(),
+		Logger:  zaptest.NewLogger(t),
+		Metrics: metrics,
+	})
+	require.NoError(t, err)
+	defer h.Close()
+
+	ctx := context.Background()
+
+	res, err := h.MsgInsert(ctx, request(
+		"insert", "values",
+		"documents", must.NotFail(types.NewArray(
+			must.NotFail(types.NewDocument("_id", int32(1))),
+			must.NotFail(types.NewDocument("_id", int32(2))),
+		)),
+		"$db", "test",
+	))
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), must.NotFail(must.NotFail(res.Document()).Get("n")))
+
+	res, err = h.MsgCount(ctx, request("count", "values", "$db", "test"))
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), must.NotFail(must.NotFail(res.Document()).Get("n")))
+
+	// commands that are not implemented fall back to the embedded dummy handler
+	_, err = h.MsgPing(ctx, request("ping", int32(1), "$db", "test"))
+	var e *common.CommandError
+	require.ErrorAs(t, err, &e)
+	assert.Equal(t, common.ErrNotImplemented, e.Code())
+
+	mfs, err := metrics.Gather()
+	require.NoError(t, err)
+	require.Len(t, mfs, 1)
+	assert.Equal(t, "ferretdb_memory_inserted_total", mfs[0].GetName())
+	assert.Equal(t, float64(2), mfs[0].GetMetric()[0].GetCounter().GetValue())
+}
+
+func TestRegisterPanics(t *testing.T) {
+	t.Parallel()
+
+	assert.PanicsWithValue(t, "registry: handler name is empty", func() {
+		registry.Register("", newMemoryHandler)
+	})
+	assert.PanicsWithValue(t, `registry: constructor for handler "other" is nil`, func() {
+		registry.Register("other", nil)
+	})
+	assert.PanicsWithValue(t, `registry: handler "memory" is already registered`, func() {
+		registry.Register("memory", newMemoryHandler)
+	})
+	assert.NotContains(t, registry.Handlers(), "other")
+}
+
+func TestNewHandlerErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := registry.NewHandler("memory", nil)
+	assert.EqualError(t, err, "opts is nil")
+
+	_, err = registry.NewHandler("memory", new(registry.NewHandlerOpts))
+	assert.EqualError(t, err, "opts.Ctx is nil")
+
+	_, err = registry.NewHandler("unknown", &registry.NewHandlerOpts{Ctx: context.Background()})
+	assert.EqualError(t, err, `unknown handler "unknown"`)
+}
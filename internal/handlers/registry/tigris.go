@@ -23,11 +23,11 @@ import (
 
 // init registers "tigris" handler for Tigris when "ferretdb_tigris" build tag is provided.
 func init() {
-	registry["tigris"] = func(opts *NewHandlerOpts) (handlers.Interface, error) {
+	Register("tigris", func(opts *NewHandlerOpts) (handlers.Interface, error) {
 		handlerOpts := &tigris.NewOpts{
 			TigrisURL: opts.TigrisURL,
 			L:         opts.Logger,
 		}
 		return tigris.New(handlerOpts)
-	}
+	})
 }
@@ -16,6 +16,9 @@ package tigris
 
 import (
 	"context"
+	"encoding/json"
+	"sort"
+	"strings"
 
 	"github.com/tigrisdata/tigris-client-go/driver"
 	"go.uber.org/zap"
@@ -24,18 +27,53 @@ import (
 	"github.com/FerretDB/FerretDB/internal/tjson"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
 )
 
 // fetchParam represents options/parameters used by the fetch.
 type fetchParam struct {
 	db         string
 	collection string
+
+	// filter, if set, is used to narrow down fetched documents when possible;
+	// fetched documents still should be matched against it.
+	filter *types.Document
 }
 
 // fetch fetches all documents from the given database and collection.
 //
 // TODO https://github.com/FerretDB/FerretDB/issues/372
 func (h *Handler) fetch(ctx context.Context, param fetchParam) ([]*types.Document, error) {
+	iter, schema, err := h.read(ctx, param)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if iter == nil {
+		return []*types.Document{}, nil
+	}
+	defer iter.Close()
+
+	var res []*types.Document
+	var d driver.Document
+	for iter.Next(&d) {
+		doc, err := tjson.Unmarshal(d, schema)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		res = append(res, doc.(*types.Document))
+	}
+
+	return res, iter.Err()
+}
+
+// read starts reading documents from the given database and collection.
+// It returns Tigris iterator and the collection schema that should be used to unmarshal documents.
+//
+// If the collection doesn't exist, it returns nil iterator and no error.
+// Otherwise, the caller should close the iterator.
+func (h *Handler) read(ctx context.Context, param fetchParam) (driver.Iterator, *tjson.Schema, error) {
 	db := h.db.Driver.UseDatabase(param.db)
 
 	collection, err := db.DescribeCollection(ctx, param.collection)
@@ -48,34 +86,80 @@ func (h *Handler) fetch(ctx context.Context, param fetchParam) ([]*types.Documen
 				"Collection doesn't exist, handling a case to deal with a non-existing collection (return empty list)",
 				zap.String("db", param.db), zap.String("collection", param.collection),
 			)
-			return []*types.Document{}, nil
+			return nil, nil, nil
 		}
-		return nil, lazyerrors.Error(err)
+		return nil, nil, lazyerrors.Error(err)
 	default:
-		return nil, lazyerrors.Error(err)
+		return nil, nil, lazyerrors.Error(err)
 	}
 
 	var schema tjson.Schema
 	if err = schema.Unmarshal(collection.Schema); err != nil {
-		return nil, lazyerrors.Error(err)
+		return nil, nil, lazyerrors.Error(err)
 	}
 
-	iter, err := db.Read(ctx, param.collection, driver.Filter(`{}`), nil)
+	f := pushdownFilter(param.filter, &schema)
+	h.L.Sugar().Debugf("Read filter: %s", f)
+
+	iter, err := db.Read(ctx, param.collection, f, nil)
 	if err != nil {
-		return nil, lazyerrors.Error(err)
+		return nil, nil, lazyerrors.Error(err)
 	}
-	defer iter.Close()
 
-	var res []*types.Document
-	var d driver.Document
-	for iter.Next(&d) {
-		doc, err := tjson.Unmarshal(d, &schema)
-		if err != nil {
-			return nil, lazyerrors.Error(err)
+	return iter, &schema, nil
+}
+
+// pushdownFilter returns Tigris filter for the subset of the given filter that could be handled by Tigris.
+//
+// Only equality conditions on top-level string, boolean and ObjectID fields
+// with the same type in the collection schema are pushed down;
+// that way, MongoDB comparison semantics are preserved for them.
+func pushdownFilter(filter *types.Document, schema *tjson.Schema) driver.Filter {
+	if filter == nil {
+		return driver.Filter(`{}`)
+	}
+
+	keys := filter.Keys()
+	sort.Strings(keys)
+
+	var conds []map[string]map[string]json.RawMessage
+	for _, k := range keys {
+		if k == "" || strings.HasPrefix(k, "$") || strings.Contains(k, ".") {
+			continue
 		}
 
-		res = append(res, doc.(*types.Document))
+		s, ok := schema.Properties[k]
+		if !ok {
+			continue
+		}
+
+		v := must.NotFail(filter.Get(k))
+		switch v.(type) {
+		case string:
+			ok = s.Type == tjson.String && s.Format == tjson.EmptyFormat
+		case bool:
+			ok = s.Type == tjson.Boolean
+		case types.ObjectID:
+			ok = s.Type == tjson.String && s.Format == tjson.Byte
+		default:
+			ok = false
+		}
+
+		if !ok {
+			continue
+		}
+
+		conds = append(conds, map[string]map[string]json.RawMessage{
+			k: {"$eq": must.NotFail(tjson.Marshal(v))},
+		})
 	}
 
-	return res, iter.Err()
+	switch len(conds) {
+	case 0:
+		return driver.Filter(`{}`)
+	case 1:
+		return must.NotFail(json.Marshal(conds[0]))
+	default:
+		return must.NotFail(json.Marshal(map[string]any{"$and": conds}))
+	}
 }
@@ -0,0 +1,126 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tigris
+
+import (
+	"context"
+
+	"github.com/tigrisdata/tigris-client-go/driver"
+
+	"github.com/FerretDB/FerretDB/internal/cursor"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/tjson"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// queryIterator implements cursor.Iterator on top of Tigris read iterator.
+//
+// Documents are unmarshaled, filtered, limited and projected as they are read,
+// so the Tigris iterator stays open between batches, and only the current batch is kept in memory.
+// It is used for queries without sort.
+type queryIterator struct {
+	iter   driver.Iterator // nil if the collection does not exist or the iterator is closed
+	cancel context.CancelFunc
+	schema *tjson.Schema
+
+	filter     *types.Document
+	projection *types.Document
+	limit      int64 // zero means no limit
+	returned   int64
+}
+
+// newQueryIterator starts reading documents for the given parameters.
+//
+// Tigris iterator uses its own context, not the one of the request,
+// because it outlives the request; it is canceled when the iterator is closed.
+func (h *Handler) newQueryIterator(fp fetchParam, projection *types.Document, limit int64) (*queryIterator, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	iter, schema, err := h.read(ctx, fp)
+	if err != nil {
+		cancel()
+		return nil, lazyerrors.Error(err)
+	}
+
+	return &queryIterator{
+		iter:       iter,
+		cancel:     cancel,
+		schema:     schema,
+		filter:     fp.filter,
+		projection: projection,
+		limit:      limit,
+	}, nil
+}
+
+// Next implements cursor.Iterator interface.
+func (it *queryIterator) Next(ctx context.Context) (*types.Document, error) {
+	if it.iter == nil || (it.limit > 0 && it.returned >= it.limit) {
+		return nil, cursor.ErrIteratorDone
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var d driver.Document
+	for it.iter.Next(&d) {
+		v, err := tjson.Unmarshal(d, it.schema)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		doc := v.(*types.Document)
+
+		matches, err := common.FilterDocument(doc, it.filter)
+		if err != nil {
+			return nil, err
+		}
+
+		if !matches {
+			continue
+		}
+
+		docs := []*types.Document{doc}
+		if err = common.ProjectDocuments(docs, it.projection); err != nil {
+			return nil, err
+		}
+
+		it.returned++
+
+		return docs[0], nil
+	}
+
+	if err := it.iter.Err(); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return nil, cursor.ErrIteratorDone
+}
+
+// Close implements cursor.Iterator interface.
+func (it *queryIterator) Close() {
+	if it.iter != nil {
+		it.iter.Close()
+		it.iter = nil
+	}
+
+	it.cancel()
+}
+
+// check interfaces
+var (
+	_ cursor.Iterator = (*queryIterator)(nil)
+)
@@ -17,12 +17,52 @@ package tigris
 import (
 	"context"
 
-	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/tigrisdata/tigris-client-go/driver"
+
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/handlers/tigris/tigrisdb"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
 // MsgCollStats implements HandlerInterface.
 func (h *Handler) MsgCollStats(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
-	// TODO https://github.com/FerretDB/FerretDB/issues/770
-	return nil, notImplemented(must.NotFail(msg.Document()).Command())
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	params, err := common.GetCollStatsParams(document)
+	if err != nil {
+		return nil, err
+	}
+
+	description, err := h.db.Driver.UseDatabase(params.DB).DescribeCollection(ctx, params.Collection)
+	switch err := err.(type) {
+	case nil:
+		// do nothing
+	case *driver.Error:
+		if !tigrisdb.IsNotFound(err) {
+			return nil, lazyerrors.Error(err)
+		}
+
+		// If collection doesn't exist just return empty stats.
+		return common.CollStatsReply(params, new(common.CollStats))
+	default:
+		return nil, lazyerrors.Error(err)
+	}
+
+	// TODO We need a better way to get the number of documents in a collection.
+	docs, err := h.fetch(ctx, fetchParam{db: params.DB, collection: params.Collection})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	// Tigris indexes all the fields https://docs.tigrisdata.com/apidocs/#operation/Tigris_Read
+	return common.CollStatsReply(params, &common.CollStats{
+		Count:       int64(len(docs)),
+		Size:        description.Size,
+		StorageSize: description.Size,
+		TotalSize:   description.Size,
+	})
 }
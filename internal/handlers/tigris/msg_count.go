@@ -33,7 +33,6 @@ func (h *Handler) MsgCount(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, e
 	}
 
 	unimplementedFields := []string{
-		"skip",
 		"collation",
 	}
 	if err := common.Unimplemented(document, unimplementedFields...); err != nil {
@@ -51,11 +50,15 @@ func (h *Handler) MsgCount(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, e
 		return nil, err
 	}
 
-	var limit int64
-	if l, _ := document.Get("limit"); l != nil {
-		if limit, err = common.GetWholeNumberParam(l); err != nil {
-			return nil, err
-		}
+	// negative limit is the same as positive for count
+	limit, _, err := common.GetLimitParam(document, document.Command())
+	if err != nil {
+		return nil, err
+	}
+
+	skip, err := common.GetSkipParam(document, document.Command())
+	if err != nil {
+		return nil, err
 	}
 
 	var fp fetchParam
@@ -93,6 +96,10 @@ func (h *Handler) MsgCount(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, e
 		resDocs = append(resDocs, doc)
 	}
 
+	if resDocs, err = common.SkipDocuments(resDocs, skip); err != nil {
+		return nil, err
+	}
+
 	if resDocs, err = common.LimitDocuments(resDocs, limit); err != nil {
 		return nil, err
 	}
@@ -40,11 +40,9 @@ func (h *Handler) MsgDBStats(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		return nil, err
 	}
 
-	m := document.Map()
-	scale, ok := m["scale"].(float64)
-
-	if !ok {
-		scale = 1
+	scale, err := common.GetScaleParam(document, "dbStats")
+	if err != nil {
+		return nil, err
 	}
 
 	stats, err := h.db.Driver.DescribeDatabase(ctx, db)
@@ -66,8 +64,9 @@ func (h *Handler) MsgDBStats(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		return nil, lazyerrors.Error(err)
 	}
 
+	// Tigris does not provide document counts, so count documents in each collection.
 	// TODO We need a better way to get the number of documents in all collections.
-	var objects int32
+	var objects int64
 
 	for _, collection := range stats.Collections {
 		f := fetchParam{db: db, collection: collection.Collection}
@@ -76,7 +75,7 @@ func (h *Handler) MsgDBStats(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		if err != nil {
 			return nil, lazyerrors.Error(err)
 		}
-		objects += int32(len(docs))
+		objects += int64(len(docs))
 	}
 
 	var avgObjSize float64
@@ -92,17 +91,16 @@ func (h *Handler) MsgDBStats(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 			// TODO https://github.com/FerretDB/FerretDB/issues/176
 			"views", int32(0),
 			"objects", int32(objects),
-			"avgObjSize", float64(avgObjSize),
-			"dataSize", float64(stats.Size),
+			"avgObjSize", avgObjSize,
+			"dataSize", float64(stats.Size)/scale,
 			// Tigris indexes all the fields https://docs.tigrisdata.com/apidocs/#operation/Tigris_Read
 			"indexes", int32(0),
-			"indexSize", int32(0),
-			"totalSize", int32(0),
+			"indexSize", float64(0),
+			"totalSize", float64(stats.Size)/scale,
 			"scaleFactor", scale,
 			"ok", float64(1),
 		))},
 	})
-
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
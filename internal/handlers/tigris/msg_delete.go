@@ -40,7 +40,6 @@ func (h *Handler) MsgDelete(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 	if err := common.Unimplemented(document, "let"); err != nil {
 		return nil, err
 	}
-	common.Ignored(document, h.L, "ordered") // TODO https://github.com/FerretDB/FerretDB/issues/848
 	common.Ignored(document, h.L, "writeConcern")
 
 	var deletes *types.Array
@@ -48,92 +47,134 @@ func (h *Handler) MsgDelete(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		return nil, err
 	}
 
+	ordered := true
+	if document.Has("ordered") {
+		if ordered, err = common.GetBoolOptionalParam(document, "ordered"); err != nil {
+			return nil, err
+		}
+	}
+
+	var fp fetchParam
+	if fp.db, err = common.GetRequiredParam[string](document, "$db"); err != nil {
+		return nil, err
+	}
+	collectionParam, err := document.Get(document.Command())
+	if err != nil {
+		return nil, err
+	}
+	var ok bool
+	if fp.collection, ok = collectionParam.(string); !ok {
+		return nil, common.NewErrorMsg(
+			common.ErrBadValue,
+			fmt.Sprintf("collection name has invalid type %s", common.AliasFromType(collectionParam)),
+		)
+	}
+
 	var deleted int32
+	var writeErrs common.WriteErrors
 	for i := 0; i < deletes.Len(); i++ {
 		d, err := common.AssertType[*types.Document](must.NotFail(deletes.Get(i)))
 		if err != nil {
 			return nil, err
 		}
 
-		if err := common.Unimplemented(d, "collation", "hint"); err != nil {
-			return nil, err
+		n, err := h.execDelete(ctx, fp, d)
+		deleted += n
+
+		if err == nil {
+			continue
 		}
 
-		var filter *types.Document
-		if filter, err = common.GetOptionalParam(d, "q", filter); err != nil {
+		// errors of individual statements are reported as write errors
+		if _, ok := common.ProtocolError(err); !ok {
 			return nil, err
 		}
 
-		var limit int64
-		if l, _ := d.Get("limit"); l != nil {
-			if limit, err = common.GetWholeNumberParam(l); err != nil {
-				return nil, err
-			}
-		}
+		writeErrs.Append(err, int32(i))
 
-		var fp fetchParam
-		if fp.db, err = common.GetRequiredParam[string](document, "$db"); err != nil {
-			return nil, err
-		}
-		collectionParam, err := document.Get(document.Command())
-		if err != nil {
-			return nil, err
-		}
-		var ok bool
-		if fp.collection, ok = collectionParam.(string); !ok {
-			return nil, common.NewErrorMsg(
-				common.ErrBadValue,
-				fmt.Sprintf("collection name has invalid type %s", common.AliasFromType(collectionParam)),
-			)
+		if ordered {
+			break
 		}
+	}
 
-		fetchedDocs, err := h.fetch(ctx, fp)
-		if err != nil {
-			return nil, err
-		}
+	replyDoc := must.NotFail(types.NewDocument(
+		"n", deleted,
+	))
+	if len(writeErrs) > 0 {
+		must.NoError(replyDoc.Set("writeErrors", must.NotFail(writeErrs.Document().Get("writeErrors"))))
+	}
+	must.NoError(replyDoc.Set("ok", float64(1)))
 
-		resDocs := make([]*types.Document, 0, 16)
-		for _, doc := range fetchedDocs {
-			matches, err := common.FilterDocument(doc, filter)
-			if err != nil {
-				return nil, err
-			}
+	var reply wire.OpMsg
+	err = reply.SetSections(wire.OpMsgSection{
+		Documents: []*types.Document{replyDoc},
+	})
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return &reply, nil
+}
+
+// execDelete executes a single delete statement and returns the number of deleted documents.
+func (h *Handler) execDelete(ctx context.Context, fp fetchParam, d *types.Document) (int32, error) {
+	if err := common.Unimplemented(d, "collation", "hint"); err != nil {
+		return 0, err
+	}
 
-			if !matches {
-				continue
-			}
+	var filter *types.Document
+	var err error
+	if filter, err = common.GetOptionalParam(d, "q", filter); err != nil {
+		return 0, err
+	}
 
-			resDocs = append(resDocs, doc)
+	var limit int64
+	if l, _ := d.Get("limit"); l != nil {
+		if limit, err = common.GetWholeNumberParam(l); err != nil {
+			return 0, err
 		}
+	}
 
-		if resDocs, err = common.LimitDocuments(resDocs, limit); err != nil {
-			return nil, err
+	// negative limits are not supported yet
+	if _, err = common.LimitDocuments(nil, limit); err != nil {
+		return 0, err
+	}
+
+	fp.filter = filter
+
+	fetchedDocs, err := h.fetch(ctx, fp)
+	if err != nil {
+		return 0, err
+	}
+
+	resDocs := make([]*types.Document, 0, 16)
+	for _, doc := range fetchedDocs {
+		matches, err := common.FilterDocument(doc, filter)
+		if err != nil {
+			return 0, err
 		}
 
-		if len(resDocs) == 0 {
+		if !matches {
 			continue
 		}
 
-		res, err := h.delete(ctx, fp, resDocs)
-		if err != nil {
-			return nil, err
+		resDocs = append(resDocs, doc)
+
+		if limit > 0 && int64(len(resDocs)) == limit {
+			break
 		}
+	}
 
-		deleted += int32(res)
+	if len(resDocs) == 0 {
+		return 0, nil
 	}
 
-	var reply wire.OpMsg
-	err = reply.SetSections(wire.OpMsgSection{
-		Documents: []*types.Document{must.NotFail(types.NewDocument(
-			"n", deleted,
-			"ok", float64(1),
-		))},
-	})
+	n, err := h.delete(ctx, fp, resDocs)
 	if err != nil {
-		return nil, lazyerrors.Error(err)
+		return 0, err
 	}
 
-	return &reply, nil
+	return int32(n), nil
 }
 
 // delete deletes documents by _id.
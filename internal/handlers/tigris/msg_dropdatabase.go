@@ -41,21 +41,17 @@ func (h *Handler) MsgDropDatabase(ctx context.Context, msg *wire.OpMsg) (*wire.O
 		return nil, err
 	}
 
-	res := must.NotFail(types.NewDocument())
-	err = h.db.Driver.DropDatabase(ctx, db)
-	switch err := err.(type) {
-	case nil:
-		res.Set("dropped", db)
-	case *driver.Error:
-		if !tigrisdb.IsNotFound(err) {
-			return nil, lazyerrors.Error(err)
-		}
-		// nothing otherwise
-	default:
+	dropped, err := h.dropDatabase(ctx, db)
+	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
-	res.Set("ok", float64(1))
+	// "dropped" field is present only if database existed
+	res := must.NotFail(types.NewDocument())
+	if dropped {
+		must.NoError(res.Set("dropped", db))
+	}
+	must.NoError(res.Set("ok", float64(1)))
 
 	var reply wire.OpMsg
 	err = reply.SetSections(wire.OpMsgSection{
@@ -67,3 +63,31 @@ func (h *Handler) MsgDropDatabase(ctx context.Context, msg *wire.OpMsg) (*wire.O
 
 	return &reply, nil
 }
+
+// dropDatabase drops the database and returns true if it was dropped,
+// or false if it did not exist.
+//
+// Concurrent drops of the same database may fail with other errors than "not found";
+// in that case, the database is checked to be gone.
+func (h *Handler) dropDatabase(ctx context.Context, db string) (bool, error) {
+	err := h.db.Driver.DropDatabase(ctx, db)
+	if err == nil {
+		return true, nil
+	}
+
+	driverErr, ok := err.(*driver.Error)
+	if !ok {
+		return false, lazyerrors.Error(err)
+	}
+
+	if tigrisdb.IsNotFound(driverErr) {
+		return false, nil
+	}
+
+	_, describeErr := h.db.Driver.DescribeDatabase(ctx, db)
+	if describeErr, ok := describeErr.(*driver.Error); ok && tigrisdb.IsNotFound(describeErr) {
+		return false, nil
+	}
+
+	return false, lazyerrors.Error(err)
+}
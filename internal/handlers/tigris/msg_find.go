@@ -19,6 +19,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/FerretDB/FerretDB/internal/clientconn/conninfo"
+	"github.com/FerretDB/FerretDB/internal/cursor"
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
@@ -39,7 +41,6 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 		"showRecordId",
 		"tailable",
 		"oplogReplay",
-		"noCursorTimeout",
 		"awaitData",
 		"allowPartialResults",
 		"collation",
@@ -51,8 +52,6 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 	}
 	ignoredFields := []string{
 		"hint",
-		"batchSize",
-		"singleBatch",
 		"readConcern",
 		"max",
 		"min",
@@ -82,11 +81,29 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 		ctx = ctxWithTimeout
 	}
 
-	var limit int64
-	if l, _ := document.Get("limit"); l != nil {
-		if limit, err = common.GetWholeNumberParam(l); err != nil {
-			return nil, err
-		}
+	batchSize, err := common.GetBatchSizeParam(document, document.Command(), common.DefaultBatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	singleBatch, err := common.GetBoolOptionalParam(document, "singleBatch")
+	if err != nil {
+		return nil, err
+	}
+
+	noCursorTimeout, err := common.GetBoolOptionalParam(document, "noCursorTimeout")
+	if err != nil {
+		return nil, err
+	}
+
+	// negative limit means a single batch
+	limit, singleBatchLimit, err := common.GetLimitParam(document, document.Command())
+	if err != nil {
+		return nil, err
+	}
+	if singleBatchLimit {
+		singleBatch = true
+		batchSize = limit
 	}
 
 	var fp fetchParam
@@ -105,49 +122,70 @@ func (h *Handler) MsgFind(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, er
 		)
 	}
 
-	fetchedDocs, err := h.fetch(ctx, fp)
-	if err != nil {
-		return nil, err
-	}
+	fp.filter = filter
+
+	stats := &conninfo.GetConnInfo(ctx).OpStats
+	stats.Reported = true
+
+	var iter cursor.Iterator
 
-	resDocs := make([]*types.Document, 0, 16)
-	for _, doc := range fetchedDocs {
-		matches, err := common.FilterDocument(doc, filter)
-		if err != nil {
+	// without sort, documents are streamed from Tigris as the client requests more batches
+	if sort.Len() == 0 {
+		if iter, err = h.newQueryIterator(fp, projection, limit); err != nil {
 			return nil, err
 		}
+	} else {
+		var fetchedDocs []*types.Document
+		if fetchedDocs, err = h.fetch(ctx, fp); err != nil {
+			return nil, err
+		}
+
+		resDocs := make([]*types.Document, 0, 16)
+		for _, doc := range fetchedDocs {
+			matches, err := common.FilterDocument(doc, filter)
+			if err != nil {
+				return nil, err
+			}
 
-		if !matches {
-			continue
+			if !matches {
+				continue
+			}
+
+			resDocs = append(resDocs, doc)
 		}
 
-		resDocs = append(resDocs, doc)
-	}
+		if err = common.SortDocuments(resDocs, sort); err != nil {
+			return nil, err
+		}
+		if resDocs, err = common.LimitDocuments(resDocs, limit); err != nil {
+			return nil, err
+		}
+		if err = common.ProjectDocuments(resDocs, projection); err != nil {
+			return nil, err
+		}
 
-	if err = common.SortDocuments(resDocs, sort); err != nil {
-		return nil, err
-	}
-	if resDocs, err = common.LimitDocuments(resDocs, limit); err != nil {
-		return nil, err
+		stats.DocsExamined = int64(len(fetchedDocs))
+
+		iter = cursor.NewSliceIterator(resDocs)
 	}
-	if err = common.ProjectDocuments(resDocs, projection); err != nil {
+
+	c := cursor.New(fp.db, fp.collection, iter)
+	c.NoTimeout = noCursorTimeout
+
+	firstBatch, id, err := common.FirstBatch(ctx, h.cursors, c, batchSize, singleBatch)
+	if err != nil {
 		return nil, err
 	}
 
-	firstBatch := types.MakeArray(len(resDocs))
-	for _, doc := range resDocs {
-		if err = firstBatch.Append(doc); err != nil {
-			return nil, err
-		}
-	}
+	stats.DocsReturned = int64(firstBatch.Len())
 
 	var reply wire.OpMsg
 	must.NoError(reply.SetSections(wire.OpMsgSection{
 		Documents: []*types.Document{must.NotFail(types.NewDocument(
 			"cursor", must.NotFail(types.NewDocument(
 				"firstBatch", firstBatch,
-				"id", int64(0), // TODO
-				"ns", fp.db+"."+fp.collection,
+				"id", id,
+				"ns", c.NS(),
 			)),
 			"ok", float64(1),
 		))},
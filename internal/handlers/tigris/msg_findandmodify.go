@@ -16,13 +16,109 @@ package tigris
 
 import (
 	"context"
+	"time"
 
-	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/wire"
 )
 
 // MsgFindAndModify implements HandlerInterface.
+//
+// Tigris transactions are not used there, so the document is found and then modified in separate steps.
+// The modified document is replaced or deleted by _id, so concurrent calls that modify different documents
+// do not interfere; concurrent changes of the same document are not detected, and the last write wins.
 func (h *Handler) MsgFindAndModify(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg, error) {
-	// TODO https://github.com/FerretDB/FerretDB/issues/775
-	return nil, notImplemented(must.NotFail(msg.Document()).Command())
+	document, err := msg.Document()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	params, err := common.GetFindAndModifyParams(document, h.L)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.MaxTimeMS != 0 {
+		ctxWithTimeout, cancel := context.WithTimeout(ctx, time.Duration(params.MaxTimeMS)*time.Millisecond)
+		defer cancel()
+
+		ctx = ctxWithTimeout
+	}
+
+	fp := fetchParam{
+		db:         params.DB,
+		collection: params.Collection,
+		filter:     params.Query,
+	}
+
+	found, err := h.findAndModifyQuery(ctx, fp, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var modified *types.Document
+	var upserted bool
+
+	switch {
+	case params.Update != nil:
+		if found == nil && !params.Upsert {
+			break
+		}
+
+		if modified, err = common.FindAndModifyDocument(params, found); err != nil {
+			return nil, err
+		}
+
+		if found == nil {
+			upserted = true
+			if err = h.insert(ctx, fp, modified); err != nil {
+				return nil, err
+			}
+
+			break
+		}
+
+		if _, err = h.update(ctx, fp, modified); err != nil {
+			return nil, err
+		}
+
+	case params.Remove:
+		if found == nil {
+			break
+		}
+
+		if _, err = h.delete(ctx, fp, []*types.Document{found}); err != nil {
+			return nil, err
+		}
+	}
+
+	return common.FindAndModifyReply(params, found, modified, upserted)
+}
+
+// findAndModifyQuery returns the first document matching findAndModify query in the requested sort order,
+// or nil if there are no such documents.
+func (h *Handler) findAndModifyQuery(ctx context.Context, fp fetchParam, params *common.FindAndModifyParams) (*types.Document, error) { //nolint:lll // argument list is too long
+	fetchedDocs, err := h.fetch(ctx, fp)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = common.SortDocuments(fetchedDocs, params.Sort); err != nil {
+		return nil, err
+	}
+
+	for _, doc := range fetchedDocs {
+		matches, err := common.FilterDocument(doc, params.Query)
+		if err != nil {
+			return nil, err
+		}
+
+		if matches {
+			return doc, nil
+		}
+	}
+
+	return nil, nil //nolint:nilnil // nil is a valid value
 }
@@ -38,10 +38,16 @@ func (h *Handler) MsgGetParameter(ctx context.Context, msg *wire.OpMsg) (*wire.O
 		return nil, lazyerrors.Error(err)
 	}
 
+	slowMS, sampleRate := common.SlowOpsParameters(ctx)
+
 	resDB := must.NotFail(types.NewDocument(
 		"acceptApiVersion2", false,
 		"authSchemaVersion", int32(5),
 		"quiet", false,
+		"slowms", slowMS,
+		"sampleRate", sampleRate,
+		"readOnly", common.ReadOnly(ctx),
+		"cursorTimeoutMillis", h.cursors.Timeout().Milliseconds(),
 		"ok", float64(1),
 	))
 
@@ -65,6 +65,10 @@ func (h *Handler) MsgInsert(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 			return nil, lazyerrors.Error(err)
 		}
 
+		if err = common.ValidateInsertDocument(doc.(*types.Document)); err != nil {
+			return nil, err
+		}
+
 		err = h.insert(ctx, fp, doc.(*types.Document))
 		if err != nil {
 			return nil, lazyerrors.Error(err)
@@ -94,12 +98,18 @@ func (h *Handler) insert(ctx context.Context, fp fetchParam, doc *types.Document
 	b := must.NotFail(schema.Marshal())
 	h.L.Sugar().Debugf("Schema:\n%s", b)
 
-	_, err = h.db.CreateCollectionIfNotExist(ctx, fp.db, fp.collection, b)
+	created, err := h.db.CreateCollectionIfNotExist(ctx, fp.db, fp.collection, b)
 	if err != nil {
 		return lazyerrors.Error(err)
 	}
 
-	b, err = tjson.Marshal(doc)
+	if !created {
+		if schema, err = h.updateSchema(ctx, fp, schema); err != nil {
+			return lazyerrors.Error(err)
+		}
+	}
+
+	b, err = tjson.MarshalWithSchema(doc, schema)
 	if err != nil {
 		return lazyerrors.Error(err)
 	}
@@ -111,3 +121,35 @@ func (h *Handler) insert(ctx context.Context, fp fetchParam, doc *types.Document
 	}
 	return nil
 }
+
+// updateSchema adds fields of the given document schema that are missing in the existing collection schema,
+// so documents with previously unseen fields could be written.
+// For fields with conflicting types, properties for mixed values are added.
+//
+// It returns the resulting collection schema that should be used to marshal the document.
+func (h *Handler) updateSchema(ctx context.Context, fp fetchParam, docSchema *tjson.Schema) (*tjson.Schema, error) {
+	db := h.db.Driver.UseDatabase(fp.db)
+
+	collection, err := db.DescribeCollection(ctx, fp.collection)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	var schema tjson.Schema
+	if err = schema.Unmarshal(collection.Schema); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if !schema.Merge(docSchema) {
+		return &schema, nil
+	}
+
+	b := must.NotFail(schema.Marshal())
+	h.L.Sugar().Debugf("Updated schema:\n%s", b)
+
+	if err = db.CreateOrUpdateCollection(ctx, fp.collection, b); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return &schema, nil
+}
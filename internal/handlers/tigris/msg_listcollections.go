@@ -31,42 +31,19 @@ func (h *Handler) MsgListCollections(ctx context.Context, msg *wire.OpMsg) (*wir
 		return nil, lazyerrors.Error(err)
 	}
 
-	if err = common.UnimplementedNonDefault(document, "filter", func(v any) bool {
-		d, ok := v.(*types.Document)
-		return ok && d.Len() == 0
-	}); err != nil {
-		return nil, err
-	}
-
-	// TODO https://github.com/FerretDB/FerretDB/issues/301
-	// if err = common.UnimplementedNonDefault(document, "nameOnly", func(v any) bool {
-	// 	nameOnly, ok := v.(bool)
-	// 	return ok && !nameOnly
-	// }); err != nil {
-	// 	return nil, err
-	// }
-
-	common.Ignored(document, h.L, "comment", "authorizedCollections")
-
-	var db string
-	if db, err = common.GetRequiredParam[string](document, "$db"); err != nil {
+	params, err := common.GetListCollectionsParams(document, h.L)
+	if err != nil {
 		return nil, err
 	}
 
-	names, err := h.db.Driver.UseDatabase(db).ListCollections(ctx)
+	names, err := h.db.Driver.UseDatabase(params.DB).ListCollections(ctx)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
 
-	collections := types.MakeArray(len(names))
-	for _, n := range names {
-		d := must.NotFail(types.NewDocument(
-			"name", n,
-			"type", "collection",
-		))
-		if err = collections.Append(d); err != nil {
-			return nil, lazyerrors.Error(err)
-		}
+	collections, err := common.ListCollectionsInfos(params, names)
+	if err != nil {
+		return nil, err
 	}
 
 	var reply wire.OpMsg
@@ -74,7 +51,7 @@ func (h *Handler) MsgListCollections(ctx context.Context, msg *wire.OpMsg) (*wir
 		Documents: []*types.Document{must.NotFail(types.NewDocument(
 			"cursor", must.NotFail(types.NewDocument(
 				"id", int64(0),
-				"ns", db+".$cmd.listCollections",
+				"ns", params.DB+".$cmd.listCollections",
 				"firstBatch", collections,
 			)),
 			"ok", float64(1),
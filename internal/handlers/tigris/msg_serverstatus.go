@@ -20,6 +20,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/FerretDB/FerretDB/internal/handlers/common"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
@@ -69,9 +70,18 @@ func (h *Handler) MsgServerStatus(ctx context.Context, msg *wire.OpMsg) (*wire.O
 				"internalCollections", int32(0),
 				"internalViews", int32(0),
 			)),
+			"connections", common.Connections(ctx),
+			"drivers", common.Drivers(ctx),
+			"opcounters", common.Opcounters(ctx),
 			"freeMonitoring", must.NotFail(types.NewDocument(
 				"state", "disabled",
 			)),
+			"metrics", must.NotFail(types.NewDocument(
+				"cursor", common.CursorMetrics(h.cursors),
+			)),
+			"ferretdb", must.NotFail(types.NewDocument(
+				"build", common.FerretDBBuild(),
+			)),
 			"ok", float64(1),
 		))},
 	}))
@@ -81,8 +81,6 @@ func (h *Handler) MsgUpdate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 		}
 
 		var q, u *types.Document
-		var upsert bool
-		var multi bool
 		if q, err = common.GetOptionalParam(update, "q", q); err != nil {
 			return nil, err
 		}
@@ -96,11 +94,13 @@ func (h *Handler) MsgUpdate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 			}
 		}
 
-		if upsert, err = common.GetOptionalParam(update, "upsert", upsert); err != nil {
+		upsert, err := common.GetBoolOptionalParam(update, "upsert")
+		if err != nil {
 			return nil, err
 		}
 
-		if multi, err = common.GetOptionalParam(update, "multi", multi); err != nil {
+		multi, err := common.GetBoolOptionalParam(update, "multi")
+		if err != nil {
 			return nil, err
 		}
 
@@ -138,7 +138,7 @@ func (h *Handler) MsgUpdate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 			}
 
 			must.NoError(upserted.Append(must.NotFail(types.NewDocument(
-				"index", int32(0), // TODO
+				"index", int32(i),
 				"_id", must.NotFail(doc.Get("_id")),
 			))))
 
@@ -195,8 +195,19 @@ func (h *Handler) MsgUpdate(ctx context.Context, msg *wire.OpMsg) (*wire.OpMsg,
 }
 
 // update replaces given document.
+//
+// If the document has fields that are missing in the collection schema, the schema is updated first.
 func (h *Handler) update(ctx context.Context, sp fetchParam, doc *types.Document) (int, error) {
-	u, err := tjson.Marshal(doc)
+	schema, err := tjson.DocumentSchema(doc)
+	if err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	if schema, err = h.updateSchema(ctx, sp, schema); err != nil {
+		return 0, lazyerrors.Error(err)
+	}
+
+	u, err := tjson.MarshalWithSchema(doc, schema)
 	if err != nil {
 		return 0, lazyerrors.Error(err)
 	}
@@ -21,9 +21,11 @@ import (
 	"github.com/tigrisdata/tigris-client-go/config"
 	"go.uber.org/zap"
 
+	"github.com/FerretDB/FerretDB/internal/cursor"
 	"github.com/FerretDB/FerretDB/internal/handlers"
 	"github.com/FerretDB/FerretDB/internal/handlers/common"
 	"github.com/FerretDB/FerretDB/internal/handlers/tigris/tigrisdb"
+	"github.com/FerretDB/FerretDB/internal/util/debug"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 )
 
@@ -42,6 +44,7 @@ type NewOpts struct {
 type Handler struct {
 	*NewOpts
 	db        *tigrisdb.TigrisDB
+	cursors   *cursor.Registry
 	startTime time.Time
 }
 
@@ -58,17 +61,32 @@ func New(opts *NewOpts) (handlers.Interface, error) {
 	h := &Handler{
 		NewOpts:   opts,
 		db:        db,
+		cursors:   cursor.NewRegistry(),
 		startTime: time.Now(),
 	}
 	return h, nil
 }
 
+// handlerState represents the handler state reported on the debug endpoint.
+type handlerState struct {
+	Cursors []common.CursorState `json:"cursors"`
+}
+
+// DebugState implements debug.StateProvider.
+func (h *Handler) DebugState() any {
+	return &handlerState{
+		Cursors: common.CursorsState(h.cursors),
+	}
+}
+
 // Close implements handlers.Interface.
 func (h *Handler) Close() {
+	h.cursors.Close()
 	h.db.Driver.Close()
 }
 
 // check interfaces
 var (
-	_ handlers.Interface = (*Handler)(nil)
+	_ handlers.Interface  = (*Handler)(nil)
+	_ debug.StateProvider = (*Handler)(nil)
 )
@@ -17,6 +17,9 @@ package tjson
 import (
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
 	"github.com/FerretDB/FerretDB/internal/types"
 )
 
@@ -37,6 +40,14 @@ var binaryTestCases = []testCase{{
 	schema: binarySchema,
 	j:      `{"$b":""}`,
 	canonJ: `{"$b":"","s":0}`,
+}, {
+	name: "uuid",
+	v: &binaryType{
+		Subtype: types.BinaryUUID,
+		B:       []byte{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0, 0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0},
+	},
+	schema: binarySchema,
+	j:      `{"$b":"EjRWeJq83vASNFZ4mrze8A==","s":4}`,
 }, {
 	name: "invalid subtype",
 	v: &binaryType{
@@ -67,6 +78,37 @@ func TestBinary(t *testing.T) {
 	testJSON(t, binaryTestCases, func() tjsontype { return new(binaryType) })
 }
 
+func TestBinarySubtypes(t *testing.T) {
+	t.Parallel()
+
+	subtypes := []types.BinarySubtype{
+		types.BinaryGeneric, types.BinaryFunction, types.BinaryGenericOld, types.BinaryUUIDOld,
+		types.BinaryUUID, types.BinaryMD5, types.BinaryEncrypted, types.BinaryColumn, types.BinaryUser,
+	}
+	payloads := [][]byte{
+		nil,
+		{},
+		{0x00},
+		{0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0, 0x12, 0x34, 0x56, 0x78, 0x9a, 0xbc, 0xde, 0xf0},
+	}
+
+	for _, subtype := range subtypes {
+		for _, payload := range payloads {
+			b, err := Marshal(types.Binary{Subtype: subtype, B: payload})
+			require.NoError(t, err)
+
+			actual, err := Unmarshal(b, binarySchema)
+			require.NoError(t, err, "%s", b)
+
+			expected := types.Binary{Subtype: subtype, B: payload}
+			if expected.B == nil {
+				expected.B = []byte{}
+			}
+			assert.Equal(t, expected, actual, "%s", b)
+		}
+	}
+}
+
 func FuzzBinary(f *testing.F) {
 	fuzzJSON(f, binaryTestCases)
 }
@@ -19,6 +19,9 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/AlekSi/pointer"
+	"golang.org/x/exp/slices"
+
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
@@ -69,15 +72,22 @@ func (doc *documentType) UnmarshalJSONWithSchema(data []byte, schema *Schema) er
 
 	td := must.NotFail(types.NewDocument())
 	for _, key := range keys {
-		b, ok = rawMessages[key]
-		if !ok {
-			return lazyerrors.Errorf("tjson.documentType.UnmarshalJSONWithSchema: missing key %q", key)
-		}
-		s := schema.Properties[key]
-		if s == nil {
-			return lazyerrors.Errorf("tjson.documentType.UnmarshalJSONWithSchema: no schema for key %q", key)
+		var v any
+		var err error
+
+		if b, ok = rawMessages[key]; ok {
+			s := schema.Properties[key]
+			if s == nil {
+				return lazyerrors.Errorf("tjson.documentType.UnmarshalJSONWithSchema: no schema for key %q", key)
+			}
+			v, err = Unmarshal(b, s)
+		} else {
+			if b, ok = rawMessages[mixedKey(key)]; !ok {
+				return lazyerrors.Errorf("tjson.documentType.UnmarshalJSONWithSchema: missing key %q", key)
+			}
+			v, err = unmarshalMixed(b)
 		}
-		v, err := Unmarshal(b, s)
+
 		if err != nil {
 			return lazyerrors.Error(err)
 		}
@@ -92,6 +102,14 @@ func (doc *documentType) UnmarshalJSONWithSchema(data []byte, schema *Schema) er
 
 // MarshalJSON implements tjsontype interface.
 func (doc *documentType) MarshalJSON() ([]byte, error) {
+	return doc.MarshalJSONWithSchema(nil)
+}
+
+// MarshalJSONWithSchema marshals the document with given JSON Schema.
+//
+// Values that don't fit the schema of their properties are marshaled as mixed values.
+// If schema is nil, or it does not have a property yet, only unsupported values are mixed.
+func (doc *documentType) MarshalJSONWithSchema(schema *Schema) ([]byte, error) {
 	td := types.Document(*doc)
 
 	var buf bytes.Buffer
@@ -108,19 +126,47 @@ func (doc *documentType) MarshalJSON() ([]byte, error) {
 	buf.Write(b)
 
 	for _, key := range keys {
+		value, err := td.Get(key)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		var s *Schema
+		if schema != nil {
+			s = schema.Properties[key]
+		}
+
+		mixed := s != nil && !fitsSchema(value, s)
+		if s == nil {
+			_, isDoc := value.(*types.Document)
+			_, err = valueSchema(value)
+			mixed = !isDoc && err != nil
+		}
+
+		if mixed && schema != nil && slices.Contains(schema.PrimaryKey, key) {
+			return nil, lazyerrors.Errorf("tjson.documentType.MarshalJSONWithSchema: %T can't be used for %q", value, key)
+		}
+
 		buf.WriteByte(',')
 
-		if b, err = json.Marshal(key); err != nil {
+		name := key
+		if mixed {
+			name = mixedKey(key)
+		}
+		if b, err = json.Marshal(name); err != nil {
 			return nil, lazyerrors.Error(err)
 		}
 		buf.Write(b)
 		buf.WriteByte(':')
 
-		value, err := td.Get(key)
-		if err != nil {
-			return nil, lazyerrors.Error(err)
+		switch {
+		case mixed:
+			b, err = marshalMixed(value)
+		case s != nil && isDocumentSchema(s):
+			b, err = pointer.To(documentType(*value.(*types.Document))).MarshalJSONWithSchema(s)
+		default:
+			b, err = Marshal(value)
 		}
-		b, err := Marshal(value)
 		if err != nil {
 			return nil, lazyerrors.Error(err)
 		}
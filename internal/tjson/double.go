@@ -17,6 +17,7 @@ package tjson
 import (
 	"bytes"
 	"encoding/json"
+	"math"
 
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 )
@@ -49,8 +50,16 @@ func (d *doubleType) UnmarshalJSON(data []byte) error {
 }
 
 // MarshalJSON implements tjsontype interface.
+//
+// NaN and ±Infinity are not supported because JSON has no literals for them,
+// and Tigris schema requires double values to be JSON numbers.
 func (d *doubleType) MarshalJSON() ([]byte, error) {
-	res, err := json.Marshal(float64(*d))
+	f := float64(*d)
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, lazyerrors.Errorf("tjson.Double.MarshalJSON: %v is not supported", f)
+	}
+
+	res, err := json.Marshal(f)
 	if err != nil {
 		return nil, lazyerrors.Error(err)
 	}
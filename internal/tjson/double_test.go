@@ -19,6 +19,7 @@ import (
 	"testing"
 
 	"github.com/AlekSi/pointer"
+	"github.com/stretchr/testify/assert"
 )
 
 var doubleTestCases = []testCase{{
@@ -58,6 +59,15 @@ func TestDouble(t *testing.T) {
 	testJSON(t, doubleTestCases, func() tjsontype { return new(doubleType) })
 }
 
+func TestDoubleNotSupported(t *testing.T) {
+	t.Parallel()
+
+	for _, f := range []float64{math.NaN(), math.Inf(+1), math.Inf(-1)} {
+		_, err := pointer.To(doubleType(f)).MarshalJSON()
+		assert.ErrorContains(t, err, "is not supported")
+	}
+}
+
 func FuzzDouble(f *testing.F) {
 	fuzzJSON(f, doubleTestCases)
 }
@@ -0,0 +1,92 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tjson
+
+import (
+	"encoding/json"
+
+	"github.com/FerretDB/FerretDB/internal/fjson"
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// Values that can't be represented by the property schema ("mixed" values) are stored
+// under the separate property with mixedKey name as the JSON string with fjson-encoded value.
+// fjson is self-describing, so original BSON types are restored without a schema.
+//
+// That happens when the value type is not supported by tjson yet (for example, null),
+// or when it conflicts with the type of the same field in other documents of the collection.
+// The first seen type of the field keeps using the normal representation,
+// so the schema of existing fields is never changed in an incompatible way.
+
+// mixedSchema is a schema of a mixed value property.
+var mixedSchema = stringSchema
+
+// mixedKey returns the name of the property used for a mixed value of the given key.
+//
+// Document keys can't start with $, so it does not clash with other keys.
+func mixedKey(key string) string {
+	return "$m" + key
+}
+
+// marshalMixed encodes the given value as a mixed value.
+func marshalMixed(v any) ([]byte, error) {
+	b, err := fjson.Marshal(v)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if b, err = json.Marshal(string(b)); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return b, nil
+}
+
+// unmarshalMixed decodes the given mixed value.
+func unmarshalMixed(data []byte) (any, error) {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	v, err := fjson.Unmarshal([]byte(s))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return v, nil
+}
+
+// isDocumentSchema returns true if the given schema describes a document,
+// not other types represented as JSON objects (binary, regex, etc).
+func isDocumentSchema(s *Schema) bool {
+	return s.Type == Object && s.Properties["$k"] != nil
+}
+
+// fitsSchema returns true if the given value could be stored with the given property schema.
+// Documents fit any document schema; their fields are checked separately.
+func fitsSchema(v any, s *Schema) bool {
+	if _, ok := v.(*types.Document); ok {
+		return isDocumentSchema(s)
+	}
+
+	vs, err := valueSchema(v)
+	if err != nil {
+		return false
+	}
+
+	return !isDocumentSchema(s) && vs.Equal(s)
+}
@@ -18,8 +18,11 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
+	"golang.org/x/exp/slices"
+
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
 	"github.com/FerretDB/FerretDB/internal/util/must"
@@ -188,6 +191,46 @@ func (s *Schema) Equal(other *Schema) bool {
 	return formatS == formatOther
 }
 
+// Merge adds to the document schema properties of the other document schema that are missing in it,
+// recursing into properties that are documents in both schemas.
+// Properties present in both schemas with different types are left unchanged;
+// instead, a property for mixed values is added, so values of the other type could be stored too.
+// Primary key properties never get mixed values.
+// It returns true if the schema was modified.
+func (s *Schema) Merge(other *Schema) bool {
+	if !isDocumentSchema(s) || !isDocumentSchema(other) {
+		return false
+	}
+
+	var changed bool
+	for k, v := range other.Properties {
+		p, ok := s.Properties[k]
+		if !ok {
+			s.Properties[k] = v
+			changed = true
+			continue
+		}
+
+		if isDocumentSchema(p) && isDocumentSchema(v) {
+			if p.Merge(v) {
+				changed = true
+			}
+			continue
+		}
+
+		if p.Equal(v) || strings.HasPrefix(k, "$") || slices.Contains(s.PrimaryKey, k) {
+			continue
+		}
+
+		if _, ok = s.Properties[mixedKey(k)]; !ok {
+			s.Properties[mixedKey(k)] = mixedSchema
+			changed = true
+		}
+	}
+
+	return changed
+}
+
 // Marshal returns the JSON encoding of the schema.
 func (s *Schema) Marshal() ([]byte, error) {
 	b, err := json.Marshal(s)
@@ -241,7 +284,12 @@ func subdocumentSchema(doc *types.Document, pkey ...string) (*Schema, error) {
 
 		s, err := valueSchema(v)
 		if err != nil {
-			return nil, lazyerrors.Error(err)
+			if slices.Contains(pkey, k) {
+				return nil, lazyerrors.Error(err)
+			}
+
+			schema.Properties[mixedKey(k)] = mixedSchema
+			continue
 		}
 		schema.Properties[k] = s
 	}
@@ -279,6 +327,8 @@ func valueSchema(v any) (*Schema, error) {
 		return nil, lazyerrors.Errorf("%T is not supported yet", v)
 	case int64:
 		return int64Schema, nil
+	case types.Decimal128:
+		return nil, lazyerrors.Errorf("%T is not supported yet", v)
 	default:
 		panic(fmt.Sprintf("not reached: %T", v))
 	}
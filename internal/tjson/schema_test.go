@@ -20,6 +20,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/util/testutil"
 )
 
@@ -233,3 +235,130 @@ func TestSchemaEqual(t *testing.T) {
 		})
 	}
 }
+
+func TestSchemaMerge(t *testing.T) {
+	t.Parallel()
+
+	keys := &Schema{Type: Array, Items: stringSchema}
+
+	s := &Schema{
+		Type: Object,
+		Properties: map[string]*Schema{
+			"$k":  keys,
+			"_id": stringSchema,
+			"a":   stringSchema,
+			"b": {
+				Type: Object,
+				Properties: map[string]*Schema{
+					"$k": keys,
+					"c":  int32Schema,
+				},
+			},
+			"f": binarySchema,
+		},
+		PrimaryKey: []string{"_id"},
+	}
+
+	other := &Schema{
+		Type: Object,
+		Properties: map[string]*Schema{
+			"$k":  keys,
+			"_id": int32Schema,
+			"a":   boolSchema,
+			"b": {
+				Type: Object,
+				Properties: map[string]*Schema{
+					"$k": keys,
+					"c":  int32Schema,
+					"d":  doubleSchema,
+				},
+			},
+			"e": stringSchema,
+			"f": regexSchema,
+		},
+		PrimaryKey: []string{"_id"},
+	}
+
+	expected := &Schema{
+		Type: Object,
+		Properties: map[string]*Schema{
+			"$k":  keys,
+			"_id": stringSchema,
+			"a":   stringSchema,
+			"$ma": mixedSchema,
+			"b": {
+				Type: Object,
+				Properties: map[string]*Schema{
+					"$k": keys,
+					"c":  int32Schema,
+					"d":  doubleSchema,
+				},
+			},
+			"e":   stringSchema,
+			"f":   binarySchema,
+			"$mf": mixedSchema,
+		},
+		PrimaryKey: []string{"_id"},
+	}
+
+	assert.True(t, s.Merge(other))
+	assert.Equal(t, expected, s)
+
+	assert.False(t, s.Merge(other))
+	assert.Equal(t, expected, s)
+
+	assert.False(t, stringSchema.Merge(other))
+	assert.False(t, binarySchema.Merge(regexSchema))
+}
+
+func TestSchemaMixed(t *testing.T) {
+	t.Parallel()
+
+	docs := []*types.Document{
+		must.NotFail(types.NewDocument("_id", "int32", "v", int32(42))),
+		must.NotFail(types.NewDocument("_id", "string", "v", "foo")),
+		must.NotFail(types.NewDocument("_id", "null", "v", types.Null)),
+		must.NotFail(types.NewDocument("_id", "document", "v", must.NotFail(types.NewDocument("foo", int64(1))))),
+		must.NotFail(types.NewDocument("_id", "nested", "v", must.NotFail(types.NewDocument("foo", "bar")))),
+		must.NotFail(types.NewDocument("_id", "int64", "v", int64(42))),
+	}
+
+	schema := must.NotFail(DocumentSchema(docs[0]))
+	for _, doc := range docs[1:] {
+		schema.Merge(must.NotFail(DocumentSchema(doc)))
+	}
+
+	expectedSchema := &Schema{
+		Type: Object,
+		Properties: map[string]*Schema{
+			"$k":  {Type: Array, Items: stringSchema},
+			"_id": stringSchema,
+			"v":   int32Schema,
+			"$mv": mixedSchema,
+		},
+		PrimaryKey: []string{"_id"},
+	}
+	assert.Equal(t, expectedSchema, schema)
+
+	expected := []string{
+		`{"$k":["_id","v"],"_id":"int32","v":42}`,
+		`{"$k":["_id","v"],"_id":"string","$mv":"\"foo\""}`,
+		`{"$k":["_id","v"],"_id":"null","$mv":"null"}`,
+		`{"$k":["_id","v"],"_id":"document","$mv":"{\"$k\":[\"foo\"],\"foo\":{\"$l\":\"1\"}}"}`,
+		`{"$k":["_id","v"],"_id":"nested","$mv":"{\"$k\":[\"foo\"],\"foo\":\"bar\"}"}`,
+		`{"$k":["_id","v"],"_id":"int64","$mv":"{\"$l\":\"42\"}"}`,
+	}
+
+	for i, doc := range docs {
+		b, err := MarshalWithSchema(doc, schema)
+		require.NoError(t, err)
+		assert.Equal(t, expected[i], string(b))
+
+		actual, err := Unmarshal(b, schema)
+		require.NoError(t, err)
+		assert.Equal(t, doc, actual)
+	}
+
+	_, err := MarshalWithSchema(must.NotFail(types.NewDocument("_id", int32(1))), schema)
+	require.Error(t, err)
+}
@@ -22,7 +22,7 @@
 // Composite types
 //
 //	*types.Document       {"$k": ["<key 1>", "<key 2>", ...], "<key 1>": <value 1>, "<key 2>": <value 2>, ...}
-//	TODO *types.Array     JSON array
+//	TODO *types.Array     JSON array (mixed value for now)
 //
 // Scalar types
 //
@@ -32,12 +32,19 @@
 //	types.ObjectID	      JSON string (byte format, length is 12 bytes)
 //	bool                  JSON true|false values
 //	time.Time        	  JSON string (date-time RFC3339 format)
-//	TODO types.NullType   JSON null
+//	types.NullType        mixed value (see below)
 //	types.Regex           {"$r": "<string without terminating 0x0>", "o": "<string without terminating 0x0>"}
 //	int32                 JSON number (int32 format)
-//	TODO types.Timestamp  {"$t": "<number as string>"}
+//	TODO types.Timestamp  {"$t": "<number as string>"} (mixed value for now)
 //	int64                 JSON number (int64 format)
 //	TODO Decimal128       {"$n": "<number as string>"}
+//
+// # Mixed values
+//
+// Values of unsupported types, and values with types that conflict with the collection schema,
+// are stored as fjson-encoded JSON strings under the "$m<key>" property instead of "<key>":
+//
+//	{"$k": ["<key>"], "$m<key>": "<fjson-encoded value>"}
 package tjson
 
 import (
@@ -260,3 +267,15 @@ func Marshal(v any) ([]byte, error) {
 
 	return b, nil
 }
+
+// MarshalWithSchema encodes given top-level document into tjson with given collection schema.
+//
+// Values that don't fit the schema are encoded as mixed values; see DocumentSchema and Schema.Merge.
+func MarshalWithSchema(doc *types.Document, schema *Schema) ([]byte, error) {
+	b, err := pointer.To(documentType(*doc)).MarshalJSONWithSchema(schema)
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return b, nil
+}
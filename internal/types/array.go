@@ -16,12 +16,16 @@ package types
 
 import (
 	"fmt"
+	"sort"
 
 	"golang.org/x/exp/slices"
 
 	"github.com/FerretDB/FerretDB/internal/util/must"
 )
 
+// ErrIndexOutOfBounds is returned (wrapped) by Array methods for indexes that are out of bounds.
+var ErrIndexOutOfBounds = fmt.Errorf("index is out of bounds")
+
 // Array represents BSON array.
 //
 // Zero value is a valid empty array.
@@ -127,7 +131,7 @@ func (a *Array) Min() any {
 	min := must.NotFail(a.Get(0))
 	for i := 1; i < a.Len(); i++ {
 		value := must.NotFail(a.Get(i))
-		if CompareOrder(min, value, Ascending) == Greater {
+		if CompareOrder(min, value) == Greater {
 			min = value
 		}
 	}
@@ -144,7 +148,7 @@ func (a *Array) Max() any {
 	max := must.NotFail(a.Get(0))
 	for i := 1; i < a.Len(); i++ {
 		value := must.NotFail(a.Get(i))
-		if CompareOrder(max, value, Ascending) == Less {
+		if CompareOrder(max, value) == Less {
 			max = value
 		}
 	}
@@ -153,6 +157,10 @@ func (a *Array) Max() any {
 }
 
 // Contains checks if the Array contains the given value.
+//
+// It uses filter semantics: composite value matches the whole array or any element,
+// scalar value matches only scalar elements.
+// Use IndexOf for exact BSON equality.
 func (a *Array) Contains(filterValue any) bool {
 	switch filterValue := filterValue.(type) {
 	case *Document, *Array:
@@ -199,11 +207,92 @@ func (a *Array) ContainsAll(b *Array) bool {
 	return true
 }
 
-// Remove removes the value at the given index.
-func (a *Array) Remove(index int) {
+// InsertAt inserts the value at the given index, shifting following elements.
+//
+// Index should be in [0, Len()] range; Len() appends the value.
+// Otherwise, error wrapping ErrIndexOutOfBounds is returned.
+func (a *Array) InsertAt(index int, value any) error {
+	if l := a.Len(); index < 0 || index > l {
+		return fmt.Errorf("types.Array.InsertAt: %w: %d is not in [0-%d]", ErrIndexOutOfBounds, index, l)
+	}
+
+	if err := validateValue(value); err != nil {
+		return fmt.Errorf("types.Array.InsertAt: %w", err)
+	}
+
+	// unlike slices.Insert, append grows the backing array with amortized reallocations
+	a.s = append(a.s, nil)
+	copy(a.s[index+1:], a.s[index:])
+	a.s[index] = value
+
+	return nil
+}
+
+// RemoveAt removes the value at the given index, shifting following elements.
+//
+// Index should be in [0, Len()) range.
+// Otherwise, error wrapping ErrIndexOutOfBounds is returned.
+func (a *Array) RemoveAt(index int) error {
 	if l := a.Len(); index < 0 || index >= l {
-		panic("types.Array.Remove: index is out of bounds")
+		return fmt.Errorf("types.Array.RemoveAt: %w: %d is not in [0-%d)", ErrIndexOutOfBounds, index, l)
+	}
+
+	a.s = slices.Delete(a.s, index, index+1)
+
+	// do not keep a reference to the removed value in the backing array
+	a.s[:len(a.s)+1][len(a.s)] = nil
+
+	return nil
+}
+
+// IndexOf returns the index of the first element equal to the given value, or -1.
+//
+// Equality is defined by CompareOrder, so, for example, int32(1) and 1.0 are equal,
+// and documents are equal only if they have the same fields in the same order.
+func (a *Array) IndexOf(value any) int {
+	for i, elem := range a.s {
+		if CompareOrder(elem, value) == Equal {
+			return i
+		}
 	}
 
-	a.s = append(a.s[:index], a.s[index+1:]...)
+	return -1
+}
+
+// SortInPlace sorts array elements using the given comparator, for example, CompareOrder.
+//
+// Sort is stable: equal elements keep their original order.
+func (a *Array) SortInPlace(cmp func(a, b any) CompareResult) {
+	sort.SliceStable(a.s, func(i, j int) bool {
+		return cmp(a.s[i], a.s[j]) == Less
+	})
+}
+
+// Iterator returns an iterator over array elements.
+//
+// Modifying the array during iteration is not supported.
+func (a *Array) Iterator() ArrayIterator {
+	return ArrayIterator{a: a}
+}
+
+// ArrayIterator iterates over array elements without allocations.
+//
+// Zero value is a valid iterator without elements.
+type ArrayIterator struct {
+	a *Array
+	n int
+}
+
+// Next returns the next element index and value.
+//
+// It returns false when there are no more elements.
+func (iter *ArrayIterator) Next() (int, any, bool) {
+	if iter.n >= iter.a.Len() {
+		return 0, nil, false
+	}
+
+	n := iter.n
+	iter.n++
+
+	return n, iter.a.s[n], true
 }
@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/FerretDB/FerretDB/internal/util/must"
 )
@@ -111,6 +112,8 @@ func TestArrayMinMax(t *testing.T) {
 		max = "max"
 	)
 
+	// equal numbers of different types are equal, so the first one is returned
+
 	for name, tc := range map[string]struct {
 		arr           *Array
 		minOrMax      string
@@ -129,7 +132,7 @@ func TestArrayMinMax(t *testing.T) {
 		"IntMin": {
 			arr:           intArray,
 			minOrMax:      min,
-			expectedValue: int32(2),
+			expectedValue: int64(2),
 		},
 		"FloatMin": {
 			arr:           floatArray,
@@ -139,12 +142,12 @@ func TestArrayMinMax(t *testing.T) {
 		"NumericMin": {
 			arr:           numericArray,
 			minOrMax:      min,
-			expectedValue: 2.0,
+			expectedValue: int64(2),
 		},
 		"ZeroMin": {
 			arr:           zeroArray,
 			minOrMax:      min,
-			expectedValue: math.Copysign(0, -1),
+			expectedValue: int64(0),
 		},
 		"StringMin": {
 			arr:           stingArray,
@@ -210,3 +213,196 @@ func TestArrayMinMax(t *testing.T) {
 		})
 	}
 }
+
+func TestArrayInsertRemove(t *testing.T) {
+	t.Parallel()
+
+	t.Run("InsertAt", func(t *testing.T) {
+		t.Parallel()
+
+		a := must.NotFail(NewArray("b", "d"))
+		require.NoError(t, a.InsertAt(0, "a"))
+		require.NoError(t, a.InsertAt(2, "c"))
+		require.NoError(t, a.InsertAt(a.Len(), "e"))
+		assert.Equal(t, must.NotFail(NewArray("a", "b", "c", "d", "e")), a)
+
+		err := a.InsertAt(-1, "x")
+		assert.ErrorIs(t, err, ErrIndexOutOfBounds)
+		assert.EqualError(t, err, "types.Array.InsertAt: index is out of bounds: -1 is not in [0-5]")
+
+		err = a.InsertAt(6, "x")
+		assert.ErrorIs(t, err, ErrIndexOutOfBounds)
+
+		err = a.InsertAt(0, 42)
+		assert.EqualError(t, err, `types.Array.InsertAt: types.validateValue: unsupported type: int (42)`)
+
+		var empty Array
+		require.NoError(t, empty.InsertAt(0, "a"))
+		assert.Equal(t, must.NotFail(NewArray("a")), &empty)
+	})
+
+	t.Run("RemoveAt", func(t *testing.T) {
+		t.Parallel()
+
+		a := must.NotFail(NewArray("a", "b", "c", "d"))
+		require.NoError(t, a.RemoveAt(1))
+		require.NoError(t, a.RemoveAt(a.Len()-1))
+		require.NoError(t, a.RemoveAt(0))
+		assert.Equal(t, must.NotFail(NewArray("c")), a)
+
+		err := a.RemoveAt(-1)
+		assert.ErrorIs(t, err, ErrIndexOutOfBounds)
+		assert.EqualError(t, err, "types.Array.RemoveAt: index is out of bounds: -1 is not in [0-1)")
+
+		err = a.RemoveAt(1)
+		assert.ErrorIs(t, err, ErrIndexOutOfBounds)
+
+		require.NoError(t, a.RemoveAt(0))
+		assert.Equal(t, 0, a.Len())
+
+		err = a.RemoveAt(0)
+		assert.ErrorIs(t, err, ErrIndexOutOfBounds)
+	})
+
+	t.Run("RemoveAtSharedBacking", func(t *testing.T) {
+		t.Parallel()
+
+		a := must.NotFail(NewArray("a", "b", "c"))
+		backing := a.s
+		require.NoError(t, a.RemoveAt(0))
+
+		// removed slot is cleared
+		assert.Equal(t, []any{"b", "c", nil}, backing)
+	})
+}
+
+func TestArrayIndexOf(t *testing.T) {
+	t.Parallel()
+
+	a := must.NotFail(NewArray(
+		int32(1),
+		"foo",
+		must.NotFail(NewDocument("a", int32(1), "b", int32(2))),
+		must.NotFail(NewArray(int32(1), int32(2))),
+		"foo",
+	))
+
+	for name, tc := range map[string]struct {
+		value    any
+		expected int
+	}{
+		"Int32":         {value: int32(1), expected: 0},
+		"Double":        {value: 1.0, expected: 0},
+		"Long":          {value: int64(1), expected: 0},
+		"StringFirst":   {value: "foo", expected: 1},
+		"Document":      {value: must.NotFail(NewDocument("a", int32(1), "b", int32(2))), expected: 2},
+		"DocumentOrder": {value: must.NotFail(NewDocument("b", int32(2), "a", int32(1))), expected: -1},
+		"Array":         {value: must.NotFail(NewArray(int32(1), int32(2))), expected: 3},
+		"ArrayElement":  {value: int32(2), expected: -1},
+		"Missing":       {value: "bar", expected: -1},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, a.IndexOf(tc.value))
+		})
+	}
+}
+
+func TestArraySortInPlace(t *testing.T) {
+	t.Parallel()
+
+	a := must.NotFail(NewArray("b", int32(2), 1.0, "a", int64(1), Null))
+	a.SortInPlace(CompareOrder)
+
+	// stable: 1.0 and int64(1) are equal and keep their order
+	assert.Equal(t, must.NotFail(NewArray(Null, 1.0, int64(1), int32(2), "a", "b")), a)
+}
+
+func TestArrayIterator(t *testing.T) {
+	t.Parallel()
+
+	a := must.NotFail(NewArray("a", "b", "c"))
+
+	var indexes []int
+	var values []any
+
+	iter := a.Iterator()
+	for {
+		i, v, ok := iter.Next()
+		if !ok {
+			break
+		}
+
+		indexes = append(indexes, i)
+		values = append(values, v)
+	}
+
+	assert.Equal(t, []int{0, 1, 2}, indexes)
+	assert.Equal(t, []any{"a", "b", "c"}, values)
+
+	_, _, ok := iter.Next()
+	assert.False(t, ok)
+
+	var zero ArrayIterator
+	_, _, ok = zero.Next()
+	assert.False(t, ok)
+}
+
+// benchmarkArray returns an array of n int32 values.
+func benchmarkArray(n int) *Array {
+	a := MakeArray(n)
+	for i := 0; i < n; i++ {
+		must.NoError(a.Append(int32(i)))
+	}
+
+	return a
+}
+
+func BenchmarkArrayInsertAt(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		a := benchmarkArray(100)
+		for j := 0; j < 100; j++ {
+			must.NoError(a.InsertAt(j, int32(j)))
+		}
+	}
+}
+
+func BenchmarkArrayRemoveAt(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		a := benchmarkArray(100)
+		for a.Len() > 0 {
+			must.NoError(a.RemoveAt(0))
+		}
+	}
+}
+
+func BenchmarkArrayIterator(b *testing.B) {
+	a := benchmarkArray(1000)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		iter := a.Iterator()
+		for _, _, ok := iter.Next(); ok; _, _, ok = iter.Next() {
+		}
+	}
+}
+
+func BenchmarkArraySortInPlace(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		a := benchmarkArray(1000)
+		b.StartTimer()
+
+		a.SortInPlace(func(a, b any) CompareResult { return CompareOrder(b, a) })
+	}
+}
@@ -41,6 +41,9 @@ const (
 	// BinaryEncrypted represents a Encrypted BSON value.
 	BinaryEncrypted = BinarySubtype(0x06) // encrypted
 
+	// BinaryColumn represents a compressed BSON column.
+	BinaryColumn = BinarySubtype(0x07) // column
+
 	// BinaryUser represents a  User defined.
 	BinaryUser = BinarySubtype(0x80) // user
 )
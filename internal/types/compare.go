@@ -58,7 +58,9 @@ func Compare(docValue, filterValue any) []CompareResult {
 
 	switch docValue := docValue.(type) {
 	case *Document:
-		// TODO: implement document comparing
+		if filterDoc, ok := filterValue.(*Document); ok {
+			return []CompareResult{CompareOrder(docValue, filterDoc)}
+		}
 		return []CompareResult{Incomparable}
 
 	case *Array:
@@ -66,10 +68,19 @@ func Compare(docValue, filterValue any) []CompareResult {
 			return compareArrays(filterArr, docValue)
 		}
 
+		var docResults []CompareResult
 		for i := 0; i < docValue.Len(); i++ {
 			docValue := must.NotFail(docValue.Get(i))
-			switch docValue.(type) {
-			case *Document, *Array:
+			switch docValue := docValue.(type) {
+			case *Document:
+				// embedded documents may match the filter document in different ways
+				if filterDoc, ok := filterValue.(*Document); ok {
+					if res := CompareOrder(docValue, filterDoc); !ContainsCompareResult(docResults, res) {
+						docResults = append(docResults, res)
+					}
+				}
+				continue
+			case *Array:
 				continue
 			}
 
@@ -77,6 +88,11 @@ func Compare(docValue, filterValue any) []CompareResult {
 				return []CompareResult{res}
 			}
 		}
+
+		if len(docResults) > 0 {
+			return docResults
+		}
+
 		return []CompareResult{Incomparable}
 
 	default:
@@ -90,6 +106,12 @@ func compareScalars(v1, v2 any) CompareResult {
 		return Incomparable
 	}
 
+	_, d1 := v1.(Decimal128)
+	_, d2 := v2.(Decimal128)
+	if d1 || d2 {
+		return compareDecimals(v1, v2)
+	}
+
 	switch v1 := v1.(type) {
 	case float64:
 		switch v2 := v2.(type) {
@@ -164,9 +186,10 @@ func compareScalars(v1, v2 any) CompareResult {
 	case Regex:
 		v2, ok := v2.(Regex)
 		if ok {
-			v1 := must.NotFail(v1.Compile())
-			v2 := must.NotFail(v2.Compile())
-			return compareOrdered(v1.String(), v2.String())
+			if res := compareOrdered(v1.Pattern, v2.Pattern); res != Equal {
+				return res
+			}
+			return compareOrdered(v1.Options, v2.Options)
 		}
 		return Incomparable
 
@@ -212,7 +235,7 @@ func isScalar(v any) bool {
 	}
 
 	switch v.(type) {
-	case float64, string, Binary, ObjectID, bool, time.Time, NullType, Regex, int32, Timestamp, int64:
+	case float64, string, Binary, ObjectID, bool, time.Time, NullType, Regex, int32, Timestamp, int64, Decimal128:
 		return true
 	}
 
@@ -262,6 +285,69 @@ func compareNumbers(a float64, b int64) CompareResult {
 	return CompareResult(bigA.Cmp(bigB))
 }
 
+// exactNumber represents the exact value of BSON number.
+type exactNumber struct {
+	rat *big.Rat // nil for NaN and infinities
+	inf int      // 1 for positive infinity, -1 for negative infinity
+	nan bool
+}
+
+// newExactNumber returns the exact value of BSON number, or false if v is not a number.
+func newExactNumber(v any) (exactNumber, bool) {
+	switch v := v.(type) {
+	case float64:
+		switch {
+		case math.IsNaN(v):
+			return exactNumber{nan: true}, true
+		case math.IsInf(v, 1):
+			return exactNumber{inf: 1}, true
+		case math.IsInf(v, -1):
+			return exactNumber{inf: -1}, true
+		default:
+			return exactNumber{rat: new(big.Rat).SetFloat64(v)}, true
+		}
+	case int32:
+		return exactNumber{rat: new(big.Rat).SetInt64(int64(v))}, true
+	case int64:
+		return exactNumber{rat: new(big.Rat).SetInt64(v)}, true
+	case Decimal128:
+		switch {
+		case v.IsNaN():
+			return exactNumber{nan: true}, true
+		case v.IsInf(1):
+			return exactNumber{inf: 1}, true
+		case v.IsInf(-1):
+			return exactNumber{inf: -1}, true
+		default:
+			return exactNumber{rat: v.Rat()}, true
+		}
+	default:
+		return exactNumber{}, false
+	}
+}
+
+// compareDecimals compares BSON numbers when at least one of them is Decimal128.
+//
+// Values are compared exactly; NaN is equal only to NaN.
+func compareDecimals(v1, v2 any) CompareResult {
+	n1, ok1 := newExactNumber(v1)
+	n2, ok2 := newExactNumber(v2)
+	if !ok1 || !ok2 {
+		return Incomparable
+	}
+
+	switch {
+	case n1.nan && n2.nan:
+		return Equal
+	case n1.nan || n2.nan:
+		return Incomparable
+	case n1.inf != 0 || n2.inf != 0:
+		return compareOrdered(n1.inf, n2.inf)
+	default:
+		return CompareResult(n1.rat.Cmp(n2.rat))
+	}
+}
+
 // compareArrays compares indices of a filter array according to indices of a document array;
 // returns Equal even when an array contains subarray equals to filter array;
 // returns both Less and Greater when subarrays satisfy filter array. Example:
@@ -319,7 +405,7 @@ func compareArrays(filterArr, docArr *Array) []CompareResult {
 					}
 
 					entireCompareResult = append(entireCompareResult, iterationResult...)
-					entireCompareResult = append(entireCompareResult, CompareOrder(docValue, filterValue, Ascending))
+					entireCompareResult = append(entireCompareResult, CompareOrder(docValue, filterValue))
 				}
 
 				entireCompareResult, gtAndLt = handleInconsistencyInResults(entireCompareResult, iterationResult, subArray)
@@ -344,7 +430,7 @@ func compareArrays(filterArr, docArr *Array) []CompareResult {
 			}
 
 			if i == 0 { // set first non-Incomparable result
-				entireCompareResult = []CompareResult{CompareOrder(docValue, filterValue, Ascending)}
+				entireCompareResult = []CompareResult{CompareOrder(docValue, filterValue)}
 				continue
 			}
 
@@ -18,16 +18,16 @@ import (
 	"fmt"
 	"math"
 	"time"
+
+	"github.com/FerretDB/FerretDB/internal/util/must"
 )
 
 //go:generate ../../bin/stringer -linecomment -type compareTypeOrderResult
-//go:generate ../../bin/stringer -linecomment -type numberOrderResult
 //go:generate ../../bin/stringer -linecomment -type SortType
 
 // compareTypeOrderResult represents the comparison order of data types.
 type compareTypeOrderResult uint8
 
-// TODO: handle sorting for documentDataType and arrayDataType; https://github.com/FerretDB/FerretDB/issues/457
 const (
 	_ compareTypeOrderResult = iota
 	nullDataType
@@ -47,6 +47,8 @@ const (
 // detectDataType returns a sequence for build-in type.
 func detectDataType(value any) compareTypeOrderResult {
 	switch value := value.(type) {
+	case *Document:
+		return documentDataType
 	case *Array:
 		return arrayDataType
 	case float64:
@@ -74,36 +76,13 @@ func detectDataType(value any) compareTypeOrderResult {
 		return timestampDataType
 	case int64:
 		return numbersDataType
-	default:
-		panic(fmt.Sprintf("value cannot be defined, value is %[1]v, data type of value is %[1]T", value))
-	}
-}
-
-// numberOrderResult represents the comparison order of numbers.
-type numberOrderResult uint8
-
-const (
-	_ numberOrderResult = iota
-	doubleNegativeZero
-	doubleDT
-	int32DT
-	int64DT
-)
-
-// detectNumberType returns a sequence for float64, int32 and int64 types.
-func detectNumberType(value any) numberOrderResult {
-	switch value := value.(type) {
-	case float64:
-		if value == 0 && math.Signbit(value) {
-			return doubleNegativeZero
+	case Decimal128:
+		if value.IsNaN() {
+			return nanDataType
 		}
-		return doubleDT
-	case int32:
-		return int32DT
-	case int64:
-		return int64DT
+		return numbersDataType
 	default:
-		panic(fmt.Sprintf("detectNumberType: value cannot be defined, value is %[1]v, data type of value is %[1]T", value))
+		panic(fmt.Sprintf("value cannot be defined, value is %[1]v, data type of value is %[1]T", value))
 	}
 }
 
@@ -118,59 +97,81 @@ const (
 	Descending SortType = -1
 )
 
-// CompareOrder detects the data type for two values and compares them.
-// When the types are equal, it compares their values using Compare.
-func CompareOrder(a, b any, order SortType) CompareResult {
+// CompareOrder compares any BSON values using the total order MongoDB uses for sorting:
+//
+//	Null < Numbers < String < Object < Array < BinData < ObjectId < Boolean < Date < Timestamp < Regex
+//
+// Numbers of different types are compared by value (NaN is less than any other number),
+// strings are compared byte by byte, documents and arrays are compared recursively.
+// Unlike Compare, it never returns Incomparable.
+func CompareOrder(a, b any) CompareResult {
 	if a == nil {
 		panic("CompareOrder: a is nil")
 	}
 	if b == nil {
 		panic("CompareOrder: b is nil")
 	}
-	if order != Ascending && order != Descending {
-		panic(fmt.Sprintf("CompareOrder: order is %v", order))
-	}
 
 	aType := detectDataType(a)
 	bType := detectDataType(b)
-	switch {
-	case aType < bType:
-		return Less
-	case aType > bType:
-		return Greater
+	if aType != bType {
+		return compareOrdered(aType, bType)
+	}
+
+	switch a := a.(type) {
+	case *Document:
+		return compareOrderDocuments(a, b.(*Document))
+	case *Array:
+		return compareOrderArrays(a, b.(*Array))
+	case Regex:
+		b := b.(Regex)
+		if res := compareOrdered(a.Pattern, b.Pattern); res != Equal {
+			return res
+		}
+		return compareOrdered(a.Options, b.Options)
 	default:
-		result := Compare(a, b)
-		if ContainsCompareResult(result, Equal) && aType == numbersDataType {
-			return compareNumberOrder(a, b, order)
+		res := compareScalars(a, b)
+		if res == Incomparable {
+			panic(fmt.Sprintf("CompareOrder: %[1]v (%[1]T) and %[2]v (%[2]T) are incomparable", a, b))
 		}
-
-		// getting result at zero index because result with more than one value is only in composite data types and
-		// since the result can be both more and less, we will simply choose a random (by zero index; only composite)
-		return result[0]
+		return res
 	}
 }
 
-// compareNumberOrder detects the number type for two values and compares them.
-func compareNumberOrder(a, b any, order SortType) CompareResult {
-	if a == nil {
-		panic("compareNumberOrder: a is nil")
-	}
-	if b == nil {
-		panic("compareNumberOrder: b is nil")
+// compareOrderDocuments compares documents field by field:
+// first by value type, then by field name, then by value.
+// If all fields are equal, the document with fewer fields is less.
+func compareOrderDocuments(a, b *Document) CompareResult {
+	aKeys, bKeys := a.Keys(), b.Keys()
+
+	for i := 0; i < len(aKeys) && i < len(bKeys); i++ {
+		aValue := must.NotFail(a.Get(aKeys[i]))
+		bValue := must.NotFail(b.Get(bKeys[i]))
+
+		if res := compareOrdered(detectDataType(aValue), detectDataType(bValue)); res != Equal {
+			return res
+		}
+
+		if res := compareOrdered(aKeys[i], bKeys[i]); res != Equal {
+			return res
+		}
+
+		if res := CompareOrder(aValue, bValue); res != Equal {
+			return res
+		}
 	}
 
-	aNumberType := detectNumberType(a)
-	bNumberType := detectNumberType(b)
-	switch {
-	case aNumberType < bNumberType && order == Ascending:
-		return Less
-	case aNumberType > bNumberType && order == Ascending:
-		return Greater
-	case aNumberType < bNumberType && order == Descending:
-		return Greater
-	case aNumberType > bNumberType && order == Descending:
-		return Less
-	default:
-		return Equal
+	return compareOrdered(len(aKeys), len(bKeys))
+}
+
+// compareOrderArrays compares arrays element by element.
+// If all elements are equal, the shorter array is less.
+func compareOrderArrays(a, b *Array) CompareResult {
+	for i := 0; i < a.Len() && i < b.Len(); i++ {
+		if res := CompareOrder(must.NotFail(a.Get(i)), must.NotFail(b.Get(i))); res != Equal {
+			return res
+		}
 	}
+
+	return compareOrdered(a.Len(), b.Len())
 }
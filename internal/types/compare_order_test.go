@@ -0,0 +1,143 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// compareOrderValues returns values in the ascending order; some neighbors are equal.
+func compareOrderValues() [][]any {
+	return [][]any{
+		{Null},
+		{math.NaN(), must.NotFail(ParseDecimal128("NaN"))},
+		{math.Inf(-1), must.NotFail(ParseDecimal128("-Infinity"))},
+		{int64(math.MinInt64)},
+		{int32(-1), int64(-1), -1.0, must.NotFail(ParseDecimal128("-1.00"))},
+		{math.Copysign(0, -1), 0.0, int32(0), int64(0), must.NotFail(ParseDecimal128("-0"))},
+		{math.SmallestNonzeroFloat64},
+		{must.NotFail(ParseDecimal128("0.1"))},
+		{0.1},
+		{int32(42), int64(42), 42.0},
+		{int64(math.MaxInt64)},
+		{math.MaxFloat64},
+		{math.Inf(1), must.NotFail(ParseDecimal128("Infinity"))},
+		{""},
+		{"A"},
+		{"a"},
+		{"ab"},
+		{"b"},
+		{must.NotFail(NewDocument())},
+		{must.NotFail(NewDocument("a", int32(1))), must.NotFail(NewDocument("a", 1.0))},
+		{must.NotFail(NewDocument("a", int32(1), "b", "foo"))},
+		{must.NotFail(NewDocument("b", int32(0)))}, // value types are compared before field names
+		{must.NotFail(NewDocument("a", "foo"))},
+		{must.NotFail(NewDocument("a", must.NotFail(NewDocument("a", int32(1)))))},
+		{must.NotFail(NewArray())},
+		{must.NotFail(NewArray(Null))},
+		{must.NotFail(NewArray(int32(1))), must.NotFail(NewArray(int64(1)))},
+		{must.NotFail(NewArray(int32(1), int32(2)))},
+		{must.NotFail(NewArray(int32(2)))},
+		{must.NotFail(NewArray("foo"))},
+		{must.NotFail(NewArray(must.NotFail(NewArray(int32(1)))))},
+		{Binary{Subtype: BinaryGeneric, B: []byte{0x02}}},
+		{Binary{Subtype: BinaryUser, B: []byte{0x01}}},
+		{Binary{Subtype: BinaryGeneric, B: []byte{0x00, 0x00}}},
+		{ObjectID{}},
+		{ObjectID{0x01}},
+		{false},
+		{true},
+		{time.UnixMilli(-1)},
+		{time.UnixMilli(0)},
+		{time.UnixMilli(1)},
+		{Timestamp(0)},
+		{Timestamp(1)},
+		{Regex{Pattern: "a"}},
+		{Regex{Pattern: "a", Options: "i"}},
+		{Regex{Pattern: "b"}},
+	}
+}
+
+func TestCompareOrder(t *testing.T) {
+	t.Parallel()
+
+	groups := compareOrderValues()
+
+	type value struct {
+		v     any
+		group int
+	}
+
+	var values []value
+	for i, g := range groups {
+		for _, v := range g {
+			values = append(values, value{v: v, group: i})
+		}
+	}
+
+	for _, a := range values {
+		for _, b := range values {
+			res := CompareOrder(a.v, b.v)
+			expected := compareOrdered(a.group, b.group)
+			require.Equal(t, expected, res, "%[1]v (%[1]T) vs %[2]v (%[2]T)", a.v, b.v)
+
+			// consistency with equality used for filtering
+			if _, ok := a.v.(*Array); ok {
+				continue
+			}
+			if _, ok := b.v.(*Array); ok {
+				continue
+			}
+
+			if ContainsCompareResult(Compare(a.v, b.v), Equal) {
+				assert.Equal(t, Equal, res, "%[1]v (%[1]T) vs %[2]v (%[2]T)", a.v, b.v)
+			}
+		}
+	}
+}
+
+func TestCompareOrderProperties(t *testing.T) {
+	t.Parallel()
+
+	var values []any
+	for _, g := range compareOrderValues() {
+		values = append(values, g...)
+	}
+
+	for _, a := range values {
+		for _, b := range values {
+			ab := CompareOrder(a, b)
+			require.NotEqual(t, Incomparable, ab)
+			require.Equal(t, compareInvert(ab), CompareOrder(b, a), "antisymmetry: %v and %v", a, b)
+
+			for _, c := range values {
+				bc := CompareOrder(b, c)
+				if ab != bc {
+					continue
+				}
+
+				// a < b < c, a == b == c, or a > b > c
+				require.Equal(t, ab, CompareOrder(a, c), "transitivity: %v, %v, and %v", a, b, c)
+			}
+		}
+	}
+}
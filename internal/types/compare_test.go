@@ -0,0 +1,69 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestCompareSpecialDoubles(t *testing.T) {
+	t.Parallel()
+
+	negZero := math.Copysign(0, -1)
+	nan := math.NaN()
+	inf := math.Inf(+1)
+	negInf := math.Inf(-1)
+
+	for name, tc := range map[string]struct {
+		docValue    any
+		filterValue any
+		expected    CompareResult
+	}{
+		"NegZeroZero":    {negZero, 0.0, Equal},
+		"ZeroNegZero":    {0.0, negZero, Equal},
+		"NegZeroInt32":   {negZero, int32(0), Equal},
+		"Int64NegZero":   {int64(0), negZero, Equal},
+		"NegZeroDecimal": {negZero, must.NotFail(ParseDecimal128("0")), Equal},
+		"NaNNaN":         {nan, nan, Equal},
+		"NaNDecimalNaN":  {nan, must.NotFail(ParseDecimal128("NaN")), Equal},
+		"NaNZero":        {nan, 0.0, Incomparable},
+		"NaNInt32":       {nan, int32(0), Incomparable},
+		"Int64NaN":       {int64(0), nan, Incomparable},
+		"NaNInf":         {nan, inf, Incomparable},
+		"InfInf":         {inf, inf, Equal},
+		"InfMaxFloat":    {inf, math.MaxFloat64, Greater},
+		"InfMaxInt64":    {inf, int64(math.MaxInt64), Greater},
+		"NegInfNegInf":   {negInf, negInf, Equal},
+		"NegInfMinInt64": {negInf, int64(math.MinInt64), Less},
+		"Int32NegInf":    {int32(math.MinInt32), negInf, Greater},
+		"NegInfInf":      {negInf, inf, Less},
+		"InfDecimalInf":  {inf, must.NotFail(ParseDecimal128("Infinity")), Equal},
+		"ArrayNaN":       {must.NotFail(NewArray(int32(1), nan)), nan, Equal},
+		"ArrayNegZero":   {must.NotFail(NewArray(negZero)), int32(0), Equal},
+		"ArrayNegInf":    {must.NotFail(NewArray(negInf)), negInf, Equal},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, []CompareResult{tc.expected}, Compare(tc.docValue, tc.filterValue))
+		})
+	}
+}
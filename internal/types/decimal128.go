@@ -0,0 +1,298 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Decimal128 represents BSON type Decimal128.
+//
+// It is an IEEE 754-2008 128-bit decimal floating point number in the binary integer decimal (BID) encoding.
+// Values are stored exactly as they were received; no arithmetic is performed on them.
+type Decimal128 struct {
+	H uint64 // high 64 bits
+	L uint64 // low 64 bits
+}
+
+// Decimal128 encoding parameters.
+const (
+	decimal128ExponentBias = 6176
+	decimal128MinExponent  = -6176
+	decimal128MaxExponent  = 6111
+	decimal128MaxDigits    = 34
+)
+
+var (
+	// decimal128NaN is the canonical Decimal128 NaN value.
+	decimal128NaN = Decimal128{H: 0x7c00000000000000}
+
+	// decimal128Inf is the canonical Decimal128 positive infinity value.
+	decimal128Inf = Decimal128{H: 0x7800000000000000}
+
+	// decimal128MaxCoefficient is the maximal coefficient value: 10^34 - 1.
+	decimal128MaxCoefficient = new(big.Int).Sub(
+		new(big.Int).Exp(big.NewInt(10), big.NewInt(decimal128MaxDigits), nil),
+		big.NewInt(1),
+	)
+)
+
+// decimal128Form represents the kind of Decimal128 value.
+type decimal128Form int
+
+const (
+	decimal128Finite decimal128Form = iota
+	decimal128Infinity
+	decimal128NotANumber
+)
+
+// ParseDecimal128 parses Decimal128 from a string like "1.23", "-1.5E+10", "Infinity", or "NaN".
+//
+// The value is not rounded: an error is returned if it can't be represented exactly.
+func ParseDecimal128(s string) (Decimal128, error) {
+	orig := s
+
+	var neg bool
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+
+	switch strings.ToLower(s) {
+	case "nan":
+		return decimal128NaN, nil
+	case "inf", "infinity":
+		res := decimal128Inf
+		if neg {
+			res.H |= 1 << 63
+		}
+
+		return res, nil
+	}
+
+	mantissa, exponent := s, ""
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		mantissa, exponent = s[:i], s[i+1:]
+		if exponent == "" {
+			return Decimal128{}, fmt.Errorf("types.ParseDecimal128: invalid value %q", orig)
+		}
+	}
+
+	var exp int
+	if exponent != "" {
+		var err error
+		if exp, err = strconv.Atoi(exponent); err != nil {
+			return Decimal128{}, fmt.Errorf("types.ParseDecimal128: invalid exponent in %q", orig)
+		}
+	}
+
+	intPart, fracPart := mantissa, ""
+	if i := strings.IndexByte(mantissa, '.'); i >= 0 {
+		intPart, fracPart = mantissa[:i], mantissa[i+1:]
+	}
+
+	digits := intPart + fracPart
+	if digits == "" {
+		return Decimal128{}, fmt.Errorf("types.ParseDecimal128: invalid value %q", orig)
+	}
+
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return Decimal128{}, fmt.Errorf("types.ParseDecimal128: invalid value %q", orig)
+		}
+	}
+
+	exp -= len(fracPart)
+
+	digits = strings.TrimLeft(digits, "0")
+	if digits == "" {
+		// zero keeps its exponent, clamped to the valid range
+		if exp < decimal128MinExponent {
+			exp = decimal128MinExponent
+		}
+
+		if exp > decimal128MaxExponent {
+			exp = decimal128MaxExponent
+		}
+
+		return newDecimal128(neg, new(big.Int), exp), nil
+	}
+
+	// remove trailing zeros if there are too many digits or the exponent is too small
+	for len(digits) > 1 && digits[len(digits)-1] == '0' &&
+		(len(digits) > decimal128MaxDigits || exp < decimal128MinExponent) {
+		digits = digits[:len(digits)-1]
+		exp++
+	}
+
+	// add trailing zeros if the exponent is too large
+	for exp > decimal128MaxExponent && len(digits) < decimal128MaxDigits {
+		digits += "0"
+		exp--
+	}
+
+	if len(digits) > decimal128MaxDigits || exp < decimal128MinExponent || exp > decimal128MaxExponent {
+		return Decimal128{}, fmt.Errorf("types.ParseDecimal128: value %q can't be represented exactly", orig)
+	}
+
+	coef, _ := new(big.Int).SetString(digits, 10)
+
+	return newDecimal128(neg, coef, exp), nil
+}
+
+// newDecimal128 returns a finite Decimal128 from sign, coefficient (that should fit), and exponent.
+func newDecimal128(neg bool, coef *big.Int, exp int) Decimal128 {
+	low := new(big.Int).And(coef, new(big.Int).SetUint64(^uint64(0))).Uint64()
+	high := new(big.Int).Rsh(coef, 64).Uint64()
+
+	high |= uint64(exp+decimal128ExponentBias) << 49
+	if neg {
+		high |= 1 << 63
+	}
+
+	return Decimal128{H: high, L: low}
+}
+
+// decompose returns the sign, form, coefficient, and exponent of the value.
+//
+// Non-canonical values (with coefficients exceeding 34 digits) are zeros.
+func (d Decimal128) decompose() (neg bool, form decimal128Form, coef *big.Int, exp int) {
+	neg = d.H>>63 == 1
+
+	switch {
+	case (d.H>>58)&0x1f == 0x1f:
+		return neg, decimal128NotANumber, nil, 0
+
+	case (d.H>>58)&0x1f == 0x1e:
+		return neg, decimal128Infinity, nil, 0
+
+	case (d.H>>61)&0x3 == 0x3:
+		// the implied coefficient is always larger than the maximum
+		exp = int((d.H>>47)&0x3fff) - decimal128ExponentBias
+		return neg, decimal128Finite, new(big.Int), exp
+	}
+
+	exp = int((d.H>>49)&0x3fff) - decimal128ExponentBias
+
+	coef = new(big.Int).SetUint64(d.H & (1<<49 - 1))
+	coef.Lsh(coef, 64)
+	coef.Or(coef, new(big.Int).SetUint64(d.L))
+
+	if coef.Cmp(decimal128MaxCoefficient) > 0 {
+		coef.SetInt64(0)
+	}
+
+	return neg, decimal128Finite, coef, exp
+}
+
+// IsNaN returns true if the value is NaN.
+func (d Decimal128) IsNaN() bool {
+	_, form, _, _ := d.decompose()
+	return form == decimal128NotANumber
+}
+
+// IsInf returns true if the value is infinity;
+// if sign > 0, positive infinity; if sign < 0, negative infinity; if sign == 0, either.
+func (d Decimal128) IsInf(sign int) bool {
+	neg, form, _, _ := d.decompose()
+	if form != decimal128Infinity {
+		return false
+	}
+
+	return sign == 0 || (sign > 0 && !neg) || (sign < 0 && neg)
+}
+
+// Rat returns the exact value of a finite Decimal128.
+// It returns nil for NaN and infinities.
+func (d Decimal128) Rat() *big.Rat {
+	neg, form, coef, exp := d.decompose()
+	if form != decimal128Finite {
+		return nil
+	}
+
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(exp))), nil)
+
+	res := new(big.Rat).SetInt(coef)
+	if exp >= 0 {
+		res.Mul(res, new(big.Rat).SetInt(scale))
+	} else {
+		res.Quo(res, new(big.Rat).SetInt(scale))
+	}
+
+	if neg {
+		res.Neg(res)
+	}
+
+	return res
+}
+
+// String returns the canonical string representation of the value,
+// as defined by the BSON Decimal128 specification.
+func (d Decimal128) String() string {
+	neg, form, coef, exp := d.decompose()
+
+	var sign string
+	if neg {
+		sign = "-"
+	}
+
+	switch form {
+	case decimal128NotANumber:
+		return "NaN"
+	case decimal128Infinity:
+		return sign + "Infinity"
+	case decimal128Finite:
+		// below
+	}
+
+	digits := coef.String()
+	adjusted := exp + len(digits) - 1
+
+	if exp <= 0 && adjusted >= -6 {
+		if exp == 0 {
+			return sign + digits
+		}
+
+		point := len(digits) + exp
+		if point > 0 {
+			return sign + digits[:point] + "." + digits[point:]
+		}
+
+		return sign + "0." + strings.Repeat("0", -point) + digits
+	}
+
+	res := sign + digits[:1]
+	if len(digits) > 1 {
+		res += "." + digits[1:]
+	}
+
+	if adjusted >= 0 {
+		return res + "E+" + strconv.Itoa(adjusted)
+	}
+
+	return res + "E" + strconv.Itoa(adjusted)
+}
+
+// abs returns the absolute value of x.
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+
+	return x
+}
@@ -0,0 +1,122 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestDecimal128(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		s     string
+		d     Decimal128
+		canon string // if different from s
+		err   string
+	}{
+		"Zero":         {s: "0", d: Decimal128{H: 0x3040000000000000}},
+		"NegativeZero": {s: "-0", d: Decimal128{H: 0xb040000000000000}},
+		"ZeroExponent": {s: "0E+3", d: Decimal128{H: 0x3046000000000000}},
+		"One":          {s: "1", d: Decimal128{H: 0x3040000000000000, L: 1}},
+		"OnePointZero": {s: "1.0", d: Decimal128{H: 0x303e000000000000, L: 10}},
+		"Negative":     {s: "-1.23E+5", d: Decimal128{H: 0xb046000000000000, L: 123}},
+		"Small":        {s: "0.001234", d: Decimal128{H: 0x3034000000000000, L: 1234}},
+		"Tiny":         {s: "1E-6176", d: Decimal128{L: 1}},
+		"Max":          {s: "9.999999999999999999999999999999999E+6144", d: Decimal128{H: 0x5fffed09bead87c0, L: 0x378d8e63ffffffff}},
+		"Clamped": {
+			s:     "1E+6144",
+			d:     Decimal128{H: 0x5ffe314dc6448d93, L: 0x38c15b0a00000000},
+			canon: "1.000000000000000000000000000000000E+6144",
+		},
+		"NaN":          {s: "NaN", d: Decimal128{H: 0x7c00000000000000}},
+		"Infinity":     {s: "Infinity", d: Decimal128{H: 0x7800000000000000}},
+		"NegInfinity":  {s: "-Infinity", d: Decimal128{H: 0xf800000000000000}},
+		"Lowercase":    {s: "-inf", d: Decimal128{H: 0xf800000000000000}, canon: "-Infinity"},
+		"Exponent":     {s: "12e3", d: Decimal128{H: 0x3046000000000000, L: 12}, canon: "1.2E+4"},
+		"LeadingZeros": {s: "000123", d: Decimal128{H: 0x3040000000000000, L: 123}, canon: "123"},
+		"Empty":        {s: "", err: `types.ParseDecimal128: invalid value ""`},
+		"Letters":      {s: "1a", err: `types.ParseDecimal128: invalid value "1a"`},
+		"NoExponent":   {s: "1E", err: `types.ParseDecimal128: invalid value "1E"`},
+		"TooManyDigits": {
+			s:   "12345678901234567890123456789012345",
+			err: `types.ParseDecimal128: value "12345678901234567890123456789012345" can't be represented exactly`,
+		},
+		"ExponentTooBig": {s: "1E+6145", err: `types.ParseDecimal128: value "1E+6145" can't be represented exactly`},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			d, err := ParseDecimal128(tc.s)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.d, d)
+
+			canon := tc.canon
+			if canon == "" {
+				canon = tc.s
+			}
+			assert.Equal(t, canon, d.String())
+		})
+	}
+}
+
+func TestDecimal128Compare(t *testing.T) {
+	t.Parallel()
+
+	one := must.NotFail(ParseDecimal128("1"))
+	onePointZero := must.NotFail(ParseDecimal128("1.0"))
+	half := must.NotFail(ParseDecimal128("0.5"))
+	tenth := must.NotFail(ParseDecimal128("0.1"))
+	nan := must.NotFail(ParseDecimal128("NaN"))
+	inf := must.NotFail(ParseDecimal128("Infinity"))
+	negInf := must.NotFail(ParseDecimal128("-Infinity"))
+
+	for name, tc := range map[string]struct {
+		a, b     any
+		expected CompareResult
+	}{
+		"SameValue":      {one, onePointZero, Equal},
+		"Int32":          {one, int32(1), Equal},
+		"Int64":          {int64(2), one, Greater},
+		"Double":         {half, 0.5, Equal},
+		"DoubleInexact":  {tenth, 0.1, Less}, // 0.1 double is slightly bigger than 0.1
+		"NaN":            {nan, nan, Equal},
+		"NaNDouble":      {nan, math.NaN(), Equal},
+		"NaNNumber":      {nan, one, Incomparable},
+		"Infinity":       {inf, math.MaxFloat64, Greater},
+		"InfinityDouble": {inf, math.Inf(1), Equal},
+		"NegInfinity":    {negInf, int64(math.MinInt64), Less},
+		"String":         {one, "1", Incomparable},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, []CompareResult{tc.expected}, Compare(tc.a, tc.b))
+		})
+	}
+}
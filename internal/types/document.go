@@ -17,6 +17,7 @@ package types
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"unicode/utf8"
 
 	"golang.org/x/exp/slices"
@@ -126,6 +127,11 @@ func isValidKey(key string) bool {
 		return false
 	}
 
+	// BSON keys are null-terminated strings, so such keys could not be encoded
+	if strings.IndexByte(key, 0) >= 0 {
+		return false
+	}
+
 	return utf8.ValidString(key)
 }
 
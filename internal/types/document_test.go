@@ -159,6 +159,19 @@ func TestDocument(t *testing.T) {
 				keys: []string{"$db"},
 				m:    map[string]any{"$db": "foo"},
 			},
+		}, {
+			name: "dotted keys",
+			doc: Document{
+				keys: []string{"a.b"},
+				m:    map[string]any{"a.b": "foo"},
+			},
+		}, {
+			name: "null byte keys",
+			doc: Document{
+				keys: []string{"a\x00b"},
+				m:    map[string]any{"a\x00b": "foo"},
+			},
+			err: fmt.Errorf(`types.Document.validate: invalid key: "a\x00b"`),
 		}} {
 			tc := tc
 			t.Run(tc.name, func(t *testing.T) {
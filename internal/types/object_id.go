@@ -33,18 +33,45 @@ type ObjectID [ObjectIDLen]byte
 const ObjectIDLen = 12
 
 // NewObjectID returns a new ObjectID.
+//
+// It is generated as described in the specification: 4-byte big-endian timestamp in seconds,
+// 5-byte random value unique for the current process, and 3-byte big-endian counter
+// that is initialized with a random value and wraps around on overflow.
 func NewObjectID() ObjectID {
-	return newObjectIDTime(time.Now())
+	return defaultObjectIDGenerator.new(time.Now())
+}
+
+// Timestamp returns the time part of ObjectID with second precision.
+func (id ObjectID) Timestamp() time.Time {
+	return time.Unix(int64(binary.BigEndian.Uint32(id[0:4])), 0)
 }
 
-// newObjectIDTime returns a new ObjectID with given time.
-func newObjectIDTime(t time.Time) ObjectID {
+// objectIDGenerator generates ObjectIDs.
+type objectIDGenerator struct {
+	process [5]byte
+	counter uint32 // accessed atomically
+}
+
+// defaultObjectIDGenerator is used by NewObjectID.
+var defaultObjectIDGenerator = newObjectIDGenerator()
+
+// newObjectIDGenerator returns a new generator with random process value and counter.
+func newObjectIDGenerator() *objectIDGenerator {
+	var g objectIDGenerator
+	must.NotFail(io.ReadFull(rand.Reader, g.process[:]))
+	must.NoError(binary.Read(rand.Reader, binary.BigEndian, &g.counter))
+
+	return &g
+}
+
+// new returns a new ObjectID with given time.
+func (g *objectIDGenerator) new(t time.Time) ObjectID {
 	var res ObjectID
 
 	binary.BigEndian.PutUint32(res[0:4], uint32(t.Unix()))
-	copy(res[4:9], objectIDProcess[:])
+	copy(res[4:9], g.process[:])
 
-	c := atomic.AddUint32(&objectIDCounter, 1)
+	c := atomic.AddUint32(&g.counter, 1)
 
 	// ignore the most significant byte for correct wraparound
 	res[9] = byte(c >> 16)
@@ -53,13 +80,3 @@ func newObjectIDTime(t time.Time) ObjectID {
 
 	return res
 }
-
-var (
-	objectIDProcess [5]byte
-	objectIDCounter uint32
-)
-
-func init() {
-	must.NotFail(io.ReadFull(rand.Reader, objectIDProcess[:]))
-	must.NoError(binary.Read(rand.Reader, binary.BigEndian, &objectIDCounter))
-}
@@ -15,45 +15,120 @@
 package types
 
 import (
+	"bytes"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-//nolint:paralleltest // we modify the global objectIDProcess
 func TestNewObjectID(t *testing.T) {
-	objectIDProcess = [5]byte{0x0b, 0xad, 0xc0, 0xff, 0xee}
+	t.Parallel()
+
+	g := &objectIDGenerator{process: [5]byte{0x0b, 0xad, 0xc0, 0xff, 0xee}}
 	ts := time.Date(2022, time.April, 13, 12, 44, 42, 0, time.UTC)
 
-	atomic.StoreUint32(&objectIDCounter, 0)
 	assert.Equal(
 		t,
 		ObjectID{0x62, 0x56, 0xc5, 0xba, 0x0b, 0xad, 0xc0, 0xff, 0xee, 0x00, 0x00, 0x01},
-		newObjectIDTime(ts),
+		g.new(ts),
 	)
 	assert.Equal(
 		t,
 		ObjectID{0x62, 0x56, 0xc5, 0xba, 0x0b, 0xad, 0xc0, 0xff, 0xee, 0x00, 0x00, 0x02},
-		newObjectIDTime(ts),
+		g.new(ts),
 	)
 
 	// test wraparound
-	atomic.StoreUint32(&objectIDCounter, 1<<24-2)
+	atomic.StoreUint32(&g.counter, 1<<24-2)
 	assert.Equal(
 		t,
 		ObjectID{0x62, 0x56, 0xc5, 0xba, 0x0b, 0xad, 0xc0, 0xff, 0xee, 0xff, 0xff, 0xff},
-		newObjectIDTime(ts),
+		g.new(ts),
 	)
 	assert.Equal(
 		t,
 		ObjectID{0x62, 0x56, 0xc5, 0xba, 0x0b, 0xad, 0xc0, 0xff, 0xee, 0x00, 0x00, 0x00},
-		newObjectIDTime(ts),
+		g.new(ts),
 	)
 	assert.Equal(
 		t,
 		ObjectID{0x62, 0x56, 0xc5, 0xba, 0x0b, 0xad, 0xc0, 0xff, 0xee, 0x00, 0x00, 0x01},
-		newObjectIDTime(ts),
+		g.new(ts),
 	)
+
+	// the counter overflows 32 bits too
+	atomic.StoreUint32(&g.counter, 1<<32-1)
+	assert.Equal(
+		t,
+		ObjectID{0x62, 0x56, 0xc5, 0xba, 0x0b, 0xad, 0xc0, 0xff, 0xee, 0x00, 0x00, 0x00},
+		g.new(ts),
+	)
+
+	assert.Equal(t, ts, g.new(ts).Timestamp().UTC())
+}
+
+func TestNewObjectIDProcess(t *testing.T) {
+	t.Parallel()
+
+	id1, id2 := NewObjectID(), NewObjectID()
+	assert.Equal(t, id1[4:9], id2[4:9], "process value should be the same")
+
+	g1, g2 := newObjectIDGenerator(), newObjectIDGenerator()
+	assert.NotEqual(t, g1.process, g2.process, "process values should be random")
+}
+
+func TestNewObjectIDOrder(t *testing.T) {
+	t.Parallel()
+
+	g := newObjectIDGenerator()
+	ts := time.Date(2022, time.April, 13, 12, 44, 42, 0, time.UTC)
+
+	var prev ObjectID
+	for i := 0; i < 10; i++ {
+		id := g.new(ts.Add(time.Duration(i) * time.Second))
+		assert.Equal(t, ts.Add(time.Duration(i)*time.Second), id.Timestamp().UTC())
+		assert.Equal(t, 1, bytes.Compare(id[:4], prev[:4]), "timestamps should be increasing")
+		prev = id
+	}
+}
+
+func TestNewObjectIDUnique(t *testing.T) {
+	t.Parallel()
+
+	const goroutines = 100
+	n := 10000
+	if testing.Short() {
+		n = 1000
+	}
+
+	ids := make([][]ObjectID, goroutines)
+
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			ids[i] = make([]ObjectID, n)
+			for j := range ids[i] {
+				ids[i][j] = NewObjectID()
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	seen := make(map[ObjectID]struct{}, goroutines*n)
+	for _, part := range ids {
+		for _, id := range part {
+			_, ok := seen[id]
+			require.False(t, ok, "duplicate ObjectID %x", id)
+			seen[id] = struct{}{}
+		}
+	}
 }
@@ -171,11 +171,11 @@ func removeByPath(v any, path Path) {
 		if err != nil {
 			return // no such path
 		}
-		if i > len(v.s)-1 {
+		if i < 0 || i > len(v.s)-1 {
 			return // no such path
 		}
 		if path.Len() == 1 {
-			v.s = append(v.s[:i], v.s[i+1:]...)
+			must.NoError(v.RemoveAt(i))
 			return
 		}
 		removeByPath(v.s[i], path.TrimPrefix())
@@ -196,6 +196,10 @@ func TestRemoveByPathArray(t *testing.T) {
 			path:     NewPath([]string{"abcd"}),
 			expected: src.DeepCopy(),
 		},
+		"array: negative index": {
+			path:     NewPath([]string{"-1"}),
+			expected: src.DeepCopy(),
+		},
 	} {
 		tc := tc
 		t.Run(name, func(t *testing.T) {
@@ -18,12 +18,11 @@ import (
 	"fmt"
 	"regexp"
 	"regexp/syntax"
+	"strings"
+	"sync"
 )
 
 var (
-	// ErrOptionNotImplemented indicates unimplemented regex option.
-	ErrOptionNotImplemented = fmt.Errorf("regex: option not implemented")
-
 	// ErrMissingParen indicates missing parentheses in regex expression.
 	ErrMissingParen = fmt.Errorf("Regular expression is invalid: missing )")
 
@@ -55,37 +54,72 @@ var (
 	ErrInvalidRepeatSize = fmt.Errorf("Regular expression is invalid: regular expression is too large")
 )
 
+// RegexUnsupportedError indicates PCRE construct that is valid for MongoDB, but not supported by Go regexp.
+type RegexUnsupportedError struct {
+	Construct string
+}
+
+// Error implements error interface.
+func (e *RegexUnsupportedError) Error() string {
+	return fmt.Sprintf("Regular expression is invalid: %s is not supported", e.Construct)
+}
+
 // Regex represents BSON type Regex.
 type Regex struct {
 	Pattern string
 	Options string
 }
 
+// maxRegexCacheSize is the maximal number of compiled regular expressions in the cache.
+const maxRegexCacheSize = 1000
+
+// regexCache caches compiled regular expressions, so they are not compiled for every filtered document.
+var regexCache = struct {
+	m sync.Mutex
+	c map[Regex]*regexp.Regexp
+}{
+	c: make(map[Regex]*regexp.Regexp),
+}
+
 // Compile returns Go Regexp object.
+//
+// PCRE pattern and options are translated to Go syntax first (see translateRegex).
+// Compiled regular expressions are cached.
 func (r Regex) Compile() (*regexp.Regexp, error) {
-	var opts string
-	for _, o := range r.Options {
-		switch o {
-		case 'i', 'm', 's':
-			opts += string(o)
-		case 'x':
-			// TODO: https://github.com/FerretDB/FerretDB/issues/592
-			return nil, ErrOptionNotImplemented
-		default:
-			continue
-		}
+	regexCache.m.Lock()
+	re := regexCache.c[r]
+	regexCache.m.Unlock()
+
+	if re != nil {
+		return re, nil
+	}
+
+	re, err := r.compile()
+	if err != nil {
+		return nil, err
+	}
+
+	regexCache.m.Lock()
+	if len(regexCache.c) >= maxRegexCacheSize {
+		regexCache.c = make(map[Regex]*regexp.Regexp, maxRegexCacheSize)
 	}
+	regexCache.c[r] = re
+	regexCache.m.Unlock()
+
+	return re, nil
+}
 
-	expr := r.Pattern
-	if opts != "" {
-		expr = "(?" + opts + ")" + expr
+// compile returns Go Regexp object without using the cache.
+func (r Regex) compile() (*regexp.Regexp, error) {
+	expr, err := translateRegex(r.Pattern, r.Options)
+	if err != nil {
+		return nil, err
 	}
 
 	re, err := regexp.Compile(expr)
 	if err == nil {
 		return re, nil
 	}
-
 	if err, ok := err.(*syntax.Error); ok {
 		switch err.Code {
 		case syntax.ErrInvalidCharRange:
@@ -116,3 +150,289 @@ func (r Regex) Compile() (*regexp.Regexp, error) {
 	}
 	return nil, fmt.Errorf("types.Regex.Compile: %w", err)
 }
+
+// translateRegex translates PCRE pattern and MongoDB options to Go regexp syntax.
+//
+// Options i, m and s are passed as Go flags, option x (extended) is implemented by removing
+// whitespace and comments from the pattern. Other options are ignored, like MongoDB does for u.
+//
+// PCRE constructs that Go does not support are reported with RegexUnsupportedError
+// instead of being silently mis-handled:
+// lookaround assertions, backreferences, atomic groups, possessive quantifiers, recursion, etc.
+//
+// Without the m option, PCRE's $ also matches before the final newline.
+// That is translated for $ and \Z at the end of the pattern or its alternative;
+// \Z elsewhere is not supported.
+func translateRegex(pattern, options string) (string, error) {
+	var flags string
+	var extended, multiline bool
+	for _, o := range options {
+		switch o {
+		case 'i', 's':
+			flags += string(o)
+		case 'm':
+			flags += string(o)
+			multiline = true
+		case 'x':
+			extended = true
+		}
+	}
+
+	var b strings.Builder
+	if flags != "" {
+		b.WriteString("(?" + flags + ")")
+	}
+
+	// endOfAlternative returns true if the pattern at i is the end of alternative
+	endOfAlternative := func(i int) bool {
+		return i == len(pattern) || pattern[i] == '|' || pattern[i] == ')'
+	}
+
+	var inClass bool
+	for i := 0; i < len(pattern); {
+		c := pattern[i]
+
+		if inClass {
+			switch {
+			case c == '\\' && i+1 < len(pattern):
+				b.WriteString(translateEscape(pattern[i+1]))
+				i += 2
+			case c == '[' && strings.HasPrefix(pattern[i:], "[:"):
+				// POSIX class like [:alpha:]
+				end := strings.Index(pattern[i+2:], ":]")
+				if end < 0 {
+					b.WriteByte(c)
+					i++
+					break
+				}
+
+				b.WriteString(pattern[i : i+2+end+2])
+				i += 2 + end + 2
+			case c == ']':
+				inClass = false
+				b.WriteByte(c)
+				i++
+			default:
+				b.WriteByte(c)
+				i++
+			}
+
+			continue
+		}
+
+		switch {
+		case c == '\\':
+			if i+1 == len(pattern) {
+				// Go reports trailing backslash
+				b.WriteByte(c)
+				i++
+				break
+			}
+
+			next := pattern[i+1]
+			switch next {
+			case '1', '2', '3', '4', '5', '6', '7', '8', '9':
+				return "", &RegexUnsupportedError{Construct: `backreference \` + string(next)}
+			case 'g', 'k':
+				return "", &RegexUnsupportedError{Construct: `backreference \` + string(next)}
+			case 'G', 'K', 'R', 'X', 'C', 'h', 'H', 'V', 'N':
+				return "", &RegexUnsupportedError{Construct: `escape sequence \` + string(next)}
+			case 'Z':
+				if !endOfAlternative(i + 2) {
+					return "", &RegexUnsupportedError{Construct: `\Z not at the end of pattern`}
+				}
+
+				b.WriteString(`(?:\n?\z)`)
+				i += 2
+				continue
+			case 'Q':
+				// literal text up to \E is supported by Go; copy it as is
+				end := strings.Index(pattern[i+2:], `\E`)
+				if end < 0 {
+					b.WriteString(pattern[i:])
+					i = len(pattern)
+					continue
+				}
+
+				b.WriteString(pattern[i : i+2+end+2])
+				i += 2 + end + 2
+				continue
+			}
+
+			b.WriteString(translateEscape(next))
+			i += 2
+
+		case c == '[':
+			inClass = true
+			b.WriteByte(c)
+			i++
+
+			// ] right after [ or [^ is a literal
+			if i < len(pattern) && pattern[i] == '^' {
+				b.WriteByte('^')
+				i++
+			}
+			if i < len(pattern) && pattern[i] == ']' {
+				b.WriteString(`\]`)
+				i++
+			}
+
+		case extended && isRegexSpace(c):
+			i++
+
+		case extended && c == '#':
+			end := strings.IndexByte(pattern[i:], '\n')
+			if end < 0 {
+				i = len(pattern)
+				break
+			}
+			i += end + 1
+
+		case c == '(' && strings.HasPrefix(pattern[i:], "(?"):
+			n, err := translateGroup(pattern[i:])
+			if err != nil {
+				return "", err
+			}
+
+			if n == 0 {
+				b.WriteString("(?")
+				i += 2
+				break
+			}
+
+			// comment group (?#...) is skipped
+			i += n
+
+		case (c == '*' || c == '+' || c == '?') && strings.HasPrefix(pattern[i+1:], "+"):
+			return "", &RegexUnsupportedError{Construct: "possessive quantifier " + string(c) + "+"}
+
+		case c == '{':
+			if n := countedRepetitionLen(pattern[i:]); n > 0 && strings.HasPrefix(pattern[i+n:], "+") {
+				return "", &RegexUnsupportedError{Construct: "possessive quantifier " + pattern[i:i+n] + "+"}
+			}
+
+			b.WriteByte(c)
+			i++
+
+		case c == '$' && !multiline && endOfAlternative(i+1):
+			b.WriteString(`(?:\n?\z)`)
+			i++
+
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+
+	return b.String(), nil
+}
+
+// translateGroup checks a group starting with "(?" at the beginning of s.
+//
+// It returns the length of the group if it should be skipped (comment), 0 if it should be kept,
+// or error for unsupported groups.
+func translateGroup(s string) (int, error) {
+	rest := s[2:]
+
+	for _, g := range []struct {
+		prefix    string
+		construct string
+	}{
+		{"'", "named group (?'"},
+		{"<=", "lookbehind assertion (?<="},
+		{"<!", "negative lookbehind assertion (?<!"},
+		{"=", "lookahead assertion (?="},
+		{"!", "negative lookahead assertion (?!"},
+		{">", "atomic group (?>"},
+		{"|", "branch reset group (?|"},
+		{"(", "conditional group (?("},
+		{"R", "recursion (?R"},
+		{"&", "subroutine call (?&"},
+		{"P>", "subroutine call (?P>"},
+		{"P=", "backreference (?P="},
+		{"C", "callout (?C"},
+		{"+", "subroutine call (?+"},
+	} {
+		if strings.HasPrefix(rest, g.prefix) {
+			return 0, &RegexUnsupportedError{Construct: g.construct}
+		}
+	}
+
+	if rest != "" && (rest[0] >= '0' && rest[0] <= '9' || rest[0] == '-' && len(rest) > 1 && rest[1] >= '0' && rest[1] <= '9') {
+		return 0, &RegexUnsupportedError{Construct: "subroutine call (?" + rest[:1]}
+	}
+
+	if strings.HasPrefix(rest, "#") {
+		end := strings.IndexByte(rest, ')')
+		if end < 0 {
+			// let Go report missing parenthesis
+			return 0, nil
+		}
+
+		return 2 + end + 1, nil
+	}
+
+	// inline flags like (?i) or (?i:...); named groups and other syntax are checked by Go
+	for i := 0; i < len(rest); i++ {
+		switch c := rest[i]; c {
+		case ':', ')':
+			return 0, nil
+		case 'x', 'J', 'X':
+			return 0, &RegexUnsupportedError{Construct: "inline flag " + string(c)}
+		case 'i', 'm', 's', 'U', '-':
+			continue
+		default:
+			return 0, nil
+		}
+	}
+
+	return 0, nil
+}
+
+// translateEscape returns Go syntax for PCRE escape sequence \c.
+func translateEscape(c byte) string {
+	switch c {
+	case ' ':
+		// Go does not allow escaping of a space
+		return `\x20`
+	default:
+		return `\` + string(c)
+	}
+}
+
+// countedRepetitionLen returns the length of counted repetition like {n}, {n,} or {n,m}
+// at the beginning of s, or 0.
+func countedRepetitionLen(s string) int {
+	end := strings.IndexByte(s, '}')
+	if end < 2 {
+		return 0
+	}
+
+	var digits, commas int
+	for _, c := range s[1:end] {
+		switch {
+		case c >= '0' && c <= '9':
+			digits++
+		case c == ',':
+			commas++
+		default:
+			return 0
+		}
+	}
+
+	if digits == 0 || commas > 1 || s[1] == ',' {
+		return 0
+	}
+
+	return end + 1
+}
+
+// isRegexSpace returns true if c is a whitespace character ignored by PCRE extended mode.
+func isRegexSpace(c byte) bool {
+	switch c {
+	case ' ', '\t', '\n', '\v', '\f', '\r':
+		return true
+	default:
+		return false
+	}
+}
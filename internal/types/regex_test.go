@@ -0,0 +1,256 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegexCompile(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		regex   Regex
+		match   []string
+		noMatch []string
+		err     string
+	}{
+		"Simple": {
+			regex:   Regex{Pattern: "^foo"},
+			match:   []string{"foo", "foobar"},
+			noMatch: []string{"Foo", "barfoo"},
+		},
+		"Options": {
+			regex:   Regex{Pattern: "^foo.bar$", Options: "ims"},
+			match:   []string{"FOO\nBAR", "baz\nfoo bar\nqux"},
+			noMatch: []string{"foobar"},
+		},
+		"UnknownOptions": {
+			regex: Regex{Pattern: "foo", Options: "123u"},
+			match: []string{"foo"},
+		},
+		"Extended": {
+			regex: Regex{
+				Pattern: "^ foo \\  [ b]  # comment\n  \\# $",
+				Options: "x",
+			},
+			match:   []string{"foo  #", "foo b#"},
+			noMatch: []string{"foo", " foo  #"},
+		},
+		"ExtendedEscapedSpace": {
+			regex: Regex{Pattern: `a\ b`},
+			match: []string{"a b"},
+		},
+		"Dollar": {
+			regex:   Regex{Pattern: "foo$"},
+			match:   []string{"foo", "foo\n"},
+			noMatch: []string{"foo\n\n", "foo\nbar"},
+		},
+		"DollarAlternative": {
+			regex:   Regex{Pattern: "(a$|b)c"},
+			noMatch: []string{"ac"},
+		},
+		"DollarMultiline": {
+			regex:   Regex{Pattern: "foo$", Options: "m"},
+			match:   []string{"foo", "foo\nbar"},
+			noMatch: []string{"foobar"},
+		},
+		"DollarClass": {
+			regex: Regex{Pattern: "[$]"},
+			match: []string{"$"},
+		},
+		"Z": {
+			regex:   Regex{Pattern: `foo\Z`},
+			match:   []string{"foo", "foo\n"},
+			noMatch: []string{"foo\nbar"},
+		},
+		"Class": {
+			regex:   Regex{Pattern: `[]a(]+[[:digit:]]`},
+			match:   []string{"]a(1"},
+			noMatch: []string{"]a("},
+		},
+		"Quote": {
+			regex: Regex{Pattern: `\Q(?<=$\E`},
+			match: []string{"(?<=$"},
+		},
+		"CommentGroup": {
+			regex: Regex{Pattern: `a(?#comment)b`},
+			match: []string{"ab"},
+		},
+		"InlineFlags": {
+			regex: Regex{Pattern: `(?i)a(?s:.)b`},
+			match: []string{"A\nb"},
+		},
+		"NamedGroup": {
+			regex: Regex{Pattern: `(?P<x>a)`},
+			match: []string{"a"},
+		},
+		"Lookbehind": {
+			regex: Regex{Pattern: `(?<=a)b`},
+			err:   "Regular expression is invalid: lookbehind assertion (?<= is not supported",
+		},
+		"NegativeLookbehind": {
+			regex: Regex{Pattern: `(?<!a)b`},
+			err:   "Regular expression is invalid: negative lookbehind assertion (?<! is not supported",
+		},
+		"Lookahead": {
+			regex: Regex{Pattern: `a(?=b)`},
+			err:   "Regular expression is invalid: lookahead assertion (?= is not supported",
+		},
+		"NegativeLookahead": {
+			regex: Regex{Pattern: `a(?!b)`},
+			err:   "Regular expression is invalid: negative lookahead assertion (?! is not supported",
+		},
+		"AtomicGroup": {
+			regex: Regex{Pattern: `(?>a+)b`},
+			err:   "Regular expression is invalid: atomic group (?> is not supported",
+		},
+		"Backreference": {
+			regex: Regex{Pattern: `(a)\1`},
+			err:   `Regular expression is invalid: backreference \1 is not supported`,
+		},
+		"NamedBackreference": {
+			regex: Regex{Pattern: `(?<x>a)\k<x>`},
+			err:   `Regular expression is invalid: backreference \k is not supported`,
+		},
+		"Possessive": {
+			regex: Regex{Pattern: `a++b`},
+			err:   "Regular expression is invalid: possessive quantifier ++ is not supported",
+		},
+		"PossessiveCounted": {
+			regex: Regex{Pattern: `a{1,3}+b`},
+			err:   "Regular expression is invalid: possessive quantifier {1,3}+ is not supported",
+		},
+		"ZInTheMiddle": {
+			regex: Regex{Pattern: `a\Zb`},
+			err:   `Regular expression is invalid: \Z not at the end of pattern is not supported`,
+		},
+		"Recursion": {
+			regex: Regex{Pattern: `(a(?R)?b)`},
+			err:   "Regular expression is invalid: recursion (?R is not supported",
+		},
+		"InlineExtended": {
+			regex: Regex{Pattern: `(?x)a b`},
+			err:   "Regular expression is invalid: inline flag x is not supported",
+		},
+		"PCREEscape": {
+			regex: Regex{Pattern: `a\Kb`},
+			err:   `Regular expression is invalid: escape sequence \K is not supported`,
+		},
+		"MissingParen": {
+			regex: Regex{Pattern: `(a`},
+			err:   ErrMissingParen.Error(),
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			re, err := tc.regex.Compile()
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+
+			for _, s := range tc.match {
+				assert.True(t, re.MatchString(s), "%q should match %q", re, s)
+			}
+			for _, s := range tc.noMatch {
+				assert.False(t, re.MatchString(s), "%q should not match %q", re, s)
+			}
+		})
+	}
+}
+
+func TestRegexCompileCache(t *testing.T) {
+	t.Parallel()
+
+	r := Regex{Pattern: "^cache$", Options: "i"}
+
+	re1, err := r.Compile()
+	require.NoError(t, err)
+
+	re2, err := r.Compile()
+	require.NoError(t, err)
+
+	assert.Same(t, re1, re2)
+}
+
+func FuzzRegex(f *testing.F) {
+	for _, tc := range []struct {
+		pattern string
+		options string
+	}{
+		{"^abc$", ""},
+		{"abc", "i"},
+		{"^a.*c$", "ms"},
+		{`\d+\.\d*`, ""},
+		{`^[a-z0-9._%+-]+@[a-z0-9.-]+\.[a-z]{2,}$`, "i"},
+		{`(foo|bar)baz?`, ""},
+		{`[^\w\s]`, ""},
+		{`^\s*#`, "m"},
+		{"a b # comment\n c", "x"},
+		{`(?i)abc`, ""},
+		{`(?<=a)b`, ""},
+		{`(a)\1`, ""},
+		{`a{2,3}+`, ""},
+		{`\Qa.b\E`, ""},
+		{`[[:alpha:]]+`, ""},
+		{`[]]`, ""},
+		{`\Z`, ""},
+		{`(?#c)a`, ""},
+		{`\`, ""},
+		{`[`, ""},
+		{`(`, ""},
+	} {
+		f.Add(tc.pattern, tc.options)
+	}
+
+	f.Fuzz(func(t *testing.T, pattern, options string) {
+		t.Parallel()
+
+		r := Regex{Pattern: pattern, Options: options}
+
+		expr, err := translateRegex(pattern, options)
+		if err != nil {
+			var ue *RegexUnsupportedError
+			require.ErrorAs(t, err, &ue)
+			require.NotEmpty(t, ue.Construct)
+			return
+		}
+
+		// patterns without special PCRE syntax should not change
+		if !strings.ContainsAny(pattern, `\[($# `+"\t\n\v\f\r") && !strings.ContainsAny(options, "imsx") {
+			assert.Equal(t, pattern, expr)
+		}
+
+		// Compile must return either Go regexp or a known error
+		re, err := r.compile()
+		if err != nil {
+			return
+		}
+
+		// patterns that are valid in Go and do not use special PCRE syntax should be the same
+		if !strings.ContainsAny(pattern, `\[($#`) && options == "" {
+			assert.Equal(t, regexp.MustCompile(pattern).String(), re.String())
+		}
+	})
+}
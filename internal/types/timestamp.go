@@ -15,7 +15,8 @@
 package types
 
 import (
-	"sync/atomic"
+	"math"
+	"sync"
 	"time"
 )
 
@@ -24,8 +25,15 @@ type (
 	Timestamp int64
 )
 
-// timestampCounter is an ordinal number for timestamps in the system.
-var timestampCounter uint32
+// timestampGenerator generates unique timestamps.
+type timestampGenerator struct {
+	m   sync.Mutex
+	sec int64
+	inc uint32
+}
+
+// defaultTimestampGenerator is used by NextTimestamp.
+var defaultTimestampGenerator timestampGenerator
 
 // NewTimestamp returns a timestamp from time and an increment.
 func NewTimestamp(t time.Time, c uint32) Timestamp {
@@ -35,10 +43,36 @@ func NewTimestamp(t time.Time, c uint32) Timestamp {
 	return Timestamp(sec)
 }
 
-// NextTimestamp returns a timestamp from time and an internal ops counter.
+// NextTimestamp returns a timestamp from time and an increment that is unique in the current process.
+//
+// Returned timestamps are strictly increasing.
+// The increment starts from 1 for every new second.
+// If the given time is before the last returned timestamp (for example, the clock went backwards),
+// the last returned second is held, and only the increment is advanced.
 func NextTimestamp(t time.Time) Timestamp {
-	c := atomic.AddUint32(&timestampCounter, 1)
-	return NewTimestamp(t, c)
+	return defaultTimestampGenerator.next(t.Unix())
+}
+
+// next returns the next timestamp for the given second.
+func (g *timestampGenerator) next(sec int64) Timestamp {
+	g.m.Lock()
+	defer g.m.Unlock()
+
+	switch {
+	case sec > g.sec:
+		g.sec = sec
+		g.inc = 1
+
+	case g.inc == math.MaxUint32:
+		// increments for that second are exhausted, borrow the next one
+		g.sec++
+		g.inc = 1
+
+	default:
+		g.inc++
+	}
+
+	return NewTimestamp(time.Unix(g.sec, 0), g.inc)
 }
 
 // Time returns time.Time ignoring increment.
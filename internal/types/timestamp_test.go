@@ -0,0 +1,107 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextTimestamp(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Concurrent", func(t *testing.T) {
+		t.Parallel()
+
+		const goroutines = 100
+		const n = 100 // per goroutine, 10k in total
+
+		now := time.Now()
+		res := make([][]Timestamp, goroutines)
+
+		var wg sync.WaitGroup
+		for g := 0; g < goroutines; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+
+				for i := 0; i < n; i++ {
+					res[g] = append(res[g], NextTimestamp(now))
+				}
+			}(g)
+		}
+		wg.Wait()
+
+		seen := make(map[Timestamp]struct{}, goroutines*n)
+		all := make([]Timestamp, 0, goroutines*n)
+
+		for _, tss := range res {
+			for i, ts := range tss {
+				// monotonic within each goroutine
+				if i > 0 {
+					require.Greater(t, ts, tss[i-1])
+				}
+
+				_, ok := seen[ts]
+				require.False(t, ok, "duplicate timestamp %d", ts)
+				seen[ts] = struct{}{}
+
+				all = append(all, ts)
+			}
+		}
+
+		assert.Len(t, seen, goroutines*n)
+
+		// timestamps are not from the past
+		sort.Slice(all, func(i, j int) bool { return all[i] < all[j] })
+		assert.GreaterOrEqual(t, all[0].Time().Unix(), now.Unix())
+	})
+
+	t.Run("ClockBackwards", func(t *testing.T) {
+		t.Parallel()
+
+		var g timestampGenerator
+
+		ts1 := g.next(1000)
+		ts2 := g.next(999)
+		assert.Equal(t, NewTimestamp(time.Unix(1000, 0), 1), ts1)
+		assert.Equal(t, NewTimestamp(time.Unix(1000, 0), 2), ts2)
+	})
+
+	t.Run("NewSecond", func(t *testing.T) {
+		t.Parallel()
+
+		var g timestampGenerator
+
+		assert.Equal(t, NewTimestamp(time.Unix(1000, 0), 1), g.next(1000))
+		assert.Equal(t, NewTimestamp(time.Unix(1000, 0), 2), g.next(1000))
+		assert.Equal(t, NewTimestamp(time.Unix(1001, 0), 1), g.next(1001))
+	})
+
+	t.Run("IncrementOverflow", func(t *testing.T) {
+		t.Parallel()
+
+		g := timestampGenerator{sec: 1000, inc: math.MaxUint32}
+
+		assert.Equal(t, NewTimestamp(time.Unix(1001, 0), 1), g.next(1000))
+		assert.Equal(t, NewTimestamp(time.Unix(1001, 0), 2), g.next(1000))
+	})
+}
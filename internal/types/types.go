@@ -27,22 +27,23 @@
 //
 // Composite types (passed by pointers)
 //
-//	*types.Document  *bson.Document       *fjson.documentType   Document
-//	*types.Array     *bson.arrayType      *fjson.arrayType      Array
+//	*types.Document   *bson.Document        *fjson.documentType    Document
+//	*types.Array      *bson.arrayType       *fjson.arrayType       Array
 //
 // Scalar types (passed by values)
 //
-//	float64          *bson.doubleType     *fjson.doubleType     64-bit binary floating point
-//	string           *bson.stringType     *fjson.stringType     UTF-8 string
-//	types.Binary     *bson.binaryType     *fjson.binaryType     Binary data
-//	types.ObjectID   *bson.objectIDType   *fjson.objectIDType   ObjectId
-//	bool             *bson.boolType       *fjson.boolType       Boolean
-//	time.Time        *bson.dateTimeType   *fjson.dateTimeType   UTC datetime
-//	types.NullType   *bson.nullType       *fjson.nullType       Null
-//	types.Regex      *bson.regexType      *fjson.regexType      Regular expression
-//	int32            *bson.int32Type      *fjson.int32Type      32-bit integer
-//	types.Timestamp  *bson.timestampType  *fjson.timestampType  Timestamp
-//	int64            *bson.int64Type      *fjson.int64Type      64-bit integer
+//	float64           *bson.doubleType      *fjson.doubleType      64-bit binary floating point
+//	string            *bson.stringType      *fjson.stringType      UTF-8 string
+//	types.Binary      *bson.binaryType      *fjson.binaryType      Binary data
+//	types.ObjectID    *bson.objectIDType    *fjson.objectIDType    ObjectId
+//	bool              *bson.boolType        *fjson.boolType        Boolean
+//	time.Time         *bson.dateTimeType    *fjson.dateTimeType    UTC datetime
+//	types.NullType    *bson.nullType        *fjson.nullType        Null
+//	types.Regex       *bson.regexType       *fjson.regexType       Regular expression
+//	int32             *bson.int32Type       *fjson.int32Type       32-bit integer
+//	types.Timestamp   *bson.timestampType   *fjson.timestampType   Timestamp
+//	int64             *bson.int64Type       *fjson.int64Type       64-bit integer
+//	types.Decimal128  *bson.decimal128Type  *fjson.decimal128Type  128-bit decimal floating point
 package types
 
 import (
@@ -55,7 +56,7 @@ const MaxDocumentLen = 16777216
 
 // ScalarType represents scalar type.
 type ScalarType interface {
-	float64 | string | Binary | ObjectID | bool | time.Time | NullType | Regex | int32 | Timestamp | int64
+	float64 | string | Binary | ObjectID | bool | time.Time | NullType | Regex | int32 | Timestamp | int64 | Decimal128
 }
 
 // CompositeType represents composite type - *Document or *Array.
@@ -124,6 +125,8 @@ func validateValue(value any) error {
 		return nil
 	case int64:
 		return nil
+	case Decimal128:
+		return nil
 	default:
 		return fmt.Errorf("types.validateValue: unsupported type: %[1]T (%[1]v)", value)
 	}
@@ -186,6 +189,8 @@ func deepCopy(value any) any {
 		return value
 	case int64:
 		return value
+	case Decimal128:
+		return value
 
 	default:
 		panic(fmt.Sprintf("types.deepCopy: unsupported type: %[1]T (%[1]v)", value))
@@ -18,6 +18,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/FerretDB/FerretDB/internal/util/must"
 )
 
 func TestDeepCopy(t *testing.T) {
@@ -51,4 +53,23 @@ func TestDeepCopy(t *testing.T) {
 		o1[0] = 0
 		assert.NotEqual(t, o1, o2)
 	})
+
+	t.Run("Nested", func(t *testing.T) {
+		t.Parallel()
+
+		inner := must.NotFail(NewDocument("b", int32(1)))
+		d1 := must.NotFail(NewDocument("a", must.NotFail(NewArray(inner, "foo"))))
+		d2 := d1.DeepCopy()
+
+		assert.Equal(t, d1, d2)
+
+		must.NoError(inner.Set("b", int32(2)))
+		must.NoError(must.NotFail(d1.Get("a")).(*Array).Set(1, "bar"))
+		assert.NotEqual(t, d1, d2)
+
+		expected := must.NotFail(NewDocument("a", must.NotFail(NewArray(
+			must.NotFail(NewDocument("b", int32(1))), "foo",
+		))))
+		assert.Equal(t, expected, d2)
+	})
 }
@@ -43,3 +43,18 @@ func WithDelay(done <-chan struct{}, delay time.Duration) (context.Context, cont
 
 	return ctx, cancel
 }
+
+// Sleep pauses the current goroutine until the duration d has elapsed or ctx is canceled.
+//
+// It returns ctx.Err() in the latter case.
+func Sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
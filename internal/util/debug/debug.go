@@ -28,23 +28,38 @@ import (
 )
 
 // RunHandler runs debug handler.
-func RunHandler(ctx context.Context, addr string, l *zap.Logger) {
+//
+// It serves metrics from the given registry; nil means prometheus.DefaultRegisterer and prometheus.DefaultGatherer.
+// States of the given providers (that may be nil) are served on /debug/state.
+// Other debug handlers (pprof, expvar) are served from http.DefaultServeMux.
+// Multiple debug handlers could run in the same process.
+func RunHandler(ctx context.Context, addr string, r *prometheus.Registry, state map[string]StateProvider, l *zap.Logger) { //nolint:lll // argument list is too long
 	stdL, err := zap.NewStdLogAt(l, zap.WarnLevel)
 	if err != nil {
 		panic(err)
 	}
 
-	http.Handle("/debug/metrics", promhttp.InstrumentMetricHandler(
-		prometheus.DefaultRegisterer, promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{
+	var registerer prometheus.Registerer = prometheus.DefaultRegisterer
+	var gatherer prometheus.Gatherer = prometheus.DefaultGatherer
+	if r != nil {
+		registerer, gatherer = r, r
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/debug/metrics", promhttp.InstrumentMetricHandler(
+		registerer, promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{
 			ErrorLog:          stdL,
 			ErrorHandling:     promhttp.ContinueOnError,
-			Registry:          prometheus.DefaultRegisterer,
+			Registry:          registerer,
 			EnableOpenMetrics: true,
 		}),
 	))
+	mux.Handle("/debug/state", StateHandler(state, l))
+	mux.Handle("/", http.DefaultServeMux)
 
 	s := http.Server{
 		Addr:     addr,
+		Handler:  mux,
 		ErrorLog: stdL,
 		BaseContext: func(_ net.Listener) context.Context {
 			return ctx
@@ -0,0 +1,50 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// StateProvider is implemented by components that report their live state on the /debug/state endpoint.
+type StateProvider interface {
+	// DebugState returns the current state that could be encoded as JSON.
+	//
+	// It must not include document data or credentials.
+	DebugState() any
+}
+
+// StateHandler returns an HTTP handler that serves a JSON object
+// with states of the given providers by their names.
+func StateHandler(providers map[string]StateProvider, l *zap.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res := make(map[string]any, len(providers))
+		for name, p := range providers {
+			res[name] = p.DebugState()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		if err := enc.Encode(res); err != nil {
+			l.Warn("Failed to write state", zap.Error(err))
+		}
+	})
+}
@@ -0,0 +1,60 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// testStateProvider is a StateProvider for tests.
+type testStateProvider struct {
+	state any
+}
+
+// DebugState implements StateProvider.
+func (p *testStateProvider) DebugState() any {
+	return p.state
+}
+
+func TestStateHandler(t *testing.T) {
+	t.Parallel()
+
+	h := StateHandler(map[string]StateProvider{
+		"first":  &testStateProvider{state: map[string]int{"cursors": 2}},
+		"second": &testStateProvider{state: []string{"a", "b"}},
+	}, zaptest.NewLogger(t))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/state", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var actual map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &actual))
+
+	expected := map[string]any{
+		"first":  map[string]any{"cursors": float64(2)},
+		"second": []any{"a", "b"},
+	}
+	assert.Equal(t, expected, actual)
+}
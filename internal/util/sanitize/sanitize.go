@@ -0,0 +1,169 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sanitize provides shape-only representations of documents
+// that are safe to log: they do not include user data.
+package sanitize
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+const (
+	// maxDepth is the nesting level of documents after which they are not expanded.
+	maxDepth = 20
+
+	// maxFields is the number of document fields after which the rest are only counted.
+	maxFields = 100
+
+	// maxKeyLen is the field name length in bytes after which it is truncated.
+	maxKeyLen = 64
+)
+
+// Document returns a shape-only representation of the given document.
+//
+// Field names are kept (long names are truncated), values are replaced by their type names,
+// arrays are collapsed to their length and elements type.
+// Huge and deeply nested documents are shortened, so the result size is bounded
+// and the time is linear in the number of visited values.
+//
+// For example:
+//
+//	{"insert": string, "documents": [2 x object], "ordered": bool, "$db": string}
+func Document(doc *types.Document) string {
+	var sb strings.Builder
+	writeDocument(&sb, doc, 0)
+
+	return sb.String()
+}
+
+// writeDocument writes document's shape to sb.
+func writeDocument(sb *strings.Builder, doc *types.Document, depth int) {
+	if doc == nil {
+		sb.WriteString("null")
+		return
+	}
+
+	if depth >= maxDepth {
+		sb.WriteString("{...}")
+		return
+	}
+
+	keys := doc.Keys()
+	m := doc.Map()
+
+	sb.WriteByte('{')
+
+	for i, k := range keys {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+
+		if i == maxFields {
+			fmt.Fprintf(sb, "... %d more", len(keys)-i)
+			break
+		}
+
+		sb.WriteString(key(k))
+		sb.WriteString(": ")
+		writeValue(sb, m[k], depth+1)
+	}
+
+	sb.WriteByte('}')
+}
+
+// writeValue writes value's shape to sb.
+func writeValue(sb *strings.Builder, v any, depth int) {
+	switch v := v.(type) {
+	case *types.Document:
+		writeDocument(sb, v, depth)
+
+	case *types.Array:
+		l := v.Len()
+		if l == 0 {
+			sb.WriteString("[]")
+			return
+		}
+
+		t := typeName(must.NotFail(v.Get(0)))
+		for i := 1; i < l; i++ {
+			if typeName(must.NotFail(v.Get(i))) != t {
+				t = "mixed"
+				break
+			}
+		}
+
+		fmt.Fprintf(sb, "[%d x %s]", l, t)
+
+	default:
+		sb.WriteString(typeName(v))
+	}
+}
+
+// key returns a quoted and possibly truncated field name.
+func key(k string) string {
+	if len(k) <= maxKeyLen {
+		return strconv.Quote(k)
+	}
+
+	i := maxKeyLen
+	for i > 0 && !utf8.RuneStart(k[i]) {
+		i--
+	}
+
+	return strconv.Quote(k[:i]) + fmt.Sprintf("...(%d bytes)", len(k))
+}
+
+// typeName returns type alias name for given value, the same as used by $type query operator.
+func typeName(v any) string {
+	switch v := v.(type) {
+	case *types.Document:
+		return "object"
+	case *types.Array:
+		return "array"
+	case float64:
+		return "double"
+	case string:
+		return "string"
+	case types.Binary:
+		return "binData"
+	case types.ObjectID:
+		return "objectId"
+	case bool:
+		return "bool"
+	case time.Time:
+		return "date"
+	case types.NullType:
+		return "null"
+	case types.Regex:
+		return "regex"
+	case int32:
+		return "int"
+	case types.Timestamp:
+		return "timestamp"
+	case int64:
+		return "long"
+	case types.Decimal128:
+		return "decimal"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sanitize
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+func TestDocument(t *testing.T) {
+	t.Parallel()
+
+	for name, tc := range map[string]struct {
+		doc      *types.Document
+		expected string
+	}{
+		"Empty": {
+			doc:      must.NotFail(types.NewDocument()),
+			expected: `{}`,
+		},
+		"Scalars": {
+			doc: must.NotFail(types.NewDocument(
+				"double", 42.13,
+				"string", "secret",
+				"binary", types.Binary{Subtype: types.BinaryUser, B: []byte("secret")},
+				"objectID", types.ObjectID{0x01},
+				"bool", true,
+				"datetime", time.Now(),
+				"null", types.Null,
+				"regex", types.Regex{Pattern: "secret"},
+				"int32", int32(42),
+				"timestamp", types.Timestamp(42),
+				"int64", int64(42),
+			)),
+			expected: `{"double": double, "string": string, "binary": binData, "objectID": objectId, "bool": bool, ` +
+				`"datetime": date, "null": null, "regex": regex, "int32": int, "timestamp": timestamp, "int64": long}`,
+		},
+		"Insert": {
+			doc: must.NotFail(types.NewDocument(
+				"insert", "users",
+				"documents", must.NotFail(types.NewArray(
+					must.NotFail(types.NewDocument("_id", int32(1), "password", "secret")),
+					must.NotFail(types.NewDocument("_id", int32(2), "password", "secret")),
+				)),
+				"ordered", true,
+				"$db", "test",
+			)),
+			expected: `{"insert": string, "documents": [2 x object], "ordered": bool, "$db": string}`,
+		},
+		"Nested": {
+			doc: must.NotFail(types.NewDocument(
+				"find", "users",
+				"filter", must.NotFail(types.NewDocument(
+					"name", must.NotFail(types.NewDocument("$eq", "secret")),
+					"tags", must.NotFail(types.NewDocument("$in", must.NotFail(types.NewArray("a", int32(1))))),
+					"empty", must.NotFail(types.NewArray()),
+				)),
+			)),
+			expected: `{"find": string, "filter": {"name": {"$eq": string}, "tags": {"$in": [2 x mixed]}, "empty": []}}`,
+		},
+		"LongKey": {
+			doc: must.NotFail(types.NewDocument(
+				strings.Repeat("a", maxKeyLen-1)+"Ж", int32(1),
+			)),
+			expected: "{" + strconv.Quote(strings.Repeat("a", maxKeyLen-1)) + `...(65 bytes): int}`,
+		},
+	} {
+		name, tc := name, tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, Document(tc.doc))
+		})
+	}
+}
+
+func TestDocumentDeeplyNested(t *testing.T) {
+	t.Parallel()
+
+	doc := must.NotFail(types.NewDocument("v", "secret"))
+	for i := 0; i < 1000; i++ {
+		doc = must.NotFail(types.NewDocument("v", doc))
+	}
+
+	expected := strings.Repeat(`{"v": `, maxDepth) + "{...}" + strings.Repeat("}", maxDepth)
+	assert.Equal(t, expected, Document(doc))
+}
+
+func TestDocumentHuge(t *testing.T) {
+	t.Parallel()
+
+	// each of those parts would make a document of about 16 MiB
+	const n = 1 << 20
+
+	fields := types.MakeDocument(n)
+	for i := 0; i < n; i++ {
+		must.NoError(fields.Set("f"+strconv.Itoa(i), int32(i)))
+	}
+
+	elements := types.MakeArray(n)
+	for i := 0; i < n; i++ {
+		must.NoError(elements.Append(must.NotFail(types.NewDocument("_id", int32(i)))))
+	}
+
+	doc := must.NotFail(types.NewDocument(
+		"string", strings.Repeat("x", types.MaxDocumentLen-1024),
+		"array", elements,
+		"fields", fields,
+	))
+
+	start := time.Now()
+	actual := Document(doc)
+	assert.Less(t, time.Since(start), 10*time.Second)
+
+	assert.Less(t, len(actual), 2048)
+	assert.True(t, strings.HasPrefix(actual, `{"string": string, "array": [1048576 x object], "fields": {"f0": int, "f1": int, `), actual)
+	assert.True(t, strings.HasSuffix(actual, `"f99": int, ... 1048476 more}}`), actual)
+}
@@ -313,6 +313,13 @@ func equalScalars(tb testing.TB, v1, v2 any) bool {
 		}
 		return s1 == s2
 
+	case types.Decimal128:
+		s2, ok := v2.(types.Decimal128)
+		if !ok {
+			return false
+		}
+		return s1 == s2
+
 	default:
 		tb.Fatalf("unhandled types %T, %T", v1, v2)
 		panic("not reached")
@@ -12,15 +12,22 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+// Package version provides information about FerretDB version and build configuration.
+//
+// Version details are taken from the Go build information (see runtime/debug.ReadBuildInfo)
+// when FerretDB is built with `go build` or `go install` from a VCS checkout or module proxy.
+// For release builds, files in gen/ (see generate.go) are used as a fallback.
 package version
 
 import (
 	"embed"
 	"fmt"
+	"io/fs"
 	"regexp"
 	"runtime/debug"
 	"strconv"
 	"strings"
+	"time"
 
 	"golang.org/x/exp/slices"
 
@@ -37,13 +44,18 @@ import (
 //go:embed gen
 var gen embed.FS
 
+// modulePath is the path of FerretDB Go module.
+const modulePath = "github.com/FerretDB/FerretDB"
+
 // Info provides details about the current build.
 type Info struct {
 	Version          string
 	Commit           string
+	CommitTime       time.Time // zero if unknown
 	Branch           string
 	Dirty            bool
-	Debug            bool // -tags=ferretdb_testcover or -race
+	Package          string // package type (for example, "deb" or "docker"); unknown for other builds
+	Debug            bool   // -tags=ferretdb_testcover or -race
 	BuildEnvironment *types.Document
 }
 
@@ -57,76 +69,123 @@ var (
 	info *Info
 )
 
-// unknown is a placeholder for unknown version, commit, and branch values.
+// unknown is a placeholder for unknown version, commit, branch, and package values.
 const unknown = "unknown"
 
+// semVerRe matches semantic versions with optional "v" prefix, pre-release and build metadata.
+var semVerRe = regexp.MustCompile(
+	`^v?(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)\.(0|[1-9][0-9]*)` + // major.minor.patch
+		`(?:-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?$`, // pre-release and build metadata
+)
+
 // Get returns current build's info.
 func Get() *Info {
 	return info
 }
 
-func init() {
-	b := must.NotFail(gen.ReadFile("gen/mongodb.txt"))
-	parts := regexp.MustCompile(`^([0-9]+)\.([0-9]+)\.([0-9]+)$`).FindStringSubmatch(strings.TrimSpace(string(b)))
+// ParseSemVer returns major, minor, and patch components of the semantic version.
+func ParseSemVer(v string) (major, minor, patch int, err error) {
+	parts := semVerRe.FindStringSubmatch(v)
 	if len(parts) != 4 {
-		panic("invalid gen/mongodb.txt")
+		err = fmt.Errorf("version %q is not a valid semantic version", v)
+		return
 	}
-	major := must.NotFail(strconv.Atoi(parts[1]))
-	minor := must.NotFail(strconv.Atoi(parts[2]))
-	patch := must.NotFail(strconv.Atoi(parts[3]))
-	MongoDBVersion = fmt.Sprintf("%d.%d.%d", major, minor, patch)
-	MongoDBVersionArray = must.NotFail(types.NewArray(int32(major), int32(minor), int32(patch), int32(0)))
 
-	// those files are not present when FerretDB is used as library package
-	version := unknown
-	if b, _ := gen.ReadFile("gen/version.txt"); len(b) > 0 {
-		version = strings.TrimSpace(string(b))
-	}
-	commit := unknown
-	if b, _ := gen.ReadFile("gen/commit.txt"); len(b) > 0 {
-		commit = strings.TrimSpace(string(b))
-	}
-	branch := unknown
-	if b, _ := gen.ReadFile("gen/branch.txt"); len(b) > 0 {
-		branch = strings.TrimSpace(string(b))
+	major = must.NotFail(strconv.Atoi(parts[1]))
+	minor = must.NotFail(strconv.Atoi(parts[2]))
+	patch = must.NotFail(strconv.Atoi(parts[3]))
+
+	return
+}
+
+// readFile returns trimmed content of the given file, or unknown if it is not present or empty.
+func readFile(fsys fs.FS, name string) string {
+	b, _ := fs.ReadFile(fsys, name)
+	if s := strings.TrimSpace(string(b)); s != "" {
+		return s
 	}
 
-	info = &Info{
-		Version:          version,
-		Commit:           commit,
-		Branch:           branch,
+	return unknown
+}
+
+// newInfo returns build info from files in fsys and from Go build information.
+//
+// buildInfo is nil if Go build information is not available.
+func newInfo(fsys fs.FS, buildInfo *debug.BuildInfo) *Info {
+	// those files are not present when FerretDB is used as library package
+	res := &Info{
+		Version:          readFile(fsys, "gen/version.txt"),
+		Commit:           readFile(fsys, "gen/commit.txt"),
+		Branch:           readFile(fsys, "gen/branch.txt"),
+		Package:          readFile(fsys, "gen/package.txt"),
 		BuildEnvironment: must.NotFail(types.NewDocument()),
 	}
+	res.Dirty = strings.HasSuffix(res.Version, "-dirty")
+
+	if buildInfo == nil {
+		return res
+	}
 
 	// do not expose extra information when FerretDB is used as library package
-	buildInfo, ok := debug.ReadBuildInfo()
-	if !ok {
-		return
+	if buildInfo.Main.Path != modulePath {
+		for _, dep := range buildInfo.Deps {
+			if dep.Path == modulePath && res.Version == unknown {
+				res.Version = dep.Version
+			}
+		}
+
+		return res
 	}
-	if buildInfo.Main.Path != "github.com/FerretDB/FerretDB" {
-		return
+
+	// set by `go install github.com/FerretDB/FerretDB/cmd/ferretdb@version`
+	if v := buildInfo.Main.Version; v != "" && v != "(devel)" && res.Version == unknown {
+		res.Version = v
 	}
 
 	for _, s := range buildInfo.Settings {
-		must.NoError(info.BuildEnvironment.Set(s.Key, s.Value))
+		must.NoError(res.BuildEnvironment.Set(s.Key, s.Value))
 
 		switch s.Key {
 		case "vcs.revision":
-			if s.Value != info.Commit {
-				panic(fmt.Sprintf("commit.txt value %q != vcs.revision value %q\n"+
-					"Please run `bin/task gen-version`", info.Commit, s.Value,
-				))
+			// gen/ files were generated for another commit; do not report stale version and branch
+			if res.Commit != unknown && res.Commit != s.Value {
+				res.Version = unknown
+				res.Branch = unknown
+			}
+			res.Commit = s.Value
+		case "vcs.time":
+			if t, err := time.Parse(time.RFC3339, s.Value); err == nil {
+				res.CommitTime = t
 			}
 		case "vcs.modified":
-			info.Dirty = must.NotFail(strconv.ParseBool(s.Value))
+			res.Dirty = must.NotFail(strconv.ParseBool(s.Value))
 		case "-race":
 			if must.NotFail(strconv.ParseBool(s.Value)) {
-				info.Debug = true
+				res.Debug = true
 			}
 		case "-tags":
 			if slices.Contains(strings.Split(s.Value, ","), "ferretdb_testcover") {
-				info.Debug = true
+				res.Debug = true
 			}
 		}
 	}
+
+	return res
+}
+
+func init() {
+	b := must.NotFail(gen.ReadFile("gen/mongodb.txt"))
+	major, minor, patch, err := ParseSemVer(strings.TrimSpace(string(b)))
+	if err != nil {
+		panic(fmt.Sprintf("invalid gen/mongodb.txt: %s", err))
+	}
+	MongoDBVersion = fmt.Sprintf("%d.%d.%d", major, minor, patch)
+	MongoDBVersionArray = must.NotFail(types.NewArray(int32(major), int32(minor), int32(patch), int32(0)))
+
+	buildInfo, ok := debug.ReadBuildInfo()
+	if !ok {
+		buildInfo = nil
+	}
+
+	info = newInfo(gen, buildInfo)
 }
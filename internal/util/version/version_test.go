@@ -15,9 +15,13 @@
 package version
 
 import (
+	"runtime/debug"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/must"
@@ -31,3 +35,120 @@ func TestGet(t *testing.T) {
 	assert.Equal(t, "5.0.42", MongoDBVersion)
 	testutil.AssertEqual(t, must.NotFail(types.NewArray(int32(5), int32(0), int32(42), int32(0))), MongoDBVersionArray)
 }
+
+func TestNewInfo(t *testing.T) {
+	t.Parallel()
+
+	genFiles := fstest.MapFS{
+		"gen/version.txt": {Data: []byte("v0.5.3-dirty\n")},
+		"gen/commit.txt":  {Data: []byte("1111111111111111111111111111111111111111\n")},
+		"gen/branch.txt":  {Data: []byte("main\n")},
+		"gen/package.txt": {Data: []byte("deb\n")},
+	}
+
+	t.Run("NoBuildInfo", func(t *testing.T) {
+		t.Parallel()
+
+		actual := newInfo(genFiles, nil)
+		assert.Equal(t, "v0.5.3-dirty", actual.Version)
+		assert.Equal(t, "1111111111111111111111111111111111111111", actual.Commit)
+		assert.Equal(t, "main", actual.Branch)
+		assert.Equal(t, "deb", actual.Package)
+		assert.True(t, actual.Dirty)
+		assert.False(t, actual.Debug)
+		assert.Zero(t, actual.CommitTime)
+		assert.Zero(t, actual.BuildEnvironment.Len())
+	})
+
+	t.Run("NoBuildInfoNoFiles", func(t *testing.T) {
+		t.Parallel()
+
+		actual := newInfo(fstest.MapFS{}, nil)
+		assert.Equal(t, unknown, actual.Version)
+		assert.Equal(t, unknown, actual.Commit)
+		assert.Equal(t, unknown, actual.Branch)
+		assert.Equal(t, unknown, actual.Package)
+		assert.False(t, actual.Dirty)
+	})
+
+	t.Run("VCS", func(t *testing.T) {
+		t.Parallel()
+
+		actual := newInfo(fstest.MapFS{}, &debug.BuildInfo{
+			Main: debug.Module{Path: modulePath, Version: "v0.6.0"},
+			Settings: []debug.BuildSetting{
+				{Key: "-race", Value: "true"},
+				{Key: "vcs.revision", Value: "2222222222222222222222222222222222222222"},
+				{Key: "vcs.time", Value: "2022-08-19T10:00:00Z"},
+				{Key: "vcs.modified", Value: "true"},
+			},
+		})
+		assert.Equal(t, "v0.6.0", actual.Version)
+		assert.Equal(t, "2222222222222222222222222222222222222222", actual.Commit)
+		assert.Equal(t, time.Date(2022, 8, 19, 10, 0, 0, 0, time.UTC), actual.CommitTime)
+		assert.Equal(t, unknown, actual.Branch)
+		assert.True(t, actual.Dirty)
+		assert.True(t, actual.Debug)
+		assert.Equal(t, []string{"-race", "vcs.revision", "vcs.time", "vcs.modified"}, actual.BuildEnvironment.Keys())
+	})
+
+	t.Run("StaleFiles", func(t *testing.T) {
+		t.Parallel()
+
+		actual := newInfo(genFiles, &debug.BuildInfo{
+			Main: debug.Module{Path: modulePath, Version: "(devel)"},
+			Settings: []debug.BuildSetting{
+				{Key: "vcs.revision", Value: "2222222222222222222222222222222222222222"},
+				{Key: "vcs.modified", Value: "false"},
+			},
+		})
+		assert.Equal(t, unknown, actual.Version)
+		assert.Equal(t, "2222222222222222222222222222222222222222", actual.Commit)
+		assert.Equal(t, unknown, actual.Branch)
+		assert.False(t, actual.Dirty)
+	})
+
+	t.Run("Library", func(t *testing.T) {
+		t.Parallel()
+
+		actual := newInfo(fstest.MapFS{}, &debug.BuildInfo{
+			Main: debug.Module{Path: "example.com/app", Version: "(devel)"},
+			Deps: []*debug.Module{{Path: modulePath, Version: "v0.6.1"}},
+			Settings: []debug.BuildSetting{
+				{Key: "vcs.revision", Value: "3333333333333333333333333333333333333333"},
+			},
+		})
+		assert.Equal(t, "v0.6.1", actual.Version)
+		assert.Equal(t, unknown, actual.Commit)
+		assert.Zero(t, actual.BuildEnvironment.Len())
+	})
+}
+
+func TestParseSemVer(t *testing.T) {
+	t.Parallel()
+
+	for v, expected := range map[string][]int{
+		"5.0.42":                   {5, 0, 42},
+		"v0.5.3":                   {0, 5, 3},
+		"v0.5.3-12-g28ef5c7-dirty": {0, 5, 3},
+		"1.2.3+build.4":            {1, 2, 3},
+		"v1.2":                     nil,
+		"01.2.3":                   nil,
+		unknown:                    nil,
+		"(devel)":                  nil,
+	} {
+		v, expected := v, expected
+		t.Run(v, func(t *testing.T) {
+			t.Parallel()
+
+			major, minor, patch, err := ParseSemVer(v)
+			if expected == nil {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, expected, []int{major, minor, patch})
+		})
+	}
+}
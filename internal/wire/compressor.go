@@ -0,0 +1,300 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+//go:generate ../../bin/stringer -linecomment -type CompressorID
+
+// CompressorID represents OP_COMPRESSED compressor.
+type CompressorID uint8
+
+// Compressors.
+const (
+	// CompressorNoop is a compressor that does not compress messages.
+	CompressorNoop = CompressorID(0) // noop
+
+	// CompressorSnappy is a compressor that uses snappy block format.
+	CompressorSnappy = CompressorID(1) // snappy
+
+	// CompressorZlib is a compressor that uses zlib format.
+	CompressorZlib = CompressorID(2) // zlib
+
+	// CompressorZstd is a compressor that uses zstd format.
+	CompressorZstd = CompressorID(3) // zstd
+)
+
+// Compressor compresses and decompresses messages wrapped by OP_COMPRESSED.
+//
+// Implementations should be safe for concurrent use.
+type Compressor interface {
+	// ID returns compressor ID.
+	ID() CompressorID
+
+	// Compress returns compressed b.
+	Compress(b []byte) ([]byte, error)
+
+	// Decompress returns decompressed b.
+	// It returns an error if decompressed data size does not match uncompressedSize.
+	Decompress(b []byte, uncompressedSize int32) ([]byte, error)
+}
+
+var (
+	compressorsRW sync.RWMutex
+	compressors   = map[CompressorID]Compressor{
+		CompressorNoop:   noopCompressor{},
+		CompressorSnappy: snappyCompressor{},
+		CompressorZlib:   must.NotFail(NewZlibCompressor(zlib.DefaultCompression)),
+		CompressorZstd:   newZstdCompressor(),
+	}
+)
+
+// RegisterCompressor registers the given compressor, replacing the one with the same ID.
+func RegisterCompressor(c Compressor) {
+	compressorsRW.Lock()
+	defer compressorsRW.Unlock()
+
+	compressors[c.ID()] = c
+}
+
+// GetCompressor returns a registered compressor with the given ID.
+func GetCompressor(id CompressorID) (Compressor, bool) {
+	compressorsRW.RLock()
+	defer compressorsRW.RUnlock()
+
+	c, ok := compressors[id]
+	return c, ok
+}
+
+// NegotiableCompressors returns IDs of registered compressors that could be negotiated by the hello command.
+func NegotiableCompressors() []CompressorID {
+	compressorsRW.RLock()
+	defer compressorsRW.RUnlock()
+
+	// noop compressor is always supported, but never negotiated
+	res := make([]CompressorID, 0, len(compressors))
+	for _, id := range maps.Keys(compressors) {
+		if id != CompressorNoop {
+			res = append(res, id)
+		}
+	}
+
+	slices.Sort(res)
+
+	return res
+}
+
+// CompressorByName returns an ID of a negotiable compressor with the given name.
+func CompressorByName(name string) (CompressorID, bool) {
+	for _, id := range NegotiableCompressors() {
+		if id.String() == name {
+			return id, true
+		}
+	}
+
+	return 0, false
+}
+
+// compress returns b compressed with the given compressor.
+func compress(id CompressorID, b []byte) ([]byte, error) {
+	c, ok := GetCompressor(id)
+	if !ok {
+		return nil, lazyerrors.Errorf("wire.compress: unknown compressor %s", id)
+	}
+
+	res, err := c.Compress(b)
+	if err != nil {
+		return nil, lazyerrors.Errorf("wire.compress: %s: %w", id, err)
+	}
+
+	return res, nil
+}
+
+// decompress returns b decompressed with the given compressor.
+//
+// It returns an error if decompressed data size does not match uncompressedSize.
+func decompress(id CompressorID, b []byte, uncompressedSize int32) ([]byte, error) {
+	c, ok := GetCompressor(id)
+	if !ok {
+		return nil, lazyerrors.Errorf("wire.decompress: unknown compressor %s", id)
+	}
+
+	res, err := c.Decompress(b, uncompressedSize)
+	if err != nil {
+		return nil, lazyerrors.Errorf("wire.decompress: %s: %w", id, err)
+	}
+
+	if l := len(res); l != int(uncompressedSize) {
+		return nil, lazyerrors.Errorf("wire.decompress: uncompressed size is %d, expected %d", l, uncompressedSize)
+	}
+
+	return res, nil
+}
+
+// sizeMismatchError returns an error for decompressed data of unexpected size.
+func sizeMismatchError(actual, expected int) error {
+	return fmt.Errorf("uncompressed size is %d, expected %d", actual, expected)
+}
+
+// noopCompressor does not compress messages.
+type noopCompressor struct{}
+
+// ID implements Compressor interface.
+func (noopCompressor) ID() CompressorID { return CompressorNoop }
+
+// Compress implements Compressor interface.
+func (noopCompressor) Compress(b []byte) ([]byte, error) { return b, nil }
+
+// Decompress implements Compressor interface.
+func (noopCompressor) Decompress(b []byte, uncompressedSize int32) ([]byte, error) {
+	if len(b) != int(uncompressedSize) {
+		return nil, sizeMismatchError(len(b), int(uncompressedSize))
+	}
+
+	return b, nil
+}
+
+// snappyCompressor uses snappy block format.
+type snappyCompressor struct{}
+
+// ID implements Compressor interface.
+func (snappyCompressor) ID() CompressorID { return CompressorSnappy }
+
+// Compress implements Compressor interface.
+func (snappyCompressor) Compress(b []byte) ([]byte, error) { return snappy.Encode(nil, b), nil }
+
+// Decompress implements Compressor interface.
+func (snappyCompressor) Decompress(b []byte, uncompressedSize int32) ([]byte, error) {
+	// check size before allocating
+	l, err := snappy.DecodedLen(b)
+	if err != nil {
+		return nil, err
+	}
+	if l != int(uncompressedSize) {
+		return nil, sizeMismatchError(l, int(uncompressedSize))
+	}
+
+	return snappy.Decode(nil, b)
+}
+
+// zlibCompressor uses zlib format.
+type zlibCompressor struct {
+	level int
+}
+
+// NewZlibCompressor returns a new zlib compressor with the given compression level
+// (from zlib.HuffmanOnly to zlib.BestCompression).
+func NewZlibCompressor(level int) (Compressor, error) {
+	if _, err := zlib.NewWriterLevel(io.Discard, level); err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	return zlibCompressor{level: level}, nil
+}
+
+// ID implements Compressor interface.
+func (zlibCompressor) ID() CompressorID { return CompressorZlib }
+
+// Compress implements Compressor interface.
+func (c zlibCompressor) Compress(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w := must.NotFail(zlib.NewWriterLevel(&buf, c.level))
+	if _, err := w.Write(b); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Decompress implements Compressor interface.
+func (zlibCompressor) Decompress(b []byte, uncompressedSize int32) ([]byte, error) {
+	r, err := zlib.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	// read one more byte to detect bigger data without decompressing all of it
+	res, err := io.ReadAll(io.LimitReader(r, int64(uncompressedSize)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(res) != int(uncompressedSize) {
+		return nil, sizeMismatchError(len(res), int(uncompressedSize))
+	}
+
+	return res, nil
+}
+
+// zstdCompressor uses zstd format.
+type zstdCompressor struct {
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+}
+
+// newZstdCompressor returns a new zstd compressor.
+func newZstdCompressor() *zstdCompressor {
+	return &zstdCompressor{
+		encoder: must.NotFail(zstd.NewWriter(nil)),
+		decoder: must.NotFail(zstd.NewReader(nil, zstd.WithDecoderMaxMemory(MaxMsgLen))),
+	}
+}
+
+// ID implements Compressor interface.
+func (*zstdCompressor) ID() CompressorID { return CompressorZstd }
+
+// Compress implements Compressor interface.
+func (c *zstdCompressor) Compress(b []byte) ([]byte, error) {
+	return c.encoder.EncodeAll(b, nil), nil
+}
+
+// Decompress implements Compressor interface.
+func (c *zstdCompressor) Decompress(b []byte, uncompressedSize int32) ([]byte, error) {
+	res, err := c.decoder.DecodeAll(b, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(res) != int(uncompressedSize) {
+		return nil, sizeMismatchError(len(res), int(uncompressedSize))
+	}
+
+	return res, nil
+}
+
+// check interfaces
+var (
+	_ Compressor = noopCompressor{}
+	_ Compressor = snappyCompressor{}
+	_ Compressor = zlibCompressor{}
+	_ Compressor = (*zstdCompressor)(nil)
+)
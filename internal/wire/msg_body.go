@@ -17,6 +17,7 @@ package wire
 import (
 	"bufio"
 	"encoding"
+	"encoding/binary"
 	"fmt"
 	"io"
 
@@ -36,6 +37,8 @@ type MsgBody interface {
 //go-sumtype:decl MsgBody
 
 // ReadMessage reads from reader and returns wire header and body.
+//
+// OP_COMPRESSED messages are returned as is; use Decompress to get wrapped messages.
 func ReadMessage(r *bufio.Reader) (*MsgHeader, MsgBody, error) {
 	var header MsgHeader
 	if err := header.readFrom(r); err != nil {
@@ -50,30 +53,58 @@ func ReadMessage(r *bufio.Reader) (*MsgHeader, MsgBody, error) {
 		return nil, nil, lazyerrors.Errorf("expected %d, read %d: %w", len(b), n, err)
 	}
 
-	switch header.OpCode {
+	if header.OpCode == OpCodeCompressed {
+		var compressed OpCompressed
+		if err := compressed.UnmarshalBinary(b); err != nil {
+			return nil, nil, lazyerrors.Error(err)
+		}
+
+		return &header, &compressed, nil
+	}
+
+	body, err := unmarshalBody(&header, b)
+	if err != nil {
+		return nil, nil, lazyerrors.Error(err)
+	}
+
+	return &header, body, nil
+}
+
+// unmarshalBody returns a message body for the given header.
+//
+// OP_MSG checksum is verified if present.
+// For compressed messages, it should be called with the decompressed message and its header.
+func unmarshalBody(header *MsgHeader, b []byte) (MsgBody, error) {
+	switch opCode := header.OpCode; opCode {
 	case OpCodeReply: // not sent by clients, but we should be able to read replies from a proxy
 		var reply OpReply
 		if err := reply.UnmarshalBinary(b); err != nil {
-			return nil, nil, lazyerrors.Error(err)
+			return nil, lazyerrors.Error(err)
 		}
 
-		return &header, &reply, nil
+		return &reply, nil
 
 	case OpCodeMsg:
 		var msg OpMsg
 		if err := msg.UnmarshalBinary(b); err != nil {
-			return nil, nil, lazyerrors.Error(err)
+			return nil, lazyerrors.Error(err)
+		}
+
+		if msg.FlagBits.FlagSet(OpMsgChecksumPresent) {
+			if expected := msgChecksum(header, b); msg.Checksum != expected {
+				return nil, lazyerrors.Errorf("%w: got 0x%08x, expected 0x%08x", ErrChecksumMismatch, msg.Checksum, expected)
+			}
 		}
 
-		return &header, &msg, nil
+		return &msg, nil
 
 	case OpCodeQuery:
 		var query OpQuery
 		if err := query.UnmarshalBinary(b); err != nil {
-			return nil, nil, lazyerrors.Error(err)
+			return nil, lazyerrors.Error(err)
 		}
 
-		return &header, &query, nil
+		return &query, nil
 
 	case OpCodeUpdate:
 		fallthrough
@@ -88,16 +119,35 @@ func ReadMessage(r *bufio.Reader) (*MsgHeader, MsgBody, error) {
 	case OpCodeKillCursors:
 		fallthrough
 	case OpCodeCompressed:
-		return nil, nil, lazyerrors.Errorf("unhandled opcode %s", header.OpCode)
+		return nil, lazyerrors.Errorf("unhandled opcode %s", opCode)
 
 	default:
-		return nil, nil, lazyerrors.Errorf("unexpected opcode %s", header.OpCode)
+		return nil, lazyerrors.Errorf("unexpected opcode %s", opCode)
 	}
 }
 
+// marshalBody returns bytes of the given message body.
+//
+// OP_MSG checksum is generated if its flag is set.
+// For compressed messages, it should be called before compression.
+func marshalBody(header *MsgHeader, body MsgBody) ([]byte, error) {
+	b, err := body.MarshalBinary()
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	if msg, ok := body.(*OpMsg); ok && msg.FlagBits.FlagSet(OpMsgChecksumPresent) {
+		binary.LittleEndian.PutUint32(b[len(b)-4:], msgChecksum(header, b))
+	}
+
+	return b, nil
+}
+
 // WriteMessage validates msg and headers and writes them to the writer.
+//
+// OP_MSG checksum is generated if its flag is set.
 func WriteMessage(w *bufio.Writer, header *MsgHeader, msg MsgBody) error {
-	b, err := msg.MarshalBinary()
+	b, err := marshalBody(header, msg)
 	if err != nil {
 		return lazyerrors.Error(err)
 	}
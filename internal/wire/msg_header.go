@@ -18,6 +18,7 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
 
@@ -79,6 +80,9 @@ const (
 	MaxMsgLen = 48000000
 )
 
+// ErrMessageTooLarge is returned (possibly wrapped) when the message length in the header exceeds MaxMsgLen.
+var ErrMessageTooLarge = errors.New("message is too large")
+
 func (msg *MsgHeader) readFrom(r *bufio.Reader) error {
 	b := make([]byte, MsgHeaderLen)
 	if n, err := io.ReadFull(r, b); err != nil {
@@ -93,10 +97,15 @@ func (msg *MsgHeader) readFrom(r *bufio.Reader) error {
 	msg.ResponseTo = int32(binary.LittleEndian.Uint32(b[8:12]))
 	msg.OpCode = OpCode(binary.LittleEndian.Uint32(b[12:16]))
 
-	if msg.MessageLength < MsgHeaderLen || msg.MessageLength > MaxMsgLen {
+	if msg.MessageLength < MsgHeaderLen {
 		return lazyerrors.Errorf("invalid message length %d", msg.MessageLength)
 	}
 
+	// check before the body is allocated by the caller
+	if msg.MessageLength > MaxMsgLen {
+		return lazyerrors.Errorf("message length %d exceeds %d: %w", msg.MessageLength, MaxMsgLen, ErrMessageTooLarge)
+	}
+
 	return nil
 }
 
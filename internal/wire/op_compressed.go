@@ -0,0 +1,174 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+)
+
+// OpCompressed is a message that wraps another compressed message.
+type OpCompressed struct {
+	OriginalOpCode    OpCode
+	UncompressedSize  int32
+	CompressorID      CompressorID
+	CompressedMessage []byte
+}
+
+func (c *OpCompressed) msgbody() {}
+
+func (c *OpCompressed) readFrom(bufr *bufio.Reader) error {
+	if err := binary.Read(bufr, binary.LittleEndian, &c.OriginalOpCode); err != nil {
+		return lazyerrors.Errorf("wire.OpCompressed.ReadFrom (binary.Read): %w", err)
+	}
+	if err := binary.Read(bufr, binary.LittleEndian, &c.UncompressedSize); err != nil {
+		return lazyerrors.Errorf("wire.OpCompressed.ReadFrom (binary.Read): %w", err)
+	}
+	if err := binary.Read(bufr, binary.LittleEndian, &c.CompressorID); err != nil {
+		return lazyerrors.Errorf("wire.OpCompressed.ReadFrom (binary.Read): %w", err)
+	}
+
+	if c.OriginalOpCode == OpCodeCompressed {
+		return lazyerrors.New("wire.OpCompressed.ReadFrom: nested OP_COMPRESSED")
+	}
+
+	if s := c.UncompressedSize; s < 0 || s > MaxMsgLen-MsgHeaderLen {
+		return lazyerrors.Errorf("wire.OpCompressed.ReadFrom: invalid uncompressed size %d", s)
+	}
+
+	if _, ok := GetCompressor(c.CompressorID); !ok {
+		return lazyerrors.Errorf("wire.OpCompressed.ReadFrom: unknown compressor %s", c.CompressorID)
+	}
+
+	var err error
+	if c.CompressedMessage, err = io.ReadAll(bufr); err != nil {
+		return lazyerrors.Errorf("wire.OpCompressed.ReadFrom: %w", err)
+	}
+
+	return nil
+}
+
+// UnmarshalBinary reads an OpCompressed from a byte array.
+//
+// The compressed message is not decompressed; use Decompress for that.
+func (c *OpCompressed) UnmarshalBinary(b []byte) error {
+	br := bytes.NewReader(b)
+	bufr := bufio.NewReader(br)
+
+	if err := c.readFrom(bufr); err != nil {
+		return lazyerrors.Errorf("wire.OpCompressed.UnmarshalBinary: %w", err)
+	}
+
+	return nil
+}
+
+// MarshalBinary writes an OpCompressed to a byte array.
+func (c *OpCompressed) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+
+	must.NoError(binary.Write(&buf, binary.LittleEndian, c.OriginalOpCode))
+	must.NoError(binary.Write(&buf, binary.LittleEndian, c.UncompressedSize))
+	must.NoError(binary.Write(&buf, binary.LittleEndian, c.CompressorID))
+	must.NotFail(buf.Write(c.CompressedMessage))
+
+	return buf.Bytes(), nil
+}
+
+// String returns a string representation for logging.
+func (c *OpCompressed) String() string {
+	if c == nil {
+		return "<nil>"
+	}
+
+	m := map[string]any{
+		"OriginalOpCode":   c.OriginalOpCode,
+		"UncompressedSize": c.UncompressedSize,
+		"CompressorID":     c.CompressorID,
+		"CompressedSize":   len(c.CompressedMessage),
+	}
+
+	return string(must.NotFail(json.MarshalIndent(m, "", "  ")))
+}
+
+// Compress returns header and body of OP_COMPRESSED message that wraps the given message.
+//
+// OP_MSG checksum, if its flag is set, is generated for the original message (with the given header)
+// before compression, and stored inside the compressed message.
+// OP_COMPRESSED itself has no checksum: checksumPresent is an OP_MSG flag,
+// and the wire protocol compresses the whole original message after the header, including its checksum.
+func Compress(header *MsgHeader, body MsgBody, compressor CompressorID) (*MsgHeader, *OpCompressed, error) {
+	b, err := marshalBody(header, body)
+	if err != nil {
+		return nil, nil, lazyerrors.Error(err)
+	}
+
+	compressed, err := compress(compressor, b)
+	if err != nil {
+		return nil, nil, lazyerrors.Error(err)
+	}
+
+	resBody := &OpCompressed{
+		OriginalOpCode:    header.OpCode,
+		UncompressedSize:  int32(len(b)),
+		CompressorID:      compressor,
+		CompressedMessage: compressed,
+	}
+
+	resHeader := &MsgHeader{
+		MessageLength: int32(MsgHeaderLen + 9 + len(compressed)),
+		RequestID:     header.RequestID,
+		ResponseTo:    header.ResponseTo,
+		OpCode:        OpCodeCompressed,
+	}
+
+	return resHeader, resBody, nil
+}
+
+// Decompress returns header and body of the message wrapped by the given OP_COMPRESSED message.
+//
+// It returns an error if the compressor is unknown or if the wrapped message is invalid.
+// OP_MSG checksum, if present, is verified for the original decompressed message
+// with the header reconstructed from the OP_COMPRESSED header (see Compress).
+func Decompress(header *MsgHeader, body *OpCompressed) (*MsgHeader, MsgBody, error) {
+	b, err := decompress(body.CompressorID, body.CompressedMessage, body.UncompressedSize)
+	if err != nil {
+		return nil, nil, lazyerrors.Error(err)
+	}
+
+	resHeader := &MsgHeader{
+		MessageLength: int32(MsgHeaderLen + len(b)),
+		RequestID:     header.RequestID,
+		ResponseTo:    header.ResponseTo,
+		OpCode:        body.OriginalOpCode,
+	}
+
+	resBody, err := unmarshalBody(resHeader, b)
+	if err != nil {
+		return nil, nil, lazyerrors.Error(err)
+	}
+
+	return resHeader, resBody, nil
+}
+
+// check interfaces
+var (
+	_ MsgBody = (*OpCompressed)(nil)
+)
@@ -0,0 +1,322 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/types"
+	"github.com/FerretDB/FerretDB/internal/util/must"
+	"github.com/FerretDB/FerretDB/internal/util/testutil"
+)
+
+// makeCompressed returns OP_COMPRESSED message bytes with the given fields.
+func makeCompressed(requestID int32, originalOpCode OpCode, uncompressedSize int32, compressor CompressorID, data []byte) []byte { //nolint:lll // argument list is too long
+	b := make([]byte, MsgHeaderLen+9, MsgHeaderLen+9+len(data))
+	b = append(b, data...)
+
+	binary.LittleEndian.PutUint32(b[0:4], uint32(len(b)))
+	binary.LittleEndian.PutUint32(b[4:8], uint32(requestID))
+	binary.LittleEndian.PutUint32(b[12:16], uint32(OpCodeCompressed))
+	binary.LittleEndian.PutUint32(b[16:20], uint32(originalOpCode))
+	binary.LittleEndian.PutUint32(b[20:24], uint32(uncompressedSize))
+	b[24] = byte(compressor)
+
+	return b
+}
+
+// importBody is the body of the message in import.hex.
+var importBody = testutil.MustParseDumpFile("testdata", "import.hex")[MsgHeaderLen:]
+
+// driverSnappyB is the ping command sent by the Go driver with snappy compressor.
+var driverSnappyB = testutil.MustParseDumpFile("testdata", "compressed_snappy.hex")
+
+var compressedTestCases = []testCase{{
+	name:      "DriverSnappy",
+	expectedB: driverSnappyB,
+	msgHeader: &MsgHeader{
+		MessageLength: int32(len(driverSnappyB)),
+		RequestID:     4,
+		OpCode:        OpCodeCompressed,
+	},
+	msgBody: &OpCompressed{
+		OriginalOpCode:    OpCodeMsg,
+		UncompressedSize:  35,
+		CompressorID:      CompressorSnappy,
+		CompressedMessage: driverSnappyB[MsgHeaderLen+9:],
+	},
+}, {
+	name:      "Snappy",
+	expectedB: makeCompressed(7, OpCodeMsg, int32(len(importBody)), CompressorSnappy, snappyEncode(importBody)),
+	msgHeader: &MsgHeader{
+		MessageLength: int32(MsgHeaderLen + 9 + len(snappyEncode(importBody))),
+		RequestID:     7,
+		OpCode:        OpCodeCompressed,
+	},
+	msgBody: &OpCompressed{
+		OriginalOpCode:    OpCodeMsg,
+		UncompressedSize:  int32(len(importBody)),
+		CompressorID:      CompressorSnappy,
+		CompressedMessage: snappyEncode(importBody),
+	},
+}, {
+	name:      "Noop",
+	expectedB: makeCompressed(8, OpCodeMsg, int32(len(importBody)), CompressorNoop, importBody),
+	msgHeader: &MsgHeader{
+		MessageLength: int32(MsgHeaderLen + 9 + len(importBody)),
+		RequestID:     8,
+		OpCode:        OpCodeCompressed,
+	},
+	msgBody: &OpCompressed{
+		OriginalOpCode:    OpCodeMsg,
+		UncompressedSize:  int32(len(importBody)),
+		CompressorID:      CompressorNoop,
+		CompressedMessage: importBody,
+	},
+}, {
+	name:      "UnknownCompressor",
+	expectedB: makeCompressed(9, OpCodeMsg, int32(len(importBody)), CompressorID(42), importBody),
+	err:       `wire.OpCompressed.ReadFrom: unknown compressor CompressorID(42)`,
+}, {
+	name:      "Nested",
+	expectedB: makeCompressed(10, OpCodeCompressed, int32(len(importBody)), CompressorNoop, importBody),
+	err:       `wire.OpCompressed.ReadFrom: nested OP_COMPRESSED`,
+}, {
+	name:      "InvalidSize",
+	expectedB: makeCompressed(11, OpCodeMsg, -1, CompressorNoop, importBody),
+	err:       `wire.OpCompressed.ReadFrom: invalid uncompressed size -1`,
+}}
+
+// allCompressors returns IDs of all registered compressors, including noop.
+func allCompressors() []CompressorID {
+	return append([]CompressorID{CompressorNoop}, NegotiableCompressors()...)
+}
+
+// snappyEncode returns b compressed with snappy.
+func snappyEncode(b []byte) []byte {
+	return must.NotFail(compress(CompressorSnappy, b))
+}
+
+func TestCompressed(t *testing.T) {
+	t.Parallel()
+	testMessages(t, compressedTestCases)
+}
+
+func TestCompressDecompress(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Driver", func(t *testing.T) {
+		t.Parallel()
+
+		header, body, err := Decompress(compressedTestCases[0].msgHeader, compressedTestCases[0].msgBody.(*OpCompressed))
+		require.NoError(t, err)
+
+		expectedHeader := &MsgHeader{
+			MessageLength: MsgHeaderLen + 35,
+			RequestID:     4,
+			OpCode:        OpCodeMsg,
+		}
+		assert.Equal(t, expectedHeader, header)
+
+		msg := body.(*OpMsg)
+		assert.Zero(t, msg.FlagBits)
+
+		doc, err := msg.Document()
+		require.NoError(t, err)
+		testutil.AssertEqual(t, must.NotFail(types.NewDocument("ping", int32(1), "$db", "admin")), doc)
+
+		compressedHeader, compressedBody, err := Compress(header, body, CompressorSnappy)
+		require.NoError(t, err)
+		assert.Equal(t, compressedTestCases[0].msgHeader, compressedHeader)
+		assert.Equal(t, compressedTestCases[0].msgBody, compressedBody)
+	})
+
+	var expectedHeader MsgHeader
+	require.NoError(t, expectedHeader.readFrom(bufio.NewReader(bytes.NewReader(msgTestCases[2].expectedB))))
+	expectedBody := msgTestCases[2].msgBody
+
+	for _, compressor := range allCompressors() {
+		compressor := compressor
+		t.Run(compressor.String(), func(t *testing.T) {
+			t.Parallel()
+
+			header, body, err := Compress(&expectedHeader, expectedBody, compressor)
+			require.NoError(t, err)
+			assert.Equal(t, OpCodeCompressed, header.OpCode)
+			assert.Equal(t, expectedHeader.RequestID, header.RequestID)
+
+			actualHeader, actualBody, err := Decompress(header, body)
+			require.NoError(t, err)
+			assert.Equal(t, &expectedHeader, actualHeader)
+			assert.Equal(t, expectedBody, actualBody)
+		})
+	}
+
+	t.Run("SizeMismatch", func(t *testing.T) {
+		t.Parallel()
+
+		for _, compressor := range allCompressors() {
+			_, body, err := Compress(&expectedHeader, expectedBody, compressor)
+			require.NoError(t, err)
+
+			body.UncompressedSize++
+			_, _, err = Decompress(&expectedHeader, body)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "uncompressed size is")
+		}
+	})
+
+	t.Run("Corrupted", func(t *testing.T) {
+		t.Parallel()
+
+		body := &OpCompressed{
+			OriginalOpCode:    OpCodeMsg,
+			UncompressedSize:  3,
+			CompressorID:      CompressorSnappy,
+			CompressedMessage: []byte{0x03, 0xff, 0xff},
+		}
+		_, _, err := Decompress(&expectedHeader, body)
+		require.Error(t, err)
+	})
+}
+
+func TestCompressLarge(t *testing.T) {
+	t.Parallel()
+
+	// close to the maximum document size, with both compressible and random-looking data
+	b := make([]byte, types.MaxDocumentLen-1024)
+	for i := range b {
+		if i%2 == 0 {
+			b[i] = byte(i * 7919 >> 3)
+		}
+	}
+
+	var msg OpMsg
+	require.NoError(t, msg.SetSections(OpMsgSection{
+		Documents: []*types.Document{must.NotFail(types.NewDocument(
+			"insert", "values",
+			"documents", must.NotFail(types.NewArray(must.NotFail(types.NewDocument(
+				"_id", int32(1),
+				"v", types.Binary{B: b},
+			)))),
+			"$db", "test",
+		))},
+	}))
+
+	msgB, err := msg.MarshalBinary()
+	require.NoError(t, err)
+
+	header := &MsgHeader{
+		MessageLength: int32(MsgHeaderLen + len(msgB)),
+		RequestID:     1,
+		OpCode:        OpCodeMsg,
+	}
+
+	for _, compressor := range allCompressors() {
+		compressor := compressor
+		t.Run(compressor.String(), func(t *testing.T) {
+			t.Parallel()
+
+			compressedHeader, compressedBody, err := Compress(header, &msg, compressor)
+			require.NoError(t, err)
+
+			// round-trip through the wire
+			var buf bytes.Buffer
+			bufw := bufio.NewWriter(&buf)
+			require.NoError(t, WriteMessage(bufw, compressedHeader, compressedBody))
+			require.NoError(t, bufw.Flush())
+
+			readHeader, readBody, err := ReadMessage(bufio.NewReader(&buf))
+			require.NoError(t, err)
+			require.IsType(t, new(OpCompressed), readBody)
+
+			actualHeader, actualBody, err := Decompress(readHeader, readBody.(*OpCompressed))
+			require.NoError(t, err)
+			assert.Equal(t, header, actualHeader)
+
+			actualB, err := actualBody.MarshalBinary()
+			require.NoError(t, err)
+			assert.Equal(t, msgB, actualB)
+		})
+	}
+}
+
+func TestDecompressTruncated(t *testing.T) {
+	t.Parallel()
+
+	for _, compressor := range NegotiableCompressors() {
+		compressor := compressor
+		t.Run(compressor.String(), func(t *testing.T) {
+			t.Parallel()
+
+			compressed := must.NotFail(compress(compressor, importBody))
+
+			for _, l := range []int{0, 1, len(compressed) / 2, len(compressed) - 1} {
+				body := &OpCompressed{
+					OriginalOpCode:    OpCodeMsg,
+					UncompressedSize:  int32(len(importBody)),
+					CompressorID:      compressor,
+					CompressedMessage: compressed[:l],
+				}
+
+				_, _, err := Decompress(new(MsgHeader), body)
+				assert.Error(t, err, "length %d", l)
+			}
+		})
+	}
+}
+
+func FuzzCompressed(f *testing.F) {
+	fuzzMessages(f, compressedTestCases)
+}
+
+func FuzzDecompress(f *testing.F) {
+	for _, tc := range compressedTestCases {
+		f.Add(tc.expectedB, uint16(0))
+	}
+
+	for _, compressor := range NegotiableCompressors() {
+		compressed := must.NotFail(compress(compressor, importBody))
+		b := makeCompressed(1, OpCodeMsg, int32(len(importBody)), compressor, compressed)
+		f.Add(b, uint16(0))
+		f.Add(b, uint16(len(compressed)/2))
+	}
+
+	f.Fuzz(func(t *testing.T, b []byte, truncate uint16) {
+		t.Parallel()
+
+		header, body, err := ReadMessage(bufio.NewReader(bytes.NewReader(b)))
+		if err != nil {
+			t.Skip()
+		}
+
+		compressed, ok := body.(*OpCompressed)
+		if !ok {
+			t.Skip()
+		}
+
+		if l := len(compressed.CompressedMessage); int(truncate) < l {
+			compressed.CompressedMessage = compressed.CompressedMessage[:l-int(truncate)]
+		}
+
+		assert.NotPanics(t, func() { _, _, _ = Decompress(header, compressed) })
+	})
+}
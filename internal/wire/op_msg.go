@@ -19,7 +19,9 @@ import (
 	"bytes"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"hash/crc32"
 	"io"
 
 	"github.com/FerretDB/FerretDB/internal/bson"
@@ -29,6 +31,43 @@ import (
 	"github.com/FerretDB/FerretDB/internal/util/must"
 )
 
+// ErrChecksumMismatch is returned (possibly wrapped) when OP_MSG checksum is invalid.
+var ErrChecksumMismatch = errors.New("OP_MSG checksum mismatch")
+
+// DocumentTooLargeError describes a document of a kind 1 section (document sequence)
+// that is larger than types.MaxDocumentLen.
+//
+// Such documents are skipped while reading the message instead of failing it,
+// so handlers (like insert) could report them as write errors for individual documents.
+type DocumentTooLargeError struct {
+	Identifier string // section identifier
+	Index      int    // document index in the section
+	Size       int32  // document size in bytes
+}
+
+// Error implements error interface.
+func (e *DocumentTooLargeError) Error() string {
+	return fmt.Sprintf(
+		"BSONObj size: %[1]d (0x%[1]X) is invalid. Size must be between 0 and %[2]d(16MB)",
+		e.Size, types.MaxDocumentLen,
+	)
+}
+
+// crc32cTable is a table for OP_MSG checksums.
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// msgChecksum returns CRC-32C checksum of OP_MSG message with the given header and body bytes.
+//
+// The checksum covers the whole message, except the checksum itself (the last 4 body bytes).
+// The header's MessageLength is not used; it is computed from the body length.
+func msgChecksum(header *MsgHeader, b []byte) uint32 {
+	h := *header
+	h.MessageLength = int32(MsgHeaderLen + len(b))
+
+	c := crc32.Update(0, crc32cTable, must.NotFail(h.MarshalBinary()))
+	return crc32.Update(c, crc32cTable, b[:len(b)-4])
+}
+
 // OpMsgSection is one or more sections contained in an OpMsg.
 type OpMsgSection struct {
 	Kind       byte
@@ -41,12 +80,14 @@ type OpMsg struct {
 	FlagBits OpMsgFlags
 	Checksum uint32
 
-	sections []OpMsgSection
+	sections  []OpMsgSection
+	oversized []*DocumentTooLargeError
 }
 
 // SetSections of the OpMsg.
 func (msg *OpMsg) SetSections(sections ...OpMsgSection) error {
 	msg.sections = sections
+	msg.oversized = nil
 	_, err := msg.Document()
 	if err != nil {
 		return lazyerrors.Error(err)
@@ -54,8 +95,40 @@ func (msg *OpMsg) SetSections(sections ...OpMsgSection) error {
 	return nil
 }
 
+// Sections returns a copy of the OpMsg sections.
+func (msg *OpMsg) Sections() []OpMsgSection {
+	return append([]OpMsgSection(nil), msg.sections...)
+}
+
 // Document returns the value of msg as a types.Document.
+//
+// Documents of kind 1 sections (document sequences) are added to the document
+// of the single kind 0 section (body) as arrays with sections' identifiers as keys.
+// Sections may be in any order.
+//
+// If any document of kind 1 sections is too large, *DocumentTooLargeError is returned.
 func (msg *OpMsg) Document() (*types.Document, error) {
+	if len(msg.oversized) > 0 {
+		return nil, msg.oversized[0]
+	}
+
+	return msg.document()
+}
+
+// DocumentWithOversized is like Document, but does not fail on too large documents of kind 1 sections.
+// They are returned separately and replaced with empty documents in the returned document,
+// so the caller should not use them.
+func (msg *OpMsg) DocumentWithOversized() (*types.Document, []*DocumentTooLargeError, error) {
+	doc, err := msg.document()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return doc, msg.oversized, nil
+}
+
+// document implements Document and DocumentWithOversized.
+func (msg *OpMsg) document() (*types.Document, error) {
 	var doc *types.Document
 
 	for _, section := range msg.sections {
@@ -76,13 +149,27 @@ func (msg *OpMsg) Document() (*types.Document, error) {
 				doc.Set(k, m[k])
 			}
 
+		case 1:
+			// handled below
+
+		default:
+			return nil, lazyerrors.Errorf("wire.OpMsg.Document: unknown kind %d", section.Kind)
+		}
+	}
+
+	if doc == nil {
+		return nil, lazyerrors.New("wire.OpMsg.Document: no kind 0 section")
+	}
+
+	for _, section := range msg.sections {
+		switch section.Kind {
+		case 0:
+			// handled above
+
 		case 1:
 			if section.Identifier == "" {
 				return nil, lazyerrors.New("wire.OpMsg.Document: empty section identifier")
 			}
-			if doc == nil {
-				return nil, lazyerrors.New("wire.OpMsg.Document: doc is empty")
-			}
 
 			m := doc.Map()
 			if _, ok := m[section.Identifier]; ok {
@@ -97,9 +184,6 @@ func (msg *OpMsg) Document() (*types.Document, error) {
 			}
 
 			doc.Set(section.Identifier, a)
-
-		default:
-			return nil, lazyerrors.Errorf("wire.OpMsg.Document: unknown kind %d", section.Kind)
 		}
 	}
 
@@ -142,27 +226,48 @@ func (msg *OpMsg) readFrom(bufr *bufio.Reader) error {
 				return lazyerrors.Errorf("wire.OpMsg.readFrom: invalid kind 1 section length %d", secSize)
 			}
 
-			sec := make([]byte, secSize-4)
-			if n, err := io.ReadFull(bufr, sec); err != nil {
-				return lazyerrors.Errorf("expected %d, read %d: %w", len(sec), n, err)
+			// do not preallocate the whole section, as its length is not validated yet
+			var sec bytes.Buffer
+			if n, err := io.CopyN(&sec, bufr, int64(secSize-4)); err != nil {
+				return lazyerrors.Errorf(
+					"wire.OpMsg.readFrom: kind 1 section length is %d, but only %d bytes left", secSize, n+4,
+				)
 			}
 
-			secr := bufio.NewReader(bytes.NewReader(sec))
+			secr := bufio.NewReader(&sec)
 
 			var id bson.CString
 			if err := id.ReadFrom(secr); err != nil {
-				return lazyerrors.Error(err)
+				return lazyerrors.New("wire.OpMsg.readFrom: kind 1 section identifier is not null-terminated")
 			}
 			section.Identifier = string(id)
 
-			for {
+			for i := 0; ; i++ {
 				if _, err := secr.Peek(1); err == io.EOF {
 					break
 				}
 
+				// skip too large document and leave an empty one in its place, see DocumentTooLargeError
+				if b, err := secr.Peek(4); err == nil {
+					if l := int32(binary.LittleEndian.Uint32(b)); l > types.MaxDocumentLen {
+						if _, err := secr.Discard(int(l)); err != nil {
+							return lazyerrors.Errorf("wire.OpMsg.readFrom: invalid document in kind 1 section %q: %w", id, err)
+						}
+
+						msg.oversized = append(msg.oversized, &DocumentTooLargeError{
+							Identifier: section.Identifier,
+							Index:      i,
+							Size:       l,
+						})
+						section.Documents = append(section.Documents, must.NotFail(types.NewDocument()))
+
+						continue
+					}
+				}
+
 				var doc bson.Document
 				if err := doc.ReadFrom(secr); err != nil {
-					return lazyerrors.Error(err)
+					return lazyerrors.Errorf("wire.OpMsg.readFrom: invalid document in kind 1 section %q: %w", id, err)
 				}
 
 				d, err := types.ConvertDocument(&doc)
@@ -193,11 +298,11 @@ func (msg *OpMsg) readFrom(bufr *bufio.Reader) error {
 		}
 	}
 
-	if _, err := msg.Document(); err != nil {
+	if _, err := msg.document(); err != nil {
 		return lazyerrors.Error(err)
 	}
 
-	// TODO validate checksum
+	// checksum is validated by the caller, as it covers the header too
 
 	return nil
 }
@@ -242,7 +347,7 @@ func (msg *OpMsg) MarshalBinary() ([]byte, error) {
 			if err != nil {
 				return nil, lazyerrors.Error(err)
 			}
-			if d.WriteTo(bufw); err != nil {
+			if err = d.WriteTo(bufw); err != nil {
 				return nil, lazyerrors.Error(err)
 			}
 
@@ -259,7 +364,7 @@ func (msg *OpMsg) MarshalBinary() ([]byte, error) {
 				if err != nil {
 					return nil, lazyerrors.Error(err)
 				}
-				if d.WriteTo(secw); err != nil {
+				if err = d.WriteTo(secw); err != nil {
 					return nil, lazyerrors.Error(err)
 				}
 			}
@@ -15,8 +15,17 @@
 package wire
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FerretDB/FerretDB/internal/bson"
 	"github.com/FerretDB/FerretDB/internal/types"
 	"github.com/FerretDB/FerretDB/internal/util/must"
 	"github.com/FerretDB/FerretDB/internal/util/testutil"
@@ -153,11 +162,416 @@ var msgTestCases = []testCase{{
 	err:       `wire.OpMsg.readFrom: invalid kind 1 section length -13619152`,
 }}
 
+// makeMsg returns OP_MSG message bytes with the given request ID and sections' bytes.
+func makeMsg(requestID int32, sections ...[]byte) []byte {
+	b := make([]byte, MsgHeaderLen+4) // header and zero flags
+	for _, section := range sections {
+		b = append(b, section...)
+	}
+
+	binary.LittleEndian.PutUint32(b[0:4], uint32(len(b)))
+	binary.LittleEndian.PutUint32(b[4:8], uint32(requestID))
+	binary.LittleEndian.PutUint32(b[12:16], uint32(OpCodeMsg))
+
+	return b
+}
+
+// makeChecksumMsg returns OP_MSG message bytes with the checksumPresent flag, sections' bytes and valid checksum.
+//
+// It uses the same CRC-32C implementation as the code under test, so it should be used only for fuzzing seeds;
+// see msg_checksum.hex for the known-good message.
+func makeChecksumMsg(requestID int32, sections ...[]byte) []byte {
+	b := makeMsg(requestID, append(sections, []byte{0, 0, 0, 0})...)
+	binary.LittleEndian.PutUint32(b[MsgHeaderLen:], uint32(OpMsgChecksumPresent))
+
+	c := crc32.Checksum(b[:len(b)-4], crc32.MakeTable(crc32.Castagnoli))
+	binary.LittleEndian.PutUint32(b[len(b)-4:], c)
+
+	return b
+}
+
+// makeBody returns kind 0 section bytes for the given document.
+func makeBody(doc *types.Document) []byte {
+	b := must.NotFail(must.NotFail(bson.ConvertDocument(doc)).MarshalBinary())
+	return append([]byte{0}, b...)
+}
+
+// makeSequence returns kind 1 section bytes with the given length, identifier bytes, and documents' bytes.
+// If length is zero, the correct length is used.
+func makeSequence(length int32, identifier []byte, docs ...[]byte) []byte {
+	b := append([]byte{1, 0, 0, 0, 0}, identifier...)
+	for _, doc := range docs {
+		b = append(b, doc...)
+	}
+
+	if length == 0 {
+		length = int32(len(b) - 1)
+	}
+
+	binary.LittleEndian.PutUint32(b[1:5], uint32(length))
+
+	return b
+}
+
+var (
+	sequenceBody = must.NotFail(types.NewDocument("insert", "values", "$db", "test"))
+	sequenceDoc1 = must.NotFail(types.NewDocument("_id", int32(1)))
+	sequenceDoc2 = must.NotFail(types.NewDocument("_id", int32(2), "v", "foo"))
+
+	sequenceDoc1B = must.NotFail(must.NotFail(bson.ConvertDocument(sequenceDoc1)).MarshalBinary())
+	sequenceDoc2B = must.NotFail(must.NotFail(bson.ConvertDocument(sequenceDoc2)).MarshalBinary())
+)
+
+var msgSequenceTestCases = []testCase{{
+	name: "SequenceFirst",
+	expectedB: makeMsg(
+		1,
+		makeSequence(0, []byte("documents\x00"), sequenceDoc1B, sequenceDoc2B),
+		makeBody(sequenceBody),
+	),
+	msgHeader: &MsgHeader{
+		MessageLength: 113,
+		RequestID:     1,
+		OpCode:        OpCodeMsg,
+	},
+	msgBody: &OpMsg{
+		sections: []OpMsgSection{{
+			Kind:       1,
+			Identifier: "documents",
+			Documents:  []*types.Document{sequenceDoc1, sequenceDoc2},
+		}, {
+			Documents: []*types.Document{sequenceBody},
+		}},
+	},
+}, {
+	name: "SequenceEmpty",
+	expectedB: makeMsg(
+		2,
+		makeBody(sequenceBody),
+		makeSequence(0, []byte("documents\x00")),
+	),
+	msgHeader: &MsgHeader{
+		MessageLength: 74,
+		RequestID:     2,
+		OpCode:        OpCodeMsg,
+	},
+	msgBody: &OpMsg{
+		sections: []OpMsgSection{{
+			Documents: []*types.Document{sequenceBody},
+		}, {
+			Kind:       1,
+			Identifier: "documents",
+		}},
+	},
+}, {
+	name: "SequenceTooLong",
+	expectedB: makeMsg(
+		3,
+		makeBody(sequenceBody),
+		makeSequence(1000, []byte("documents\x00"), sequenceDoc1B),
+	),
+	err: `wire.OpMsg.readFrom: kind 1 section length is 1000, but only 28 bytes left`,
+}, {
+	name: "SequenceTooShort",
+	expectedB: makeMsg(
+		4,
+		makeBody(sequenceBody),
+		makeSequence(20, []byte("documents\x00"), sequenceDoc1B),
+	),
+	err: `unexpected EOF`,
+}, {
+	name: "SequenceNoTerminator",
+	expectedB: makeMsg(
+		5,
+		makeBody(sequenceBody),
+		makeSequence(0, []byte("documents")),
+	),
+	err: `wire.OpMsg.readFrom: kind 1 section identifier is not null-terminated`,
+}, {
+	name: "SequenceNoBody",
+	expectedB: makeMsg(
+		6,
+		makeSequence(0, []byte("documents\x00"), sequenceDoc1B),
+	),
+	err: `wire.OpMsg.Document: no kind 0 section`,
+}, {
+	name: "SequenceDuplicate",
+	expectedB: makeMsg(
+		7,
+		makeBody(sequenceBody),
+		makeSequence(0, []byte("documents\x00"), sequenceDoc1B),
+		makeSequence(0, []byte("documents\x00"), sequenceDoc2B),
+	),
+	err: `wire.OpMsg.Document: doc already has "documents" key`,
+}}
+
+var (
+	checksumBody = must.NotFail(types.NewDocument("ping", int32(1), "$db", "admin"))
+
+	// checksumB is the ping command, as sent by the Go driver (see compressed_snappy.hex),
+	// without compression, with checksumPresent flag and checksum calculated by an independent implementation.
+	checksumB = testutil.MustParseDumpFile("testdata", "msg_checksum.hex")
+
+	badChecksumB = func() []byte {
+		b := testutil.MustParseDumpFile("testdata", "msg_checksum.hex")
+		b[len(b)-1]++
+		return b
+	}()
+)
+
+var msgChecksumTestCases = []testCase{{
+	name:      "Checksum",
+	expectedB: checksumB,
+	msgHeader: &MsgHeader{
+		MessageLength: int32(len(checksumB)),
+		RequestID:     5,
+		OpCode:        OpCodeMsg,
+	},
+	msgBody: &OpMsg{
+		FlagBits: OpMsgFlags(OpMsgChecksumPresent),
+		Checksum: 0x91a515b3,
+		sections: []OpMsgSection{{
+			Documents: []*types.Document{checksumBody},
+		}},
+	},
+}, {
+	name:      "BadChecksum",
+	expectedB: badChecksumB,
+	err:       `OP_MSG checksum mismatch`,
+}}
+
 func TestMsg(t *testing.T) {
 	t.Parallel()
 	testMessages(t, msgTestCases)
 }
 
+func TestMsgSequences(t *testing.T) {
+	t.Parallel()
+	testMessages(t, msgSequenceTestCases)
+
+	t.Run("Document", func(t *testing.T) {
+		t.Parallel()
+
+		var msg OpMsg
+		err := msg.UnmarshalBinary(msgSequenceTestCases[0].expectedB[MsgHeaderLen:])
+		require.NoError(t, err)
+
+		assert.Len(t, msg.Sections(), 2)
+
+		doc, err := msg.Document()
+		require.NoError(t, err)
+
+		expected := must.NotFail(types.NewDocument(
+			"insert", "values",
+			"$db", "test",
+			"documents", must.NotFail(types.NewArray(sequenceDoc1, sequenceDoc2)),
+		))
+		assert.Equal(t, expected, doc)
+
+		// body document is not modified
+		assert.Equal(t, []string{"insert", "$db"}, sequenceBody.Keys())
+	})
+}
+
+func TestMsgChecksum(t *testing.T) {
+	t.Parallel()
+	testMessages(t, msgChecksumTestCases)
+
+	t.Run("CRC32C", func(t *testing.T) {
+		t.Parallel()
+
+		// check value from RFC 3720, B.4
+		assert.Equal(t, uint32(0xe3069283), crc32.Checksum([]byte("123456789"), crc32cTable))
+	})
+
+	t.Run("Compressed", func(t *testing.T) {
+		t.Parallel()
+
+		header := msgChecksumTestCases[0].msgHeader
+		body := msgChecksumTestCases[0].msgBody
+
+		for _, compressor := range allCompressors() {
+			compressedHeader, compressedBody, err := Compress(header, body, compressor)
+			require.NoError(t, err)
+
+			actualHeader, actualBody, err := Decompress(compressedHeader, compressedBody)
+			require.NoError(t, err)
+			assert.Equal(t, header, actualHeader)
+			assert.Equal(t, body, actualBody)
+
+			// corrupt the checksum of the original message
+			compressedBody.CompressedMessage = must.NotFail(compress(compressor, badChecksumB[MsgHeaderLen:]))
+			compressedBody.UncompressedSize = int32(len(badChecksumB) - MsgHeaderLen)
+
+			_, _, err = Decompress(compressedHeader, compressedBody)
+			assert.True(t, errors.Is(err, ErrChecksumMismatch), "%s: %v", compressor, err)
+		}
+	})
+
+	t.Run("CompressedFixture", func(t *testing.T) {
+		t.Parallel()
+
+		// the checksum belongs to the wrapped OP_MSG and covers it with its own header,
+		// so it is stored inside the compressed message
+		expectedB := testutil.MustParseDumpFile("testdata", "compressed_checksum.hex")
+
+		header := msgChecksumTestCases[0].msgHeader
+		body := msgChecksumTestCases[0].msgBody
+
+		compressedHeader, compressedBody, err := Compress(header, body, CompressorNoop)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		bufw := bufio.NewWriter(&buf)
+		require.NoError(t, WriteMessage(bufw, compressedHeader, compressedBody))
+		require.NoError(t, bufw.Flush())
+		assert.Equal(t, expectedB, buf.Bytes())
+
+		actualHeader, actualBody, err := ReadMessage(bufio.NewReader(bytes.NewReader(expectedB)))
+		require.NoError(t, err)
+		require.Equal(t, OpCodeCompressed, actualHeader.OpCode)
+
+		actualHeader, actualMsgBody, err := Decompress(actualHeader, actualBody.(*OpCompressed))
+		require.NoError(t, err)
+		assert.Equal(t, header, actualHeader)
+		assert.Equal(t, body, actualMsgBody)
+	})
+}
+
+// makeSizedDocument returns bytes of a valid document with a single string field and the given size.
+func makeSizedDocument(size int) []byte {
+	b := make([]byte, size)
+	binary.LittleEndian.PutUint32(b, uint32(size))
+	b[4] = 0x02 // string
+	b[5] = 'v'
+	binary.LittleEndian.PutUint32(b[7:], uint32(size-12)) // including terminating zero
+
+	for i := 11; i < size-2; i++ {
+		b[i] = 'x'
+	}
+
+	return b
+}
+
+func TestMsgSizeLimits(t *testing.T) {
+	t.Parallel()
+
+	body := makeBody(sequenceBody)
+
+	t.Run("MaxDocumentLen", func(t *testing.T) {
+		t.Parallel()
+
+		b := makeMsg(1, body, makeSequence(0, []byte("documents\x00"), sequenceDoc1B, makeSizedDocument(types.MaxDocumentLen)))
+		_, msgBody, err := ReadMessage(bufio.NewReader(bytes.NewReader(b)))
+		require.NoError(t, err)
+
+		doc, err := msgBody.(*OpMsg).Document()
+		require.NoError(t, err)
+
+		docs := must.NotFail(doc.Get("documents")).(*types.Array)
+		require.Equal(t, 2, docs.Len())
+		v := must.NotFail(must.NotFail(docs.Get(1)).(*types.Document).Get("v")).(string)
+		assert.Len(t, v, types.MaxDocumentLen-13)
+	})
+
+	t.Run("DocumentTooLarge", func(t *testing.T) {
+		t.Parallel()
+
+		b := makeMsg(1, body, makeSequence(
+			0, []byte("documents\x00"),
+			sequenceDoc1B, makeSizedDocument(types.MaxDocumentLen+1), sequenceDoc2B,
+		))
+
+		// the message itself is valid
+		_, msgBody, err := ReadMessage(bufio.NewReader(bytes.NewReader(b)))
+		require.NoError(t, err)
+		msg := msgBody.(*OpMsg)
+
+		expected := &DocumentTooLargeError{Identifier: "documents", Index: 1, Size: types.MaxDocumentLen + 1}
+
+		_, err = msg.Document()
+		var tooLarge *DocumentTooLargeError
+		require.True(t, errors.As(err, &tooLarge), "%v", err)
+		assert.Equal(t, expected, tooLarge)
+		assert.Equal(t, "BSONObj size: 16777217 (0x1000001) is invalid. Size must be between 0 and 16777216(16MB)", err.Error())
+
+		doc, oversized, err := msg.DocumentWithOversized()
+		require.NoError(t, err)
+		assert.Equal(t, []*DocumentTooLargeError{expected}, oversized)
+
+		docs := must.NotFail(doc.Get("documents")).(*types.Array)
+		require.Equal(t, 3, docs.Len())
+		assert.Equal(t, sequenceDoc1, must.NotFail(docs.Get(0)))
+		assert.Equal(t, sequenceDoc2, must.NotFail(docs.Get(2)))
+	})
+
+	t.Run("BodyTooLarge", func(t *testing.T) {
+		t.Parallel()
+
+		b := makeMsg(1, append([]byte{0}, makeSizedDocument(types.MaxDocumentLen+1)...))
+		_, _, err := ReadMessage(bufio.NewReader(bytes.NewReader(b)))
+		require.Error(t, err)
+	})
+
+	t.Run("MaxMsgLen", func(t *testing.T) {
+		t.Parallel()
+
+		header := func(length int32) *bufio.Reader {
+			b := makeMsg(1)[:MsgHeaderLen]
+			binary.LittleEndian.PutUint32(b, uint32(length))
+			return bufio.NewReader(bytes.NewReader(b))
+		}
+
+		// valid length, but the body is missing
+		_, _, err := ReadMessage(header(MaxMsgLen))
+		require.Error(t, err)
+		assert.False(t, errors.Is(err, ErrMessageTooLarge), "%v", err)
+
+		_, _, err = ReadMessage(header(MaxMsgLen + 1))
+		assert.True(t, errors.Is(err, ErrMessageTooLarge), "%v", err)
+	})
+}
+
+// makeDocument returns BSON document bytes with the given elements' bytes.
+func makeDocument(elements ...[]byte) []byte {
+	b := make([]byte, 4)
+	for _, e := range elements {
+		b = append(b, e...)
+	}
+	b = append(b, 0)
+
+	binary.LittleEndian.PutUint32(b, uint32(len(b)))
+
+	return b
+}
+
+// deprecatedTypesSeeds contains OP_MSG messages with deprecated BSON types.
+var deprecatedTypesSeeds = func() [][]byte {
+	symbol := []byte{0x0e, 'v', 0x00, 0x04, 0x00, 0x00, 0x00, 'f', 'o', 'o', 0x00}
+	undefined := []byte{0x06, 'v', 0x00}
+	dbPointer := append(
+		[]byte{0x0c, 'v', 0x00, 0x05, 0x00, 0x00, 0x00, 'd', 'b', '.', 'c', 0x00},
+		0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01,
+	)
+	db := []byte{0x02, '$', 'd', 'b', 0x00, 0x05, 0x00, 0x00, 0x00, 't', 'e', 's', 't', 0x00}
+
+	var res [][]byte
+	for _, e := range [][]byte{symbol, undefined, dbPointer} {
+		res = append(res, makeMsg(1, append([]byte{0}, makeDocument(e, db)...)))
+	}
+
+	array := append([]byte{0x04, 'a', 0x00}, makeDocument([]byte{0x06, '0', 0x00})...)
+	res = append(res, makeChecksumMsg(2, append([]byte{0}, makeDocument(symbol, array, db)...)))
+	res = append(res, makeMsg(3, makeSequence(0, []byte("documents\x00"), makeDocument(dbPointer))))
+
+	return res
+}()
+
 func FuzzMsg(f *testing.F) {
-	fuzzMessages(f, msgTestCases)
+	for _, b := range deprecatedTypesSeeds {
+		f.Add(b)
+	}
+
+	cases := append(msgTestCases, msgSequenceTestCases...)
+	fuzzMessages(f, append(cases, msgChecksumTestCases...))
 }
@@ -0,0 +1,109 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/FerretDB/FerretDB/internal/util/lazyerrors"
+)
+
+// Record represents a single message read from the wire traffic recording.
+//
+// Recordings (.bin files) are just concatenated messages as they are sent over the wire.
+type Record struct {
+	Header *MsgHeader
+	Body   MsgBody
+
+	// Bytes contains the whole message, header and body.
+	Bytes []byte
+}
+
+// ReadRecords reads all messages from the recording.
+func ReadRecords(r io.Reader) ([]Record, error) {
+	bufr := bufio.NewReader(r)
+
+	var res []Record
+	for {
+		if _, err := bufr.Peek(1); errors.Is(err, io.EOF) {
+			return res, nil
+		}
+
+		lb, err := bufr.Peek(4)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		l := int32(binary.LittleEndian.Uint32(lb))
+		if l < MsgHeaderLen || l > MaxMsgLen {
+			return nil, lazyerrors.Errorf("invalid message length %d", l)
+		}
+
+		b := make([]byte, l)
+		if _, err = io.ReadFull(bufr, b); err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		header, body, err := ReadMessage(bufio.NewReader(bytes.NewReader(b)))
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		res = append(res, Record{
+			Header: header,
+			Body:   body,
+			Bytes:  b,
+		})
+	}
+}
+
+// LoadRecords reads all messages from all recordings (.bin files) in the given directory,
+// sorted by file names.
+//
+// Absent directory is not an error.
+func LoadRecords(dir string) ([]Record, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.bin"))
+	if err != nil {
+		return nil, lazyerrors.Error(err)
+	}
+
+	sort.Strings(files)
+
+	var res []Record
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, lazyerrors.Error(err)
+		}
+
+		records, err := ReadRecords(f)
+		f.Close()
+
+		if err != nil {
+			return nil, lazyerrors.Errorf("%s: %w", file, err)
+		}
+
+		res = append(res, records...)
+	}
+
+	return res, nil
+}
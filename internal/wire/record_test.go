@@ -0,0 +1,86 @@
+// Copyright 2021 FerretDB Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wire
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecords(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	testCases := append(msgTestCases, msgSequenceTestCases...)
+
+	var expected []Record
+	for i, tc := range testCases {
+		if tc.msgHeader == nil {
+			continue
+		}
+
+		var buf bytes.Buffer
+		bufw := bufio.NewWriter(&buf)
+		require.NoError(t, WriteMessage(bufw, tc.msgHeader, tc.msgBody))
+		require.NoError(t, bufw.Flush())
+
+		expected = append(expected, Record{
+			Header: tc.msgHeader,
+			Body:   tc.msgBody,
+			Bytes:  buf.Bytes(),
+		})
+
+		// put first two messages into one file, the rest into another
+		file := "2.bin"
+		if i < 2 {
+			file = "1.bin"
+		}
+
+		f, err := os.OpenFile(filepath.Join(dir, file), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o666)
+		require.NoError(t, err)
+		_, err = f.Write(buf.Bytes())
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+	}
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ignored.txt"), []byte("not a recording"), 0o666))
+
+	actual, err := LoadRecords(dir)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+
+	t.Run("Absent", func(t *testing.T) {
+		t.Parallel()
+
+		actual, err := LoadRecords(filepath.Join(dir, "absent"))
+		require.NoError(t, err)
+		assert.Empty(t, actual)
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		t.Parallel()
+
+		b := expected[0].Bytes
+		_, err := ReadRecords(bytes.NewReader(b[:len(b)-1]))
+		require.Error(t, err)
+	})
+}
@@ -18,6 +18,7 @@ import (
 	"bufio"
 	"bytes"
 	"errors"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -36,6 +37,9 @@ func lastErr(err error) error {
 	}
 }
 
+// recordsDir contains wire traffic recordings (see -record-dir flag) that are used as additional fuzz seeds.
+var recordsDir = filepath.Join("testdata", "records")
+
 var lastUpdate = time.Date(2020, 2, 15, 9, 34, 33, 0, time.UTC).Local()
 
 type testCase struct {
@@ -113,11 +117,21 @@ func testMessages(t *testing.T, testCases []testCase) {
 	}
 }
 
+// deprecatedTags contains tags of deprecated BSON types (Undefined, DBPointer, Symbol).
+const deprecatedTags = "\x06\x0c\x0e"
+
 func fuzzMessages(f *testing.F, testCases []testCase) {
 	for _, tc := range testCases {
 		f.Add(tc.expectedB)
 	}
 
+	records, err := LoadRecords(recordsDir)
+	require.NoError(f, err)
+
+	for _, r := range records {
+		f.Add(r.Bytes)
+	}
+
 	f.Fuzz(func(t *testing.T, b []byte) {
 		t.Parallel()
 
@@ -142,6 +156,22 @@ func fuzzMessages(f *testing.F, testCases []testCase) {
 			expectedB = b[:len(b)-bufr.Buffered()-br.Len()]
 		}
 
+		// deprecated BSON types are replaced on decoding; check that the replacement is stable instead
+		if b, err := marshalBody(msgHeader, msgBody); err == nil &&
+			!bytes.Equal(expectedB[MsgHeaderLen:], b) && bytes.ContainsAny(expectedB, deprecatedTags) {
+			h := *msgHeader
+			h.MessageLength = int32(MsgHeaderLen + len(b))
+
+			var bw bytes.Buffer
+			bufw := bufio.NewWriter(&bw)
+			require.NoError(t, WriteMessage(bufw, &h, msgBody))
+			require.NoError(t, bufw.Flush())
+			expectedB = bw.Bytes()
+
+			msgHeader, msgBody, err = ReadMessage(bufio.NewReader(bytes.NewReader(expectedB)))
+			require.NoError(t, err)
+		}
+
 		// test WriteMessage
 		{
 			var bw bytes.Buffer
@@ -63,7 +63,10 @@ var orderTypes = map[string]int{
 	"int64":     13,
 	"int64Type": 13,
 
-	"CString": 14,
+	"Decimal128":     14,
+	"decimal128Type": 14,
+
+	"CString": 15,
 }
 
 var analyzer = &analysis.Analyzer{